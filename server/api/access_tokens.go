@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (a *API) registerAccessTokensRoutes(r *mux.Router) {
+	// personal-server specific routes. These are not needed in plugin mode.
+	r.HandleFunc("/users/me/access_tokens", a.sessionRequired(a.handleGetAccessTokens)).Methods("GET")
+	r.HandleFunc("/users/me/access_tokens", a.sessionRequired(a.handleCreateAccessToken)).Methods("POST")
+	r.HandleFunc("/users/me/access_tokens/{tokenID}", a.sessionRequired(a.handleRevokeAccessToken)).Methods("DELETE")
+}
+
+func (a *API) handleGetAccessTokens(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /users/me/access_tokens getAccessTokens
+	//
+	// Returns the personal access tokens of the current user
+	//
+	// ---
+	// produces:
+	// - application/json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/AccessToken"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	userID := getUserID(r)
+
+	auditRec := a.makeAuditRecord(r, "getAccessTokens", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+
+	tokens, err := a.app.GetAccessTokensForUser(userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("tokenCount", len(tokens))
+	auditRec.Success()
+}
+
+func (a *API) handleCreateAccessToken(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/me/access_tokens createAccessToken
+	//
+	// Creates a personal access token for the current user. The token
+	// value is only returned in this response
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: access token definition
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/AccessTokenCreateRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/AccessToken"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	request, err := model.AccessTokenCreateRequestFromJSON(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	userID := getUserID(r)
+
+	auditRec := a.makeAuditRecord(r, "createAccessToken", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("scopes", request.Scopes)
+	auditRec.AddMeta("expiresAt", request.ExpiresAt)
+
+	accessToken, err := a.app.CreateAccessToken(userID, request)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("CreateAccessToken",
+		mlog.String("userID", userID),
+		mlog.String("tokenID", accessToken.ID),
+	)
+
+	data, err := json.Marshal(accessToken)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("tokenID", accessToken.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleRevokeAccessToken(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /users/me/access_tokens/{tokenID} revokeAccessToken
+	//
+	// Revokes a personal access token of the current user
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tokenID
+	//   in: path
+	//   description: Access token ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: access token not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	tokenID := mux.Vars(r)["tokenID"]
+	userID := getUserID(r)
+
+	auditRec := a.makeAuditRecord(r, "revokeAccessToken", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("tokenID", tokenID)
+
+	if err := a.app.RevokeAccessToken(userID, tokenID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("RevokeAccessToken",
+		mlog.String("userID", userID),
+		mlog.String("tokenID", tokenID),
+	)
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
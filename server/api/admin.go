@@ -54,3 +54,59 @@ func (a *API) handleAdminSetPassword(w http.ResponseWriter, r *http.Request) {
 	jsonStringResponse(w, http.StatusOK, "{}")
 	auditRec.Success()
 }
+
+func (a *API) handleAdminResetMfa(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	username := vars["username"]
+
+	auditRec := a.makeAuditRecord(r, "adminResetMfa", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("username", username)
+
+	err := a.app.ResetUserMfa(username)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("AdminResetMfa, username: %s", mlog.String("username", username))
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleAdminSyncLDAP(w http.ResponseWriter, r *http.Request) {
+	auditRec := a.makeAuditRecord(r, "adminSyncLDAP", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	err := a.app.SyncLDAP()
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("AdminSyncLDAP")
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleAdminUnlockUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	username := vars["username"]
+
+	auditRec := a.makeAuditRecord(r, "adminUnlockUser", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("username", username)
+
+	err := a.app.UnlockUser(username)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("AdminUnlockUser, username: %s", mlog.String("username", username))
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
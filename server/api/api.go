@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/focalboard/server/app"
@@ -75,7 +78,12 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 	// V2 routes (ToDo: migrate these to V3 when ready to ship V3)
 	a.registerUsersRoutes(apiv2)
 	a.registerAuthRoutes(apiv2)
+	a.registerAccessTokensRoutes(apiv2)
+	a.registerSessionsRoutes(apiv2)
+	a.registerMfaRoutes(apiv2)
+	a.registerUserTokensRoutes(apiv2)
 	a.registerMembersRoutes(apiv2)
+	a.registerBoardRolesRoutes(apiv2)
 	a.registerCategoriesRoutes(apiv2)
 	a.registerSharingRoutes(apiv2)
 	a.registerTeamsRoutes(apiv2)
@@ -99,10 +107,20 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 
 	// System routes are outside the /api/v2 path
 	a.registerSystemRoutes(r)
+	a.registerOIDCRoutes(r)
+	a.registerSCIMRoutes(r)
 }
 
 func (a *API) RegisterAdminRoutes(r *mux.Router) {
 	r.HandleFunc("/api/v2/admin/users/{username}/password", a.adminRequired(a.handleAdminSetPassword)).Methods("POST")
+	r.HandleFunc("/api/v2/admin/users/{username}/mfa/reset", a.adminRequired(a.handleAdminResetMfa)).Methods("POST")
+	r.HandleFunc("/api/v2/admin/users/{username}/unlock", a.adminRequired(a.handleAdminUnlockUser)).Methods("POST")
+	r.HandleFunc("/api/v2/admin/users/{username}/sessions/revoke", a.adminRequired(a.handleAdminRevokeUserSessions)).Methods("POST")
+	r.HandleFunc("/api/v2/admin/ldap/sync", a.adminRequired(a.handleAdminSyncLDAP)).Methods("POST")
+	r.HandleFunc("/api/v2/admin/jobs", a.adminRequired(a.handleAdminGetJobs)).Methods("GET")
+	r.HandleFunc("/api/v2/admin/jobs", a.adminRequired(a.handleAdminTriggerJob)).Methods("POST")
+	r.HandleFunc("/api/v2/admin/jobs/{jobID}", a.adminRequired(a.handleAdminGetJob)).Methods("GET")
+	r.HandleFunc("/api/v2/admin/jobs/{jobID}/cancel", a.adminRequired(a.handleAdminCancelJob)).Methods("POST")
 }
 
 func getUserID(r *http.Request) string {
@@ -165,6 +183,17 @@ func (a *API) hasValidReadTokenForBoard(r *http.Request, boardID string) bool {
 	return isValid
 }
 
+// permissionsFor returns the permissions service to use for a
+// request. Sessions created from personal access tokens are restricted
+// to the permissions granted by the token scopes.
+func (a *API) permissionsFor(r *http.Request) permissions.PermissionsService {
+	session, ok := r.Context().Value(sessionContextKey).(*model.Session)
+	if !ok || !session.IsAccessToken() {
+		return a.permissions
+	}
+	return permissions.NewScopedService(a.permissions, session.AccessTokenScopes())
+}
+
 func (a *API) userIsGuest(userID string) (bool, error) {
 	if a.singleUserToken != "" {
 		return false, nil
@@ -187,10 +216,23 @@ func (a *API) errorResponse(w http.ResponseWriter, r *http.Request, err error) {
 		errorResponse.ErrorCode = http.StatusForbidden
 	case model.IsErrNotFound(err):
 		errorResponse.ErrorCode = http.StatusNotFound
+	case model.IsErrConflict(err):
+		errorResponse.ErrorCode = http.StatusConflict
+		var vc *model.ErrVersionConflict
+		if errors.As(err, &vc) {
+			errorResponse.CurrentUpdateAt = vc.CurrentUpdateAt
+			setETag(w, vc.CurrentUpdateAt)
+		}
 	case model.IsErrRequestEntityTooLarge(err):
 		errorResponse.ErrorCode = http.StatusRequestEntityTooLarge
 	case model.IsErrNotImplemented(err):
 		errorResponse.ErrorCode = http.StatusNotImplemented
+	case model.IsErrTooManyRequests(err):
+		errorResponse.ErrorCode = http.StatusTooManyRequests
+		var tmr *model.ErrTooManyRequests
+		if errors.As(err, &tmr) && tmr.RetryAfter > 0 {
+			setResponseHeader(w, "Retry-After", strconv.Itoa(int(math.Ceil(tmr.RetryAfter.Seconds()))))
+		}
 	default:
 		a.logger.Error("API ERROR",
 			mlog.Int("code", http.StatusInternalServerError),
@@ -228,6 +270,27 @@ func jsonBytesResponse(w http.ResponseWriter, code int, json []byte) { //nolint:
 	_, _ = w.Write(json)
 }
 
+// setETag sets the version of the returned resource, which is its update
+// time. It can be sent back in the If-Match header of its patches.
+func setETag(w http.ResponseWriter, updateAt int64) {
+	setResponseHeader(w, "ETag", strconv.Quote(strconv.FormatInt(updateAt, 10)))
+}
+
+// getExpectedUpdateAt returns the version expected by the If-Match header
+// of a patch, if any.
+func getExpectedUpdateAt(r *http.Request) (*int64, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, nil
+	}
+
+	updateAt, err := strconv.ParseInt(strings.Trim(ifMatch, `"`), 10, 64)
+	if err != nil {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("invalid If-Match header: %s", ifMatch))
+	}
+	return &updateAt, nil
+}
+
 func setResponseHeader(w http.ResponseWriter, key string, value string) { //nolint:unparam
 	header := w.Header()
 	if header == nil {
@@ -3,15 +3,19 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/audit"
 	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/permissions"
 	"github.com/mattermost/focalboard/server/utils"
 
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
@@ -50,6 +54,10 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 	//     description: invalid login
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
+	//   '429':
+	//     description: too many failed logins
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: internal error
 	//     schema:
@@ -65,6 +73,11 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if a.authService == model.AuthModeOIDC {
+		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted when using OIDC authentication"))
+		return
+	}
+
 	requestBody, err := io.ReadAll(r.Body)
 	if err != nil {
 		a.errorResponse(w, r, err)
@@ -84,7 +97,25 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("type", loginData.Type)
 
 	if loginData.Type == "normal" {
-		token, err := a.app.Login(loginData.Username, loginData.Email, loginData.Password, loginData.MfaToken)
+		token, err := a.app.Login(loginData.Username, loginData.Email, loginData.Password, loginData.MfaToken, remoteIP(r), r.UserAgent())
+		var lockedErr *app.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			auditRec.AddMeta("lockedAccount", lockedErr.Account)
+			auditRec.AddMeta("lockedUntil", lockedErr.Until)
+			if lockedErr.Locked {
+				a.auditLoginLockout(r, lockedErr)
+			}
+			a.errorResponse(w, r, model.NewErrTooManyRequests("too many failed logins, try again later", time.Until(lockedErr.Until)))
+			return
+		}
+		if errors.Is(err, app.ErrMfaRequired) {
+			a.errorResponse(w, r, model.NewErrUnauthorized("MFA token required"))
+			return
+		}
+		if errors.Is(err, app.ErrEmailNotVerified) {
+			a.errorResponse(w, r, model.NewErrUnauthorized("email address not verified"))
+			return
+		}
 		if err != nil {
 			a.errorResponse(w, r, model.NewErrUnauthorized("incorrect login"))
 			return
@@ -103,6 +134,24 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 	a.errorResponse(w, r, model.NewErrBadRequest("invalid login type"))
 }
 
+// auditLoginLockout records the lockout of an account or an IP address.
+func (a *API) auditLoginLockout(r *http.Request, lockedErr *app.LoginLockedError) {
+	auditRec := a.makeAuditRecord(r, "loginLockout", audit.Success)
+	auditRec.AddMeta("account", lockedErr.Account)
+	auditRec.AddMeta("ipAddress", lockedErr.IPAddress)
+	auditRec.AddMeta("until", lockedErr.Until)
+	a.audit.LogRecord(audit.LevelAuth, auditRec)
+}
+
+// remoteIP returns the IP address of the client of a request.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func (a *API) handleLogout(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation POST /logout logout
 	//
@@ -146,6 +195,10 @@ func (a *API) handleLogout(w http.ResponseWriter, r *http.Request) {
 
 	auditRec.AddMeta("sessionID", session.ID)
 
+	if _, location := auth.ParseAuthTokenFromRequest(r); location == auth.TokenLocationCookie {
+		a.setCookie(w, auth.SessionCookieToken, "", a.basePath()+"/", -1)
+	}
+
 	jsonStringResponse(w, http.StatusOK, "{}")
 	auditRec.Success()
 }
@@ -185,6 +238,11 @@ func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if a.authService == model.AuthModeOIDC || a.authService == model.AuthModeLDAP {
+		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted when using "+strings.ToUpper(a.authService)+" authentication"))
+		return
+	}
+
 	requestBody, err := io.ReadAll(r.Body)
 	if err != nil {
 		a.errorResponse(w, r, err)
@@ -201,7 +259,10 @@ func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
 	registerData.Username = strings.TrimSpace(registerData.Username)
 
 	// Validate token
-	if len(registerData.Token) > 0 {
+	switch {
+	case len(registerData.InviteToken) > 0:
+		// the team invite is checked when registering the user
+	case len(registerData.Token) > 0:
 		team, err2 := a.app.GetRootTeam()
 		if err2 != nil {
 			a.errorResponse(w, r, err2)
@@ -212,7 +273,7 @@ func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
 			a.errorResponse(w, r, model.NewErrUnauthorized("invalid token"))
 			return
 		}
-	} else {
+	default:
 		// No signup token, check if no active users
 		userCount, err2 := a.app.GetRegisteredUserCount()
 		if err2 != nil {
@@ -234,10 +295,18 @@ func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
 	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
 	auditRec.AddMeta("username", registerData.Username)
 
-	err = a.app.RegisterUser(registerData.Username, registerData.Email, registerData.Password)
-	if err != nil {
-		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
-		return
+	if len(registerData.InviteToken) > 0 {
+		err = a.app.RegisterInvitedUser(registerData.Username, registerData.Email, registerData.Password, registerData.InviteToken)
+		if err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+	} else {
+		err = a.app.RegisterUser(registerData.Username, registerData.Email, registerData.Password)
+		if err != nil {
+			a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+			return
+		}
 	}
 
 	jsonStringResponse(w, http.StatusOK, "{}")
@@ -277,14 +346,8 @@ func (a *API) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
-	if a.MattermostAuth {
-		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted in plugin mode"))
-		return
-	}
-
-	if len(a.singleUserToken) > 0 {
-		// Not permitted in single-user mode
-		a.errorResponse(w, r, model.NewErrUnauthorized("not permitted in single-user mode"))
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
 		return
 	}
 
@@ -311,7 +374,8 @@ func (a *API) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	auditRec := a.makeAuditRecord(r, "changePassword", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
 
-	if err = a.app.ChangePassword(userID, requestData.OldPassword, requestData.NewPassword); err != nil {
+	session := r.Context().Value(sessionContextKey).(*model.Session)
+	if err = a.app.ChangePassword(userID, requestData.OldPassword, requestData.NewPassword, session); err != nil {
 		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
 		return
 	}
@@ -379,8 +443,10 @@ func (a *API) attachSession(handler func(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		// the native users keep logging in with their password when the
+		// users are authenticated with LDAP
 		authService := session.AuthService
-		if authService != a.authService {
+		if authService != a.authService && !(a.authService == model.AuthModeLDAP && authService == model.AuthModeNative) {
 			msg := `Session authService mismatch`
 			a.logger.Error(msg,
 				mlog.String("sessionID", session.ID),
@@ -391,11 +457,129 @@ func (a *API) attachSession(handler func(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		if session.IsAccessToken() && !accessTokenAllowsRequest(r, session.AccessTokenScopes()) {
+			a.errorResponse(w, r, model.NewErrForbidden("access token scopes don't allow this request"))
+			return
+		}
+
+		if session.IsPasswordExpired() && !isAllowedWithExpiredPassword(r) {
+			a.errorResponse(w, r, model.NewErrForbidden("password expired, it must be changed"))
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), sessionContextKey, session)
 		handler(w, r.WithContext(ctx))
 	}
 }
 
+// isReadOnlyRequest returns true if the request method doesn't modify
+// any data.
+func isReadOnlyRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+var (
+	anyAccessTokenScopes   = []string{model.AccessTokenScopeReadOnly, model.AccessTokenScopeCardsWrite, model.AccessTokenScopeBoardsAdmin}
+	cardsAccessTokenScopes = []string{model.AccessTokenScopeCardsWrite, model.AccessTokenScopeBoardsAdmin}
+	adminAccessTokenScopes = []string{model.AccessTokenScopeBoardsAdmin}
+)
+
+// accessTokenRouteScopes lists the scopes that allow the write routes
+// that aren't checked against the board permissions granted by the
+// scopes. The routes listed without scopes can't be used with a scoped
+// access token.
+var accessTokenRouteScopes = map[string][]string{
+	"POST /api/v2/logout":                        nil,
+	"POST /api/v2/users/{userID}/changepassword": nil,
+
+	// reads made with a POST request
+	"POST /api/v2/users":                anyAccessTokenScopes,
+	"POST /api/v2/teams/{teamID}/users": anyAccessTokenScopes,
+
+	// card subscriptions
+	"POST /api/v2/subscriptions":                            cardsAccessTokenScopes,
+	"DELETE /api/v2/subscriptions/{blockID}/{subscriberID}": cardsAccessTokenScopes,
+
+	// sidebar, preferences and team membership
+	"POST /api/v2/teams/{teamID}/categories":                                     adminAccessTokenScopes,
+	"PUT /api/v2/teams/{teamID}/categories/reorder":                              adminAccessTokenScopes,
+	"PUT /api/v2/teams/{teamID}/categories/{categoryID}":                         adminAccessTokenScopes,
+	"DELETE /api/v2/teams/{teamID}/categories/{categoryID}":                      adminAccessTokenScopes,
+	"PUT /api/v2/teams/{teamID}/categories/{categoryID}/boards/reorder":          adminAccessTokenScopes,
+	"POST /api/v2/teams/{teamID}/categories/{categoryID}/boards/{boardID}":       adminAccessTokenScopes,
+	"PUT /api/v2/teams/{teamID}/categories/{categoryID}/boards/{boardID}/hide":   adminAccessTokenScopes,
+	"PUT /api/v2/teams/{teamID}/categories/{categoryID}/boards/{boardID}/unhide": adminAccessTokenScopes,
+	"PUT /api/v2/users/{userID}/config":                                          adminAccessTokenScopes,
+	"POST /api/v2/teams/{teamID}/onboard":                                        adminAccessTokenScopes,
+	"POST /api/v2/teams/{teamID}/regenerate_signup_token":                        adminAccessTokenScopes,
+	"POST /api/v2/teams/invites/accept":                                          adminAccessTokenScopes,
+}
+
+// accessTokenAllowsRequest returns true if the scopes of an access token
+// allow the request. The write routes not listed in the route scopes are
+// restricted by the board permissions granted by the scopes, so any
+// scope that allows writing is enough for them.
+func accessTokenAllowsRequest(r *http.Request, scopes []string) bool {
+	if isReadOnlyRequest(r) {
+		return true
+	}
+
+	if allowed, ok := accessTokenRouteScopes[r.Method+" "+routeTemplate(r)]; ok {
+		return permissions.ScopesAllowAny(scopes, allowed...)
+	}
+	return permissions.ScopesAllowWrite(scopes)
+}
+
+// routeTemplate returns the path template of the route matching the
+// request, or an empty string if there is none.
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return template
+}
+
+// isAllowedWithExpiredPassword returns true if the request can be made
+// with a session whose password expired, to change it or log out.
+func isAllowedWithExpiredPassword(r *http.Request) bool {
+	switch routeTemplate(r) {
+	case "/api/v2/users/{userID}/changepassword", "/api/v2/logout":
+		return true
+	case "/api/v2/users/me":
+		return isReadOnlyRequest(r)
+	}
+	return false
+}
+
+// checkLoginSession ensures that the request was made using a session
+// created by logging in. Account security settings, such as access
+// tokens or MFA, cannot be managed using an access token.
+func (a *API) checkLoginSession(r *http.Request) error {
+	if a.MattermostAuth {
+		return model.NewErrNotImplemented("not permitted in plugin mode")
+	}
+
+	if len(a.singleUserToken) > 0 {
+		return model.NewErrUnauthorized("not permitted in single-user mode")
+	}
+
+	session := r.Context().Value(sessionContextKey).(*model.Session)
+	if session.IsAccessToken() {
+		return model.NewErrForbidden("not permitted using an access token")
+	}
+
+	return nil
+}
+
 func (a *API) adminRequired(handler func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Currently, admin APIs require local unix connections
@@ -49,6 +49,11 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 	//   description: Type of blocks to return, omit to specify all types
 	//   required: false
 	//   type: string
+	// - name: block_id
+	//   in: query
+	//   description: ID of the block to return, which sets the ETag header to its version
+	//   required: false
+	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
@@ -88,12 +93,12 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 
 	if !hasValidReadToken {
 		if board.IsTemplate && board.Type == model.BoardTypeOpen {
-			if board.TeamID != model.GlobalTeamID && !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
+			if board.TeamID != model.GlobalTeamID && !a.permissionsFor(r).HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
 				a.errorResponse(w, r, model.NewErrPermission("access denied to board template"))
 				return
 			}
 		} else {
-			if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+			if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
 				a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
 				return
 			}
@@ -151,6 +156,12 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	blocks, err = a.filterBlocksForUser(board, userID, blocks)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	a.logger.Debug("GetBlocks",
 		mlog.String("boardID", boardID),
 		mlog.String("parentID", parentID),
@@ -165,6 +176,9 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if blockID != "" && len(blocks) == 1 {
+		setETag(w, blocks[0].UpdateAt)
+	}
 	jsonBytesResponse(w, http.StatusOK, json)
 
 	auditRec.AddMeta("blockCount", len(blocks))
@@ -270,18 +284,24 @@ func (a *API) handlePostBlocks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if hasContents {
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to make board changes"))
 			return
 		}
 	}
 	if hasComments {
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionCommentBoardCards) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionCommentBoardCards) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to post card comments"))
 			return
 		}
 	}
 
+	// blocks can't be added to the private cards the user can't see
+	if err = a.checkParentCardsVisible(boardID, userID, blocks); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	blocks = model.GenerateBlockIDs(blocks, a.logger)
 
 	auditRec := a.makeAuditRecord(r, "postBlocks", audit.Fail)
@@ -308,6 +328,13 @@ func (a *API) handlePostBlocks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	restrictions, err := a.app.GetPropertyRestrictionsForBoard(boardID, session.UserID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	newBlocks = restrictions.FilterBlocks(newBlocks)
+
 	a.logger.Debug("POST Blocks",
 		mlog.Int("block_count", len(blocks)),
 		mlog.Bool("disable_notify", disableNotify),
@@ -369,7 +396,7 @@ func (a *API) handleDeleteBlock(w http.ResponseWriter, r *http.Request) {
 	val := r.URL.Query().Get("disable_notify")
 	disableNotify := val == True
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to make board changes"))
 		return
 	}
@@ -384,6 +411,10 @@ func (a *API) handleDeleteBlock(w http.ResponseWriter, r *http.Request) {
 		a.errorResponse(w, r, model.NewErrNotFound(message))
 		return
 	}
+	if err = a.checkBlockVisible(block, userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
 
 	auditRec := a.makeAuditRecord(r, "deleteBlock", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
@@ -461,11 +492,16 @@ func (a *API) handleUndeleteBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to modify board members"))
 		return
 	}
 
+	if err = a.checkBlockVisible(block, userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	auditRec := a.makeAuditRecord(r, "undeleteBlock", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("blockID", blockID)
@@ -476,6 +512,13 @@ func (a *API) handleUndeleteBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	restrictions, err := a.app.GetPropertyRestrictions(board, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	undeletedBlock = restrictions.FilterBlock(undeletedBlock)
+
 	undeletedBlockData, err := json.Marshal(undeletedBlock)
 	if err != nil {
 		a.errorResponse(w, r, err)
@@ -518,6 +561,11 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 	//   required: true
 	//   schema:
 	//     "$ref": "#/definitions/BlockPatch"
+	// - name: If-Match
+	//   in: header
+	//   description: ETag of the expected version, unless set in the patch
+	//   required: false
+	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
@@ -525,6 +573,10 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 	//     description: success
 	//   '404':
 	//     description: block not found
+	//   '409':
+	//     description: the block was modified since the expected version
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -538,7 +590,7 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 	val := r.URL.Query().Get("disable_notify")
 	disableNotify := val == True
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to make board changes"))
 		return
 	}
@@ -553,6 +605,10 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 		a.errorResponse(w, r, model.NewErrNotFound(message))
 		return
 	}
+	if err = a.checkBlockVisible(block, userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
 
 	requestBody, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -567,17 +623,26 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if patch.ExpectedUpdateAt == nil {
+		if patch.ExpectedUpdateAt, err = getExpectedUpdateAt(r); err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+	}
+
 	auditRec := a.makeAuditRecord(r, "patchBlock", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("boardID", boardID)
 	auditRec.AddMeta("blockID", blockID)
 
-	if _, err = a.app.PatchBlockAndNotify(blockID, patch, userID, disableNotify); err != nil {
+	patchedBlock, err := a.app.PatchBlockAndNotify(blockID, patch, userID, disableNotify)
+	if err != nil {
 		a.errorResponse(w, r, err)
 		return
 	}
 
 	a.logger.Debug("PATCH Block", mlog.String("boardID", boardID), mlog.String("blockID", blockID))
+	setETag(w, patchedBlock.UpdateAt)
 	jsonStringResponse(w, http.StatusOK, "{}")
 
 	auditRec.Success()
@@ -654,10 +719,14 @@ func (a *API) handlePatchBlocks(w http.ResponseWriter, r *http.Request) {
 			a.errorResponse(w, r, model.NewErrForbidden("access denied to make board changes"))
 			return
 		}
-		if !a.permissions.HasPermissionToBoard(userID, block.BoardID, model.PermissionManageBoardCards) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, block.BoardID, model.PermissionManageBoardCards) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to make board changesa"))
 			return
 		}
+		if err = a.checkBlockVisible(block, userID); err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
 	}
 
 	err = a.app.PatchBlocksAndNotify(teamID, patches, userID, disableNotify)
@@ -736,13 +805,18 @@ func (a *API) handleDuplicateBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = a.checkBlockVisible(block, userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	if block.Type == model.TypeComment {
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionCommentBoardCards) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionCommentBoardCards) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to comment on board cards"))
 			return
 		}
 	} else {
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to modify board cards"))
 			return
 		}
@@ -764,6 +838,12 @@ func (a *API) handleDuplicateBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	blocks, err = a.filterBlocksForUser(board, userID, blocks)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	data, err := json.Marshal(blocks)
 	if err != nil {
 		a.errorResponse(w, r, err)
@@ -775,3 +855,60 @@ func (a *API) handleDuplicateBlock(w http.ResponseWriter, r *http.Request) {
 
 	auditRec.Success()
 }
+
+// checkBlockVisible returns a not found error if the block is a private
+// card the user can't see, or belongs to one.
+func (a *API) checkBlockVisible(block *model.Block, userID string) error {
+	visible, err := a.app.CanViewBlock(block, userID)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return model.NewErrNotFound("block ID=" + block.ID)
+	}
+	return nil
+}
+
+// checkParentCardsVisible returns a permission error if any of the new
+// blocks belongs to a private card the user can't see.
+func (a *API) checkParentCardsVisible(boardID, userID string, blocks []*model.Block) error {
+	newBlockIDs := map[string]bool{}
+	for _, block := range blocks {
+		newBlockIDs[block.ID] = true
+	}
+
+	var children []*model.Block
+	for _, block := range blocks {
+		if parentID := model.CardParentID(block); parentID != "" && !newBlockIDs[parentID] {
+			children = append(children, block)
+		}
+	}
+	if len(children) == 0 {
+		return nil
+	}
+
+	visible, err := a.app.FilterVisibleBlocks(boardID, userID, children)
+	if err != nil {
+		return err
+	}
+	if len(visible) != len(children) {
+		return model.NewErrPermission("access denied to private card")
+	}
+	return nil
+}
+
+// filterBlocksForUser removes the private cards the user can't see from
+// the blocks of a board, and strips the card property values hidden to
+// the user.
+func (a *API) filterBlocksForUser(board *model.Board, userID string, blocks []*model.Block) ([]*model.Block, error) {
+	blocks, err := a.app.FilterVisibleBlocks(board.ID, userID, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	restrictions, err := a.app.GetPropertyRestrictions(board, userID)
+	if err != nil {
+		return nil, err
+	}
+	return restrictions.FilterBlocks(blocks), nil
+}
@@ -0,0 +1,300 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (a *API) registerBoardRolesRoutes(r *mux.Router) {
+	// Custom board role APIs
+	r.HandleFunc("/boards/{boardID}/roles", a.sessionRequired(a.handleGetCustomBoardRoles)).Methods("GET")
+	r.HandleFunc("/boards/{boardID}/roles", a.sessionRequired(a.handleCreateCustomBoardRole)).Methods("POST")
+	r.HandleFunc("/boards/{boardID}/roles/{roleID}", a.sessionRequired(a.handleUpdateCustomBoardRole)).Methods("PUT")
+	r.HandleFunc("/boards/{boardID}/roles/{roleID}", a.sessionRequired(a.handleDeleteCustomBoardRole)).Methods("DELETE")
+}
+
+func (a *API) handleGetCustomBoardRoles(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /boards/{boardID}/roles getCustomBoardRoles
+	//
+	// Returns the custom roles of the board
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/CustomBoardRole"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+	userID := getUserID(r)
+
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to board roles"))
+		return
+	}
+
+	roles, err := a.app.GetCustomBoardRoles(boardID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(roles)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleCreateCustomBoardRole(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /boards/{boardID}/roles createCustomBoardRole
+	//
+	// Creates a custom role for the board
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the name and the permissions of the role
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CustomBoardRole"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       $ref: '#/definitions/CustomBoardRole'
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+	userID := getUserID(r)
+
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to modify board roles"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var role *model.CustomBoardRole
+	if err = json.Unmarshal(requestBody, &role); err != nil || role == nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid board role"))
+		return
+	}
+	role.BoardID = boardID
+
+	auditRec := a.makeAuditRecord(r, "createCustomBoardRole", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("name", role.Name)
+
+	role, err = a.app.CreateCustomBoardRole(role, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("CreateCustomBoardRole",
+		mlog.String("boardID", boardID),
+		mlog.String("roleID", role.ID),
+	)
+
+	data, err := json.Marshal(role)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("roleID", role.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleUpdateCustomBoardRole(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /boards/{boardID}/roles/{roleID} updateCustomBoardRole
+	//
+	// Replaces the name and the permissions of a custom board role
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: roleID
+	//   in: path
+	//   description: Role ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the new name and permissions of the role
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CustomBoardRole"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       $ref: '#/definitions/CustomBoardRole'
+	//   '404':
+	//     description: role not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+	roleID := mux.Vars(r)["roleID"]
+	userID := getUserID(r)
+
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to modify board roles"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var update *model.CustomBoardRole
+	if err = json.Unmarshal(requestBody, &update); err != nil || update == nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid board role"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "updateCustomBoardRole", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("roleID", roleID)
+
+	role, err := a.app.UpdateCustomBoardRole(boardID, roleID, update)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("UpdateCustomBoardRole",
+		mlog.String("boardID", boardID),
+		mlog.String("roleID", roleID),
+	)
+
+	data, err := json.Marshal(role)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteCustomBoardRole(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /boards/{boardID}/roles/{roleID} deleteCustomBoardRole
+	//
+	// Deletes a custom board role and unassigns it from the members
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: boardID
+	//   in: path
+	//   description: Board ID
+	//   required: true
+	//   type: string
+	// - name: roleID
+	//   in: path
+	//   description: Role ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: role not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+	roleID := mux.Vars(r)["roleID"]
+	userID := getUserID(r)
+
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to modify board roles"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteCustomBoardRole", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("roleID", roleID)
+
+	if err := a.app.DeleteCustomBoardRole(boardID, roleID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("DeleteCustomBoardRole",
+		mlog.String("boardID", boardID),
+		mlog.String("roleID", roleID),
+	)
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	auditRec.Success()
+}
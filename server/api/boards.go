@@ -54,7 +54,7 @@ func (a *API) handleGetBoards(w http.ResponseWriter, r *http.Request) {
 	teamID := mux.Vars(r)["teamID"]
 	userID := getUserID(r)
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -136,12 +136,12 @@ func (a *API) handleCreateBoard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if newBoard.Type == model.BoardTypeOpen {
-		if !a.permissions.HasPermissionToTeam(userID, newBoard.TeamID, model.PermissionCreatePublicChannel) {
+		if !a.permissionsFor(r).HasPermissionToTeam(userID, newBoard.TeamID, model.PermissionCreatePublicChannel) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to create public boards"))
 			return
 		}
 	} else {
-		if !a.permissions.HasPermissionToTeam(userID, newBoard.TeamID, model.PermissionCreatePrivateChannel) {
+		if !a.permissionsFor(r).HasPermissionToTeam(userID, newBoard.TeamID, model.PermissionCreatePrivateChannel) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to create private boards"))
 			return
 		}
@@ -238,7 +238,7 @@ func (a *API) handleGetBoard(w http.ResponseWriter, r *http.Request) {
 
 	if !hasValidReadToken {
 		if board.Type == model.BoardTypePrivate {
-			if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+			if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
 				a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
 				return
 			}
@@ -250,13 +250,13 @@ func (a *API) handleGetBoard(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			if isGuest {
-				if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+				if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
 					a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
 					return
 				}
 			}
 
-			if !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
+			if !a.permissionsFor(r).HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
 				a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
 				return
 			}
@@ -278,6 +278,7 @@ func (a *API) handleGetBoard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// response
+	setETag(w, board.UpdateAt)
 	jsonBytesResponse(w, http.StatusOK, data)
 
 	auditRec.Success()
@@ -303,6 +304,11 @@ func (a *API) handlePatchBoard(w http.ResponseWriter, r *http.Request) {
 	//   required: true
 	//   schema:
 	//     "$ref": "#/definitions/BoardPatch"
+	// - name: If-Match
+	//   in: header
+	//   description: ETag of the expected version, unless set in the patch
+	//   required: false
+	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
@@ -312,13 +318,18 @@ func (a *API) handlePatchBoard(w http.ResponseWriter, r *http.Request) {
 	//       $ref: '#/definitions/Board'
 	//   '404':
 	//     description: board not found
+	//   '409':
+	//     description: the board was modified since the expected version
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
 	boardID := mux.Vars(r)["boardID"]
-	if _, err := a.app.GetBoard(boardID); err != nil {
+	board, err := a.app.GetBoard(boardID)
+	if err != nil {
 		a.errorResponse(w, r, err)
 		return
 	}
@@ -337,28 +348,41 @@ func (a *API) handlePatchBoard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if patch.ExpectedUpdateAt == nil {
+		if patch.ExpectedUpdateAt, err = getExpectedUpdateAt(r); err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+	}
+
 	if err = patch.IsValid(); err != nil {
 		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
 		return
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardProperties) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardProperties) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to modifying board properties"))
 		return
 	}
 
 	if patch.Type != nil || patch.MinimumRole != nil {
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardType) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardType) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to modifying board type"))
 			return
 		}
 	}
 	if patch.ChannelID != nil {
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to modifying board access"))
 			return
 		}
 	}
+	if model.PropertyAccessChanged(board, patch) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
+			a.errorResponse(w, r, model.NewErrPermission("access denied to modifying card property access"))
+			return
+		}
+	}
 
 	auditRec := a.makeAuditRecord(r, "patchBoard", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
@@ -384,6 +408,7 @@ func (a *API) handlePatchBoard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// response
+	setETag(w, updatedBoard.UpdateAt)
 	jsonBytesResponse(w, http.StatusOK, data)
 
 	auditRec.Success()
@@ -424,7 +449,7 @@ func (a *API) handleDeleteBoard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionDeleteBoard) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionDeleteBoard) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to delete board"))
 		return
 	}
@@ -493,23 +518,23 @@ func (a *API) handleDuplicateBoard(w http.ResponseWriter, r *http.Request) {
 		toTeam = board.TeamID
 	}
 
-	if toTeam == "" && !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
+	if toTeam == "" && !a.permissionsFor(r).HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
 
-	if toTeam != "" && !a.permissions.HasPermissionToTeam(userID, toTeam, model.PermissionViewTeam) {
+	if toTeam != "" && !a.permissionsFor(r).HasPermissionToTeam(userID, toTeam, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
 
 	if board.IsTemplate && board.Type == model.BoardTypeOpen {
-		if board.TeamID != model.GlobalTeamID && !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
+		if board.TeamID != model.GlobalTeamID && !a.permissionsFor(r).HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
 			return
 		}
 	} else {
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
 			return
 		}
@@ -586,7 +611,7 @@ func (a *API) handleUndeleteBoard(w http.ResponseWriter, r *http.Request) {
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("boardID", boardID)
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionDeleteBoard) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionDeleteBoard) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to undelete board"))
 		return
 	}
@@ -647,12 +672,12 @@ func (a *API) handleGetBoardMetadata(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if board.Type == model.BoardTypePrivate {
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
 			return
 		}
 	} else {
-		if !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
+		if !a.permissionsFor(r).HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
 			return
 		}
@@ -87,7 +87,7 @@ func (a *API) handleCreateBoardsAndBlocks(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to board template"))
 		return
 	}
@@ -227,13 +227,13 @@ func (a *API) handlePatchBoardsAndBlocks(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardProperties) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardProperties) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to modifying board properties"))
 			return
 		}
 
 		if patch.Type != nil || patch.MinimumRole != nil {
-			if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardType) {
+			if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardType) {
 				a.errorResponse(w, r, model.NewErrPermission("access denied to modifying board type"))
 				return
 			}
@@ -245,6 +245,13 @@ func (a *API) handlePatchBoardsAndBlocks(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		if model.PropertyAccessChanged(board, patch) {
+			if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
+				a.errorResponse(w, r, model.NewErrPermission("access denied to modifying card property access"))
+				return
+			}
+		}
+
 		if teamID == "" {
 			teamID = board.TeamID
 		}
@@ -266,10 +273,15 @@ func (a *API) handlePatchBoardsAndBlocks(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		if !a.permissions.HasPermissionToBoard(userID, block.BoardID, model.PermissionManageBoardCards) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, block.BoardID, model.PermissionManageBoardCards) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to modifying cards"))
 			return
 		}
+
+		if err2 = a.checkBlockVisible(block, userID); err2 != nil {
+			a.errorResponse(w, r, err2)
+			return
+		}
 	}
 
 	auditRec := a.makeAuditRecord(r, "patchBoardsAndBlocks", audit.Fail)
@@ -283,6 +295,19 @@ func (a *API) handlePatchBoardsAndBlocks(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	for _, board := range bab.Boards {
+		var restrictions *model.PropertyRestrictions
+		if restrictions, err = a.app.GetPropertyRestrictions(board, userID); err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+		for i := range bab.Blocks {
+			if bab.Blocks[i].BoardID == board.ID {
+				bab.Blocks[i] = restrictions.FilterBlock(bab.Blocks[i])
+			}
+		}
+	}
+
 	a.logger.Debug("PATCH BoardsAndBlocks",
 		mlog.Int("boardsCount", len(pbab.BoardIDs)),
 		mlog.Int("blocksCount", len(pbab.BlockIDs)),
@@ -360,7 +385,7 @@ func (a *API) handleDeleteBoardsAndBlocks(w http.ResponseWriter, r *http.Request
 		}
 
 		// permission check
-		if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionDeleteBoard) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionDeleteBoard) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to delete board"))
 			return
 		}
@@ -378,7 +403,7 @@ func (a *API) handleDeleteBoardsAndBlocks(w http.ResponseWriter, r *http.Request
 			return
 		}
 
-		if !a.permissions.HasPermissionToBoard(userID, block.BoardID, model.PermissionManageBoardCards) {
+		if !a.permissionsFor(r).HasPermissionToBoard(userID, block.BoardID, model.PermissionManageBoardCards) {
 			a.errorResponse(w, r, model.NewErrPermission("access denied to modifying cards"))
 			return
 		}
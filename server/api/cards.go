@@ -82,7 +82,7 @@ func (a *API) handleCreateCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to create card"))
 		return
 	}
@@ -109,6 +109,12 @@ func (a *API) handleCreateCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	card, err = a.filterCardForUser(card, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	a.logger.Debug("CreateCard",
 		mlog.String("boardID", boardID),
 		mlog.String("cardID", card.ID),
@@ -171,7 +177,7 @@ func (a *API) handleGetCards(w http.ResponseWriter, r *http.Request) {
 	strPage := query.Get("page")
 	strPerPage := query.Get("per_page")
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to fetch cards"))
 		return
 	}
@@ -207,6 +213,21 @@ func (a *API) handleGetCards(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	cards, err = a.app.FilterVisibleCards(boardID, userID, cards)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	restrictions, err := a.app.GetPropertyRestrictionsForBoard(boardID, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	for i := range cards {
+		cards[i] = restrictions.FilterCard(cards[i])
+	}
+
 	a.logger.Debug("GetCards",
 		mlog.String("boardID", boardID),
 		mlog.String("userID", userID),
@@ -252,6 +273,11 @@ func (a *API) handlePatchCard(w http.ResponseWriter, r *http.Request) {
 	//   description: Disables notifications (for bulk data patching)
 	//   required: false
 	//   type: bool
+	// - name: If-Match
+	//   in: header
+	//   description: ETag of the expected version, unless set in the patch
+	//   required: false
+	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
@@ -259,6 +285,10 @@ func (a *API) handlePatchCard(w http.ResponseWriter, r *http.Request) {
 	//     description: success
 	//     schema:
 	//       $ref: '#/definitions/Card'
+	//   '409':
+	//     description: the card was modified since the expected version
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -283,17 +313,29 @@ func (a *API) handlePatchCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, card.BoardID, model.PermissionManageBoardCards) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, card.BoardID, model.PermissionManageBoardCards) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to patch card"))
 		return
 	}
 
+	if err = a.checkBlockVisible(model.Card2Block(card), userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	var patch *model.CardPatch
 	if err = json.Unmarshal(requestBody, &patch); err != nil {
 		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
 		return
 	}
 
+	if patch.ExpectedUpdateAt == nil {
+		if patch.ExpectedUpdateAt, err = getExpectedUpdateAt(r); err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+	}
+
 	auditRec := a.makeAuditRecord(r, "patchCard", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("boardID", card.BoardID)
@@ -306,6 +348,12 @@ func (a *API) handlePatchCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	cardPatched, err = a.filterCardForUser(cardPatched, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	a.logger.Debug("PatchCard",
 		mlog.String("boardID", cardPatched.BoardID),
 		mlog.String("cardID", cardPatched.ID),
@@ -319,6 +367,7 @@ func (a *API) handlePatchCard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// response
+	setETag(w, cardPatched.UpdateAt)
 	jsonBytesResponse(w, http.StatusOK, data)
 
 	auditRec.Success()
@@ -360,16 +409,27 @@ func (a *API) handleGetCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, card.BoardID, model.PermissionManageBoardCards) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, card.BoardID, model.PermissionManageBoardCards) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to fetch card"))
 		return
 	}
 
+	if err = a.checkBlockVisible(model.Card2Block(card), userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	auditRec := a.makeAuditRecord(r, "getCard", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelRead, auditRec)
 	auditRec.AddMeta("boardID", card.BoardID)
 	auditRec.AddMeta("cardID", card.ID)
 
+	card, err = a.filterCardForUser(card, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	a.logger.Debug("GetCard",
 		mlog.String("boardID", card.BoardID),
 		mlog.String("cardID", card.ID),
@@ -383,7 +443,18 @@ func (a *API) handleGetCard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// response
+	setETag(w, card.UpdateAt)
 	jsonBytesResponse(w, http.StatusOK, data)
 
 	auditRec.Success()
 }
+
+// filterCardForUser strips the property values hidden to the user from
+// the card.
+func (a *API) filterCardForUser(card *model.Card, userID string) (*model.Card, error) {
+	restrictions, err := a.app.GetPropertyRestrictionsForBoard(card.BoardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return restrictions.FilterCard(card), nil
+}
@@ -91,7 +91,7 @@ func (a *API) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -189,7 +189,7 @@ func (a *API) handleUpdateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -250,7 +250,7 @@ func (a *API) handleDeleteCategory(w http.ResponseWriter, r *http.Request) {
 	auditRec := a.makeAuditRecord(r, "deleteCategory", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 
-	if !a.permissions.HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -309,7 +309,7 @@ func (a *API) handleGetUserCategoryBoards(w http.ResponseWriter, r *http.Request
 	auditRec := a.makeAuditRecord(r, "getUserCategoryBoards", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 
-	if !a.permissions.HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -376,7 +376,7 @@ func (a *API) handleUpdateCategoryBoard(w http.ResponseWriter, r *http.Request)
 	session := ctx.Value(sessionContextKey).(*model.Session)
 	userID := session.UserID
 
-	if !a.permissions.HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(session.UserID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -423,7 +423,7 @@ func (a *API) handleReorderCategories(w http.ResponseWriter, r *http.Request) {
 	session := ctx.Value(sessionContextKey).(*model.Session)
 	userID := session.UserID
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to category"))
 		return
 	}
@@ -501,7 +501,7 @@ func (a *API) handleReorderCategoryBoards(w http.ResponseWriter, r *http.Request
 	session := ctx.Value(sessionContextKey).(*model.Session)
 	userID := session.UserID
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to category"))
 		return
 	}
@@ -591,7 +591,7 @@ func (a *API) handleHideBoard(w http.ResponseWriter, r *http.Request) {
 	boardID := vars["boardID"]
 	categoryID := vars["categoryID"]
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to category"))
 		return
 	}
@@ -653,7 +653,7 @@ func (a *API) handleUnhideBoard(w http.ResponseWriter, r *http.Request) {
 	boardID := vars["boardID"]
 	categoryID := vars["categoryID"]
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to category"))
 		return
 	}
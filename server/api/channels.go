@@ -59,12 +59,12 @@ func (a *API) handleGetChannel(w http.ResponseWriter, r *http.Request) {
 	channelID := mux.Vars(r)["channelID"]
 	userID := getUserID(r)
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
 
-	if !a.permissions.HasPermissionToChannel(userID, channelID, model.PermissionReadChannel) {
+	if !a.permissionsFor(r).HasPermissionToChannel(userID, channelID, model.PermissionReadChannel) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to channel"))
 		return
 	}
@@ -72,7 +72,7 @@ func (a *API) handleGetBoardsForCompliance(w http.ResponseWriter, r *http.Reques
 
 	// check for permission `manage_system`
 	userID := getUserID(r)
-	if !a.permissions.HasPermissionTo(userID, mm_model.PermissionManageSystem) {
+	if !a.permissionsFor(r).HasPermissionTo(userID, mm_model.PermissionManageSystem) {
 		a.errorResponse(w, r, model.NewErrUnauthorized("access denied Compliance Export getAllBoards"))
 		return
 	}
@@ -207,7 +207,7 @@ func (a *API) handleGetBoardsComplianceHistory(w http.ResponseWriter, r *http.Re
 
 	// check for permission `manage_system`
 	userID := getUserID(r)
-	if !a.permissions.HasPermissionTo(userID, mm_model.PermissionManageSystem) {
+	if !a.permissionsFor(r).HasPermissionTo(userID, mm_model.PermissionManageSystem) {
 		a.errorResponse(w, r, model.NewErrUnauthorized("access denied Compliance Export getBoardsHistory"))
 		return
 	}
@@ -356,7 +356,7 @@ func (a *API) handleGetBlocksComplianceHistory(w http.ResponseWriter, r *http.Re
 
 	// check for permission `manage_system`
 	userID := getUserID(r)
-	if !a.permissions.HasPermissionTo(userID, mm_model.PermissionManageSystem) {
+	if !a.permissionsFor(r).HasPermissionTo(userID, mm_model.PermissionManageSystem) {
 		a.errorResponse(w, r, model.NewErrUnauthorized("access denied Compliance Export getBlocksHistory"))
 		return
 	}
@@ -80,11 +80,16 @@ func (a *API) handleMoveBlockTo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, block.BoardID, model.PermissionManageBoardCards) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, block.BoardID, model.PermissionManageBoardCards) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to modify board cards"))
 		return
 	}
 
+	if err = a.checkBlockVisible(block, userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
 	auditRec := a.makeAuditRecord(r, "moveBlockTo", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("blockID", blockID)
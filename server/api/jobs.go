@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	jobsDefaultPage    = "0"
+	jobsDefaultPerPage = "60"
+)
+
+func (a *API) handleAdminGetJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	jobType := query.Get("type")
+	strStatus := query.Get("status")
+	strPage := query.Get("page")
+	strPerPage := query.Get("per_page")
+
+	if strPage == "" {
+		strPage = jobsDefaultPage
+	}
+	if strPerPage == "" {
+		strPerPage = jobsDefaultPerPage
+	}
+	page, err := strconv.Atoi(strPage)
+	if err != nil || page < 0 {
+		message := fmt.Sprintf("invalid `page` parameter: %s", strPage)
+		a.errorResponse(w, r, model.NewErrBadRequest(message))
+		return
+	}
+	perPage, err := strconv.Atoi(strPerPage)
+	if err != nil || perPage < 1 {
+		message := fmt.Sprintf("invalid `per_page` parameter: %s", strPerPage)
+		a.errorResponse(w, r, model.NewErrBadRequest(message))
+		return
+	}
+
+	opts := model.QueryJobsOptions{
+		Type:    jobType,
+		Page:    page,
+		PerPage: perPage,
+	}
+	if strStatus != "" {
+		opts.Statuses = strings.Split(strStatus, ",")
+	}
+
+	jobs, err := a.app.GetJobs(opts)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("AdminGetJobs",
+		mlog.String("type", jobType),
+		mlog.Int("jobsCount", len(jobs)),
+	)
+
+	data, err := json.Marshal(jobs)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleAdminGetJob(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["jobID"]
+
+	job, err := a.app.GetJob(jobID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleAdminTriggerJob(w http.ResponseWriter, r *http.Request) {
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var requestData model.TriggerJobRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "adminTriggerJob", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("type", requestData.Type)
+
+	if requestData.Type == "" {
+		a.errorResponse(w, r, model.NewErrBadRequest("type is required"))
+		return
+	}
+
+	job, err := a.app.TriggerJob(requestData.Type, getUserID(r))
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("AdminTriggerJob",
+		mlog.String("type", job.Type),
+		mlog.String("jobID", job.ID),
+	)
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("jobID", job.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleAdminCancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["jobID"]
+
+	auditRec := a.makeAuditRecord(r, "adminCancelJob", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("jobID", jobID)
+
+	job, err := a.app.CancelJob(jobID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("AdminCancelJob", mlog.String("jobID", jobID))
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
@@ -52,7 +52,7 @@ func (a *API) handleGetMembersForBoard(w http.ResponseWriter, r *http.Request) {
 	boardID := mux.Vars(r)["boardID"]
 	userID := getUserID(r)
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to board members"))
 		return
 	}
@@ -127,8 +127,8 @@ func (a *API) handleAddMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) &&
-		!(board.Type == model.BoardTypeOpen && a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardProperties)) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) &&
+		!(board.Type == model.BoardTypeOpen && a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardProperties)) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to modify board members"))
 		return
 	}
@@ -150,7 +150,7 @@ func (a *API) handleAddMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToTeam(reqBoardMember.UserID, board.TeamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(reqBoardMember.UserID, board.TeamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -162,6 +162,17 @@ func (a *API) handleAddMember(w http.ResponseWriter, r *http.Request) {
 		SchemeAdmin:     reqBoardMember.SchemeAdmin,
 		SchemeViewer:    reqBoardMember.SchemeViewer,
 		SchemeCommenter: reqBoardMember.SchemeCommenter,
+		CustomRoleID:    reqBoardMember.CustomRoleID,
+	}
+
+	isGuest, err := a.userIsGuest(reqBoardMember.UserID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	if isGuest {
+		newBoardMember.SchemeAdmin = false
 	}
 
 	auditRec := a.makeAuditRecord(r, "addMember", audit.Fail)
@@ -245,14 +256,14 @@ func (a *API) handleJoinBoard(w http.ResponseWriter, r *http.Request) {
 
 	isAdmin := false
 	if board.Type != model.BoardTypeOpen {
-		if !allowAdmin || !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionManageTeam) {
+		if !allowAdmin || !a.permissionsFor(r).HasPermissionToTeam(userID, board.TeamID, model.PermissionManageTeam) {
 			a.errorResponse(w, r, model.NewErrPermission("cannot join a non Open board"))
 			return
 		}
 		isAdmin = true
 	}
 
-	if !a.permissions.HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, board.TeamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -340,7 +351,7 @@ func (a *API) handleLeaveBoard(w http.ResponseWriter, r *http.Request) {
 
 	boardID := mux.Vars(r)["boardID"]
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionViewBoard) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to board"))
 		return
 	}
@@ -432,6 +443,7 @@ func (a *API) handleUpdateMember(w http.ResponseWriter, r *http.Request) {
 		SchemeEditor:    reqBoardMember.SchemeEditor,
 		SchemeCommenter: reqBoardMember.SchemeCommenter,
 		SchemeViewer:    reqBoardMember.SchemeViewer,
+		CustomRoleID:    reqBoardMember.CustomRoleID,
 	}
 
 	isGuest, err := a.userIsGuest(paramsUserID)
@@ -444,7 +456,7 @@ func (a *API) handleUpdateMember(w http.ResponseWriter, r *http.Request) {
 		newBoardMember.SchemeAdmin = false
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to modify board members"))
 		return
 	}
@@ -517,7 +529,7 @@ func (a *API) handleDeleteMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
+	if !a.permissionsFor(r).HasPermissionToBoard(userID, boardID, model.PermissionManageBoardRoles) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to modify board members"))
 		return
 	}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (a *API) registerMfaRoutes(r *mux.Router) {
+	// personal-server specific routes. These are not needed in plugin mode.
+	r.HandleFunc("/users/me/mfa/generate", a.sessionRequired(a.handleGenerateMfaSecret)).Methods("POST")
+	r.HandleFunc("/users/me/mfa/activate", a.sessionRequired(a.handleActivateMfa)).Methods("POST")
+	r.HandleFunc("/users/me/mfa/deactivate", a.sessionRequired(a.handleDeactivateMfa)).Methods("POST")
+	r.HandleFunc("/users/me/mfa/recovery_codes", a.sessionRequired(a.handleRegenerateMfaRecoveryCodes)).Methods("POST")
+}
+
+// mfaTokenError turns a failed MFA verification into a bad request, as
+// the caller is already authenticated.
+func mfaTokenError(err error) error {
+	if errors.Is(err, app.ErrMfaRequired) || errors.Is(err, app.ErrInvalidMfaToken) {
+		return model.NewErrBadRequest(err.Error())
+	}
+	return err
+}
+
+func (a *API) handleGenerateMfaSecret(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/me/mfa/generate generateMfaSecret
+	//
+	// Starts the MFA enrolment of the current user by generating a new
+	// secret. MFA is not active until the secret is confirmed
+	//
+	// ---
+	// produces:
+	// - application/json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/MfaEnrollment"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	userID := getUserID(r)
+
+	auditRec := a.makeAuditRecord(r, "generateMfaSecret", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	enrollment, err := a.app.GenerateMfaSecret(userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(enrollment)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleActivateMfa(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/me/mfa/activate activateMfa
+	//
+	// Activates MFA for the current user by confirming a code of the
+	// authenticator app. The recovery codes are only returned in this
+	// response
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: MFA code
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/MfaCodeRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/MfaRecoveryCodes"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	request, err := model.MfaCodeRequestFromJSON(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	userID := getUserID(r)
+
+	auditRec := a.makeAuditRecord(r, "activateMfa", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	recoveryCodes, err := a.app.ActivateMfa(userID, request.Code)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("ActivateMfa", mlog.String("userID", userID))
+
+	data, err := json.Marshal(model.MfaRecoveryCodes{RecoveryCodes: recoveryCodes})
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleDeactivateMfa(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/me/mfa/deactivate deactivateMfa
+	//
+	// Deactivates MFA for the current user
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: MFA code or recovery code
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/MfaCodeRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	request, err := model.MfaCodeRequestFromJSON(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	userID := getUserID(r)
+
+	auditRec := a.makeAuditRecord(r, "deactivateMfa", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	if err := a.app.DeactivateMfa(userID, request.Code); err != nil {
+		a.errorResponse(w, r, mfaTokenError(err))
+		return
+	}
+
+	a.logger.Debug("DeactivateMfa", mlog.String("userID", userID))
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleRegenerateMfaRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/me/mfa/recovery_codes regenerateMfaRecoveryCodes
+	//
+	// Replaces the MFA recovery codes of the current user. The new codes
+	// are only returned in this response
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: MFA code or recovery code
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/MfaCodeRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/MfaRecoveryCodes"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	request, err := model.MfaCodeRequestFromJSON(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	userID := getUserID(r)
+
+	auditRec := a.makeAuditRecord(r, "regenerateMfaRecoveryCodes", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	recoveryCodes, err := a.app.RegenerateMfaRecoveryCodes(userID, request.Code)
+	if err != nil {
+		a.errorResponse(w, r, mfaTokenError(err))
+		return
+	}
+
+	data, err := json.Marshal(model.MfaRecoveryCodes{RecoveryCodes: recoveryCodes})
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
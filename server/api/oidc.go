@@ -0,0 +1,209 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/oidc"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	oidcStateCookie       = "FOCALBOARDOIDCSTATE"
+	oidcStateCookieMaxAge = 10 * 60
+)
+
+// oidcLoginState is kept in a short lived cookie between the redirect
+// to the provider and the callback.
+type oidcLoginState struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+	Redirect     string `json:"redirect"`
+}
+
+func (a *API) registerOIDCRoutes(r *mux.Router) {
+	// OIDC routes are reached through browser redirects, which don't
+	// carry the CSRF header, so they live outside the /api/v2 path.
+	r.HandleFunc("/oidc/login", a.handleOIDCLogin).Methods("GET")
+	r.HandleFunc(oidc.CallbackPath, a.handleOIDCCallback).Methods("GET")
+}
+
+func (a *API) checkOIDCEnabled() error {
+	if a.MattermostAuth || a.authService != model.AuthModeOIDC {
+		return model.NewErrNotImplemented("OIDC authentication is not enabled")
+	}
+	return nil
+}
+
+// basePath returns the path of the server root, without trailing slash.
+func (a *API) basePath() string {
+	serverRoot, err := url.Parse(a.app.GetConfig().ServerRoot)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(serverRoot.Path, "/")
+}
+
+// localRedirect returns the redirect if it is a path on this server.
+func (a *API) localRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return a.basePath() + "/"
+	}
+	return redirect
+}
+
+func (a *API) setCookie(w http.ResponseWriter, name, value, path string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   a.app.GetConfig().SecureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (a *API) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /oidc/login oidcLogin
+	//
+	// Redirects the user to the OpenID Connect provider to log in
+	//
+	// ---
+	// parameters:
+	// - name: redirect
+	//   in: query
+	//   description: Path to redirect to once logged in
+	//   required: false
+	//   type: string
+	// responses:
+	//   '302':
+	//     description: redirect to the provider
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkOIDCEnabled(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	loginState := oidcLoginState{Redirect: a.localRedirect(r.URL.Query().Get("redirect"))}
+	for _, value := range []*string{&loginState.State, &loginState.Nonce, &loginState.CodeVerifier} {
+		random, err := oidc.NewRandomValue()
+		if err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+		*value = random
+	}
+
+	authURL, err := a.app.GetOIDCAuthCodeURL(loginState.State, loginState.Nonce, oidc.CodeChallenge(loginState.CodeVerifier))
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(loginState)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.setCookie(w, oidcStateCookie, base64.RawURLEncoding.EncodeToString(data), a.basePath()+"/oidc", oidcStateCookieMaxAge)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (a *API) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /oidc/callback oidcCallback
+	//
+	// Completes the OpenID Connect login, creating the user on their
+	// first login, and redirects to the application
+	//
+	// ---
+	// parameters:
+	// - name: code
+	//   in: query
+	//   description: Authorization code
+	//   required: true
+	//   type: string
+	// - name: state
+	//   in: query
+	//   description: Login state
+	//   required: true
+	//   type: string
+	// responses:
+	//   '302':
+	//     description: logged in, redirect to the application
+	//   '401':
+	//     description: invalid login
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkOIDCEnabled(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "oidcLogin", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("missing OIDC login state"))
+		return
+	}
+	a.setCookie(w, oidcStateCookie, "", a.basePath()+"/oidc", -1)
+
+	var loginState oidcLoginState
+	data, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err == nil {
+		err = json.Unmarshal(data, &loginState)
+	}
+	if err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid OIDC login state"))
+		return
+	}
+
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		auditRec.AddMeta("providerError", providerError)
+		a.errorResponse(w, r, model.NewErrUnauthorized("OIDC login failed: "+providerError))
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(loginState.State)) != 1 {
+		a.errorResponse(w, r, model.NewErrBadRequest("invalid OIDC login state"))
+		return
+	}
+
+	token, err := a.app.LoginWithOIDC(query.Get("code"), loginState.CodeVerifier, loginState.Nonce, remoteIP(r), r.UserAgent())
+	if err != nil {
+		a.logger.Warn("OIDC login failed", mlog.Err(err))
+		if !model.IsErrForbidden(err) {
+			err = model.NewErrUnauthorized("OIDC login failed")
+		}
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.setCookie(w, auth.SessionCookieToken, token, a.basePath()+"/", int(a.app.GetConfig().SessionExpireTime))
+	auditRec.Success()
+
+	http.Redirect(w, r, a.localRedirect(loginState.Redirect), http.StatusFound)
+}
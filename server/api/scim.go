@@ -0,0 +1,688 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const scimContentType = "application/scim+json"
+
+func (a *API) registerSCIMRoutes(r *mux.Router) {
+	// SCIM clients are identity providers authenticated with the
+	// provisioning token, they don't carry the CSRF header, so the SCIM
+	// routes live outside the /api/v2 path.
+	scim := r.PathPrefix("/scim/v2").Subrouter()
+	scim.Use(a.panicHandler)
+
+	scim.HandleFunc("/ServiceProviderConfig", a.scimRequired(a.handleSCIMServiceProviderConfig)).Methods("GET")
+
+	scim.HandleFunc("/Users", a.scimRequired(a.handleSCIMGetUsers)).Methods("GET")
+	scim.HandleFunc("/Users", a.scimRequired(a.handleSCIMCreateUser)).Methods("POST")
+	scim.HandleFunc("/Users/{userID}", a.scimRequired(a.handleSCIMGetUser)).Methods("GET")
+	scim.HandleFunc("/Users/{userID}", a.scimRequired(a.handleSCIMReplaceUser)).Methods("PUT")
+	scim.HandleFunc("/Users/{userID}", a.scimRequired(a.handleSCIMPatchUser)).Methods("PATCH")
+	scim.HandleFunc("/Users/{userID}", a.scimRequired(a.handleSCIMDeleteUser)).Methods("DELETE")
+
+	scim.HandleFunc("/Groups", a.scimRequired(a.handleSCIMGetGroups)).Methods("GET")
+	scim.HandleFunc("/Groups", a.scimRequired(a.handleSCIMCreateGroup)).Methods("POST")
+	scim.HandleFunc("/Groups/{groupID}", a.scimRequired(a.handleSCIMGetGroup)).Methods("GET")
+	scim.HandleFunc("/Groups/{groupID}", a.scimRequired(a.handleSCIMReplaceGroup)).Methods("PUT")
+	scim.HandleFunc("/Groups/{groupID}", a.scimRequired(a.handleSCIMPatchGroup)).Methods("PATCH")
+	scim.HandleFunc("/Groups/{groupID}", a.scimRequired(a.handleSCIMDeleteGroup)).Methods("DELETE")
+}
+
+// scimRequired checks the provisioning token of the SCIM requests.
+func (a *API) scimRequired(handler func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := a.app.GetConfig().SCIM.Token
+		if a.MattermostAuth || token == "" {
+			a.scimErrorResponse(w, r, model.NewErrNotImplemented("SCIM provisioning is not enabled"))
+			return
+		}
+
+		authHeader := r.Header.Get("Authorization")
+		if len(authHeader) < 7 || !strings.EqualFold(authHeader[:7], "Bearer ") ||
+			subtle.ConstantTimeCompare([]byte(authHeader[7:]), []byte(token)) != 1 {
+			a.scimErrorResponse(w, r, model.NewErrUnauthorized("invalid provisioning token"))
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// scimErrorResponse writes an error in the SCIM format.
+func (a *API) scimErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	scimErr := model.SCIMError{
+		Schemas: []string{model.SCIMSchemaError},
+		Detail:  err.Error(),
+	}
+
+	var code int
+	switch {
+	case model.IsErrBadRequest(err):
+		code = http.StatusBadRequest
+		scimErr.ScimType = "invalidValue"
+	case model.IsErrUnauthorized(err):
+		code = http.StatusUnauthorized
+	case model.IsErrNotFound(err):
+		code = http.StatusNotFound
+	case model.IsErrConflict(err):
+		code = http.StatusConflict
+		scimErr.ScimType = "uniqueness"
+	case model.IsErrNotImplemented(err):
+		code = http.StatusNotImplemented
+	default:
+		a.logger.Error("SCIM API ERROR",
+			mlog.Int("code", http.StatusInternalServerError),
+			mlog.Err(err),
+			mlog.String("api", r.URL.Path),
+		)
+		code = http.StatusInternalServerError
+		scimErr.Detail = "internal server error"
+	}
+	scimErr.Status = strconv.Itoa(code)
+
+	data, err := json.Marshal(scimErr)
+	if err != nil {
+		data = []byte("{}")
+	}
+	scimBytesResponse(w, code, data)
+}
+
+func scimBytesResponse(w http.ResponseWriter, code int, data []byte) {
+	setResponseHeader(w, "Content-Type", scimContentType)
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+func (a *API) scimResponse(w http.ResponseWriter, r *http.Request, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+	scimBytesResponse(w, code, data)
+}
+
+// scimListParams returns the filter and the pagination of a SCIM list
+// request.
+func scimListParams(r *http.Request) (*model.SCIMFilter, int, int, error) {
+	query := r.URL.Query()
+
+	filter, err := model.ParseSCIMFilter(query.Get("filter"))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	startIndex := 1
+	if s := query.Get("startIndex"); s != "" {
+		if startIndex, err = strconv.Atoi(s); err != nil {
+			return nil, 0, 0, model.NewErrBadRequest("invalid startIndex")
+		}
+	}
+
+	count := model.SCIMMaxResults
+	if s := query.Get("count"); s != "" {
+		if count, err = strconv.Atoi(s); err != nil {
+			return nil, 0, 0, model.NewErrBadRequest("invalid count")
+		}
+	}
+
+	return filter, startIndex, count, nil
+}
+
+// scimWithMembers returns false if the members of the groups are
+// excluded from the response, which providers do to speed up lookups.
+func scimWithMembers(r *http.Request) bool {
+	for _, attribute := range strings.Split(r.URL.Query().Get("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attribute), "members") {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *API) handleSCIMServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /scim/v2/ServiceProviderConfig scimServiceProviderConfig
+	//
+	// Returns the SCIM features supported by the server
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '501':
+	//     description: SCIM provisioning is not enabled
+
+	a.scimResponse(w, r, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{model.SCIMSchemaServiceProviderConfig},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": model.SCIMMaxResults},
+		"changePassword": map[string]bool{"supported": true},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]string{{
+			"type":        "oauthbearertoken",
+			"name":        "Provisioning token",
+			"description": "The provisioning token of the server configuration, as a bearer token",
+		}},
+	})
+}
+
+func (a *API) handleSCIMGetUsers(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /scim/v2/Users scimGetUsers
+	//
+	// Returns the users matching a SCIM filter, such as `userName eq "john"`
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// parameters:
+	// - name: filter
+	//   in: query
+	//   description: SCIM equality filter on the id, userName or emails
+	//   required: false
+	//   type: string
+	// - name: startIndex
+	//   in: query
+	//   description: 1-based index of the first user
+	//   required: false
+	//   type: integer
+	// - name: count
+	//   in: query
+	//   description: Maximum number of users to return
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid filter
+	//   '401':
+	//     description: invalid provisioning token
+
+	filter, startIndex, count, err := scimListParams(r)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	list, err := a.app.GetSCIMUsers(filter, startIndex, count)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.scimResponse(w, r, http.StatusOK, list)
+}
+
+func (a *API) handleSCIMGetUser(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /scim/v2/Users/{userID} scimGetUser
+	//
+	// Returns a user, active or not
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: user not found
+
+	user, err := a.app.GetSCIMUser(mux.Vars(r)["userID"])
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.scimResponse(w, r, http.StatusOK, user)
+}
+
+func (a *API) handleSCIMCreateUser(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /scim/v2/Users scimCreateUser
+	//
+	// Provisions a user
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '201':
+	//     description: success
+	//   '400':
+	//     description: invalid user
+	//   '409':
+	//     description: the userName or the email is already used
+
+	scimUser, err := model.SCIMUserFromJSON(r.Body)
+	if err != nil {
+		a.scimErrorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "scimCreateUser", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("username", scimUser.UserName)
+
+	user, err := a.app.CreateSCIMUser(scimUser)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("SCIMCreateUser", mlog.String("userID", user.ID))
+
+	a.scimResponse(w, r, http.StatusCreated, user)
+	auditRec.AddMeta("userID", user.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleSCIMReplaceUser(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /scim/v2/Users/{userID} scimReplaceUser
+	//
+	// Replaces the attributes of a user
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: user not found
+	//   '409':
+	//     description: the userName or the email is already used
+
+	userID := mux.Vars(r)["userID"]
+
+	scimUser, err := model.SCIMUserFromJSON(r.Body)
+	if err != nil {
+		a.scimErrorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "scimReplaceUser", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("userID", userID)
+
+	user, err := a.app.ReplaceSCIMUser(userID, scimUser)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.scimResponse(w, r, http.StatusOK, user)
+	auditRec.Success()
+}
+
+func (a *API) handleSCIMPatchUser(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PATCH /scim/v2/Users/{userID} scimPatchUser
+	//
+	// Modifies the attributes of a user, setting `active` to false
+	// deactivates it
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid operation
+	//   '404':
+	//     description: user not found
+
+	userID := mux.Vars(r)["userID"]
+
+	patch, err := model.SCIMPatchRequestFromJSON(r.Body)
+	if err != nil {
+		a.scimErrorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "scimPatchUser", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("userID", userID)
+
+	user, err := a.app.PatchSCIMUser(userID, patch)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.scimResponse(w, r, http.StatusOK, user)
+	auditRec.Success()
+}
+
+func (a *API) handleSCIMDeleteUser(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /scim/v2/Users/{userID} scimDeleteUser
+	//
+	// Deactivates a user, the users are never deleted
+	//
+	// ---
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '204':
+	//     description: success
+	//   '404':
+	//     description: user not found
+
+	userID := mux.Vars(r)["userID"]
+
+	auditRec := a.makeAuditRecord(r, "scimDeleteUser", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("userID", userID)
+
+	if err := a.app.DeleteSCIMUser(userID); err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	auditRec.Success()
+}
+
+func (a *API) handleSCIMGetGroups(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /scim/v2/Groups scimGetGroups
+	//
+	// Returns the groups matching a SCIM filter, such as
+	// `displayName eq "Engineering"`. Each group is a team.
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// parameters:
+	// - name: filter
+	//   in: query
+	//   description: SCIM equality filter on the id or displayName
+	//   required: false
+	//   type: string
+	// - name: startIndex
+	//   in: query
+	//   description: 1-based index of the first group
+	//   required: false
+	//   type: integer
+	// - name: count
+	//   in: query
+	//   description: Maximum number of groups to return
+	//   required: false
+	//   type: integer
+	// - name: excludedAttributes
+	//   in: query
+	//   description: Set to members to omit the group members
+	//   required: false
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid filter
+
+	filter, startIndex, count, err := scimListParams(r)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	list, err := a.app.GetSCIMGroups(filter, startIndex, count, scimWithMembers(r))
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.scimResponse(w, r, http.StatusOK, list)
+}
+
+func (a *API) handleSCIMGetGroup(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /scim/v2/Groups/{groupID} scimGetGroup
+	//
+	// Returns a group
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// parameters:
+	// - name: groupID
+	//   in: path
+	//   description: Group ID, which is the team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: group not found
+
+	group, err := a.app.GetSCIMGroup(mux.Vars(r)["groupID"], scimWithMembers(r))
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.scimResponse(w, r, http.StatusOK, group)
+}
+
+func (a *API) handleSCIMCreateGroup(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /scim/v2/Groups scimCreateGroup
+	//
+	// Creates a team for a group, with its members
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '201':
+	//     description: success
+	//   '400':
+	//     description: invalid group
+	//   '409':
+	//     description: the displayName is already used
+
+	scimGroup, err := model.SCIMGroupFromJSON(r.Body)
+	if err != nil {
+		a.scimErrorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "scimCreateGroup", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("displayName", scimGroup.DisplayName)
+
+	group, err := a.app.CreateSCIMGroup(scimGroup)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("SCIMCreateGroup", mlog.String("teamID", group.ID))
+
+	a.scimResponse(w, r, http.StatusCreated, group)
+	auditRec.AddMeta("teamID", group.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleSCIMReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /scim/v2/Groups/{groupID} scimReplaceGroup
+	//
+	// Replaces the name and the members of a group
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// parameters:
+	// - name: groupID
+	//   in: path
+	//   description: Group ID, which is the team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: group not found
+	//   '409':
+	//     description: the displayName is already used
+
+	teamID := mux.Vars(r)["groupID"]
+
+	scimGroup, err := model.SCIMGroupFromJSON(r.Body)
+	if err != nil {
+		a.scimErrorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "scimReplaceGroup", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("teamID", teamID)
+
+	group, err := a.app.ReplaceSCIMGroup(teamID, scimGroup)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.scimResponse(w, r, http.StatusOK, group)
+	auditRec.Success()
+}
+
+func (a *API) handleSCIMPatchGroup(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PATCH /scim/v2/Groups/{groupID} scimPatchGroup
+	//
+	// Renames a group, or adds and removes its members
+	//
+	// ---
+	// produces:
+	// - application/scim+json
+	// parameters:
+	// - name: groupID
+	//   in: path
+	//   description: Group ID, which is the team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid operation
+	//   '404':
+	//     description: group not found
+
+	teamID := mux.Vars(r)["groupID"]
+
+	patch, err := model.SCIMPatchRequestFromJSON(r.Body)
+	if err != nil {
+		a.scimErrorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "scimPatchGroup", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("teamID", teamID)
+
+	group, err := a.app.PatchSCIMGroup(teamID, patch)
+	if err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	a.scimResponse(w, r, http.StatusOK, group)
+	auditRec.Success()
+}
+
+func (a *API) handleSCIMDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /scim/v2/Groups/{groupID} scimDeleteGroup
+	//
+	// Deletes the team of a group, which must not have boards
+	//
+	// ---
+	// parameters:
+	// - name: groupID
+	//   in: path
+	//   description: Group ID, which is the team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '204':
+	//     description: success
+	//   '404':
+	//     description: group not found
+	//   '409':
+	//     description: the team has boards
+
+	teamID := mux.Vars(r)["groupID"]
+
+	auditRec := a.makeAuditRecord(r, "scimDeleteGroup", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("teamID", teamID)
+
+	if err := a.app.DeleteSCIMGroup(teamID); err != nil {
+		a.scimErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	auditRec.Success()
+}
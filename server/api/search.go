@@ -62,7 +62,7 @@ func (a *API) handleSearchMyChannels(w http.ResponseWriter, r *http.Request) {
 	teamID := mux.Vars(r)["teamID"]
 	userID := getUserID(r)
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -147,7 +147,7 @@ func (a *API) handleSearchBoards(w http.ResponseWriter, r *http.Request) {
 	}
 	userID := getUserID(r)
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -235,7 +235,7 @@ func (a *API) handleSearchLinkableBoards(w http.ResponseWriter, r *http.Request)
 	term := r.URL.Query().Get("q")
 	userID := getUserID(r)
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -258,7 +258,7 @@ func (a *API) handleSearchLinkableBoards(w http.ResponseWriter, r *http.Request)
 
 	linkableBoards := []*model.Board{}
 	for _, board := range boards {
-		if a.permissions.HasPermissionToBoard(userID, board.ID, model.PermissionManageBoardRoles) {
+		if a.permissionsFor(r).HasPermissionToBoard(userID, board.ID, model.PermissionManageBoardRoles) {
 			linkableBoards = append(linkableBoards, board)
 		}
 	}
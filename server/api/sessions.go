@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (a *API) registerSessionsRoutes(r *mux.Router) {
+	// personal-server specific routes. These are not needed in plugin mode.
+	r.HandleFunc("/users/me/sessions", a.sessionRequired(a.handleGetSessions)).Methods("GET")
+	r.HandleFunc("/users/me/sessions/{sessionID}", a.sessionRequired(a.handleRevokeSession)).Methods("DELETE")
+}
+
+func (a *API) handleGetSessions(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /users/me/sessions getSessions
+	//
+	// Returns the active sessions of the current user, with the user
+	// agent, the IP address and the last activity of each of them
+	//
+	// ---
+	// produces:
+	// - application/json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Session"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	userID := getUserID(r)
+
+	auditRec := a.makeAuditRecord(r, "getSessions", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+
+	sessions, err := a.app.GetUserSessions(userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(sessions)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("sessionCount", len(sessions))
+	auditRec.Success()
+}
+
+func (a *API) handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /users/me/sessions/{sessionID} revokeSession
+	//
+	// Revokes a session of the current user, logging out its device
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: sessionID
+	//   in: path
+	//   description: Session ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: session not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if err := a.checkLoginSession(r); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	sessionID := mux.Vars(r)["sessionID"]
+	userID := getUserID(r)
+
+	auditRec := a.makeAuditRecord(r, "revokeSession", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("sessionID", sessionID)
+
+	if err := a.app.RevokeSession(userID, sessionID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("RevokeSession",
+		mlog.String("userID", userID),
+		mlog.String("sessionID", sessionID),
+	)
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleAdminRevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	username := vars["username"]
+
+	auditRec := a.makeAuditRecord(r, "adminRevokeUserSessions", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("username", username)
+
+	err := a.app.RevokeAllUserSessions(username)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	a.logger.Debug("AdminRevokeUserSessions, username: %s", mlog.String("username", username))
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
@@ -40,7 +40,7 @@ func (a *API) handleStatistics(w http.ResponseWriter, r *http.Request) {
 
 	// user must have right to access analytics
 	userID := getUserID(r)
-	if !a.permissions.HasPermissionTo(userID, mmModel.PermissionGetAnalytics) {
+	if !a.permissionsFor(r).HasPermissionTo(userID, mmModel.PermissionGetAnalytics) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied System Statistics"))
 		return
 	}
@@ -9,6 +9,8 @@ import (
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/audit"
 	"github.com/mattermost/focalboard/server/utils"
+
+	mm_model "github.com/mattermost/mattermost/server/public/model"
 )
 
 func (a *API) registerTeamsRoutes(r *mux.Router) {
@@ -18,6 +20,13 @@ func (a *API) registerTeamsRoutes(r *mux.Router) {
 	r.HandleFunc("/teams/{teamID}/users", a.sessionRequired(a.handleGetTeamUsers)).Methods("GET")
 	r.HandleFunc("/teams/{teamID}/users", a.sessionRequired(a.handleGetTeamUsersByID)).Methods("POST")
 	r.HandleFunc("/teams/{teamID}/archive/export", a.sessionRequired(a.handleArchiveExportTeam)).Methods("GET")
+
+	// personal-server specific routes. These are not needed in plugin mode.
+	r.HandleFunc("/teams", a.sessionRequired(a.handleCreateTeam)).Methods("POST")
+	r.HandleFunc("/teams/invites/accept", a.sessionRequired(a.handleAcceptTeamInvite)).Methods("POST")
+	r.HandleFunc("/teams/{teamID}/members", a.sessionRequired(a.handleGetTeamMembers)).Methods("GET")
+	r.HandleFunc("/teams/{teamID}/members/{userID}", a.sessionRequired(a.handleDeleteTeamMember)).Methods("DELETE")
+	r.HandleFunc("/teams/{teamID}/invites", a.sessionRequired(a.handleInviteToTeam)).Methods("POST")
 }
 
 func (a *API) handleGetTeams(w http.ResponseWriter, r *http.Request) {
@@ -66,7 +75,7 @@ func (a *API) handleGetTeams(w http.ResponseWriter, r *http.Request) {
 func (a *API) handleGetTeam(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation GET /teams/{teamID} getTeam
 	//
-	// Returns information of the root team
+	// Returns information of a team
 	//
 	// ---
 	// produces:
@@ -93,7 +102,7 @@ func (a *API) handleGetTeam(w http.ResponseWriter, r *http.Request) {
 	teamID := vars["teamID"]
 	userID := getUserID(r)
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -109,12 +118,22 @@ func (a *API) handleGetTeam(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			a.errorResponse(w, r, err)
 		}
-	} else {
+	} else if teamID == model.GlobalTeamID {
 		team, err = a.app.GetRootTeam()
 		if err != nil {
 			a.errorResponse(w, r, err)
 			return
 		}
+	} else {
+		team, err = a.app.GetTeam(teamID)
+		if err != nil {
+			a.errorResponse(w, r, err)
+			return
+		}
+		if team == nil {
+			a.errorResponse(w, r, model.NewErrNotFound("team ID="+teamID))
+			return
+		}
 	}
 
 	auditRec := a.makeAuditRecord(r, "getTeam", audit.Fail)
@@ -224,7 +243,7 @@ func (a *API) handleGetTeamUsers(w http.ResponseWriter, r *http.Request) {
 	searchQuery := query.Get("search")
 	excludeBots := r.URL.Query().Get("exclude_bots") == True
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -312,7 +331,7 @@ func (a *API) handleGetTeamUsersByID(w http.ResponseWriter, r *http.Request) {
 	teamID := vars["teamID"]
 	userID := getUserID(r)
 
-	if !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -343,6 +362,8 @@ func (a *API) handleGetTeamUsersByID(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// the token scopes of the caller don't change the permissions of
+		// the users it gets
 		for i, u := range users {
 			if a.permissions.HasPermissionToTeam(u.ID, teamID, model.PermissionManageTeam) {
 				users[i].Permissions = append(users[i].Permissions, model.PermissionManageTeam.Id)
@@ -362,3 +383,351 @@ func (a *API) handleGetTeamUsersByID(w http.ResponseWriter, r *http.Request) {
 	jsonStringResponse(w, http.StatusOK, string(usersList))
 	auditRec.Success()
 }
+
+func (a *API) handleCreateTeam(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /teams createTeam
+	//
+	// Creates a new team, with the current user as its admin. Only system
+	// admins can create teams
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: the team to create
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateTeamRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Team"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if a.MattermostAuth {
+		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted in plugin mode"))
+		return
+	}
+
+	userID := getUserID(r)
+
+	if !a.permissionsFor(r).HasPermissionTo(userID, mm_model.PermissionManageSystem) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to create teams"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var requestData model.CreateTeamRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if err = requestData.IsValid(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "createTeam", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+
+	team, err := a.app.CreateTeam(requestData.Title, userID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	auditRec.AddMeta("teamID", team.ID)
+
+	data, err := json.Marshal(team)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleGetTeamMembers(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /teams/{teamID}/members getTeamMembers
+	//
+	// Returns the members of a team. All the users are members of the
+	// root team, which has no member records
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/TeamMember"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if a.MattermostAuth {
+		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted in plugin mode"))
+		return
+	}
+
+	teamID := mux.Vars(r)["teamID"]
+	userID := getUserID(r)
+
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getTeamMembers", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("teamID", teamID)
+
+	members, err := a.app.GetTeamMembers(teamID)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	auditRec.AddMeta("memberCount", len(members))
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteTeamMember(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /teams/{teamID}/members/{userID} deleteTeamMember
+	//
+	// Removes a user from a team, and from the boards of the team. Team
+	// admins can remove any member, and the members can leave the team
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if a.MattermostAuth {
+		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted in plugin mode"))
+		return
+	}
+
+	vars := mux.Vars(r)
+	teamID := vars["teamID"]
+	memberID := vars["userID"]
+	userID := getUserID(r)
+
+	if memberID != userID && !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionManageTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to modify team members"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteTeamMember", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("teamID", teamID)
+	auditRec.AddMeta("memberID", memberID)
+
+	if err := a.app.RemoveTeamMember(teamID, memberID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleInviteToTeam(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /teams/{teamID}/invites inviteToTeam
+	//
+	// Sends invites to join a team by email. The invites expire, and can
+	// only be used once
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: teamID
+	//   in: path
+	//   description: Team ID
+	//   required: true
+	//   type: string
+	// - name: body
+	//   in: body
+	//   description: the addresses to invite
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/TeamInviteRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '501':
+	//     description: sending emails is not configured
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if a.MattermostAuth {
+		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted in plugin mode"))
+		return
+	}
+
+	teamID := mux.Vars(r)["teamID"]
+	userID := getUserID(r)
+
+	if !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionManageTeam) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to invite team members"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var requestData model.TeamInviteRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if err = requestData.IsValid(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "inviteToTeam", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("teamID", teamID)
+	auditRec.AddMeta("inviteCount", len(requestData.Emails))
+	auditRec.AddMeta("role", requestData.Role)
+
+	if err = a.app.InviteToTeam(teamID, userID, requestData.Emails, requestData.Role); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleAcceptTeamInvite(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /teams/invites/accept acceptTeamInvite
+	//
+	// Joins the team of an invite sent to the email address of the
+	// current user
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: the invite to accept
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/TeamInviteAcceptRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Team"
+	//   '400':
+	//     description: invalid or expired invite
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if a.MattermostAuth {
+		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted in plugin mode"))
+		return
+	}
+
+	userID := getUserID(r)
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var requestData model.TeamInviteAcceptRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if err = requestData.IsValid(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "acceptTeamInvite", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+
+	team, err := a.app.AcceptTeamInvite(userID, requestData.Token)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	auditRec.AddMeta("teamID", team.ID)
+
+	data, err := json.Marshal(team)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
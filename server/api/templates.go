@@ -46,7 +46,7 @@ func (a *API) handleGetTemplates(w http.ResponseWriter, r *http.Request) {
 	teamID := mux.Vars(r)["teamID"]
 	userID := getUserID(r)
 
-	if teamID != model.GlobalTeamID && !a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
+	if teamID != model.GlobalTeamID && !a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionViewTeam) {
 		a.errorResponse(w, r, model.NewErrPermission("access denied to team"))
 		return
 	}
@@ -76,7 +76,7 @@ func (a *API) handleGetTemplates(w http.ResponseWriter, r *http.Request) {
 	for _, board := range boards {
 		if board.Type == model.BoardTypeOpen {
 			results = append(results, board)
-		} else if a.permissions.HasPermissionToBoard(userID, board.ID, model.PermissionViewBoard) {
+		} else if a.permissionsFor(r).HasPermissionToBoard(userID, board.ID, model.PermissionViewBoard) {
 			results = append(results, board)
 		}
 	}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+)
+
+func (a *API) registerUserTokensRoutes(r *mux.Router) {
+	// personal-server specific routes. These are not needed in plugin mode.
+	r.HandleFunc("/users/password/reset/send", a.handleSendPasswordReset).Methods("POST")
+	r.HandleFunc("/users/password/reset", a.handleResetPassword).Methods("POST")
+	r.HandleFunc("/users/email/verify/send", a.handleSendEmailVerification).Methods("POST")
+	r.HandleFunc("/users/email/verify", a.handleVerifyEmail).Methods("POST")
+}
+
+// checkNativeAuth returns an error if the passwords of the users are not
+// managed by the server.
+func (a *API) checkNativeAuth() error {
+	if a.MattermostAuth {
+		return model.NewErrNotImplemented("not permitted in plugin mode")
+	}
+
+	if len(a.singleUserToken) > 0 {
+		return model.NewErrUnauthorized("not permitted in single-user mode")
+	}
+
+	if a.authService == model.AuthModeOIDC || a.authService == model.AuthModeLDAP {
+		return model.NewErrNotImplemented("not permitted when using " + strings.ToUpper(a.authService) + " authentication")
+	}
+
+	return nil
+}
+
+func (a *API) handleSendPasswordReset(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/password/reset/send sendPasswordReset
+	//
+	// Emails a password reset link to the user with the given email
+	// address. The response is the same whether the user exists or not
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: Password reset link request
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PasswordResetSendRequest"
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid request
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '501':
+	//     description: sending emails is not configured
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if err := a.checkNativeAuth(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var requestData model.PasswordResetSendRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if err = requestData.IsValid(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "sendPasswordReset", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("email", requestData.Email)
+
+	if err = a.app.SendPasswordReset(strings.TrimSpace(requestData.Email)); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/password/reset resetPassword
+	//
+	// Sets a new password with the token of a password reset link. The
+	// new password must follow the password policy of the server
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: Password reset request
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PasswordResetRequest"
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid or expired token, or invalid password
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if err := a.checkNativeAuth(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var requestData model.PasswordResetRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if err = requestData.IsValid(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "resetPassword", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	if err = a.app.ResetPassword(requestData.Token, requestData.NewPassword); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleSendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/email/verify/send sendEmailVerification
+	//
+	// Emails a new verification link to the user with the given email
+	// address. The response is the same whether the user exists or not
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: Email verification link request
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/EmailVerificationSendRequest"
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid request
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '501':
+	//     description: sending emails is not configured
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if err := a.checkNativeAuth(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var requestData model.EmailVerificationSendRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if err = requestData.IsValid(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "sendEmailVerification", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("email", requestData.Email)
+
+	if err = a.app.SendEmailVerification(strings.TrimSpace(requestData.Email)); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/email/verify verifyEmail
+	//
+	// Verifies the email address of a user with the token of a
+	// verification link
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: Email verification request
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/EmailVerificationRequest"
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid or expired token
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if err := a.checkNativeAuth(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var requestData model.EmailVerificationRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if err = requestData.IsValid(); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "verifyEmail", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	if err = a.app.VerifyEmail(requestData.Token); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
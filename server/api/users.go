@@ -19,6 +19,7 @@ func (a *API) registerUsersRoutes(r *mux.Router) {
 	r.HandleFunc("/users/{userID}", a.sessionRequired(a.handleGetUser)).Methods("GET")
 	r.HandleFunc("/users/{userID}/config", a.sessionRequired(a.handleUpdateUserConfig)).Methods(http.MethodPut)
 	r.HandleFunc("/users/me/config", a.sessionRequired(a.handleGetUserPreferences)).Methods(http.MethodGet)
+	r.HandleFunc("/users/{userID}/deactivate", a.sessionRequired(a.handleDeactivateUser)).Methods("POST")
 }
 
 func (a *API) handleGetUsersList(w http.ResponseWriter, r *http.Request) {
@@ -91,7 +92,7 @@ func (a *API) handleGetUsersList(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 	session := ctx.Value(sessionContextKey).(*model.Session)
-	isSystemAdmin := a.permissions.HasPermissionTo(session.UserID, model.PermissionManageSystem)
+	isSystemAdmin := a.permissionsFor(r).HasPermissionTo(session.UserID, model.PermissionManageSystem)
 
 	sanitizedUsers := make([]*model.User, 0)
 	for _, user := range users {
@@ -182,13 +183,13 @@ func (a *API) handleGetMe(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if teamID != "" && a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionManageTeam) {
+	if teamID != "" && a.permissionsFor(r).HasPermissionToTeam(userID, teamID, model.PermissionManageTeam) {
 		user.Permissions = append(user.Permissions, model.PermissionManageTeam.Id)
 	}
-	if a.permissions.HasPermissionTo(userID, model.PermissionManageSystem) {
+	if a.permissionsFor(r).HasPermissionTo(userID, model.PermissionManageSystem) {
 		user.Permissions = append(user.Permissions, model.PermissionManageSystem.Id)
 	}
-	if channelID != "" && a.permissions.HasPermissionToChannel(userID, channelID, model.PermissionCreatePost) {
+	if channelID != "" && a.permissionsFor(r).HasPermissionToChannel(userID, channelID, model.PermissionCreatePost) {
 		user.Permissions = append(user.Permissions, model.PermissionCreatePost.Id)
 	}
 
@@ -304,7 +305,7 @@ func (a *API) handleGetUser(w http.ResponseWriter, r *http.Request) {
 	if userID == session.UserID {
 		user.Sanitize(map[string]bool{})
 	} else {
-		a.app.SanitizeProfile(user, a.permissions.HasPermissionTo(session.UserID, model.PermissionManageSystem))
+		a.app.SanitizeProfile(user, a.permissionsFor(r).HasPermissionTo(session.UserID, model.PermissionManageSystem))
 	}
 
 	userData, err := json.Marshal(user)
@@ -431,3 +432,96 @@ func (a *API) handleGetUserPreferences(w http.ResponseWriter, r *http.Request) {
 	jsonBytesResponse(w, http.StatusOK, data)
 	auditRec.Success()
 }
+
+func (a *API) handleDeactivateUser(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /users/{userID}/deactivate deactivateUser
+	//
+	// Deactivates a user, transferring the admin of their boards to another
+	// user. Their sessions are revoked, their person property values are
+	// reassigned or cleared and their subscriptions are removed. Only
+	// system admins can deactivate users
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// - name: body
+	//   in: body
+	//   description: who to transfer the boards to
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/DeactivateUserRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/DeactivateUserReport"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	if a.MattermostAuth {
+		a.errorResponse(w, r, model.NewErrNotImplemented("not permitted in plugin mode"))
+		return
+	}
+
+	userID := mux.Vars(r)["userID"]
+	session := r.Context().Value(sessionContextKey).(*model.Session)
+
+	if !a.permissionsFor(r).HasPermissionTo(session.UserID, model.PermissionManageSystem) {
+		a.errorResponse(w, r, model.NewErrPermission("access denied to deactivate users"))
+		return
+	}
+	if userID == session.UserID {
+		a.errorResponse(w, r, model.NewErrBadRequest("users can't deactivate themselves"))
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	var requestData model.DeactivateUserRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r, model.NewErrBadRequest(err.Error()))
+		return
+	}
+
+	if err = requestData.IsValid(userID); err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deactivateUser", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("deactivatedUserID", userID)
+	auditRec.AddMeta("transferTo", requestData.TransferTo)
+
+	report, err := a.app.DeactivateUser(userID, &requestData)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+	auditRec.AddMeta("revokedSessions", report.RevokedSessions)
+	auditRec.AddMeta("transferredBoards", report.TransferredBoards)
+	auditRec.AddMeta("updatedCards", len(report.UpdatedCards))
+	auditRec.AddMeta("removedSubscriptions", report.RemovedSubscriptions)
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		a.errorResponse(w, r, err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/pkg/errors"
+)
+
+// CreateAccessToken creates a new personal access token for a user. The
+// returned token is the only place where the plain token value is
+// available, as only its hash is stored.
+func (a *App) CreateAccessToken(userID string, request *model.AccessTokenCreateRequest) (*model.AccessToken, error) {
+	if err := request.IsValid(utils.GetMillis()); err != nil {
+		return nil, err
+	}
+
+	token := auth.NewAccessToken()
+	accessToken := &model.AccessToken{
+		ID:          utils.NewID(utils.IDTypeToken),
+		UserID:      userID,
+		Description: request.Description,
+		Scopes:      request.Scopes,
+		TokenHash:   auth.HashToken(token),
+		ExpiresAt:   request.ExpiresAt,
+	}
+
+	if err := a.store.CreateAccessToken(accessToken); err != nil {
+		return nil, errors.Wrap(err, "unable to create access token")
+	}
+
+	accessToken.Token = token
+	return accessToken, nil
+}
+
+// GetAccessTokensForUser returns the active access tokens of a user.
+func (a *App) GetAccessTokensForUser(userID string) ([]*model.AccessToken, error) {
+	return a.store.GetAccessTokensForUser(userID)
+}
+
+// RevokeAccessToken revokes an access token owned by the user.
+func (a *App) RevokeAccessToken(userID, tokenID string) error {
+	accessToken, err := a.store.GetAccessToken(tokenID)
+	if err != nil {
+		return err
+	}
+
+	if accessToken.UserID != userID {
+		return model.NewErrNotFound("access token ID=" + tokenID)
+	}
+
+	return a.store.DeleteAccessToken(tokenID)
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAccessToken(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("stores only the token hash", func(t *testing.T) {
+		var stored *model.AccessToken
+		th.Store.EXPECT().CreateAccessToken(gomock.Any()).DoAndReturn(func(token *model.AccessToken) error {
+			stored = token
+			require.Empty(t, token.Token)
+			return nil
+		})
+
+		request := &model.AccessTokenCreateRequest{
+			Description: "automation",
+			Scopes:      []string{model.AccessTokenScopeReadOnly},
+		}
+		token, err := th.App.CreateAccessToken("user-id", request)
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		require.True(t, model.IsAccessTokenString(token.Token))
+		require.Equal(t, auth.HashToken(token.Token), stored.TokenHash)
+		require.Equal(t, "user-id", token.UserID)
+		require.Equal(t, []string{model.AccessTokenScopeReadOnly}, token.Scopes)
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		request := &model.AccessTokenCreateRequest{Scopes: []string{"everything"}}
+		token, err := th.App.CreateAccessToken("user-id", request)
+		require.True(t, model.IsErrBadRequest(err))
+		require.Nil(t, token)
+	})
+
+	t.Run("expiry in the past", func(t *testing.T) {
+		request := &model.AccessTokenCreateRequest{ExpiresAt: utils.GetMillis() - 1000}
+		token, err := th.App.CreateAccessToken("user-id", request)
+		require.True(t, model.IsErrBadRequest(err))
+		require.Nil(t, token)
+	})
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	token := &model.AccessToken{ID: "token-id", UserID: "user-id"}
+
+	t.Run("owner can revoke", func(t *testing.T) {
+		th.Store.EXPECT().GetAccessToken("token-id").Return(token, nil)
+		th.Store.EXPECT().DeleteAccessToken("token-id").Return(nil)
+
+		require.NoError(t, th.App.RevokeAccessToken("user-id", "token-id"))
+	})
+
+	t.Run("other users cannot revoke", func(t *testing.T) {
+		th.Store.EXPECT().GetAccessToken("token-id").Return(token, nil)
+
+		err := th.App.RevokeAccessToken("other-user-id", "token-id")
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
@@ -6,9 +6,14 @@ import (
 	"time"
 
 	"github.com/mattermost/focalboard/server/auth"
+	authService "github.com/mattermost/focalboard/server/services/auth"
 	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/jobs"
+	"github.com/mattermost/focalboard/server/services/ldap"
+	"github.com/mattermost/focalboard/server/services/mail"
 	"github.com/mattermost/focalboard/server/services/metrics"
 	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/services/oidc"
 	"github.com/mattermost/focalboard/server/services/permissions"
 	"github.com/mattermost/focalboard/server/services/store"
 	"github.com/mattermost/focalboard/server/services/webhook"
@@ -50,6 +55,10 @@ type Services struct {
 	Notifications    *notify.Service
 	Logger           mlog.LoggerIFace
 	Permissions      permissions.PermissionsService
+	OIDC             *oidc.Provider
+	LDAP             *ldap.Service
+	Mail             *mail.Service
+	Jobs             *jobs.Service
 	SkipTemplateInit bool
 	ServicesAPI      servicesAPI
 }
@@ -67,6 +76,12 @@ type App struct {
 	permissions         permissions.PermissionsService
 	blockChangeNotifier *utils.CallbackQueue
 	servicesAPI         servicesAPI
+	oidc                *oidc.Provider
+	ldap                *ldap.Service
+	mail                *mail.Service
+	jobs                *jobs.Service
+	accountLimiter      *authService.LoginLimiter
+	ipLimiter           *authService.LoginLimiter
 
 	cardLimitMux sync.RWMutex
 	cardLimit    int
@@ -81,6 +96,7 @@ func (a *App) GetConfig() *config.Configuration {
 }
 
 func New(config *config.Configuration, wsAdapter ws.Adapter, services Services) *App {
+	accountLimiter, ipLimiter := newLoginLimiters(config.LoginLockout)
 	app := &App{
 		config:              config,
 		store:               services.Store,
@@ -94,6 +110,12 @@ func New(config *config.Configuration, wsAdapter ws.Adapter, services Services)
 		permissions:         services.Permissions,
 		blockChangeNotifier: utils.NewCallbackQueue("blockChangeNotifier", blockChangeNotifierQueueSize, blockChangeNotifierPoolSize, services.Logger),
 		servicesAPI:         services.ServicesAPI,
+		oidc:                services.OIDC,
+		ldap:                services.LDAP,
+		mail:                services.Mail,
+		jobs:                services.Jobs,
+		accountLimiter:      accountLimiter,
+		ipLimiter:           ipLimiter,
 	}
 	app.initialize(services.SkipTemplateInit)
 	return app
@@ -77,14 +77,67 @@ func (a *App) GetUsersList(userIDs []string) ([]*model.User, error) {
 }
 
 // Login create a new user session if the authentication data is valid.
-func (a *App) Login(username, email, password, mfaToken string) (string, error) {
+// Failed logins are delayed, and lock the account or the IP address once
+// there are too many of them. The IP address and the user agent of the
+// client are recorded on the session.
+func (a *App) Login(username, email, password, mfaToken, ipAddress, userAgent string) (string, error) {
+	accountKey := loginAccountKey(username, email)
+	if err := a.checkLoginLockout(accountKey, ipAddress); err != nil {
+		return "", err
+	}
+
+	var user *model.User
+	var err error
+	if ldapUsername, ok := a.ldapLoginUsername(username, email); ok {
+		user, err = a.loginWithLDAP(ldapUsername, password, mfaToken)
+	} else {
+		user, err = a.loginWithPassword(username, email, password, mfaToken)
+	}
+	if err != nil {
+		return "", a.loginFailed(accountKey, ipAddress, err)
+	}
+
+	a.accountLimiter.Reset(accountKey)
+	return a.createSession(user, ipAddress, userAgent)
+}
+
+// ldapLoginUsername returns the username to authenticate with the
+// directory, or false if the login must be checked against the password
+// of a native user. When LDAP is enabled, the LDAP users and the unknown
+// usernames are authenticated with the directory, and the native users,
+// such as the local admin, keep logging in with their password.
+func (a *App) ldapLoginUsername(username, email string) (string, bool) {
+	if a.ldap == nil {
+		return "", false
+	}
+
+	var user *model.User
+	var err error
+	if username != "" {
+		user, err = a.store.GetUserByUsername(username)
+		if model.IsErrNotFound(err) {
+			return username, true
+		}
+	} else if email != "" {
+		user, err = a.store.GetUserByEmail(email)
+	}
+	if err != nil || user == nil {
+		// the password login reports the error
+		return "", false
+	}
+
+	return user.Username, user.AuthService == model.AuthModeLDAP
+}
+
+// loginWithPassword checks the credentials of a native user.
+func (a *App) loginWithPassword(username, email, password, mfaToken string) (*model.User, error) {
 	var user *model.User
 	if username != "" {
 		var err error
 		user, err = a.store.GetUserByUsername(username)
 		if err != nil && !model.IsErrNotFound(err) {
 			a.metrics.IncrementLoginFailCount(1)
-			return "", errors.Wrap(err, "invalid username or password")
+			return nil, errors.Wrap(err, "invalid username or password")
 		}
 	}
 
@@ -93,24 +146,53 @@ func (a *App) Login(username, email, password, mfaToken string) (string, error)
 		user, err = a.store.GetUserByEmail(email)
 		if err != nil && model.IsErrNotFound(err) {
 			a.metrics.IncrementLoginFailCount(1)
-			return "", errors.Wrap(err, "invalid username or password")
+			return nil, errors.Wrap(err, "invalid username or password")
 		}
 	}
 
 	if user == nil {
 		a.metrics.IncrementLoginFailCount(1)
-		return "", errors.New("invalid username or password")
+		return nil, errors.New("invalid username or password")
 	}
 
 	if !auth.ComparePassword(user.Password, password) {
 		a.metrics.IncrementLoginFailCount(1)
 		a.logger.Debug("Invalid password for user", mlog.String("userID", user.ID))
-		return "", errors.New("invalid username or password")
+		return nil, errors.New("invalid username or password")
+	}
+
+	if a.config.RequireEmailVerification && !user.EmailVerified {
+		a.metrics.IncrementLoginFailCount(1)
+		return nil, ErrEmailNotVerified
+	}
+
+	if err := a.checkMfa(user, mfaToken); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkMfa verifies the MFA token of the users that have it enabled.
+func (a *App) checkMfa(user *model.User, mfaToken string) error {
+	if !user.MfaActive {
+		return nil
+	}
+
+	if err := a.verifyMfaToken(user, mfaToken); err != nil {
+		a.metrics.IncrementLoginFailCount(1)
+		a.logger.Debug("MFA verification failed for user", mlog.String("userID", user.ID), mlog.Err(err))
+		return err
 	}
+	return nil
+}
 
+// createSession creates a new session for an authenticated user and
+// returns its token.
+func (a *App) createSession(user *model.User, ipAddress, userAgent string) (string, error) {
 	authService := user.AuthService
 	if authService == "" {
-		authService = "native"
+		authService = model.AuthModeNative
 	}
 
 	session := model.Session{
@@ -119,6 +201,12 @@ func (a *App) Login(username, email, password, mfaToken string) (string, error)
 		UserID:      user.ID,
 		AuthService: authService,
 		Props:       map[string]interface{}{},
+		UserAgent:   truncateUserAgent(userAgent),
+		IPAddress:   ipAddress,
+	}
+	if a.isPasswordExpired(user) {
+		// the session can only be used to change the password
+		session.Props[model.SessionPropPasswordExpired] = true
 	}
 	err := a.store.CreateSession(&session)
 	if err != nil {
@@ -127,7 +215,6 @@ func (a *App) Login(username, email, password, mfaToken string) (string, error)
 
 	a.metrics.IncrementLoginCount(1)
 
-	// TODO: MFA verification
 	return session.Token, nil
 }
 
@@ -137,6 +224,7 @@ func (a *App) Logout(sessionID string) error {
 	if err != nil {
 		return errors.Wrap(err, "unable to delete the session")
 	}
+	a.wsAdapter.CloseSessions(sessionID)
 
 	a.metrics.IncrementLogoutCount(1)
 
@@ -145,15 +233,22 @@ func (a *App) Logout(sessionID string) error {
 
 // RegisterUser creates a new user if the provided data is valid.
 func (a *App) RegisterUser(username, email, password string) error {
+	_, err := a.registerUser(username, email, password, false, false)
+	return err
+}
+
+// registerUser creates a new user. The users whose email address is
+// already verified, like invited ones, don't get a verification link.
+func (a *App) registerUser(username, email, password string, emailVerified, isGuest bool) (*model.User, error) {
 	var user *model.User
 	if username != "" {
 		var err error
 		user, err = a.store.GetUserByUsername(username)
 		if err != nil && !model.IsErrNotFound(err) {
-			return err
+			return nil, err
 		}
 		if user != nil {
-			return errors.New("The username already exists")
+			return nil, errors.New("The username already exists")
 		}
 	}
 
@@ -161,49 +256,65 @@ func (a *App) RegisterUser(username, email, password string) error {
 		var err error
 		user, err = a.store.GetUserByEmail(email)
 		if err != nil && !model.IsErrNotFound(err) {
-			return err
+			return nil, err
 		}
 		if user != nil {
-			return errors.New("The email already exists")
+			return nil, errors.New("The email already exists")
 		}
 	}
 
-	// TODO: Move this into the config
-	passwordSettings := auth.PasswordSettings{
-		MinimumLength: 6,
+	err := auth.IsPasswordValid(password, a.passwordSettings())
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid password")
 	}
 
-	err := auth.IsPasswordValid(password, passwordSettings)
-	if err != nil {
-		return errors.Wrap(err, "Invalid password")
-	}
-
-	_, err = a.store.CreateUser(&model.User{
-		ID:          utils.NewID(utils.IDTypeUser),
-		Username:    username,
-		Email:       email,
-		Password:    auth.HashPassword(password),
-		MfaSecret:   "",
-		AuthService: a.config.AuthMode,
-		AuthData:    "",
-	})
+	// the users registered while the verification isn't required are
+	// trusted, so requiring it later doesn't lock them out.
+	user = &model.User{
+		ID:            utils.NewID(utils.IDTypeUser),
+		Username:      username,
+		Email:         email,
+		EmailVerified: emailVerified || !a.config.RequireEmailVerification,
+		Password:      auth.HashPassword(password),
+		MfaSecret:     "",
+		AuthService:   a.config.AuthMode,
+		AuthData:      "",
+		IsGuest:       isGuest,
+	}
+	_, err = a.store.CreateUser(user)
 	if err != nil {
-		return errors.Wrap(err, "Unable to create the new user")
+		return nil, errors.Wrap(err, "Unable to create the new user")
 	}
 
-	return nil
+	if err := a.addPasswordHistory(user.ID, user.Password); err != nil {
+		return nil, err
+	}
+
+	if !user.EmailVerified {
+		// the user can ask for a new link if this one is lost
+		if err := a.sendEmailVerification(user); err != nil {
+			a.logger.Error("Unable to send the email verification", mlog.String("userID", user.ID), mlog.Err(err))
+		}
+	}
+
+	return user, nil
 }
 
+// UpdateUserPassword sets the password of a user, following the password
+// policy.
 func (a *App) UpdateUserPassword(username, password string) error {
-	err := a.store.UpdateUserPassword(username, auth.HashPassword(password))
+	user, err := a.store.GetUserByUsername(username)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return a.setUserPassword(user, password)
 }
 
-func (a *App) ChangePassword(userID, oldPassword, newPassword string) error {
+// ChangePassword changes the password of a user, and revokes all their
+// sessions but the current one, which can be used again if its password
+// had expired.
+func (a *App) ChangePassword(userID, oldPassword, newPassword string, currentSession *model.Session) error {
 	var user *model.User
 	if userID != "" {
 		var err error
@@ -217,15 +328,28 @@ func (a *App) ChangePassword(userID, oldPassword, newPassword string) error {
 		return errors.New("invalid username or password")
 	}
 
+	if !isNativeUser(user) {
+		return model.NewErrBadRequest("the password of the user is managed by " + user.AuthService)
+	}
+
 	if !auth.ComparePassword(user.Password, oldPassword) {
 		a.logger.Debug("Invalid password for user", mlog.String("userID", user.ID))
 		return errors.New("invalid username or password")
 	}
 
-	err := a.store.UpdateUserPasswordByID(userID, auth.HashPassword(newPassword))
-	if err != nil {
-		return errors.Wrap(err, "unable to update password")
+	if err := a.setUserPassword(user, newPassword); err != nil {
+		return err
 	}
 
-	return nil
+	if currentSession == nil {
+		return a.revokeUserSessions(userID, "")
+	}
+
+	if currentSession.IsPasswordExpired() {
+		delete(currentSession.Props, model.SessionPropPasswordExpired)
+		if err := a.store.UpdateSession(currentSession); err != nil {
+			return errors.Wrap(err, "unable to update the session")
+		}
+	}
+	return a.revokeUserSessions(userID, currentSession.ID)
 }
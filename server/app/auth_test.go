@@ -46,7 +46,7 @@ func TestLogin(t *testing.T) {
 
 	for _, test := range testcases {
 		t.Run(test.title, func(t *testing.T) {
-			token, err := th.App.Login(test.userName, test.email, test.password, test.mfa)
+			token, err := th.App.Login(test.userName, test.email, test.password, test.mfa, "", "")
 			if test.isError {
 				require.Error(t, err)
 			} else {
@@ -135,12 +135,14 @@ func TestUpdateUserPassword(t *testing.T) {
 	}{
 		{"fail, missing login information", "", "", true},
 		{"fail, invalid username", "badUsername", "", true},
+		{"fail, password not following the policy", "testUsername", "short", true},
 		{"success, username", "testUsername", "testPassword", false},
 	}
 
-	th.Store.EXPECT().UpdateUserPassword("", gomock.Any()).Return(errors.New("user not found"))
-	th.Store.EXPECT().UpdateUserPassword("badUsername", gomock.Any()).Return(errors.New("user not found"))
-	th.Store.EXPECT().UpdateUserPassword("testUsername", gomock.Any()).Return(nil)
+	th.Store.EXPECT().GetUserByUsername("").Return(nil, errors.New("user not found"))
+	th.Store.EXPECT().GetUserByUsername("badUsername").Return(nil, errors.New("user not found"))
+	th.Store.EXPECT().GetUserByUsername("testUsername").Return(mockUser, nil).Times(2)
+	th.Store.EXPECT().UpdateUserPasswordByID(mockUser.ID, gomock.Any()).Return(nil)
 
 	for _, test := range testcases {
 		t.Run(test.title, func(t *testing.T) {
@@ -174,10 +176,12 @@ func TestChangePassword(t *testing.T) {
 	th.Store.EXPECT().GetUserByID("badID").Return(nil, errors.New("userID not found"))
 	th.Store.EXPECT().GetUserByID(mockUser.ID).Return(mockUser, nil).Times(2)
 	th.Store.EXPECT().UpdateUserPasswordByID(mockUser.ID, gomock.Any()).Return(nil)
+	th.Store.EXPECT().GetUserSessions(mockUser.ID, gomock.Any()).Return([]*model.Session{{ID: "current-session-id"}, {ID: "session-id"}}, nil)
+	th.Store.EXPECT().DeleteUserSessions(mockUser.ID, "current-session-id").Return(nil)
 
 	for _, test := range testcases {
 		t.Run(test.title, func(t *testing.T) {
-			err := th.App.ChangePassword(test.userName, test.oldPassword, test.password)
+			err := th.App.ChangePassword(test.userName, test.oldPassword, test.password, &model.Session{ID: "current-session-id"})
 			if test.isError {
 				require.Error(t, err)
 			} else {
@@ -72,6 +72,10 @@ func (a *App) PatchBlockAndNotify(blockID string, blockPatch *model.BlockPatch,
 		return nil, err
 	}
 
+	if err = a.checkBlockPatchPropertyAccess(board, oldBlock, blockPatch, modifiedByID); err != nil {
+		return nil, err
+	}
+
 	err = a.store.PatchBlock(blockID, blockPatch, modifiedByID)
 	if err != nil {
 		return nil, err
@@ -108,6 +112,29 @@ func (a *App) PatchBlocksAndNotify(teamID string, blockPatches *model.BlockPatch
 		return err
 	}
 
+	oldBlocksMap := map[string]*model.Block{}
+	for _, block := range oldBlocks {
+		oldBlocksMap[block.ID] = block
+	}
+
+	boards := map[string]*model.Board{}
+	for i, blockID := range blockPatches.BlockIDs {
+		oldBlock, ok := oldBlocksMap[blockID]
+		if !ok || !blockPatchChangesProperties(oldBlock, &blockPatches.BlockPatches[i]) {
+			continue
+		}
+		board, ok := boards[oldBlock.BoardID]
+		if !ok {
+			if board, err = a.store.GetBoard(oldBlock.BoardID); err != nil {
+				return err
+			}
+			boards[oldBlock.BoardID] = board
+		}
+		if err = a.checkBlockPatchPropertyAccess(board, oldBlock, &blockPatches.BlockPatches[i], modifiedByID); err != nil {
+			return err
+		}
+	}
+
 	if err := a.store.PatchBlocks(blockPatches, modifiedByID); err != nil {
 		return err
 	}
@@ -140,6 +167,10 @@ func (a *App) InsertBlockAndNotify(block *model.Block, modifiedByID string, disa
 		return bErr
 	}
 
+	if err := a.checkInsertedBlocksPropertyAccess(board, []*model.Block{block}, modifiedByID); err != nil {
+		return err
+	}
+
 	err := a.store.InsertBlock(block, modifiedByID)
 	if err == nil {
 		a.blockChangeNotifier.Enqueue(func() error {
@@ -178,6 +209,10 @@ func (a *App) InsertBlocksAndNotify(blocks []*model.Block, modifiedByID string,
 		return nil, err
 	}
 
+	if err = a.checkInsertedBlocksPropertyAccess(board, blocks, modifiedByID); err != nil {
+		return nil, err
+	}
+
 	needsNotify := make([]*model.Block, 0, len(blocks))
 	for i := range blocks {
 		err := a.store.InsertBlock(blocks[i], modifiedByID)
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+func (a *App) GetCustomBoardRoles(boardID string) ([]*model.CustomBoardRole, error) {
+	return a.store.GetCustomBoardRoles(boardID)
+}
+
+// GetCustomBoardRole returns a custom role, or a not found error if it
+// doesn't belong to the board.
+func (a *App) GetCustomBoardRole(boardID, roleID string) (*model.CustomBoardRole, error) {
+	role, err := a.store.GetCustomBoardRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role.BoardID != boardID {
+		return nil, model.NewErrNotFound("board role ID=" + roleID)
+	}
+	return role, nil
+}
+
+func (a *App) CreateCustomBoardRole(role *model.CustomBoardRole, userID string) (*model.CustomBoardRole, error) {
+	role.Name = strings.TrimSpace(role.Name)
+	if err := role.IsValid(); err != nil {
+		return nil, err
+	}
+
+	role.ID = utils.NewID(utils.IDTypeNone)
+	role.CreatedBy = userID
+	role.CreateAt = 0
+	return a.store.SaveCustomBoardRole(role)
+}
+
+// UpdateCustomBoardRole replaces the name and the permissions of a custom
+// role. The changes apply to all the members assigned to it.
+func (a *App) UpdateCustomBoardRole(boardID, roleID string, update *model.CustomBoardRole) (*model.CustomBoardRole, error) {
+	role, err := a.GetCustomBoardRole(boardID, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Name = strings.TrimSpace(update.Name)
+	role.Permissions = update.Permissions
+	if err := role.IsValid(); err != nil {
+		return nil, err
+	}
+
+	return a.store.SaveCustomBoardRole(role)
+}
+
+// DeleteCustomBoardRole deletes a custom role. The members assigned to it
+// keep only their scheme roles.
+func (a *App) DeleteCustomBoardRole(boardID, roleID string) error {
+	if _, err := a.GetCustomBoardRole(boardID, roleID); err != nil {
+		return err
+	}
+	return a.store.DeleteCustomBoardRole(roleID)
+}
+
+func (a *App) checkMemberCustomRole(member *model.BoardMember) error {
+	if member.CustomRoleID == "" {
+		return nil
+	}
+
+	_, err := a.GetCustomBoardRole(member.BoardID, member.CustomRoleID)
+	if model.IsErrNotFound(err) {
+		return model.NewErrBadRequest("invalid custom role: " + member.CustomRoleID)
+	}
+	return err
+}
@@ -528,6 +528,10 @@ func (a *App) AddMemberToBoard(member *model.BoardMember) (*model.BoardMember, e
 		return existingMembership, nil
 	}
 
+	if err = a.checkMemberCustomRole(member); err != nil {
+		return nil, err
+	}
+
 	newMember, err := a.store.SaveMember(member)
 	if err != nil {
 		return nil, err
@@ -572,6 +576,10 @@ func (a *App) UpdateBoardMember(member *model.BoardMember) (*model.BoardMember,
 		return nil, err
 	}
 
+	if err = a.checkMemberCustomRole(member); err != nil {
+		return nil, err
+	}
+
 	// if we're updating an admin, we need to check that there is at
 	// least still another admin on the board
 	if oldMember.SchemeAdmin && !member.SchemeAdmin {
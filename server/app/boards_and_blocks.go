@@ -66,6 +66,24 @@ func (a *App) PatchBoardsAndBlocks(pbab *model.PatchBoardsAndBlocks, userID stri
 		oldBlocksMap[block.ID] = block
 	}
 
+	boards := map[string]*model.Board{}
+	for i, blockID := range pbab.BlockIDs {
+		oldBlock, ok := oldBlocksMap[blockID]
+		if !ok || !blockPatchChangesProperties(oldBlock, pbab.BlockPatches[i]) {
+			continue
+		}
+		board, ok := boards[oldBlock.BoardID]
+		if !ok {
+			if board, err = a.store.GetBoard(oldBlock.BoardID); err != nil {
+				return nil, err
+			}
+			boards[oldBlock.BoardID] = board
+		}
+		if err = a.checkBlockPatchPropertyAccess(board, oldBlock, pbab.BlockPatches[i], userID); err != nil {
+			return nil, err
+		}
+	}
+
 	bab, err := a.store.PatchBoardsAndBlocks(pbab, userID)
 	if err != nil {
 		return nil, err
@@ -630,7 +630,7 @@ func TestDuplicateBoard(t *testing.T) {
 		th.Store.EXPECT().AddUpdateCategoryBoard("user_id_1", "category_id_1", utils.Anything).Return(nil)
 
 		// for WS change broadcast
-		th.Store.EXPECT().GetMembersForBoard(utils.Anything).Return([]*model.BoardMember{}, nil).Times(2)
+		th.Store.EXPECT().GetMembersForBoard(utils.Anything).Return([]*model.BoardMember{}, nil).Times(1)
 
 		bab, members, err := th.App.DuplicateBoard("board_id_1", "user_id_1", "team_id_1", false)
 		assert.NoError(t, err)
@@ -665,7 +665,7 @@ func TestDuplicateBoard(t *testing.T) {
 		th.Store.EXPECT().GetBoard("board_id_1").Return(&model.Board{}, nil)
 
 		// for WS change broadcast
-		th.Store.EXPECT().GetMembersForBoard(utils.Anything).Return([]*model.BoardMember{}, nil).Times(2)
+		th.Store.EXPECT().GetMembersForBoard(utils.Anything).Return([]*model.BoardMember{}, nil).Times(1)
 
 		bab, members, err := th.App.DuplicateBoard("board_id_1", "user_id_1", "team_id_1", true)
 		assert.NoError(t, err)
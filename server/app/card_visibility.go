@@ -0,0 +1,108 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// cardVisibility checks whether a user can see the blocks of a board,
+// caching the cards and the board membership it has to look up.
+type cardVisibility struct {
+	app     *App
+	boardID string
+	userID  string
+
+	member       *model.BoardMember
+	memberLoaded bool
+	cards        map[string]*model.Block
+}
+
+func (a *App) newCardVisibility(boardID, userID string) *cardVisibility {
+	return &cardVisibility{
+		app:     a,
+		boardID: boardID,
+		userID:  userID,
+		cards:   map[string]*model.Block{},
+	}
+}
+
+// canView returns true unless the block is a private card the user can't
+// see, or belongs to one.
+func (cv *cardVisibility) canView(block *model.Block) (bool, error) {
+	card := block
+	if parentID := model.CardParentID(block); parentID != "" {
+		parent, ok := cv.cards[parentID]
+		if !ok {
+			var err error
+			parent, err = cv.app.store.GetBlock(parentID)
+			if err != nil && !model.IsErrNotFound(err) {
+				return false, err
+			}
+			cv.cards[parentID] = parent
+		}
+		if parent == nil {
+			return true, nil
+		}
+		card = parent
+	}
+
+	if !model.IsPrivateCard(card) {
+		return true, nil
+	}
+
+	if !cv.memberLoaded && cv.userID != "" && cv.userID != model.SystemUserID {
+		member, err := cv.app.store.GetMemberForBoard(cv.boardID, cv.userID)
+		if err != nil && !model.IsErrNotFound(err) {
+			return false, err
+		}
+		cv.member = member
+		cv.memberLoaded = true
+	}
+	return model.CanViewCard(card, cv.userID, cv.member), nil
+}
+
+// FilterVisibleBlocks removes from the blocks of a board the private cards
+// the user can't see, along with their contents.
+func (a *App) FilterVisibleBlocks(boardID, userID string, blocks []*model.Block) ([]*model.Block, error) {
+	cv := a.newCardVisibility(boardID, userID)
+	for _, block := range blocks {
+		if block.Type == model.TypeCard {
+			cv.cards[block.ID] = block
+		}
+	}
+
+	filtered := make([]*model.Block, 0, len(blocks))
+	for _, block := range blocks {
+		ok, err := cv.canView(block)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered = append(filtered, block)
+		}
+	}
+	return filtered, nil
+}
+
+// FilterVisibleCards removes from the cards of a board the private ones
+// the user can't see.
+func (a *App) FilterVisibleCards(boardID, userID string, cards []*model.Card) ([]*model.Card, error) {
+	cv := a.newCardVisibility(boardID, userID)
+
+	filtered := make([]*model.Card, 0, len(cards))
+	for _, card := range cards {
+		ok, err := cv.canView(model.Card2Block(card))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered = append(filtered, card)
+		}
+	}
+	return filtered, nil
+}
+
+// CanViewBlock returns false if the block is a private card the user can't
+// see, or belongs to one.
+func (a *App) CanViewBlock(block *model.Block, userID string) (bool, error) {
+	return a.newCardVisibility(block.BoardID, userID).canView(block)
+}
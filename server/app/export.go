@@ -87,6 +87,19 @@ func (a *App) writeArchiveBoard(zw *zip.Writer, board model.Board, opt model.Exp
 		return err
 	}
 
+	if opt.UserID != "" {
+		blocks, err = a.FilterVisibleBlocks(board.ID, opt.UserID, blocks)
+		if err != nil {
+			return err
+		}
+
+		restrictions, err2 := a.GetPropertyRestrictions(&board, opt.UserID)
+		if err2 != nil {
+			return err2
+		}
+		blocks = restrictions.FilterBlocks(blocks)
+	}
+
 	for _, block := range blocks {
 		if err = a.writeArchiveBlockLine(w, block); err != nil {
 			return err
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/pkg/errors"
+)
+
+// getExternalUser returns the user linked to an account of an external
+// identity provider. The deactivated users can't sign in, and aren't
+// provisioned again either.
+func (a *App) getExternalUser(authService, authData string) (*model.User, error) {
+	user, err := a.store.GetUserByAuthData(authService, authData)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeleteAt != 0 {
+		return nil, model.NewErrForbidden("the user is deactivated")
+	}
+	return user, nil
+}
+
+// checkExternalUser checks that the username and email an external
+// identity provider gives a user aren't used by other accounts,
+// deactivated or not. Accounts are never linked implicitly, as that would
+// allow the provider users to take over existing accounts.
+func (a *App) checkExternalUser(userID, username, email string) error {
+	users, _, err := a.store.QueryUsers(model.QueryUsersOptions{Username: username})
+	if err != nil {
+		return errors.Wrap(err, "unable to get the users")
+	}
+	for _, other := range users {
+		if other.ID != userID {
+			return model.NewErrForbidden(fmt.Sprintf("the username %s is already used by another account", username))
+		}
+	}
+
+	if email == "" {
+		return nil
+	}
+	users, _, err = a.store.QueryUsers(model.QueryUsersOptions{Email: email})
+	if err != nil {
+		return errors.Wrap(err, "unable to get the users")
+	}
+	for _, other := range users {
+		if other.ID != userID {
+			return model.NewErrForbidden(fmt.Sprintf("the email %s is already used by another account", email))
+		}
+	}
+
+	return nil
+}
@@ -37,7 +37,7 @@ func SetupTestHelper(t *testing.T) (*TestHelper, func()) {
 	auth := auth.New(&cfg, store, nil)
 	logger, _ := mlog.NewLogger()
 	sessionToken := "TESTTOKEN"
-	wsserver := ws.NewServer(auth, sessionToken, false, logger, store)
+	wsserver := ws.NewServer(auth, sessionToken, false, logger, store, nil)
 	webhook := webhook.NewClient(&cfg, logger)
 	metricsService := metrics.NewMetrics(metrics.InstanceInfo{})
 
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// GetJobs returns the background jobs, the most recent first.
+func (a *App) GetJobs(opts model.QueryJobsOptions) ([]*model.Job, error) {
+	if a.jobs == nil {
+		return nil, model.NewErrNotImplemented("the jobs are not available")
+	}
+	return a.jobs.GetJobs(opts)
+}
+
+// GetJob returns a background job.
+func (a *App) GetJob(id string) (*model.Job, error) {
+	if a.jobs == nil {
+		return nil, model.NewErrNotImplemented("the jobs are not available")
+	}
+	return a.jobs.GetJob(id)
+}
+
+// TriggerJob runs a job of the given type as soon as possible.
+func (a *App) TriggerJob(jobType, userID string) (*model.Job, error) {
+	if a.jobs == nil {
+		return nil, model.NewErrNotImplemented("the jobs are not available")
+	}
+	return a.jobs.Trigger(jobType, userID)
+}
+
+// CancelJob cancels a pending or running job.
+func (a *App) CancelJob(id string) (*model.Job, error) {
+	if a.jobs == nil {
+		return nil, model.NewErrNotImplemented("the jobs are not available")
+	}
+	return a.jobs.Cancel(id)
+}
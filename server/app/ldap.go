@@ -0,0 +1,305 @@
+package app
+
+import (
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/ldap"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/pkg/errors"
+)
+
+// ldapMemberRoles marks the board memberships managed by the LDAP group
+// synchronization. Memberships added by hand are never changed by it.
+const ldapMemberRoles = "ldap"
+
+var boardRoleRanks = map[model.BoardRole]int{
+	model.BoardRoleViewer:    1,
+	model.BoardRoleCommenter: 2,
+	model.BoardRoleEditor:    3,
+	model.BoardRoleAdmin:     4,
+}
+
+// LDAPSyncInterval returns the time between two LDAP synchronizations,
+// and false if LDAP authentication is not enabled.
+func (a *App) LDAPSyncInterval() (time.Duration, bool) {
+	if a.ldap == nil {
+		return 0, false
+	}
+	return a.ldap.SyncInterval(), true
+}
+
+// loginWithLDAP checks the credentials against the directory,
+// provisioning the user on their first login.
+func (a *App) loginWithLDAP(username, password, mfaToken string) (*model.User, error) {
+	entry, err := a.ldap.Authenticate(username, password)
+	if err != nil {
+		a.metrics.IncrementLoginFailCount(1)
+		if errors.Is(err, ldap.ErrInvalidCredentials) {
+			return nil, errors.New("invalid username or password")
+		}
+		return nil, errors.Wrap(err, "unable to authenticate with LDAP")
+	}
+
+	adminGroups, err := a.ldap.UserGroups(entry.DN, a.config.LDAP.AdminGroups)
+	if err != nil {
+		a.metrics.IncrementLoginFailCount(1)
+		return nil, errors.Wrap(err, "unable to get the LDAP groups of the user")
+	}
+
+	user, err := a.syncLDAPUser(entry, ldapRoles(len(adminGroups) > 0))
+	if err != nil {
+		a.metrics.IncrementLoginFailCount(1)
+		return nil, err
+	}
+
+	if err := a.checkMfa(user, mfaToken); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// syncLDAPUser returns the user linked to the directory entry, creating
+// it if needed and keeping its attributes and roles up to date. The
+// deactivated users are refused.
+func (a *App) syncLDAPUser(entry *ldap.User, roles string) (*model.User, error) {
+	user, err := a.getExternalUser(model.AuthModeLDAP, entry.ID)
+	if err == nil {
+		return a.updateLDAPUser(user, entry, roles)
+	}
+	if !model.IsErrNotFound(err) {
+		return nil, err
+	}
+
+	if err = a.checkExternalUser("", entry.Username, entry.Email); err != nil {
+		return nil, err
+	}
+
+	user, err = a.store.CreateUser(&model.User{
+		ID:          utils.NewID(utils.IDTypeUser),
+		Username:    entry.Username,
+		Email:       entry.Email,
+		Nickname:    entry.Nickname,
+		FirstName:   entry.FirstName,
+		LastName:    entry.LastName,
+		AuthService: model.AuthModeLDAP,
+		AuthData:    entry.ID,
+		Roles:       roles,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the LDAP user")
+	}
+
+	a.logger.Info("Provisioned LDAP user",
+		mlog.String("userID", user.ID),
+		mlog.String("username", user.Username),
+	)
+
+	return user, nil
+}
+
+func (a *App) updateLDAPUser(user *model.User, entry *ldap.User, roles string) (*model.User, error) {
+	if user.Username == entry.Username &&
+		user.Email == entry.Email &&
+		user.Nickname == entry.Nickname &&
+		user.FirstName == entry.FirstName &&
+		user.LastName == entry.LastName &&
+		user.Roles == roles {
+		return user, nil
+	}
+
+	if user.Username != entry.Username || (user.Email != entry.Email && entry.Email != "") {
+		if err := a.checkExternalUser(user.ID, entry.Username, entry.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Username = entry.Username
+	user.Email = entry.Email
+	user.Nickname = entry.Nickname
+	user.FirstName = entry.FirstName
+	user.LastName = entry.LastName
+	user.Roles = roles
+
+	user, err := a.store.UpdateUser(user)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to update the LDAP user")
+	}
+	return user, nil
+}
+
+func ldapRoles(isAdmin bool) string {
+	if isAdmin {
+		return model.SystemUserRoleID + " " + model.SystemAdminRoleID
+	}
+	return model.SystemUserRoleID
+}
+
+// SyncLDAP updates the users linked to the directory with their current
+// attributes and admin rights, and the memberships of the boards mapped
+// to LDAP groups. Users that never logged in are not created. The group
+// mappings are read from the current configuration on every run.
+func (a *App) SyncLDAP() error {
+	if a.ldap == nil {
+		return model.NewErrNotImplemented("LDAP authentication is not enabled")
+	}
+	cfg := a.config.LDAP
+
+	entries, err := a.ldap.Users()
+	if err != nil {
+		return errors.Wrap(err, "unable to get the LDAP users")
+	}
+
+	groups := append([]string{}, cfg.AdminGroups...)
+	for _, mapping := range cfg.GroupBoards {
+		groups = append(groups, mapping.Group)
+	}
+	groupMembers, err := a.ldap.GroupMembers(groups)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the LDAP group members")
+	}
+
+	// every mapped board is synchronized, even without members, so the
+	// users that left the groups are removed.
+	boardRoles := map[string]map[string]model.BoardRole{}
+	for _, mapping := range cfg.GroupBoards {
+		boardRoles[mapping.BoardID] = map[string]model.BoardRole{}
+	}
+
+	updatedUsers := 0
+	for _, entry := range entries {
+		user, err := a.getExternalUser(model.AuthModeLDAP, entry.ID)
+		if model.IsErrNotFound(err) || model.IsErrForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		isAdmin := false
+		for _, group := range cfg.AdminGroups {
+			if ldap.IsMember(groupMembers[group], entry.DN) {
+				isAdmin = true
+				break
+			}
+		}
+
+		updateAt := user.UpdateAt
+		user, err = a.updateLDAPUser(user, entry, ldapRoles(isAdmin))
+		if err != nil {
+			a.logger.Warn("Unable to synchronize the LDAP user", mlog.String("username", entry.Username), mlog.Err(err))
+			continue
+		}
+		if user.UpdateAt != updateAt {
+			updatedUsers++
+		}
+
+		for _, mapping := range cfg.GroupBoards {
+			role := model.BoardRole(mapping.Role)
+			if boardRoleRanks[role] == 0 {
+				a.logger.Warn("Invalid role in the LDAP group mapping",
+					mlog.String("group", mapping.Group),
+					mlog.String("boardID", mapping.BoardID),
+					mlog.String("role", mapping.Role),
+				)
+				continue
+			}
+			if ldap.IsMember(groupMembers[mapping.Group], entry.DN) &&
+				boardRoleRanks[role] > boardRoleRanks[boardRoles[mapping.BoardID][user.ID]] {
+				boardRoles[mapping.BoardID][user.ID] = role
+			}
+		}
+	}
+
+	for boardID, roles := range boardRoles {
+		if err := a.syncLDAPBoardMembers(boardID, roles); err != nil {
+			a.logger.Warn("Unable to synchronize the LDAP board members", mlog.String("boardID", boardID), mlog.Err(err))
+		}
+	}
+
+	a.logger.Info("LDAP synchronization done",
+		mlog.Int("ldapUsers", len(entries)),
+		mlog.Int("updatedUsers", updatedUsers),
+		mlog.Int("boards", len(boardRoles)),
+	)
+
+	return nil
+}
+
+// syncLDAPBoardMembers sets the memberships of a board managed by the
+// LDAP synchronization to the given roles, indexed by user ID.
+func (a *App) syncLDAPBoardMembers(boardID string, roles map[string]model.BoardRole) error {
+	if _, err := a.store.GetBoard(boardID); err != nil {
+		return err
+	}
+
+	members, err := a.store.GetMembersForBoard(boardID)
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]*model.BoardMember{}
+	for _, member := range members {
+		existing[member.UserID] = member
+	}
+
+	for userID, role := range roles {
+		member := ldapBoardMember(boardID, userID, role)
+		current, ok := existing[userID]
+
+		switch {
+		case !ok || current.Synthetic:
+			_, err = a.AddMemberToBoard(member)
+		case current.Roles == ldapMemberRoles && !sameBoardRole(current, member):
+			_, err = a.UpdateBoardMember(member)
+		default:
+			continue
+		}
+		if err != nil {
+			a.logger.Warn("Unable to save the LDAP board member",
+				mlog.String("boardID", boardID),
+				mlog.String("userID", userID),
+				mlog.Err(err),
+			)
+		}
+	}
+
+	for _, member := range members {
+		if member.Roles != ldapMemberRoles || roles[member.UserID] != model.BoardRoleNone {
+			continue
+		}
+		if err := a.DeleteBoardMember(boardID, member.UserID); err != nil {
+			a.logger.Warn("Unable to remove the LDAP board member",
+				mlog.String("boardID", boardID),
+				mlog.String("userID", member.UserID),
+				mlog.Err(err),
+			)
+		}
+	}
+
+	return nil
+}
+
+func ldapBoardMember(boardID, userID string, role model.BoardRole) *model.BoardMember {
+	rank := boardRoleRanks[role]
+	return &model.BoardMember{
+		BoardID:         boardID,
+		UserID:          userID,
+		Roles:           ldapMemberRoles,
+		SchemeViewer:    rank >= boardRoleRanks[model.BoardRoleViewer],
+		SchemeCommenter: rank >= boardRoleRanks[model.BoardRoleCommenter],
+		SchemeEditor:    rank >= boardRoleRanks[model.BoardRoleEditor],
+		SchemeAdmin:     rank >= boardRoleRanks[model.BoardRoleAdmin],
+	}
+}
+
+func sameBoardRole(a, b *model.BoardMember) bool {
+	return a.SchemeAdmin == b.SchemeAdmin &&
+		a.SchemeEditor == b.SchemeEditor &&
+		a.SchemeCommenter == b.SchemeCommenter &&
+		a.SchemeViewer == b.SchemeViewer
+}
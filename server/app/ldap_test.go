@@ -0,0 +1,216 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/ldap"
+	"github.com/mattermost/focalboard/server/services/ldap/ldaptest"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLDAP(t *testing.T, th *TestHelper) *ldaptest.Server {
+	directory, err := ldaptest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(directory.Close)
+
+	th.App.ldap = ldap.New(directory.Config())
+	th.App.config.LDAP.AdminGroups = []string{"admins"}
+	return directory
+}
+
+func TestLoginWithLDAP(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	directory := setupLDAP(t, th)
+	johnDN := directory.AddUser("john", "john-password", "john@example.com", "John", "Doe")
+	directory.AddGroup("admins", johnDN)
+
+	t.Run("provisions a new user", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeLDAP, gomock.Any()).Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().GetUserByUsername("john").Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{}, 0, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Email: "john@example.com"}).Return([]*model.User{}, 0, nil)
+
+		var created *model.User
+		th.Store.EXPECT().CreateUser(gomock.Any()).DoAndReturn(func(user *model.User) (*model.User, error) {
+			created = user
+			return user, nil
+		})
+		th.Store.EXPECT().CreateSession(gomock.Any()).DoAndReturn(func(session *model.Session) error {
+			require.Equal(t, model.AuthModeLDAP, session.AuthService)
+			return nil
+		})
+
+		token, err := th.App.Login("john", "", "john-password", "", "", "")
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+
+		require.Equal(t, "john", created.Username)
+		require.Equal(t, "John", created.FirstName)
+		require.Equal(t, "Doe", created.LastName)
+		require.Equal(t, model.AuthModeLDAP, created.AuthService)
+		require.NotEmpty(t, created.AuthData)
+		require.True(t, created.IsSystemAdmin())
+	})
+
+	t.Run("updates a linked user", func(t *testing.T) {
+		existing := &model.User{
+			ID:          "user-id",
+			Username:    "john",
+			Email:       "old@example.com",
+			FirstName:   "John",
+			LastName:    "Doe",
+			AuthService: model.AuthModeLDAP,
+			Roles:       model.SystemUserRoleID,
+		}
+		th.Store.EXPECT().GetUserByUsername("john").Return(existing, nil)
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeLDAP, gomock.Any()).Return(existing, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{existing}, 1, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Email: "john@example.com"}).Return([]*model.User{}, 0, nil)
+		th.Store.EXPECT().UpdateUser(existing).Return(existing, nil)
+		th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+
+		_, err := th.App.Login("john", "", "john-password", "", "", "")
+		require.NoError(t, err)
+		require.Equal(t, "john@example.com", existing.Email)
+		require.True(t, existing.IsSystemAdmin())
+	})
+
+	t.Run("does not take the email of another account on update", func(t *testing.T) {
+		existing := &model.User{
+			ID:          "user-id",
+			Username:    "john",
+			Email:       "old@example.com",
+			AuthService: model.AuthModeLDAP,
+			Roles:       model.SystemUserRoleID,
+		}
+		th.Store.EXPECT().GetUserByUsername("john").Return(existing, nil)
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeLDAP, gomock.Any()).Return(existing, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{existing}, 1, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Email: "john@example.com"}).Return([]*model.User{{ID: "other-id", DeleteAt: 1}}, 1, nil)
+
+		token, err := th.App.Login("john", "", "john-password", "", "", "")
+		require.True(t, model.IsErrForbidden(err))
+		require.Empty(t, token)
+		require.Equal(t, "old@example.com", existing.Email)
+	})
+
+	t.Run("does not link existing accounts", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("john").Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeLDAP, gomock.Any()).Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{}, 0, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Email: "john@example.com"}).Return([]*model.User{{ID: "other-id", Username: "johnny"}}, 1, nil)
+
+		token, err := th.App.Login("john", "", "john-password", "", "", "")
+		require.True(t, model.IsErrForbidden(err))
+		require.Empty(t, token)
+	})
+
+	t.Run("deactivated users are refused", func(t *testing.T) {
+		deactivated := &model.User{ID: "user-id", Username: "john", AuthService: model.AuthModeLDAP, DeleteAt: 1}
+		th.Store.EXPECT().GetUserByUsername("john").Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeLDAP, gomock.Any()).Return(deactivated, nil)
+
+		token, err := th.App.Login("john", "", "john-password", "", "", "")
+		require.True(t, model.IsErrForbidden(err))
+		require.Empty(t, token)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("john").Return(nil, model.NewErrNotFound("user"))
+
+		token, err := th.App.Login("john", "", "wrong-password", "", "", "")
+		require.Error(t, err)
+		require.Empty(t, token)
+	})
+
+	t.Run("native users log in with their password", func(t *testing.T) {
+		admin := &model.User{
+			ID:       "admin-id",
+			Username: "admin",
+			Email:    "admin@example.com",
+			Password: auth.HashPassword("admin-password"),
+		}
+		th.Store.EXPECT().GetUserByUsername("admin").Return(admin, nil).Times(2)
+		th.Store.EXPECT().CreateSession(gomock.Any()).DoAndReturn(func(session *model.Session) error {
+			require.Equal(t, model.AuthModeNative, session.AuthService)
+			return nil
+		})
+
+		token, err := th.App.Login("admin", "", "admin-password", "", "", "")
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+
+		th.Store.EXPECT().GetUserByEmail("admin@example.com").Return(admin, nil).Times(2)
+		th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+
+		token, err = th.App.Login("", "admin@example.com", "admin-password", "", "", "")
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+	})
+}
+
+func TestSyncLDAPNotEnabled(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	err := th.App.SyncLDAP()
+	require.True(t, model.IsErrNotImplemented(err))
+}
+
+func TestSyncLDAPUsers(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	directory := setupLDAP(t, th)
+	johnDN := directory.AddUser("john", "john-password", "john@example.com", "Johnny", "Doe")
+	directory.SetAttribute(johnDN, "entryUUID", "john-uuid")
+	directory.AddUser("jane", "jane-password", "jane@example.com", "Jane", "Doe")
+	jimDN := directory.AddUser("jim", "jim-password", "jim@example.com", "Jim", "Doe")
+	directory.SetAttribute(jimDN, "entryUUID", "jim-uuid")
+
+	john := &model.User{
+		ID:          "john-id",
+		Username:    "john",
+		Email:       "john@example.com",
+		FirstName:   "John",
+		LastName:    "Doe",
+		AuthService: model.AuthModeLDAP,
+		AuthData:    "john-uuid",
+		Roles:       model.SystemUserRoleID,
+	}
+
+	th.Store.EXPECT().GetUserByAuthData(model.AuthModeLDAP, gomock.Any()).DoAndReturn(func(_, authData string) (*model.User, error) {
+		switch authData {
+		case john.AuthData:
+			return john, nil
+		case "jim-uuid":
+			// jim was deactivated, and is left as is
+			return &model.User{ID: "jim-id", Username: "jimmy", AuthService: model.AuthModeLDAP, AuthData: authData, DeleteAt: 1}, nil
+		}
+		// jane never logged in
+		return nil, model.NewErrNotFound("user")
+	}).Times(3)
+	th.Store.EXPECT().UpdateUser(john).Return(john, nil)
+
+	require.NoError(t, th.App.SyncLDAP())
+	require.Equal(t, "Johnny", john.FirstName)
+}
+
+func TestLDAPBoardMember(t *testing.T) {
+	editor := ldapBoardMember("board-id", "user-id", model.BoardRoleEditor)
+	require.Equal(t, ldapMemberRoles, editor.Roles)
+	require.True(t, editor.SchemeViewer)
+	require.True(t, editor.SchemeCommenter)
+	require.True(t, editor.SchemeEditor)
+	require.False(t, editor.SchemeAdmin)
+
+	viewer := ldapBoardMember("board-id", "user-id", model.BoardRoleViewer)
+	require.True(t, viewer.SchemeViewer)
+	require.False(t, viewer.SchemeCommenter)
+	require.False(t, sameBoardRole(editor, viewer))
+}
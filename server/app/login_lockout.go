@@ -0,0 +1,140 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/config"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const defaultLoginLockoutMinutes = 15
+
+// LoginLockedError is returned when a login is refused because the
+// account or the IP address has too many failed logins.
+type LoginLockedError struct {
+	// Account is the locked username or email, empty when the IP
+	// address is locked
+	Account   string
+	IPAddress string
+	Until     time.Time
+	// Locked is true when this login caused the lockout
+	Locked bool
+}
+
+func (e *LoginLockedError) Error() string {
+	if e.Account != "" {
+		return "account locked after too many failed logins"
+	}
+	return "IP address locked after too many failed logins"
+}
+
+// newLoginLimiters creates the limiters of the failed logins by account
+// and by IP address.
+func newLoginLimiters(cfg config.LoginLockoutConfig) (*auth.LoginLimiter, *auth.LoginLimiter) {
+	lockout := time.Duration(cfg.LockoutMinutes) * time.Minute
+	if lockout <= 0 {
+		lockout = defaultLoginLockoutMinutes * time.Minute
+	}
+	delay := time.Duration(cfg.DelayMilliseconds) * time.Millisecond
+	maxDelay := time.Duration(cfg.MaxDelayMilliseconds) * time.Millisecond
+
+	accountLimiter := auth.NewLoginLimiter(auth.LoginLimiterSettings{
+		MaxFailures: cfg.MaxAccountFailures,
+		Lockout:     lockout,
+		Delay:       delay,
+		MaxDelay:    maxDelay,
+	})
+	ipLimiter := auth.NewLoginLimiter(auth.LoginLimiterSettings{
+		MaxFailures: cfg.MaxIPFailures,
+		Lockout:     lockout,
+		Delay:       delay,
+		MaxDelay:    maxDelay,
+	})
+	return accountLimiter, ipLimiter
+}
+
+// loginAccountKey returns the key under which the failed logins of an
+// account are counted. Unknown accounts are counted too, so the lockout
+// doesn't tell which accounts exist.
+func loginAccountKey(username, email string) string {
+	if username != "" {
+		return strings.ToLower(strings.TrimSpace(username))
+	}
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (a *App) checkLoginLockout(accountKey, ipAddress string) error {
+	if ipAddress != "" {
+		if until, locked := a.ipLimiter.LockedUntil(ipAddress); locked {
+			return &LoginLockedError{IPAddress: ipAddress, Until: until}
+		}
+	}
+	if accountKey != "" {
+		if until, locked := a.accountLimiter.LockedUntil(accountKey); locked {
+			return &LoginLockedError{Account: accountKey, IPAddress: ipAddress, Until: until}
+		}
+	}
+	return nil
+}
+
+// loginFailed records a failed login, delays it and returns the error to
+// report. Logins refused after checking the credentials, such as the
+// ones missing an MFA token, are not failures.
+func (a *App) loginFailed(accountKey, ipAddress string, err error) error {
+	if errors.Is(err, ErrMfaRequired) || errors.Is(err, ErrEmailNotVerified) || model.IsErrForbidden(err) {
+		return err
+	}
+
+	var delay time.Duration
+	var lockedErr *LoginLockedError
+
+	if ipAddress != "" {
+		failures, lockedUntil := a.ipLimiter.Fail(ipAddress)
+		delay = a.ipLimiter.Delay(failures)
+		if !lockedUntil.IsZero() {
+			lockedErr = &LoginLockedError{IPAddress: ipAddress, Until: lockedUntil, Locked: true}
+		}
+	}
+
+	if accountKey != "" {
+		failures, lockedUntil := a.accountLimiter.Fail(accountKey)
+		if accountDelay := a.accountLimiter.Delay(failures); accountDelay > delay {
+			delay = accountDelay
+		}
+		if !lockedUntil.IsZero() {
+			lockedErr = &LoginLockedError{Account: accountKey, IPAddress: ipAddress, Until: lockedUntil, Locked: true}
+		}
+	}
+
+	if lockedErr != nil {
+		a.logger.Warn("Login locked after too many failures",
+			mlog.String("account", lockedErr.Account),
+			mlog.String("ipAddress", lockedErr.IPAddress),
+			mlog.Time("until", lockedErr.Until),
+		)
+		return lockedErr
+	}
+
+	time.Sleep(delay)
+	return err
+}
+
+// UnlockUser forgets the failed logins of a user, by username and by
+// email, and unlocks their account.
+func (a *App) UnlockUser(username string) error {
+	user, err := a.store.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	a.accountLimiter.Reset(loginAccountKey(user.Username, ""))
+	if user.Email != "" {
+		a.accountLimiter.Reset(loginAccountKey("", user.Email))
+	}
+	return nil
+}
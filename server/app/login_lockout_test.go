@@ -0,0 +1,123 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginLockout(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.accountLimiter, th.App.ipLimiter = newLoginLimiters(config.LoginLockoutConfig{
+		MaxAccountFailures: 3,
+		MaxIPFailures:      5,
+		LockoutMinutes:     15,
+	})
+
+	user := &model.User{
+		ID:       "user-id",
+		Username: "john",
+		Email:    "john@example.com",
+		Password: auth.HashPassword("john-password"),
+	}
+	th.Store.EXPECT().GetUserByUsername("john").Return(user, nil).AnyTimes()
+	th.Store.EXPECT().GetUserByUsername(" John").Return(nil, model.NewErrNotFound("user")).AnyTimes()
+	th.Store.EXPECT().GetUserByUsername("unknown").Return(nil, model.NewErrNotFound("user")).AnyTimes()
+	th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil).AnyTimes()
+
+	t.Run("a successful login forgets the failures", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := th.App.Login("john", "", "bad-password", "", "10.0.0.1", "")
+			require.Error(t, err)
+		}
+		_, err := th.App.Login("john", "", "john-password", "", "10.0.0.1", "")
+		require.NoError(t, err)
+
+		_, err = th.App.Login("john", "", "bad-password", "", "10.0.0.1", "")
+		var lockedErr *LoginLockedError
+		require.Error(t, err)
+		require.False(t, errors.As(err, &lockedErr))
+	})
+
+	t.Run("the account is locked after too many failures", func(t *testing.T) {
+		th.App.accountLimiter.Reset("john")
+
+		for i := 0; i < 2; i++ {
+			_, err := th.App.Login("john", "", "bad-password", "", "10.0.0.2", "")
+			var lockedErr *LoginLockedError
+			require.False(t, errors.As(err, &lockedErr))
+		}
+
+		// usernames are not case sensitive
+		_, err := th.App.Login(" John", "", "bad-password", "", "10.0.0.2", "")
+		var lockedErr *LoginLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		require.True(t, lockedErr.Locked)
+		require.Equal(t, "john", lockedErr.Account)
+
+		// the right password is refused too
+		_, err = th.App.Login("john", "", "john-password", "", "10.0.0.3", "")
+		require.ErrorAs(t, err, &lockedErr)
+		require.False(t, lockedErr.Locked)
+
+		require.NoError(t, th.App.UnlockUser("john"))
+		_, err = th.App.Login("john", "", "john-password", "", "10.0.0.3", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown accounts are locked too", func(t *testing.T) {
+		var err error
+		for i := 0; i < 3; i++ {
+			_, err = th.App.Login("unknown", "", "password", "", "", "")
+		}
+		var lockedErr *LoginLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		require.Equal(t, "unknown", lockedErr.Account)
+	})
+
+	t.Run("the IP address is locked after too many failures", func(t *testing.T) {
+		var err error
+		for i := 0; i < 5; i++ {
+			th.App.accountLimiter.Reset("john")
+			_, err = th.App.Login("john", "", "bad-password", "", "10.0.0.4", "")
+		}
+		var lockedErr *LoginLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		require.Empty(t, lockedErr.Account)
+		require.Equal(t, "10.0.0.4", lockedErr.IPAddress)
+
+		_, err = th.App.Login("john", "", "john-password", "", "10.0.0.4", "")
+		require.ErrorAs(t, err, &lockedErr)
+
+		_, err = th.App.Login("john", "", "john-password", "", "10.0.0.5", "")
+		require.NoError(t, err)
+	})
+}
+
+func TestLoginLockoutIgnoresMfa(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.accountLimiter, th.App.ipLimiter = newLoginLimiters(config.LoginLockoutConfig{MaxAccountFailures: 1})
+
+	th.App.config.RequireEmailVerification = true
+	user := &model.User{
+		ID:       "user-id",
+		Username: "john",
+		Password: auth.HashPassword("john-password"),
+	}
+	th.Store.EXPECT().GetUserByUsername("john").Return(user, nil).Times(2)
+
+	// a valid password without a verified email is not a failure
+	for i := 0; i < 2; i++ {
+		_, err := th.App.Login("john", "", "john-password", "", "", "")
+		require.ErrorIs(t, err, ErrEmailNotVerified)
+	}
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrMfaRequired     = errors.New("MFA token required")
+	ErrInvalidMfaToken = errors.New("invalid MFA token")
+)
+
+// GenerateMfaSecret starts the MFA enrolment of a user. The secret is
+// stored but MFA stays inactive until it is confirmed with ActivateMfa.
+func (a *App) GenerateMfaSecret(userID string) (*model.MfaEnrollment, error) {
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MfaActive {
+		return nil, model.NewErrBadRequest("MFA is already active")
+	}
+
+	secret, err := auth.NewMfaSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate MFA secret")
+	}
+
+	if err := a.store.UpdateUserMfa(userID, secret, false); err != nil {
+		return nil, errors.Wrap(err, "unable to store MFA secret")
+	}
+
+	return &model.MfaEnrollment{
+		Secret:    secret,
+		QRCodeURI: auth.MfaProvisioningURI(secret, user.Username),
+	}, nil
+}
+
+// ActivateMfa completes the MFA enrolment of a user once the code of the
+// authenticator app has been confirmed, and returns the recovery codes.
+func (a *App) ActivateMfa(userID, code string) ([]string, error) {
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MfaActive {
+		return nil, model.NewErrBadRequest("MFA is already active")
+	}
+
+	if user.MfaSecret == "" {
+		return nil, model.NewErrBadRequest("MFA enrolment has not been started")
+	}
+
+	counter, valid, err := auth.ValidateTotpCode(user.MfaSecret, code, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to verify MFA code")
+	}
+	if !valid {
+		return nil, model.NewErrBadRequest("invalid MFA code")
+	}
+
+	accepted, err := a.store.UseMfaTotpCounter(userID, counter)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to verify MFA code")
+	}
+	if !accepted {
+		return nil, model.NewErrBadRequest("MFA code already used")
+	}
+
+	recoveryCodes, err := a.newMfaRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.store.UpdateUserMfa(userID, user.MfaSecret, true); err != nil {
+		return nil, errors.Wrap(err, "unable to activate MFA")
+	}
+
+	return recoveryCodes, nil
+}
+
+// DeactivateMfa disables MFA for a user who can provide a valid code.
+func (a *App) DeactivateMfa(userID, token string) error {
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MfaActive {
+		return model.NewErrBadRequest("MFA is not active")
+	}
+
+	if err := a.verifyMfaToken(user, token); err != nil {
+		return err
+	}
+
+	return a.resetMfa(userID)
+}
+
+// RegenerateMfaRecoveryCodes replaces the recovery codes of a user who
+// can provide a valid code.
+func (a *App) RegenerateMfaRecoveryCodes(userID, token string) ([]string, error) {
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MfaActive {
+		return nil, model.NewErrBadRequest("MFA is not active")
+	}
+
+	if err := a.verifyMfaToken(user, token); err != nil {
+		return nil, err
+	}
+
+	return a.newMfaRecoveryCodes(userID)
+}
+
+// ResetUserMfa disables MFA for a user, for instance when they have lost
+// both their authenticator and their recovery codes.
+func (a *App) ResetUserMfa(username string) error {
+	user, err := a.store.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	return a.resetMfa(user.ID)
+}
+
+func (a *App) resetMfa(userID string) error {
+	if err := a.store.UpdateUserMfa(userID, "", false); err != nil {
+		return errors.Wrap(err, "unable to deactivate MFA")
+	}
+
+	if err := a.store.SaveMfaRecoveryCodes(userID, nil); err != nil {
+		return errors.Wrap(err, "unable to delete MFA recovery codes")
+	}
+
+	return nil
+}
+
+func (a *App) newMfaRecoveryCodes(userID string) ([]string, error) {
+	recoveryCodes := auth.NewMfaRecoveryCodes()
+	codeHashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		codeHashes[i] = auth.HashToken(code)
+	}
+
+	if err := a.store.SaveMfaRecoveryCodes(userID, codeHashes); err != nil {
+		return nil, errors.Wrap(err, "unable to store MFA recovery codes")
+	}
+
+	return recoveryCodes, nil
+}
+
+// verifyMfaToken checks a TOTP code that wasn't used yet or, failing
+// that, consumes a recovery code of the user.
+func (a *App) verifyMfaToken(user *model.User, token string) error {
+	if token == "" {
+		return ErrMfaRequired
+	}
+
+	counter, valid, err := auth.ValidateTotpCode(user.MfaSecret, token, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "unable to verify MFA token")
+	}
+	if valid {
+		// a code can only be used once, so that an observed code can't be
+		// replayed while it's still valid
+		accepted, useErr := a.store.UseMfaTotpCounter(user.ID, counter)
+		if useErr != nil {
+			return errors.Wrap(useErr, "unable to verify MFA token")
+		}
+		if !accepted {
+			a.logger.Warn("MFA code reused", mlog.String("userID", user.ID))
+			return ErrInvalidMfaToken
+		}
+		return nil
+	}
+
+	used, err := a.store.UseMfaRecoveryCode(user.ID, auth.HashToken(auth.NormalizeMfaRecoveryCode(token)))
+	if err != nil {
+		return errors.Wrap(err, "unable to verify MFA recovery code")
+	}
+	if used {
+		a.logger.Info("MFA recovery code used", mlog.String("userID", user.ID))
+		return nil
+	}
+
+	return ErrInvalidMfaToken
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func newMfaUser(t *testing.T, active bool) (*model.User, string) {
+	secret, err := auth.NewMfaSecret()
+	require.NoError(t, err)
+
+	code, err := auth.GenerateTotpCode(secret, time.Now())
+	require.NoError(t, err)
+
+	return &model.User{
+		ID:        "user-id",
+		Username:  "mfaUsername",
+		Password:  auth.HashPassword("testPassword"),
+		MfaSecret: secret,
+		MfaActive: active,
+	}, code
+}
+
+func TestGenerateMfaSecret(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("stores an inactive secret", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID("user-id").Return(&model.User{ID: "user-id", Username: "john"}, nil)
+
+		var storedSecret string
+		th.Store.EXPECT().UpdateUserMfa("user-id", gomock.Any(), false).DoAndReturn(func(_, secret string, _ bool) error {
+			storedSecret = secret
+			return nil
+		})
+
+		enrollment, err := th.App.GenerateMfaSecret("user-id")
+		require.NoError(t, err)
+		require.Equal(t, storedSecret, enrollment.Secret)
+		require.Contains(t, enrollment.QRCodeURI, "otpauth://totp/")
+		require.Contains(t, enrollment.QRCodeURI, "secret="+enrollment.Secret)
+	})
+
+	t.Run("MFA already active", func(t *testing.T) {
+		user, _ := newMfaUser(t, true)
+		th.Store.EXPECT().GetUserByID("user-id").Return(user, nil)
+
+		enrollment, err := th.App.GenerateMfaSecret("user-id")
+		require.True(t, model.IsErrBadRequest(err))
+		require.Nil(t, enrollment)
+	})
+}
+
+func TestActivateMfa(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("valid code", func(t *testing.T) {
+		user, code := newMfaUser(t, false)
+		th.Store.EXPECT().GetUserByID("user-id").Return(user, nil)
+
+		var codeHashes []string
+		th.Store.EXPECT().SaveMfaRecoveryCodes("user-id", gomock.Any()).DoAndReturn(func(_ string, hashes []string) error {
+			codeHashes = hashes
+			return nil
+		})
+		th.Store.EXPECT().UseMfaTotpCounter("user-id", gomock.Any()).Return(true, nil)
+		th.Store.EXPECT().UpdateUserMfa("user-id", user.MfaSecret, true).Return(nil)
+
+		recoveryCodes, err := th.App.ActivateMfa("user-id", code)
+		require.NoError(t, err)
+		require.Len(t, recoveryCodes, auth.MfaRecoveryCodeCount)
+		require.Len(t, codeHashes, auth.MfaRecoveryCodeCount)
+		for i, recoveryCode := range recoveryCodes {
+			require.Equal(t, auth.HashToken(recoveryCode), codeHashes[i])
+		}
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		user, _ := newMfaUser(t, false)
+		th.Store.EXPECT().GetUserByID("user-id").Return(user, nil)
+
+		recoveryCodes, err := th.App.ActivateMfa("user-id", "000000x")
+		require.True(t, model.IsErrBadRequest(err))
+		require.Nil(t, recoveryCodes)
+	})
+
+	t.Run("enrolment not started", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID("user-id").Return(&model.User{ID: "user-id"}, nil)
+
+		recoveryCodes, err := th.App.ActivateMfa("user-id", "123456")
+		require.True(t, model.IsErrBadRequest(err))
+		require.Nil(t, recoveryCodes)
+	})
+}
+
+func TestLoginWithMfa(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	user, code := newMfaUser(t, true)
+
+	t.Run("missing MFA token", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("mfaUsername").Return(user, nil)
+
+		token, err := th.App.Login("mfaUsername", "", "testPassword", "", "", "")
+		require.ErrorIs(t, err, ErrMfaRequired)
+		require.Empty(t, token)
+	})
+
+	t.Run("invalid MFA token", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("mfaUsername").Return(user, nil)
+		th.Store.EXPECT().UseMfaRecoveryCode("user-id", gomock.Any()).Return(false, nil)
+
+		token, err := th.App.Login("mfaUsername", "", "testPassword", "badcode", "", "")
+		require.ErrorIs(t, err, ErrInvalidMfaToken)
+		require.Empty(t, token)
+	})
+
+	t.Run("invalid password is checked before MFA", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("mfaUsername").Return(user, nil)
+
+		token, err := th.App.Login("mfaUsername", "", "badPassword", code, "", "")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrMfaRequired)
+		require.Empty(t, token)
+	})
+
+	t.Run("valid TOTP code", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("mfaUsername").Return(user, nil)
+		th.Store.EXPECT().UseMfaTotpCounter("user-id", gomock.Any()).Return(true, nil)
+		th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+
+		token, err := th.App.Login("mfaUsername", "", "testPassword", code, "", "")
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+	})
+
+	t.Run("TOTP code already used", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("mfaUsername").Return(user, nil)
+		th.Store.EXPECT().UseMfaTotpCounter("user-id", gomock.Any()).Return(false, nil)
+
+		token, err := th.App.Login("mfaUsername", "", "testPassword", code, "", "")
+		require.ErrorIs(t, err, ErrInvalidMfaToken)
+		require.Empty(t, token)
+	})
+
+	t.Run("valid recovery code", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("mfaUsername").Return(user, nil)
+		th.Store.EXPECT().UseMfaRecoveryCode("user-id", auth.HashToken("abcde-fghij")).Return(true, nil)
+		th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+
+		token, err := th.App.Login("mfaUsername", "", "testPassword", "ABCDEFGHIJ", "", "")
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+	})
+}
+
+func TestResetUserMfa(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	user, _ := newMfaUser(t, true)
+	th.Store.EXPECT().GetUserByUsername("mfaUsername").Return(user, nil)
+	th.Store.EXPECT().UpdateUserMfa("user-id", "", false).Return(nil)
+	th.Store.EXPECT().SaveMfaRecoveryCodes("user-id", nil).Return(nil)
+
+	require.NoError(t, th.App.ResetUserMfa("mfaUsername"))
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/oidc"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/pkg/errors"
+)
+
+// GetOIDCAuthCodeURL returns the URL of the OpenID Connect provider
+// where the user has to be redirected to log in.
+func (a *App) GetOIDCAuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	if a.oidc == nil {
+		return "", model.NewErrNotImplemented("OIDC authentication is not enabled")
+	}
+
+	authURL, err := a.oidc.AuthCodeURL(state, nonce, codeChallenge)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to build the OIDC authorization URL")
+	}
+	return authURL, nil
+}
+
+// LoginWithOIDC completes an OpenID Connect login, provisioning the user
+// on their first login, and returns the token of the new session.
+func (a *App) LoginWithOIDC(code, codeVerifier, nonce, ipAddress, userAgent string) (string, error) {
+	if a.oidc == nil {
+		return "", model.NewErrNotImplemented("OIDC authentication is not enabled")
+	}
+
+	token, err := a.oidc.Exchange(code, codeVerifier)
+	if err != nil {
+		a.metrics.IncrementLoginFailCount(1)
+		return "", errors.Wrap(err, "unable to complete the OIDC login")
+	}
+
+	claims, err := a.oidc.VerifyIDToken(token.IDToken, nonce)
+	if err != nil {
+		a.metrics.IncrementLoginFailCount(1)
+		return "", errors.Wrap(err, "unable to complete the OIDC login")
+	}
+
+	user, err := a.syncOIDCUser(claims)
+	if err != nil {
+		a.metrics.IncrementLoginFailCount(1)
+		return "", err
+	}
+
+	return a.createSession(user, ipAddress, userAgent)
+}
+
+// syncOIDCUser returns the user linked to the subject of the claims,
+// creating it if needed and keeping its email and roles up to date. The
+// deactivated users are refused. In
+// standalone mode every user is a member of the root team, so creating
+// the user is enough to give them access to it.
+func (a *App) syncOIDCUser(claims oidc.Claims) (*model.User, error) {
+	subject := claims.String("sub")
+	email := claims.String("email")
+	roles := a.oidcRoles(claims)
+
+	user, err := a.getExternalUser(model.AuthModeOIDC, subject)
+	if err == nil {
+		if (email == "" || user.Email == email) && user.Roles == roles {
+			return user, nil
+		}
+		if email != "" && user.Email != email {
+			if err = a.checkExternalUser(user.ID, user.Username, email); err != nil {
+				return nil, err
+			}
+			user.Email = email
+		}
+		user.Roles = roles
+		user, err = a.store.UpdateUser(user)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to update the OIDC user")
+		}
+		return user, nil
+	}
+	if !model.IsErrNotFound(err) {
+		return nil, err
+	}
+
+	username := claims.String(a.oidc.UsernameClaim())
+	if username == "" && email != "" {
+		username, _, _ = strings.Cut(email, "@")
+	}
+	if username == "" {
+		username = subject
+	}
+
+	if err = a.checkExternalUser("", username, email); err != nil {
+		return nil, err
+	}
+
+	user, err = a.store.CreateUser(&model.User{
+		ID:          utils.NewID(utils.IDTypeUser),
+		Username:    username,
+		Email:       email,
+		AuthService: model.AuthModeOIDC,
+		AuthData:    subject,
+		Roles:       roles,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the OIDC user")
+	}
+
+	a.logger.Info("Provisioned OIDC user",
+		mlog.String("userID", user.ID),
+		mlog.String("username", username),
+	)
+
+	return user, nil
+}
+
+// oidcRoles maps the roles claim to the roles of the user.
+func (a *App) oidcRoles(claims oidc.Claims) string {
+	adminRoles := a.oidc.Config().AdminRoles
+	for _, role := range claims.Strings(a.oidc.RolesClaim()) {
+		for _, adminRole := range adminRoles {
+			if role == adminRole {
+				return model.SystemUserRoleID + " " + model.SystemAdminRoleID
+			}
+		}
+	}
+	return model.SystemUserRoleID
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/oidc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncOIDCUser(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.oidc = oidc.New(config.OIDCConfig{AdminRoles: []string{"board-admins"}}, "http://localhost:8000")
+
+	claims := oidc.Claims{
+		"sub":                "subject-id",
+		"email":              "john@example.com",
+		"preferred_username": "john",
+	}
+
+	t.Run("provisions a new user", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeOIDC, "subject-id").Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{}, 0, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Email: "john@example.com"}).Return([]*model.User{}, 0, nil)
+		th.Store.EXPECT().CreateUser(gomock.Any()).DoAndReturn(func(user *model.User) (*model.User, error) { return user, nil })
+
+		user, err := th.App.syncOIDCUser(claims)
+		require.NoError(t, err)
+		require.Equal(t, "john", user.Username)
+		require.Equal(t, model.AuthModeOIDC, user.AuthService)
+		require.Equal(t, "subject-id", user.AuthData)
+		require.False(t, user.IsSystemAdmin())
+	})
+
+	t.Run("does not link existing accounts", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeOIDC, "subject-id").Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{{ID: "other-id", Username: "john"}}, 1, nil)
+
+		user, err := th.App.syncOIDCUser(claims)
+		require.True(t, model.IsErrForbidden(err))
+		require.Nil(t, user)
+	})
+
+	t.Run("does not provision a deactivated user again", func(t *testing.T) {
+		deactivated := &model.User{ID: "user-id", Username: "john", Email: "john@example.com", DeleteAt: 1}
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeOIDC, "subject-id").Return(deactivated, nil)
+
+		user, err := th.App.syncOIDCUser(claims)
+		require.True(t, model.IsErrForbidden(err))
+		require.Nil(t, user)
+	})
+
+	t.Run("does not take the email of another account", func(t *testing.T) {
+		existing := &model.User{ID: "user-id", Username: "john", Email: "old@example.com", Roles: model.SystemUserRoleID}
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeOIDC, "subject-id").Return(existing, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{existing}, 1, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Email: "john@example.com"}).Return([]*model.User{{ID: "other-id", DeleteAt: 1}}, 1, nil)
+
+		user, err := th.App.syncOIDCUser(claims)
+		require.True(t, model.IsErrForbidden(err))
+		require.Nil(t, user)
+	})
+
+	t.Run("updates the roles of a linked user", func(t *testing.T) {
+		adminClaims := oidc.Claims{
+			"sub":   "subject-id",
+			"email": "john@example.com",
+			"roles": []interface{}{"board-admins"},
+		}
+		existing := &model.User{ID: "user-id", Username: "john", Email: "john@example.com", Roles: model.SystemUserRoleID}
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeOIDC, "subject-id").Return(existing, nil)
+		th.Store.EXPECT().UpdateUser(existing).Return(existing, nil)
+
+		user, err := th.App.syncOIDCUser(adminClaims)
+		require.NoError(t, err)
+		require.True(t, user.IsSystemAdmin())
+	})
+
+	t.Run("linked user without changes", func(t *testing.T) {
+		existing := &model.User{ID: "user-id", Username: "john", Email: "john@example.com", Roles: model.SystemUserRoleID}
+		th.Store.EXPECT().GetUserByAuthData(model.AuthModeOIDC, "subject-id").Return(existing, nil)
+
+		user, err := th.App.syncOIDCUser(claims)
+		require.NoError(t, err)
+		require.Equal(t, existing, user)
+	})
+}
+
+func TestOIDCRoles(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	claims := oidc.Claims{"roles": []interface{}{"board-admins", model.SystemAdminRoleID}}
+
+	t.Run("the claims don't make system admins unless configured", func(t *testing.T) {
+		th.App.oidc = oidc.New(config.OIDCConfig{}, "http://localhost:8000")
+		require.Equal(t, model.SystemUserRoleID, th.App.oidcRoles(claims))
+	})
+
+	t.Run("only the configured roles make system admins", func(t *testing.T) {
+		th.App.oidc = oidc.New(config.OIDCConfig{AdminRoles: []string{"other-admins"}}, "http://localhost:8000")
+		require.Equal(t, model.SystemUserRoleID, th.App.oidcRoles(claims))
+
+		th.App.oidc = oidc.New(config.OIDCConfig{AdminRoles: []string{"board-admins"}}, "http://localhost:8000")
+		require.Equal(t, model.SystemUserRoleID+" "+model.SystemAdminRoleID, th.App.oidcRoles(claims))
+	})
+}
+
+func TestLoginWithOIDCNotEnabled(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	token, err := th.App.LoginWithOIDC("code", "verifier", "nonce", "", "")
+	require.True(t, model.IsErrNotImplemented(err))
+	require.Empty(t, token)
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/pkg/errors"
+)
+
+// passwordSettings returns the password policy of the native users. It
+// can't be weaker than the minimum length checked by the API.
+func (a *App) passwordSettings() auth.PasswordSettings {
+	cfg := a.config.PasswordSettings
+	settings := auth.PasswordSettings{
+		MinimumLength: cfg.MinimumLength,
+		Lowercase:     cfg.Lowercase,
+		Uppercase:     cfg.Uppercase,
+		Number:        cfg.Number,
+		Symbol:        cfg.Symbol,
+	}
+	if settings.MinimumLength < model.MinimumPasswordLength {
+		settings.MinimumLength = model.MinimumPasswordLength
+	}
+	return settings
+}
+
+// GetPasswordPolicy returns the policy the passwords must follow.
+func (a *App) GetPasswordPolicy() model.PasswordPolicy {
+	settings := a.passwordSettings()
+	return model.PasswordPolicy{
+		MinimumLength: settings.MinimumLength,
+		MaximumLength: auth.PasswordMaximumLength,
+		Lowercase:     settings.Lowercase,
+		Uppercase:     settings.Uppercase,
+		Number:        settings.Number,
+		Symbol:        settings.Symbol,
+		MaxAgeDays:    max(a.config.PasswordSettings.MaxAgeDays, 0),
+		HistoryDepth:  max(a.config.PasswordSettings.HistoryDepth, 0),
+	}
+}
+
+// checkNewPassword checks a new password of a user against the policy
+// and the last passwords of the user.
+func (a *App) checkNewPassword(user *model.User, password string) error {
+	if err := auth.IsPasswordValid(password, a.passwordSettings()); err != nil {
+		return model.NewErrBadRequest(err.Error())
+	}
+
+	depth := a.config.PasswordSettings.HistoryDepth
+	if depth <= 0 {
+		return nil
+	}
+
+	hashes, err := a.store.GetPasswordHistory(user.ID, depth)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the password history")
+	}
+	// the current password may predate the history
+	if user.Password != "" {
+		hashes = append(hashes, user.Password)
+	}
+
+	for _, hash := range hashes {
+		if auth.ComparePassword(hash, password) {
+			return model.NewErrBadRequest("the password was used recently, choose a new one")
+		}
+	}
+	return nil
+}
+
+// setUserPassword checks and sets a new password for a user.
+func (a *App) setUserPassword(user *model.User, password string) error {
+	if err := a.checkNewPassword(user, password); err != nil {
+		return err
+	}
+
+	hash := auth.HashPassword(password)
+	if err := a.store.UpdateUserPasswordByID(user.ID, hash); err != nil {
+		return errors.Wrap(err, "unable to update password")
+	}
+
+	return a.addPasswordHistory(user.ID, hash)
+}
+
+// addPasswordHistory records the hash of a new password of a user, if
+// the policy keeps a history.
+func (a *App) addPasswordHistory(userID, hash string) error {
+	depth := a.config.PasswordSettings.HistoryDepth
+	if depth <= 0 {
+		return nil
+	}
+
+	if err := a.store.AddPasswordHistory(userID, hash, depth); err != nil {
+		return errors.Wrap(err, "unable to update the password history")
+	}
+	return nil
+}
+
+// isPasswordExpired returns true if the password of a native user is
+// older than the maximum age of the policy.
+func (a *App) isPasswordExpired(user *model.User) bool {
+	maxAgeDays := a.config.PasswordSettings.MaxAgeDays
+	if maxAgeDays <= 0 || !isNativeUser(user) || user.Password == "" {
+		return false
+	}
+
+	maxAge := time.Duration(maxAgeDays) * 24 * time.Hour
+	return utils.GetMillis()-user.PasswordUpdateAt > maxAge.Milliseconds()
+}
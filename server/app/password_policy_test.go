@@ -0,0 +1,139 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPasswordPolicy(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.PasswordSettings.MinimumLength = 4
+	th.App.config.PasswordSettings.Symbol = true
+	th.App.config.PasswordSettings.MaxAgeDays = 90
+	th.App.config.PasswordSettings.HistoryDepth = 5
+
+	policy := th.App.GetPasswordPolicy()
+	require.Equal(t, model.MinimumPasswordLength, policy.MinimumLength)
+	require.Equal(t, auth.PasswordMaximumLength, policy.MaximumLength)
+	require.True(t, policy.Symbol)
+	require.False(t, policy.Number)
+	require.Equal(t, 90, policy.MaxAgeDays)
+	require.Equal(t, 5, policy.HistoryDepth)
+
+	require.Equal(t, policy, th.App.GetClientConfig().PasswordPolicy)
+}
+
+func TestSetUserPasswordHistory(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.PasswordSettings.HistoryDepth = 3
+	user := &model.User{ID: "user-id", Password: auth.HashPassword("current-password")}
+
+	t.Run("the current password cannot be reused", func(t *testing.T) {
+		th.Store.EXPECT().GetPasswordHistory(user.ID, 3).Return([]string{}, nil)
+
+		err := th.App.setUserPassword(user, "current-password")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("the last passwords cannot be reused", func(t *testing.T) {
+		th.Store.EXPECT().GetPasswordHistory(user.ID, 3).Return([]string{auth.HashPassword("previous-password")}, nil)
+
+		err := th.App.setUserPassword(user, "previous-password")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("a new password is recorded", func(t *testing.T) {
+		th.Store.EXPECT().GetPasswordHistory(user.ID, 3).Return([]string{auth.HashPassword("previous-password")}, nil)
+
+		var hash string
+		th.Store.EXPECT().UpdateUserPasswordByID(user.ID, gomock.Any()).DoAndReturn(func(_, password string) error {
+			hash = password
+			return nil
+		})
+		th.Store.EXPECT().AddPasswordHistory(user.ID, gomock.Any(), 3).DoAndReturn(func(_, password string, _ int) error {
+			require.Equal(t, hash, password)
+			return nil
+		})
+
+		require.NoError(t, th.App.setUserPassword(user, "new-password"))
+		require.True(t, auth.ComparePassword(hash, "new-password"))
+	})
+
+	t.Run("no history without a depth", func(t *testing.T) {
+		th.App.config.PasswordSettings.HistoryDepth = 0
+		th.Store.EXPECT().UpdateUserPasswordByID(user.ID, gomock.Any()).Return(nil)
+
+		require.NoError(t, th.App.setUserPassword(user, "current-password"))
+	})
+}
+
+func TestIsPasswordExpired(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	day := 24 * time.Hour
+	oldUser := &model.User{
+		Password:         "hash",
+		PasswordUpdateAt: utils.GetMillis() - (31 * day).Milliseconds(),
+	}
+	recentUser := &model.User{
+		Password:         "hash",
+		PasswordUpdateAt: utils.GetMillis() - (29 * day).Milliseconds(),
+	}
+	ldapUser := &model.User{
+		AuthService:      model.AuthModeLDAP,
+		PasswordUpdateAt: oldUser.PasswordUpdateAt,
+	}
+
+	require.False(t, th.App.isPasswordExpired(oldUser))
+
+	th.App.config.PasswordSettings.MaxAgeDays = 30
+	require.True(t, th.App.isPasswordExpired(oldUser))
+	require.False(t, th.App.isPasswordExpired(recentUser))
+	require.False(t, th.App.isPasswordExpired(ldapUser))
+}
+
+func TestLoginExpiredPassword(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.PasswordSettings.MaxAgeDays = 30
+	user := &model.User{
+		ID:               "user-id",
+		Username:         "john",
+		Password:         auth.HashPassword("john-password"),
+		PasswordUpdateAt: utils.GetMillis() - (31 * 24 * time.Hour).Milliseconds(),
+	}
+	th.Store.EXPECT().GetUserByUsername("john").Return(user, nil)
+	th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+
+	var session *model.Session
+	th.Store.EXPECT().CreateSession(gomock.Any()).DoAndReturn(func(created *model.Session) error {
+		session = created
+		return nil
+	})
+
+	_, err := th.App.Login("john", "", "john-password", "", "", "")
+	require.NoError(t, err)
+	require.True(t, session.IsPasswordExpired())
+
+	t.Run("changing the password restores the session", func(t *testing.T) {
+		th.Store.EXPECT().UpdateUserPasswordByID(user.ID, gomock.Any()).Return(nil)
+		th.Store.EXPECT().UpdateSession(session).Return(nil)
+		th.Store.EXPECT().GetUserSessions(user.ID, gomock.Any()).Return([]*model.Session{session}, nil)
+		th.Store.EXPECT().DeleteUserSessions(user.ID, session.ID).Return(nil)
+
+		require.NoError(t, th.App.ChangePassword(user.ID, "john-password", "new-password", session))
+		require.False(t, session.IsPasswordExpired())
+	})
+}
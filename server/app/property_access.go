@@ -0,0 +1,119 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// GetPropertyRestrictions returns the card properties of the board whose
+// values the user can't see or edit.
+func (a *App) GetPropertyRestrictions(board *model.Board, userID string) (*model.PropertyRestrictions, error) {
+	if userID == model.SystemUserID || !model.HasPropertyAccessRules(board) {
+		return model.GetPropertyRestrictions(nil, nil), nil
+	}
+
+	member, err := a.store.GetMemberForBoard(board.ID, userID)
+	if err != nil && !model.IsErrNotFound(err) {
+		return nil, err
+	}
+	return model.GetPropertyRestrictions(board, member), nil
+}
+
+// GetPropertyRestrictionsForBoard returns the card properties of the
+// board whose values the user can't see or edit.
+func (a *App) GetPropertyRestrictionsForBoard(boardID, userID string) (*model.PropertyRestrictions, error) {
+	board, err := a.store.GetBoard(boardID)
+	if model.IsErrNotFound(err) {
+		return model.GetPropertyRestrictions(nil, nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a.GetPropertyRestrictions(board, userID)
+}
+
+// blockPatchChangesProperties returns true if the patch updates or
+// deletes the property values of a card.
+func blockPatchChangesProperties(oldBlock *model.Block, patch *model.BlockPatch) bool {
+	if oldBlock.Type != model.TypeCard {
+		return false
+	}
+	if _, ok := patch.UpdatedFields["properties"]; ok {
+		return true
+	}
+	for _, field := range patch.DeletedFields {
+		if field == "properties" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBlockPatchPropertyAccess makes sure that a block patch doesn't
+// change the card property values that the user can't edit. The patch is
+// updated to keep them.
+func (a *App) checkBlockPatchPropertyAccess(board *model.Board, oldBlock *model.Block, patch *model.BlockPatch, userID string) error {
+	if !blockPatchChangesProperties(oldBlock, patch) {
+		return nil
+	}
+
+	restrictions, err := a.GetPropertyRestrictions(board, userID)
+	if err != nil {
+		return err
+	}
+	if len(restrictions.ReadOnly) == 0 {
+		return nil
+	}
+
+	newProperties, _ := patch.UpdatedFields["properties"].(map[string]interface{})
+	properties, err := restrictions.ApplyPropertyChanges(model.GetBlockProperties(oldBlock), newProperties)
+	if err != nil {
+		return err
+	}
+
+	if patch.UpdatedFields == nil {
+		patch.UpdatedFields = map[string]interface{}{}
+	}
+	patch.UpdatedFields["properties"] = properties
+
+	deletedFields := make([]string, 0, len(patch.DeletedFields))
+	for _, field := range patch.DeletedFields {
+		if field != "properties" {
+			deletedFields = append(deletedFields, field)
+		}
+	}
+	patch.DeletedFields = deletedFields
+	return nil
+}
+
+// checkInsertedBlocksPropertyAccess makes sure that the inserted cards
+// don't set or change the property values that the user can't edit.
+func (a *App) checkInsertedBlocksPropertyAccess(board *model.Board, blocks []*model.Block, userID string) error {
+	restrictions, err := a.GetPropertyRestrictions(board, userID)
+	if err != nil {
+		return err
+	}
+	if len(restrictions.ReadOnly) == 0 {
+		return nil
+	}
+
+	for _, block := range blocks {
+		if block.Type != model.TypeCard {
+			continue
+		}
+
+		oldBlock, err := a.store.GetBlock(block.ID)
+		if err != nil && !model.IsErrNotFound(err) {
+			return err
+		}
+
+		properties, err := restrictions.ApplyPropertyChanges(model.GetBlockProperties(oldBlock), model.GetBlockProperties(block))
+		if err != nil {
+			return err
+		}
+		if block.Fields == nil {
+			block.Fields = map[string]interface{}{}
+		}
+		block.Fields["properties"] = properties
+	}
+	return nil
+}
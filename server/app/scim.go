@@ -0,0 +1,921 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/pkg/errors"
+)
+
+// SCIM provisioning maps the SCIM users onto the native users, which
+// are deactivated instead of deleted, and the SCIM groups onto the
+// teams, the members of a group being the members of its team. The
+// root team isn't exposed as a group, as every user is a member of it.
+
+const scimBasePath = "/scim/v2"
+
+// scimPage returns the store offset and limit of a page of a SCIM list,
+// whose start index is 1-based.
+func scimPage(startIndex, count int) (int, int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > model.SCIMMaxResults {
+		count = model.SCIMMaxResults
+	}
+	return startIndex - 1, count
+}
+
+func scimTime(millis int64) string {
+	if millis == 0 {
+		return ""
+	}
+	return time.UnixMilli(millis).UTC().Format(time.RFC3339)
+}
+
+func (a *App) scimLocation(resource, id string) string {
+	return strings.TrimRight(a.config.ServerRoot, "/") + scimBasePath + "/" + resource + "/" + id
+}
+
+func newSCIMList(resources interface{}, total, startIndex, itemsPerPage int) *model.SCIMListResponse {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	return &model.SCIMListResponse{
+		Schemas:      []string{model.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: itemsPerPage,
+		Resources:    resources,
+	}
+}
+
+// Users
+
+// GetSCIMUsers returns a page of the users matching the filter, which
+// can be on the id, the userName or the email of the users.
+func (a *App) GetSCIMUsers(filter *model.SCIMFilter, startIndex, count int) (*model.SCIMListResponse, error) {
+	opts := model.QueryUsersOptions{}
+	if filter != nil {
+		switch filter.Attribute {
+		case "id":
+			opts.UserID = filter.Value
+		case "username":
+			opts.Username = filter.Value
+		case "emails", "emails.value":
+			opts.Email = filter.Value
+		default:
+			return nil, model.NewErrBadRequest(fmt.Sprintf("unsupported filter attribute: %s", filter.Attribute))
+		}
+	}
+
+	offset, limit := scimPage(startIndex, count)
+	opts.Offset = offset
+	// the store has no limit for 0, only the total is needed then
+	opts.Limit = max(limit, 1)
+
+	users, total, err := a.store.QueryUsers(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the users")
+	}
+	if limit == 0 {
+		users = nil
+	}
+
+	teams, err := a.scimTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*model.SCIMUser, 0, len(users))
+	for _, user := range users {
+		scimUser, err := a.toSCIMUser(user, teams)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, scimUser)
+	}
+
+	return newSCIMList(resources, total, startIndex, len(resources)), nil
+}
+
+// GetSCIMUser returns a user, active or not.
+func (a *App) GetSCIMUser(userID string) (*model.SCIMUser, error) {
+	user, err := a.getSCIMUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return a.toSCIMUserWithTeams(user)
+}
+
+// CreateSCIMUser provisions a native user. The user is trusted, so its
+// email is considered verified. Without a password, the user sets one
+// with a password reset.
+func (a *App) CreateSCIMUser(scimUser *model.SCIMUser) (*model.SCIMUser, error) {
+	user := &model.User{
+		ID:            utils.NewID(utils.IDTypeUser),
+		EmailVerified: true,
+		AuthService:   a.config.AuthMode,
+	}
+	applySCIMUser(user, scimUser)
+
+	if err := a.checkSCIMUser(user); err != nil {
+		return nil, err
+	}
+
+	if scimUser.Password != "" {
+		if err := a.checkNewPassword(user, scimUser.Password); err != nil {
+			return nil, err
+		}
+		user.Password = auth.HashPassword(scimUser.Password)
+	}
+
+	if _, err := a.store.CreateUser(user); err != nil {
+		return nil, errors.Wrap(err, "unable to create the user")
+	}
+
+	if user.Password != "" {
+		if err := a.addPasswordHistory(user.ID, user.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	if scimUser.Active != nil && !*scimUser.Active {
+		if err := a.setUserActive(user, false); err != nil {
+			return nil, err
+		}
+	}
+
+	a.logger.Info("Provisioned SCIM user",
+		mlog.String("userID", user.ID),
+		mlog.String("username", user.Username),
+	)
+
+	return a.toSCIMUserWithTeams(user)
+}
+
+// ReplaceSCIMUser replaces the attributes of a user. The active state
+// and the password are only changed if they are given.
+func (a *App) ReplaceSCIMUser(userID string, scimUser *model.SCIMUser) (*model.SCIMUser, error) {
+	user, err := a.getSCIMUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	applySCIMUser(user, scimUser)
+	changes := scimUserChanges{password: scimUser.Password, active: scimUser.Active}
+	if err := a.saveSCIMUser(user, changes); err != nil {
+		return nil, err
+	}
+
+	return a.toSCIMUserWithTeams(user)
+}
+
+// PatchSCIMUser applies a list of operations to a user. Unsupported
+// attributes are ignored, as providers send every mapped attribute.
+func (a *App) PatchSCIMUser(userID string, patch *model.SCIMPatchRequest) (*model.SCIMUser, error) {
+	user, err := a.getSCIMUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := scimUserChanges{}
+	for _, operation := range patch.Operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return nil, model.NewErrBadRequest(fmt.Sprintf("invalid patch operation: %s", operation.Op))
+		}
+
+		if operation.Path == "" {
+			if op == "remove" {
+				return nil, model.NewErrBadRequest("a remove operation requires a path")
+			}
+			var attributes map[string]json.RawMessage
+			if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+				return nil, model.NewErrBadRequest("the value of an operation without path must be an object")
+			}
+			for path, value := range attributes {
+				if err := patchSCIMUserAttribute(user, &changes, path, value, false); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if err := patchSCIMUserAttribute(user, &changes, operation.Path, operation.Value, op == "remove"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := a.saveSCIMUser(user, changes); err != nil {
+		return nil, err
+	}
+
+	return a.toSCIMUserWithTeams(user)
+}
+
+// DeleteSCIMUser deactivates a user, as the users are never deleted.
+func (a *App) DeleteSCIMUser(userID string) error {
+	user, err := a.getSCIMUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.DeleteAt != 0 {
+		return nil
+	}
+	return a.setUserActive(user, false)
+}
+
+// scimUserChanges are the modifications of a user that aren't saved by
+// UpdateUser.
+type scimUserChanges struct {
+	password string
+	active   *bool
+}
+
+func (a *App) getSCIMUser(userID string) (*model.User, error) {
+	users, _, err := a.store.QueryUsers(model.QueryUsersOptions{UserID: userID, Limit: 1})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the user")
+	}
+	if len(users) == 0 {
+		return nil, model.NewErrNotFound("user ID=" + userID)
+	}
+	return users[0], nil
+}
+
+// checkSCIMUser checks that the username of a user is set, and that its
+// username and email aren't used by other users, deactivated or not.
+func (a *App) checkSCIMUser(user *model.User) error {
+	if user.Username == "" {
+		return model.NewErrBadRequest("the userName is required")
+	}
+
+	users, _, err := a.store.QueryUsers(model.QueryUsersOptions{Username: user.Username})
+	if err != nil {
+		return errors.Wrap(err, "unable to get the users")
+	}
+	for _, other := range users {
+		if other.ID != user.ID {
+			return model.NewErrConflict(fmt.Sprintf("the userName %s is already used", user.Username))
+		}
+	}
+
+	if user.Email == "" {
+		return nil
+	}
+	users, _, err = a.store.QueryUsers(model.QueryUsersOptions{Email: user.Email})
+	if err != nil {
+		return errors.Wrap(err, "unable to get the users")
+	}
+	for _, other := range users {
+		if other.ID != user.ID {
+			return model.NewErrConflict(fmt.Sprintf("the email %s is already used", user.Email))
+		}
+	}
+
+	return nil
+}
+
+func (a *App) saveSCIMUser(user *model.User, changes scimUserChanges) error {
+	if err := a.checkSCIMUser(user); err != nil {
+		return err
+	}
+
+	if _, err := a.store.UpdateUser(user); err != nil {
+		return errors.Wrap(err, "unable to update the user")
+	}
+
+	// providers may send the current password with every update
+	if changes.password != "" && (user.Password == "" || !auth.ComparePassword(user.Password, changes.password)) {
+		if err := a.setUserPassword(user, changes.password); err != nil {
+			return err
+		}
+	}
+
+	if changes.active != nil && *changes.active != (user.DeleteAt == 0) {
+		return a.setUserActive(user, *changes.active)
+	}
+	return nil
+}
+
+// setUserActive activates or deactivates a user. A deactivated user
+// can't log in, and all their sessions are revoked.
+func (a *App) setUserActive(user *model.User, active bool) error {
+	if active {
+		user.DeleteAt = 0
+	} else {
+		user.DeleteAt = utils.GetMillis()
+	}
+
+	if _, err := a.store.UpdateUser(user); err != nil {
+		return errors.Wrap(err, "unable to update the user")
+	}
+
+	if !active {
+		if err := a.revokeUserSessions(user.ID, ""); err != nil {
+			return err
+		}
+	}
+
+	a.logger.Info("SCIM user active state changed",
+		mlog.String("userID", user.ID),
+		mlog.Bool("active", active),
+	)
+	return nil
+}
+
+// applySCIMUser sets the attributes of a user from a SCIM user. The
+// displayName is used as nickname if no nickName is given.
+func applySCIMUser(user *model.User, scimUser *model.SCIMUser) {
+	user.Username = scimUser.UserName
+	user.Email = scimUser.PrimaryEmail()
+	user.FirstName = ""
+	user.LastName = ""
+	if scimUser.Name != nil {
+		user.FirstName = scimUser.Name.GivenName
+		user.LastName = scimUser.Name.FamilyName
+	}
+	user.Nickname = scimUser.NickName
+	if user.Nickname == "" {
+		user.Nickname = scimUser.DisplayName
+	}
+}
+
+func patchSCIMUserAttribute(user *model.User, changes *scimUserChanges, path string, value json.RawMessage, remove bool) error {
+	path = strings.ToLower(path)
+	if strings.HasPrefix(path, "emails") {
+		path = "emails"
+	}
+
+	switch path {
+	case "username":
+		if remove {
+			return model.NewErrBadRequest("the userName is required")
+		}
+		return unmarshalSCIMValue(value, &user.Username)
+	case "name":
+		var name model.SCIMName
+		if !remove {
+			if err := unmarshalSCIMValue(value, &name); err != nil {
+				return err
+			}
+		}
+		user.FirstName = name.GivenName
+		user.LastName = name.FamilyName
+	case "name.givenname":
+		user.FirstName = ""
+		if !remove {
+			return unmarshalSCIMValue(value, &user.FirstName)
+		}
+	case "name.familyname":
+		user.LastName = ""
+		if !remove {
+			return unmarshalSCIMValue(value, &user.LastName)
+		}
+	case "nickname", "displayname":
+		user.Nickname = ""
+		if !remove {
+			return unmarshalSCIMValue(value, &user.Nickname)
+		}
+	case "emails":
+		user.Email = ""
+		if !remove {
+			return unmarshalSCIMEmail(value, &user.Email)
+		}
+	case "active":
+		if remove {
+			return model.NewErrBadRequest("the active attribute can't be removed")
+		}
+		active, err := parseSCIMBool(value)
+		if err != nil {
+			return err
+		}
+		changes.active = &active
+	case "password":
+		if remove {
+			return model.NewErrBadRequest("the password can't be removed")
+		}
+		return unmarshalSCIMValue(value, &changes.password)
+	}
+
+	return nil
+}
+
+func unmarshalSCIMValue(value json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(value, v); err != nil {
+		return model.NewErrBadRequest(fmt.Sprintf("invalid value: %s", string(value)))
+	}
+	return nil
+}
+
+// unmarshalSCIMEmail reads an email given either as a string or as a
+// list of SCIM emails.
+func unmarshalSCIMEmail(value json.RawMessage, email *string) error {
+	if err := json.Unmarshal(value, email); err == nil {
+		return nil
+	}
+
+	scimUser := model.SCIMUser{}
+	if err := unmarshalSCIMValue(value, &scimUser.Emails); err != nil {
+		return err
+	}
+	*email = scimUser.PrimaryEmail()
+	return nil
+}
+
+// parseSCIMBool reads a boolean, that some providers send as a string.
+func parseSCIMBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, model.NewErrBadRequest(fmt.Sprintf("invalid boolean: %s", string(value)))
+}
+
+func (a *App) toSCIMUserWithTeams(user *model.User) (*model.SCIMUser, error) {
+	teams, err := a.scimTeams()
+	if err != nil {
+		return nil, err
+	}
+	return a.toSCIMUser(user, teams)
+}
+
+func (a *App) toSCIMUser(user *model.User, teams map[string]*model.Team) (*model.SCIMUser, error) {
+	members, err := a.store.GetTeamMembersForUser(user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the teams of the user")
+	}
+
+	active := user.DeleteAt == 0
+	scimUser := &model.SCIMUser{
+		Schemas:     []string{model.SCIMSchemaUser},
+		ID:          user.ID,
+		UserName:    user.Username,
+		DisplayName: user.Nickname,
+		NickName:    user.Nickname,
+		Active:      &active,
+		Meta: &model.SCIMMeta{
+			ResourceType: "User",
+			Created:      scimTime(user.CreateAt),
+			LastModified: scimTime(user.UpdateAt),
+			Location:     a.scimLocation("Users", user.ID),
+		},
+	}
+
+	if user.FirstName != "" || user.LastName != "" {
+		scimUser.Name = &model.SCIMName{
+			Formatted:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+			GivenName:  user.FirstName,
+			FamilyName: user.LastName,
+		}
+		if scimUser.DisplayName == "" {
+			scimUser.DisplayName = scimUser.Name.Formatted
+		}
+	}
+	if scimUser.DisplayName == "" {
+		scimUser.DisplayName = user.Username
+	}
+
+	if user.Email != "" {
+		scimUser.Emails = []model.SCIMEmail{{Value: user.Email, Type: "work", Primary: true}}
+	}
+
+	for _, member := range members {
+		team, ok := teams[member.TeamID]
+		if !ok {
+			continue
+		}
+		scimUser.Groups = append(scimUser.Groups, model.SCIMMember{
+			Value:   team.ID,
+			Display: team.Title,
+			Ref:     a.scimLocation("Groups", team.ID),
+		})
+	}
+
+	return scimUser, nil
+}
+
+// Groups
+
+// scimTeams returns the teams exposed as SCIM groups, indexed by ID.
+func (a *App) scimTeams() (map[string]*model.Team, error) {
+	teams, err := a.store.GetAllTeams()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the teams")
+	}
+
+	scimTeams := map[string]*model.Team{}
+	for _, team := range teams {
+		if team.ID != model.GlobalTeamID {
+			scimTeams[team.ID] = team
+		}
+	}
+	return scimTeams, nil
+}
+
+// GetSCIMGroups returns a page of the groups matching the filter, which
+// can be on the id or the displayName of the groups. The members are
+// omitted if they are excluded by the request.
+func (a *App) GetSCIMGroups(filter *model.SCIMFilter, startIndex, count int, withMembers bool) (*model.SCIMListResponse, error) {
+	if filter != nil && filter.Attribute != "id" && filter.Attribute != "displayname" {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("unsupported filter attribute: %s", filter.Attribute))
+	}
+
+	teams, err := a.scimTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	matching := []*model.Team{}
+	for _, team := range teams {
+		switch {
+		case filter == nil,
+			filter.Attribute == "id" && team.ID == filter.Value,
+			filter.Attribute == "displayname" && strings.EqualFold(team.Title, filter.Value):
+			matching = append(matching, team)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].ID < matching[j].ID
+	})
+
+	offset, limit := scimPage(startIndex, count)
+	page := matching[min(offset, len(matching)):min(offset+limit, len(matching))]
+
+	resources := make([]*model.SCIMGroup, 0, len(page))
+	for _, team := range page {
+		group, err := a.toSCIMGroup(team, withMembers)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, group)
+	}
+
+	return newSCIMList(resources, len(matching), startIndex, len(resources)), nil
+}
+
+// GetSCIMGroup returns a group.
+func (a *App) GetSCIMGroup(teamID string, withMembers bool) (*model.SCIMGroup, error) {
+	team, err := a.getSCIMTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+	return a.toSCIMGroup(team, withMembers)
+}
+
+// CreateSCIMGroup creates a team with the members of the group.
+func (a *App) CreateSCIMGroup(group *model.SCIMGroup) (*model.SCIMGroup, error) {
+	team := &model.Team{
+		ID:    utils.NewID(utils.IDTypeTeam),
+		Title: group.DisplayName,
+	}
+	if err := a.checkSCIMTeam(team); err != nil {
+		return nil, err
+	}
+
+	userIDs, err := a.scimMemberIDs(group.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := a.store.CreateTeam(team); err != nil {
+		return nil, errors.Wrap(err, "unable to create the team")
+	}
+
+	if err := a.setSCIMTeamMembers(team.ID, userIDs); err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Provisioned SCIM group",
+		mlog.String("teamID", team.ID),
+		mlog.String("title", team.Title),
+	)
+
+	return a.toSCIMGroup(team, true)
+}
+
+// ReplaceSCIMGroup replaces the name and the members of a group.
+func (a *App) ReplaceSCIMGroup(teamID string, group *model.SCIMGroup) (*model.SCIMGroup, error) {
+	team, err := a.getSCIMTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, err := a.scimMemberIDs(group.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.renameSCIMTeam(team, group.DisplayName); err != nil {
+		return nil, err
+	}
+
+	if err := a.setSCIMTeamMembers(team.ID, userIDs); err != nil {
+		return nil, err
+	}
+
+	return a.toSCIMGroup(team, true)
+}
+
+// PatchSCIMGroup applies a list of operations to the name or the members
+// of a group.
+func (a *App) PatchSCIMGroup(teamID string, patch *model.SCIMPatchRequest) (*model.SCIMGroup, error) {
+	team, err := a.getSCIMTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := a.store.GetTeamMembers(team.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the team members")
+	}
+	userIDs := map[string]bool{}
+	for _, member := range members {
+		userIDs[member.UserID] = true
+	}
+	title := team.Title
+
+	for _, operation := range patch.Operations {
+		op := strings.ToLower(operation.Op)
+		path := strings.ToLower(operation.Path)
+
+		switch {
+		case op != "add" && op != "replace" && op != "remove":
+			return nil, model.NewErrBadRequest(fmt.Sprintf("invalid patch operation: %s", operation.Op))
+
+		case path == "":
+			if op == "remove" {
+				return nil, model.NewErrBadRequest("a remove operation requires a path")
+			}
+			var group model.SCIMGroup
+			if err := unmarshalSCIMValue(operation.Value, &group); err != nil {
+				return nil, err
+			}
+			if group.DisplayName != "" {
+				title = group.DisplayName
+			}
+			if group.Members != nil {
+				if err := patchSCIMMembers(userIDs, op, group.Members); err != nil {
+					return nil, err
+				}
+			}
+
+		case path == "displayname":
+			if op == "remove" {
+				return nil, model.NewErrBadRequest("the displayName is required")
+			}
+			if err := unmarshalSCIMValue(operation.Value, &title); err != nil {
+				return nil, err
+			}
+
+		case path == "members":
+			var scimMembers []model.SCIMMember
+			if len(operation.Value) > 0 {
+				if err := unmarshalSCIMValue(operation.Value, &scimMembers); err != nil {
+					return nil, err
+				}
+			}
+			if op == "remove" && len(scimMembers) == 0 {
+				// removes all the members
+				userIDs = map[string]bool{}
+				continue
+			}
+			if err := patchSCIMMembers(userIDs, op, scimMembers); err != nil {
+				return nil, err
+			}
+
+		case strings.HasPrefix(path, "members[") && strings.HasSuffix(path, "]") && op == "remove":
+			filter, err := model.ParseSCIMFilter(operation.Path[len("members[") : len(operation.Path)-1])
+			if err != nil {
+				return nil, err
+			}
+			if filter == nil || filter.Attribute != "value" {
+				return nil, model.NewErrBadRequest(fmt.Sprintf("unsupported path: %s", operation.Path))
+			}
+			delete(userIDs, filter.Value)
+
+		default:
+			return nil, model.NewErrBadRequest(fmt.Sprintf("unsupported path: %s", operation.Path))
+		}
+	}
+
+	ids := make([]string, 0, len(userIDs))
+	for userID := range userIDs {
+		ids = append(ids, userID)
+	}
+	if err := a.checkSCIMMembers(ids); err != nil {
+		return nil, err
+	}
+
+	if err := a.renameSCIMTeam(team, title); err != nil {
+		return nil, err
+	}
+	if err := a.setSCIMTeamMembers(team.ID, ids); err != nil {
+		return nil, err
+	}
+
+	return a.toSCIMGroup(team, true)
+}
+
+// DeleteSCIMGroup deletes the team of a group. Teams with boards can't
+// be deleted, their boards must be moved or deleted first.
+func (a *App) DeleteSCIMGroup(teamID string) error {
+	team, err := a.getSCIMTeam(teamID)
+	if err != nil {
+		return err
+	}
+
+	boards, _, err := a.store.GetBoardsForCompliance(model.QueryBoardsForComplianceOptions{TeamID: team.ID, PerPage: 1})
+	if err != nil {
+		return errors.Wrap(err, "unable to get the boards of the team")
+	}
+	if len(boards) > 0 {
+		return model.NewErrConflict("the team of the group has boards")
+	}
+
+	if err := a.store.DeleteTeam(team.ID); err != nil {
+		return errors.Wrap(err, "unable to delete the team")
+	}
+
+	a.logger.Info("Deleted SCIM group", mlog.String("teamID", team.ID))
+	return nil
+}
+
+func (a *App) getSCIMTeam(teamID string) (*model.Team, error) {
+	if teamID == model.GlobalTeamID {
+		return nil, model.NewErrNotFound("group ID=" + teamID)
+	}
+
+	team, err := a.store.GetTeam(teamID)
+	if model.IsErrNotFound(err) {
+		return nil, model.NewErrNotFound("group ID=" + teamID)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the team")
+	}
+	return team, nil
+}
+
+// checkSCIMTeam checks that the title of a team is set and not used by
+// another team, so the groups can be looked up by their displayName.
+func (a *App) checkSCIMTeam(team *model.Team) error {
+	if team.Title == "" {
+		return model.NewErrBadRequest("the displayName is required")
+	}
+
+	teams, err := a.scimTeams()
+	if err != nil {
+		return err
+	}
+	for _, other := range teams {
+		if other.ID != team.ID && strings.EqualFold(other.Title, team.Title) {
+			return model.NewErrConflict(fmt.Sprintf("the displayName %s is already used", team.Title))
+		}
+	}
+	return nil
+}
+
+func (a *App) renameSCIMTeam(team *model.Team, title string) error {
+	if team.Title == title {
+		return nil
+	}
+
+	team.Title = title
+	if err := a.checkSCIMTeam(team); err != nil {
+		return err
+	}
+	if _, err := a.store.UpdateTeam(team); err != nil {
+		return errors.Wrap(err, "unable to update the team")
+	}
+	return nil
+}
+
+func (a *App) scimMemberIDs(scimMembers []model.SCIMMember) ([]string, error) {
+	userIDs := make([]string, 0, len(scimMembers))
+	for _, member := range scimMembers {
+		userIDs = append(userIDs, member.Value)
+	}
+	if err := a.checkSCIMMembers(userIDs); err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// checkSCIMMembers checks that the members of a group are existing
+// users.
+func (a *App) checkSCIMMembers(userIDs []string) error {
+	for _, userID := range userIDs {
+		if _, err := a.getSCIMUser(userID); model.IsErrNotFound(err) {
+			return model.NewErrBadRequest(fmt.Sprintf("the member %s is not a user", userID))
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchSCIMMembers(userIDs map[string]bool, op string, scimMembers []model.SCIMMember) error {
+	if op == "replace" {
+		for userID := range userIDs {
+			delete(userIDs, userID)
+		}
+	}
+
+	for _, member := range scimMembers {
+		if member.Value == "" {
+			return model.NewErrBadRequest("a member requires a value")
+		}
+		if op == "remove" {
+			delete(userIDs, member.Value)
+		} else {
+			userIDs[member.Value] = true
+		}
+	}
+	return nil
+}
+
+// setSCIMTeamMembers sets the members of a team to the given users.
+func (a *App) setSCIMTeamMembers(teamID string, userIDs []string) error {
+	members, err := a.store.GetTeamMembers(teamID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the team members")
+	}
+
+	existing := map[string]bool{}
+	for _, member := range members {
+		existing[member.UserID] = true
+	}
+
+	wanted := map[string]bool{}
+	for _, userID := range userIDs {
+		wanted[userID] = true
+		if existing[userID] {
+			continue
+		}
+		if _, err := a.store.SaveTeamMember(&model.TeamMember{TeamID: teamID, UserID: userID}); err != nil {
+			return errors.Wrap(err, "unable to add the team member")
+		}
+	}
+
+	for _, member := range members {
+		if wanted[member.UserID] {
+			continue
+		}
+		if err := a.removeTeamMember(teamID, member.UserID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *App) toSCIMGroup(team *model.Team, withMembers bool) (*model.SCIMGroup, error) {
+	group := &model.SCIMGroup{
+		Schemas:     []string{model.SCIMSchemaGroup},
+		ID:          team.ID,
+		DisplayName: team.Title,
+		Meta: &model.SCIMMeta{
+			ResourceType: "Group",
+			LastModified: scimTime(team.UpdateAt),
+			Location:     a.scimLocation("Groups", team.ID),
+		},
+	}
+
+	if !withMembers {
+		return group, nil
+	}
+
+	members, err := a.store.GetTeamMembers(team.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the team members")
+	}
+	for _, member := range members {
+		group.Members = append(group.Members, model.SCIMMember{
+			Value: member.UserID,
+			Ref:   a.scimLocation("Users", member.UserID),
+		})
+	}
+
+	return group, nil
+}
@@ -0,0 +1,339 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSCIMUsers(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.ServerRoot = "http://localhost:8000"
+	teams := []*model.Team{{ID: model.GlobalTeamID}, {ID: "team-id", Title: "Engineering"}}
+
+	t.Run("unsupported filter", func(t *testing.T) {
+		_, err := th.App.GetSCIMUsers(&model.SCIMFilter{Attribute: "title", Value: "boss"}, 1, 10)
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("filter and pagination", func(t *testing.T) {
+		user := &model.User{ID: "user-id", Username: "john", Email: "john@example.com", FirstName: "John", LastName: "Doe"}
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Email: "john@example.com", Offset: 2, Limit: 5}).Return([]*model.User{user}, 3, nil)
+		th.Store.EXPECT().GetAllTeams().Return(teams, nil)
+		th.Store.EXPECT().GetTeamMembersForUser("user-id").Return([]*model.TeamMember{
+			{TeamID: model.GlobalTeamID, UserID: "user-id"},
+			{TeamID: "team-id", UserID: "user-id"},
+		}, nil)
+
+		list, err := th.App.GetSCIMUsers(&model.SCIMFilter{Attribute: "emails.value", Value: "john@example.com"}, 3, 5)
+		require.NoError(t, err)
+		require.Equal(t, 3, list.TotalResults)
+		require.Equal(t, 3, list.StartIndex)
+		require.Equal(t, 1, list.ItemsPerPage)
+
+		users := list.Resources.([]*model.SCIMUser)
+		require.Equal(t, "john", users[0].UserName)
+		require.Equal(t, "John Doe", users[0].DisplayName)
+		require.Equal(t, "john@example.com", users[0].PrimaryEmail())
+		require.True(t, *users[0].Active)
+		require.Equal(t, "http://localhost:8000/scim/v2/Users/user-id", users[0].Meta.Location)
+		require.Equal(t, []model.SCIMMember{{
+			Value:   "team-id",
+			Display: "Engineering",
+			Ref:     "http://localhost:8000/scim/v2/Groups/team-id",
+		}}, users[0].Groups)
+	})
+
+	t.Run("count only", func(t *testing.T) {
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Limit: 1}).Return([]*model.User{{ID: "user-id"}}, 7, nil)
+		th.Store.EXPECT().GetAllTeams().Return(teams, nil)
+
+		list, err := th.App.GetSCIMUsers(nil, 1, 0)
+		require.NoError(t, err)
+		require.Equal(t, 7, list.TotalResults)
+		require.Empty(t, list.Resources)
+	})
+}
+
+func TestCreateSCIMUser(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	scimUser := &model.SCIMUser{
+		UserName: "john",
+		Name:     &model.SCIMName{GivenName: "John", FamilyName: "Doe"},
+		Emails:   []model.SCIMEmail{{Value: "john@example.com", Primary: true}},
+	}
+
+	t.Run("missing userName", func(t *testing.T) {
+		_, err := th.App.CreateSCIMUser(&model.SCIMUser{})
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("userName already used", func(t *testing.T) {
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{{ID: "other-id"}}, 1, nil)
+
+		_, err := th.App.CreateSCIMUser(scimUser)
+		require.True(t, model.IsErrConflict(err))
+	})
+
+	t.Run("email used by a deactivated user", func(t *testing.T) {
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{}, 0, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Email: "john@example.com"}).Return([]*model.User{{ID: "other-id", DeleteAt: 1}}, 1, nil)
+
+		_, err := th.App.CreateSCIMUser(scimUser)
+		require.True(t, model.IsErrConflict(err))
+	})
+
+	t.Run("create the user", func(t *testing.T) {
+		scimUser.Password = "john-password"
+
+		th.Store.EXPECT().QueryUsers(gomock.Any()).Return([]*model.User{}, 0, nil).Times(2)
+		var created *model.User
+		th.Store.EXPECT().CreateUser(gomock.Any()).DoAndReturn(func(user *model.User) (*model.User, error) {
+			created = user
+			return user, nil
+		})
+		th.Store.EXPECT().GetAllTeams().Return([]*model.Team{}, nil)
+		th.Store.EXPECT().GetTeamMembersForUser(gomock.Any()).Return([]*model.TeamMember{}, nil)
+
+		user, err := th.App.CreateSCIMUser(scimUser)
+		require.NoError(t, err)
+		require.Equal(t, created.ID, user.ID)
+		require.Equal(t, "john", created.Username)
+		require.Equal(t, "john@example.com", created.Email)
+		require.True(t, created.EmailVerified)
+		require.Equal(t, "John", created.FirstName)
+		require.Equal(t, "Doe", created.LastName)
+		require.True(t, auth.ComparePassword(created.Password, "john-password"))
+		require.True(t, *user.Active)
+	})
+}
+
+func TestPatchSCIMUser(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	user := &model.User{ID: "user-id", Username: "john", Email: "john@example.com"}
+	patch := &model.SCIMPatchRequest{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "Replace", "path": "name.givenName", "value": "Johnny"},
+			{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "johnny@example.com"},
+			{"op": "add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "R&D"},
+			{"op": "Replace", "path": "active", "value": "False"}
+		]
+	}`), patch))
+
+	th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{UserID: "user-id", Limit: 1}).Return([]*model.User{user}, 1, nil)
+	th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Username: "john"}).Return([]*model.User{user}, 1, nil)
+	th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{Email: "johnny@example.com"}).Return([]*model.User{}, 0, nil)
+	th.Store.EXPECT().UpdateUser(user).Return(user, nil).Times(2)
+	th.Store.EXPECT().GetUserSessions("user-id", gomock.Any()).Return([]*model.Session{{ID: "session-id"}}, nil)
+	th.Store.EXPECT().DeleteUserSessions("user-id", "").Return(nil)
+	th.Store.EXPECT().GetAllTeams().Return([]*model.Team{}, nil)
+	th.Store.EXPECT().GetTeamMembersForUser("user-id").Return([]*model.TeamMember{}, nil)
+
+	scimUser, err := th.App.PatchSCIMUser("user-id", patch)
+	require.NoError(t, err)
+	require.Equal(t, "Johnny", user.FirstName)
+	require.Equal(t, "johnny@example.com", user.Email)
+	require.NotZero(t, user.DeleteAt)
+	require.False(t, *scimUser.Active)
+
+	t.Run("invalid operation", func(t *testing.T) {
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{UserID: "user-id", Limit: 1}).Return([]*model.User{user}, 1, nil)
+
+		_, err := th.App.PatchSCIMUser("user-id", &model.SCIMPatchRequest{
+			Operations: []model.SCIMPatchOperation{{Op: "move", Path: "active"}},
+		})
+		require.True(t, model.IsErrBadRequest(err))
+	})
+}
+
+func TestDeleteSCIMUser(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("unknown user", func(t *testing.T) {
+		th.Store.EXPECT().QueryUsers(gomock.Any()).Return([]*model.User{}, 0, nil)
+
+		err := th.App.DeleteSCIMUser("user-id")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("already deactivated", func(t *testing.T) {
+		th.Store.EXPECT().QueryUsers(gomock.Any()).Return([]*model.User{{ID: "user-id", DeleteAt: 1}}, 1, nil)
+
+		require.NoError(t, th.App.DeleteSCIMUser("user-id"))
+	})
+
+	t.Run("deactivate the user", func(t *testing.T) {
+		user := &model.User{ID: "user-id"}
+		th.Store.EXPECT().QueryUsers(gomock.Any()).Return([]*model.User{user}, 1, nil)
+		th.Store.EXPECT().UpdateUser(user).Return(user, nil)
+		th.Store.EXPECT().GetUserSessions("user-id", gomock.Any()).Return([]*model.Session{}, nil)
+		th.Store.EXPECT().DeleteUserSessions("user-id", "").Return(nil)
+
+		require.NoError(t, th.App.DeleteSCIMUser("user-id"))
+		require.NotZero(t, user.DeleteAt)
+	})
+}
+
+func TestGetSCIMGroups(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	teams := []*model.Team{
+		{ID: model.GlobalTeamID},
+		{ID: "team-2", Title: "Platform"},
+		{ID: "team-1", Title: "Engineering"},
+	}
+
+	t.Run("the root team is not a group", func(t *testing.T) {
+		th.Store.EXPECT().GetAllTeams().Return(teams, nil)
+
+		list, err := th.App.GetSCIMGroups(nil, 2, 10, false)
+		require.NoError(t, err)
+		require.Equal(t, 2, list.TotalResults)
+		groups := list.Resources.([]*model.SCIMGroup)
+		require.Len(t, groups, 1)
+		require.Equal(t, "team-2", groups[0].ID)
+		require.Nil(t, groups[0].Members)
+	})
+
+	t.Run("filter on the displayName", func(t *testing.T) {
+		th.Store.EXPECT().GetAllTeams().Return(teams, nil)
+		th.Store.EXPECT().GetTeamMembers("team-1").Return([]*model.TeamMember{{TeamID: "team-1", UserID: "user-id"}}, nil)
+
+		list, err := th.App.GetSCIMGroups(&model.SCIMFilter{Attribute: "displayname", Value: "engineering"}, 1, 10, true)
+		require.NoError(t, err)
+		require.Equal(t, 1, list.TotalResults)
+		groups := list.Resources.([]*model.SCIMGroup)
+		require.Equal(t, "Engineering", groups[0].DisplayName)
+		require.Equal(t, "user-id", groups[0].Members[0].Value)
+	})
+
+	t.Run("the root team can't be read", func(t *testing.T) {
+		_, err := th.App.GetSCIMGroup(model.GlobalTeamID, true)
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
+
+func TestCreateSCIMGroup(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("displayName already used", func(t *testing.T) {
+		th.Store.EXPECT().GetAllTeams().Return([]*model.Team{{ID: "team-id", Title: "Engineering"}}, nil)
+
+		_, err := th.App.CreateSCIMGroup(&model.SCIMGroup{DisplayName: "ENGINEERING"})
+		require.True(t, model.IsErrConflict(err))
+	})
+
+	t.Run("unknown member", func(t *testing.T) {
+		th.Store.EXPECT().GetAllTeams().Return([]*model.Team{}, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{UserID: "unknown", Limit: 1}).Return([]*model.User{}, 0, nil)
+
+		_, err := th.App.CreateSCIMGroup(&model.SCIMGroup{
+			DisplayName: "Engineering",
+			Members:     []model.SCIMMember{{Value: "unknown"}},
+		})
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("create the team with its members", func(t *testing.T) {
+		th.Store.EXPECT().GetAllTeams().Return([]*model.Team{}, nil)
+		th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{UserID: "user-id", Limit: 1}).Return([]*model.User{{ID: "user-id"}}, 1, nil)
+
+		var teamID string
+		th.Store.EXPECT().CreateTeam(gomock.Any()).DoAndReturn(func(team *model.Team) (*model.Team, error) {
+			require.Equal(t, "Engineering", team.Title)
+			teamID = team.ID
+			return team, nil
+		})
+		th.Store.EXPECT().GetTeamMembers(gomock.Any()).Return([]*model.TeamMember{}, nil)
+		th.Store.EXPECT().SaveTeamMember(gomock.Any()).DoAndReturn(func(member *model.TeamMember) (*model.TeamMember, error) {
+			require.Equal(t, teamID, member.TeamID)
+			require.Equal(t, "user-id", member.UserID)
+			return member, nil
+		})
+		th.Store.EXPECT().GetTeamMembers(gomock.Any()).Return([]*model.TeamMember{{UserID: "user-id"}}, nil)
+
+		group, err := th.App.CreateSCIMGroup(&model.SCIMGroup{
+			DisplayName: "Engineering",
+			Members:     []model.SCIMMember{{Value: "user-id"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, teamID, group.ID)
+		require.Len(t, group.Members, 1)
+	})
+}
+
+func TestPatchSCIMGroup(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	team := &model.Team{ID: "team-id", Title: "Engineering"}
+	patch := &model.SCIMPatchRequest{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "add", "path": "members", "value": [{"value": "user-3"}]},
+			{"op": "remove", "path": "members[value eq \"user-1\"]"},
+			{"op": "Remove", "path": "members", "value": [{"value": "user-2"}]},
+			{"op": "replace", "value": {"id": "team-id", "displayName": "Platform"}}
+		]
+	}`), patch))
+
+	th.Store.EXPECT().GetTeam("team-id").Return(team, nil)
+	th.Store.EXPECT().GetTeamMembers("team-id").Return([]*model.TeamMember{
+		{TeamID: "team-id", UserID: "user-1"},
+		{TeamID: "team-id", UserID: "user-2"},
+	}, nil).Times(2)
+	th.Store.EXPECT().QueryUsers(model.QueryUsersOptions{UserID: "user-3", Limit: 1}).Return([]*model.User{{ID: "user-3"}}, 1, nil)
+	th.Store.EXPECT().GetAllTeams().Return([]*model.Team{team}, nil)
+	th.Store.EXPECT().UpdateTeam(team).Return(team, nil)
+	th.Store.EXPECT().SaveTeamMember(&model.TeamMember{TeamID: "team-id", UserID: "user-3"}).Return(nil, nil)
+	th.Store.EXPECT().GetBoardsForUserAndTeam("user-1", "team-id", false).Return([]*model.Board{}, nil)
+	th.Store.EXPECT().GetBoardsForUserAndTeam("user-2", "team-id", false).Return([]*model.Board{}, nil)
+	th.Store.EXPECT().DeleteTeamMember("team-id", "user-1").Return(nil)
+	th.Store.EXPECT().DeleteTeamMember("team-id", "user-2").Return(nil)
+	th.Store.EXPECT().GetTeamMembers("team-id").Return([]*model.TeamMember{{TeamID: "team-id", UserID: "user-3"}}, nil)
+
+	group, err := th.App.PatchSCIMGroup("team-id", patch)
+	require.NoError(t, err)
+	require.Equal(t, "Platform", group.DisplayName)
+	require.Equal(t, []model.SCIMMember{{Value: "user-3", Ref: "/scim/v2/Users/user-3"}}, group.Members)
+}
+
+func TestDeleteSCIMGroup(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	team := &model.Team{ID: "team-id", Title: "Engineering"}
+	opts := model.QueryBoardsForComplianceOptions{TeamID: "team-id", PerPage: 1}
+
+	t.Run("team with boards", func(t *testing.T) {
+		th.Store.EXPECT().GetTeam("team-id").Return(team, nil)
+		th.Store.EXPECT().GetBoardsForCompliance(opts).Return([]*model.Board{{ID: "board-id"}}, false, nil)
+
+		err := th.App.DeleteSCIMGroup("team-id")
+		require.True(t, model.IsErrConflict(err))
+	})
+
+	t.Run("delete the team", func(t *testing.T) {
+		th.Store.EXPECT().GetTeam("team-id").Return(team, nil)
+		th.Store.EXPECT().GetBoardsForCompliance(opts).Return([]*model.Board{}, false, nil)
+		th.Store.EXPECT().DeleteTeam("team-id").Return(nil)
+
+		require.NoError(t, th.App.DeleteSCIMGroup("team-id"))
+	})
+}
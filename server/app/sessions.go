@@ -0,0 +1,94 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/pkg/errors"
+)
+
+// maxSessionUserAgentLength is the length of the user agent column of the
+// sessions.
+const maxSessionUserAgentLength = 512
+
+// GetUserSessions returns the active sessions of a user, without their
+// tokens.
+func (a *App) GetUserSessions(userID string) ([]*model.Session, error) {
+	sessions, err := a.store.GetUserSessions(userID, a.config.SessionExpireTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the sessions")
+	}
+
+	for _, session := range sessions {
+		session.Sanitize()
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of a user and closes its websocket
+// connections.
+func (a *App) RevokeSession(userID, sessionID string) error {
+	sessions, err := a.store.GetUserSessions(userID, a.config.SessionExpireTime)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the sessions")
+	}
+
+	found := false
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return model.NewErrNotFound("session ID=" + sessionID)
+	}
+
+	if err := a.store.DeleteSession(sessionID); err != nil {
+		return errors.Wrap(err, "unable to delete the session")
+	}
+	a.wsAdapter.CloseSessions(sessionID)
+
+	return nil
+}
+
+// RevokeAllUserSessions revokes all the sessions of a user, logging them
+// out of all their devices.
+func (a *App) RevokeAllUserSessions(username string) error {
+	user, err := a.store.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	return a.revokeUserSessions(user.ID, "")
+}
+
+// revokeUserSessions revokes all the sessions of a user but the excepted
+// one, if any, and closes their websocket connections.
+func (a *App) revokeUserSessions(userID, exceptSessionID string) error {
+	sessions, err := a.store.GetUserSessions(userID, a.config.SessionExpireTime)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the sessions")
+	}
+
+	if err := a.store.DeleteUserSessions(userID, exceptSessionID); err != nil {
+		return errors.Wrap(err, "unable to delete the sessions")
+	}
+
+	sessionIDs := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		if session.ID != exceptSessionID {
+			sessionIDs = append(sessionIDs, session.ID)
+		}
+	}
+	a.wsAdapter.CloseSessions(sessionIDs...)
+
+	return nil
+}
+
+// truncateUserAgent truncates a user agent to fit the sessions column.
+func truncateUserAgent(userAgent string) string {
+	runes := []rune(userAgent)
+	if len(runes) <= maxSessionUserAgentLength {
+		return userAgent
+	}
+	return string(runes[:maxSessionUserAgentLength])
+}
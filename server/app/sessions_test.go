@@ -0,0 +1,94 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserSessions(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.Store.EXPECT().GetUserSessions("user-id", th.App.config.SessionExpireTime).Return([]*model.Session{
+		{ID: "session-id", Token: "token", UserID: "user-id", UserAgent: "Mozilla/5.0", Props: map[string]interface{}{"key": "value"}},
+	}, nil)
+
+	sessions, err := th.App.GetUserSessions("user-id")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "session-id", sessions[0].ID)
+	require.Equal(t, "Mozilla/5.0", sessions[0].UserAgent)
+	require.Empty(t, sessions[0].Token)
+	require.Nil(t, sessions[0].Props)
+}
+
+func TestRevokeSession(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	sessions := []*model.Session{{ID: "session-id", UserID: "user-id"}}
+
+	t.Run("session of another user", func(t *testing.T) {
+		th.Store.EXPECT().GetUserSessions("user-id", gomock.Any()).Return(sessions, nil)
+
+		err := th.App.RevokeSession("user-id", "other-session-id")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("session of the user", func(t *testing.T) {
+		th.Store.EXPECT().GetUserSessions("user-id", gomock.Any()).Return(sessions, nil)
+		th.Store.EXPECT().DeleteSession("session-id").Return(nil)
+
+		require.NoError(t, th.App.RevokeSession("user-id", "session-id"))
+	})
+}
+
+func TestRevokeAllUserSessions(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("unknown user", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("unknown").Return(nil, model.NewErrNotFound("user"))
+
+		err := th.App.RevokeAllUserSessions("unknown")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("revoke the sessions", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername("john").Return(&model.User{ID: "user-id", Username: "john"}, nil)
+		th.Store.EXPECT().GetUserSessions("user-id", gomock.Any()).Return([]*model.Session{{ID: "session-id"}}, nil)
+		th.Store.EXPECT().DeleteUserSessions("user-id", "").Return(nil)
+
+		require.NoError(t, th.App.RevokeAllUserSessions("john"))
+	})
+}
+
+func TestLoginRecordsClient(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	user := &model.User{
+		ID:       "user-id",
+		Username: "john",
+		Password: auth.HashPassword("john-password"),
+	}
+	th.Store.EXPECT().GetUserByUsername("john").Return(user, nil)
+
+	var created *model.Session
+	th.Store.EXPECT().CreateSession(gomock.Any()).DoAndReturn(func(session *model.Session) error {
+		created = session
+		return nil
+	})
+
+	userAgent := strings.Repeat("a", maxSessionUserAgentLength+10)
+	token, err := th.App.Login("john", "", "john-password", "", "10.0.0.1", userAgent)
+	require.NoError(t, err)
+	require.Equal(t, created.Token, token)
+	require.Equal(t, "10.0.0.1", created.IPAddress)
+	require.Equal(t, userAgent[:maxSessionUserAgentLength], created.UserAgent)
+}
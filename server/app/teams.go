@@ -1,10 +1,28 @@
 package app
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
 	"github.com/mattermost/focalboard/server/utils"
 
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	teamInviteSubject = "You have been invited to %s"
+	teamInviteBody    = `Hello,
+
+%s has invited you to join the team %s. To accept the invitation, open the following link within %s:
+
+%s
+
+The link can only be used once. If you don't have an account yet, you can create one with this email address.
+`
 )
 
 func (a *App) GetRootTeam() (*model.Team, error) {
@@ -63,3 +81,240 @@ func (a *App) UpsertTeamSignupToken(team model.Team) error {
 func (a *App) GetTeamCount() (int64, error) {
 	return a.store.GetTeamCount()
 }
+
+// CreateTeam creates a new team, with the user who created it as its
+// admin.
+func (a *App) CreateTeam(title, userID string) (*model.Team, error) {
+	team, err := a.store.CreateTeam(&model.Team{
+		ID:         utils.NewID(utils.IDTypeTeam),
+		Title:      strings.TrimSpace(title),
+		ModifiedBy: userID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the team")
+	}
+
+	member := &model.TeamMember{
+		TeamID: team.ID,
+		UserID: userID,
+		Roles:  model.TeamRoleAdmin,
+	}
+	if _, err := a.store.SaveTeamMember(member); err != nil {
+		return nil, errors.Wrap(err, "unable to add the team admin")
+	}
+
+	return team, nil
+}
+
+func (a *App) GetTeamMembers(teamID string) ([]*model.TeamMember, error) {
+	return a.store.GetTeamMembers(teamID)
+}
+
+// InviteToTeam emails an invite to join a team with the given role to
+// each address. The invites expire, and can only be used once. Guests can
+// also be invited to the root team, since it's where standalone servers
+// keep their boards.
+func (a *App) InviteToTeam(teamID, inviterID string, emails []string, role string) error {
+	if a.mail == nil {
+		return model.NewErrNotImplemented("sending emails is not configured")
+	}
+	if teamID == model.GlobalTeamID && role != model.TeamRoleGuest {
+		return model.NewErrBadRequest("all the users belong to the root team")
+	}
+
+	team, err := a.store.GetTeam(teamID)
+	if err != nil {
+		return err
+	}
+
+	inviter, err := a.store.GetUserByID(inviterID)
+	if err != nil {
+		return err
+	}
+
+	roles := ""
+	if role == model.TeamRoleAdmin || role == model.TeamRoleGuest {
+		roles = role
+	}
+
+	for _, email := range emails {
+		email = strings.TrimSpace(email)
+
+		token := utils.NewID(utils.IDTypeToken)
+		now := utils.GetMillis()
+		err := a.store.SaveTeamInvite(&model.TeamInvite{
+			TokenHash: auth.HashToken(token),
+			TeamID:    teamID,
+			Email:     email,
+			Roles:     roles,
+			InvitedBy: inviterID,
+			CreateAt:  now,
+			ExpireAt:  now + model.TeamInviteExpiry.Milliseconds(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "unable to save the team invite")
+		}
+
+		subject := fmt.Sprintf(teamInviteSubject, team.Title)
+		body := fmt.Sprintf(teamInviteBody, inviter.Username, team.Title, formatExpiry(model.TeamInviteExpiry), a.userTokenLink("invite", token))
+		if err := a.mail.Send(email, subject, body); err != nil {
+			a.logger.Error("Unable to send the team invite", mlog.String("teamID", teamID), mlog.Err(err))
+		}
+	}
+
+	return nil
+}
+
+// AcceptTeamInvite adds a user to the team of an invite sent to their
+// email address, and returns the team.
+func (a *App) AcceptTeamInvite(userID, token string) (*model.Team, error) {
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := a.getTeamInvite(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(user.Email, invite.Email) {
+		return nil, model.NewErrBadRequest("the invite was sent to another email address")
+	}
+
+	if err := a.useTeamInvite(userID, token); err != nil {
+		return nil, err
+	}
+
+	return a.store.GetTeam(invite.TeamID)
+}
+
+// RegisterInvitedUser creates a new user with the email address an invite
+// was sent to, and adds them to the team of the invite. The invite proves
+// the user owns the address. Users invited as guests are created as
+// guests.
+func (a *App) RegisterInvitedUser(username, email, password, token string) error {
+	invite, err := a.getTeamInvite(token)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(email, invite.Email) {
+		return model.NewErrBadRequest("the invite was sent to another email address")
+	}
+
+	user, err := a.registerUser(username, email, password, true, invite.Roles == model.TeamRoleGuest)
+	if err != nil {
+		return model.NewErrBadRequest(err.Error())
+	}
+
+	return a.useTeamInvite(user.ID, token)
+}
+
+func (a *App) getTeamInvite(token string) (*model.TeamInvite, error) {
+	invite, err := a.store.GetTeamInvite(auth.HashToken(token))
+	if model.IsErrNotFound(err) {
+		return nil, model.NewErrBadRequest("invalid or expired invite")
+	}
+	return invite, err
+}
+
+// useTeamInvite consumes an invite and adds the user to its team. Users
+// who are already members keep their roles, and guests join as members.
+func (a *App) useTeamInvite(userID, token string) error {
+	invite, err := a.store.UseTeamInvite(auth.HashToken(token))
+	if model.IsErrNotFound(err) {
+		return model.NewErrBadRequest("invalid or expired invite")
+	}
+	if err != nil {
+		return err
+	}
+
+	// all the users belong to the root team
+	if invite.TeamID == model.GlobalTeamID {
+		return nil
+	}
+
+	roles := invite.Roles
+	if roles == model.TeamRoleGuest {
+		roles = ""
+	}
+
+	member, err := a.store.GetTeamMember(invite.TeamID, userID)
+	if err != nil && !model.IsErrNotFound(err) {
+		return err
+	}
+	if member != nil && (member.IsAdmin() || roles == "") {
+		return nil
+	}
+
+	_, err = a.store.SaveTeamMember(&model.TeamMember{
+		TeamID: invite.TeamID,
+		UserID: userID,
+		Roles:  roles,
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to add the team member")
+	}
+
+	return nil
+}
+
+// RemoveTeamMember removes a user from a team, along with their
+// memberships of the boards of the team. The last admin of a team can't
+// be removed.
+func (a *App) RemoveTeamMember(teamID, userID string) error {
+	if teamID == model.GlobalTeamID {
+		return model.NewErrBadRequest("users can't be removed from the root team")
+	}
+
+	members, err := a.store.GetTeamMembers(teamID)
+	if err != nil {
+		return err
+	}
+
+	var removed *model.TeamMember
+	admins := 0
+	for _, member := range members {
+		if member.UserID == userID {
+			removed = member
+		}
+		if member.IsAdmin() {
+			admins++
+		}
+	}
+
+	if removed == nil {
+		return model.NewErrNotFound("team member")
+	}
+	if removed.IsAdmin() && admins == 1 {
+		return model.NewErrBadRequest("the last admin of a team can't be removed")
+	}
+
+	return a.removeTeamMember(teamID, userID)
+}
+
+func (a *App) removeTeamMember(teamID, userID string) error {
+	boards, err := a.store.GetBoardsForUserAndTeam(userID, teamID, false)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the boards of the team member")
+	}
+
+	for _, board := range boards {
+		if err := a.store.DeleteMember(board.ID, userID); err != nil {
+			return errors.Wrap(err, "unable to remove the board member")
+		}
+	}
+
+	if err := a.store.DeleteTeamMember(teamID, userID); err != nil {
+		return errors.Wrap(err, "unable to remove the team member")
+	}
+
+	// the user's connections stop getting the team and boards changes
+	for _, board := range boards {
+		a.wsAdapter.BroadcastMemberDelete(teamID, board.ID, userID)
+	}
+	a.wsAdapter.RevokeTeamAccess(teamID, userID)
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -152,3 +153,199 @@ func TestTeamOperations(t *testing.T) {
 	assert.NoError(t, errGetTeamCount)
 	assert.Equal(t, int64(10), count)
 }
+
+func TestCreateTeam(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.Store.EXPECT().CreateTeam(gomock.Any()).DoAndReturn(func(team *model.Team) (*model.Team, error) {
+		require.NotEmpty(t, team.ID)
+		require.Equal(t, "Engineering", team.Title)
+		require.Equal(t, "user-id", team.ModifiedBy)
+		return team, nil
+	})
+	th.Store.EXPECT().SaveTeamMember(gomock.Any()).DoAndReturn(func(member *model.TeamMember) (*model.TeamMember, error) {
+		require.Equal(t, "user-id", member.UserID)
+		require.True(t, member.IsAdmin())
+		return member, nil
+	})
+
+	team, err := th.App.CreateTeam(" Engineering ", "user-id")
+	require.NoError(t, err)
+	require.Equal(t, "Engineering", team.Title)
+}
+
+func TestInviteToTeam(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("email not configured", func(t *testing.T) {
+		err := th.App.InviteToTeam("team-id", "user-id", []string{"jane@example.com"}, "")
+		require.True(t, model.IsErrNotImplemented(err))
+	})
+
+	server := setupMail(t, th)
+
+	t.Run("root team", func(t *testing.T) {
+		err := th.App.InviteToTeam(model.GlobalTeamID, "user-id", []string{"jane@example.com"}, "")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("send invites", func(t *testing.T) {
+		th.Store.EXPECT().GetTeam("team-id").Return(&model.Team{ID: "team-id", Title: "Engineering"}, nil)
+		th.Store.EXPECT().GetUserByID("user-id").Return(&model.User{ID: "user-id", Username: "john"}, nil)
+
+		saved := map[string]*model.TeamInvite{}
+		th.Store.EXPECT().SaveTeamInvite(gomock.Any()).DoAndReturn(func(invite *model.TeamInvite) error {
+			saved[invite.Email] = invite
+			return nil
+		}).Times(2)
+
+		err := th.App.InviteToTeam("team-id", "user-id", []string{"jane@example.com", "bob@example.com"}, model.TeamRoleAdmin)
+		require.NoError(t, err)
+
+		for _, email := range []string{"jane@example.com", "bob@example.com"} {
+			message := server.LastMessageTo(email)
+			require.NotNil(t, message)
+			require.Equal(t, "You have been invited to Engineering", message.Subject)
+			require.Contains(t, message.Body, "john has invited you")
+			require.Contains(t, message.Body, "within 168 hours")
+
+			token := tokenFromMessage(t, server, email)
+			invite := saved[email]
+			require.NotNil(t, invite)
+			require.Equal(t, auth.HashToken(token), invite.TokenHash)
+			require.Equal(t, "team-id", invite.TeamID)
+			require.Equal(t, model.TeamRoleAdmin, invite.Roles)
+			require.Equal(t, "user-id", invite.InvitedBy)
+			require.Equal(t, model.TeamInviteExpiry.Milliseconds(), invite.ExpireAt-invite.CreateAt)
+		}
+	})
+}
+
+func TestAcceptTeamInvite(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	user := &model.User{ID: "user-id", Email: "jane@example.com"}
+	invite := &model.TeamInvite{TeamID: "team-id", Email: "Jane@example.com", Roles: model.TeamRoleAdmin}
+
+	t.Run("invalid invite", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+		th.Store.EXPECT().GetTeamInvite(auth.HashToken("token")).Return(nil, model.NewErrNotFound("team invite"))
+
+		_, err := th.App.AcceptTeamInvite(user.ID, "token")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("invite sent to another address", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+		th.Store.EXPECT().GetTeamInvite(auth.HashToken("token")).Return(&model.TeamInvite{TeamID: "team-id", Email: "bob@example.com"}, nil)
+
+		_, err := th.App.AcceptTeamInvite(user.ID, "token")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("join the team", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+		th.Store.EXPECT().GetTeamInvite(auth.HashToken("token")).Return(invite, nil)
+		th.Store.EXPECT().UseTeamInvite(auth.HashToken("token")).Return(invite, nil)
+		th.Store.EXPECT().GetTeamMember("team-id", user.ID).Return(nil, model.NewErrNotFound("team member"))
+		th.Store.EXPECT().SaveTeamMember(&model.TeamMember{TeamID: "team-id", UserID: user.ID, Roles: model.TeamRoleAdmin}).Return(nil, nil)
+		th.Store.EXPECT().GetTeam("team-id").Return(&model.Team{ID: "team-id"}, nil)
+
+		team, err := th.App.AcceptTeamInvite(user.ID, "token")
+		require.NoError(t, err)
+		require.Equal(t, "team-id", team.ID)
+	})
+
+	t.Run("admins keep their role", func(t *testing.T) {
+		memberInvite := &model.TeamInvite{TeamID: "team-id", Email: user.Email}
+		th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+		th.Store.EXPECT().GetTeamInvite(auth.HashToken("token")).Return(memberInvite, nil)
+		th.Store.EXPECT().UseTeamInvite(auth.HashToken("token")).Return(memberInvite, nil)
+		th.Store.EXPECT().GetTeamMember("team-id", user.ID).Return(&model.TeamMember{TeamID: "team-id", UserID: user.ID, Roles: model.TeamRoleAdmin}, nil)
+		th.Store.EXPECT().GetTeam("team-id").Return(&model.Team{ID: "team-id"}, nil)
+
+		_, err := th.App.AcceptTeamInvite(user.ID, "token")
+		require.NoError(t, err)
+	})
+}
+
+func TestRegisterInvitedUser(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.RequireEmailVerification = true
+	invite := &model.TeamInvite{TeamID: "team-id", Email: "jane@example.com"}
+
+	t.Run("invite sent to another address", func(t *testing.T) {
+		th.Store.EXPECT().GetTeamInvite(auth.HashToken("token")).Return(invite, nil)
+
+		err := th.App.RegisterInvitedUser("bob", "bob@example.com", "bob-password", "token")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("register and join the team", func(t *testing.T) {
+		th.Store.EXPECT().GetTeamInvite(auth.HashToken("token")).Return(invite, nil)
+		th.Store.EXPECT().GetUserByUsername("jane").Return(nil, model.NewErrNotFound("user"))
+		th.Store.EXPECT().GetUserByEmail("jane@example.com").Return(nil, model.NewErrNotFound("user"))
+
+		var created *model.User
+		th.Store.EXPECT().CreateUser(gomock.Any()).DoAndReturn(func(user *model.User) (*model.User, error) {
+			created = user
+			return user, nil
+		})
+		th.Store.EXPECT().UseTeamInvite(auth.HashToken("token")).Return(invite, nil)
+		th.Store.EXPECT().GetTeamMember("team-id", gomock.Any()).Return(nil, model.NewErrNotFound("team member"))
+		th.Store.EXPECT().SaveTeamMember(gomock.Any()).DoAndReturn(func(member *model.TeamMember) (*model.TeamMember, error) {
+			require.Equal(t, created.ID, member.UserID)
+			require.False(t, member.IsAdmin())
+			return member, nil
+		})
+
+		require.NoError(t, th.App.RegisterInvitedUser("jane", "jane@example.com", "jane-password", "token"))
+		// the invite proves the user owns the address
+		require.True(t, created.EmailVerified)
+	})
+}
+
+func TestRemoveTeamMember(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	members := []*model.TeamMember{
+		{TeamID: "team-id", UserID: "admin-id", Roles: model.TeamRoleAdmin},
+		{TeamID: "team-id", UserID: "user-id"},
+	}
+
+	t.Run("root team", func(t *testing.T) {
+		err := th.App.RemoveTeamMember(model.GlobalTeamID, "user-id")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		th.Store.EXPECT().GetTeamMembers("team-id").Return(members, nil)
+
+		err := th.App.RemoveTeamMember("team-id", "other-id")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("last admin", func(t *testing.T) {
+		th.Store.EXPECT().GetTeamMembers("team-id").Return(members, nil)
+
+		err := th.App.RemoveTeamMember("team-id", "admin-id")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("remove a member and their board memberships", func(t *testing.T) {
+		th.Store.EXPECT().GetTeamMembers("team-id").Return(members, nil)
+		th.Store.EXPECT().GetBoardsForUserAndTeam("user-id", "team-id", false).Return([]*model.Board{{ID: "board-id"}}, nil)
+		th.Store.EXPECT().DeleteMember("board-id", "user-id").Return(nil)
+		th.Store.EXPECT().DeleteTeamMember("team-id", "user-id").Return(nil)
+		// the websocket server notifies the members of the board
+		th.Store.EXPECT().GetMembersForBoard("board-id").Return([]*model.BoardMember{}, nil)
+
+		require.NoError(t, th.App.RemoveTeamMember("team-id", "user-id"))
+	})
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// DeactivateUser deactivates a user and hands their boards over to
+// another user, so that no board is left without an admin. The boards
+// are transferred and the user's person property values are reassigned
+// or cleared in a single transaction before the user is deactivated.
+// Then the user's sessions are revoked and their subscriptions removed.
+func (a *App) DeactivateUser(userID string, request *model.DeactivateUserRequest) (*model.DeactivateUserReport, error) {
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	newAdmin, err := a.store.GetUserByID(request.TransferTo)
+	if model.IsErrNotFound(err) {
+		return nil, model.NewErrBadRequest("the user to transfer the boards to doesn't exist or is deactivated")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if newAdmin.IsGuest {
+		return nil, model.NewErrBadRequest("the boards can't be transferred to a guest")
+	}
+
+	report := &model.DeactivateUserReport{
+		UserID:            user.ID,
+		TransferTo:        newAdmin.ID,
+		TransferredBoards: []string{},
+		UpdatedCards:      []string{},
+	}
+
+	members, err := a.store.GetMembersForUser(user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the board memberships")
+	}
+
+	var changedMembers, addedMembers []*model.BoardMember
+	for _, member := range members {
+		// the admins include the members admin through their custom role
+		// or the minimum role of the board
+		if !a.permissions.HasPermissionToBoard(user.ID, member.BoardID, model.PermissionManageBoardRoles) {
+			continue
+		}
+		changed, added, err2 := a.boardAdminTransfer(member, newAdmin.ID)
+		if err2 != nil {
+			return nil, errors.Wrapf(err2, "unable to transfer the board %s", member.BoardID)
+		}
+		changedMembers = append(changedMembers, changed...)
+		addedMembers = append(addedMembers, added...)
+		report.TransferredBoards = append(report.TransferredBoards, member.BoardID)
+	}
+
+	reassignTo := ""
+	if request.ReassignPersonProperties {
+		reassignTo = newAdmin.ID
+	}
+	patches := &model.BlockPatchBatch{}
+	for _, member := range members {
+		if err = a.addPersonPropertyPatches(patches, member.BoardID, user.ID, reassignTo); err != nil {
+			return nil, errors.Wrapf(err, "unable to update the cards of the board %s", member.BoardID)
+		}
+	}
+	report.UpdatedCards = append(report.UpdatedCards, patches.BlockIDs...)
+
+	// the values are changed on behalf of the system, as the admin
+	// deactivating the user may not be able to edit them
+	if err = a.store.SaveMembersAndPatchBlocks(changedMembers, patches, model.SystemUserID); err != nil {
+		return nil, errors.Wrap(err, "unable to transfer the boards")
+	}
+	if err = a.addBoardsOfMembersToDefaultCategory(addedMembers); err != nil {
+		return nil, err
+	}
+	a.notifyBoardTransfer(changedMembers, patches)
+
+	sessions, err := a.store.GetUserSessions(user.ID, a.config.SessionExpireTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the sessions")
+	}
+	user.DeleteAt = utils.GetMillis()
+	if _, err = a.store.UpdateUser(user); err != nil {
+		return nil, errors.Wrap(err, "unable to update the user")
+	}
+	if err = a.revokeUserSessions(user.ID, ""); err != nil {
+		return nil, err
+	}
+	report.RevokedSessions = len(sessions)
+
+	subscriptions, err := a.store.GetSubscriptions(user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the subscriptions")
+	}
+	for _, sub := range subscriptions {
+		if _, err = a.DeleteSubscription(sub.BlockID, user.ID); err != nil {
+			return nil, errors.Wrap(err, "unable to delete the subscription")
+		}
+		report.RemovedSubscriptions++
+	}
+
+	a.logger.Info("User deactivated",
+		mlog.String("userID", user.ID),
+		mlog.String("transferTo", newAdmin.ID),
+		mlog.Int("transferredBoards", len(report.TransferredBoards)),
+		mlog.Int("updatedCards", len(report.UpdatedCards)),
+	)
+	return report, nil
+}
+
+// boardAdminTransfer returns the memberships to save to make a user admin
+// of a board in place of the member, who is kept as an editor, and the
+// memberships among them that are new.
+func (a *App) boardAdminTransfer(member *model.BoardMember, newAdminID string) ([]*model.BoardMember, []*model.BoardMember, error) {
+	var changed, added []*model.BoardMember
+
+	newAdmin, err := a.store.GetMemberForBoard(member.BoardID, newAdminID)
+	if err != nil && !model.IsErrNotFound(err) {
+		return nil, nil, err
+	}
+
+	if newAdmin == nil || newAdmin.Synthetic {
+		newAdmin = &model.BoardMember{
+			BoardID:      member.BoardID,
+			UserID:       newAdminID,
+			SchemeAdmin:  true,
+			SchemeEditor: true,
+		}
+		changed = append(changed, newAdmin)
+		added = append(added, newAdmin)
+	} else if !newAdmin.SchemeAdmin {
+		newAdmin.SchemeAdmin = true
+		newAdmin.SchemeEditor = true
+		changed = append(changed, newAdmin)
+	}
+
+	if member.SchemeAdmin {
+		member.SchemeAdmin = false
+		member.SchemeEditor = true
+		changed = append(changed, member)
+	}
+	return changed, added, nil
+}
+
+// addBoardsOfMembersToDefaultCategory adds the boards the members have
+// joined to their default category.
+func (a *App) addBoardsOfMembersToDefaultCategory(members []*model.BoardMember) error {
+	for _, member := range members {
+		board, err := a.store.GetBoard(member.BoardID)
+		if err != nil {
+			return err
+		}
+		if board.IsTemplate {
+			continue
+		}
+		if err = a.addBoardsToDefaultCategory(member.UserID, board.TeamID, []*model.Board{board}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyBoardTransfer broadcasts the memberships and the blocks changed
+// by a board transfer.
+func (a *App) notifyBoardTransfer(members []*model.BoardMember, patches *model.BlockPatchBatch) {
+	a.blockChangeNotifier.Enqueue(func() error {
+		for _, member := range members {
+			board, err := a.store.GetBoard(member.BoardID)
+			if err != nil {
+				return err
+			}
+			a.wsAdapter.BroadcastMemberChange(board.TeamID, member.BoardID, member)
+		}
+
+		a.metrics.IncrementBlocksPatched(len(patches.BlockIDs))
+		for _, blockID := range patches.BlockIDs {
+			block, err := a.store.GetBlock(blockID)
+			if err != nil {
+				return err
+			}
+			board, err := a.store.GetBoard(block.BoardID)
+			if err != nil {
+				return err
+			}
+			a.wsAdapter.BroadcastBlockChange(board.TeamID, block)
+			a.webhook.NotifyUpdate(block)
+		}
+		return nil
+	})
+}
+
+// addPersonPropertyPatches adds to the patches the replacement of a user
+// by another one in the person property values of the cards of a board,
+// or their removal if newUserID is empty.
+func (a *App) addPersonPropertyPatches(patches *model.BlockPatchBatch, boardID, oldUserID, newUserID string) error {
+	board, err := a.store.GetBoard(boardID)
+	if model.IsErrNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	schema, err := model.ParsePropertySchema(board)
+	if err != nil {
+		return err
+	}
+	hasPersonProperties := false
+	for _, prop := range schema {
+		if prop.Type == "person" || prop.Type == "multiPerson" {
+			hasPersonProperties = true
+			break
+		}
+	}
+	if !hasPersonProperties {
+		return nil
+	}
+
+	cards, err := a.store.GetBlocks(model.QueryBlocksOptions{BoardID: boardID, BlockType: model.TypeCard})
+	if err != nil {
+		return err
+	}
+
+	for _, card := range cards {
+		properties := model.ReplacePersonPropertyValues(schema, model.GetBlockProperties(card), oldUserID, newUserID)
+		if properties == nil {
+			continue
+		}
+		patches.BlockIDs = append(patches.BlockIDs, card.ID)
+		patches.BlockPatches = append(patches.BlockPatches, model.BlockPatch{
+			UpdatedFields: map[string]interface{}{"properties": properties},
+		})
+	}
+	return nil
+}
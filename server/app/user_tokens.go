@@ -0,0 +1,234 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	passwordResetSubject = "Reset your password"
+	passwordResetBody    = `Hello %s,
+
+A password reset was requested for your account. To choose a new password, open the following link within %s:
+
+%s
+
+If you did not request it, you can ignore this email and your password will not change.
+`
+
+	emailVerificationSubject = "Verify your email address"
+	emailVerificationBody    = `Hello %s,
+
+To verify your email address and complete your registration, open the following link within %s:
+
+%s
+`
+)
+
+var ErrEmailNotVerified = errors.New("email address not verified")
+
+func isNativeUser(user *model.User) bool {
+	return user.AuthService == "" || user.AuthService == model.AuthModeNative
+}
+
+// SendPasswordReset emails a password reset link to the native user with
+// the given email address. Unknown addresses are ignored without error,
+// so the response doesn't tell which accounts exist.
+func (a *App) SendPasswordReset(email string) error {
+	if a.mail == nil {
+		return model.NewErrNotImplemented("sending emails is not configured")
+	}
+
+	user, err := a.store.GetUserByEmail(email)
+	if model.IsErrNotFound(err) {
+		a.logger.Debug("Password reset requested for an unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !isNativeUser(user) {
+		a.logger.Debug("Password reset requested for an external user", mlog.String("userID", user.ID))
+		return nil
+	}
+
+	token, err := a.newUserToken(user, model.UserTokenTypePasswordReset, model.PasswordResetTokenExpiry)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(passwordResetBody, user.Username, formatExpiry(model.PasswordResetTokenExpiry), a.userTokenLink("reset_password", token))
+	if err := a.mail.Send(user.Email, passwordResetSubject, body); err != nil {
+		a.logger.Error("Unable to send the password reset email", mlog.String("userID", user.ID), mlog.Err(err))
+	}
+
+	return nil
+}
+
+// ResetPassword sets the password of the user a reset token was sent to.
+// The token can only be used once, and it proves the user owns their
+// email address. All the sessions of the user are revoked.
+func (a *App) ResetPassword(token, newPassword string) error {
+	if err := auth.IsPasswordValid(newPassword, a.passwordSettings()); err != nil {
+		return model.NewErrBadRequest(err.Error())
+	}
+
+	user, err := a.useUserToken(token, model.UserTokenTypePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	if !isNativeUser(user) {
+		return model.NewErrBadRequest("the password of the user is managed by " + user.AuthService)
+	}
+
+	if err := a.setUserPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	if !user.EmailVerified {
+		user.EmailVerified = true
+		if _, err := a.store.UpdateUser(user); err != nil {
+			return errors.Wrap(err, "unable to verify the email address")
+		}
+	}
+
+	// whoever knew the previous password is logged out
+	return a.revokeUserSessions(user.ID, "")
+}
+
+// SendEmailVerification emails a new verification link to the user with
+// the given email address, if it isn't verified yet. Like password
+// resets, unknown addresses are ignored without error.
+func (a *App) SendEmailVerification(email string) error {
+	if a.mail == nil {
+		return model.NewErrNotImplemented("sending emails is not configured")
+	}
+
+	user, err := a.store.GetUserByEmail(email)
+	if model.IsErrNotFound(err) {
+		a.logger.Debug("Email verification requested for an unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified || !isNativeUser(user) {
+		return nil
+	}
+
+	if err := a.sendEmailVerification(user); err != nil {
+		a.logger.Error("Unable to send the email verification", mlog.String("userID", user.ID), mlog.Err(err))
+	}
+
+	return nil
+}
+
+func (a *App) sendEmailVerification(user *model.User) error {
+	if a.mail == nil {
+		return model.NewErrNotImplemented("sending emails is not configured")
+	}
+
+	token, err := a.newUserToken(user, model.UserTokenTypeEmailVerification, model.EmailVerificationTokenExpiry)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(emailVerificationBody, user.Username, formatExpiry(model.EmailVerificationTokenExpiry), a.userTokenLink("verify_email", token))
+	return a.mail.Send(user.Email, emailVerificationSubject, body)
+}
+
+// VerifyEmail marks the email address a verification token was sent to
+// as verified.
+func (a *App) VerifyEmail(token string) error {
+	user, err := a.useUserToken(token, model.UserTokenTypeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified {
+		return nil
+	}
+
+	user.EmailVerified = true
+	if _, err := a.store.UpdateUser(user); err != nil {
+		return errors.Wrap(err, "unable to verify the email address")
+	}
+
+	return nil
+}
+
+// newUserToken creates a token of the given type for a user, replacing
+// the ones sent before, and returns it. Only its hash is stored.
+func (a *App) newUserToken(user *model.User, tokenType string, expiry time.Duration) (string, error) {
+	if err := a.store.DeleteUserTokens(user.ID, tokenType); err != nil {
+		return "", errors.Wrap(err, "unable to delete the previous tokens")
+	}
+
+	token := utils.NewID(utils.IDTypeToken)
+	now := utils.GetMillis()
+	err := a.store.SaveUserToken(&model.UserToken{
+		TokenHash: auth.HashToken(token),
+		Type:      tokenType,
+		UserID:    user.ID,
+		Extra:     user.Email,
+		CreateAt:  now,
+		ExpireAt:  now + expiry.Milliseconds(),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "unable to save the token")
+	}
+
+	return token, nil
+}
+
+// useUserToken consumes a token and returns its user. Tokens sent to an
+// address the user no longer has are rejected.
+func (a *App) useUserToken(token, tokenType string) (*model.User, error) {
+	userToken, err := a.store.UseUserToken(auth.HashToken(token), tokenType)
+	if model.IsErrNotFound(err) {
+		return nil, model.NewErrBadRequest("invalid or expired token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.store.GetUserByID(userToken.UserID)
+	if model.IsErrNotFound(err) {
+		return nil, model.NewErrBadRequest("invalid or expired token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Email != userToken.Extra {
+		return nil, model.NewErrBadRequest("the email address of the user has changed")
+	}
+
+	return user, nil
+}
+
+func (a *App) userTokenLink(path, token string) string {
+	return fmt.Sprintf("%s/%s?token=%s", a.config.ServerRoot, path, url.QueryEscape(token))
+}
+
+func formatExpiry(expiry time.Duration) string {
+	if expiry%time.Hour == 0 {
+		if hours := int(expiry.Hours()); hours != 1 {
+			return fmt.Sprintf("%d hours", hours)
+		}
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d minutes", int(expiry.Minutes()))
+}
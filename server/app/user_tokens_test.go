@@ -0,0 +1,209 @@
+package app
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/mail"
+	"github.com/mattermost/focalboard/server/services/mail/mailtest"
+	"github.com/stretchr/testify/require"
+)
+
+var tokenLinkRegexp = regexp.MustCompile(`\?token=(\w+)`)
+
+func setupMail(t *testing.T, th *TestHelper) *mailtest.Server {
+	server, err := mailtest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	th.App.mail = mail.New(server.Config())
+	return server
+}
+
+// tokenFromMessage returns the token of the link of the last email sent
+// to a recipient.
+func tokenFromMessage(t *testing.T, server *mailtest.Server, to string) string {
+	message := server.LastMessageTo(to)
+	require.NotNil(t, message)
+	match := tokenLinkRegexp.FindStringSubmatch(message.Body)
+	require.Len(t, match, 2)
+	return match[1]
+}
+
+func TestSendPasswordReset(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("email not configured", func(t *testing.T) {
+		err := th.App.SendPasswordReset("john@example.com")
+		require.True(t, model.IsErrNotImplemented(err))
+	})
+
+	server := setupMail(t, th)
+
+	t.Run("unknown email", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByEmail("unknown@example.com").Return(nil, model.NewErrNotFound("user"))
+
+		require.NoError(t, th.App.SendPasswordReset("unknown@example.com"))
+		require.Empty(t, server.Messages())
+	})
+
+	t.Run("external user", func(t *testing.T) {
+		user := &model.User{ID: "user-id", Email: "ldap@example.com", AuthService: model.AuthModeLDAP}
+		th.Store.EXPECT().GetUserByEmail(user.Email).Return(user, nil)
+
+		require.NoError(t, th.App.SendPasswordReset(user.Email))
+		require.Empty(t, server.Messages())
+	})
+
+	t.Run("native user", func(t *testing.T) {
+		user := &model.User{ID: "user-id", Username: "john", Email: "john@example.com"}
+		th.Store.EXPECT().GetUserByEmail(user.Email).Return(user, nil)
+		th.Store.EXPECT().DeleteUserTokens(user.ID, model.UserTokenTypePasswordReset).Return(nil)
+
+		var saved *model.UserToken
+		th.Store.EXPECT().SaveUserToken(gomock.Any()).DoAndReturn(func(token *model.UserToken) error {
+			saved = token
+			return nil
+		})
+
+		require.NoError(t, th.App.SendPasswordReset(user.Email))
+
+		message := server.LastMessageTo(user.Email)
+		require.NotNil(t, message)
+		require.Equal(t, passwordResetSubject, message.Subject)
+		require.Contains(t, message.Body, "Hello john")
+		require.Contains(t, message.Body, "within 1 hour")
+
+		token := tokenFromMessage(t, server, user.Email)
+		require.Equal(t, auth.HashToken(token), saved.TokenHash)
+		require.Equal(t, model.UserTokenTypePasswordReset, saved.Type)
+		require.Equal(t, user.ID, saved.UserID)
+		require.Equal(t, user.Email, saved.Extra)
+		require.Equal(t, model.PasswordResetTokenExpiry.Milliseconds(), saved.ExpireAt-saved.CreateAt)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.PasswordSettings.Number = true
+
+	t.Run("password not following the policy", func(t *testing.T) {
+		err := th.App.ResetPassword("token", "no-number-password")
+		require.True(t, model.IsErrBadRequest(err))
+
+		err = th.App.ResetPassword("token", "short1")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		th.Store.EXPECT().UseUserToken(auth.HashToken("token"), model.UserTokenTypePasswordReset).Return(nil, model.NewErrNotFound("user token"))
+
+		err := th.App.ResetPassword("token", "new-password-1")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("email changed since the token was sent", func(t *testing.T) {
+		th.Store.EXPECT().UseUserToken(auth.HashToken("token"), model.UserTokenTypePasswordReset).
+			Return(&model.UserToken{UserID: "user-id", Extra: "old@example.com"}, nil)
+		th.Store.EXPECT().GetUserByID("user-id").Return(&model.User{ID: "user-id", Email: "new@example.com"}, nil)
+
+		err := th.App.ResetPassword("token", "new-password-1")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		user := &model.User{ID: "user-id", Email: "john@example.com"}
+		th.Store.EXPECT().UseUserToken(auth.HashToken("token"), model.UserTokenTypePasswordReset).
+			Return(&model.UserToken{UserID: user.ID, Extra: user.Email}, nil)
+		th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+		th.Store.EXPECT().UpdateUserPasswordByID(user.ID, gomock.Any()).DoAndReturn(func(_, password string) error {
+			require.True(t, auth.ComparePassword(password, "new-password-1"))
+			return nil
+		})
+		th.Store.EXPECT().UpdateUser(user).Return(user, nil)
+		th.Store.EXPECT().GetUserSessions(user.ID, gomock.Any()).Return([]*model.Session{{ID: "session-id"}}, nil)
+		th.Store.EXPECT().DeleteUserSessions(user.ID, "").Return(nil)
+
+		require.NoError(t, th.App.ResetPassword("token", "new-password-1"))
+		require.True(t, user.EmailVerified)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("invalid token", func(t *testing.T) {
+		th.Store.EXPECT().UseUserToken(auth.HashToken("token"), model.UserTokenTypeEmailVerification).Return(nil, model.NewErrNotFound("user token"))
+
+		err := th.App.VerifyEmail("token")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		user := &model.User{ID: "user-id", Email: "john@example.com"}
+		th.Store.EXPECT().UseUserToken(auth.HashToken("token"), model.UserTokenTypeEmailVerification).
+			Return(&model.UserToken{UserID: user.ID, Extra: user.Email}, nil)
+		th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+		th.Store.EXPECT().UpdateUser(user).Return(user, nil)
+
+		require.NoError(t, th.App.VerifyEmail("token"))
+		require.True(t, user.EmailVerified)
+	})
+}
+
+func TestRegisterUserEmailVerification(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	server := setupMail(t, th)
+	th.App.config.RequireEmailVerification = true
+
+	th.Store.EXPECT().GetUserByUsername("john").Return(nil, model.NewErrNotFound("user"))
+	th.Store.EXPECT().GetUserByEmail("john@example.com").Return(nil, model.NewErrNotFound("user"))
+
+	var created *model.User
+	th.Store.EXPECT().CreateUser(gomock.Any()).DoAndReturn(func(user *model.User) (*model.User, error) {
+		created = user
+		return user, nil
+	})
+	th.Store.EXPECT().DeleteUserTokens(gomock.Any(), model.UserTokenTypeEmailVerification).Return(nil)
+	th.Store.EXPECT().SaveUserToken(gomock.Any()).Return(nil)
+
+	require.NoError(t, th.App.RegisterUser("john", "john@example.com", "john-password"))
+	require.False(t, created.EmailVerified)
+
+	message := server.LastMessageTo("john@example.com")
+	require.NotNil(t, message)
+	require.Equal(t, emailVerificationSubject, message.Subject)
+	require.Contains(t, message.Body, "within 24 hours")
+}
+
+func TestLoginEmailNotVerified(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.RequireEmailVerification = true
+	user := &model.User{
+		ID:       "user-id",
+		Username: "john",
+		Password: auth.HashPassword("john-password"),
+	}
+	th.Store.EXPECT().GetUserByUsername("john").Return(user, nil).Times(2)
+
+	_, err := th.App.Login("john", "", "john-password", "", "", "")
+	require.ErrorIs(t, err, ErrEmailNotVerified)
+
+	user.EmailVerified = true
+	th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+
+	token, err := th.App.Login("john", "", "john-password", "", "", "")
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+}
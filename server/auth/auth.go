@@ -3,6 +3,7 @@ package auth
 
 import (
 	"github.com/mattermost/focalboard/server/model"
+	authService "github.com/mattermost/focalboard/server/services/auth"
 	"github.com/mattermost/focalboard/server/services/config"
 	"github.com/mattermost/focalboard/server/services/permissions"
 	"github.com/mattermost/focalboard/server/services/store"
@@ -14,6 +15,8 @@ type AuthInterface interface {
 	GetSession(token string) (*model.Session, error)
 	IsValidReadToken(boardID string, readToken string) (bool, error)
 	DoesUserHaveTeamAccess(userID string, teamID string) bool
+	DoesUserHaveBoardAccess(userID string, boardID string) bool
+	CanUserEditBoardCards(userID string, boardID string) bool
 }
 
 // Auth authenticates sessions.
@@ -34,6 +37,10 @@ func (a *Auth) GetSession(token string) (*model.Session, error) {
 		return nil, errors.New("no session token")
 	}
 
+	if model.IsAccessTokenString(token) {
+		return a.getSessionForAccessToken(token)
+	}
+
 	session, err := a.store.GetSession(token, a.config.SessionExpireTime)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to get the session for the token")
@@ -44,6 +51,42 @@ func (a *Auth) GetSession(token string) (*model.Session, error) {
 	return session, nil
 }
 
+// getSessionForAccessToken validates a personal access token and
+// returns a session that carries the token scopes.
+func (a *Auth) getSessionForAccessToken(token string) (*model.Session, error) {
+	accessToken, err := a.store.GetAccessTokenByHash(authService.HashToken(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the access token")
+	}
+
+	now := utils.GetMillis()
+	if accessToken.IsExpired(now) {
+		return nil, errors.New("access token expired")
+	}
+
+	// ensures that the owner of the token still exists and is active
+	if _, err = a.store.GetUserByID(accessToken.UserID); err != nil {
+		return nil, errors.Wrap(err, "unable to get the access token user")
+	}
+
+	if accessToken.LastUsedAt < (now - utils.SecondsToMillis(a.config.SessionRefreshTime)) {
+		_ = a.store.UpdateAccessTokenLastUsed(accessToken.ID, now)
+	}
+
+	return &model.Session{
+		ID:          accessToken.ID,
+		Token:       token,
+		UserID:      accessToken.UserID,
+		AuthService: a.config.AuthMode,
+		Props: map[string]interface{}{
+			model.SessionPropAccessTokenID:     accessToken.ID,
+			model.SessionPropAccessTokenScopes: accessToken.Scopes,
+		},
+		CreateAt: accessToken.CreateAt,
+		UpdateAt: now,
+	}, nil
+}
+
 // IsValidReadToken validates the read token for a board.
 func (a *Auth) IsValidReadToken(boardID string, readToken string) (bool, error) {
 	sharing, err := a.store.GetSharing(boardID)
@@ -68,3 +111,11 @@ func (a *Auth) IsValidReadToken(boardID string, readToken string) (bool, error)
 func (a *Auth) DoesUserHaveTeamAccess(userID string, teamID string) bool {
 	return a.permissions.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam)
 }
+
+func (a *Auth) DoesUserHaveBoardAccess(userID string, boardID string) bool {
+	return a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionViewBoard)
+}
+
+func (a *Auth) CanUserEditBoardCards(userID string, boardID string) bool {
+	return a.permissions.HasPermissionToBoard(userID, boardID, model.PermissionManageBoardCards)
+}
@@ -27,6 +27,17 @@ func (rre RequestReaderError) Error() string {
 	return "payload: " + string(rre.buf)
 }
 
+// ConflictError is returned when a patch is rejected because the patched
+// resource was modified since the version it expected.
+type ConflictError struct {
+	Message         string
+	CurrentUpdateAt int64
+}
+
+func (ce ConflictError) Error() string {
+	return ce.Message
+}
+
 type Response struct {
 	StatusCode int
 	Error      error
@@ -147,6 +158,12 @@ func (c *Client) doAPIRequestReader(method, url string, data io.Reader, _ /* eta
 		if err != nil {
 			return rp, fmt.Errorf("error when parsing response with code %d: %w", rp.StatusCode, err)
 		}
+		if rp.StatusCode == http.StatusConflict {
+			var errorResponse model.ErrorResponse
+			if json.Unmarshal(b, &errorResponse) == nil && errorResponse.CurrentUpdateAt != 0 {
+				return rp, ConflictError{Message: errorResponse.Error, CurrentUpdateAt: errorResponse.CurrentUpdateAt}
+			}
+		}
 		return rp, RequestReaderError{b}
 	}
 
@@ -215,6 +232,70 @@ func (c *Client) GetTeam(teamID string) (*model.Team, *Response) {
 	return model.TeamFromJSON(r.Body), BuildResponse(r)
 }
 
+func (c *Client) GetTeams() ([]*model.Team, *Response) {
+	r, err := c.DoAPIGet(c.GetTeamsRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.TeamsFromJSON(r.Body), BuildResponse(r)
+}
+
+func (c *Client) CreateTeam(title string) (*model.Team, *Response) {
+	r, err := c.DoAPIPost(c.GetTeamsRoute(), toJSON(&model.CreateTeamRequest{Title: title}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.TeamFromJSON(r.Body), BuildResponse(r)
+}
+
+func (c *Client) GetTeamMembersRoute(teamID string) string {
+	return fmt.Sprintf("%s/members", c.GetTeamRoute(teamID))
+}
+
+func (c *Client) GetTeamMembers(teamID string) ([]*model.TeamMember, *Response) {
+	r, err := c.DoAPIGet(c.GetTeamMembersRoute(teamID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.TeamMembersFromJSON(r.Body), BuildResponse(r)
+}
+
+func (c *Client) RemoveTeamMember(teamID, userID string) *Response {
+	r, err := c.DoAPIDelete(fmt.Sprintf("%s/%s", c.GetTeamMembersRoute(teamID), userID), "")
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
+func (c *Client) InviteToTeam(teamID string, emails []string, role string) *Response {
+	r, err := c.DoAPIPost(c.GetTeamRoute(teamID)+"/invites", toJSON(&model.TeamInviteRequest{Emails: emails, Role: role}))
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
+func (c *Client) AcceptTeamInvite(token string) (*model.Team, *Response) {
+	r, err := c.DoAPIPost(c.GetTeamsRoute()+"/invites/accept", toJSON(&model.TeamInviteAcceptRequest{Token: token}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.TeamFromJSON(r.Body), BuildResponse(r)
+}
+
 func (c *Client) GetBlocksForBoard(boardID string) ([]*model.Block, *Response) {
 	r, err := c.DoAPIGet(c.GetBlocksRoute(boardID), "")
 	if err != nil {
@@ -225,6 +306,22 @@ func (c *Client) GetBlocksForBoard(boardID string) ([]*model.Block, *Response) {
 	return model.BlocksFromJSON(r.Body), BuildResponse(r)
 }
 
+// GetBlock returns a block of a board. The response has the ETag of the
+// block's version.
+func (c *Client) GetBlock(boardID, blockID string) (*model.Block, *Response) {
+	r, err := c.DoAPIGet(c.GetBlocksRoute(boardID)+"?block_id="+blockID, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	blocks := model.BlocksFromJSON(r.Body)
+	if len(blocks) == 0 {
+		return nil, BuildResponse(r)
+	}
+	return blocks[0], BuildResponse(r)
+}
+
 func (c *Client) GetAllBlocksForBoard(boardID string) ([]*model.Block, *Response) {
 	r, err := c.DoAPIGet(c.GetAllBlocksRoute(boardID), "")
 	if err != nil {
@@ -560,6 +657,54 @@ func (c *Client) Login(request *model.LoginRequest) (*model.LoginResponse, *Resp
 	return data, BuildResponse(r)
 }
 
+func (c *Client) GetPasswordResetRoute() string {
+	return "/users/password/reset"
+}
+
+func (c *Client) SendPasswordReset(email string) *Response {
+	r, err := c.DoAPIPost(c.GetPasswordResetRoute()+"/send", toJSON(&model.PasswordResetSendRequest{Email: email}))
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
+func (c *Client) ResetPassword(token, newPassword string) *Response {
+	r, err := c.DoAPIPost(c.GetPasswordResetRoute(), toJSON(&model.PasswordResetRequest{Token: token, NewPassword: newPassword}))
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
+func (c *Client) GetEmailVerificationRoute() string {
+	return "/users/email/verify"
+}
+
+func (c *Client) SendEmailVerification(email string) *Response {
+	r, err := c.DoAPIPost(c.GetEmailVerificationRoute()+"/send", toJSON(&model.EmailVerificationSendRequest{Email: email}))
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
+func (c *Client) VerifyEmail(token string) *Response {
+	r, err := c.DoAPIPost(c.GetEmailVerificationRoute(), toJSON(&model.EmailVerificationRequest{Token: token}))
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
 func (c *Client) GetMeRoute() string {
 	return "/users/me"
 }
@@ -624,6 +769,16 @@ func (c *Client) GetUserList(ids []string) ([]model.User, *Response) {
 	return users, BuildResponse(r)
 }
 
+func (c *Client) DeactivateUser(id string, request *model.DeactivateUserRequest) (*model.DeactivateUserReport, *Response) {
+	r, err := c.DoAPIPost(c.GetUserRoute(id)+"/deactivate", toJSON(request))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.DeactivateUserReportFromJSON(r.Body), BuildResponse(r)
+}
+
 func (c *Client) GetUserChangePasswordRoute(id string) string {
 	return fmt.Sprintf("/users/%s/changepassword", id)
 }
@@ -638,6 +793,140 @@ func (c *Client) UserChangePassword(id string, data *model.ChangePasswordRequest
 	return true, BuildResponse(r)
 }
 
+func (c *Client) GetAccessTokensRoute() string {
+	return "/users/me/access_tokens"
+}
+
+func (c *Client) GetAccessTokenRoute(tokenID string) string {
+	return fmt.Sprintf("%s/%s", c.GetAccessTokensRoute(), tokenID)
+}
+
+func (c *Client) CreateAccessToken(request *model.AccessTokenCreateRequest) (*model.AccessToken, *Response) {
+	r, err := c.DoAPIPost(c.GetAccessTokensRoute(), toJSON(request))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	token, err := model.AccessTokenFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return token, BuildResponse(r)
+}
+
+func (c *Client) GetAccessTokens() ([]*model.AccessToken, *Response) {
+	r, err := c.DoAPIGet(c.GetAccessTokensRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	tokens, err := model.AccessTokensFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return tokens, BuildResponse(r)
+}
+
+func (c *Client) RevokeAccessToken(tokenID string) *Response {
+	r, err := c.DoAPIDelete(c.GetAccessTokenRoute(tokenID), "")
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
+func (c *Client) GetSessionsRoute() string {
+	return "/users/me/sessions"
+}
+
+func (c *Client) GetSessionRoute(sessionID string) string {
+	return fmt.Sprintf("%s/%s", c.GetSessionsRoute(), sessionID)
+}
+
+func (c *Client) GetSessions() ([]*model.Session, *Response) {
+	r, err := c.DoAPIGet(c.GetSessionsRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	sessions, err := model.SessionsFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return sessions, BuildResponse(r)
+}
+
+func (c *Client) RevokeSession(sessionID string) *Response {
+	r, err := c.DoAPIDelete(c.GetSessionRoute(sessionID), "")
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
+func (c *Client) GetMfaRoute() string {
+	return "/users/me/mfa"
+}
+
+func (c *Client) GenerateMfaSecret() (*model.MfaEnrollment, *Response) {
+	r, err := c.DoAPIPost(c.GetMfaRoute()+"/generate", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	enrollment, err := model.MfaEnrollmentFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return enrollment, BuildResponse(r)
+}
+
+func (c *Client) ActivateMfa(code string) (*model.MfaRecoveryCodes, *Response) {
+	r, err := c.DoAPIPost(c.GetMfaRoute()+"/activate", toJSON(&model.MfaCodeRequest{Code: code}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	codes, err := model.MfaRecoveryCodesFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return codes, BuildResponse(r)
+}
+
+func (c *Client) DeactivateMfa(code string) *Response {
+	r, err := c.DoAPIPost(c.GetMfaRoute()+"/deactivate", toJSON(&model.MfaCodeRequest{Code: code}))
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
+func (c *Client) RegenerateMfaRecoveryCodes(code string) (*model.MfaRecoveryCodes, *Response) {
+	r, err := c.DoAPIPost(c.GetMfaRoute()+"/recovery_codes", toJSON(&model.MfaCodeRequest{Code: code}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	codes, err := model.MfaRecoveryCodesFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return codes, BuildResponse(r)
+}
+
 func (c *Client) CreateBoard(board *model.Board) (*model.Board, *Response) {
 	r, err := c.DoAPIPost(c.GetBoardsRoute(), toJSON(board))
 	if err != nil {
@@ -799,6 +1088,50 @@ func (c *Client) DeleteBoardMember(member *model.BoardMember) (bool, *Response)
 	return true, BuildResponse(r)
 }
 
+func (c *Client) GetCustomBoardRolesRoute(boardID string) string {
+	return fmt.Sprintf("%s/roles", c.GetBoardRoute(boardID))
+}
+
+func (c *Client) GetCustomBoardRoles(boardID string) ([]*model.CustomBoardRole, *Response) {
+	r, err := c.DoAPIGet(c.GetCustomBoardRolesRoute(boardID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.CustomBoardRolesFromJSON(r.Body), BuildResponse(r)
+}
+
+func (c *Client) CreateCustomBoardRole(role *model.CustomBoardRole) (*model.CustomBoardRole, *Response) {
+	r, err := c.DoAPIPost(c.GetCustomBoardRolesRoute(role.BoardID), toJSON(role))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.CustomBoardRoleFromJSON(r.Body), BuildResponse(r)
+}
+
+func (c *Client) UpdateCustomBoardRole(role *model.CustomBoardRole) (*model.CustomBoardRole, *Response) {
+	r, err := c.DoAPIPut(c.GetCustomBoardRolesRoute(role.BoardID)+"/"+role.ID, toJSON(role))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.CustomBoardRoleFromJSON(r.Body), BuildResponse(r)
+}
+
+func (c *Client) DeleteCustomBoardRole(boardID, roleID string) *Response {
+	r, err := c.DoAPIDelete(c.GetCustomBoardRolesRoute(boardID)+"/"+roleID, "")
+	if err != nil {
+		return BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return BuildResponse(r)
+}
+
 func (c *Client) GetTeamUploadFileRoute(teamID, boardID string) string {
 	return fmt.Sprintf("%s/%s/files", c.GetTeamRoute(teamID), boardID)
 }
@@ -0,0 +1,225 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokens(t *testing.T) {
+	t.Run("a non authenticated client should be rejected", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		th.Logout(th.Client)
+
+		tokens, resp := th.Client.GetAccessTokens()
+		th.CheckUnauthorized(resp)
+		require.Nil(t, tokens)
+	})
+
+	t.Run("create, use, list and revoke a token", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		me := th.Me(th.Client)
+
+		accessToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{Description: "automation"})
+		th.CheckOK(resp)
+		require.NotEmpty(t, accessToken.Token)
+
+		tokenClient := client.NewClient(th.Client.URL, accessToken.Token)
+		require.Equal(t, me.ID, th.Me(tokenClient).ID)
+
+		board := th.CreateBoard(testTeamID, model.BoardTypeOpen)
+		newTitle := "changed"
+		_, resp = tokenClient.PatchBoard(board.ID, &model.BoardPatch{Title: &newTitle})
+		th.CheckOK(resp)
+
+		tokens, resp := th.Client.GetAccessTokens()
+		th.CheckOK(resp)
+		require.Len(t, tokens, 1)
+		require.Equal(t, accessToken.ID, tokens[0].ID)
+		require.Empty(t, tokens[0].Token)
+
+		th.CheckOK(th.Client.RevokeAccessToken(accessToken.ID))
+
+		_, resp = tokenClient.GetMe()
+		th.CheckUnauthorized(resp)
+	})
+
+	t.Run("read-only tokens cannot modify data", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		board := th.CreateBoard(testTeamID, model.BoardTypeOpen)
+
+		accessToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{
+			Scopes: []string{model.AccessTokenScopeReadOnly},
+		})
+		th.CheckOK(resp)
+		tokenClient := client.NewClient(th.Client.URL, accessToken.Token)
+
+		fetched, resp := tokenClient.GetBoard(board.ID, "")
+		th.CheckOK(resp)
+		require.Equal(t, board.ID, fetched.ID)
+
+		newTitle := "changed"
+		_, resp = tokenClient.PatchBoard(board.ID, &model.BoardPatch{Title: &newTitle})
+		th.CheckForbidden(resp)
+
+		_, resp = tokenClient.CreateBoard(&model.Board{TeamID: testTeamID, Type: model.BoardTypeOpen})
+		th.CheckForbidden(resp)
+	})
+
+	t.Run("cards:write tokens cannot manage boards", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		board := th.CreateBoard(testTeamID, model.BoardTypeOpen)
+
+		accessToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{
+			Scopes: []string{model.AccessTokenScopeCardsWrite},
+		})
+		th.CheckOK(resp)
+		tokenClient := client.NewClient(th.Client.URL, accessToken.Token)
+
+		card, resp := tokenClient.CreateCard(board.ID, &model.Card{Title: "card"}, false)
+		th.CheckOK(resp)
+		require.NotNil(t, card)
+
+		success, resp := tokenClient.DeleteBoard(board.ID)
+		th.CheckForbidden(resp)
+		require.False(t, success)
+	})
+
+	t.Run("boards:admin tokens create boards", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		accessToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{
+			Scopes: []string{model.AccessTokenScopeBoardsAdmin},
+		})
+		th.CheckOK(resp)
+		tokenClient := client.NewClient(th.Client.URL, accessToken.Token)
+
+		for _, boardType := range []model.BoardType{model.BoardTypeOpen, model.BoardTypePrivate} {
+			board, resp := tokenClient.CreateBoard(&model.Board{TeamID: testTeamID, Type: boardType})
+			th.CheckOK(resp)
+			require.NotNil(t, board)
+		}
+	})
+
+	t.Run("the token scopes don't hide the permissions of other users", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		admin := th.GetUser2()
+		th.makeSystemAdmin(admin.ID)
+
+		accessToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{
+			Scopes: []string{model.AccessTokenScopeReadOnly},
+		})
+		th.CheckOK(resp)
+		tokenClient := client.NewClient(th.Client.URL, accessToken.Token)
+
+		r, err := tokenClient.DoAPIPost("/teams/"+testTeamID+"/users", toJSON(t, []string{admin.ID}))
+		require.NoError(t, err)
+		defer r.Body.Close()
+
+		var users []*model.User
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&users))
+		require.Len(t, users, 1)
+		require.Contains(t, users[0].Permissions, model.PermissionManageSystem.Id)
+	})
+
+	t.Run("write routes outside of boards need an explicit scope", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		me := th.GetUser1()
+		category := model.Category{Name: "from a token", UserID: me.ID, TeamID: testTeamID}
+
+		cardsToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{
+			Scopes: []string{model.AccessTokenScopeCardsWrite},
+		})
+		th.CheckOK(resp)
+		cardsClient := client.NewClient(th.Client.URL, cardsToken.Token)
+
+		_, resp = cardsClient.CreateCategory(category)
+		th.CheckForbidden(resp)
+
+		adminToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{
+			Scopes: []string{model.AccessTokenScopeBoardsAdmin},
+		})
+		th.CheckOK(resp)
+		adminClient := client.NewClient(th.Client.URL, adminToken.Token)
+
+		created, resp := adminClient.CreateCategory(category)
+		th.CheckOK(resp)
+		require.Equal(t, "from a token", created.Name)
+
+		changePassword := &model.ChangePasswordRequest{OldPassword: password, NewPassword: "Pa$$word2"}
+		_, resp = adminClient.UserChangePassword(me.ID, changePassword)
+		th.CheckForbidden(resp)
+
+		unscopedToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{})
+		th.CheckOK(resp)
+		_, resp = client.NewClient(th.Client.URL, unscopedToken.Token).UserChangePassword(me.ID, changePassword)
+		th.CheckForbidden(resp)
+	})
+
+	t.Run("expired, revoked and invalid tokens are rejected", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		expiring, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{
+			ExpiresAt: utils.GetMillis() + 500,
+		})
+		th.CheckOK(resp)
+		expiringClient := client.NewClient(th.Client.URL, expiring.Token)
+		th.Me(expiringClient)
+
+		time.Sleep(time.Second)
+		_, resp = expiringClient.GetMe()
+		th.CheckUnauthorized(resp)
+
+		revoked, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{})
+		th.CheckOK(resp)
+		th.CheckOK(th.Client.RevokeAccessToken(revoked.ID))
+		_, resp = client.NewClient(th.Client.URL, revoked.Token).GetMe()
+		th.CheckUnauthorized(resp)
+
+		_, resp = client.NewClient(th.Client.URL, "fbpat_invalid").GetMe()
+		th.CheckUnauthorized(resp)
+	})
+
+	t.Run("tokens cannot manage other tokens", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		accessToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{})
+		th.CheckOK(resp)
+		tokenClient := client.NewClient(th.Client.URL, accessToken.Token)
+
+		_, resp = tokenClient.CreateAccessToken(&model.AccessTokenCreateRequest{})
+		th.CheckForbidden(resp)
+
+		th.CheckForbidden(tokenClient.RevokeAccessToken(accessToken.ID))
+	})
+
+	t.Run("users cannot revoke tokens of other users", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		accessToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{})
+		th.CheckOK(resp)
+
+		th.CheckNotFound(th.Client2.RevokeAccessToken(accessToken.ID))
+	})
+}
@@ -1,6 +1,8 @@
 package integrationtests
 
 import (
+	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -257,6 +259,35 @@ func TestPatchBlock(t *testing.T) {
 		require.Equal(t, "test value 2", updatedBlock.Fields["test2"])
 		require.Equal(t, nil, updatedBlock.Fields["test3"])
 	})
+
+	t.Run("Patch an outdated version of a block", func(t *testing.T) {
+		current, resp := th.Client.GetBlock(board.ID, blockID)
+		th.CheckOK(resp)
+		require.Equal(t, fmt.Sprintf("%q", strconv.FormatInt(current.UpdateAt, 10)), resp.Header.Get("ETag"))
+
+		newTitle := "Title from the expected version"
+		expectedUpdateAt := current.UpdateAt
+		blockPatch := &model.BlockPatch{
+			Title:            &newTitle,
+			ExpectedUpdateAt: &expectedUpdateAt,
+		}
+		_, resp = th.Client.PatchBlock(board.ID, blockID, blockPatch, false)
+		require.NoError(t, resp.Error)
+
+		updated, err := th.Server.App().GetBlockByID(blockID)
+		require.NoError(t, err)
+		require.Equal(t, newTitle, updated.Title)
+		require.Equal(t, fmt.Sprintf("%q", strconv.FormatInt(updated.UpdateAt, 10)), resp.Header.Get("ETag"))
+
+		outdatedTitle := "Title from an outdated version"
+		blockPatch.Title = &outdatedTitle
+		_, resp = th.Client.PatchBlock(board.ID, blockID, blockPatch, false)
+		th.CheckConflict(resp, updated.UpdateAt)
+
+		block, err := th.Server.App().GetBlockByID(blockID)
+		require.NoError(t, err)
+		require.Equal(t, newTitle, block.Title)
+	})
 }
 
 func TestDeleteBlock(t *testing.T) {
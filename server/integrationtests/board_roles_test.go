@@ -0,0 +1,109 @@
+package integrationtests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomBoardRoles(t *testing.T) {
+	th := SetupTestHelper(t).InitBasic()
+	defer th.TearDown()
+
+	board := th.CreateBoard(testTeamID, model.BoardTypePrivate)
+	_, resp := th.Client.AddMemberToBoard(&model.BoardMember{
+		BoardID:      board.ID,
+		UserID:       th.GetUser2().ID,
+		SchemeViewer: true,
+	})
+	th.CheckOK(resp)
+
+	t.Run("only board admins manage roles", func(t *testing.T) {
+		_, resp := th.Client2.CreateCustomBoardRole(&model.CustomBoardRole{
+			BoardID:     board.ID,
+			Name:        "Card editor",
+			Permissions: []string{model.PermissionManageBoardCards.Id},
+		})
+		th.CheckForbidden(resp)
+	})
+
+	t.Run("roles can only contain board permissions", func(t *testing.T) {
+		_, resp := th.Client.CreateCustomBoardRole(&model.CustomBoardRole{
+			BoardID:     board.ID,
+			Name:        "System admin",
+			Permissions: []string{model.PermissionManageSystem.Id},
+		})
+		th.CheckBadRequest(resp)
+	})
+
+	role, resp := th.Client.CreateCustomBoardRole(&model.CustomBoardRole{
+		BoardID:     board.ID,
+		Name:        "Card editor",
+		Permissions: []string{model.PermissionManageBoardCards.Id, model.PermissionCommentBoardCards.Id},
+	})
+	th.CheckOK(resp)
+	require.NotEmpty(t, role.ID)
+
+	t.Run("members see the roles", func(t *testing.T) {
+		roles, resp := th.Client2.GetCustomBoardRoles(board.ID)
+		th.CheckOK(resp)
+		require.Len(t, roles, 1)
+		require.Equal(t, role.ID, roles[0].ID)
+	})
+
+	t.Run("a member assigned to a role gets its permissions", func(t *testing.T) {
+		_, resp := th.Client2.CreateCard(board.ID, &model.Card{Title: "card"}, false)
+		th.CheckForbidden(resp)
+
+		member, resp := th.Client.UpdateBoardMember(&model.BoardMember{
+			BoardID:      board.ID,
+			UserID:       th.GetUser2().ID,
+			SchemeViewer: true,
+			CustomRoleID: role.ID,
+		})
+		th.CheckOK(resp)
+		require.Equal(t, role.ID, member.CustomRoleID)
+
+		_, resp = th.Client2.CreateCard(board.ID, &model.Card{Title: "card"}, false)
+		th.CheckOK(resp)
+
+		newTitle := "New title"
+		_, resp = th.Client2.PatchBoard(board.ID, &model.BoardPatch{Title: &newTitle})
+		th.CheckForbidden(resp)
+	})
+
+	t.Run("roles of other boards can't be assigned", func(t *testing.T) {
+		otherBoard := th.CreateBoard(testTeamID, model.BoardTypePrivate)
+		_, resp := th.Client.AddMemberToBoard(&model.BoardMember{
+			BoardID:      otherBoard.ID,
+			UserID:       th.GetUser2().ID,
+			SchemeViewer: true,
+			CustomRoleID: role.ID,
+		})
+		th.CheckBadRequest(resp)
+	})
+
+	t.Run("updating a role changes the members' permissions", func(t *testing.T) {
+		role.Permissions = []string{model.PermissionCommentBoardCards.Id}
+		_, resp := th.Client.UpdateCustomBoardRole(role)
+		th.CheckOK(resp)
+
+		_, resp = th.Client2.CreateCard(board.ID, &model.Card{Title: "card"}, false)
+		th.CheckForbidden(resp)
+	})
+
+	t.Run("deleting a role unassigns it", func(t *testing.T) {
+		resp := th.Client.DeleteCustomBoardRole(board.ID, role.ID)
+		th.CheckOK(resp)
+
+		members, resp := th.Client.GetMembersForBoard(board.ID)
+		th.CheckOK(resp)
+		for _, member := range members {
+			require.Empty(t, member.CustomRoleID)
+		}
+
+		resp = th.Client.DeleteCustomBoardRole(board.ID, role.ID)
+		th.CheckNotFound(resp)
+	})
+}
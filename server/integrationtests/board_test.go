@@ -39,7 +39,7 @@ func TestGetBoards(t *testing.T) {
 		defer th.TearDown()
 
 		teamID := "0"
-		otherTeamID := "other-team-id"
+		otherTeamID := testTeamID
 		user1 := th.GetUser1()
 		user2 := th.GetUser2()
 
@@ -990,6 +990,39 @@ func TestPatchBoard(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, initialTitle, dbBoard.Title)
 	})
+
+	t.Run("patch on an outdated version of a board", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		user1 := th.GetUser1()
+
+		newBoard := &model.Board{
+			Title:  "title",
+			Type:   model.BoardTypeOpen,
+			TeamID: teamID,
+		}
+		board, err := th.Server.App().CreateBoard(newBoard, user1.ID, true)
+		require.NoError(t, err)
+
+		newTitle := "a new title"
+		expectedUpdateAt := board.UpdateAt
+		patch := &model.BoardPatch{Title: &newTitle, ExpectedUpdateAt: &expectedUpdateAt}
+
+		rBoard, resp := th.Client.PatchBoard(board.ID, patch)
+		th.CheckOK(resp)
+		require.Equal(t, newTitle, rBoard.Title)
+
+		outdatedTitle := "an outdated title"
+		patch.Title = &outdatedTitle
+
+		_, resp = th.Client.PatchBoard(board.ID, patch)
+		th.CheckConflict(resp, rBoard.UpdateAt)
+
+		dbBoard, err := th.Server.App().GetBoard(board.ID)
+		require.NoError(t, err)
+		require.Equal(t, newTitle, dbBoard.Title)
+	})
 }
 
 func TestDeleteBoard(t *testing.T) {
@@ -1872,7 +1905,7 @@ func TestGetTemplates(t *testing.T) {
 		err := th.Server.App().InitTemplates()
 		require.NoError(t, err, "InitTemplates should not fail")
 
-		teamID := "my-team-id"
+		teamID := testTeamID
 		rBoards, resp := th.Client.GetTemplatesForTeam("0")
 		th.CheckOK(resp)
 		require.NotNil(t, rBoards)
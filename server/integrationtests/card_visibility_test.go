@@ -0,0 +1,117 @@
+package integrationtests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateCards(t *testing.T) {
+	th := SetupTestHelper(t).InitBasic()
+	defer th.TearDown()
+
+	board := th.CreateBoard(testTeamID, model.BoardTypePrivate)
+	_, resp := th.Client.AddMemberToBoard(&model.BoardMember{
+		BoardID:      board.ID,
+		UserID:       th.GetUser2().ID,
+		SchemeEditor: true,
+	})
+	th.CheckOK(resp)
+
+	privateCard, resp := th.Client.CreateCard(board.ID, &model.Card{
+		Title:     "private card",
+		VisibleTo: []string{string(model.BoardRoleAdmin)},
+	}, true)
+	th.CheckOK(resp)
+	require.Equal(t, []string{string(model.BoardRoleAdmin)}, privateCard.VisibleTo)
+
+	publicCard, resp := th.Client.CreateCard(board.ID, &model.Card{Title: "public card"}, true)
+	th.CheckOK(resp)
+
+	now := utils.GetMillis()
+	_, resp = th.Client.InsertBlocks(board.ID, []*model.Block{
+		{
+			ID:       "comment-1",
+			BoardID:  board.ID,
+			ParentID: privateCard.ID,
+			Type:     model.TypeComment,
+			Title:    "private comment",
+			CreateAt: now,
+			UpdateAt: now,
+		},
+	}, true)
+	th.CheckOK(resp)
+
+	t.Run("other members don't see the card nor its contents", func(t *testing.T) {
+		cards, resp := th.Client2.GetCards(board.ID, 0, 10)
+		th.CheckOK(resp)
+		require.Len(t, cards, 1)
+		require.Equal(t, publicCard.ID, cards[0].ID)
+
+		blocks, resp := th.Client2.GetBlocksForBoard(board.ID)
+		th.CheckOK(resp)
+		for _, block := range blocks {
+			require.NotEqual(t, privateCard.ID, block.ID)
+			require.NotEqual(t, privateCard.ID, block.ParentID)
+		}
+
+		_, resp = th.Client2.GetCard(privateCard.ID)
+		th.CheckNotFound(resp)
+	})
+
+	t.Run("other members can't change the card", func(t *testing.T) {
+		title := "new title"
+		_, resp := th.Client2.PatchCard(privateCard.ID, &model.CardPatch{Title: &title}, true)
+		th.CheckNotFound(resp)
+
+		now := utils.GetMillis()
+		_, resp = th.Client2.InsertBlocks(board.ID, []*model.Block{
+			{
+				ID:       "comment-2",
+				BoardID:  board.ID,
+				ParentID: privateCard.ID,
+				Type:     model.TypeComment,
+				Title:    "comment",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+		}, true)
+		th.CheckForbidden(resp)
+	})
+
+	t.Run("the creator and the listed users see the card", func(t *testing.T) {
+		cards, resp := th.Client.GetCards(board.ID, 0, 10)
+		th.CheckOK(resp)
+		require.Len(t, cards, 2)
+
+		visibleTo := []string{string(model.BoardRoleAdmin), th.GetUser2().ID}
+		_, resp = th.Client.PatchCard(privateCard.ID, &model.CardPatch{VisibleTo: &visibleTo}, true)
+		th.CheckOK(resp)
+
+		card, resp := th.Client2.GetCard(privateCard.ID)
+		th.CheckOK(resp)
+		require.Equal(t, visibleTo, card.VisibleTo)
+
+		blocks, resp := th.Client2.GetBlocksForBoard(board.ID)
+		th.CheckOK(resp)
+		var found int
+		for _, block := range blocks {
+			if block.ID == privateCard.ID || block.ParentID == privateCard.ID {
+				found++
+			}
+		}
+		require.Equal(t, 2, found)
+	})
+
+	t.Run("clearing the list makes the card public", func(t *testing.T) {
+		visibleTo := []string{}
+		_, resp := th.Client.PatchCard(privateCard.ID, &model.CardPatch{VisibleTo: &visibleTo}, true)
+		th.CheckOK(resp)
+
+		card, resp := th.Client.GetCard(privateCard.ID)
+		th.CheckOK(resp)
+		require.Empty(t, card.VisibleTo)
+	})
+}
@@ -147,8 +147,12 @@ func newTestServerWithLicense(singleUserToken string, licenseType LicenseType) *
 		panic(err)
 	}
 
+	return newTestServerWithConfig(cfg, singleUserToken, licenseType)
+}
+
+func newTestServerWithConfig(cfg *config.Configuration, singleUserToken string, licenseType LicenseType) *server.Server {
 	logger, _ := mlog.NewLogger()
-	if err = logger.Configure("", cfg.LoggingCfgJSON, nil); err != nil {
+	if err := logger.Configure("", cfg.LoggingCfgJSON, nil); err != nil {
 		panic(err)
 	}
 	singleUser := len(singleUserToken) > 0
@@ -325,6 +329,29 @@ func SetupTestHelperWithLicense(t *testing.T, licenseType LicenseType) *TestHelp
 	return th
 }
 
+// SetupTestHelperWithConfig sets up a test helper whose server
+// configuration is changed by the given function before starting it.
+func SetupTestHelperWithConfig(t *testing.T, changeConfig func(*config.Configuration)) *TestHelper {
+	origUnitTesting := os.Getenv("FOCALBOARD_UNIT_TESTING")
+	os.Setenv("FOCALBOARD_UNIT_TESTING", "1")
+
+	th := &TestHelper{
+		T:                  t,
+		origEnvUnitTesting: origUnitTesting,
+	}
+
+	cfg, err := getTestConfig()
+	if err != nil {
+		panic(err)
+	}
+	changeConfig(cfg)
+
+	th.Server = newTestServerWithConfig(cfg, "", LicenseNone)
+	th.Client = client.NewClient(th.Server.Config().ServerRoot, "")
+	th.Client2 = client.NewClient(th.Server.Config().ServerRoot, "")
+	return th
+}
+
 // Start starts the test server and ensures that it's correctly
 // responding to requests before returning.
 func (th *TestHelper) Start() *TestHelper {
@@ -380,6 +407,14 @@ func (th *TestHelper) InitBasic() *TestHelper {
 	// user2
 	th.RegisterAndLogin(th.Client2, user2Username, "user2@sample.com", password, team.SignupToken)
 
+	// both users are members of the test team
+	_, err := th.Server.Store().CreateTeam(&model.Team{ID: testTeamID, Title: "Test team"})
+	require.NoError(th.T, err)
+	for _, user := range []*model.User{th.GetUser1(), th.GetUser2()} {
+		_, err = th.Server.Store().SaveTeamMember(&model.TeamMember{TeamID: testTeamID, UserID: user.ID})
+		require.NoError(th.T, err)
+	}
+
 	return th
 }
 
@@ -551,6 +586,16 @@ func (th *TestHelper) CheckForbidden(r *client.Response) {
 	require.Error(th.T, r.Error)
 }
 
+// CheckConflict checks that a patch was rejected as it expected an
+// outdated version, and that the current version is returned.
+func (th *TestHelper) CheckConflict(r *client.Response, currentUpdateAt int64) {
+	require.Equal(th.T, http.StatusConflict, r.StatusCode)
+
+	var conflictErr client.ConflictError
+	require.ErrorAs(th.T, r.Error, &conflictErr)
+	require.Equal(th.T, currentUpdateAt, conflictErr.CurrentUpdateAt)
+}
+
 func (th *TestHelper) CheckRequestEntityTooLarge(r *client.Response) {
 	require.Equal(th.T, http.StatusRequestEntityTooLarge, r.StatusCode)
 	require.Error(th.T, r.Error)
@@ -560,3 +605,8 @@ func (th *TestHelper) CheckNotImplemented(r *client.Response) {
 	require.Equal(th.T, http.StatusNotImplemented, r.StatusCode)
 	require.Error(th.T, r.Error)
 }
+
+func (th *TestHelper) CheckTooManyRequests(r *client.Response) {
+	require.Equal(th.T, http.StatusTooManyRequests, r.StatusCode)
+	require.Error(th.T, r.Error)
+}
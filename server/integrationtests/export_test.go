@@ -16,7 +16,7 @@ func TestExportBoard(t *testing.T) {
 
 		board := &model.Board{
 			ID:        utils.NewID(utils.IDTypeBoard),
-			TeamID:    "test-team",
+			TeamID:    testTeamID,
 			Title:     "Export Test Board",
 			CreatedBy: th.GetUser1().ID,
 			Type:      model.BoardTypeOpen,
@@ -0,0 +1,87 @@
+package integrationtests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuests(t *testing.T) {
+	th, server := setupMail(t, false)
+	th.InitBasic()
+
+	t.Run("only system admins invite guests", func(t *testing.T) {
+		resp := th.Client.InviteToTeam(model.GlobalTeamID, []string{"guest@sample.com"}, model.TeamRoleGuest)
+		th.CheckForbidden(resp)
+	})
+
+	user1, err := th.Server.Store().GetUserByID(th.GetUser1().ID)
+	require.NoError(t, err)
+	user1.Roles = model.SystemUserRoleID + " " + model.SystemAdminRoleID
+	_, err = th.Server.Store().UpdateUser(user1)
+	require.NoError(t, err)
+
+	resp := th.Client.InviteToTeam(model.GlobalTeamID, []string{"guest@sample.com"}, model.TeamRoleGuest)
+	th.CheckOK(resp)
+
+	guestClient := client.NewClient(th.Client.URL, "")
+	_, resp = guestClient.Register(&model.RegisterRequest{
+		Username:    "guest",
+		Email:       "guest@sample.com",
+		Password:    password,
+		InviteToken: lastToken(t, server, "guest@sample.com"),
+	})
+	th.CheckOK(resp)
+	th.Login(guestClient, "guest", password)
+
+	me, resp := guestClient.GetMe()
+	th.CheckOK(resp)
+	require.True(t, me.IsGuest)
+
+	openBoard := th.CreateBoard(model.GlobalTeamID, model.BoardTypeOpen)
+	privateBoard := th.CreateBoard(model.GlobalTeamID, model.BoardTypePrivate)
+
+	t.Run("guests don't get the public boards", func(t *testing.T) {
+		boards, resp := guestClient.GetBoardsForTeam(model.GlobalTeamID)
+		th.CheckOK(resp)
+		require.Empty(t, boards)
+
+		_, resp = guestClient.GetBoard(openBoard.ID, "")
+		th.CheckForbidden(resp)
+	})
+
+	t.Run("guests can't be board admins", func(t *testing.T) {
+		member, resp := th.Client.AddMemberToBoard(&model.BoardMember{
+			BoardID:     privateBoard.ID,
+			UserID:      me.ID,
+			SchemeAdmin: true,
+		})
+		th.CheckOK(resp)
+		require.False(t, member.SchemeAdmin)
+
+		boards, resp := guestClient.GetBoardsForTeam(model.GlobalTeamID)
+		th.CheckOK(resp)
+		require.Len(t, boards, 1)
+		require.Equal(t, privateBoard.ID, boards[0].ID)
+	})
+
+	t.Run("guests only see the users of their boards", func(t *testing.T) {
+		_, resp := guestClient.GetUser(th.GetUser1().ID)
+		th.CheckOK(resp)
+
+		_, resp = guestClient.GetUser(th.GetUser2().ID)
+		th.CheckNotFound(resp)
+
+		_, resp = th.Client.AddMemberToBoard(&model.BoardMember{
+			BoardID:      privateBoard.ID,
+			UserID:       th.GetUser2().ID,
+			SchemeEditor: true,
+		})
+		th.CheckOK(resp)
+
+		_, resp = guestClient.GetUser(th.GetUser2().ID)
+		th.CheckOK(resp)
+	})
+}
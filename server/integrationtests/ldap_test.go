@@ -0,0 +1,226 @@
+package integrationtests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/ldap/ldaptest"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const ldapAdminGroup = "board-admins"
+
+func setupLDAP(t *testing.T) (*TestHelper, *ldaptest.Server) {
+	directory, err := ldaptest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(directory.Close)
+
+	ldapConfig := directory.Config()
+	ldapConfig.AdminGroups = []string{ldapAdminGroup}
+
+	th := SetupTestHelperWithConfig(t, func(cfg *config.Configuration) {
+		cfg.AuthMode = model.AuthModeLDAP
+		cfg.LDAP = ldapConfig
+	}).Start()
+	t.Cleanup(th.TearDown)
+
+	return th, directory
+}
+
+func (th *TestHelper) memberRoles(boardID string) map[string]*model.BoardMember {
+	members, resp := th.Client.GetMembersForBoard(boardID)
+	th.CheckOK(resp)
+
+	byUser := map[string]*model.BoardMember{}
+	for _, member := range members {
+		byUser[member.UserID] = member
+	}
+	return byUser
+}
+
+func TestLDAPLogin(t *testing.T) {
+	t.Run("provisions the user on first login", func(t *testing.T) {
+		th, directory := setupLDAP(t)
+		directory.AddUser("john", password, "john@example.com", "John", "Doe")
+
+		th.Login(th.Client, "john", password)
+		me := th.Me(th.Client)
+		require.Equal(t, "john", me.Username)
+		require.NotContains(t, me.Permissions, model.PermissionManageSystem.Id)
+
+		stored, err := th.Server.Store().GetUserByID(me.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.AuthModeLDAP, stored.AuthService)
+		require.Equal(t, "John", stored.FirstName)
+		require.Equal(t, "Doe", stored.LastName)
+
+		t.Run("logs in the same user again", func(t *testing.T) {
+			th.Login(th.Client2, "john", password)
+			require.Equal(t, me.ID, th.Me(th.Client2).ID)
+		})
+
+		t.Run("the root team is available", func(t *testing.T) {
+			team, resp := th.Client.GetTeam(model.GlobalTeamID)
+			th.CheckOK(resp)
+			require.Equal(t, model.GlobalTeamID, team.ID)
+		})
+
+		t.Run("a deactivated user isn't provisioned again", func(t *testing.T) {
+			stored.DeleteAt = utils.GetMillis()
+			_, err := th.Server.Store().UpdateUser(stored)
+			require.NoError(t, err)
+
+			_, resp := th.Client2.Login(&model.LoginRequest{
+				Type:     "normal",
+				Username: "john",
+				Password: password,
+			})
+			require.Error(t, resp.Error)
+
+			users, count, err := th.Server.Store().QueryUsers(model.QueryUsersOptions{Username: "john"})
+			require.NoError(t, err)
+			require.Equal(t, 1, count)
+			require.Equal(t, me.ID, users[0].ID)
+		})
+	})
+
+	t.Run("admin groups grant system admin rights", func(t *testing.T) {
+		th, directory := setupLDAP(t)
+		adminDN := directory.AddUser("admin", password, "admin@example.com", "Admin", "User")
+		directory.AddGroup(ldapAdminGroup, adminDN)
+
+		th.Login(th.Client, "admin", password)
+		require.Contains(t, th.Me(th.Client).Permissions, model.PermissionManageSystem.Id)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		th, directory := setupLDAP(t)
+		directory.AddUser("john", password, "john@example.com", "John", "Doe")
+
+		for _, credentials := range [][2]string{{"john", "wrong-password"}, {"john", ""}, {"jane", password}} {
+			_, resp := th.Client.Login(&model.LoginRequest{
+				Type:     "normal",
+				Username: credentials[0],
+				Password: credentials[1],
+			})
+			th.CheckUnauthorized(resp)
+		}
+	})
+
+	t.Run("native users still log in with their password", func(t *testing.T) {
+		th, _ := setupLDAP(t)
+
+		admin, err := th.Server.Store().CreateUser(&model.User{
+			ID:       utils.NewID(utils.IDTypeUser),
+			Username: "admin",
+			Email:    "admin@example.com",
+			Password: auth.HashPassword(password),
+		})
+		require.NoError(t, err)
+
+		th.Login(th.Client, "admin", password)
+		require.Equal(t, admin.ID, th.Me(th.Client).ID)
+
+		_, resp := th.Client2.Login(&model.LoginRequest{
+			Type:     "normal",
+			Email:    "admin@example.com",
+			Password: password,
+		})
+		th.CheckOK(resp)
+		require.Equal(t, admin.ID, th.Me(th.Client2).ID)
+	})
+
+	t.Run("registration and password changes are disabled", func(t *testing.T) {
+		th, directory := setupLDAP(t)
+		directory.AddUser("john", password, "john@example.com", "John", "Doe")
+
+		_, resp := th.Client.Register(&model.RegisterRequest{
+			Username: "jane",
+			Email:    "jane@example.com",
+			Password: password,
+		})
+		th.CheckNotImplemented(resp)
+
+		th.Login(th.Client, "john", password)
+		_, resp = th.Client.UserChangePassword(th.Me(th.Client).ID, &model.ChangePasswordRequest{
+			OldPassword: password,
+			NewPassword: "new-password",
+		})
+		th.CheckBadRequest(resp)
+	})
+}
+
+func TestLDAPSync(t *testing.T) {
+	th, directory := setupLDAP(t)
+	johnDN := directory.AddUser("john", password, "john@example.com", "John", "Doe")
+	janeDN := directory.AddUser("jane", password, "jane@example.com", "Jane", "Doe")
+	directory.AddUser("bob", password, "bob@example.com", "Bob", "Smith")
+
+	th.Login(th.Client, "john", password)
+	th.Login(th.Client2, "jane", password)
+	john := th.Me(th.Client)
+	jane := th.Me(th.Client2)
+
+	board := th.CreateBoard(model.GlobalTeamID, model.BoardTypePrivate)
+	th.Server.Config().LDAP.GroupBoards = []config.LDAPGroupBoard{
+		{Group: "developers", BoardID: board.ID, Role: string(model.BoardRoleEditor)},
+		{Group: "reviewers", BoardID: board.ID, Role: string(model.BoardRoleViewer)},
+	}
+
+	t.Run("updates the user attributes and roles", func(t *testing.T) {
+		directory.SetAttribute(janeDN, "sn", "Smith")
+		directory.AddGroup(ldapAdminGroup, janeDN)
+
+		require.NoError(t, th.Server.App().SyncLDAP())
+
+		stored, err := th.Server.Store().GetUserByID(jane.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Smith", stored.LastName)
+		require.True(t, stored.IsSystemAdmin())
+
+		directory.AddGroup(ldapAdminGroup)
+		require.NoError(t, th.Server.App().SyncLDAP())
+
+		stored, err = th.Server.Store().GetUserByID(jane.ID)
+		require.NoError(t, err)
+		require.False(t, stored.IsSystemAdmin())
+	})
+
+	t.Run("adds the group members to the mapped boards", func(t *testing.T) {
+		directory.AddGroup("developers", janeDN)
+		directory.AddGroup("reviewers", janeDN, johnDN)
+
+		require.NoError(t, th.Server.App().SyncLDAP())
+
+		members := th.memberRoles(board.ID)
+		require.Contains(t, members, jane.ID)
+		require.True(t, members[jane.ID].SchemeEditor)
+
+		// the board creator keeps their own membership
+		require.True(t, members[john.ID].SchemeAdmin)
+	})
+
+	t.Run("updates the roles of the group members", func(t *testing.T) {
+		directory.AddGroup("developers")
+
+		require.NoError(t, th.Server.App().SyncLDAP())
+
+		members := th.memberRoles(board.ID)
+		require.Contains(t, members, jane.ID)
+		require.False(t, members[jane.ID].SchemeEditor)
+		require.True(t, members[jane.ID].SchemeViewer)
+	})
+
+	t.Run("removes the users that left the groups", func(t *testing.T) {
+		directory.AddGroup("reviewers", johnDN)
+
+		require.NoError(t, th.Server.App().SyncLDAP())
+
+		members := th.memberRoles(board.ID)
+		require.NotContains(t, members, jane.ID)
+		require.Contains(t, members, john.ID)
+	})
+}
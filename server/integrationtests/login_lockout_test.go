@@ -0,0 +1,61 @@
+package integrationtests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginLockout(t *testing.T) {
+	th := SetupTestHelperWithConfig(t, func(cfg *config.Configuration) {
+		cfg.LoginLockout = config.LoginLockoutConfig{
+			MaxAccountFailures: 3,
+			MaxIPFailures:      10,
+			LockoutMinutes:     15,
+		}
+	}).InitBasic()
+	defer th.TearDown()
+
+	login := func(username, password string) (*model.LoginResponse, *client.Response) {
+		return th.Client2.Login(&model.LoginRequest{Type: "normal", Username: username, Password: password})
+	}
+
+	for i := 0; i < 2; i++ {
+		_, resp := login(user1Username, "bad-password")
+		th.CheckUnauthorized(resp)
+	}
+
+	t.Run("the account is locked after too many failures", func(t *testing.T) {
+		_, resp := login(user1Username, "bad-password")
+		th.CheckTooManyRequests(resp)
+		require.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+		_, resp = login(user1Username, password)
+		th.CheckTooManyRequests(resp)
+	})
+
+	t.Run("an admin unlocks the account", func(t *testing.T) {
+		require.NoError(t, th.Server.App().UnlockUser(user1Username))
+
+		data, resp := login(user1Username, password)
+		th.CheckOK(resp)
+		require.NotEmpty(t, data.Token)
+	})
+
+	t.Run("the IP address is locked after too many failures", func(t *testing.T) {
+		// 3 failures are above, and the 10th one locks the IP address
+		for i := 0; i < 6; i++ {
+			_, resp := login(fmt.Sprintf("unknown%d", i), "bad-password")
+			th.CheckUnauthorized(resp)
+		}
+		_, resp := login(user1Username, "bad-password")
+		th.CheckTooManyRequests(resp)
+
+		_, resp = login(user1Username, password)
+		th.CheckTooManyRequests(resp)
+	})
+}
@@ -0,0 +1,118 @@
+package integrationtests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func (th *TestHelper) loginWithMfa(mfaToken string) *client.Response {
+	_, resp := client.NewClient(th.Server.Config().ServerRoot, "").Login(&model.LoginRequest{
+		Type:     "normal",
+		Username: user1Username,
+		Password: password,
+		MfaToken: mfaToken,
+	})
+	return resp
+}
+
+func (th *TestHelper) activateMfa() (string, []string) {
+	enrollment, resp := th.Client.GenerateMfaSecret()
+	th.CheckOK(resp)
+	require.NotEmpty(th.T, enrollment.Secret)
+	require.Contains(th.T, enrollment.QRCodeURI, "otpauth://totp/")
+
+	code, err := auth.GenerateTotpCode(enrollment.Secret, time.Now())
+	require.NoError(th.T, err)
+
+	recoveryCodes, resp := th.Client.ActivateMfa(code)
+	th.CheckOK(resp)
+	require.Len(th.T, recoveryCodes.RecoveryCodes, auth.MfaRecoveryCodeCount)
+
+	return enrollment.Secret, recoveryCodes.RecoveryCodes
+}
+
+func TestMfa(t *testing.T) {
+	t.Run("enrolment requires a valid code", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		_, resp := th.Client.GenerateMfaSecret()
+		th.CheckOK(resp)
+
+		_, resp = th.Client.ActivateMfa("000000")
+		th.CheckBadRequest(resp)
+
+		require.False(t, th.Me(th.Client).MfaActive)
+		th.CheckOK(th.loginWithMfa(""))
+	})
+
+	t.Run("users with MFA cannot login without a valid code", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		secret, recoveryCodes := th.activateMfa()
+		require.True(t, th.Me(th.Client).MfaActive)
+
+		th.CheckUnauthorized(th.loginWithMfa(""))
+		th.CheckUnauthorized(th.loginWithMfa("000000"))
+
+		// the code used to activate MFA can't be used again
+		code, err := auth.GenerateTotpCode(secret, time.Now())
+		require.NoError(t, err)
+		th.CheckUnauthorized(th.loginWithMfa(code))
+
+		code, err = auth.GenerateTotpCode(secret, time.Now().Add(30*time.Second))
+		require.NoError(t, err)
+		th.CheckOK(th.loginWithMfa(code))
+		th.CheckUnauthorized(th.loginWithMfa(code))
+
+		th.CheckOK(th.loginWithMfa(recoveryCodes[0]))
+		th.CheckUnauthorized(th.loginWithMfa(recoveryCodes[0]))
+	})
+
+	t.Run("deactivate MFA", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		_, recoveryCodes := th.activateMfa()
+
+		th.CheckBadRequest(th.Client.DeactivateMfa("000000"))
+		th.CheckOK(th.Client.DeactivateMfa(recoveryCodes[0]))
+
+		require.False(t, th.Me(th.Client).MfaActive)
+		th.CheckOK(th.loginWithMfa(""))
+	})
+
+	t.Run("regenerate recovery codes", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		secret, oldRecoveryCodes := th.activateMfa()
+
+		code, err := auth.GenerateTotpCode(secret, time.Now().Add(30*time.Second))
+		require.NoError(t, err)
+		newRecoveryCodes, resp := th.Client.RegenerateMfaRecoveryCodes(code)
+		th.CheckOK(resp)
+		require.Len(t, newRecoveryCodes.RecoveryCodes, auth.MfaRecoveryCodeCount)
+
+		th.CheckUnauthorized(th.loginWithMfa(oldRecoveryCodes[1]))
+		th.CheckOK(th.loginWithMfa(newRecoveryCodes.RecoveryCodes[1]))
+	})
+
+	t.Run("access tokens cannot manage MFA", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		accessToken, resp := th.Client.CreateAccessToken(&model.AccessTokenCreateRequest{})
+		th.CheckOK(resp)
+		tokenClient := client.NewClient(th.Client.URL, accessToken.Token)
+
+		_, resp = tokenClient.GenerateMfaSecret()
+		th.CheckForbidden(resp)
+	})
+}
@@ -0,0 +1,185 @@
+package integrationtests
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/oidc/oidctest"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const oidcAdminRole = "board-admins"
+
+func setupOIDC(t *testing.T) (*TestHelper, *oidctest.Server) {
+	idp, err := oidctest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(idp.Close)
+
+	oidcConfig := idp.Config()
+	oidcConfig.AdminRoles = []string{oidcAdminRole}
+
+	th := SetupTestHelperWithConfig(t, func(cfg *config.Configuration) {
+		cfg.AuthMode = model.AuthModeOIDC
+		cfg.OIDC = oidcConfig
+		// the session cookie expires with the session, so the session
+		// lifetime must be a valid cookie max age.
+		cfg.SessionExpireTime = 60 * 60
+	}).Start()
+	t.Cleanup(th.TearDown)
+
+	return th, idp
+}
+
+// oidcLogin goes through the login redirects like a browser would and
+// returns the last response and the session token set by the server.
+func (th *TestHelper) oidcLogin(redirect string) (*http.Response, string) {
+	jar, err := cookiejar.New(nil)
+	require.NoError(th.T, err)
+
+	serverRoot := th.Server.Config().ServerRoot
+	rootURL, err := url.Parse(serverRoot + "/")
+	require.NoError(th.T, err)
+
+	httpClient := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, _ []*http.Request) error {
+			// stop once redirected back to the application
+			if req.URL.Host == rootURL.Host && !strings.HasPrefix(req.URL.Path, "/oidc/") {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	resp, err := httpClient.Get(serverRoot + "/oidc/login?redirect=" + url.QueryEscape(redirect))
+	require.NoError(th.T, err)
+	resp.Body.Close()
+
+	for _, cookie := range jar.Cookies(rootURL) {
+		if cookie.Name == auth.SessionCookieToken {
+			return resp, cookie.Value
+		}
+	}
+	return resp, ""
+}
+
+func TestOIDCLogin(t *testing.T) {
+	t.Run("provisions the user on first login", func(t *testing.T) {
+		th, idp := setupOIDC(t)
+		idp.SetUser(map[string]interface{}{
+			"sub":                "subject-id",
+			"email":              "john@example.com",
+			"preferred_username": "john",
+		})
+
+		resp, token := th.oidcLogin("/board")
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		require.Equal(t, "/board", resp.Header.Get("Location"))
+		require.NotEmpty(t, token)
+
+		me := th.Me(client.NewClient(th.Server.Config().ServerRoot, token))
+		require.Equal(t, "john", me.Username)
+		require.NotContains(t, me.Permissions, model.PermissionManageSystem.Id)
+
+		t.Run("logs in the same user again", func(t *testing.T) {
+			_, token2 := th.oidcLogin("/board")
+			require.NotEmpty(t, token2)
+			require.NotEqual(t, token, token2)
+
+			me2 := th.Me(client.NewClient(th.Server.Config().ServerRoot, token2))
+			require.Equal(t, me.ID, me2.ID)
+		})
+
+		t.Run("the root team is available", func(t *testing.T) {
+			team, resp := client.NewClient(th.Server.Config().ServerRoot, token).GetTeam(model.GlobalTeamID)
+			th.CheckOK(resp)
+			require.Equal(t, model.GlobalTeamID, team.ID)
+		})
+
+		t.Run("a deactivated user isn't provisioned again", func(t *testing.T) {
+			user, err := th.Server.Store().GetUserByID(me.ID)
+			require.NoError(t, err)
+			user.DeleteAt = utils.GetMillis()
+			_, err = th.Server.Store().UpdateUser(user)
+			require.NoError(t, err)
+
+			_, token := th.oidcLogin("/board")
+			require.Empty(t, token)
+
+			users, count, err := th.Server.Store().QueryUsers(model.QueryUsersOptions{Username: "john"})
+			require.NoError(t, err)
+			require.Equal(t, 1, count)
+			require.Equal(t, me.ID, users[0].ID)
+		})
+	})
+
+	t.Run("admin roles grant system admin rights", func(t *testing.T) {
+		th, idp := setupOIDC(t)
+		idp.SetUser(map[string]interface{}{
+			"sub":                "admin-id",
+			"preferred_username": "admin",
+			"roles":              []string{"users", oidcAdminRole},
+		})
+
+		_, token := th.oidcLogin("/")
+		require.NotEmpty(t, token)
+
+		me := th.Me(client.NewClient(th.Server.Config().ServerRoot, token))
+		require.Contains(t, me.Permissions, model.PermissionManageSystem.Id)
+
+		t.Run("rights are removed with the role", func(t *testing.T) {
+			idp.SetUser(map[string]interface{}{
+				"sub":                "admin-id",
+				"preferred_username": "admin",
+			})
+
+			_, token := th.oidcLogin("/")
+			me := th.Me(client.NewClient(th.Server.Config().ServerRoot, token))
+			require.NotContains(t, me.Permissions, model.PermissionManageSystem.Id)
+		})
+	})
+
+	t.Run("redirects outside of the server are ignored", func(t *testing.T) {
+		th, idp := setupOIDC(t)
+		idp.SetUser(map[string]interface{}{"sub": "subject-id"})
+
+		resp, token := th.oidcLogin("//example.com/")
+		require.NotEmpty(t, token)
+		require.Equal(t, "/", resp.Header.Get("Location"))
+	})
+
+	t.Run("callback without login state", func(t *testing.T) {
+		th, _ := setupOIDC(t)
+
+		resp, err := http.Get(th.Server.Config().ServerRoot + "/oidc/callback?code=code&state=state")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("password login is disabled", func(t *testing.T) {
+		th, _ := setupOIDC(t)
+
+		_, resp := th.Client.Login(&model.LoginRequest{
+			Type:     "normal",
+			Username: user1Username,
+			Password: password,
+		})
+		th.CheckNotImplemented(resp)
+
+		_, resp = th.Client.Register(&model.RegisterRequest{
+			Username: user1Username,
+			Email:    "user1@sample.com",
+			Password: password,
+		})
+		th.CheckNotImplemented(resp)
+	})
+}
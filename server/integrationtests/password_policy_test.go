@@ -0,0 +1,75 @@
+package integrationtests
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	th := SetupTestHelperWithConfig(t, func(cfg *config.Configuration) {
+		cfg.PasswordSettings = config.PasswordConfig{
+			MinimumLength: 8,
+			MaxAgeDays:    90,
+			HistoryDepth:  2,
+		}
+	}).InitBasic()
+	defer th.TearDown()
+
+	changePassword := func(c *client.Client, oldPassword, newPassword string) *client.Response {
+		_, resp := c.UserChangePassword(th.Me(th.Client).ID, &model.ChangePasswordRequest{
+			OldPassword: oldPassword,
+			NewPassword: newPassword,
+		})
+		return resp
+	}
+
+	t.Run("the client config exposes the policy", func(t *testing.T) {
+		clientConfig := th.Server.App().GetClientConfig()
+		require.Equal(t, 8, clientConfig.PasswordPolicy.MinimumLength)
+		require.False(t, clientConfig.PasswordPolicy.Number)
+		require.Equal(t, 90, clientConfig.PasswordPolicy.MaxAgeDays)
+		require.Equal(t, 2, clientConfig.PasswordPolicy.HistoryDepth)
+	})
+
+	t.Run("a password breaking the policy is refused", func(t *testing.T) {
+		th.CheckBadRequest(changePassword(th.Client, password, "short"))
+	})
+
+	t.Run("the recent passwords cannot be reused", func(t *testing.T) {
+		th.CheckBadRequest(changePassword(th.Client, password, password))
+
+		th.CheckOK(changePassword(th.Client, password, "Pa$$word1"))
+		th.CheckBadRequest(changePassword(th.Client, "Pa$$word1", password))
+
+		th.CheckOK(changePassword(th.Client, "Pa$$word1", "Pa$$word2"))
+		th.CheckOK(changePassword(th.Client, "Pa$$word2", "Pa$$word3"))
+
+		// the first password is now out of the history
+		th.CheckOK(changePassword(th.Client, "Pa$$word3", password))
+	})
+
+	t.Run("an expired password must be changed", func(t *testing.T) {
+		db, ok := th.Server.Store().(interface{ DBHandle() *sql.DB })
+		require.True(t, ok)
+		_, err := db.DBHandle().Exec("UPDATE test_users SET password_update_at = 0")
+		require.NoError(t, err)
+
+		otherClient := client.NewClient(th.Client.URL, "")
+		th.Login(otherClient, user1Username, password)
+
+		_, resp := otherClient.GetSessions()
+		th.CheckForbidden(resp)
+		th.Me(otherClient)
+
+		th.CheckOK(changePassword(otherClient, password, "Pa$$word4"))
+
+		sessions, resp := otherClient.GetSessions()
+		th.CheckOK(resp)
+		require.NotEmpty(t, sessions)
+	})
+}
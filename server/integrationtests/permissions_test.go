@@ -128,6 +128,16 @@ func setupLocalClients(th *TestHelper) Clients {
 	th.RegisterAndLogin(clients.Admin, userAdmin, userAdmin+"@sample.com", password, team.SignupToken)
 	userAdminID = clients.Admin.GetUserID()
 
+	// all the users but the no-team-member one belong to the test teams
+	for _, teamID := range []string{"test-team", "other-team"} {
+		_, err := th.Server.Store().CreateTeam(&model.Team{ID: teamID, Title: teamID})
+		require.NoError(th.T, err)
+		for _, userID := range []string{userTeamMemberID, userViewerID, userCommenterID, userEditorID, userAdminID} {
+			_, err = th.Server.Store().SaveTeamMember(&model.TeamMember{TeamID: teamID, UserID: userID})
+			require.NoError(th.T, err)
+		}
+	}
+
 	return clients
 }
 
@@ -360,8 +370,6 @@ func TestPermissionsGetTeamBoards(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -390,8 +398,6 @@ func TestPermissionsSearchTeamBoards(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -475,8 +481,6 @@ func TestPermissionsGetTeamTemplates(t *testing.T) {
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
 		extraSetup(t, th)
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -510,10 +514,6 @@ func TestPermissionsCreateBoard(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
-		ttCases[5].expectedStatusCode = http.StatusOK
-		ttCases[5].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -573,10 +573,6 @@ func TestPermissionsGetBoard(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[9].expectedStatusCode = http.StatusOK
-		ttCases[9].totalResults = 1
-		ttCases[25].expectedStatusCode = http.StatusOK
-		ttCases[25].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -926,8 +922,6 @@ func TestPermissionsDuplicateBoard(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[25].expectedStatusCode = http.StatusOK
-		ttCases[25].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 
@@ -981,8 +975,6 @@ func TestPermissionsDuplicateBoard(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[25].expectedStatusCode = http.StatusOK
-		ttCases[25].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 
@@ -1088,8 +1080,6 @@ func TestPermissionsGetBoardBlocks(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[25].expectedStatusCode = http.StatusOK
-		ttCases[25].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -2073,10 +2063,6 @@ func TestPermissionsJoinBoardAsMember(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[9].expectedStatusCode = http.StatusOK
-		ttCases[9].totalResults = 1
-		ttCases[25].expectedStatusCode = http.StatusOK
-		ttCases[25].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -2337,10 +2323,11 @@ func TestPermissionsListTeams(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[1].expectedStatusCode = http.StatusOK
+		// the root team and the test teams
 		for i := range ttCases {
-			ttCases[i].totalResults = 1
+			ttCases[i].totalResults = 3
 		}
+		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -2379,7 +2366,7 @@ func TestPermissionsGetTeam(t *testing.T) {
 		testData := setupData(t, th)
 		ttCases := []TestCase{
 			{"/teams/test-team", methodGet, "", userAnon, http.StatusUnauthorized, 0},
-			{"/teams/test-team", methodGet, "", userNoTeamMember, http.StatusOK, 1},
+			{"/teams/test-team", methodGet, "", userNoTeamMember, http.StatusForbidden, 0},
 			{"/teams/test-team", methodGet, "", userTeamMember, http.StatusOK, 1},
 			{"/teams/test-team", methodGet, "", userViewer, http.StatusOK, 1},
 			{"/teams/test-team", methodGet, "", userCommenter, http.StatusOK, 1},
@@ -2456,13 +2443,13 @@ func TestPermissionsGetTeamUsers(t *testing.T) {
 		testData := setupData(t, th)
 		ttCases := []TestCase{
 			{"/teams/test-team/users", methodGet, "", userAnon, http.StatusUnauthorized, 0},
-			{"/teams/test-team/users", methodGet, "", userNoTeamMember, http.StatusOK, 7},
-			{"/teams/test-team/users", methodGet, "", userTeamMember, http.StatusOK, 7},
-			{"/teams/test-team/users", methodGet, "", userViewer, http.StatusOK, 7},
-			{"/teams/test-team/users", methodGet, "", userCommenter, http.StatusOK, 7},
-			{"/teams/test-team/users", methodGet, "", userEditor, http.StatusOK, 7},
-			{"/teams/test-team/users", methodGet, "", userAdmin, http.StatusOK, 7},
-			{"/teams/test-team/users", methodGet, "", userGuest, http.StatusOK, 7},
+			{"/teams/test-team/users", methodGet, "", userNoTeamMember, http.StatusForbidden, 0},
+			{"/teams/test-team/users", methodGet, "", userTeamMember, http.StatusOK, 5},
+			{"/teams/test-team/users", methodGet, "", userViewer, http.StatusOK, 5},
+			{"/teams/test-team/users", methodGet, "", userCommenter, http.StatusOK, 5},
+			{"/teams/test-team/users", methodGet, "", userEditor, http.StatusOK, 5},
+			{"/teams/test-team/users", methodGet, "", userAdmin, http.StatusOK, 5},
+			{"/teams/test-team/users", methodGet, "", userGuest, http.StatusOK, 5},
 		}
 		runTestCases(t, ttCases, testData, clients)
 	})
@@ -2747,8 +2734,6 @@ func TestPermissionsCreateBoardsAndBlocks(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -3014,8 +2999,6 @@ func TestPermissionsGetCategories(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -3075,8 +3058,6 @@ func TestPermissionsCreateCategory(t *testing.T) {
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
 		ttCases := ttCasesF()
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -3181,8 +3162,6 @@ func TestPermissionsUpdateCategory(t *testing.T) {
 		testData := setupData(t, th)
 		extraData := extraSetup(t, th)
 		ttCases := ttCasesF(extraData)
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -3266,10 +3245,6 @@ func TestPermissionsDeleteCategory(t *testing.T) {
 		testData := setupData(t, th)
 		extraData := extraSetup(t, th)
 		ttCases := ttCasesF(extraData)
-		ttCases[1].expectedStatusCode = http.StatusBadRequest
-		ttCases[1].totalResults = 0
-		ttCases[9].expectedStatusCode = http.StatusOK
-		ttCases[9].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -3344,8 +3319,6 @@ func TestPermissionsUpdateCategoryBoard(t *testing.T) {
 		testData := setupData(t, th)
 		extraData := extraSetup(t, th)
 		ttCases := ttCasesF(testData, extraData)
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 0
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -3581,8 +3554,6 @@ func TestPermissionsOnboard(t *testing.T) {
 		err := th.Server.App().InitTemplates()
 		require.NoError(t, err, "InitTemplates should not fail")
 
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -3666,8 +3637,6 @@ func TestPermissionsBoardArchiveImport(t *testing.T) {
 		defer th.TearDown()
 		clients := setupLocalClients(th)
 		testData := setupData(t, th)
-		ttCases[1].expectedStatusCode = http.StatusOK
-		ttCases[1].totalResults = 1
 		runTestCases(t, ttCases, testData, clients)
 	})
 }
@@ -0,0 +1,164 @@
+package integrationtests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertyAccess(t *testing.T) {
+	th := SetupTestHelper(t).InitBasic()
+	defer th.TearDown()
+
+	board, resp := th.Client.CreateBoard(&model.Board{
+		TeamID: testTeamID,
+		Type:   model.BoardTypePrivate,
+		CardProperties: []map[string]interface{}{
+			{"id": "status", "name": "Status", "type": "text"},
+			{
+				"id":   "salary",
+				"name": "Salary",
+				"type": "number",
+				"access": map[string]interface{}{
+					"view": []interface{}{"admin"},
+				},
+			},
+			{
+				"id":   "estimate",
+				"name": "Estimate",
+				"type": "number",
+				"access": map[string]interface{}{
+					"edit": []interface{}{"admin"},
+				},
+			},
+		},
+	})
+	th.CheckOK(resp)
+
+	_, resp = th.Client.AddMemberToBoard(&model.BoardMember{
+		BoardID:      board.ID,
+		UserID:       th.GetUser2().ID,
+		SchemeEditor: true,
+	})
+	th.CheckOK(resp)
+
+	card, resp := th.Client.CreateCard(board.ID, &model.Card{
+		Title:      "card",
+		Properties: map[string]any{"status": "todo", "salary": "100", "estimate": "3"},
+	}, true)
+	th.CheckOK(resp)
+
+	t.Run("hidden values are left out", func(t *testing.T) {
+		userCard, resp := th.Client2.GetCard(card.ID)
+		th.CheckOK(resp)
+		require.Equal(t, map[string]any{"status": "todo", "estimate": "3"}, userCard.Properties)
+
+		blocks, resp := th.Client2.GetBlocksForBoard(board.ID)
+		th.CheckOK(resp)
+		for _, block := range blocks {
+			if block.ID == card.ID {
+				require.NotContains(t, block.Fields["properties"], "salary")
+			}
+		}
+
+		adminCard, resp := th.Client.GetCard(card.ID)
+		th.CheckOK(resp)
+		require.Equal(t, "100", adminCard.Properties["salary"])
+	})
+
+	t.Run("editing the visible values keeps the hidden ones", func(t *testing.T) {
+		_, resp := th.Client2.PatchCard(card.ID, &model.CardPatch{
+			UpdatedProperties: map[string]any{"status": "done", "estimate": "3"},
+		}, true)
+		th.CheckOK(resp)
+
+		adminCard, resp := th.Client.GetCard(card.ID)
+		th.CheckOK(resp)
+		require.Equal(t, map[string]any{"status": "done", "salary": "100", "estimate": "3"}, adminCard.Properties)
+	})
+
+	t.Run("read only values can't be changed", func(t *testing.T) {
+		_, resp := th.Client2.PatchCard(card.ID, &model.CardPatch{
+			UpdatedProperties: map[string]any{"estimate": "5"},
+		}, true)
+		th.CheckForbidden(resp)
+
+		_, resp = th.Client2.PatchCard(card.ID, &model.CardPatch{
+			UpdatedProperties: map[string]any{"salary": "200"},
+		}, true)
+		th.CheckForbidden(resp)
+	})
+
+	t.Run("duplicates leave out the hidden values and private cards", func(t *testing.T) {
+		privateCard, resp := th.Client.CreateCard(board.ID, &model.Card{
+			Title:     "private card",
+			VisibleTo: []string{string(model.BoardRoleAdmin)},
+		}, true)
+		th.CheckOK(resp)
+
+		now := utils.GetMillis()
+		_, resp = th.Client.InsertBlocks(board.ID, []*model.Block{
+			{
+				ID:       utils.NewID(utils.IDTypeBlock),
+				BoardID:  board.ID,
+				ParentID: privateCard.ID,
+				Type:     model.TypeText,
+				Title:    "private text",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+		}, true)
+		th.CheckOK(resp)
+
+		duplicate, resp := th.Client2.DuplicateBoard(board.ID, false, testTeamID)
+		th.CheckOK(resp)
+		require.Len(t, duplicate.Boards, 1)
+
+		blocks, resp := th.Client2.GetBlocksForBoard(duplicate.Boards[0].ID)
+		th.CheckOK(resp)
+		cards := 0
+		for _, block := range blocks {
+			require.NotEqual(t, "private card", block.Title)
+			require.NotEqual(t, "private text", block.Title)
+			if block.Type == model.TypeCard {
+				cards++
+				require.Equal(t, map[string]any{"status": "done", "estimate": "3"}, block.Fields["properties"])
+			}
+		}
+		require.Equal(t, 1, cards)
+
+		t.Run("the admins copy everything", func(t *testing.T) {
+			duplicate, resp := th.Client.DuplicateBoard(board.ID, false, testTeamID)
+			th.CheckOK(resp)
+
+			blocks, resp := th.Client.GetBlocksForBoard(duplicate.Boards[0].ID)
+			th.CheckOK(resp)
+			titles := []string{}
+			for _, block := range blocks {
+				titles = append(titles, block.Title)
+			}
+			require.Contains(t, titles, "private card")
+			require.Contains(t, titles, "private text")
+		})
+	})
+
+	t.Run("only board admins change the access rules", func(t *testing.T) {
+		_, resp := th.Client2.PatchBoard(board.ID, &model.BoardPatch{
+			DeletedCardProperties: []string{"salary"},
+		})
+		th.CheckForbidden(resp)
+
+		_, resp = th.Client.PatchBoard(board.ID, &model.BoardPatch{
+			UpdatedCardProperties: []map[string]interface{}{
+				{"id": "salary", "name": "Salary", "type": "number"},
+			},
+		})
+		th.CheckOK(resp)
+
+		userCard, resp := th.Client2.GetCard(card.ID)
+		th.CheckOK(resp)
+		require.Equal(t, "100", userCard.Properties["salary"])
+	})
+}
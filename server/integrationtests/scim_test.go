@@ -0,0 +1,176 @@
+package integrationtests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/stretchr/testify/require"
+)
+
+const scimToken = "scim-provisioning-token"
+
+// scimRequest sends a SCIM request with the given token, decoding the
+// response into v if it isn't nil, and returns the status code.
+func scimRequest(t *testing.T, th *TestHelper, token, method, path string, body, v interface{}) int {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+
+	req, err := http.NewRequest(method, th.Server.Config().ServerRoot+"/scim/v2"+path, bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/scim+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if v != nil && resp.StatusCode < 300 {
+		require.Equal(t, "application/scim+json", resp.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func scimFilter(filter string) string {
+	return "?filter=" + url.QueryEscape(filter)
+}
+
+func TestSCIMDisabled(t *testing.T) {
+	th := SetupTestHelper(t).Start()
+	defer th.TearDown()
+
+	require.Equal(t, http.StatusNotImplemented, scimRequest(t, th, scimToken, http.MethodGet, "/Users", nil, nil))
+}
+
+func TestSCIM(t *testing.T) {
+	th := SetupTestHelperWithConfig(t, func(cfg *config.Configuration) {
+		cfg.SCIM.Token = scimToken
+	}).InitBasic()
+	defer th.TearDown()
+
+	t.Run("the provisioning token is required", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, scimRequest(t, th, "", http.MethodGet, "/Users", nil, nil))
+		require.Equal(t, http.StatusUnauthorized, scimRequest(t, th, "bad-token", http.MethodGet, "/Users", nil, nil))
+
+		var config map[string]interface{}
+		require.Equal(t, http.StatusOK, scimRequest(t, th, scimToken, http.MethodGet, "/ServiceProviderConfig", nil, &config))
+		require.Contains(t, config["schemas"], model.SCIMSchemaServiceProviderConfig)
+	})
+
+	active := true
+	var user model.SCIMUser
+	status := scimRequest(t, th, scimToken, http.MethodPost, "/Users", &model.SCIMUser{
+		Schemas:  []string{model.SCIMSchemaUser},
+		UserName: "alice",
+		Name:     &model.SCIMName{GivenName: "Alice", FamilyName: "Smith"},
+		Emails:   []model.SCIMEmail{{Value: "alice@example.com", Primary: true}},
+		Password: "Alice-Pa$$word",
+		Active:   &active,
+	}, &user)
+	require.Equal(t, http.StatusCreated, status)
+	require.NotEmpty(t, user.ID)
+
+	aliceClient := client.NewClient(th.Client.URL, "")
+	th.Login(aliceClient, "alice", "Alice-Pa$$word")
+
+	t.Run("users are looked up with a filter", func(t *testing.T) {
+		var list model.SCIMListResponse
+		require.Equal(t, http.StatusOK, scimRequest(t, th, scimToken, http.MethodGet, "/Users"+scimFilter(`userName eq "alice"`), nil, &list))
+		require.Equal(t, 1, list.TotalResults)
+
+		list = model.SCIMListResponse{}
+		require.Equal(t, http.StatusOK, scimRequest(t, th, scimToken, http.MethodGet, "/Users?count=1&startIndex=2", nil, &list))
+		require.Equal(t, 3, list.TotalResults)
+		require.Equal(t, 1, list.ItemsPerPage)
+
+		require.Equal(t, http.StatusBadRequest, scimRequest(t, th, scimToken, http.MethodGet, "/Users"+scimFilter(`userName sw "a"`), nil, nil))
+	})
+
+	t.Run("the userName must be unique", func(t *testing.T) {
+		status := scimRequest(t, th, scimToken, http.MethodPost, "/Users", &model.SCIMUser{UserName: "alice"}, nil)
+		require.Equal(t, http.StatusConflict, status)
+	})
+
+	var group model.SCIMGroup
+	t.Run("groups are teams", func(t *testing.T) {
+		status := scimRequest(t, th, scimToken, http.MethodPost, "/Groups", &model.SCIMGroup{
+			Schemas:     []string{model.SCIMSchemaGroup},
+			DisplayName: "Engineering",
+			Members:     []model.SCIMMember{{Value: user.ID}},
+		}, &group)
+		require.Equal(t, http.StatusCreated, status)
+
+		team, err := th.Server.App().GetTeam(group.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Engineering", team.Title)
+
+		var got model.SCIMUser
+		require.Equal(t, http.StatusOK, scimRequest(t, th, scimToken, http.MethodGet, "/Users/"+user.ID, nil, &got))
+		require.Len(t, got.Groups, 1)
+		require.Equal(t, group.ID, got.Groups[0].Value)
+
+		var list model.SCIMListResponse
+		path := "/Groups" + scimFilter(`displayName eq "Engineering"`) + "&excludedAttributes=members"
+		require.Equal(t, http.StatusOK, scimRequest(t, th, scimToken, http.MethodGet, path, nil, &list))
+		require.Equal(t, 1, list.TotalResults)
+	})
+
+	t.Run("members are removed with a patch", func(t *testing.T) {
+		patch := &model.SCIMPatchRequest{
+			Schemas: []string{model.SCIMSchemaPatchOp},
+			Operations: []model.SCIMPatchOperation{
+				{Op: "remove", Path: fmt.Sprintf(`members[value eq "%s"]`, user.ID)},
+			},
+		}
+		var got model.SCIMGroup
+		require.Equal(t, http.StatusOK, scimRequest(t, th, scimToken, http.MethodPatch, "/Groups/"+group.ID, patch, &got))
+		require.Empty(t, got.Members)
+	})
+
+	t.Run("a deactivated user is logged out", func(t *testing.T) {
+		patch := &model.SCIMPatchRequest{
+			Schemas: []string{model.SCIMSchemaPatchOp},
+			Operations: []model.SCIMPatchOperation{
+				{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)},
+			},
+		}
+		var got model.SCIMUser
+		require.Equal(t, http.StatusOK, scimRequest(t, th, scimToken, http.MethodPatch, "/Users/"+user.ID, patch, &got))
+		require.False(t, *got.Active)
+
+		_, resp := aliceClient.GetMe()
+		th.CheckUnauthorized(resp)
+
+		_, resp = aliceClient.Login(&model.LoginRequest{Type: "normal", Username: "alice", Password: "Alice-Pa$$word"})
+		th.CheckUnauthorized(resp)
+
+		// deactivated users are still provisioned
+		require.Equal(t, http.StatusOK, scimRequest(t, th, scimToken, http.MethodGet, "/Users/"+user.ID, nil, &got))
+	})
+
+	t.Run("a reactivated user can log in", func(t *testing.T) {
+		user.Active = &active
+		user.Password = ""
+		require.Equal(t, http.StatusOK, scimRequest(t, th, scimToken, http.MethodPut, "/Users/"+user.ID, &user, nil))
+
+		th.Login(aliceClient, "alice", "Alice-Pa$$word")
+	})
+
+	t.Run("delete a group", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, scimRequest(t, th, scimToken, http.MethodDelete, "/Groups/"+group.ID, nil, nil))
+		require.Equal(t, http.StatusNotFound, scimRequest(t, th, scimToken, http.MethodGet, "/Groups/"+group.ID, nil, nil))
+	})
+}
@@ -0,0 +1,105 @@
+package integrationtests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	t.Run("a non authenticated client should be rejected", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		th.Logout(th.Client)
+
+		sessions, resp := th.Client.GetSessions()
+		th.CheckUnauthorized(resp)
+		require.Nil(t, sessions)
+	})
+
+	th := SetupTestHelper(t).InitBasic()
+	defer th.TearDown()
+
+	sessions, resp := th.Client.GetSessions()
+	th.CheckOK(resp)
+	require.Len(t, sessions, 1)
+	currentSessionID := sessions[0].ID
+
+	// logs in user1 on another device
+	login := func() (*client.Client, string) {
+		otherClient := client.NewClient(th.Client.URL, "")
+		th.Login(otherClient, user1Username, password)
+
+		sessions, resp := otherClient.GetSessions()
+		th.CheckOK(resp)
+		for _, session := range sessions {
+			if session.ID != currentSessionID {
+				return otherClient, session.ID
+			}
+		}
+		require.Fail(t, "the session of the other device was not found")
+		return nil, ""
+	}
+
+	t.Run("list the sessions", func(t *testing.T) {
+		_, otherSessionID := login()
+
+		sessions, resp := th.Client.GetSessions()
+		th.CheckOK(resp)
+		require.Len(t, sessions, 2)
+
+		for _, session := range sessions {
+			require.Contains(t, []string{currentSessionID, otherSessionID}, session.ID)
+			require.Empty(t, session.Token)
+			require.Equal(t, "127.0.0.1", session.IPAddress)
+			require.NotEmpty(t, session.UserAgent)
+			require.NotZero(t, session.UpdateAt)
+		}
+
+		th.CheckOK(th.Client.RevokeSession(otherSessionID))
+	})
+
+	t.Run("revoke a session", func(t *testing.T) {
+		otherClient, otherSessionID := login()
+
+		th.CheckOK(th.Client.RevokeSession(otherSessionID))
+
+		_, resp := otherClient.GetMe()
+		th.CheckUnauthorized(resp)
+		th.Me(th.Client)
+	})
+
+	t.Run("cannot revoke the sessions of other users", func(t *testing.T) {
+		sessions, resp := th.Client2.GetSessions()
+		th.CheckOK(resp)
+		require.NotEmpty(t, sessions)
+
+		th.CheckNotFound(th.Client.RevokeSession(sessions[0].ID))
+		th.Me(th.Client2)
+	})
+
+	t.Run("changing the password revokes the other sessions", func(t *testing.T) {
+		otherClient, _ := login()
+
+		_, resp := th.Client.UserChangePassword(th.Me(th.Client).ID, &model.ChangePasswordRequest{
+			OldPassword: password,
+			NewPassword: "New-Pa$$word",
+		})
+		th.CheckOK(resp)
+
+		_, resp = otherClient.GetMe()
+		th.CheckUnauthorized(resp)
+		th.Me(th.Client)
+	})
+
+	t.Run("an admin revokes all the sessions of a user", func(t *testing.T) {
+		require.NoError(t, th.Server.App().RevokeAllUserSessions(user1Username))
+
+		_, resp := th.Client.GetMe()
+		th.CheckUnauthorized(resp)
+		th.Me(th.Client2)
+	})
+}
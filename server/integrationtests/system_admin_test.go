@@ -0,0 +1,107 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func (th *TestHelper) makeSystemAdmin(userID string) {
+	user, err := th.Server.Store().GetUserByID(userID)
+	require.NoError(th.T, err)
+	user.Roles = model.SystemUserRoleID + " " + model.SystemAdminRoleID
+	_, err = th.Server.Store().UpdateUser(user)
+	require.NoError(th.T, err)
+}
+
+func (th *TestHelper) getTeamUsers(userIDs []string) []*model.User {
+	var r *http.Response
+	var err error
+	if userIDs == nil {
+		r, err = th.Client.DoAPIGet("/teams/"+testTeamID+"/users", "")
+	} else {
+		r, err = th.Client.DoAPIPost("/teams/"+testTeamID+"/users", toJSON(th.T, userIDs))
+	}
+	require.NoError(th.T, err)
+	defer r.Body.Close()
+
+	var users []*model.User
+	require.NoError(th.T, json.NewDecoder(r.Body).Decode(&users))
+	return users
+}
+
+func userPermissions(users []*model.User, userID string) []string {
+	for _, user := range users {
+		if user.ID == userID {
+			return user.Permissions
+		}
+	}
+	return nil
+}
+
+func TestSystemAdminPermissions(t *testing.T) {
+	th := SetupTestHelper(t).InitBasic()
+	defer th.TearDown()
+
+	user1ID := th.GetUser1().ID
+	user2ID := th.GetUser2().ID
+	th.makeSystemAdmin(user1ID)
+
+	t.Run("the permissions of the users", func(t *testing.T) {
+		require.Contains(t, th.Me(th.Client).Permissions, model.PermissionManageSystem.Id)
+		require.NotContains(t, th.Me(th.Client2).Permissions, model.PermissionManageSystem.Id)
+
+		for _, users := range [][]*model.User{th.getTeamUsers(nil), th.getTeamUsers([]string{user1ID, user2ID})} {
+			require.Contains(t, userPermissions(users, user1ID), model.PermissionManageSystem.Id)
+			require.NotContains(t, userPermissions(users, user2ID), model.PermissionManageSystem.Id)
+		}
+	})
+
+	t.Run("only system admins see the full names of the other users", func(t *testing.T) {
+		for _, userID := range []string{user1ID, user2ID} {
+			user, err := th.Server.Store().GetUserByID(userID)
+			require.NoError(t, err)
+			user.FirstName = "First"
+			user.LastName = "Last"
+			_, err = th.Server.Store().UpdateUser(user)
+			require.NoError(t, err)
+		}
+
+		user, resp := th.Client.GetUser(user2ID)
+		th.CheckOK(resp)
+		require.Equal(t, "First", user.FirstName)
+
+		user, resp = th.Client2.GetUser(user1ID)
+		th.CheckOK(resp)
+		require.Empty(t, user.FirstName)
+
+		users, resp := th.Client.GetUserList([]string{user2ID})
+		th.CheckOK(resp)
+		require.Len(t, users, 1)
+		require.Equal(t, "Last", users[0].LastName)
+
+		users, resp = th.Client2.GetUserList([]string{user1ID})
+		th.CheckOK(resp)
+		require.Len(t, users, 1)
+		require.Empty(t, users[0].LastName)
+	})
+
+	t.Run("the compliance exports need a license", func(t *testing.T) {
+		_, resp := th.Client.GetBoardsForCompliance(testTeamID, 0, 0)
+		th.CheckNotImplemented(resp)
+
+		_, resp = th.Client2.GetBoardsForCompliance(testTeamID, 0, 0)
+		th.CheckUnauthorized(resp)
+	})
+
+	t.Run("system admins can't export boards they can't access without a license", func(t *testing.T) {
+		board := th.CreateBoard(testTeamID, model.BoardTypePrivate)
+		th.makeSystemAdmin(user2ID)
+
+		_, resp := th.Client2.ExportBoardArchive(board.ID)
+		th.CheckForbidden(resp)
+	})
+}
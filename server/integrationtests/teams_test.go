@@ -0,0 +1,152 @@
+package integrationtests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func teamIDs(teams []*model.Team) []string {
+	ids := make([]string, 0, len(teams))
+	for _, team := range teams {
+		ids = append(ids, team.ID)
+	}
+	return ids
+}
+
+func TestTeamInvites(t *testing.T) {
+	t.Run("email not configured", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		_, err := th.Server.Store().SaveTeamMember(&model.TeamMember{TeamID: testTeamID, UserID: th.GetUser1().ID, Roles: model.TeamRoleAdmin})
+		require.NoError(t, err)
+
+		resp := th.Client.InviteToTeam(testTeamID, []string{"jane@sample.com"}, "")
+		th.CheckNotImplemented(resp)
+	})
+
+	th, server := setupMail(t, false)
+	th.InitBasic()
+
+	t.Run("only system admins create teams", func(t *testing.T) {
+		_, resp := th.Client.CreateTeam("Engineering")
+		th.CheckForbidden(resp)
+	})
+
+	user1, err := th.Server.Store().GetUserByID(th.GetUser1().ID)
+	require.NoError(t, err)
+	user1.Roles = model.SystemUserRoleID + " " + model.SystemAdminRoleID
+	_, err = th.Server.Store().UpdateUser(user1)
+	require.NoError(t, err)
+
+	team, resp := th.Client.CreateTeam("Engineering")
+	th.CheckOK(resp)
+	require.Equal(t, "Engineering", team.Title)
+
+	t.Run("the creator is the team admin", func(t *testing.T) {
+		members, resp := th.Client.GetTeamMembers(team.ID)
+		th.CheckOK(resp)
+		require.Len(t, members, 1)
+		require.Equal(t, user1.ID, members[0].UserID)
+		require.True(t, members[0].IsAdmin())
+	})
+
+	t.Run("other users don't have access to the team", func(t *testing.T) {
+		_, resp := th.Client2.GetTeam(team.ID)
+		th.CheckForbidden(resp)
+
+		teams, resp := th.Client2.GetTeams()
+		th.CheckOK(resp)
+		require.NotContains(t, teamIDs(teams), team.ID)
+
+		resp = th.Client2.InviteToTeam(team.ID, []string{"jane@sample.com"}, "")
+		th.CheckForbidden(resp)
+	})
+
+	resp = th.Client.InviteToTeam(team.ID, []string{"user2@sample.com", "jane@sample.com"}, "")
+	th.CheckOK(resp)
+
+	t.Run("an existing user accepts an invite", func(t *testing.T) {
+		token := lastToken(t, server, "user2@sample.com")
+
+		// the invite was sent to another address
+		_, resp := th.Client.AcceptTeamInvite(token)
+		th.CheckBadRequest(resp)
+
+		joined, resp := th.Client2.AcceptTeamInvite(token)
+		th.CheckOK(resp)
+		require.Equal(t, team.ID, joined.ID)
+
+		_, resp = th.Client2.GetTeam(team.ID)
+		th.CheckOK(resp)
+
+		// invites are single use
+		_, resp = th.Client2.AcceptTeamInvite(token)
+		th.CheckBadRequest(resp)
+	})
+
+	janeClient := client.NewClient(th.Client.URL, "")
+	t.Run("a new user registers with an invite", func(t *testing.T) {
+		token := lastToken(t, server, "jane@sample.com")
+
+		_, resp := janeClient.Register(&model.RegisterRequest{
+			Username:    "jane",
+			Email:       "bob@sample.com",
+			Password:    password,
+			InviteToken: token,
+		})
+		th.CheckBadRequest(resp)
+
+		_, resp = janeClient.Register(&model.RegisterRequest{
+			Username:    "jane",
+			Email:       "jane@sample.com",
+			Password:    password,
+			InviteToken: token,
+		})
+		th.CheckOK(resp)
+
+		th.Login(janeClient, "jane", password)
+		teams, resp := janeClient.GetTeams()
+		th.CheckOK(resp)
+		require.ElementsMatch(t, []string{model.GlobalTeamID, team.ID}, teamIDs(teams))
+	})
+
+	t.Run("only team admins remove other members", func(t *testing.T) {
+		resp := th.Client2.RemoveTeamMember(team.ID, user1.ID)
+		th.CheckForbidden(resp)
+
+		resp = th.Client.RemoveTeamMember(team.ID, janeClient.GetUserID())
+		th.CheckOK(resp)
+
+		_, resp = janeClient.GetTeam(team.ID)
+		th.CheckForbidden(resp)
+	})
+
+	t.Run("members leave the team", func(t *testing.T) {
+		board, err := th.Server.App().CreateBoard(&model.Board{TeamID: team.ID, Type: model.BoardTypePrivate, Title: "Board"}, th.GetUser2().ID, true)
+		require.NoError(t, err)
+
+		resp := th.Client2.RemoveTeamMember(team.ID, th.GetUser2().ID)
+		th.CheckOK(resp)
+
+		_, resp = th.Client2.GetBoard(board.ID, "")
+		th.CheckForbidden(resp)
+
+		members, resp := th.Client.GetTeamMembers(team.ID)
+		th.CheckOK(resp)
+		require.Len(t, members, 1)
+	})
+
+	t.Run("the last admin can't leave the team", func(t *testing.T) {
+		resp := th.Client.RemoveTeamMember(team.ID, user1.ID)
+		th.CheckBadRequest(resp)
+	})
+
+	t.Run("users can't leave the root team", func(t *testing.T) {
+		resp := th.Client2.RemoveTeamMember(model.GlobalTeamID, th.GetUser2().ID)
+		th.CheckBadRequest(resp)
+	})
+}
@@ -0,0 +1,133 @@
+package integrationtests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeactivateUser(t *testing.T) {
+	th := SetupTestHelper(t).InitBasic()
+	defer th.TearDown()
+
+	user1ID := th.GetUser1().ID
+	user2ID := th.GetUser2().ID
+
+	board, resp := th.Client2.CreateBoard(&model.Board{
+		TeamID: testTeamID,
+		Type:   model.BoardTypePrivate,
+		CardProperties: []map[string]interface{}{
+			{"id": "owner", "name": "Owner", "type": "person"},
+			{"id": "reviewers", "name": "Reviewers", "type": "multiPerson"},
+		},
+	})
+	th.CheckOK(resp)
+
+	card, resp := th.Client2.CreateCard(board.ID, &model.Card{
+		Title:      "card",
+		Properties: map[string]any{"owner": user2ID, "reviewers": []any{user2ID}},
+	}, true)
+	th.CheckOK(resp)
+
+	_, resp = th.Client2.CreateSubscription(&model.Subscription{
+		BlockType:      model.TypeCard,
+		BlockID:        card.ID,
+		SubscriberType: model.SubTypeUser,
+		SubscriberID:   user2ID,
+	})
+	th.CheckOK(resp)
+
+	// user2 administers this board through a custom role only
+	roleBoard, err := th.Server.Store().InsertBoard(&model.Board{
+		ID:     utils.NewID(utils.IDTypeBoard),
+		TeamID: testTeamID,
+		Type:   model.BoardTypePrivate,
+	}, user2ID)
+	require.NoError(t, err)
+	role, err := th.Server.Store().SaveCustomBoardRole(&model.CustomBoardRole{
+		ID:          utils.NewID(utils.IDTypeNone),
+		BoardID:     roleBoard.ID,
+		Name:        "Board manager",
+		Permissions: []string{model.PermissionManageBoardRoles.Id},
+		CreatedBy:   user2ID,
+	})
+	require.NoError(t, err)
+	_, err = th.Server.Store().SaveMember(&model.BoardMember{
+		BoardID:      roleBoard.ID,
+		UserID:       user2ID,
+		SchemeViewer: true,
+		CustomRoleID: role.ID,
+	})
+	require.NoError(t, err)
+
+	t.Run("only system admins deactivate users", func(t *testing.T) {
+		_, resp := th.Client.DeactivateUser(user2ID, &model.DeactivateUserRequest{TransferTo: user1ID})
+		th.CheckForbidden(resp)
+	})
+
+	user1, err := th.Server.Store().GetUserByID(user1ID)
+	require.NoError(t, err)
+	user1.Roles = model.SystemUserRoleID + " " + model.SystemAdminRoleID
+	_, err = th.Server.Store().UpdateUser(user1)
+	require.NoError(t, err)
+
+	t.Run("the boards must be transferred to another user", func(t *testing.T) {
+		_, resp := th.Client.DeactivateUser(user2ID, &model.DeactivateUserRequest{})
+		th.CheckBadRequest(resp)
+
+		_, resp = th.Client.DeactivateUser(user2ID, &model.DeactivateUserRequest{TransferTo: user2ID})
+		th.CheckBadRequest(resp)
+
+		_, resp = th.Client.DeactivateUser(user2ID, &model.DeactivateUserRequest{TransferTo: "nonexistent"})
+		th.CheckBadRequest(resp)
+	})
+
+	report, resp := th.Client.DeactivateUser(user2ID, &model.DeactivateUserRequest{
+		TransferTo:               user1ID,
+		ReassignPersonProperties: true,
+	})
+	th.CheckOK(resp)
+	require.Equal(t, user2ID, report.UserID)
+	require.ElementsMatch(t, []string{board.ID, roleBoard.ID}, report.TransferredBoards)
+	require.Equal(t, []string{card.ID}, report.UpdatedCards)
+	require.Equal(t, 1, report.RemovedSubscriptions)
+	require.NotZero(t, report.RevokedSessions)
+
+	t.Run("the user is logged out", func(t *testing.T) {
+		_, resp := th.Client2.GetMe()
+		th.CheckUnauthorized(resp)
+	})
+
+	t.Run("the new admin manages the boards", func(t *testing.T) {
+		for _, boardID := range []string{board.ID, roleBoard.ID} {
+			members, resp := th.Client.GetMembersForBoard(boardID)
+			th.CheckOK(resp)
+			admins := []string{}
+			for _, member := range members {
+				if member.SchemeAdmin {
+					admins = append(admins, member.UserID)
+				}
+			}
+			require.Equal(t, []string{user1ID}, admins)
+		}
+	})
+
+	t.Run("the person properties are reassigned", func(t *testing.T) {
+		updatedCard, resp := th.Client.GetCard(card.ID)
+		th.CheckOK(resp)
+		require.Equal(t, map[string]any{"owner": user1ID, "reviewers": []any{user1ID}}, updatedCard.Properties)
+	})
+
+	t.Run("the subscriptions are removed", func(t *testing.T) {
+		subs, err := th.Server.Store().GetSubscriptions(user2ID)
+		require.NoError(t, err)
+		require.Empty(t, subs)
+	})
+
+	t.Run("deactivated users can't be deactivated again", func(t *testing.T) {
+		_, resp := th.Client.DeactivateUser(user2ID, &model.DeactivateUserRequest{TransferTo: user1ID})
+		th.CheckNotFound(resp)
+	})
+}
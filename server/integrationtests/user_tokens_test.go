@@ -0,0 +1,143 @@
+package integrationtests
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/mail/mailtest"
+	"github.com/stretchr/testify/require"
+)
+
+var tokenLinkRegexp = regexp.MustCompile(`\?token=(\w+)`)
+
+func setupMail(t *testing.T, requireEmailVerification bool) (*TestHelper, *mailtest.Server) {
+	server, err := mailtest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	th := SetupTestHelperWithConfig(t, func(cfg *config.Configuration) {
+		cfg.SMTP = server.Config()
+		cfg.RequireEmailVerification = requireEmailVerification
+	})
+	t.Cleanup(th.TearDown)
+
+	return th, server
+}
+
+// lastToken returns the token of the link of the last email sent to a
+// recipient.
+func lastToken(t *testing.T, server *mailtest.Server, to string) string {
+	message := server.LastMessageTo(to)
+	require.NotNil(t, message)
+	match := tokenLinkRegexp.FindStringSubmatch(message.Body)
+	require.Len(t, match, 2)
+	return match[1]
+}
+
+func (th *TestHelper) loginResponse(username, password string) *model.LoginResponse {
+	data, resp := th.Client2.Login(&model.LoginRequest{Type: "normal", Username: username, Password: password})
+	if resp.Error != nil {
+		th.CheckUnauthorized(resp)
+		return nil
+	}
+	return data
+}
+
+func TestPasswordReset(t *testing.T) {
+	t.Run("email not configured", func(t *testing.T) {
+		th := SetupTestHelper(t).InitBasic()
+		defer th.TearDown()
+
+		resp := th.Client.SendPasswordReset("user1@sample.com")
+		th.CheckNotImplemented(resp)
+	})
+
+	th, server := setupMail(t, false)
+	th.InitBasic()
+
+	t.Run("unknown email", func(t *testing.T) {
+		resp := th.Client.SendPasswordReset("unknown@sample.com")
+		th.CheckOK(resp)
+		require.Empty(t, server.Messages())
+	})
+
+	t.Run("reset the password", func(t *testing.T) {
+		resp := th.Client.SendPasswordReset("user1@sample.com")
+		th.CheckOK(resp)
+		token := lastToken(t, server, "user1@sample.com")
+
+		// the token isn't used by an invalid password
+		resp = th.Client.ResetPassword(token, "short")
+		th.CheckBadRequest(resp)
+
+		newPassword := "New-Pa$$word"
+		resp = th.Client.ResetPassword(token, newPassword)
+		th.CheckOK(resp)
+
+		require.Nil(t, th.loginResponse(user1Username, password))
+		require.NotNil(t, th.loginResponse(user1Username, newPassword))
+
+		t.Run("tokens are single use", func(t *testing.T) {
+			resp := th.Client.ResetPassword(token, "Other-Pa$$word")
+			th.CheckBadRequest(resp)
+		})
+	})
+
+	t.Run("a new link replaces the previous one", func(t *testing.T) {
+		th.CheckOK(th.Client.SendPasswordReset("user2@sample.com"))
+		first := lastToken(t, server, "user2@sample.com")
+		th.CheckOK(th.Client.SendPasswordReset("user2@sample.com"))
+		second := lastToken(t, server, "user2@sample.com")
+		require.NotEqual(t, first, second)
+
+		th.CheckBadRequest(th.Client.ResetPassword(first, "New-Pa$$word"))
+		th.CheckOK(th.Client.ResetPassword(second, "New-Pa$$word"))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		resp := th.Client.ResetPassword("invalid-token", "New-Pa$$word")
+		th.CheckBadRequest(resp)
+	})
+}
+
+func TestEmailVerification(t *testing.T) {
+	th, server := setupMail(t, true)
+	th.Start()
+
+	success, resp := th.Client.Register(&model.RegisterRequest{
+		Username: user1Username,
+		Email:    "user1@sample.com",
+		Password: password,
+	})
+	th.CheckOK(resp)
+	require.True(t, success)
+
+	t.Run("login requires a verified email", func(t *testing.T) {
+		require.Nil(t, th.loginResponse(user1Username, password))
+	})
+
+	t.Run("a new link replaces the previous one", func(t *testing.T) {
+		first := lastToken(t, server, "user1@sample.com")
+		th.CheckOK(th.Client.SendEmailVerification("user1@sample.com"))
+		second := lastToken(t, server, "user1@sample.com")
+		require.NotEqual(t, first, second)
+
+		th.CheckBadRequest(th.Client.VerifyEmail(first))
+	})
+
+	t.Run("verify the email", func(t *testing.T) {
+		resp := th.Client.VerifyEmail(lastToken(t, server, "user1@sample.com"))
+		th.CheckOK(resp)
+
+		th.Login(th.Client, user1Username, password)
+		require.True(t, th.Me(th.Client).EmailVerified)
+	})
+
+	t.Run("verified users get no new link", func(t *testing.T) {
+		count := len(server.Messages())
+		th.CheckOK(th.Client.SendEmailVerification("user1@sample.com"))
+		require.Len(t, server.Messages(), count)
+	})
+}
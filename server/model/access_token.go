@@ -0,0 +1,155 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/services/auth"
+)
+
+const (
+	AccessTokenScopeReadOnly    = "read-only"
+	AccessTokenScopeCardsWrite  = "cards:write"
+	AccessTokenScopeBoardsAdmin = "boards:admin"
+
+	SessionPropAccessTokenID     = "accessTokenId"
+	SessionPropAccessTokenScopes = "accessTokenScopes"
+)
+
+// AccessToken is a user managed personal access token.
+// swagger:model
+type AccessToken struct {
+	// The access token ID
+	// required: true
+	ID string `json:"id"`
+
+	// The ID of the user that owns the token
+	// required: true
+	UserID string `json:"userId"`
+
+	// The description of the token
+	// required: false
+	Description string `json:"description"`
+
+	// The scopes that restrict the token. An empty list grants the
+	// same rights as the owner
+	// required: false
+	Scopes []string `json:"scopes"`
+
+	// The token itself, only returned when the token is created
+	// required: false
+	Token string `json:"token,omitempty"`
+
+	// swagger:ignore
+	TokenHash string `json:"-"`
+
+	// Expiration time in miliseconds since the current epoch, or zero
+	// if the token never expires
+	// required: false
+	ExpiresAt int64 `json:"expiresAt"`
+
+	// Last time the token was used in miliseconds since the current epoch
+	// required: false
+	LastUsedAt int64 `json:"lastUsedAt"`
+
+	// Created time in miliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"createAt"`
+
+	// Revoked time in miliseconds since the current epoch, set to indicate
+	// the token is revoked
+	// required: true
+	DeleteAt int64 `json:"deleteAt"`
+}
+
+// IsExpired returns true if the token has an expiry time that has passed.
+func (t *AccessToken) IsExpired(now int64) bool {
+	return t.ExpiresAt != 0 && t.ExpiresAt <= now
+}
+
+// AccessTokenCreateRequest is a request to create a personal access token
+// swagger:model
+type AccessTokenCreateRequest struct {
+	// The description of the token
+	// required: false
+	Description string `json:"description"`
+
+	// The scopes for the token
+	// required: false
+	Scopes []string `json:"scopes"`
+
+	// Expiration time in miliseconds since the current epoch, zero
+	// for no expiration
+	// required: false
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// IsValid validates an access token creation request.
+func (r *AccessTokenCreateRequest) IsValid(now int64) error {
+	for _, scope := range r.Scopes {
+		if !IsValidAccessTokenScope(scope) {
+			return NewErrBadRequest("invalid access token scope: " + scope)
+		}
+	}
+	if r.ExpiresAt != 0 && r.ExpiresAt <= now {
+		return NewErrBadRequest("access token expiration must be in the future")
+	}
+	return nil
+}
+
+// IsValidAccessTokenScope returns true if the scope is a known one.
+func IsValidAccessTokenScope(scope string) bool {
+	switch scope {
+	case AccessTokenScopeReadOnly, AccessTokenScopeCardsWrite, AccessTokenScopeBoardsAdmin:
+		return true
+	}
+	return false
+}
+
+// IsAccessTokenString returns true if the token looks like a personal
+// access token.
+func IsAccessTokenString(token string) bool {
+	return strings.HasPrefix(token, auth.AccessTokenPrefix)
+}
+
+// IsAccessToken returns true if the session was created from a
+// personal access token.
+func (s *Session) IsAccessToken() bool {
+	_, ok := s.Props[SessionPropAccessTokenID]
+	return ok
+}
+
+// AccessTokenScopes returns the scopes of the personal access token
+// the session was created from, if any.
+func (s *Session) AccessTokenScopes() []string {
+	scopes, ok := s.Props[SessionPropAccessTokenScopes].([]string)
+	if !ok {
+		return nil
+	}
+	return scopes
+}
+
+func AccessTokenCreateRequestFromJSON(data io.Reader) (*AccessTokenCreateRequest, error) {
+	var request AccessTokenCreateRequest
+	if err := json.NewDecoder(data).Decode(&request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func AccessTokenFromJSON(data io.Reader) (*AccessToken, error) {
+	var token AccessToken
+	if err := json.NewDecoder(data).Decode(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func AccessTokensFromJSON(data io.Reader) ([]*AccessToken, error) {
+	var tokens []*AccessToken
+	if err := json.NewDecoder(data).Decode(&tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -11,6 +11,10 @@ import (
 
 const (
 	MinimumPasswordLength = 8
+
+	AuthModeNative = "native"
+	AuthModeOIDC   = "oidc"
+	AuthModeLDAP   = "ldap"
 )
 
 func NewErrAuthParam(msg string) *ErrAuthParam {
@@ -86,6 +90,10 @@ type RegisterRequest struct {
 	// Registration authorization token
 	// required: true
 	Token string `json:"token"`
+
+	// Team invite token, instead of the registration token
+	// required: false
+	InviteToken string `json:"inviteToken"`
 }
 
 func (rd *RegisterRequest) IsValid() error {
@@ -108,6 +108,11 @@ type BlockPatch struct {
 	// The block removed fields
 	// required: false
 	DeletedFields []string `json:"deletedFields"`
+
+	// The update time the patched block is expected to have. If it was
+	// modified since, the patch is rejected with a conflict
+	// required: false
+	ExpectedUpdateAt *int64 `json:"expectedUpdateAt"`
 }
 
 // BlockPatchBatch is a batch of IDs and patches for modify blocks
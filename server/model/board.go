@@ -166,6 +166,11 @@ type BoardPatch struct {
 	// The board removed card properties
 	// required: false
 	DeletedCardProperties []string `json:"deletedCardProperties"`
+
+	// The update time the patched board is expected to have. If it was
+	// modified since, the patch is rejected with a conflict
+	// required: false
+	ExpectedUpdateAt *int64 `json:"expectedUpdateAt"`
 }
 
 // BoardMember stores the information of the membership of a user on a board
@@ -203,6 +208,10 @@ type BoardMember struct {
 	// required: true
 	SchemeViewer bool `json:"schemeViewer"`
 
+	// The ID of the custom board role of the user, if any
+	// required: false
+	CustomRoleID string `json:"customRoleId"`
+
 	// Marks the membership as generated by an access group
 	// required: true
 	Synthetic bool `json:"synthetic"`
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+)
+
+// BoardRolePermissions are the permissions that can be granted by a
+// custom board role.
+var BoardRolePermissions = []*mmModel.Permission{
+	PermissionViewBoard,
+	PermissionCommentBoardCards,
+	PermissionManageBoardCards,
+	PermissionManageBoardProperties,
+	PermissionDeleteOthersComments,
+	PermissionShareBoard,
+	PermissionManageBoardType,
+	PermissionManageBoardRoles,
+	PermissionDeleteBoard,
+}
+
+// CustomBoardRole is a named set of board permissions defined by the
+// board admins. Board members can be assigned to it on top of their
+// scheme roles.
+// swagger:model
+type CustomBoardRole struct {
+	// The ID of the role
+	// required: true
+	ID string `json:"id"`
+
+	// The ID of the board the role belongs to
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The name of the role
+	// required: true
+	Name string `json:"name"`
+
+	// The IDs of the board permissions granted by the role
+	// required: true
+	Permissions []string `json:"permissions"`
+
+	// The ID of the user that created the role
+	// required: true
+	CreatedBy string `json:"createdBy"`
+
+	// The creation time in miliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"createAt"`
+
+	// The last modified time in miliseconds since the current epoch
+	// required: true
+	UpdateAt int64 `json:"updateAt"`
+}
+
+func CustomBoardRoleFromJSON(data io.Reader) *CustomBoardRole {
+	var role *CustomBoardRole
+	_ = json.NewDecoder(data).Decode(&role)
+	return role
+}
+
+func CustomBoardRolesFromJSON(data io.Reader) []*CustomBoardRole {
+	var roles []*CustomBoardRole
+	_ = json.NewDecoder(data).Decode(&roles)
+	return roles
+}
+
+// IsValid validates the name and the permissions of a custom role.
+func (r *CustomBoardRole) IsValid() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return NewErrBadRequest("role name is required")
+	}
+	if len(r.Name) > 64 {
+		return NewErrBadRequest("role name is too long")
+	}
+	if len(r.Permissions) == 0 {
+		return NewErrBadRequest("at least one permission is required")
+	}
+	for _, id := range r.Permissions {
+		if !isBoardRolePermission(id) {
+			return NewErrBadRequest("invalid permission: " + id)
+		}
+	}
+	return nil
+}
+
+// HasPermission returns true if the role grants the permission. Every
+// role grants access to view the board.
+func (r *CustomBoardRole) HasPermission(permission *mmModel.Permission) bool {
+	if permission.Id == PermissionViewBoard.Id {
+		return true
+	}
+	for _, id := range r.Permissions {
+		if id == permission.Id {
+			return true
+		}
+	}
+	return false
+}
+
+// boardAdminPermissions are the permissions of the custom roles that are
+// never granted to guests, as guests can't be board admins.
+var boardAdminPermissions = []*mmModel.Permission{
+	PermissionDeleteOthersComments,
+	PermissionShareBoard,
+	PermissionManageBoardType,
+	PermissionManageBoardRoles,
+	PermissionDeleteBoard,
+}
+
+// IsBoardAdminPermission returns true if the permission is only granted
+// to the board admins.
+func IsBoardAdminPermission(permission *mmModel.Permission) bool {
+	for _, p := range boardAdminPermissions {
+		if p.Id == permission.Id {
+			return true
+		}
+	}
+	return false
+}
+
+func isBoardRolePermission(id string) bool {
+	for _, p := range BoardRolePermissions {
+		if p.Id == id {
+			return true
+		}
+	}
+	return false
+}
@@ -73,6 +73,11 @@ type Card struct {
 	// required: false
 	Properties map[string]any `json:"properties"`
 
+	// The users, board roles and custom board roles the card is visible to,
+	// besides its creator. Empty means every member of the board can see it
+	// required: false
+	VisibleTo []string `json:"visibleTo,omitempty"`
+
 	// The creation time in milliseconds since the current epoch
 	// required: false
 	CreateAt int64 `json:"createAt"`
@@ -155,6 +160,16 @@ type CardPatch struct {
 	// A map of property ids to property option ids to be updated
 	// required: false
 	UpdatedProperties map[string]any `json:"updatedProperties"`
+
+	// The users, board roles and custom board roles the card is visible to.
+	// An empty array makes the card visible to every member of the board
+	// required: false
+	VisibleTo *[]string `json:"visibleTo"`
+
+	// The update time the patched card is expected to have. If it was
+	// modified since, the patch is rejected with a conflict
+	// required: false
+	ExpectedUpdateAt *int64 `json:"expectedUpdateAt"`
 }
 
 // Patch returns an updated version of the card.
@@ -181,6 +196,10 @@ func (p *CardPatch) Patch(card *Card) *Card {
 		card.Properties[propID] = propVal
 	}
 
+	if p.VisibleTo != nil {
+		card.VisibleTo = *p.VisibleTo
+	}
+
 	return card
 }
 
@@ -200,6 +219,9 @@ func Card2Block(card *Card) *Block {
 	fields["icon"] = card.Icon
 	fields["isTemplate"] = card.IsTemplate
 	fields["properties"] = card.Properties
+	if len(card.VisibleTo) != 0 {
+		fields[CardVisibilityKey] = card.VisibleTo
+	}
 
 	return &Block{
 		ID:         card.ID,
@@ -281,6 +303,7 @@ func Block2Card(block *Block) (*Card, error) {
 		Icon:         icon,
 		IsTemplate:   isTemplate,
 		Properties:   properties,
+		VisibleTo:    GetCardVisibility(block),
 		CreateAt:     block.CreateAt,
 		UpdateAt:     block.UpdateAt,
 		DeleteAt:     block.DeleteAt,
@@ -296,7 +319,8 @@ func CardPatch2BlockPatch(cardPatch *CardPatch) (*BlockPatch, error) {
 	}
 
 	blockPatch := &BlockPatch{
-		Title: cardPatch.Title,
+		Title:            cardPatch.Title,
+		ExpectedUpdateAt: cardPatch.ExpectedUpdateAt,
 	}
 
 	updatedFields := make(map[string]any, 0)
@@ -317,6 +341,14 @@ func CardPatch2BlockPatch(cardPatch *CardPatch) (*BlockPatch, error) {
 		updatedFields["properties"] = cardPatch.UpdatedProperties
 	}
 
+	if cardPatch.VisibleTo != nil {
+		if len(*cardPatch.VisibleTo) == 0 {
+			blockPatch.DeletedFields = []string{CardVisibilityKey}
+		} else {
+			updatedFields[CardVisibilityKey] = *cardPatch.VisibleTo
+		}
+	}
+
 	blockPatch.UpdatedFields = updatedFields
 
 	return blockPatch, nil
@@ -0,0 +1,54 @@
+package model
+
+// CardVisibilityKey is the key of the card fields that restricts who can
+// see a card.
+const CardVisibilityKey = "visibleTo"
+
+// GetCardVisibility returns the users, board roles and custom board roles
+// a card is visible to, or nil if every member of the board can see it.
+func GetCardVisibility(block *Block) []string {
+	if block == nil || block.Type != TypeCard {
+		return nil
+	}
+
+	if subjects, ok := block.Fields[CardVisibilityKey].([]string); ok {
+		if len(subjects) == 0 {
+			return nil
+		}
+		return subjects
+	}
+	subjects := getMapStrings(CardVisibilityKey, block.Fields)
+	if len(subjects) == 0 {
+		return nil
+	}
+	return subjects
+}
+
+// IsPrivateCard returns true if the card is visible only to some of the
+// members of the board.
+func IsPrivateCard(block *Block) bool {
+	return len(GetCardVisibility(block)) != 0
+}
+
+// CanViewCard returns true if the user can see the card. The creator of a
+// private card can always see it. A nil member stands for a user that
+// isn't a member of the board.
+func CanViewCard(block *Block, userID string, member *BoardMember) bool {
+	subjects := GetCardVisibility(block)
+	if len(subjects) == 0 || userID == SystemUserID {
+		return true
+	}
+	if userID != "" && block.CreatedBy == userID {
+		return true
+	}
+	return matchesMemberSubjects(subjects, member)
+}
+
+// CardParentID returns the ID of the card a block belongs to, or an empty
+// string if the block doesn't belong to a card.
+func CardParentID(block *Block) string {
+	if block.Type == TypeCard || block.ParentID == "" || block.ParentID == block.BoardID {
+		return ""
+	}
+	return block.ParentID
+}
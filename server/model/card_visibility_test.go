@@ -0,0 +1,77 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardVisibility(t *testing.T) {
+	card := &Card{
+		ID:        "card-1",
+		BoardID:   "board-1",
+		CreatedBy: "creator",
+		VisibleTo: []string{"user-1", string(BoardRoleAdmin), "role-1"},
+	}
+	card.Populate()
+	block := Card2Block(card)
+
+	require.True(t, IsPrivateCard(block))
+	assert.Equal(t, card.VisibleTo, GetCardVisibility(block))
+
+	tests := []struct {
+		name    string
+		userID  string
+		member  *BoardMember
+		canView bool
+	}{
+		{"creator", "creator", &BoardMember{UserID: "creator", SchemeEditor: true}, true},
+		{"listed user", "user-1", &BoardMember{UserID: "user-1", SchemeViewer: true}, true},
+		{"listed role", "user-2", &BoardMember{UserID: "user-2", SchemeAdmin: true}, true},
+		{"custom role", "user-3", &BoardMember{UserID: "user-3", SchemeViewer: true, CustomRoleID: "role-1"}, true},
+		{"other member", "user-4", &BoardMember{UserID: "user-4", SchemeEditor: true}, false},
+		{"non member", "user-5", nil, false},
+		{"anonymous", "", nil, false},
+		{"system", SystemUserID, nil, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.canView, CanViewCard(block, tc.userID, tc.member))
+		})
+	}
+
+	t.Run("public card", func(t *testing.T) {
+		publicCard := &Card{ID: "card-2", BoardID: "board-1", CreatedBy: "creator"}
+		publicCard.Populate()
+		publicBlock := Card2Block(publicCard)
+		require.False(t, IsPrivateCard(publicBlock))
+		require.NotContains(t, publicBlock.Fields, CardVisibilityKey)
+		assert.True(t, CanViewCard(publicBlock, "user-4", nil))
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		converted, err := Block2Card(block)
+		require.NoError(t, err)
+		assert.Equal(t, card.VisibleTo, converted.VisibleTo)
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		visibleTo := []string{}
+		patch, err := CardPatch2BlockPatch(&CardPatch{VisibleTo: &visibleTo})
+		require.NoError(t, err)
+		assert.Equal(t, []string{CardVisibilityKey}, patch.DeletedFields)
+
+		visibleTo = []string{"user-1"}
+		patch, err = CardPatch2BlockPatch(&CardPatch{VisibleTo: &visibleTo})
+		require.NoError(t, err)
+		assert.Equal(t, visibleTo, patch.UpdatedFields[CardVisibilityKey])
+	})
+
+	t.Run("card contents", func(t *testing.T) {
+		assert.Equal(t, "card-1", CardParentID(&Block{ID: "text-1", BoardID: "board-1", ParentID: "card-1", Type: TypeText}))
+		assert.Empty(t, CardParentID(&Block{ID: "view-1", BoardID: "board-1", ParentID: "board-1", Type: TypeView}))
+		assert.Empty(t, CardParentID(block))
+	})
+}
@@ -26,4 +26,13 @@ type ClientConfig struct {
 	// Required for file upload to check the size of the file
 	// required: true
 	MaxFileSize int64 `json:"maxFileSize"`
+
+	// The authentication mode, so clients know how users log in
+	// required: true
+	AuthMode string `json:"authMode"`
+
+	// The policy the passwords must follow, so clients can show its
+	// requirements
+	// required: true
+	PasswordPolicy PasswordPolicy `json:"passwordPolicy"`
 }
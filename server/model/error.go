@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	mmModel "github.com/mattermost/mattermost/server/public/model"
 
@@ -160,6 +161,60 @@ func (ni *ErrNotImplemented) Error() string {
 	return ni.msg
 }
 
+// ErrTooManyRequests is returned when a client has to wait before
+// trying again.
+type ErrTooManyRequests struct {
+	msg        string
+	RetryAfter time.Duration
+}
+
+func NewErrTooManyRequests(msg string, retryAfter time.Duration) *ErrTooManyRequests {
+	return &ErrTooManyRequests{
+		msg:        msg,
+		RetryAfter: retryAfter,
+	}
+}
+
+func (e *ErrTooManyRequests) Error() string {
+	return e.msg
+}
+
+// ErrConflict is returned when a resource cannot be saved because it
+// conflicts with its current state or with another resource.
+type ErrConflict struct {
+	reason string
+}
+
+// NewErrConflict creates a new ErrConflict instance.
+func NewErrConflict(reason string) *ErrConflict {
+	return &ErrConflict{
+		reason: reason,
+	}
+}
+
+func (c *ErrConflict) Error() string {
+	return c.reason
+}
+
+// ErrVersionConflict is returned when a patch expects a version of a
+// resource that was modified since.
+type ErrVersionConflict struct {
+	ID              string
+	CurrentUpdateAt int64
+}
+
+// NewErrVersionConflict creates a new ErrVersionConflict instance.
+func NewErrVersionConflict(id string, currentUpdateAt int64) *ErrVersionConflict {
+	return &ErrVersionConflict{
+		ID:              id,
+		CurrentUpdateAt: currentUpdateAt,
+	}
+}
+
+func (c *ErrVersionConflict) Error() string {
+	return fmt.Sprintf("%s was modified since the expected version, its current version is %d", c.ID, c.CurrentUpdateAt)
+}
+
 // IsErrBadRequest returns true if `err` is or wraps one of:
 // - model.ErrBadRequest
 // - model.ErrViewsLimitReached
@@ -328,3 +383,23 @@ func IsErrNotImplemented(err error) bool {
 	// check if this is a model.ErrInsufficientLicense
 	return errors.Is(err, ErrInsufficientLicense)
 }
+
+// IsErrTooManyRequests returns true if `err` is or wraps a
+// model.ErrTooManyRequests.
+func IsErrTooManyRequests(err error) bool {
+	var tmr *ErrTooManyRequests
+	return errors.As(err, &tmr)
+}
+
+// IsErrConflict returns true if `err` is or wraps one of:
+// - model.ErrConflict
+// - model.ErrVersionConflict.
+func IsErrConflict(err error) bool {
+	var c *ErrConflict
+	if errors.As(err, &c) {
+		return true
+	}
+
+	var vc *ErrVersionConflict
+	return errors.As(err, &vc)
+}
@@ -10,4 +10,9 @@ type ErrorResponse struct {
 	// The error code
 	// required: false
 	ErrorCode int `json:"errorCode"`
+
+	// The current update time of the resource, when it was modified
+	// since the expected version
+	// required: false
+	CurrentUpdateAt int64 `json:"currentUpdateAt,omitempty"`
 }
@@ -38,6 +38,11 @@ type ExportArchiveOptions struct {
 	// BoardIDs is the list of boards to include in the archive.
 	// Empty slice means export all boards from workspace/team.
 	BoardIDs []string
+
+	// UserID is the user the archive is exported for. The private cards and
+	// the card property values hidden to the user are left out. Empty means
+	// no restrictions.
+	UserID string
 }
 
 // ImportArchiveOptions provides options when importing an archive.
@@ -0,0 +1,80 @@
+package model
+
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// Job is a run of a background job. A failed job that is retried is
+// followed by a new job with the next attempt, so that the jobs of a
+// type make up its history.
+// swagger:model
+type Job struct {
+	// ID of the job
+	// required: true
+	ID string `json:"id"`
+
+	// Type of the job, such as cleanUpSessions
+	// required: true
+	Type string `json:"type"`
+
+	// Status of the job: pending, running, succeeded, failed or cancelled
+	// required: true
+	Status string `json:"status"`
+
+	// Attempt is the number of the attempt, starting at 1
+	// required: true
+	Attempt int `json:"attempt"`
+
+	// ID of the user who triggered the job, empty for scheduled jobs
+	// required: false
+	CreatedBy string `json:"createdBy"`
+
+	// Error of a failed job
+	// required: false
+	Error string `json:"error"`
+
+	// RunAt is the time at which the job is due, in milliseconds since the current epoch
+	// required: true
+	RunAt int64 `json:"runAt"`
+
+	// StartAt is the time at which the job started, in milliseconds since the current epoch
+	// required: false
+	StartAt int64 `json:"startAt"`
+
+	// FinishAt is the time at which the job finished, in milliseconds since the current epoch
+	// required: false
+	FinishAt int64 `json:"finishAt"`
+
+	// CreateAt is the time at which the job was created, in milliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"createAt"`
+
+	// UpdateAt is the time of the last update of the job, in milliseconds since the current epoch
+	// required: true
+	UpdateAt int64 `json:"updateAt"`
+}
+
+// IsFinished returns true if the job won't run anymore.
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
+
+// QueryJobsOptions filters the jobs, the most recent first.
+type QueryJobsOptions struct {
+	Type     string
+	Statuses []string
+	Page     int
+	PerPage  int
+}
+
+// TriggerJobRequest runs a job now
+// swagger:model
+type TriggerJobRequest struct {
+	// Type of the job
+	// required: true
+	Type string `json:"type"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// MfaEnrollment is the secret generated when a user starts enabling
+// multi-factor authentication
+// swagger:model
+type MfaEnrollment struct {
+	// The base32 encoded TOTP secret
+	// required: true
+	Secret string `json:"secret"`
+
+	// The otpauth URI to be rendered as a QR code for authenticator apps
+	// required: true
+	QRCodeURI string `json:"qr_code_uri"`
+}
+
+// MfaCodeRequest carries a code from an authenticator app
+// swagger:model
+type MfaCodeRequest struct {
+	// The TOTP code
+	// required: true
+	Code string `json:"code"`
+}
+
+// MfaRecoveryCodes are the single-use codes that can replace a TOTP code
+// swagger:model
+type MfaRecoveryCodes struct {
+	// The recovery codes, only returned once when they are generated
+	// required: true
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func MfaEnrollmentFromJSON(data io.Reader) (*MfaEnrollment, error) {
+	var enrollment MfaEnrollment
+	if err := json.NewDecoder(data).Decode(&enrollment); err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+func MfaCodeRequestFromJSON(data io.Reader) (*MfaCodeRequest, error) {
+	var request MfaCodeRequest
+	if err := json.NewDecoder(data).Decode(&request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func MfaRecoveryCodesFromJSON(data io.Reader) (*MfaRecoveryCodes, error) {
+	var codes MfaRecoveryCodes
+	if err := json.NewDecoder(data).Decode(&codes); err != nil {
+		return nil, err
+	}
+	return &codes, nil
+}
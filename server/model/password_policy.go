@@ -0,0 +1,50 @@
+package model
+
+// SessionPropPasswordExpired is set on the sessions created with an
+// expired password, which can only be used to change it.
+const SessionPropPasswordExpired = "passwordExpired"
+
+// PasswordPolicy is the policy the passwords of the native users must
+// follow
+// swagger:model
+type PasswordPolicy struct {
+	// The minimum length of the passwords
+	// required: true
+	MinimumLength int `json:"minimumLength"`
+
+	// The maximum length of the passwords
+	// required: true
+	MaximumLength int `json:"maximumLength"`
+
+	// If the passwords require a lowercase letter
+	// required: true
+	Lowercase bool `json:"lowercase"`
+
+	// If the passwords require an uppercase letter
+	// required: true
+	Uppercase bool `json:"uppercase"`
+
+	// If the passwords require a number
+	// required: true
+	Number bool `json:"number"`
+
+	// If the passwords require a symbol
+	// required: true
+	Symbol bool `json:"symbol"`
+
+	// The number of days after which the passwords expire, zero if they
+	// don't
+	// required: true
+	MaxAgeDays int `json:"maxAgeDays"`
+
+	// The number of previous passwords of a user that cannot be reused
+	// required: true
+	HistoryDepth int `json:"historyDepth"`
+}
+
+// IsPasswordExpired returns true if the session was created with an
+// expired password.
+func (s *Session) IsPasswordExpired() bool {
+	expired, _ := s.Props[SessionPropPasswordExpired].(bool)
+	return expired
+}
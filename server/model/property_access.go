@@ -0,0 +1,278 @@
+package model
+
+import (
+	"reflect"
+)
+
+// PropertyAccessKey is the key of the access rules in the card property
+// templates of a board.
+const PropertyAccessKey = "access"
+
+// PropertyAccess restricts who can see and edit the values of a card
+// property. The lists contain board role names (admin, editor, commenter
+// and viewer), custom board role IDs and user IDs. An empty list doesn't
+// restrict anything, and board admins always have access.
+// swagger:model
+type PropertyAccess struct {
+	// Who can see the values of the property
+	// required: false
+	View []string `json:"view,omitempty"`
+
+	// Who can edit the values of the property
+	// required: false
+	Edit []string `json:"edit,omitempty"`
+}
+
+// GetPropertyAccess returns the access rules of a card property template,
+// or nil if it has none.
+func GetPropertyAccess(template map[string]interface{}) *PropertyAccess {
+	rules, ok := template[PropertyAccessKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	access := &PropertyAccess{
+		View: getMapStrings("view", rules),
+		Edit: getMapStrings("edit", rules),
+	}
+	if len(access.View) == 0 && len(access.Edit) == 0 {
+		return nil
+	}
+	return access
+}
+
+// CanView returns true if the member can see the values of the property.
+// A nil member stands for a user that isn't a member of the board.
+func (pa *PropertyAccess) CanView(member *BoardMember) bool {
+	return pa == nil || isPropertyAdmin(member) || matchesMemberSubjects(pa.View, member)
+}
+
+// CanEdit returns true if the member can change the values of the
+// property. Only the users that can see a property can edit it.
+func (pa *PropertyAccess) CanEdit(member *BoardMember) bool {
+	return pa.CanView(member) && (pa == nil || isPropertyAdmin(member) || matchesMemberSubjects(pa.Edit, member))
+}
+
+func isPropertyAdmin(member *BoardMember) bool {
+	return member != nil && (member.SchemeAdmin || member.MinimumRole == string(BoardRoleAdmin))
+}
+
+// matchesMemberSubjects returns true if the member is one of the users,
+// board roles or custom board roles of the list. An empty list matches
+// everyone.
+func matchesMemberSubjects(subjects []string, member *BoardMember) bool {
+	if len(subjects) == 0 {
+		return true
+	}
+	if member == nil {
+		return false
+	}
+
+	for _, subject := range subjects {
+		switch subject {
+		case member.UserID, member.CustomRoleID, member.MinimumRole:
+			return true
+		case string(BoardRoleAdmin):
+			if member.SchemeAdmin {
+				return true
+			}
+		case string(BoardRoleEditor):
+			if member.SchemeEditor {
+				return true
+			}
+		case string(BoardRoleCommenter):
+			if member.SchemeCommenter {
+				return true
+			}
+		case string(BoardRoleViewer):
+			if member.SchemeViewer {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PropertyRestrictions are the card properties of a board whose values
+// a user can't see or edit.
+type PropertyRestrictions struct {
+	Hidden   map[string]bool
+	ReadOnly map[string]bool
+}
+
+// GetPropertyRestrictions returns the card property restrictions of a
+// board for a member. A nil member stands for a user that isn't a member
+// of the board.
+func GetPropertyRestrictions(board *Board, member *BoardMember) *PropertyRestrictions {
+	restrictions := &PropertyRestrictions{
+		Hidden:   map[string]bool{},
+		ReadOnly: map[string]bool{},
+	}
+	if board == nil {
+		return restrictions
+	}
+
+	for _, template := range board.CardProperties {
+		access := GetPropertyAccess(template)
+		if access == nil {
+			continue
+		}
+		id := getMapString("id", template)
+		if !access.CanView(member) {
+			restrictions.Hidden[id] = true
+		}
+		if !access.CanEdit(member) {
+			restrictions.ReadOnly[id] = true
+		}
+	}
+	return restrictions
+}
+
+// HasPropertyAccessRules returns true if any of the card properties of the
+// board restricts who can see or edit its values.
+func HasPropertyAccessRules(board *Board) bool {
+	if board == nil {
+		return false
+	}
+	for _, template := range board.CardProperties {
+		if GetPropertyAccess(template) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// PropertyAccessChanged returns true if the patch adds, changes or
+// removes the access rules of any card property of the board.
+func PropertyAccessChanged(board *Board, patch *BoardPatch) bool {
+	current := map[string]*PropertyAccess{}
+	for _, template := range board.CardProperties {
+		current[getMapString("id", template)] = GetPropertyAccess(template)
+	}
+
+	for _, id := range patch.DeletedCardProperties {
+		if current[id] != nil {
+			return true
+		}
+	}
+	for _, template := range patch.UpdatedCardProperties {
+		if !reflect.DeepEqual(current[getMapString("id", template)], GetPropertyAccess(template)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (pr *PropertyRestrictions) IsEmpty() bool {
+	return len(pr.Hidden) == 0 && len(pr.ReadOnly) == 0
+}
+
+// FilterBlock returns the block without the hidden property values.
+// Blocks that don't need changes are returned as is, the others are
+// copied.
+func (pr *PropertyRestrictions) FilterBlock(block *Block) *Block {
+	if block == nil || block.Type != TypeCard || len(pr.Hidden) == 0 {
+		return block
+	}
+
+	properties, ok := block.Fields["properties"].(map[string]interface{})
+	if !ok {
+		return block
+	}
+
+	filtered := pr.filterProperties(properties)
+	if len(filtered) == len(properties) {
+		return block
+	}
+
+	newBlock := *block
+	newBlock.Fields = make(map[string]interface{}, len(block.Fields))
+	for key, value := range block.Fields {
+		newBlock.Fields[key] = value
+	}
+	newBlock.Fields["properties"] = filtered
+	return &newBlock
+}
+
+// FilterBlocks returns the blocks without the hidden property values.
+func (pr *PropertyRestrictions) FilterBlocks(blocks []*Block) []*Block {
+	if len(pr.Hidden) == 0 {
+		return blocks
+	}
+
+	filtered := make([]*Block, 0, len(blocks))
+	for _, block := range blocks {
+		filtered = append(filtered, pr.FilterBlock(block))
+	}
+	return filtered
+}
+
+// FilterCard returns the card without the hidden property values.
+func (pr *PropertyRestrictions) FilterCard(card *Card) *Card {
+	if card == nil || len(pr.Hidden) == 0 {
+		return card
+	}
+
+	newCard := *card
+	newCard.Properties = pr.filterProperties(card.Properties)
+	return &newCard
+}
+
+func (pr *PropertyRestrictions) filterProperties(properties map[string]interface{}) map[string]interface{} {
+	filtered := make(map[string]interface{}, len(properties))
+	for id, value := range properties {
+		if !pr.Hidden[id] {
+			filtered[id] = value
+		}
+	}
+	return filtered
+}
+
+// ApplyPropertyChanges returns the property values of a card after an
+// update. The values the user can't edit are kept even if they are
+// missing from the update, so users can save cards without knowing the
+// values hidden to them. The update fails if it changes any of them.
+func (pr *PropertyRestrictions) ApplyPropertyChanges(oldProperties, newProperties map[string]interface{}) (map[string]interface{}, error) {
+	if len(pr.ReadOnly) == 0 {
+		return newProperties, nil
+	}
+
+	properties := make(map[string]interface{}, len(newProperties))
+	for id, value := range newProperties {
+		oldValue, existed := oldProperties[id]
+		if pr.ReadOnly[id] && (!existed || !reflect.DeepEqual(oldValue, value)) {
+			return nil, NewErrPermission("access denied to edit card property " + id)
+		}
+		properties[id] = value
+	}
+	for id, value := range oldProperties {
+		if pr.ReadOnly[id] {
+			properties[id] = value
+		}
+	}
+	return properties, nil
+}
+
+// GetBlockProperties returns the property values of a card block.
+func GetBlockProperties(block *Block) map[string]interface{} {
+	if block == nil {
+		return nil
+	}
+	properties, _ := block.Fields["properties"].(map[string]interface{})
+	return properties
+}
+
+func getMapStrings(key string, m map[string]interface{}) []string {
+	iface, ok := m[key].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	values := make([]string, 0, len(iface))
+	for _, v := range iface {
+		if s, ok := v.(string); ok && s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
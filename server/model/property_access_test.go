@@ -0,0 +1,136 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertyAccess(t *testing.T) {
+	access := &PropertyAccess{
+		View: []string{string(BoardRoleEditor), "role-1"},
+		Edit: []string{"user-1"},
+	}
+
+	admin := &BoardMember{UserID: "admin", SchemeAdmin: true}
+	editor := &BoardMember{UserID: "user-1", SchemeEditor: true}
+	otherEditor := &BoardMember{UserID: "user-2", SchemeEditor: true}
+	viewer := &BoardMember{UserID: "user-3", SchemeViewer: true}
+	customRole := &BoardMember{UserID: "user-4", SchemeViewer: true, CustomRoleID: "role-1"}
+	minimumRole := &BoardMember{UserID: "user-5", MinimumRole: string(BoardRoleEditor)}
+
+	tests := []struct {
+		name    string
+		member  *BoardMember
+		canView bool
+		canEdit bool
+	}{
+		{"admin", admin, true, true},
+		{"listed user", editor, true, true},
+		{"listed role", otherEditor, true, false},
+		{"unlisted role", viewer, false, false},
+		{"custom role", customRole, true, false},
+		{"minimum role", minimumRole, true, false},
+		{"non member", nil, false, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.canView, access.CanView(tc.member))
+			assert.Equal(t, tc.canEdit, access.CanEdit(tc.member))
+		})
+	}
+
+	t.Run("no rules", func(t *testing.T) {
+		var noAccess *PropertyAccess
+		assert.True(t, noAccess.CanView(nil))
+		assert.True(t, noAccess.CanEdit(nil))
+	})
+}
+
+func TestPropertyRestrictions(t *testing.T) {
+	board := &Board{
+		ID: "board-1",
+		CardProperties: []map[string]interface{}{
+			{"id": "status", "name": "Status", "type": "select"},
+			{
+				"id":   "salary",
+				"name": "Salary",
+				"type": "number",
+				"access": map[string]interface{}{
+					"view": []interface{}{"admin"},
+				},
+			},
+			{
+				"id":   "estimate",
+				"name": "Estimate",
+				"type": "number",
+				"access": map[string]interface{}{
+					"edit": []interface{}{"user-1"},
+				},
+			},
+		},
+	}
+	editor := &BoardMember{BoardID: board.ID, UserID: "user-2", SchemeEditor: true}
+
+	require.True(t, HasPropertyAccessRules(board))
+	restrictions := GetPropertyRestrictions(board, editor)
+	assert.Equal(t, map[string]bool{"salary": true}, restrictions.Hidden)
+	assert.Equal(t, map[string]bool{"salary": true, "estimate": true}, restrictions.ReadOnly)
+
+	t.Run("filter block", func(t *testing.T) {
+		block := &Block{
+			ID:   "card-1",
+			Type: TypeCard,
+			Fields: map[string]interface{}{
+				"icon":       "🎉",
+				"properties": map[string]interface{}{"status": "done", "salary": "100"},
+			},
+		}
+
+		filtered := restrictions.FilterBlock(block)
+		assert.Equal(t, map[string]interface{}{"status": "done"}, filtered.Fields["properties"])
+		assert.Equal(t, "🎉", filtered.Fields["icon"])
+		// the original block is left untouched
+		assert.Equal(t, map[string]interface{}{"status": "done", "salary": "100"}, block.Fields["properties"])
+	})
+
+	t.Run("apply property changes", func(t *testing.T) {
+		old := map[string]interface{}{"status": "todo", "salary": "100", "estimate": "3"}
+
+		properties, err := restrictions.ApplyPropertyChanges(old, map[string]interface{}{"status": "done"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"status": "done", "salary": "100", "estimate": "3"}, properties)
+
+		properties, err = restrictions.ApplyPropertyChanges(old, map[string]interface{}{"status": "done", "estimate": "3"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"status": "done", "salary": "100", "estimate": "3"}, properties)
+
+		_, err = restrictions.ApplyPropertyChanges(old, map[string]interface{}{"estimate": "5"})
+		require.Error(t, err)
+		var errPermission *ErrPermission
+		assert.ErrorAs(t, err, &errPermission)
+
+		_, err = restrictions.ApplyPropertyChanges(nil, map[string]interface{}{"salary": "200"})
+		require.Error(t, err)
+	})
+
+	t.Run("access changes", func(t *testing.T) {
+		title := "New title"
+		assert.False(t, PropertyAccessChanged(board, &BoardPatch{Title: &title}))
+		assert.False(t, PropertyAccessChanged(board, &BoardPatch{
+			UpdatedCardProperties: []map[string]interface{}{
+				{"id": "status", "name": "State", "type": "select"},
+			},
+		}))
+		assert.True(t, PropertyAccessChanged(board, &BoardPatch{
+			UpdatedCardProperties: []map[string]interface{}{
+				{"id": "status", "name": "Status", "type": "select", "access": map[string]interface{}{"view": []interface{}{"admin"}}},
+			},
+		}))
+		assert.True(t, PropertyAccessChanged(board, &BoardPatch{
+			DeletedCardProperties: []string{"salary"},
+		}))
+	})
+}
@@ -0,0 +1,179 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	SCIMSchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SCIMSchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	// SCIMMaxResults is the maximum number of resources returned in a
+	// page of a list.
+	SCIMMaxResults = 200
+)
+
+// SCIMMeta is the metadata of a SCIM resource.
+type SCIMMeta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+// SCIMName is the name of a SCIM user.
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMEmail is an email address of a SCIM user.
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMMember references a user member of a group, or a group of a user.
+type SCIMMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMUser is a user, as exchanged with the identity providers.
+type SCIMUser struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *SCIMName    `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	NickName    string       `json:"nickName,omitempty"`
+	Emails      []SCIMEmail  `json:"emails,omitempty"`
+	Password    string       `json:"password,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Groups      []SCIMMember `json:"groups,omitempty"`
+	Meta        *SCIMMeta    `json:"meta,omitempty"`
+}
+
+// PrimaryEmail returns the primary email address of the user, or the
+// first one if none is marked as primary.
+func (u *SCIMUser) PrimaryEmail() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// SCIMGroup is a group, as exchanged with the identity providers.
+type SCIMGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []SCIMMember `json:"members,omitempty"`
+	Meta        *SCIMMeta    `json:"meta,omitempty"`
+}
+
+// SCIMListResponse is a page of the resources matching a query.
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// SCIMPatchRequest is a list of operations to apply to a resource.
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation is a single modification of a resource. Op is
+// "add", "remove" or "replace", compared case-insensitively as some
+// providers capitalize it. Without a path, the value is an object of
+// the attributes to modify.
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// SCIMError is the body of the SCIM error responses.
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// SCIMFilter is an equality filter on an attribute, the only kind of
+// filter the identity providers need to look up resources. The
+// attribute is lowercased, as attribute names are case insensitive.
+type SCIMFilter struct {
+	Attribute string
+	Value     string
+}
+
+// ParseSCIMFilter parses a filter of the form `attribute eq "value"`.
+// An empty filter returns nil.
+func ParseSCIMFilter(filter string) (*SCIMFilter, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(filter, " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[1], "eq") {
+		return nil, NewErrBadRequest(fmt.Sprintf("unsupported filter: %s", filter))
+	}
+
+	value := strings.TrimSpace(parts[2])
+	if strings.HasPrefix(value, `"`) {
+		if err := json.Unmarshal([]byte(value), &value); err != nil {
+			return nil, NewErrBadRequest(fmt.Sprintf("invalid filter value: %s", parts[2]))
+		}
+	}
+
+	return &SCIMFilter{
+		Attribute: strings.ToLower(parts[0]),
+		Value:     value,
+	}, nil
+}
+
+func SCIMUserFromJSON(data io.Reader) (*SCIMUser, error) {
+	var user SCIMUser
+	if err := json.NewDecoder(data).Decode(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func SCIMGroupFromJSON(data io.Reader) (*SCIMGroup, error) {
+	var group SCIMGroup
+	if err := json.NewDecoder(data).Decode(&group); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func SCIMPatchRequestFromJSON(data io.Reader) (*SCIMPatchRequest, error) {
+	var patch SCIMPatchRequest
+	if err := json.NewDecoder(data).Decode(&patch); err != nil {
+		return nil, err
+	}
+	return &patch, nil
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filter   string
+		expected *SCIMFilter
+		isError  bool
+	}{
+		{"empty", "", nil, false},
+		{"quoted value", `userName eq "john"`, &SCIMFilter{Attribute: "username", Value: "john"}, false},
+		{"escaped value", `displayName eq "the \"A\" team"`, &SCIMFilter{Attribute: "displayname", Value: `the "A" team`}, false},
+		{"value with spaces", `displayName EQ "Platform team"`, &SCIMFilter{Attribute: "displayname", Value: "Platform team"}, false},
+		{"unquoted value", `active eq true`, &SCIMFilter{Attribute: "active", Value: "true"}, false},
+		{"unsupported operator", `userName sw "jo"`, nil, true},
+		{"missing value", `userName eq`, nil, true},
+		{"invalid quoting", `userName eq "john`, nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := ParseSCIMFilter(tc.filter)
+			if tc.isError {
+				require.True(t, IsErrBadRequest(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, filter)
+		})
+	}
+}
+
+func TestSCIMUserPrimaryEmail(t *testing.T) {
+	user := &SCIMUser{}
+	require.Empty(t, user.PrimaryEmail())
+
+	user.Emails = []SCIMEmail{{Value: "home@example.com"}, {Value: "work@example.com", Primary: true}}
+	require.Equal(t, "work@example.com", user.PrimaryEmail())
+
+	user.Emails[1].Primary = false
+	require.Equal(t, "home@example.com", user.PrimaryEmail())
+}
@@ -3,6 +3,21 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/auth"
+)
+
+const (
+	TeamRoleAdmin  = "admin"
+	TeamRoleMember = "member"
+
+	// TeamRoleGuest invites users as guests, who only have access to the
+	// boards they are explicitly added to. It isn't a team member role.
+	TeamRoleGuest = "guest"
+
+	TeamInviteExpiry = 7 * 24 * time.Hour
 )
 
 // Team is information global to a team
@@ -44,3 +59,115 @@ func TeamsFromJSON(data io.Reader) []*Team {
 	_ = json.NewDecoder(data).Decode(&teams)
 	return teams
 }
+
+// TeamMember stores the membership of a user to a team
+// swagger:model
+type TeamMember struct {
+	// ID of the team
+	// required: true
+	TeamID string `json:"teamId"`
+
+	// ID of the user
+	// required: true
+	UserID string `json:"userId"`
+
+	// Roles of the user in the team
+	// required: false
+	Roles string `json:"roles"`
+
+	// Created time in miliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
+
+func TeamMembersFromJSON(data io.Reader) []*TeamMember {
+	var members []*TeamMember
+	_ = json.NewDecoder(data).Decode(&members)
+	return members
+}
+
+// IsAdmin returns true if the member can manage the team.
+func (tm *TeamMember) IsAdmin() bool {
+	for _, role := range strings.Fields(tm.Roles) {
+		if role == TeamRoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamInvite is a single-use invitation to join a team, sent by email.
+// Only the hash of the token is stored.
+type TeamInvite struct {
+	TokenHash string
+	TeamID    string
+	Email     string
+	Roles     string
+	InvitedBy string
+	CreateAt  int64
+	ExpireAt  int64
+}
+
+// CreateTeamRequest creates a new team
+// swagger:model
+type CreateTeamRequest struct {
+	// Title of the team
+	// required: true
+	Title string `json:"title"`
+}
+
+// IsValid validates a team creation request.
+func (rd *CreateTeamRequest) IsValid() error {
+	if strings.TrimSpace(rd.Title) == "" {
+		return NewErrBadRequest("title is required")
+	}
+	if len(rd.Title) > 100 {
+		return NewErrBadRequest("title is too long")
+	}
+	return nil
+}
+
+// TeamInviteRequest invites users to a team by email
+// swagger:model
+type TeamInviteRequest struct {
+	// Email addresses to send the invites to
+	// required: true
+	Emails []string `json:"emails"`
+
+	// Role of the invited users in the team: admin, member or guest. Guests
+	// are only invited when they register with the invite
+	// required: false
+	Role string `json:"role"`
+}
+
+// IsValid validates a team invite request.
+func (rd *TeamInviteRequest) IsValid() error {
+	if len(rd.Emails) == 0 {
+		return NewErrBadRequest("at least one email is required")
+	}
+	for _, email := range rd.Emails {
+		if !auth.IsEmailValid(strings.TrimSpace(email)) {
+			return NewErrBadRequest("invalid email: " + email)
+		}
+	}
+	if rd.Role != "" && rd.Role != TeamRoleMember && rd.Role != TeamRoleAdmin && rd.Role != TeamRoleGuest {
+		return NewErrBadRequest("invalid role: " + rd.Role)
+	}
+	return nil
+}
+
+// TeamInviteAcceptRequest joins a team with an invite
+// swagger:model
+type TeamInviteAcceptRequest struct {
+	// The token received by email
+	// required: true
+	Token string `json:"token"`
+}
+
+// IsValid validates a team invite acceptance.
+func (rd *TeamInviteAcceptRequest) IsValid() error {
+	if rd.Token == "" {
+		return NewErrBadRequest("token is required")
+	}
+	return nil
+}
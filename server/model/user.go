@@ -3,6 +3,7 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"strings"
 )
 
 const (
@@ -10,6 +11,9 @@ const (
 	GlobalTeamID                  = "0"
 	SystemUserID                  = "system"
 	PreferencesCategoryFocalboard = "focalboard"
+
+	SystemUserRoleID  = "system_user"
+	SystemAdminRoleID = "system_admin"
 )
 
 // User is a user
@@ -27,6 +31,9 @@ type User struct {
 	// required: true
 	Email string `json:"-"`
 
+	// If the user has confirmed owning their email address
+	EmailVerified bool `json:"email_verified"`
+
 	// The user's nickname
 	Nickname string `json:"nickname"`
 	// The user's first name
@@ -37,9 +44,15 @@ type User struct {
 	// swagger:ignore
 	Password string `json:"-"`
 
+	// swagger:ignore
+	PasswordUpdateAt int64 `json:"-"`
+
 	// swagger:ignore
 	MfaSecret string `json:"-"`
 
+	// If the user has multi-factor authentication enabled
+	MfaActive bool `json:"mfa_active"`
+
 	// swagger:ignore
 	AuthService string `json:"-"`
 
@@ -72,6 +85,16 @@ type User struct {
 	Roles string `json:"roles"`
 }
 
+// QueryUsersOptions are query options that can be passed to QueryUsers.
+// Unlike the other user queries, the deactivated users are included.
+type QueryUsersOptions struct {
+	UserID   string // if not empty then filter for the user with this ID
+	Username string // if not empty then filter for the user with this username
+	Email    string // if not empty then filter for the user with this email
+	Offset   int    // number of users to skip when paginating
+	Limit    int    // if non-zero then limit the number of returned users
+}
+
 // UserPreferencesPatch is a user property patch
 // swagger:model
 type UserPreferencesPatch struct {
@@ -84,14 +107,52 @@ type UserPreferencesPatch struct {
 	DeletedFields []string `json:"deletedFields"`
 }
 
+// Session is an authenticated session of a user.
+// swagger:model
 type Session struct {
-	ID          string                 `json:"id"`
-	Token       string                 `json:"token"`
-	UserID      string                 `json:"user_id"`
-	AuthService string                 `json:"authService"`
-	Props       map[string]interface{} `json:"props"`
-	CreateAt    int64                  `json:"create_at,omitempty"`
-	UpdateAt    int64                  `json:"update_at,omitempty"`
+	// The session ID
+	// required: true
+	ID string `json:"id"`
+
+	// The session token, only known by its client
+	// required: false
+	Token string `json:"token"`
+
+	// The ID of the user of the session
+	// required: true
+	UserID string `json:"user_id"`
+
+	// The authentication service that created the session
+	// required: true
+	AuthService string `json:"authService"`
+
+	// The session properties
+	// required: false
+	Props map[string]interface{} `json:"props"`
+
+	// The user agent of the client that created the session
+	// required: false
+	UserAgent string `json:"user_agent"`
+
+	// The IP address of the client that created the session
+	// required: false
+	IPAddress string `json:"ip_address"`
+
+	// Creation time in miliseconds since the current epoch
+	// required: true
+	CreateAt int64 `json:"create_at,omitempty"`
+
+	// Last activity time in miliseconds since the current epoch, the
+	// session is refreshed while it's used
+	// required: true
+	UpdateAt int64 `json:"update_at,omitempty"`
+}
+
+// Sanitize removes the secrets of a session, before sending it to a
+// client.
+func (s *Session) Sanitize() {
+	s.Token = ""
+	s.Props = nil
 }
 
 func UserFromJSON(data io.Reader) (*User, error) {
@@ -102,6 +163,24 @@ func UserFromJSON(data io.Reader) (*User, error) {
 	return &user, nil
 }
 
+func SessionsFromJSON(data io.Reader) ([]*Session, error) {
+	var sessions []*Session
+	if err := json.NewDecoder(data).Decode(&sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// IsSystemAdmin returns true if the user has the system admin role.
+func (u *User) IsSystemAdmin() bool {
+	for _, role := range strings.Fields(u.Roles) {
+		if role == SystemAdminRoleID {
+			return true
+		}
+	}
+	return false
+}
+
 func (u *User) Sanitize(options map[string]bool) {
 	u.Password = ""
 	u.MfaSecret = ""
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// DeactivateUserRequest deactivates a user, handing their boards over to
+// another user
+// swagger:model
+type DeactivateUserRequest struct {
+	// ID of the user who becomes admin of the boards the deactivated user
+	// administered
+	// required: true
+	TransferTo string `json:"transferTo"`
+
+	// If true, the person property values set to the deactivated user are
+	// reassigned to the new admin, otherwise they're cleared
+	// required: false
+	ReassignPersonProperties bool `json:"reassignPersonProperties"`
+}
+
+// IsValid validates a user deactivation request.
+func (rd *DeactivateUserRequest) IsValid(userID string) error {
+	if rd.TransferTo == "" {
+		return NewErrBadRequest("transferTo is required")
+	}
+	if rd.TransferTo == userID {
+		return NewErrBadRequest("the boards can't be transferred to the deactivated user")
+	}
+	return nil
+}
+
+// DeactivateUserReport summarizes the changes made when deactivating a
+// user
+// swagger:model
+type DeactivateUserReport struct {
+	// ID of the deactivated user
+	// required: true
+	UserID string `json:"userId"`
+
+	// ID of the user the boards were transferred to
+	// required: true
+	TransferTo string `json:"transferTo"`
+
+	// Number of sessions revoked
+	// required: true
+	RevokedSessions int `json:"revokedSessions"`
+
+	// IDs of the boards whose admin was transferred
+	// required: true
+	TransferredBoards []string `json:"transferredBoards"`
+
+	// IDs of the cards whose person property values were reassigned or
+	// cleared
+	// required: true
+	UpdatedCards []string `json:"updatedCards"`
+
+	// Number of subscriptions removed
+	// required: true
+	RemovedSubscriptions int `json:"removedSubscriptions"`
+}
+
+func DeactivateUserReportFromJSON(data io.Reader) *DeactivateUserReport {
+	var report *DeactivateUserReport
+	_ = json.NewDecoder(data).Decode(&report)
+	return report
+}
+
+// ReplacePersonPropertyValues replaces a user by another one in the
+// person and multi person property values of a card. If newUserID is
+// empty the user is removed instead. The returned properties are nil if
+// nothing changed.
+func ReplacePersonPropertyValues(schema PropSchema, properties map[string]interface{}, oldUserID, newUserID string) map[string]interface{} {
+	var changed map[string]interface{}
+	set := func(id string, value interface{}) {
+		if changed == nil {
+			changed = make(map[string]interface{}, len(properties))
+			for k, v := range properties {
+				changed[k] = v
+			}
+		}
+		if value == nil {
+			delete(changed, id)
+			return
+		}
+		changed[id] = value
+	}
+
+	for id, value := range properties {
+		switch schema[id].Type {
+		case "person":
+			if userID, ok := value.(string); ok && userID == oldUserID {
+				if newUserID == "" {
+					set(id, nil)
+				} else {
+					set(id, newUserID)
+				}
+			}
+
+		case "multiPerson":
+			userIDs, ok := value.([]interface{})
+			if !ok || !containsInterface(userIDs, oldUserID) {
+				continue
+			}
+			newUserIDs := make([]interface{}, 0, len(userIDs))
+			for _, userID := range userIDs {
+				if userID == oldUserID || (newUserID != "" && userID == newUserID) {
+					continue
+				}
+				newUserIDs = append(newUserIDs, userID)
+			}
+			if newUserID != "" {
+				newUserIDs = append(newUserIDs, newUserID)
+			}
+			if len(newUserIDs) == 0 {
+				set(id, nil)
+			} else {
+				set(id, newUserIDs)
+			}
+		}
+	}
+	return changed
+}
+
+func containsInterface(values []interface{}, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplacePersonPropertyValues(t *testing.T) {
+	schema := PropSchema{
+		"owner":     {ID: "owner", Type: "person"},
+		"reviewers": {ID: "reviewers", Type: "multiPerson"},
+		"status":    {ID: "status", Type: "text"},
+	}
+	properties := map[string]interface{}{
+		"owner":     "user-1",
+		"reviewers": []interface{}{"user-1", "user-2"},
+		"status":    "user-1",
+	}
+
+	t.Run("reassign", func(t *testing.T) {
+		changed := ReplacePersonPropertyValues(schema, properties, "user-1", "user-2")
+		assert.Equal(t, map[string]interface{}{
+			"owner":     "user-2",
+			"reviewers": []interface{}{"user-2"},
+			"status":    "user-1",
+		}, changed)
+		// the original properties are left untouched
+		assert.Equal(t, "user-1", properties["owner"])
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		changed := ReplacePersonPropertyValues(schema, properties, "user-1", "")
+		assert.Equal(t, map[string]interface{}{
+			"reviewers": []interface{}{"user-2"},
+			"status":    "user-1",
+		}, changed)
+	})
+
+	t.Run("nothing to change", func(t *testing.T) {
+		assert.Nil(t, ReplacePersonPropertyValues(schema, properties, "user-3", "user-2"))
+	})
+}
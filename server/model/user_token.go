@@ -0,0 +1,99 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	UserTokenTypePasswordReset     = "password_reset"
+	UserTokenTypeEmailVerification = "email_verification"
+
+	PasswordResetTokenExpiry     = time.Hour
+	EmailVerificationTokenExpiry = 24 * time.Hour
+)
+
+// UserToken is a single-use token sent to a user by email, to reset their
+// password or verify their email address. Only the hash of the token is
+// stored.
+type UserToken struct {
+	TokenHash string
+	Type      string
+	UserID    string
+	// Extra holds the email address the token was sent to
+	Extra    string
+	CreateAt int64
+	ExpireAt int64
+}
+
+// PasswordResetSendRequest asks for a password reset link
+// swagger:model
+type PasswordResetSendRequest struct {
+	// The email address of the account
+	// required: true
+	Email string `json:"email"`
+}
+
+// IsValid validates a password reset link request.
+func (rd *PasswordResetSendRequest) IsValid() error {
+	if strings.TrimSpace(rd.Email) == "" {
+		return NewErrAuthParam("email is required")
+	}
+	return nil
+}
+
+// PasswordResetRequest sets a new password with a reset token
+// swagger:model
+type PasswordResetRequest struct {
+	// The token received by email
+	// required: true
+	Token string `json:"token"`
+
+	// New password
+	// required: true
+	NewPassword string `json:"newPassword"`
+}
+
+// IsValid validates a password reset request. The new password is
+// checked against the password policy of the server.
+func (rd *PasswordResetRequest) IsValid() error {
+	if rd.Token == "" {
+		return NewErrAuthParam("token is required")
+	}
+	if rd.NewPassword == "" {
+		return NewErrAuthParam("new password is required")
+	}
+	return nil
+}
+
+// EmailVerificationSendRequest asks for a new email verification link
+// swagger:model
+type EmailVerificationSendRequest struct {
+	// The email address of the account
+	// required: true
+	Email string `json:"email"`
+}
+
+// IsValid validates an email verification link request.
+func (rd *EmailVerificationSendRequest) IsValid() error {
+	if strings.TrimSpace(rd.Email) == "" {
+		return NewErrAuthParam("email is required")
+	}
+	return nil
+}
+
+// EmailVerificationRequest confirms an email address with a token
+// swagger:model
+type EmailVerificationRequest struct {
+	// The token received by email
+	// required: true
+	Token string `json:"token"`
+}
+
+// IsValid validates an email verification request.
+func (rd *EmailVerificationRequest) IsValid() error {
+	if rd.Token == "" {
+		return NewErrAuthParam("token is required")
+	}
+	return nil
+}
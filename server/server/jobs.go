@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/jobs"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/wiggin77/merror"
+)
+
+const (
+	cleanUpSessionsSchedule = "*/10 * * * *"
+	dataRetentionSchedule   = "0 3 * * *"
+	cleanUpJobsSchedule     = "@daily"
+
+	minSessionExpiryTime = int64(60 * 60 * 24 * 31) // 31 days
+
+	dataRetentionBatchSize = 100
+
+	// jobsHistoryRetention is the time the finished jobs are kept for.
+	jobsHistoryRetention = 30 * 24 * time.Hour
+)
+
+var errInvalidDataRetentionDays = errors.New("the data retention period must be at least a day")
+
+// registerJobs registers the background jobs of the server.
+func (s *Server) registerJobs() error {
+	definitions := []jobs.Definition{
+		{
+			Type:     "cleanUpJobs",
+			Schedule: cleanUpJobsSchedule,
+			Run: func(ctx context.Context) error {
+				_, err := s.store.CleanUpJobs(utils.GetMillisForTime(time.Now().Add(-jobsHistoryRetention)))
+				return err
+			},
+		},
+	}
+
+	if s.config.AuthMode != MattermostAuthMod {
+		definitions = append(definitions, jobs.Definition{
+			Type:     "cleanUpSessions",
+			Schedule: cleanUpSessionsSchedule,
+			Run:      s.cleanUpSessions,
+		})
+
+		if s.config.EnableDataRetention {
+			definitions = append(definitions, jobs.Definition{
+				Type:        "dataRetention",
+				Schedule:    dataRetentionSchedule,
+				MaxAttempts: 3,
+				RetryDelay:  10 * time.Minute,
+				Run:         s.runDataRetention,
+			})
+		}
+	}
+
+	if interval, ok := s.app.LDAPSyncInterval(); ok {
+		definitions = append(definitions, jobs.Definition{
+			Type:        "ldapSync",
+			Schedule:    fmt.Sprintf("@every %s", interval),
+			MaxAttempts: 2,
+			Run: func(ctx context.Context) error {
+				return s.app.SyncLDAP()
+			},
+		})
+	}
+
+	for _, definition := range definitions {
+		if err := s.jobsService.Register(definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanUpSessions deletes the expired sessions, user tokens and team
+// invites.
+func (s *Server) cleanUpSessions(ctx context.Context) error {
+	secondsAgo := minSessionExpiryTime
+	if secondsAgo < s.config.SessionExpireTime {
+		secondsAgo = s.config.SessionExpireTime
+	}
+
+	merr := merror.New()
+	if err := s.store.CleanUpSessions(secondsAgo); err != nil {
+		merr.Append(fmt.Errorf("unable to clean up the sessions: %w", err))
+	}
+
+	if err := s.store.CleanUpUserTokens(); err != nil {
+		merr.Append(fmt.Errorf("unable to clean up the user tokens: %w", err))
+	}
+
+	if err := s.store.CleanUpTeamInvites(); err != nil {
+		merr.Append(fmt.Errorf("unable to clean up the team invites: %w", err))
+	}
+	return merr.ErrorOrNil()
+}
+
+// runDataRetention deletes the boards and cards that weren't updated for
+// the retention period of the configuration.
+func (s *Server) runDataRetention(ctx context.Context) error {
+	if s.config.DataRetentionDays < 1 {
+		return errInvalidDataRetentionDays
+	}
+	retention := time.Duration(s.config.DataRetentionDays) * 24 * time.Hour
+	globalRetentionDate := utils.GetMillisForTime(time.Now().Add(-retention))
+
+	_, err := s.store.RunDataRetention(globalRetentionDate, dataRetentionBatchSize)
+	return err
+}
@@ -19,10 +19,15 @@ import (
 	"github.com/mattermost/focalboard/server/auth"
 	appModel "github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/cluster"
 	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/jobs"
+	"github.com/mattermost/focalboard/server/services/ldap"
+	"github.com/mattermost/focalboard/server/services/mail"
 	"github.com/mattermost/focalboard/server/services/metrics"
 	"github.com/mattermost/focalboard/server/services/notify"
 	"github.com/mattermost/focalboard/server/services/notify/notifylogger"
+	"github.com/mattermost/focalboard/server/services/oidc"
 	"github.com/mattermost/focalboard/server/services/scheduler"
 	"github.com/mattermost/focalboard/server/services/store"
 	"github.com/mattermost/focalboard/server/services/store/sqlstore"
@@ -38,10 +43,7 @@ import (
 )
 
 const (
-	cleanupSessionTaskFrequency = 10 * time.Minute
-	updateMetricsTaskFrequency  = 15 * time.Minute
-
-	minSessionExpiryTime = int64(60 * 60 * 24 * 31) // 31 days
+	updateMetricsTaskFrequency = 15 * time.Minute
 
 	MattermostAuthMod = "mattermost"
 )
@@ -49,12 +51,14 @@ const (
 type Server struct {
 	config                 *config.Configuration
 	wsAdapter              ws.Adapter
+	clusterAdapter         *ws.ClusterAdapter
 	webServer              *web.Server
 	store                  store.Store
 	filesBackend           filestore.FileBackend
 	telemetry              *telemetry.Service
 	logger                 mlog.LoggerIFace
-	cleanUpSessionsTask    *scheduler.ScheduledTask
+	leaderElector          *scheduler.LeaderElector
+	jobsService            *jobs.Service
 	metricsServer          *metrics.Service
 	metricsService         *metrics.Metrics
 	metricsUpdaterTask     *scheduler.ScheduledTask
@@ -75,10 +79,34 @@ func New(params Params) (*Server, error) {
 
 	authenticator := auth.New(params.Cfg, params.DBStore, params.PermissionsService)
 
+	// Init metrics
+	instanceInfo := metrics.InstanceInfo{
+		Version:        appModel.CurrentVersion,
+		BuildNum:       appModel.BuildNumber,
+		Edition:        appModel.Edition,
+		InstallationID: os.Getenv("MM_CLOUD_INSTALLATION_ID"),
+	}
+	metricsService := metrics.NewMetrics(instanceInfo)
+
 	// if no ws adapter is provided, we spin up a websocket server
 	wsAdapter := params.WSAdapter
+	var clusterAdapter *ws.ClusterAdapter
+	var wsServer *ws.Server
 	if wsAdapter == nil {
-		wsAdapter = ws.NewServer(authenticator, params.SingleUserToken, params.Cfg.AuthMode == MattermostAuthMod, params.Logger, params.DBStore)
+		wsServer = ws.NewServer(authenticator, params.SingleUserToken, params.Cfg.AuthMode == MattermostAuthMod, params.Logger, params.DBStore, metricsService)
+		wsAdapter = wsServer
+
+		if params.Cfg.Cluster.Enable {
+			if params.Cfg.DBType != appModel.PostgresDBType {
+				return nil, errors.New("clustering requires a postgres database")
+			}
+			bus, err := cluster.NewPostgresBus(params.Cfg.DBConfigString, params.Cfg.Cluster.Channel, params.Logger)
+			if err != nil {
+				return nil, fmt.Errorf("unable to create the cluster bus: %w", err)
+			}
+			clusterAdapter = ws.NewClusterAdapter(wsAdapter, bus, params.Logger)
+			wsAdapter = clusterAdapter
+		}
 	}
 
 	filesBackendSettings := filestore.FileBackendSettings{}
@@ -105,15 +133,6 @@ func New(params Params) (*Server, error) {
 
 	webhookClient := webhook.NewClient(params.Cfg, params.Logger)
 
-	// Init metrics
-	instanceInfo := metrics.InstanceInfo{
-		Version:        appModel.CurrentVersion,
-		BuildNum:       appModel.BuildNumber,
-		Edition:        appModel.Edition,
-		InstallationID: os.Getenv("MM_CLOUD_INSTALLATION_ID"),
-	}
-	metricsService := metrics.NewMetrics(instanceInfo)
-
 	// Init audit
 	auditService, errAudit := audit.NewAudit()
 	if errAudit != nil {
@@ -129,6 +148,27 @@ func New(params Params) (*Server, error) {
 		return nil, fmt.Errorf("cannot initialize notification service(s): %w", errNotify)
 	}
 
+	var oidcProvider *oidc.Provider
+	if params.Cfg.AuthMode == appModel.AuthModeOIDC {
+		oidcProvider = oidc.New(params.Cfg.OIDC, params.Cfg.ServerRoot)
+	}
+
+	var ldapService *ldap.Service
+	if params.Cfg.AuthMode == appModel.AuthModeLDAP {
+		ldapService = ldap.New(params.Cfg.LDAP)
+	}
+
+	var mailService *mail.Service
+	if params.Cfg.SMTP.Server != "" {
+		mailService = mail.New(params.Cfg.SMTP)
+	} else if params.Cfg.RequireEmailVerification {
+		return nil, errors.New("email verification requires an SMTP server")
+	}
+
+	// the nodes of a cluster elect the one running the jobs
+	leaderElector := scheduler.NewLeaderElector(params.DBStore, "scheduler", scheduler.DefaultLeaseDuration, params.Logger)
+	jobsService := jobs.New(params.DBStore, leaderElector, params.Logger)
+
 	appServices := app.Services{
 		Auth:             authenticator,
 		Store:            params.DBStore,
@@ -138,10 +178,17 @@ func New(params Params) (*Server, error) {
 		Notifications:    notificationService,
 		Logger:           params.Logger,
 		Permissions:      params.PermissionsService,
+		OIDC:             oidcProvider,
+		LDAP:             ldapService,
+		Mail:             mailService,
+		Jobs:             jobsService,
 		ServicesAPI:      params.ServicesAPI,
 		SkipTemplateInit: utils.IsRunningUnitTests(),
 	}
 	app := app.New(params.Cfg, wsAdapter, appServices)
+	if wsServer != nil {
+		wsServer.SetTextBlockSaver(app)
+	}
 
 	focalboardAPI := api.NewAPI(app, params.SingleUserToken, params.Cfg.AuthMode, params.PermissionsService, params.Logger, auditService)
 
@@ -189,6 +236,7 @@ func New(params Params) (*Server, error) {
 	server := Server{
 		config:              params.Cfg,
 		wsAdapter:           wsAdapter,
+		clusterAdapter:      clusterAdapter,
 		webServer:           webServer,
 		store:               params.DBStore,
 		filesBackend:        filesBackend,
@@ -201,10 +249,16 @@ func New(params Params) (*Server, error) {
 		localRouter:         localRouter,
 		api:                 focalboardAPI,
 		app:                 app,
+		leaderElector:       leaderElector,
+		jobsService:         jobsService,
 	}
 
 	server.initHandlers()
 
+	if err := server.registerJobs(); err != nil {
+		return nil, err
+	}
+
 	return &server, nil
 }
 
@@ -253,17 +307,15 @@ func (s *Server) Start() error {
 		}
 	}
 
-	if s.config.AuthMode != MattermostAuthMod {
-		s.cleanUpSessionsTask = scheduler.CreateRecurringTask("cleanUpSessions", func() {
-			secondsAgo := minSessionExpiryTime
-			if secondsAgo < s.config.SessionExpireTime {
-				secondsAgo = s.config.SessionExpireTime
-			}
+	if s.clusterAdapter != nil {
+		if err := s.clusterAdapter.Start(); err != nil {
+			return fmt.Errorf("unable to start the cluster bus: %w", err)
+		}
+	}
 
-			if err := s.store.CleanUpSessions(secondsAgo); err != nil {
-				s.logger.Error("Unable to clean up the sessions", mlog.Err(err))
-			}
-		}, cleanupSessionTaskFrequency)
+	s.leaderElector.Start()
+	if err := s.jobsService.Start(); err != nil {
+		return err
 	}
 
 	metricsUpdater := func() {
@@ -327,14 +379,19 @@ func (s *Server) Shutdown() error {
 	s.servicesStartStopMutex.Lock()
 	defer s.servicesStartStopMutex.Unlock()
 
-	if s.cleanUpSessionsTask != nil {
-		s.cleanUpSessionsTask.Cancel()
-	}
-
 	if s.metricsUpdaterTask != nil {
 		s.metricsUpdaterTask.Cancel()
 	}
 
+	s.jobsService.Shutdown()
+	s.leaderElector.Shutdown()
+
+	if s.clusterAdapter != nil {
+		if err := s.clusterAdapter.Shutdown(); err != nil {
+			s.logger.Warn("Error occurred when shutting down the cluster bus", mlog.Err(err))
+		}
+	}
+
 	if err := s.telemetry.Shutdown(); err != nil {
 		s.logger.Warn("Error occurred when shutting down telemetry", mlog.Err(err))
 	}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	// AccessTokenPrefix identifies personal access tokens so they can be
+	// told apart from session tokens when parsing a request.
+	AccessTokenPrefix = "fbpat_"
+
+	accessTokenRandomLength = 40
+)
+
+// NewAccessToken generates a new random personal access token.
+func NewAccessToken() string {
+	return AccessTokenPrefix + mmModel.NewRandomString(accessTokenRandomLength)
+}
+
+// HashToken returns the hash under which a random secret token, such as
+// an access token or an MFA recovery code, is stored. These tokens are
+// random and long enough that a fast hash is sufficient, and it allows
+// looking the token up by its hash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
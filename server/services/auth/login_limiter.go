@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginLimiterSettings configures a LoginLimiter. A zero MaxFailures
+// disables the lockout and a zero Delay disables the delays.
+type LoginLimiterSettings struct {
+	// MaxFailures is the number of consecutive failures locking a key
+	MaxFailures int
+	// Lockout is how long a key stays locked, and how long its failures
+	// are remembered
+	Lockout time.Duration
+	// Delay is the delay after the first failure, doubled after every
+	// following failure
+	Delay time.Duration
+	// MaxDelay caps the delays
+	MaxDelay time.Duration
+}
+
+type loginFailures struct {
+	count       int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// LoginLimiter counts the failed logins by key, such as an account or an
+// IP address, and locks the keys with too many consecutive failures. The
+// failures are kept in memory and forgotten after the lockout duration.
+type LoginLimiter struct {
+	settings LoginLimiterSettings
+	now      func() time.Time
+
+	mu        sync.Mutex
+	failures  map[string]*loginFailures
+	lastSweep time.Time
+}
+
+// NewLoginLimiter creates a limiter without any failure.
+func NewLoginLimiter(settings LoginLimiterSettings) *LoginLimiter {
+	return &LoginLimiter{
+		settings: settings,
+		now:      time.Now,
+		failures: map[string]*loginFailures{},
+	}
+}
+
+// LockedUntil returns the end of the lockout of a key, and false if the
+// key is not locked.
+func (l *LoginLimiter) LockedUntil(key string) (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	failures, ok := l.failures[key]
+	if !ok || !l.now().Before(failures.lockedUntil) {
+		return time.Time{}, false
+	}
+	return failures.lockedUntil, true
+}
+
+// Fail records a failure for a key and returns the number of consecutive
+// failures. The key is locked, and the end of the lockout returned, when
+// the failures reach the maximum.
+func (l *LoginLimiter) Fail(key string) (int, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	failures, ok := l.failures[key]
+	if !ok || l.expired(failures, now) {
+		failures = &loginFailures{}
+		l.failures[key] = failures
+	}
+	failures.count++
+	failures.lastFailure = now
+
+	if l.settings.MaxFailures > 0 && failures.count >= l.settings.MaxFailures {
+		// the key starts over once the lockout ends
+		failures.count = 0
+		failures.lockedUntil = now.Add(l.settings.Lockout)
+		return l.settings.MaxFailures, failures.lockedUntil
+	}
+	return failures.count, time.Time{}
+}
+
+// Reset forgets the failures of a key and unlocks it.
+func (l *LoginLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+}
+
+// Delay returns the delay to apply after the given number of consecutive
+// failures.
+func (l *LoginLimiter) Delay(failures int) time.Duration {
+	if l.settings.Delay <= 0 || failures <= 0 {
+		return 0
+	}
+
+	delay := l.settings.Delay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if l.settings.MaxDelay > 0 && delay >= l.settings.MaxDelay {
+			return l.settings.MaxDelay
+		}
+	}
+	if l.settings.MaxDelay > 0 && delay > l.settings.MaxDelay {
+		return l.settings.MaxDelay
+	}
+	return delay
+}
+
+func (l *LoginLimiter) expired(failures *loginFailures, now time.Time) bool {
+	return now.After(failures.lockedUntil) && now.Sub(failures.lastFailure) > l.settings.Lockout
+}
+
+// sweep removes the expired failures, at most once per lockout duration.
+func (l *LoginLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.settings.Lockout {
+		return
+	}
+	l.lastSweep = now
+
+	for key, failures := range l.failures {
+		if l.expired(failures, now) {
+			delete(l.failures, key)
+		}
+	}
+}
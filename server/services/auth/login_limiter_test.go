@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLoginLimiter(settings LoginLimiterSettings) (*LoginLimiter, *time.Time) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewLoginLimiter(settings)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestLoginLimiterLockout(t *testing.T) {
+	limiter, now := newTestLoginLimiter(LoginLimiterSettings{
+		MaxFailures: 3,
+		Lockout:     10 * time.Minute,
+	})
+
+	count, lockedUntil := limiter.Fail("key")
+	require.Equal(t, 1, count)
+	require.True(t, lockedUntil.IsZero())
+
+	count, _ = limiter.Fail("key")
+	require.Equal(t, 2, count)
+	_, locked := limiter.LockedUntil("key")
+	require.False(t, locked)
+
+	count, lockedUntil = limiter.Fail("key")
+	require.Equal(t, 3, count)
+	require.Equal(t, now.Add(10*time.Minute), lockedUntil)
+
+	t.Run("other keys are not locked", func(t *testing.T) {
+		_, locked := limiter.LockedUntil("other-key")
+		require.False(t, locked)
+	})
+
+	t.Run("the lockout is temporary", func(t *testing.T) {
+		*now = now.Add(9 * time.Minute)
+		until, locked := limiter.LockedUntil("key")
+		require.True(t, locked)
+		require.Equal(t, lockedUntil, until)
+
+		*now = now.Add(time.Minute)
+		_, locked = limiter.LockedUntil("key")
+		require.False(t, locked)
+
+		count, _ := limiter.Fail("key")
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("reset unlocks", func(t *testing.T) {
+		limiter.Fail("key")
+		_, lockedUntil := limiter.Fail("key")
+		require.False(t, lockedUntil.IsZero())
+
+		limiter.Reset("key")
+		_, locked := limiter.LockedUntil("key")
+		require.False(t, locked)
+	})
+}
+
+func TestLoginLimiterForgetsFailures(t *testing.T) {
+	limiter, now := newTestLoginLimiter(LoginLimiterSettings{
+		MaxFailures: 3,
+		Lockout:     10 * time.Minute,
+	})
+
+	limiter.Fail("key")
+	limiter.Fail("key")
+
+	*now = now.Add(11 * time.Minute)
+	count, lockedUntil := limiter.Fail("key")
+	require.Equal(t, 1, count)
+	require.True(t, lockedUntil.IsZero())
+
+	t.Run("expired failures are removed", func(t *testing.T) {
+		limiter.Fail("other-key")
+		*now = now.Add(11 * time.Minute)
+		limiter.Fail("key")
+		require.Len(t, limiter.failures, 1)
+	})
+}
+
+func TestLoginLimiterWithoutLockout(t *testing.T) {
+	limiter, _ := newTestLoginLimiter(LoginLimiterSettings{Lockout: time.Minute})
+
+	for i := 1; i <= 10; i++ {
+		count, lockedUntil := limiter.Fail("key")
+		require.Equal(t, i, count)
+		require.True(t, lockedUntil.IsZero())
+	}
+}
+
+func TestLoginLimiterDelay(t *testing.T) {
+	limiter := NewLoginLimiter(LoginLimiterSettings{
+		Delay:    100 * time.Millisecond,
+		MaxDelay: time.Second,
+	})
+
+	require.Zero(t, limiter.Delay(0))
+	require.Equal(t, 100*time.Millisecond, limiter.Delay(1))
+	require.Equal(t, 200*time.Millisecond, limiter.Delay(2))
+	require.Equal(t, 800*time.Millisecond, limiter.Delay(4))
+	require.Equal(t, time.Second, limiter.Delay(5))
+	require.Equal(t, time.Second, limiter.Delay(1000))
+
+	require.Zero(t, NewLoginLimiter(LoginLimiterSettings{}).Delay(3))
+}
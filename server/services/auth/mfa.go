@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	MfaIssuer            = "Focalboard"
+	MfaRecoveryCodeCount = 10
+
+	mfaSecretLength       = 20
+	mfaCodeDigits         = 6
+	mfaCodeModulo         = 1000000
+	mfaPeriod             = 30 * time.Second
+	mfaAllowedSkew        = 1
+	mfaRecoveryCodeLength = 10
+)
+
+var mfaSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewMfaSecret generates a new random base32 encoded TOTP secret.
+func NewMfaSecret() (string, error) {
+	secret := make([]byte, mfaSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return mfaSecretEncoding.EncodeToString(secret), nil
+}
+
+// MfaProvisioningURI returns the otpauth URI that authenticator apps
+// read, usually from a QR code, to enrol the secret.
+func MfaProvisioningURI(secret, accountName string) string {
+	label := url.PathEscape(MfaIssuer + ":" + accountName)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", MfaIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(mfaCodeDigits))
+	params.Set("period", fmt.Sprint(int(mfaPeriod.Seconds())))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// GenerateTotpCode returns the RFC 6238 code for the secret at the given time.
+func GenerateTotpCode(secret string, t time.Time) (string, error) {
+	key, err := decodeMfaSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return totpCode(key, uint64(t.Unix()/int64(mfaPeriod.Seconds()))), nil
+}
+
+// ValidateTotpCode checks a code against the secret, accepting codes of
+// the adjacent time steps to allow for clock skew. It returns the time
+// step of the code, so that the caller can refuse codes of the time steps
+// already used.
+func ValidateTotpCode(secret, code string, t time.Time) (int64, bool, error) {
+	key, err := decodeMfaSecret(secret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != mfaCodeDigits {
+		return 0, false, nil
+	}
+
+	counter := t.Unix() / int64(mfaPeriod.Seconds())
+	for skew := -mfaAllowedSkew; skew <= mfaAllowedSkew; skew++ {
+		expected := totpCode(key, uint64(counter+int64(skew)))
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return counter + int64(skew), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// NewMfaRecoveryCodes generates a set of single-use recovery codes.
+func NewMfaRecoveryCodes() []string {
+	codes := make([]string, MfaRecoveryCodeCount)
+	for i := range codes {
+		code := mmModel.NewRandomString(mfaRecoveryCodeLength)
+		codes[i] = code[:mfaRecoveryCodeLength/2] + "-" + code[mfaRecoveryCodeLength/2:]
+	}
+	return codes
+}
+
+// NormalizeMfaRecoveryCode removes the formatting users may add or
+// drop when typing a recovery code.
+func NormalizeMfaRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != mfaRecoveryCodeLength {
+		return code
+	}
+	return code[:mfaRecoveryCodeLength/2] + "-" + code[mfaRecoveryCodeLength/2:]
+}
+
+func decodeMfaSecret(secret string) ([]byte, error) {
+	key, err := mfaSecretEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MFA secret: %w", err)
+	}
+	return key, nil
+}
+
+func totpCode(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", mfaCodeDigits, value%mfaCodeModulo)
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the base32 encoding of the SHA1 test key from RFC 6238.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTotpCode(t *testing.T) {
+	for unix, expected := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		code, err := GenerateTotpCode(rfc6238Secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+
+	_, err := GenerateTotpCode("not base32!", time.Now())
+	require.Error(t, err)
+}
+
+func TestValidateTotpCode(t *testing.T) {
+	secret, err := NewMfaSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := GenerateTotpCode(secret, now)
+	require.NoError(t, err)
+
+	counter := now.Unix() / int64(mfaPeriod.Seconds())
+
+	t.Run("current code", func(t *testing.T) {
+		codeCounter, valid, err := ValidateTotpCode(secret, code, now)
+		require.NoError(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, counter, codeCounter)
+	})
+
+	t.Run("code of the previous time step", func(t *testing.T) {
+		codeCounter, valid, err := ValidateTotpCode(secret, code, now.Add(mfaPeriod))
+		require.NoError(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, counter, codeCounter)
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		_, valid, err := ValidateTotpCode(secret, code, now.Add(3*mfaPeriod))
+		require.NoError(t, err)
+		assert.False(t, valid)
+	})
+
+	t.Run("malformed code", func(t *testing.T) {
+		for _, malformed := range []string{"", "12345", "1234567", "abcdef"} {
+			_, valid, err := ValidateTotpCode(secret, malformed, now)
+			require.NoError(t, err)
+			assert.False(t, valid)
+		}
+	})
+}
+
+func TestMfaProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(MfaProvisioningURI(rfc6238Secret, "john doe"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Focalboard:john doe", uri.Path)
+	assert.Equal(t, rfc6238Secret, uri.Query().Get("secret"))
+	assert.Equal(t, MfaIssuer, uri.Query().Get("issuer"))
+}
+
+func TestMfaRecoveryCodes(t *testing.T) {
+	codes := NewMfaRecoveryCodes()
+	require.Len(t, codes, MfaRecoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Len(t, code, mfaRecoveryCodeLength+1)
+		assert.Equal(t, code, NormalizeMfaRecoveryCode(code))
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+
+	assert.Equal(t, "abcde-fghij", NormalizeMfaRecoveryCode(" ABCDEFGHIJ "))
+	assert.Equal(t, "abcde-fghij", NormalizeMfaRecoveryCode("abcde fghij"))
+}
@@ -0,0 +1,19 @@
+// Package cluster relays events between the nodes of a standalone
+// deployment, so that several servers can run against one database.
+package cluster
+
+// Bus delivers the messages published by a node to the other nodes of
+// the cluster. Delivery is best effort: messages published while a node
+// is disconnected may be lost.
+type Bus interface {
+	// Start begins the delivery of the messages of the other nodes to
+	// the handler. The messages published by this node aren't delivered
+	// to it.
+	Start(handler func(data []byte)) error
+
+	// Publish sends a message to the other nodes.
+	Publish(data []byte) error
+
+	// Shutdown stops the delivery of the messages.
+	Shutdown() error
+}
@@ -0,0 +1,279 @@
+package cluster
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// maxChunkSize is the size of the data sent in a notification. Postgres
+	// limits the payload of a notification to 8000 bytes, which leaves room
+	// for the envelope.
+	maxChunkSize = 7000
+
+	// partialMessageTimeout is the time after which the chunks received
+	// for an incomplete message are dropped.
+	partialMessageTimeout = time.Minute
+
+	minReconnectInterval = time.Second
+	maxReconnectInterval = time.Minute
+	listenerPingInterval = 90 * time.Second
+)
+
+var errBusStarted = errors.New("the cluster bus is already started")
+
+// notification is a chunk of a message, as sent through Postgres.
+type notification struct {
+	NodeID    string `json:"n"`
+	MessageID string `json:"m"`
+	Index     int    `json:"i"`
+	Count     int    `json:"c"`
+	Data      string `json:"d"`
+}
+
+// PostgresBus is a Bus that relays messages through the LISTEN and
+// NOTIFY commands of Postgres. Messages larger than a notification are
+// split into chunks and reassembled by the receivers.
+type PostgresBus struct {
+	nodeID     string
+	channel    string
+	connString string
+	db         *sql.DB
+	logger     mlog.LoggerIFace
+
+	mu       sync.Mutex
+	listener *pq.Listener
+	done     chan struct{}
+	stopped  sync.WaitGroup
+}
+
+// NewPostgresBus creates a bus that relays messages on a Postgres
+// channel. The nodes of a cluster must use the same channel.
+func NewPostgresBus(connString, channel string, logger mlog.LoggerIFace) (*PostgresBus, error) {
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the cluster bus connection: %w", err)
+	}
+
+	return &PostgresBus{
+		nodeID:     utils.NewID(utils.IDTypeNone),
+		channel:    channel,
+		connString: connString,
+		db:         db,
+		logger:     logger,
+	}, nil
+}
+
+// Start listens to the channel, and calls the handler for every message
+// published by another node.
+func (b *PostgresBus) Start(handler func(data []byte)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.listener != nil {
+		return errBusStarted
+	}
+
+	listener := pq.NewListener(b.connString, minReconnectInterval, maxReconnectInterval, b.onListenerEvent)
+	if err := listener.Listen(b.channel); err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("cannot listen to the cluster channel %s: %w", b.channel, err)
+	}
+
+	b.listener = listener
+	b.done = make(chan struct{})
+	b.stopped.Add(1)
+	go b.receive(listener, b.done, handler)
+
+	b.logger.Info("Cluster bus started",
+		mlog.String("node_id", b.nodeID),
+		mlog.String("channel", b.channel),
+	)
+	return nil
+}
+
+// Publish sends a message to the other nodes, in as many notifications
+// as needed.
+func (b *PostgresBus) Publish(data []byte) error {
+	for _, n := range splitMessage(b.nodeID, utils.NewID(utils.IDTypeNone), data) {
+		payload, err := json.Marshal(n)
+		if err != nil {
+			return err
+		}
+		if _, err := b.db.Exec("SELECT pg_notify($1, $2)", b.channel, string(payload)); err != nil {
+			return fmt.Errorf("cannot publish to the cluster channel %s: %w", b.channel, err)
+		}
+	}
+	return nil
+}
+
+// Shutdown stops listening to the channel, and closes the connections.
+func (b *PostgresBus) Shutdown() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.listener != nil {
+		close(b.done)
+		b.stopped.Wait()
+		if err := b.listener.Close(); err != nil {
+			b.logger.Warn("Error closing the cluster bus listener", mlog.Err(err))
+		}
+		b.listener = nil
+	}
+	return b.db.Close()
+}
+
+func (b *PostgresBus) onListenerEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		b.logger.Warn("Cluster bus disconnected", mlog.Err(err))
+	case pq.ListenerEventReconnected:
+		// the notifications sent while disconnected are lost
+		b.logger.Warn("Cluster bus reconnected, the events of the other nodes may have been missed")
+	case pq.ListenerEventConnectionAttemptFailed:
+		b.logger.Error("Cluster bus connection attempt failed", mlog.Err(err))
+	}
+}
+
+func (b *PostgresBus) receive(listener *pq.Listener, done chan struct{}, handler func(data []byte)) {
+	defer b.stopped.Done()
+
+	assembler := newMessageAssembler(b.nodeID)
+	for {
+		select {
+		case <-done:
+			return
+		case n, ok := <-listener.Notify:
+			if !ok {
+				return
+			}
+			// a nil notification is sent on reconnection
+			if n == nil {
+				continue
+			}
+			data, err := assembler.add(n.Extra, time.Now())
+			if err != nil {
+				b.logger.Error("Invalid cluster notification", mlog.Err(err))
+				continue
+			}
+			if data != nil {
+				handler(data)
+			}
+		case <-time.After(listenerPingInterval):
+			go func() {
+				if err := listener.Ping(); err != nil {
+					b.logger.Debug("Cluster bus ping failed", mlog.Err(err))
+				}
+			}()
+		}
+	}
+}
+
+// splitMessage splits a message in the notifications that carry it.
+func splitMessage(nodeID, messageID string, data []byte) []notification {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	count := (len(encoded) + maxChunkSize - 1) / maxChunkSize
+	if count == 0 {
+		count = 1
+	}
+
+	notifications := make([]notification, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * maxChunkSize
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		notifications = append(notifications, notification{
+			NodeID:    nodeID,
+			MessageID: messageID,
+			Index:     i,
+			Count:     count,
+			Data:      encoded[i*maxChunkSize : end],
+		})
+	}
+	return notifications
+}
+
+type partialMessage struct {
+	chunks   []string
+	received int
+	started  time.Time
+}
+
+// messageAssembler reassembles the messages of the other nodes from
+// their notifications.
+type messageAssembler struct {
+	nodeID  string
+	partial map[string]*partialMessage
+}
+
+func newMessageAssembler(nodeID string) *messageAssembler {
+	return &messageAssembler{
+		nodeID:  nodeID,
+		partial: make(map[string]*partialMessage),
+	}
+}
+
+// add processes a notification payload. It returns the message once all
+// of its chunks are received, and nil otherwise, or for the messages of
+// this node.
+func (a *messageAssembler) add(payload string, now time.Time) ([]byte, error) {
+	a.dropStale(now)
+
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return nil, err
+	}
+	if n.NodeID == a.nodeID {
+		return nil, nil
+	}
+	if n.Count < 1 || n.Index < 0 || n.Index >= n.Count {
+		return nil, fmt.Errorf("invalid chunk %d of %d for message %s", n.Index, n.Count, n.MessageID)
+	}
+
+	if n.Count == 1 {
+		return base64.StdEncoding.DecodeString(n.Data)
+	}
+
+	message, ok := a.partial[n.MessageID]
+	if !ok {
+		message = &partialMessage{chunks: make([]string, n.Count), started: now}
+		a.partial[n.MessageID] = message
+	}
+	if len(message.chunks) != n.Count {
+		delete(a.partial, n.MessageID)
+		return nil, fmt.Errorf("inconsistent chunk count for message %s", n.MessageID)
+	}
+	if message.chunks[n.Index] == "" {
+		message.received++
+	}
+	message.chunks[n.Index] = n.Data
+	if message.received < n.Count {
+		return nil, nil
+	}
+
+	delete(a.partial, n.MessageID)
+	return base64.StdEncoding.DecodeString(strings.Join(message.chunks, ""))
+}
+
+// dropStale drops the messages whose chunks stopped coming, as they
+// were lost while the listener was disconnected.
+func (a *messageAssembler) dropStale(now time.Time) {
+	for id, message := range a.partial {
+		if now.Sub(message.started) > partialMessageTimeout {
+			delete(a.partial, id)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func notificationPayload(t *testing.T, n notification) string {
+	payload, err := json.Marshal(n)
+	require.NoError(t, err)
+	return string(payload)
+}
+
+func TestSplitMessage(t *testing.T) {
+	t.Run("a small message fits in a notification", func(t *testing.T) {
+		notifications := splitMessage("node-1", "message-1", []byte("hello"))
+		require.Len(t, notifications, 1)
+		require.Equal(t, 1, notifications[0].Count)
+	})
+
+	t.Run("a large message is split", func(t *testing.T) {
+		data := bytes.Repeat([]byte("a"), maxChunkSize*2)
+		notifications := splitMessage("node-1", "message-1", data)
+		require.Len(t, notifications, 3)
+		for i, n := range notifications {
+			require.Equal(t, i, n.Index)
+			require.Equal(t, 3, n.Count)
+			require.LessOrEqual(t, len(notificationPayload(t, n)), 8000)
+		}
+	})
+}
+
+func TestMessageAssembler(t *testing.T) {
+	now := time.Now()
+
+	t.Run("the messages of the node are skipped", func(t *testing.T) {
+		assembler := newMessageAssembler("node-1")
+		for _, n := range splitMessage("node-1", "message-1", []byte("hello")) {
+			data, err := assembler.add(notificationPayload(t, n), now)
+			require.NoError(t, err)
+			require.Nil(t, data)
+		}
+	})
+
+	t.Run("a message is reassembled in any order", func(t *testing.T) {
+		assembler := newMessageAssembler("node-1")
+		data := bytes.Repeat([]byte("abcdef"), maxChunkSize)
+		notifications := splitMessage("node-2", "message-1", data)
+		require.Greater(t, len(notifications), 2)
+
+		last := len(notifications) - 1
+		notifications[0], notifications[last] = notifications[last], notifications[0]
+		for i, n := range notifications {
+			received, err := assembler.add(notificationPayload(t, n), now)
+			require.NoError(t, err)
+			if i < last {
+				require.Nil(t, received)
+			} else {
+				require.Equal(t, data, received)
+			}
+		}
+		require.Empty(t, assembler.partial)
+	})
+
+	t.Run("incomplete messages are dropped", func(t *testing.T) {
+		assembler := newMessageAssembler("node-1")
+		notifications := splitMessage("node-2", "message-1", bytes.Repeat([]byte("a"), maxChunkSize))
+		_, err := assembler.add(notificationPayload(t, notifications[0]), now)
+		require.NoError(t, err)
+		require.Len(t, assembler.partial, 1)
+
+		later := now.Add(partialMessageTimeout + time.Second)
+		received, err := assembler.add(notificationPayload(t, splitMessage("node-2", "message-2", []byte("hello"))[0]), later)
+		require.NoError(t, err)
+		require.Equal(t, []byte("hello"), received)
+		require.Empty(t, assembler.partial)
+	})
+
+	t.Run("invalid notifications", func(t *testing.T) {
+		assembler := newMessageAssembler("node-1")
+		_, err := assembler.add("not json", now)
+		require.Error(t, err)
+
+		_, err = assembler.add(notificationPayload(t, notification{NodeID: "node-2", MessageID: "message-1", Index: 2, Count: 2}), now)
+		require.Error(t, err)
+	})
+}
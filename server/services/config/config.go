@@ -26,6 +26,107 @@ type AmazonS3Config struct {
 	Timeout         int64
 }
 
+// OIDCConfig is the configuration of the OpenID Connect provider used
+// when the auth mode is "oidc".
+type OIDCConfig struct {
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	Scopes        []string
+	UsernameClaim string
+	RolesClaim    string
+	// AdminRoles are the values of the roles claim that make a user a
+	// system admin. When empty, the claims never make system admins.
+	AdminRoles []string
+}
+
+// LDAPConfig is the configuration of the directory used when the auth
+// mode is "ldap".
+type LDAPConfig struct {
+	URL                  string
+	StartTLS             bool
+	InsecureSkipVerify   bool
+	BindDN               string
+	BindPassword         string
+	BaseDN               string
+	UserFilter           string
+	IDAttribute          string
+	UsernameAttribute    string
+	EmailAttribute       string
+	FirstNameAttribute   string
+	LastNameAttribute    string
+	NicknameAttribute    string
+	GroupBaseDN          string
+	GroupFilter          string
+	GroupNameAttribute   string
+	GroupMemberAttribute string
+	AdminGroups          []string
+	GroupBoards          []LDAPGroupBoard
+	SyncIntervalMinutes  int
+}
+
+// LDAPGroupBoard gives the members of an LDAP group a role on a board.
+type LDAPGroupBoard struct {
+	Group   string
+	BoardID string
+	Role    string
+}
+
+// SMTPConfig is the configuration of the server used to send emails,
+// such as the password reset links. ConnectionSecurity is either empty,
+// "TLS" or "STARTTLS".
+type SMTPConfig struct {
+	Server                            string
+	Port                              int
+	Username                          string
+	Password                          string
+	ConnectionSecurity                string
+	SkipServerCertificateVerification bool
+	FromAddress                       string
+	FromName                          string
+}
+
+// PasswordConfig is the policy the passwords of the native users must
+// follow. Passwords expire MaxAgeDays after being set, and cannot reuse
+// any of the last HistoryDepth passwords of the user. Zero disables them.
+type PasswordConfig struct {
+	MinimumLength int
+	Lowercase     bool
+	Uppercase     bool
+	Number        bool
+	Symbol        bool
+	MaxAgeDays    int
+	HistoryDepth  int
+}
+
+// LoginLockoutConfig limits the failed logins per account and per IP
+// address. A key is locked for LockoutMinutes after the maximum number
+// of consecutive failures, and every failure is delayed, starting with
+// DelayMilliseconds and doubling up to MaxDelayMilliseconds. A zero
+// maximum or delay disables it.
+type LoginLockoutConfig struct {
+	MaxAccountFailures   int
+	MaxIPFailures        int
+	LockoutMinutes       int
+	DelayMilliseconds    int
+	MaxDelayMilliseconds int
+}
+
+// SCIMConfig enables the SCIM 2.0 provisioning endpoints, which the
+// identity providers authenticate to with Token as a bearer token. An
+// empty token disables them.
+type SCIMConfig struct {
+	Token string
+}
+
+// ClusterConfig relays the websocket events between the servers that
+// share a Postgres database, so that several of them can run behind a
+// load balancer. The servers of a cluster must use the same Channel.
+type ClusterConfig struct {
+	Enable  bool
+	Channel string
+}
+
 // Configuration is the app configuration stored in a json file.
 type Configuration struct {
 	ServerRoot               string            `json:"serverRoot" mapstructure:"serverRoot"`
@@ -59,7 +160,19 @@ type Configuration struct {
 	ShowEmailAddress         bool              `json:"show_email_address" mapstructure:"showEmailAddress"`
 	ShowFullName             bool              `json:"show_full_name" mapstructure:"showFullName"`
 
-	AuthMode string `json:"authMode" mapstructure:"authMode"`
+	AuthMode string     `json:"authMode" mapstructure:"authMode"`
+	OIDC     OIDCConfig `json:"oidc" mapstructure:"oidc"`
+	LDAP     LDAPConfig `json:"ldap" mapstructure:"ldap"`
+
+	SMTP                     SMTPConfig     `json:"smtp" mapstructure:"smtp"`
+	PasswordSettings         PasswordConfig `json:"passwordSettings" mapstructure:"passwordSettings"`
+	RequireEmailVerification bool           `json:"requireEmailVerification" mapstructure:"requireEmailVerification"`
+
+	LoginLockout LoginLockoutConfig `json:"loginLockout" mapstructure:"loginLockout"`
+
+	SCIM SCIMConfig `json:"scim" mapstructure:"scim"`
+
+	Cluster ClusterConfig `json:"cluster" mapstructure:"cluster"`
 
 	LoggingCfgFile string `json:"logging_cfg_file" mapstructure:"logging_cfg_file"`
 	LoggingCfgJSON string `json:"logging_cfg_json" mapstructure:"logging_cfg_json"`
@@ -110,6 +223,13 @@ func ReadConfigFile(configFilePath string) (*Configuration, error) {
 	viper.SetDefault("TeammateNameDisplay", "username")
 	viper.SetDefault("ShowEmailAddress", false)
 	viper.SetDefault("ShowFullName", false)
+	viper.SetDefault("LoginLockout.MaxAccountFailures", 10)
+	viper.SetDefault("LoginLockout.MaxIPFailures", 50)
+	viper.SetDefault("LoginLockout.LockoutMinutes", 15)
+	viper.SetDefault("LoginLockout.DelayMilliseconds", 250)
+	viper.SetDefault("LoginLockout.MaxDelayMilliseconds", 4000)
+	viper.SetDefault("Cluster.Enable", false)
+	viper.SetDefault("Cluster.Channel", "focalboard_cluster")
 
 	err := viper.ReadInConfig() // Find and read the config file
 	if err != nil {             // Handle errors reading the config file
@@ -131,5 +251,9 @@ func ReadConfigFile(configFilePath string) (*Configuration, error) {
 
 func removeSecurityData(config Configuration) Configuration {
 	clean := config
+	clean.OIDC.ClientSecret = ""
+	clean.LDAP.BindPassword = ""
+	clean.SMTP.Password = ""
+	clean.SCIM.Token = ""
 	return clean
 }
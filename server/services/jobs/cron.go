@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes the times at which a job is due.
+type Schedule interface {
+	// Next returns the first time the job is due after t.
+	Next(t time.Time) time.Time
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	// 0 and 7 are both sunday
+	{name: "day of week", min: 0, max: 7},
+}
+
+// cronSchedule is a schedule defined by a cron expression. Each field is
+// a set of allowed values, stored as a bitmask.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// restricted day fields, as a day matches either of them when both
+	// are restricted
+	domRestricted, dowRestricted bool
+}
+
+// everySchedule is a schedule that repeats at a fixed interval.
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// ParseSchedule parses a cron expression with the minute, hour, day of
+// month, month and day of week fields, such as "*/10 * * * *". Each
+// field accepts "*", values, ranges, lists and steps. The @hourly,
+// @daily, @weekly, @monthly and @yearly descriptors are accepted, as
+// well as "@every <duration>" for a fixed interval. Times are in the
+// local time zone of the server.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval in schedule %q: %w", spec, err)
+		}
+		if interval < time.Minute {
+			return nil, fmt.Errorf("the interval of schedule %q is shorter than a minute", spec)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+
+	if expr, ok := cronDescriptors[spec]; ok {
+		spec = expr
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("invalid schedule %q: expected %d fields, got %d", spec, len(cronFields), len(parts))
+	}
+
+	values := make([]uint64, len(cronFields))
+	for i, field := range cronFields {
+		bits, err := parseCronField(parts[i], field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		values[i] = bits
+	}
+
+	dow := values[4]
+	if dow&(1<<7) != 0 {
+		dow |= 1
+	}
+
+	return &cronSchedule{
+		minute:        values[0],
+		hour:          values[1],
+		dom:           values[2],
+		month:         values[3],
+		dow:           dow,
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		itemBits, err := parseCronItem(item, field)
+		if err != nil {
+			return 0, err
+		}
+		bits |= itemBits
+	}
+	return bits, nil
+}
+
+// parseCronItem parses a "*", a value or a range, with an optional step.
+func parseCronItem(item string, field cronField) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step < 1 {
+			return 0, fmt.Errorf("invalid step %q in the %s field", stepPart, field.name)
+		}
+	}
+
+	start, end := field.min, field.max
+	if rangePart != "*" {
+		startPart, endPart, isRange := strings.Cut(rangePart, "-")
+		var err error
+		if start, err = parseCronValue(startPart, field); err != nil {
+			return 0, err
+		}
+		switch {
+		case isRange:
+			if end, err = parseCronValue(endPart, field); err != nil {
+				return 0, err
+			}
+		case !hasStep:
+			end = start
+		}
+		if end < start {
+			return 0, fmt.Errorf("invalid range %q in the %s field", rangePart, field.name)
+		}
+	}
+
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, field cronField) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < field.min || v > field.max {
+		return 0, fmt.Errorf("invalid value %q in the %s field", value, field.name)
+	}
+	return v, nil
+}
+
+// maxCronYears bounds the search of the next time, for the schedules
+// that never match, such as the 31st of February.
+const maxCronYears = 5
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxCronYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
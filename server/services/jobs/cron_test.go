@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 10, 7, 30, 0, time.UTC) // a wednesday
+
+	testCases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.January, 31, 10, 8, 0, 0, time.UTC)},
+		{"*/10 * * * *", time.Date(2024, time.January, 31, 10, 10, 0, 0, time.UTC)},
+		{"5 * * * *", time.Date(2024, time.January, 31, 11, 5, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2024, time.February, 1, 2, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 * * 1-5", time.Date(2024, time.January, 31, 13, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 5", time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{"0,30 12 1,15 * *", time.Date(2024, time.February, 1, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", start.Add(90 * time.Minute)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tc.spec)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, schedule.Next(start))
+		})
+	}
+
+	t.Run("a schedule that never matches", func(t *testing.T) {
+		schedule, err := ParseSchedule("0 0 31 2 *")
+		require.NoError(t, err)
+		require.True(t, schedule.Next(start).IsZero())
+	})
+
+	t.Run("invalid schedules", func(t *testing.T) {
+		for _, spec := range []string{
+			"",
+			"* * * *",
+			"60 * * * *",
+			"* 24 * * *",
+			"* * 0 * *",
+			"* * * 13 *",
+			"* * * * 8",
+			"*/0 * * * *",
+			"5-1 * * * *",
+			"a * * * *",
+			"@every 10s",
+			"@every soon",
+		} {
+			_, err := ParseSchedule(spec)
+			require.Error(t, err, spec)
+		}
+	})
+}
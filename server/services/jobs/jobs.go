@@ -0,0 +1,486 @@
+// Package jobs runs the background jobs of the server. Jobs are stored in
+// the database, so that they survive restarts and that the nodes of a
+// cluster share them, and only the leader of the cluster runs them.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/scheduler"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// pollInterval is the time between two checks of the due jobs.
+	pollInterval = 15 * time.Second
+
+	// staleJobTimeout is the time after which a running job that isn't
+	// updated is considered interrupted, as the node running it stopped.
+	staleJobTimeout = 5 * pollInterval
+
+	defaultRetryDelay = time.Minute
+
+	errInterrupted = "the job was interrupted"
+)
+
+var (
+	errDuplicateJobType = errors.New("the job type is already registered")
+	errServiceStarted   = errors.New("the jobs service is already started")
+)
+
+// Definition describes a type of job.
+type Definition struct {
+	Type string
+
+	// Schedule is a cron expression, as accepted by ParseSchedule. Jobs
+	// without a schedule only run when triggered.
+	Schedule string
+
+	// MaxAttempts is the number of times a failing job is run, 1 if not
+	// set.
+	MaxAttempts int
+
+	// RetryDelay is the time before a failed job is retried, doubled for
+	// each attempt. It defaults to a minute.
+	RetryDelay time.Duration
+
+	// Run does the work of the job. The context is cancelled when the
+	// job is cancelled or the server shuts down.
+	Run func(ctx context.Context) error
+}
+
+type definition struct {
+	Definition
+	schedule Schedule
+}
+
+// Store keeps the jobs.
+type Store interface {
+	SaveJob(job *model.Job) error
+	GetJob(id string) (*model.Job, error)
+	GetJobs(opts model.QueryJobsOptions) ([]*model.Job, error)
+	GetDueJobs(runBefore int64) ([]*model.Job, error)
+	UpdateJobStatus(job *model.Job, previousStatus string) (bool, error)
+}
+
+type runningJob struct {
+	job    *model.Job
+	cancel context.CancelFunc
+}
+
+// Service schedules the registered jobs and runs them when they are due.
+type Service struct {
+	store  Store
+	leader scheduler.Leader
+	logger mlog.LoggerIFace
+
+	mu          sync.Mutex
+	definitions map[string]*definition
+	running     map[string]*runningJob
+	task        *scheduler.ScheduledTask
+	ctx         context.Context
+	stop        context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// New creates a jobs service. The jobs only run on the node that is the
+// leader, or on every node if leader is nil.
+func New(store Store, leader scheduler.Leader, logger mlog.LoggerIFace) *Service {
+	ctx, stop := context.WithCancel(context.Background())
+	return &Service{
+		store:       store,
+		leader:      leader,
+		logger:      logger,
+		definitions: make(map[string]*definition),
+		running:     make(map[string]*runningJob),
+		ctx:         ctx,
+		stop:        stop,
+	}
+}
+
+// Register adds a type of job. The job types are registered before the
+// service is started.
+func (s *Service) Register(def Definition) error {
+	var schedule Schedule
+	if def.Schedule != "" {
+		var err error
+		if schedule, err = ParseSchedule(def.Schedule); err != nil {
+			return fmt.Errorf("cannot register job %s: %w", def.Type, err)
+		}
+	}
+	if def.MaxAttempts < 1 {
+		def.MaxAttempts = 1
+	}
+	if def.RetryDelay <= 0 {
+		def.RetryDelay = defaultRetryDelay
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.definitions[def.Type]; ok {
+		return fmt.Errorf("cannot register job %s: %w", def.Type, errDuplicateJobType)
+	}
+	s.definitions[def.Type] = &definition{Definition: def, schedule: schedule}
+	return nil
+}
+
+// Start checks the due jobs periodically, and runs them.
+func (s *Service) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.task != nil {
+		return errServiceStarted
+	}
+
+	s.task = scheduler.CreateRecurringTask("jobs", s.poll, pollInterval)
+	return nil
+}
+
+// Shutdown stops running jobs, and waits for the running ones to end.
+// They are stopped, and run again once a server starts.
+func (s *Service) Shutdown() {
+	s.mu.Lock()
+	task := s.task
+	s.task = nil
+	s.mu.Unlock()
+
+	if task != nil {
+		task.Cancel()
+	}
+	s.stop()
+	s.wg.Wait()
+}
+
+// GetJob returns a job.
+func (s *Service) GetJob(id string) (*model.Job, error) {
+	return s.store.GetJob(id)
+}
+
+// GetJobs returns the jobs, the most recent first.
+func (s *Service) GetJobs(opts model.QueryJobsOptions) ([]*model.Job, error) {
+	return s.store.GetJobs(opts)
+}
+
+// Trigger creates a job that is due now. It runs once the running job of
+// the same type, if any, ends.
+func (s *Service) Trigger(jobType, userID string) (*model.Job, error) {
+	s.mu.Lock()
+	_, ok := s.definitions[jobType]
+	s.mu.Unlock()
+	if !ok {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("unknown job type %s", jobType))
+	}
+
+	job := &model.Job{
+		ID:        utils.NewID(utils.IDTypeNone),
+		Type:      jobType,
+		Status:    model.JobStatusPending,
+		Attempt:   1,
+		CreatedBy: userID,
+		RunAt:     utils.GetMillis(),
+	}
+	if err := s.store.SaveJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Cancel cancels a pending or running job. A running job is stopped by
+// the node running it.
+func (s *Service) Cancel(id string) (*model.Job, error) {
+	job, err := s.store.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFinished() {
+		return nil, model.NewErrBadRequest(fmt.Sprintf("the job is already %s", job.Status))
+	}
+
+	previousStatus := job.Status
+	job.Status = model.JobStatusCancelled
+	job.FinishAt = utils.GetMillis()
+	updated, err := s.store.UpdateJobStatus(job, previousStatus)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		// the job started or finished meanwhile
+		return s.Cancel(id)
+	}
+
+	s.mu.Lock()
+	if running, ok := s.running[id]; ok {
+		running.cancel()
+	}
+	s.mu.Unlock()
+
+	return job, nil
+}
+
+// poll keeps the running jobs alive and, on the leader, schedules the
+// next jobs and runs the due ones. A node that loses the leadership
+// keeps its running jobs alive until they end.
+func (s *Service) poll() {
+	now := time.Now()
+
+	s.heartbeat()
+	if s.leader != nil && !s.leader.IsLeader() {
+		return
+	}
+	s.recoverInterruptedJobs(now)
+	s.scheduleJobs(now)
+	s.runDueJobs(now)
+}
+
+// heartbeat refreshes the running jobs of this node, so that the other
+// nodes don't consider them interrupted, and stops the ones that were
+// cancelled.
+func (s *Service) heartbeat() {
+	s.mu.Lock()
+	jobs := make([]model.Job, 0, len(s.running))
+	cancels := make([]context.CancelFunc, 0, len(s.running))
+	for _, r := range s.running {
+		jobs = append(jobs, *r.job)
+		cancels = append(cancels, r.cancel)
+	}
+	s.mu.Unlock()
+
+	for i := range jobs {
+		updated, err := s.store.UpdateJobStatus(&jobs[i], model.JobStatusRunning)
+		if err != nil {
+			s.logger.Error("Unable to update a running job", mlog.String("job_id", jobs[i].ID), mlog.Err(err))
+			continue
+		}
+		if !updated {
+			cancels[i]()
+		}
+	}
+}
+
+// recoverInterruptedJobs fails the jobs whose node stopped while running
+// them, and retries them if they can be.
+func (s *Service) recoverInterruptedJobs(now time.Time) {
+	jobs, err := s.store.GetJobs(model.QueryJobsOptions{Statuses: []string{model.JobStatusRunning}})
+	if err != nil {
+		s.logger.Error("Unable to get the running jobs", mlog.Err(err))
+		return
+	}
+
+	threshold := utils.GetMillisForTime(now.Add(-staleJobTimeout))
+	for _, job := range jobs {
+		s.mu.Lock()
+		_, local := s.running[job.ID]
+		def := s.definitions[job.Type]
+		s.mu.Unlock()
+
+		if local || job.UpdateAt >= threshold {
+			continue
+		}
+		s.logger.Warn("Recovering an interrupted job", mlog.String("job_id", job.ID), mlog.String("type", job.Type))
+		s.finish(job, def, errors.New(errInterrupted), now)
+	}
+}
+
+// scheduleJobs creates the next job of each scheduled type that has no
+// pending job. As the next job is created ahead of time, a job that was
+// due while the server was stopped runs once it starts again.
+func (s *Service) scheduleJobs(now time.Time) {
+	s.mu.Lock()
+	defs := make([]*definition, 0, len(s.definitions))
+	for _, def := range s.definitions {
+		if def.schedule != nil {
+			defs = append(defs, def)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, def := range defs {
+		pending, err := s.store.GetJobs(model.QueryJobsOptions{
+			Type:     def.Type,
+			Statuses: []string{model.JobStatusPending},
+			PerPage:  1,
+		})
+		if err != nil {
+			s.logger.Error("Unable to get the pending jobs", mlog.String("type", def.Type), mlog.Err(err))
+			continue
+		}
+		if len(pending) > 0 {
+			continue
+		}
+
+		next := def.schedule.Next(now)
+		if next.IsZero() {
+			continue
+		}
+		job := &model.Job{
+			ID:      utils.NewID(utils.IDTypeNone),
+			Type:    def.Type,
+			Status:  model.JobStatusPending,
+			Attempt: 1,
+			RunAt:   utils.GetMillisForTime(next),
+		}
+		if err := s.store.SaveJob(job); err != nil {
+			s.logger.Error("Unable to schedule a job", mlog.String("type", def.Type), mlog.Err(err))
+		}
+	}
+}
+
+// runDueJobs claims and runs the due jobs. A single job of each type runs
+// at a time.
+func (s *Service) runDueJobs(now time.Time) {
+	jobs, err := s.store.GetDueJobs(utils.GetMillisForTime(now))
+	if err != nil {
+		s.logger.Error("Unable to get the due jobs", mlog.Err(err))
+		return
+	}
+
+	for _, job := range jobs {
+		s.mu.Lock()
+		def, ok := s.definitions[job.Type]
+		busy := s.isRunning(job.Type)
+		s.mu.Unlock()
+
+		if busy {
+			continue
+		}
+		if !ok {
+			// the job may have been created by another version
+			s.logger.Warn("Skipping a job of an unknown type", mlog.String("job_id", job.ID), mlog.String("type", job.Type))
+			continue
+		}
+
+		job.Status = model.JobStatusRunning
+		job.StartAt = utils.GetMillis()
+		claimed, err := s.store.UpdateJobStatus(job, model.JobStatusPending)
+		if err != nil {
+			s.logger.Error("Unable to claim a job", mlog.String("job_id", job.ID), mlog.Err(err))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		s.start(job, def)
+	}
+}
+
+// isRunning returns true if a job of the type runs on this node. The
+// caller must hold the lock.
+func (s *Service) isRunning(jobType string) bool {
+	for _, r := range s.running {
+		if r.job.Type == jobType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) start(job *model.Job, def *definition) {
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	s.mu.Lock()
+	s.running[job.ID] = &runningJob{job: job, cancel: cancel}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+
+		s.logger.Info("Job started",
+			mlog.String("job_id", job.ID),
+			mlog.String("type", job.Type),
+			mlog.Int("attempt", job.Attempt),
+		)
+		err := run(ctx, def.Run)
+
+		s.mu.Lock()
+		delete(s.running, job.ID)
+		s.mu.Unlock()
+
+		if s.ctx.Err() != nil {
+			s.requeue(job)
+			return
+		}
+		if err == nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		s.finish(job, def, err, time.Now())
+	}()
+}
+
+// requeue makes a job stopped by the shutdown of the server pending
+// again, so that it runs once a server starts.
+func (s *Service) requeue(job *model.Job) {
+	job.Status = model.JobStatusPending
+	job.StartAt = 0
+	if _, err := s.store.UpdateJobStatus(job, model.JobStatusRunning); err != nil {
+		s.logger.Error("Unable to requeue a job", mlog.String("job_id", job.ID), mlog.Err(err))
+	}
+}
+
+// run calls the function of a job, turning its panics into errors.
+func run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("the job panicked: %v", p)
+		}
+	}()
+	return fn(ctx)
+}
+
+// finish saves the result of a running job, and creates the next attempt
+// of a failed one if it can be retried. Nothing is saved if the job was
+// cancelled meanwhile.
+func (s *Service) finish(job *model.Job, def *definition, err error, now time.Time) {
+	job.FinishAt = utils.GetMillisForTime(now)
+	job.Status = model.JobStatusSucceeded
+	if err != nil {
+		job.Status = model.JobStatusFailed
+		job.Error = err.Error()
+	}
+
+	updated, updateErr := s.store.UpdateJobStatus(job, model.JobStatusRunning)
+	if updateErr != nil {
+		s.logger.Error("Unable to save the job result", mlog.String("job_id", job.ID), mlog.Err(updateErr))
+		return
+	}
+	if !updated {
+		s.logger.Info("Job cancelled", mlog.String("job_id", job.ID), mlog.String("type", job.Type))
+		return
+	}
+
+	if err == nil {
+		s.logger.Info("Job succeeded", mlog.String("job_id", job.ID), mlog.String("type", job.Type))
+		return
+	}
+	s.logger.Error("Job failed",
+		mlog.String("job_id", job.ID),
+		mlog.String("type", job.Type),
+		mlog.Int("attempt", job.Attempt),
+		mlog.Err(err),
+	)
+
+	if def == nil || job.Attempt >= def.MaxAttempts {
+		return
+	}
+	retry := &model.Job{
+		ID:        utils.NewID(utils.IDTypeNone),
+		Type:      job.Type,
+		Status:    model.JobStatusPending,
+		Attempt:   job.Attempt + 1,
+		CreatedBy: job.CreatedBy,
+		RunAt:     utils.GetMillisForTime(now.Add(def.RetryDelay << (job.Attempt - 1))),
+	}
+	if err := s.store.SaveJob(retry); err != nil {
+		s.logger.Error("Unable to retry a job", mlog.String("job_id", job.ID), mlog.Err(err))
+	}
+}
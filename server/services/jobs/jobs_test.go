@@ -0,0 +1,327 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+type memoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]model.Job
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{jobs: map[string]model.Job{}}
+}
+
+func (s *memoryJobStore) SaveJob(job *model.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job.UpdateAt = utils.GetMillis()
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s *memoryJobStore) GetJob(id string) (*model.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, model.NewErrNotFound("job ID=" + id)
+	}
+	return &job, nil
+}
+
+func (s *memoryJobStore) GetJobs(opts model.QueryJobsOptions) ([]*model.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := []*model.Job{}
+	for _, job := range s.jobs {
+		job := job
+		if opts.Type != "" && job.Type != opts.Type {
+			continue
+		}
+		if len(opts.Statuses) > 0 && !slices.Contains(opts.Statuses, job.Status) {
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].RunAt > jobs[j].RunAt })
+	return jobs, nil
+}
+
+func (s *memoryJobStore) GetDueJobs(runBefore int64) ([]*model.Job, error) {
+	jobs, _ := s.GetJobs(model.QueryJobsOptions{Statuses: []string{model.JobStatusPending}})
+	due := []*model.Job{}
+	for i := len(jobs) - 1; i >= 0; i-- {
+		if jobs[i].RunAt <= runBefore {
+			due = append(due, jobs[i])
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryJobStore) UpdateJobStatus(job *model.Job, previousStatus string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.jobs[job.ID].Status != previousStatus {
+		return false, nil
+	}
+	job.UpdateAt = utils.GetMillis()
+	s.jobs[job.ID] = *job
+	return true, nil
+}
+
+func (s *memoryJobStore) jobsOfType(jobType string) []*model.Job {
+	jobs, _ := s.GetJobs(model.QueryJobsOptions{Type: jobType})
+	return jobs
+}
+
+type staticLeader bool
+
+func (l staticLeader) IsLeader() bool {
+	return bool(l)
+}
+
+func newTestService(t *testing.T, leader staticLeader) (*Service, *memoryJobStore) {
+	store := newMemoryJobStore()
+	service := New(store, leader, mlog.CreateConsoleTestLogger(t))
+	return service, store
+}
+
+func TestRunJobs(t *testing.T) {
+	t.Run("a triggered job runs", func(t *testing.T) {
+		service, store := newTestService(t, true)
+		runs := 0
+		require.NoError(t, service.Register(Definition{
+			Type: "test",
+			Run:  func(ctx context.Context) error { runs++; return nil },
+		}))
+
+		job, err := service.Trigger("test", "user-id")
+		require.NoError(t, err)
+		require.Equal(t, "user-id", job.CreatedBy)
+
+		service.poll()
+		service.wg.Wait()
+
+		require.Equal(t, 1, runs)
+		job, err = service.GetJob(job.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.JobStatusSucceeded, job.Status)
+		require.NotZero(t, job.StartAt)
+		require.NotZero(t, job.FinishAt)
+		require.Len(t, store.jobsOfType("test"), 1)
+
+		_, err = service.Trigger("unknown", "user-id")
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("a failed job is retried", func(t *testing.T) {
+		service, store := newTestService(t, true)
+		require.NoError(t, service.Register(Definition{
+			Type:        "test",
+			MaxAttempts: 2,
+			RetryDelay:  time.Hour,
+			Run:         func(ctx context.Context) error { return errors.New("failure") },
+		}))
+		job, err := service.Trigger("test", "")
+		require.NoError(t, err)
+
+		service.poll()
+		service.wg.Wait()
+
+		jobs := store.jobsOfType("test")
+		require.Len(t, jobs, 2)
+		retry, failed := jobs[0], jobs[1]
+		require.Equal(t, job.ID, failed.ID)
+		require.Equal(t, model.JobStatusFailed, failed.Status)
+		require.Equal(t, "failure", failed.Error)
+		require.Equal(t, model.JobStatusPending, retry.Status)
+		require.Equal(t, 2, retry.Attempt)
+		require.Greater(t, retry.RunAt, utils.GetMillis()+time.Minute.Milliseconds())
+
+		// the last attempt isn't retried
+		service.runDueJobs(utils.GetTimeForMillis(retry.RunAt))
+		service.wg.Wait()
+		jobs = store.jobsOfType("test")
+		require.Len(t, jobs, 2)
+		require.Equal(t, model.JobStatusFailed, jobs[0].Status)
+	})
+
+	t.Run("a panicking job fails", func(t *testing.T) {
+		service, store := newTestService(t, true)
+		require.NoError(t, service.Register(Definition{
+			Type: "test",
+			Run:  func(ctx context.Context) error { panic("boom") },
+		}))
+		_, err := service.Trigger("test", "")
+		require.NoError(t, err)
+
+		service.poll()
+		service.wg.Wait()
+
+		jobs := store.jobsOfType("test")
+		require.Len(t, jobs, 1)
+		require.Equal(t, model.JobStatusFailed, jobs[0].Status)
+		require.Contains(t, jobs[0].Error, "boom")
+	})
+
+	t.Run("jobs only run on the leader", func(t *testing.T) {
+		service, store := newTestService(t, false)
+		require.NoError(t, service.Register(Definition{
+			Type:     "test",
+			Schedule: "* * * * *",
+			Run:      func(ctx context.Context) error { return nil },
+		}))
+		_, err := service.Trigger("test", "")
+		require.NoError(t, err)
+
+		service.poll()
+		jobs := store.jobsOfType("test")
+		require.Len(t, jobs, 1)
+		require.Equal(t, model.JobStatusPending, jobs[0].Status)
+	})
+}
+
+func TestScheduleJobs(t *testing.T) {
+	service, store := newTestService(t, true)
+	require.NoError(t, service.Register(Definition{
+		Type:     "scheduled",
+		Schedule: "0 * * * *",
+		Run:      func(ctx context.Context) error { return nil },
+	}))
+	require.NoError(t, service.Register(Definition{
+		Type: "triggered",
+		Run:  func(ctx context.Context) error { return nil },
+	}))
+	require.Error(t, service.Register(Definition{Type: "scheduled"}))
+	require.Error(t, service.Register(Definition{Type: "invalid", Schedule: "never"}))
+
+	now := time.Date(2024, time.January, 31, 10, 7, 30, 0, time.Local)
+	service.scheduleJobs(now)
+	service.scheduleJobs(now)
+
+	jobs := store.jobsOfType("scheduled")
+	require.Len(t, jobs, 1)
+	require.Equal(t, model.JobStatusPending, jobs[0].Status)
+	require.Equal(t, utils.GetMillisForTime(time.Date(2024, time.January, 31, 11, 0, 0, 0, time.Local)), jobs[0].RunAt)
+	require.Empty(t, store.jobsOfType("triggered"))
+
+	// the next job is scheduled once the pending one ran
+	service.runDueJobs(now.Add(time.Hour))
+	service.wg.Wait()
+	service.scheduleJobs(now.Add(time.Hour))
+
+	jobs = store.jobsOfType("scheduled")
+	require.Len(t, jobs, 2)
+	require.Equal(t, model.JobStatusPending, jobs[0].Status)
+	require.Equal(t, utils.GetMillisForTime(time.Date(2024, time.January, 31, 12, 0, 0, 0, time.Local)), jobs[0].RunAt)
+	require.Equal(t, model.JobStatusSucceeded, jobs[1].Status)
+}
+
+func TestCancelJobs(t *testing.T) {
+	service, store := newTestService(t, true)
+	started := make(chan struct{})
+	require.NoError(t, service.Register(Definition{
+		Type: "test",
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}))
+
+	t.Run("cancel a pending job", func(t *testing.T) {
+		job, err := service.Trigger("test", "")
+		require.NoError(t, err)
+
+		cancelled, err := service.Cancel(job.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.JobStatusCancelled, cancelled.Status)
+
+		_, err = service.Cancel(job.ID)
+		require.True(t, model.IsErrBadRequest(err))
+	})
+
+	t.Run("cancel a running job", func(t *testing.T) {
+		job, err := service.Trigger("test", "")
+		require.NoError(t, err)
+
+		service.poll()
+		<-started
+
+		_, err = service.Cancel(job.ID)
+		require.NoError(t, err)
+		service.wg.Wait()
+
+		job, err = service.GetJob(job.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.JobStatusCancelled, job.Status)
+		require.Len(t, store.jobsOfType("test"), 2)
+	})
+}
+
+func TestInterruptedJobs(t *testing.T) {
+	t.Run("the jobs of a stopped node are recovered", func(t *testing.T) {
+		service, store := newTestService(t, true)
+		require.NoError(t, service.Register(Definition{
+			Type:        "test",
+			MaxAttempts: 2,
+			Run:         func(ctx context.Context) error { return nil },
+		}))
+		job := &model.Job{ID: "job-id", Type: "test", Status: model.JobStatusRunning, Attempt: 1}
+		require.NoError(t, store.SaveJob(job))
+
+		service.recoverInterruptedJobs(time.Now())
+		require.Len(t, store.jobsOfType("test"), 1, "the job is still alive")
+
+		service.recoverInterruptedJobs(time.Now().Add(staleJobTimeout + time.Second))
+		jobs := store.jobsOfType("test")
+		require.Len(t, jobs, 2)
+		require.Equal(t, model.JobStatusPending, jobs[0].Status)
+		require.Equal(t, model.JobStatusFailed, jobs[1].Status)
+		require.Equal(t, errInterrupted, jobs[1].Error)
+	})
+
+	t.Run("the running jobs are requeued on shutdown", func(t *testing.T) {
+		service, store := newTestService(t, true)
+		started := make(chan struct{})
+		require.NoError(t, service.Register(Definition{
+			Type: "test",
+			Run: func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}))
+		require.NoError(t, service.Start())
+		job, err := service.Trigger("test", "")
+		require.NoError(t, err)
+
+		service.poll()
+		<-started
+		service.Shutdown()
+
+		job, err = store.GetJob(job.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.JobStatusPending, job.Status)
+		require.Zero(t, job.StartAt)
+	})
+}
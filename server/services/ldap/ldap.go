@@ -0,0 +1,312 @@
+// Package ldap authenticates users against an LDAP directory and reads
+// the user attributes and group memberships synchronized into the boards.
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	goldap "github.com/mattermost/ldap"
+
+	"github.com/mattermost/focalboard/server/services/config"
+)
+
+const (
+	DefaultUserFilter           = "(objectClass=person)"
+	DefaultIDAttribute          = "entryUUID"
+	DefaultUsernameAttribute    = "uid"
+	DefaultEmailAttribute       = "mail"
+	DefaultFirstNameAttribute   = "givenName"
+	DefaultLastNameAttribute    = "sn"
+	DefaultGroupFilter          = "(objectClass=groupOfNames)"
+	DefaultGroupNameAttribute   = "cn"
+	DefaultGroupMemberAttribute = "member"
+	DefaultSyncIntervalMinutes  = 60
+
+	requestTimeout = 10 * time.Second
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid LDAP credentials")
+	ErrMultipleUsers      = errors.New("more than one LDAP user matches the username")
+)
+
+// User is a user entry of the directory.
+type User struct {
+	DN        string
+	ID        string
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+	Nickname  string
+}
+
+// Service reads users and groups from the directory. Every operation
+// opens its own connection, bound with the service account.
+type Service struct {
+	config config.LDAPConfig
+}
+
+// New creates a service for the given configuration, filling in the
+// default attribute names and filters.
+func New(cfg config.LDAPConfig) *Service {
+	setDefault(&cfg.UserFilter, DefaultUserFilter)
+	setDefault(&cfg.IDAttribute, DefaultIDAttribute)
+	setDefault(&cfg.UsernameAttribute, DefaultUsernameAttribute)
+	setDefault(&cfg.EmailAttribute, DefaultEmailAttribute)
+	setDefault(&cfg.FirstNameAttribute, DefaultFirstNameAttribute)
+	setDefault(&cfg.LastNameAttribute, DefaultLastNameAttribute)
+	setDefault(&cfg.GroupBaseDN, cfg.BaseDN)
+	setDefault(&cfg.GroupFilter, DefaultGroupFilter)
+	setDefault(&cfg.GroupNameAttribute, DefaultGroupNameAttribute)
+	setDefault(&cfg.GroupMemberAttribute, DefaultGroupMemberAttribute)
+	if cfg.SyncIntervalMinutes <= 0 {
+		cfg.SyncIntervalMinutes = DefaultSyncIntervalMinutes
+	}
+
+	return &Service{config: cfg}
+}
+
+func setDefault(value *string, defaultValue string) {
+	if *value == "" {
+		*value = defaultValue
+	}
+}
+
+// Config returns the service configuration, including the defaults.
+func (s *Service) Config() config.LDAPConfig {
+	return s.config
+}
+
+// SyncInterval returns the time between two synchronizations.
+func (s *Service) SyncInterval() time.Duration {
+	return time.Duration(s.config.SyncIntervalMinutes) * time.Minute
+}
+
+// Authenticate checks the password of a user by binding as them, and
+// returns their entry.
+func (s *Service) Authenticate(username, password string) (*User, error) {
+	// an empty password would be an unauthenticated bind, which most
+	// servers accept without checking anything.
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	filter := fmt.Sprintf("(&(%s=%s)%s)", s.config.UsernameAttribute, goldap.EscapeFilter(username), s.config.UserFilter)
+	users, err := s.searchUsers(conn, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+	if len(users) > 1 {
+		return nil, ErrMultipleUsers
+	}
+
+	if err := conn.Bind(users[0].DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("unable to bind as the LDAP user: %w", err)
+	}
+
+	return users[0], nil
+}
+
+// Users returns all the users of the directory matching the user filter.
+func (s *Service) Users() ([]*User, error) {
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return s.searchUsers(conn, s.config.UserFilter)
+}
+
+// GroupMembers returns the DNs of the members of the given groups,
+// indexed by group name. Groups missing from the directory have no
+// members.
+func (s *Service) GroupMembers(groups []string) (map[string][]string, error) {
+	members := map[string][]string{}
+	if len(groups) == 0 {
+		return members, nil
+	}
+
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	nameFilter := ""
+	for _, group := range groups {
+		nameFilter += fmt.Sprintf("(%s=%s)", s.config.GroupNameAttribute, goldap.EscapeFilter(group))
+	}
+
+	request := goldap.NewSearchRequest(
+		s.config.GroupBaseDN,
+		goldap.ScopeWholeSubtree,
+		goldap.NeverDerefAliases,
+		0, 0, false,
+		fmt.Sprintf("(&%s(|%s))", s.config.GroupFilter, nameFilter),
+		[]string{s.config.GroupNameAttribute, s.config.GroupMemberAttribute},
+		nil,
+	)
+	result, err := conn.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to search the LDAP groups: %w", err)
+	}
+
+	for _, entry := range result.Entries {
+		name := entry.GetAttributeValue(s.config.GroupNameAttribute)
+		members[name] = append(members[name], entry.GetAttributeValues(s.config.GroupMemberAttribute)...)
+	}
+	return members, nil
+}
+
+// UserGroups returns the names of the given groups that have the user
+// as a member.
+func (s *Service) UserGroups(userDN string, groups []string) ([]string, error) {
+	members, err := s.GroupMembers(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	userGroups := []string{}
+	for _, group := range groups {
+		if IsMember(members[group], userDN) {
+			userGroups = append(userGroups, group)
+		}
+	}
+	return userGroups, nil
+}
+
+// IsMember returns true if the DN is in the member list.
+func IsMember(memberDNs []string, dn string) bool {
+	for _, memberDN := range memberDNs {
+		if EqualDN(memberDN, dn) {
+			return true
+		}
+	}
+	return false
+}
+
+// EqualDN compares two distinguished names, ignoring the differences of
+// case and spacing the directory may introduce.
+func EqualDN(a, b string) bool {
+	dnA, err := goldap.ParseDN(strings.ToLower(a))
+	if err != nil {
+		return a == b
+	}
+	dnB, err := goldap.ParseDN(strings.ToLower(b))
+	if err != nil {
+		return a == b
+	}
+	return dnA.Equal(dnB)
+}
+
+func (s *Service) connect() (*goldap.Conn, error) {
+	serverURL, err := url.Parse(s.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP server URL: %w", err)
+	}
+	//nolint:gosec
+	tlsConfig := &tls.Config{
+		ServerName:         serverURL.Hostname(),
+		InsecureSkipVerify: s.config.InsecureSkipVerify,
+	}
+
+	var conn *goldap.Conn
+	if serverURL.Scheme == "ldaps" {
+		host := serverURL.Host
+		if serverURL.Port() == "" {
+			host = net.JoinHostPort(host, goldap.DefaultLdapsPort)
+		}
+		conn, err = goldap.DialTLS("tcp", host, tlsConfig)
+	} else {
+		conn, err = goldap.DialURL(s.config.URL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the LDAP server: %w", err)
+	}
+	conn.Start()
+	conn.SetTimeout(requestTimeout)
+
+	if s.config.StartTLS {
+		if err = conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("unable to start TLS with the LDAP server: %w", err)
+		}
+	}
+
+	if s.config.BindDN != "" {
+		if err = conn.Bind(s.config.BindDN, s.config.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("unable to bind with the LDAP service account: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+func (s *Service) searchUsers(conn *goldap.Conn, filter string) ([]*User, error) {
+	attributes := []string{
+		s.config.IDAttribute,
+		s.config.UsernameAttribute,
+		s.config.EmailAttribute,
+		s.config.FirstNameAttribute,
+		s.config.LastNameAttribute,
+	}
+	if s.config.NicknameAttribute != "" {
+		attributes = append(attributes, s.config.NicknameAttribute)
+	}
+
+	request := goldap.NewSearchRequest(
+		s.config.BaseDN,
+		goldap.ScopeWholeSubtree,
+		goldap.NeverDerefAliases,
+		0, 0, false,
+		filter,
+		attributes,
+		nil,
+	)
+	result, err := conn.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to search the LDAP users: %w", err)
+	}
+
+	users := make([]*User, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		user := &User{
+			DN:        entry.DN,
+			ID:        entry.GetAttributeValue(s.config.IDAttribute),
+			Username:  entry.GetAttributeValue(s.config.UsernameAttribute),
+			Email:     entry.GetAttributeValue(s.config.EmailAttribute),
+			FirstName: entry.GetAttributeValue(s.config.FirstNameAttribute),
+			LastName:  entry.GetAttributeValue(s.config.LastNameAttribute),
+		}
+		if s.config.NicknameAttribute != "" {
+			user.Nickname = entry.GetAttributeValue(s.config.NicknameAttribute)
+		}
+		// entries without a stable ID can't be linked to a user
+		if user.ID == "" || user.Username == "" {
+			continue
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
@@ -0,0 +1,100 @@
+package ldap_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/services/ldap"
+	"github.com/mattermost/focalboard/server/services/ldap/ldaptest"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDirectory(t *testing.T) (*ldaptest.Server, *ldap.Service) {
+	directory, err := ldaptest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(directory.Close)
+
+	return directory, ldap.New(directory.Config())
+}
+
+func TestAuthenticate(t *testing.T) {
+	directory, service := setupDirectory(t)
+	dn := directory.AddUser("john", "john-password", "john@example.com", "John", "Doe")
+
+	t.Run("valid credentials", func(t *testing.T) {
+		user, err := service.Authenticate("john", "john-password")
+		require.NoError(t, err)
+		require.Equal(t, dn, user.DN)
+		require.NotEmpty(t, user.ID)
+		require.Equal(t, "john", user.Username)
+		require.Equal(t, "john@example.com", user.Email)
+		require.Equal(t, "John", user.FirstName)
+		require.Equal(t, "Doe", user.LastName)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := service.Authenticate("john", "wrong-password")
+		require.ErrorIs(t, err, ldap.ErrInvalidCredentials)
+	})
+
+	t.Run("empty password", func(t *testing.T) {
+		_, err := service.Authenticate("john", "")
+		require.ErrorIs(t, err, ldap.ErrInvalidCredentials)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := service.Authenticate("jane", "john-password")
+		require.ErrorIs(t, err, ldap.ErrInvalidCredentials)
+	})
+
+	t.Run("filter characters are escaped", func(t *testing.T) {
+		_, err := service.Authenticate("*", "john-password")
+		require.ErrorIs(t, err, ldap.ErrInvalidCredentials)
+	})
+
+	t.Run("wrong service account", func(t *testing.T) {
+		config := directory.Config()
+		config.BindPassword = "wrong-password"
+
+		_, err := ldap.New(config).Authenticate("john", "john-password")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ldap.ErrInvalidCredentials)
+	})
+}
+
+func TestUsers(t *testing.T) {
+	directory, service := setupDirectory(t)
+	directory.AddUser("john", "john-password", "john@example.com", "John", "Doe")
+	directory.AddUser("jane", "jane-password", "jane@example.com", "Jane", "Doe")
+	directory.AddGroup("developers")
+
+	users, err := service.Users()
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	usernames := []string{users[0].Username, users[1].Username}
+	require.ElementsMatch(t, []string{"john", "jane"}, usernames)
+}
+
+func TestGroupMembers(t *testing.T) {
+	directory, service := setupDirectory(t)
+	johnDN := directory.AddUser("john", "john-password", "john@example.com", "John", "Doe")
+	janeDN := directory.AddUser("jane", "jane-password", "jane@example.com", "Jane", "Doe")
+	directory.AddGroup("developers", johnDN, janeDN)
+	directory.AddGroup("admins", janeDN)
+	directory.AddGroup("others", johnDN)
+
+	members, err := service.GroupMembers([]string{"developers", "admins", "missing"})
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	require.ElementsMatch(t, []string{johnDN, janeDN}, members["developers"])
+	require.Equal(t, []string{janeDN}, members["admins"])
+
+	groups, err := service.UserGroups(johnDN, []string{"developers", "admins"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"developers"}, groups)
+}
+
+func TestEqualDN(t *testing.T) {
+	require.True(t, ldap.EqualDN("uid=john,ou=users,dc=example,dc=com", "UID=John, OU=Users, DC=example, DC=com"))
+	require.False(t, ldap.EqualDN("uid=john,ou=users,dc=example,dc=com", "uid=jane,ou=users,dc=example,dc=com"))
+}
@@ -0,0 +1,325 @@
+// Package ldaptest provides a stand-in LDAP server to test the directory
+// integration without an external server. It supports simple binds and
+// subtree searches with and, or, not, equality and presence filters.
+package ldaptest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/mattermost/ldap"
+
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+const (
+	BaseDN        = "dc=example,dc=com"
+	UsersDN       = "ou=users," + BaseDN
+	GroupsDN      = "ou=groups," + BaseDN
+	AdminDN       = "cn=admin," + BaseDN
+	AdminPassword = "admin-password"
+)
+
+type entry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// Server is an in-memory LDAP directory. It must be closed after use.
+type Server struct {
+	URL string
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	conns   map[net.Conn]struct{}
+}
+
+// NewServer starts a new directory containing only the service account.
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		URL:      "ldap://" + listener.Addr().String(),
+		listener: listener,
+		entries:  map[string]*entry{},
+		conns:    map[net.Conn]struct{}{},
+	}
+	s.AddEntry(AdminDN, AdminPassword, map[string][]string{"cn": {"admin"}})
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Config returns the configuration to use the directory.
+func (s *Server) Config() config.LDAPConfig {
+	return config.LDAPConfig{
+		URL:          s.URL,
+		BindDN:       AdminDN,
+		BindPassword: AdminPassword,
+		BaseDN:       BaseDN,
+	}
+}
+
+// Close stops the server and closes the open connections.
+func (s *Server) Close() {
+	s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// AddEntry adds or replaces an entry. Entries with a password can bind.
+func (s *Server) AddEntry(dn, password string, attributes map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[strings.ToLower(dn)] = &entry{dn: dn, password: password, attributes: attributes}
+}
+
+// RemoveEntry removes an entry.
+func (s *Server) RemoveEntry(dn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, strings.ToLower(dn))
+}
+
+// AddUser adds a person with the default attributes and returns its DN.
+func (s *Server) AddUser(username, password, email, firstName, lastName string) string {
+	dn := fmt.Sprintf("uid=%s,%s", username, UsersDN)
+	s.AddEntry(dn, password, map[string][]string{
+		"objectClass": {"person", "inetOrgPerson"},
+		"entryUUID":   {utils.NewID(utils.IDTypeNone)},
+		"uid":         {username},
+		"mail":        {email},
+		"givenName":   {firstName},
+		"sn":          {lastName},
+	})
+	return dn
+}
+
+// SetAttribute replaces the values of an attribute of an entry.
+func (s *Server) SetAttribute(dn, name string, values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[strings.ToLower(dn)]; ok {
+		e.attributes[name] = values
+	}
+}
+
+// AddGroup adds or replaces a group with the given members and returns
+// its DN.
+func (s *Server) AddGroup(name string, memberDNs ...string) string {
+	dn := fmt.Sprintf("cn=%s,%s", name, GroupsDN)
+	s.AddEntry(dn, "", map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"cn":          {name},
+		"member":      memberDNs,
+	})
+	return dn
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	boundDN := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		messageID := packet.Children[0].Value
+		request := packet.Children[1]
+
+		var responses []*ber.Packet
+		switch request.Tag {
+		case goldap.ApplicationBindRequest:
+			var response *ber.Packet
+			boundDN, response = s.bind(request)
+			responses = []*ber.Packet{response}
+		case goldap.ApplicationSearchRequest:
+			responses = s.search(request, boundDN)
+		case goldap.ApplicationUnbindRequest:
+			return
+		default:
+			responses = []*ber.Packet{result(goldap.ApplicationExtendedResponse, goldap.LDAPResultUnwillingToPerform, "operation not supported")}
+		}
+
+		for _, response := range responses {
+			envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+			envelope.AppendChild(response)
+			if _, err := conn.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) bind(request *ber.Packet) (string, *ber.Packet) {
+	if len(request.Children) < 3 {
+		return "", result(goldap.ApplicationBindResponse, goldap.LDAPResultProtocolError, "invalid bind request")
+	}
+
+	dn := request.Children[1].Data.String()
+	password := request.Children[2].Data.String()
+
+	// like most servers, accept unauthenticated binds without
+	// checking the DN.
+	if password == "" {
+		return "", result(goldap.ApplicationBindResponse, goldap.LDAPResultSuccess, "")
+	}
+
+	s.mu.Lock()
+	e, ok := s.entries[strings.ToLower(dn)]
+	s.mu.Unlock()
+	if !ok || e.password == "" || e.password != password {
+		return "", result(goldap.ApplicationBindResponse, goldap.LDAPResultInvalidCredentials, "invalid credentials")
+	}
+
+	return e.dn, result(goldap.ApplicationBindResponse, goldap.LDAPResultSuccess, "")
+}
+
+func (s *Server) search(request *ber.Packet, boundDN string) []*ber.Packet {
+	if boundDN == "" {
+		return []*ber.Packet{result(goldap.ApplicationSearchResultDone, goldap.LDAPResultInsufficientAccessRights, "bind required")}
+	}
+	if len(request.Children) < 8 {
+		return []*ber.Packet{result(goldap.ApplicationSearchResultDone, goldap.LDAPResultProtocolError, "invalid search request")}
+	}
+
+	baseDN := strings.ToLower(request.Children[0].Data.String())
+	filter := request.Children[6]
+	requested := map[string]bool{}
+	for _, attribute := range request.Children[7].Children {
+		requested[strings.ToLower(attribute.Data.String())] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	responses := []*ber.Packet{}
+	for key, e := range s.entries {
+		if key != baseDN && !strings.HasSuffix(key, ","+baseDN) {
+			continue
+		}
+		if !matches(filter, e.attributes) {
+			continue
+		}
+
+		response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+		response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "Object Name"))
+		attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for name, values := range e.attributes {
+			if len(requested) > 0 && !requested[strings.ToLower(name)] {
+				continue
+			}
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+			}
+			attribute.AppendChild(set)
+			attributes.AppendChild(attribute)
+		}
+		response.AppendChild(attributes)
+		responses = append(responses, response)
+	}
+
+	return append(responses, result(goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess, ""))
+}
+
+func matches(filter *ber.Packet, attributes map[string][]string) bool {
+	switch filter.Tag {
+	case goldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matches(child, attributes) {
+				return false
+			}
+		}
+		return true
+	case goldap.FilterOr:
+		for _, child := range filter.Children {
+			if matches(child, attributes) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterNot:
+		return len(filter.Children) == 1 && !matches(filter.Children[0], attributes)
+	case goldap.FilterPresent:
+		return len(attributeValues(attributes, filter.Data.String())) > 0
+	case goldap.FilterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		expected := filter.Children[1].Data.String()
+		for _, value := range attributeValues(attributes, filter.Children[0].Data.String()) {
+			if strings.EqualFold(value, expected) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func attributeValues(attributes map[string][]string, name string) []string {
+	for attributeName, values := range attributes {
+		if strings.EqualFold(attributeName, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+func result(tag ber.Tag, code uint16, message string) *ber.Packet {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	return response
+}
@@ -0,0 +1,168 @@
+// Package mail sends the emails of the server, such as the password
+// reset links, through an SMTP server.
+package mail
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/config"
+)
+
+const (
+	ConnectionSecurityNone     = ""
+	ConnectionSecurityTLS      = "TLS"
+	ConnectionSecurityStartTLS = "STARTTLS"
+
+	DefaultPort    = 25
+	DefaultTLSPort = 465
+
+	requestTimeout = 10 * time.Second
+)
+
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Service sends plain text emails. Every email opens its own connection
+// to the SMTP server.
+type Service struct {
+	config config.SMTPConfig
+}
+
+// New creates a service for the given configuration, filling in the
+// default port.
+func New(cfg config.SMTPConfig) *Service {
+	if cfg.Port <= 0 {
+		cfg.Port = DefaultPort
+		if cfg.ConnectionSecurity == ConnectionSecurityTLS {
+			cfg.Port = DefaultTLSPort
+		}
+	}
+	return &Service{config: cfg}
+}
+
+// Config returns the service configuration, including the defaults.
+func (s *Service) Config() config.SMTPConfig {
+	return s.config
+}
+
+// Send sends an email to a single recipient.
+func (s *Service) Send(to, subject, body string) error {
+	recipient, err := netmail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, to)
+	}
+	sender, err := netmail.ParseAddress(s.config.FromAddress)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAddress, s.config.FromAddress)
+	}
+	sender.Name = s.config.FromName
+
+	message, err := buildMessage(sender, recipient, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+
+	client, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err = client.Mail(sender.Address); err != nil {
+		return fmt.Errorf("the SMTP server refused the sender: %w", err)
+	}
+	if err = client.Rcpt(recipient.Address); err != nil {
+		return fmt.Errorf("the SMTP server refused the recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("unable to send the email: %w", err)
+	}
+	if _, err = writer.Write(message); err != nil {
+		return fmt.Errorf("unable to send the email: %w", err)
+	}
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("unable to send the email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (s *Service) connect() (*smtp.Client, error) {
+	address := net.JoinHostPort(s.config.Server, strconv.Itoa(s.config.Port))
+	//nolint:gosec
+	tlsConfig := &tls.Config{
+		ServerName:         s.config.Server,
+		InsecureSkipVerify: s.config.SkipServerCertificateVerification,
+	}
+
+	dialer := &net.Dialer{Timeout: requestTimeout}
+	var conn net.Conn
+	var err error
+	if s.config.ConnectionSecurity == ConnectionSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the SMTP server: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	client, err := smtp.NewClient(conn, s.config.Server)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("unable to connect to the SMTP server: %w", err)
+	}
+
+	if s.config.ConnectionSecurity == ConnectionSecurityStartTLS {
+		if err = client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("unable to start TLS with the SMTP server: %w", err)
+		}
+	}
+
+	// PlainAuth refuses to send the credentials over an unencrypted
+	// connection, except to localhost.
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Server)
+		if err = client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("unable to authenticate with the SMTP server: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+func buildMessage(from, to *netmail.Address, subject, body string, date time.Time) ([]byte, error) {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from.String())
+	fmt.Fprintf(&message, "To: %s\r\n", to.String())
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	message.WriteString("\r\n")
+
+	writer := quotedprintable.NewWriter(&message)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return message.Bytes(), nil
+}
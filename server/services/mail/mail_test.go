@@ -0,0 +1,64 @@
+package mail_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/mail"
+	"github.com/mattermost/focalboard/server/services/mail/mailtest"
+	"github.com/stretchr/testify/require"
+)
+
+func setupServer(t *testing.T) (*mailtest.Server, *mail.Service) {
+	server, err := mailtest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	return server, mail.New(server.Config())
+}
+
+func TestSend(t *testing.T) {
+	server, service := setupServer(t)
+
+	t.Run("plain text email", func(t *testing.T) {
+		body := "Hello John,\n\nA line longer than seventy-six characters is wrapped by the quoted-printable encoding = but restored.\n"
+		err := service.Send("john@example.com", "Réinitialisation du mot de passe", body)
+		require.NoError(t, err)
+
+		message := server.LastMessageTo("john@example.com")
+		require.NotNil(t, message)
+		require.Equal(t, mailtest.FromAddress, message.From)
+		require.Equal(t, []string{"john@example.com"}, message.To)
+		require.Equal(t, "Réinitialisation du mot de passe", message.Subject)
+		require.Equal(t, body, message.Body)
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		err := service.Send("john@example.com\r\nBcc: jane@example.com", "Subject", "Body")
+		require.ErrorIs(t, err, mail.ErrInvalidAddress)
+		require.Len(t, server.Messages(), 1)
+	})
+
+	t.Run("invalid sender", func(t *testing.T) {
+		config := server.Config()
+		config.FromAddress = ""
+
+		err := mail.New(config).Send("john@example.com", "Subject", "Body")
+		require.ErrorIs(t, err, mail.ErrInvalidAddress)
+	})
+
+	t.Run("server not reachable", func(t *testing.T) {
+		config := server.Config()
+		config.Port = 1
+
+		err := mail.New(config).Send("john@example.com", "Subject", "Body")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, mail.ErrInvalidAddress)
+	})
+}
+
+func TestNew(t *testing.T) {
+	require.Equal(t, mail.DefaultPort, mail.New(config.SMTPConfig{}).Config().Port)
+	require.Equal(t, mail.DefaultTLSPort, mail.New(config.SMTPConfig{ConnectionSecurity: mail.ConnectionSecurityTLS}).Config().Port)
+	require.Equal(t, 587, mail.New(config.SMTPConfig{Port: 587}).Config().Port)
+}
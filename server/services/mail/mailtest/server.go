@@ -0,0 +1,234 @@
+// Package mailtest provides a stand-in SMTP server that keeps the emails
+// it receives, to test the emails sent by the server without an external
+// mail server.
+package mailtest
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/focalboard/server/services/config"
+)
+
+const (
+	FromAddress = "boards@example.com"
+	FromName    = "Boards"
+)
+
+// Message is an email received by the server.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Server is an SMTP server accepting any email without authentication.
+// It must be closed after use.
+type Server struct {
+	Host string
+	Port int
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	messages []*Message
+	conns    map[net.Conn]struct{}
+}
+
+// NewServer starts a new server without any email.
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	addr := listener.Addr().(*net.TCPAddr)
+	s := &Server{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		listener: listener,
+		conns:    map[net.Conn]struct{}{},
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Config returns the configuration to send emails through the server.
+func (s *Server) Config() config.SMTPConfig {
+	return config.SMTPConfig{
+		Server:      s.Host,
+		Port:        s.Port,
+		FromAddress: FromAddress,
+		FromName:    FromName,
+	}
+}
+
+// Close stops the server and closes the open connections.
+func (s *Server) Close() {
+	s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Messages returns the emails received so far, in order.
+func (s *Server) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message{}, s.messages...)
+}
+
+// LastMessageTo returns the last email received for a recipient, or nil
+// if there is none.
+func (s *Server) LastMessageTo(to string) *Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		for _, recipient := range s.messages[i].To {
+			if strings.EqualFold(recipient, to) {
+				return s.messages[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	text := textproto.NewConn(conn)
+	if err := text.PrintfLine("220 localhost ESMTP"); err != nil {
+		return
+	}
+
+	var from string
+	var to []string
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, argument, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO", "EHLO":
+			err = text.PrintfLine("250 localhost")
+		case "MAIL":
+			from = address(argument)
+			to = nil
+			err = text.PrintfLine("250 OK")
+		case "RCPT":
+			to = append(to, address(argument))
+			err = text.PrintfLine("250 OK")
+		case "DATA":
+			if err = text.PrintfLine("354 End data with <CR><LF>.<CR><LF>"); err != nil {
+				return
+			}
+			var data []byte
+			if data, err = text.ReadDotBytes(); err != nil {
+				return
+			}
+			if err = s.receive(from, to, data); err != nil {
+				err = text.PrintfLine("554 %s", err.Error())
+			} else {
+				err = text.PrintfLine("250 OK")
+			}
+		case "RSET":
+			from, to = "", nil
+			err = text.PrintfLine("250 OK")
+		case "NOOP":
+			err = text.PrintfLine("250 OK")
+		case "QUIT":
+			_ = text.PrintfLine("221 Bye")
+			return
+		default:
+			err = text.PrintfLine("502 command not implemented")
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) receive(from string, to []string, data []byte) error {
+	parsed, err := netmail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		return err
+	}
+
+	body := parsed.Body
+	if strings.EqualFold(parsed.Header.Get("Content-Transfer-Encoding"), "quoted-printable") {
+		body = quotedprintable.NewReader(body)
+	}
+	bodyBytes, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, &Message{
+		From:    from,
+		To:      to,
+		Subject: subject,
+		Body:    strings.ReplaceAll(string(bodyBytes), "\r\n", "\n"),
+	})
+	return nil
+}
+
+// address extracts the address of a MAIL FROM:<...> or RCPT TO:<...>
+// argument.
+func address(argument string) string {
+	start := strings.Index(argument, "<")
+	end := strings.LastIndex(argument, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return argument[start+1 : end]
+}
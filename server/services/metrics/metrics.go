@@ -8,11 +8,12 @@ import (
 )
 
 const (
-	MetricsNamespace       = "focalboard"
-	MetricsSubsystemBlocks = "blocks"
-	MetricsSubsystemBoards = "boards"
-	MetricsSubsystemTeams  = "teams"
-	MetricsSubsystemSystem = "system"
+	MetricsNamespace          = "focalboard"
+	MetricsSubsystemBlocks    = "blocks"
+	MetricsSubsystemBoards    = "boards"
+	MetricsSubsystemTeams     = "teams"
+	MetricsSubsystemSystem    = "system"
+	MetricsSubsystemWebsocket = "websocket"
 
 	MetricsCloudInstallationLabel = "installationId"
 )
@@ -44,6 +45,10 @@ type Metrics struct {
 	teamCount  prometheus.Gauge
 
 	blockLastActivity prometheus.Gauge
+
+	websocketQueuedMessages       prometheus.Gauge
+	websocketDroppedMessagesCount prometheus.Counter
+	websocketSlowClientsCount     prometheus.Counter
 }
 
 // NewMetrics Factory method to create a new metrics collector.
@@ -172,6 +177,33 @@ func NewMetrics(info InstanceInfo) *Metrics {
 	})
 	m.registry.MustRegister(m.blockLastActivity)
 
+	m.websocketQueuedMessages = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemWebsocket,
+		Name:        "queued_messages",
+		Help:        "Number of messages waiting to be written to the websocket clients.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.websocketQueuedMessages)
+
+	m.websocketDroppedMessagesCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemWebsocket,
+		Name:        "dropped_messages_total",
+		Help:        "Total number of messages dropped because the websocket client was too slow or gone.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.websocketDroppedMessagesCount)
+
+	m.websocketSlowClientsCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemWebsocket,
+		Name:        "slow_clients_disconnected_total",
+		Help:        "Total number of websocket clients disconnected because their queue overflowed.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.websocketSlowClientsCount)
+
 	return m
 }
 
@@ -231,3 +263,21 @@ func (m *Metrics) ObserveTeamCount(count int64) {
 		m.teamCount.Set(float64(count))
 	}
 }
+
+func (m *Metrics) AddWebsocketQueuedMessages(num int) {
+	if m != nil {
+		m.websocketQueuedMessages.Add(float64(num))
+	}
+}
+
+func (m *Metrics) IncrementWebsocketDroppedMessages(num int) {
+	if m != nil {
+		m.websocketDroppedMessagesCount.Add(float64(num))
+	}
+}
+
+func (m *Metrics) IncrementWebsocketSlowClients() {
+	if m != nil {
+		m.websocketSlowClientsCount.Inc()
+	}
+}
@@ -237,5 +237,16 @@ func (b *Backend) deliverMentionNotification(username string, extract string, ev
 		}
 	}
 
+	// private cards can only mention the users that can see them.
+	if model.IsPrivateCard(evt.Card) {
+		member, err := b.appAPI.GetMemberForBoard(evt.Board.ID, mentionedUser.Id)
+		if err != nil && !model.IsErrNotFound(err) {
+			return "", fmt.Errorf("cannot lookup mentioned user membership: %w", err)
+		}
+		if !model.CanViewCard(evt.Card, mentionedUser.Id, member) {
+			return "", fmt.Errorf("%s cannot mention user %s on a private card: %w", evt.ModifiedBy.UserID, mentionedUser.Id, ErrMentionPermission)
+		}
+	}
+
 	return b.delivery.MentionDeliver(mentionedUser, extract, evt)
 }
@@ -309,6 +309,14 @@ func (dg *diffGenerator) generatePropDiffs(oldBlock, newBlock *model.Block, sche
 		)
 	}
 
+	// the values of properties restricted to some of the members are left
+	// out, since anyone subscribed to the card can read the notification.
+	hidden := model.GetPropertyRestrictions(dg.board, nil).Hidden
+	for id := range hidden {
+		delete(oldProps, id)
+		delete(newProps, id)
+	}
+
 	// look for new or changed properties.
 	for k, prop := range newProps {
 		oldP, ok := oldProps[k]
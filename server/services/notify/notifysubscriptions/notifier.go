@@ -11,6 +11,7 @@ import (
 
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/permissions"
+	"github.com/mattermost/focalboard/server/services/scheduler"
 	"github.com/mattermost/focalboard/server/utils"
 	"github.com/wiggin77/merror"
 
@@ -21,6 +22,10 @@ const (
 	defBlockNotificationFreq = time.Minute * 2
 	enqueueNotifyHintTimeout = time.Second * 10
 	hintQueueSize            = 20
+
+	// the leader of a cluster isn't told about the hints added by the
+	// other nodes, so it checks for them at least this often
+	clusterNotifyCheckFreq = time.Second * 30
 )
 
 var (
@@ -36,6 +41,7 @@ type notifier struct {
 	permissions permissions.PermissionsService
 	delivery    SubscriptionDelivery
 	logger      mlog.LoggerIFace
+	leader      scheduler.Leader
 
 	hints chan *model.NotificationHint
 
@@ -50,6 +56,7 @@ func newNotifier(params BackendParams) *notifier {
 		permissions: params.Permissions,
 		delivery:    params.Delivery,
 		logger:      params.Logger,
+		leader:      params.Leader,
 		done:        nil,
 		hints:       make(chan *model.NotificationHint, hintQueueSize),
 	}
@@ -80,23 +87,34 @@ func (n *notifier) loop() {
 	var nextNotify time.Time
 
 	for {
-		hint, err := n.store.GetNextNotificationHint(false)
-		switch {
-		case model.IsErrNotFound(err):
-			// no hints in table; wait up to an hour or when `onNotifyHint` is called again
-			nextNotify = time.Now().Add(time.Hour * 1)
-			n.logger.Debug("notify loop - no hints in queue", mlog.Time("next_check", nextNotify))
-		case err != nil:
-			// try again in a minute
-			nextNotify = time.Now().Add(time.Minute * 1)
-			n.logger.Error("notify loop - error fetching next notification", mlog.Err(err))
-		case hint.NotifyAt > utils.GetMillis():
-			// next hint is not ready yet; sleep until hint.NotifyAt
-			nextNotify = utils.GetTimeForMillis(hint.NotifyAt)
-		default:
-			// it's time to notify
-			n.notify()
-			continue
+		if n.leader != nil && !n.leader.IsLeader() {
+			// another node of the cluster sends the notifications; check
+			// again later in case this node becomes the leader
+			nextNotify = time.Now().Add(clusterNotifyCheckFreq)
+			n.logger.Debug("notify loop - not the leader", mlog.Time("next_check", nextNotify))
+		} else {
+			hint, err := n.store.GetNextNotificationHint(false)
+			switch {
+			case model.IsErrNotFound(err):
+				// no hints in table; wait up to an hour or when `onNotifyHint` is called again
+				nextNotify = time.Now().Add(time.Hour * 1)
+				n.logger.Debug("notify loop - no hints in queue", mlog.Time("next_check", nextNotify))
+			case err != nil:
+				// try again in a minute
+				nextNotify = time.Now().Add(time.Minute * 1)
+				n.logger.Error("notify loop - error fetching next notification", mlog.Err(err))
+			case hint.NotifyAt > utils.GetMillis():
+				// next hint is not ready yet; sleep until hint.NotifyAt
+				nextNotify = utils.GetTimeForMillis(hint.NotifyAt)
+			default:
+				// it's time to notify
+				n.notify()
+				continue
+			}
+
+			if n.leader != nil && time.Until(nextNotify) > clusterNotifyCheckFreq {
+				nextNotify = time.Now().Add(clusterNotifyCheckFreq)
+			}
 		}
 
 		n.logger.Debug("subscription notifier loop",
@@ -239,6 +257,23 @@ func (n *notifier) notifySubscribers(hint *model.NotificationHint) error {
 				continue
 			}
 
+			// private cards are only notified to the users that can see them.
+			if model.IsPrivateCard(card) {
+				member, err2 := n.store.GetMemberForBoard(board.ID, sub.SubscriberID)
+				if err2 != nil && !model.IsErrNotFound(err2) {
+					merr.Append(fmt.Errorf("cannot get board member %s: %w", sub.SubscriberID, err2))
+					continue
+				}
+				if !model.CanViewCard(card, sub.SubscriberID, member) {
+					n.logger.Debug("notifySubscribers - skipping user without access to private card",
+						mlog.Any("hint", hint),
+						mlog.String("subscriber_id", sub.SubscriberID),
+						mlog.String("card_id", card.ID),
+					)
+					continue
+				}
+			}
+
 			n.logger.Debug("notifySubscribers - deliver",
 				mlog.Any("hint", hint),
 				mlog.String("modified_by_id", hint.ModifiedByID),
@@ -0,0 +1,64 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package notifysubscriptions
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+type testLeader struct {
+	leader atomic.Bool
+}
+
+func (l *testLeader) IsLeader() bool {
+	return l.leader.Load()
+}
+
+// testHintStore always has a hint ready to be sent, and counts the hints
+// taken to be sent.
+type testHintStore struct {
+	AppAPI
+	taken atomic.Int32
+}
+
+func (s *testHintStore) GetNextNotificationHint(remove bool) (*model.NotificationHint, error) {
+	if remove {
+		s.taken.Add(1)
+		// the hint is sent by another node
+		return nil, model.NewErrNotFound("hint")
+	}
+	return &model.NotificationHint{BlockID: "block-id", NotifyAt: utils.GetMillis() - 1}, nil
+}
+
+func TestNotifierLeader(t *testing.T) {
+	store := &testHintStore{}
+	leader := &testLeader{}
+	n := newNotifier(BackendParams{
+		AppAPI: store,
+		Logger: mlog.CreateConsoleTestLogger(t),
+		Leader: leader,
+	})
+	n.start()
+	defer n.stop()
+
+	t.Run("only the leader sends the notifications", func(t *testing.T) {
+		require.NoError(t, n.onNotifyHint(&model.NotificationHint{BlockID: "block-id"}))
+		time.Sleep(100 * time.Millisecond)
+		require.Zero(t, store.taken.Load())
+	})
+
+	t.Run("a node sends the notifications once it's the leader", func(t *testing.T) {
+		leader.leader.Store(true)
+		require.NoError(t, n.onNotifyHint(&model.NotificationHint{BlockID: "block-id"}))
+		require.Eventually(t, func() bool { return store.taken.Load() > 0 }, time.Second, 10*time.Millisecond)
+	})
+}
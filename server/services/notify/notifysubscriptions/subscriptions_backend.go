@@ -12,6 +12,7 @@ import (
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/notify"
 	"github.com/mattermost/focalboard/server/services/permissions"
+	"github.com/mattermost/focalboard/server/services/scheduler"
 	"github.com/wiggin77/merror"
 
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
@@ -29,6 +30,10 @@ type BackendParams struct {
 	Logger                 mlog.LoggerIFace
 	NotifyFreqCardSeconds  int
 	NotifyFreqBoardSeconds int
+
+	// Leader tells whether the node sends the notifications of the
+	// cluster. When nil, the node sends them.
+	Leader scheduler.Leader
 }
 
 // Backend provides the notification backend for subscriptions.
@@ -0,0 +1,185 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	algorithmRS256 = "RS256"
+	clockSkew      = time.Minute
+)
+
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jwt struct {
+	header       jwtHeader
+	claims       Claims
+	signingInput string
+	signature    []byte
+}
+
+func parseJWT(raw string) (*jwt, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidIDToken)
+	}
+
+	token := &jwt{signingInput: parts[0] + "." + parts[1]}
+
+	if err := decodeSegment(parts[0], &token.header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidIDToken)
+	}
+	if err := decodeSegment(parts[1], &token.claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidIDToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidIDToken)
+	}
+	token.signature = signature
+
+	return token, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verify checks the token signature. Only RS256, the algorithm every
+// provider must support, is accepted.
+func (t *jwt) verify(key *rsa.PublicKey) error {
+	if t.header.Algorithm != algorithmRS256 {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidIDToken, t.header.Algorithm)
+	}
+
+	digest := sha256.Sum256([]byte(t.signingInput))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], t.signature); err != nil {
+		return fmt.Errorf("%w: bad signature", ErrInvalidIDToken)
+	}
+	return nil
+}
+
+// Claims are the claims of an ID token.
+type Claims map[string]interface{}
+
+// String returns a string claim, or an empty string if the claim is
+// missing or isn't a string.
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Strings returns a claim that can be either a single string or a list
+// of strings.
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func (c Claims) time(name string) (time.Time, bool) {
+	value, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(value), 0), true
+}
+
+func (c Claims) validate(issuer, clientID, nonce string, now time.Time) error {
+	if strings.TrimRight(c.String("iss"), "/") != strings.TrimRight(issuer, "/") {
+		return fmt.Errorf("%w: wrong issuer", ErrInvalidIDToken)
+	}
+
+	audienceFound := false
+	for _, audience := range c.Strings("aud") {
+		if audience == clientID {
+			audienceFound = true
+			break
+		}
+	}
+	if !audienceFound {
+		return fmt.Errorf("%w: wrong audience", ErrInvalidIDToken)
+	}
+
+	expiresAt, ok := c.time("exp")
+	if !ok || now.After(expiresAt.Add(clockSkew)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidIDToken)
+	}
+
+	if c.String("nonce") != nonce {
+		return fmt.Errorf("%w: wrong nonce", ErrInvalidIDToken)
+	}
+
+	if c.String("sub") == "" {
+		return fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return nil
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// rsaKeys returns the RSA signing keys of the set by key ID.
+func (s jsonWebKeySet) rsaKeys() (map[string]*rsa.PublicKey, error) {
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range s.Keys {
+		if key.KeyType != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		modulus, err := base64.RawURLEncoding.DecodeString(key.Modulus)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus for key %q: %w", key.KeyID, err)
+		}
+		exponent, err := base64.RawURLEncoding.DecodeString(key.Exponent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent for key %q: %w", key.KeyID, err)
+		}
+
+		keys[key.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}
+	}
+	return keys, nil
+}
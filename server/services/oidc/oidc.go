@@ -0,0 +1,291 @@
+// Package oidc implements the parts of OpenID Connect needed to log users
+// in with the authorization code flow and PKCE.
+package oidc
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/config"
+)
+
+const (
+	CallbackPath = "/oidc/callback"
+
+	DefaultUsernameClaim = "preferred_username"
+	DefaultRolesClaim    = "roles"
+
+	discoveryPath  = "/.well-known/openid-configuration"
+	requestTimeout = 10 * time.Second
+	maxBodySize    = 1 << 20
+)
+
+var (
+	ErrNoIDToken      = errors.New("token response doesn't contain an ID token")
+	ErrIssuerMismatch = errors.New("issuer doesn't match the configured issuer")
+)
+
+var defaultScopes = []string{"openid", "profile", "email"}
+
+// Discovery is the subset of the provider metadata used by the client.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// TokenResponse is the response of the token endpoint.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Provider is an OpenID Connect provider. Its metadata and keys are
+// fetched lazily and cached.
+type Provider struct {
+	config      config.OIDCConfig
+	redirectURL string
+	httpClient  *http.Client
+
+	mu        sync.Mutex
+	discovery *Discovery
+	keys      map[string]*rsa.PublicKey
+}
+
+// New creates a provider for the given configuration. The redirect URL
+// is the callback of the server registered in the provider.
+func New(cfg config.OIDCConfig, serverRoot string) *Provider {
+	return &Provider{
+		config:      cfg,
+		redirectURL: strings.TrimRight(serverRoot, "/") + CallbackPath,
+		httpClient:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Config returns the provider configuration.
+func (p *Provider) Config() config.OIDCConfig {
+	return p.config
+}
+
+// UsernameClaim returns the claim used as the username of new users.
+func (p *Provider) UsernameClaim() string {
+	if p.config.UsernameClaim == "" {
+		return DefaultUsernameClaim
+	}
+	return p.config.UsernameClaim
+}
+
+// RolesClaim returns the claim that holds the roles of a user.
+func (p *Provider) RolesClaim() string {
+	if p.config.RolesClaim == "" {
+		return DefaultRolesClaim
+	}
+	return p.config.RolesClaim
+}
+
+func (p *Provider) scopes() []string {
+	if len(p.config.Scopes) == 0 {
+		return defaultScopes
+	}
+	return p.config.Scopes
+}
+
+// Discover returns the provider metadata, fetching it if needed.
+func (p *Provider) Discover() (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery Discovery
+	issuer := strings.TrimRight(p.config.Issuer, "/")
+	if err := p.getJSON(issuer+discoveryPath, &discovery); err != nil {
+		return nil, fmt.Errorf("unable to fetch provider metadata: %w", err)
+	}
+
+	if strings.TrimRight(discovery.Issuer, "/") != issuer {
+		return nil, ErrIssuerMismatch
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("incomplete provider metadata")
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// AuthCodeURL returns the URL of the provider to redirect the user to.
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	discovery, err := p.Discover()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.config.ClientID)
+	params.Set("redirect_uri", p.redirectURL)
+	params.Set("scope", strings.Join(p.scopes(), " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", CodeChallengeMethod)
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange trades an authorization code for the provider tokens.
+func (p *Provider) Exchange(code, codeVerifier string) (*TokenResponse, error) {
+	discovery, err := p.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var token TokenResponse
+	if err := p.doJSON(req, &token); err != nil {
+		return nil, fmt.Errorf("unable to exchange authorization code: %w", err)
+	}
+
+	if token.IDToken == "" {
+		return nil, ErrNoIDToken
+	}
+
+	return &token, nil
+}
+
+// VerifyIDToken checks the signature and the claims of an ID token and
+// returns its claims.
+func (p *Provider) VerifyIDToken(rawIDToken, nonce string) (Claims, error) {
+	discovery, err := p.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := parseJWT(rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := p.publicKey(token.header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := token.verify(key); err != nil {
+		return nil, err
+	}
+
+	if err := token.claims.validate(discovery.Issuer, p.config.ClientID, nonce, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return token.claims, nil
+}
+
+// publicKey returns the signing key with the given ID. The keys are
+// fetched again when the key isn't known, as providers rotate them.
+func (p *Provider) publicKey(keyID string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.findKey(keyID)
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	discovery, err := p.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	var keySet jsonWebKeySet
+	if err := p.getJSON(discovery.JWKSURI, &keySet); err != nil {
+		return nil, fmt.Errorf("unable to fetch provider keys: %w", err)
+	}
+
+	keys, err := keySet.rsaKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = keys
+
+	key, ok = p.findKey(keyID)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+	return key, nil
+}
+
+func (p *Provider) findKey(keyID string) (*rsa.PublicKey, bool) {
+	if keyID == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[keyID]
+	return key, ok
+}
+
+func (p *Provider) getJSON(url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	return p.doJSON(req, v)
+}
+
+func (p *Provider) doJSON(req *http.Request, v interface{}) error {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,167 @@
+package oidc_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/oidc"
+	"github.com/mattermost/focalboard/server/services/oidc/oidctest"
+	"github.com/stretchr/testify/require"
+)
+
+const serverRoot = "http://localhost:8000"
+
+func setupProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	idp, err := oidctest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(idp.Close)
+
+	return idp, oidc.New(idp.Config(), serverRoot)
+}
+
+// authorize follows the provider redirect and returns the authorization code.
+func authorize(t *testing.T, authURL, state string) string {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, serverRoot+oidc.CallbackPath, location.Scheme+"://"+location.Host+location.Path)
+	require.Equal(t, state, location.Query().Get("state"))
+
+	return location.Query().Get("code")
+}
+
+func TestDiscover(t *testing.T) {
+	idp, provider := setupProvider(t)
+
+	discovery, err := provider.Discover()
+	require.NoError(t, err)
+	require.Equal(t, idp.URL, discovery.Issuer)
+	require.Equal(t, idp.URL+"/token", discovery.TokenEndpoint)
+
+	t.Run("issuer mismatch", func(t *testing.T) {
+		config := idp.Config()
+		config.Issuer = idp.URL + "/other"
+		_, err := oidc.New(config, serverRoot).Discover()
+		require.Error(t, err)
+	})
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp, provider := setupProvider(t)
+	idp.SetUser(map[string]interface{}{
+		"sub":                "subject-id",
+		"preferred_username": "john",
+		"roles":              []string{"admin"},
+	})
+
+	verifier, err := oidc.NewRandomValue()
+	require.NoError(t, err)
+
+	authURL, err := provider.AuthCodeURL("state-value", "nonce-value", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(authURL, idp.URL+"/authorize?"))
+
+	t.Run("successful login", func(t *testing.T) {
+		code := authorize(t, authURL, "state-value")
+
+		token, err := provider.Exchange(code, verifier)
+		require.NoError(t, err)
+
+		claims, err := provider.VerifyIDToken(token.IDToken, "nonce-value")
+		require.NoError(t, err)
+		require.Equal(t, "subject-id", claims.String("sub"))
+		require.Equal(t, "john", claims.String(provider.UsernameClaim()))
+		require.Equal(t, []string{"admin"}, claims.Strings(provider.RolesClaim()))
+	})
+
+	t.Run("codes can only be used once", func(t *testing.T) {
+		code := authorize(t, authURL, "state-value")
+
+		_, err := provider.Exchange(code, verifier)
+		require.NoError(t, err)
+
+		_, err = provider.Exchange(code, verifier)
+		require.Error(t, err)
+	})
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		code := authorize(t, authURL, "state-value")
+
+		_, err := provider.Exchange(code, "wrong-verifier")
+		require.Error(t, err)
+	})
+
+	t.Run("wrong nonce", func(t *testing.T) {
+		code := authorize(t, authURL, "state-value")
+
+		token, err := provider.Exchange(code, verifier)
+		require.NoError(t, err)
+
+		_, err = provider.VerifyIDToken(token.IDToken, "other-nonce")
+		require.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	})
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp, provider := setupProvider(t)
+
+	for name, tc := range map[string]struct {
+		claims map[string]interface{}
+		valid  bool
+	}{
+		"valid token":    {map[string]interface{}{"sub": "subject-id"}, true},
+		"missing sub":    {map[string]interface{}{}, false},
+		"wrong issuer":   {map[string]interface{}{"sub": "subject-id", "iss": "https://example.com"}, false},
+		"wrong audience": {map[string]interface{}{"sub": "subject-id", "aud": "other-client"}, false},
+		"audience list":  {map[string]interface{}{"sub": "subject-id", "aud": []string{"other-client", oidctest.ClientID}}, true},
+		"expired":        {map[string]interface{}{"sub": "subject-id", "exp": time.Now().Add(-time.Hour).Unix()}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			idToken, err := idp.SignIDToken(tc.claims, "nonce-value")
+			require.NoError(t, err)
+
+			_, err = provider.VerifyIDToken(idToken, "nonce-value")
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+			}
+		})
+	}
+
+	t.Run("tampered claims", func(t *testing.T) {
+		idToken, err := idp.SignIDToken(map[string]interface{}{"sub": "subject-id"}, "nonce-value")
+		require.NoError(t, err)
+
+		other, err := idp.SignIDToken(map[string]interface{}{"sub": "admin-id"}, "nonce-value")
+		require.NoError(t, err)
+
+		parts := strings.Split(idToken, ".")
+		otherParts := strings.Split(other, ".")
+		tampered := parts[0] + "." + otherParts[1] + "." + parts[2]
+
+		_, err = provider.VerifyIDToken(tampered, "nonce-value")
+		require.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	})
+
+	t.Run("unsigned token", func(t *testing.T) {
+		idToken, err := idp.SignIDToken(map[string]interface{}{"sub": "subject-id"}, "nonce-value")
+		require.NoError(t, err)
+
+		parts := strings.Split(idToken, ".")
+		unsigned := "eyJhbGciOiJub25lIn0." + parts[1] + "."
+
+		_, err = provider.VerifyIDToken(unsigned, "nonce-value")
+		require.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	})
+}
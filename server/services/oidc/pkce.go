@@ -0,0 +1,29 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+const (
+	CodeChallengeMethod = "S256"
+
+	randomValueLength = 32
+)
+
+// NewRandomValue returns a random URL safe value, suitable for states,
+// nonces and PKCE code verifiers.
+func NewRandomValue() (string, error) {
+	value := make([]byte, randomValueLength)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+// CodeChallenge returns the S256 PKCE challenge of a code verifier.
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
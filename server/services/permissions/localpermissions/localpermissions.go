@@ -23,18 +23,83 @@ func New(store permissions.Store, logger mlog.LoggerIFace) *Service {
 	}
 }
 
+// systemAdminPermissions are the system wide permissions granted to the
+// system admins. The other system permissions, such as the analytics or
+// the compliance exports, are only available in plugin mode.
+var systemAdminPermissions = map[string]bool{
+	model.PermissionManageSystem.Id: true,
+}
+
+// HasPermissionTo grants the system admin permissions to the system
+// admins only.
 func (s *Service) HasPermissionTo(userID string, permission *mmModel.Permission) bool {
-	return false
+	if userID == "" || permission == nil || !systemAdminPermissions[permission.Id] {
+		return false
+	}
+	return s.isSystemAdmin(userID)
 }
 
+// HasPermissionToTeam grants access to the root team to all the users,
+// and to the other teams to their members. Teams are managed by the
+// system admins and by the team admins.
 func (s *Service) HasPermissionToTeam(userID, teamID string, permission *mmModel.Permission) bool {
 	if userID == "" || teamID == "" || permission == nil {
 		return false
 	}
-	if permission.Id == model.PermissionManageTeam.Id {
+
+	// the user of the single-user mode owns all the teams
+	if teamID == model.GlobalTeamID || userID == model.SingleUser {
+		if permission.Id == model.PermissionManageTeam.Id {
+			return s.isSystemAdmin(userID)
+		}
+		return true
+	}
+
+	member, err := s.store.GetTeamMember(teamID, userID)
+	if err != nil && !model.IsErrNotFound(err) {
+		s.logger.Error("error getting team member",
+			mlog.String("teamID", teamID),
+			mlog.String("userID", userID),
+			mlog.Err(err),
+		)
 		return false
 	}
-	return true
+
+	if member != nil && (permission.Id != model.PermissionManageTeam.Id || member.IsAdmin()) {
+		return true
+	}
+	return s.isSystemAdmin(userID)
+}
+
+func (s *Service) isGuest(userID string) bool {
+	user, err := s.store.GetUserByID(userID)
+	if model.IsErrNotFound(err) {
+		return false
+	}
+	if err != nil {
+		// without the user, the safe answer is the most restrictive one
+		s.logger.Error("error getting user",
+			mlog.String("userID", userID),
+			mlog.Err(err),
+		)
+		return true
+	}
+	return user.IsGuest
+}
+
+func (s *Service) isSystemAdmin(userID string) bool {
+	user, err := s.store.GetUserByID(userID)
+	if model.IsErrNotFound(err) {
+		return false
+	}
+	if err != nil {
+		s.logger.Error("error getting user",
+			mlog.String("userID", userID),
+			mlog.Err(err),
+		)
+		return false
+	}
+	return user.IsSystemAdmin()
 }
 
 func (s *Service) HasPermissionToChannel(userID, channelID string, permission *mmModel.Permission) bool {
@@ -64,7 +129,12 @@ func (s *Service) HasPermissionToBoard(userID, boardID string, permission *mmMod
 
 	switch member.MinimumRole {
 	case "admin":
-		member.SchemeAdmin = true
+		// guests are never board admins
+		if s.isGuest(userID) {
+			member.SchemeEditor = true
+		} else {
+			member.SchemeAdmin = true
+		}
 	case "editor":
 		member.SchemeEditor = true
 	case "commenter":
@@ -73,6 +143,13 @@ func (s *Service) HasPermissionToBoard(userID, boardID string, permission *mmMod
 		member.SchemeViewer = true
 	}
 
+	if hasSchemePermission(member, permission) {
+		return true
+	}
+	return s.hasCustomRolePermission(member, permission)
+}
+
+func hasSchemePermission(member *model.BoardMember, permission *mmModel.Permission) bool {
 	switch permission {
 	case model.PermissionManageBoardType, model.PermissionDeleteBoard, model.PermissionManageBoardRoles, model.PermissionShareBoard, model.PermissionDeleteOthersComments:
 		return member.SchemeAdmin
@@ -86,3 +163,30 @@ func (s *Service) HasPermissionToBoard(userID, boardID string, permission *mmMod
 		return false
 	}
 }
+
+// hasCustomRolePermission checks the permissions granted to the member by
+// their custom board role, if they have one. Guests never get the board
+// admin permissions from their custom role.
+func (s *Service) hasCustomRolePermission(member *model.BoardMember, permission *mmModel.Permission) bool {
+	if member.CustomRoleID == "" {
+		return false
+	}
+
+	role, err := s.store.GetCustomBoardRole(member.CustomRoleID)
+	if model.IsErrNotFound(err) {
+		return false
+	}
+	if err != nil {
+		s.logger.Error("error getting custom board role",
+			mlog.String("boardID", member.BoardID),
+			mlog.String("roleID", member.CustomRoleID),
+			mlog.Err(err),
+		)
+		return false
+	}
+
+	if role.BoardID != member.BoardID || !role.HasPermission(permission) {
+		return false
+	}
+	return !model.IsBoardAdminPermission(permission) || !s.isGuest(member.UserID)
+}
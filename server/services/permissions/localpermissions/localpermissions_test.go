@@ -23,14 +23,77 @@ func TestHasPermissionToTeam(t *testing.T) {
 		assert.False(t, th.permissions.HasPermissionToTeam("user-id", "team-id", nil))
 	})
 
-	t.Run("all users have all permissions on teams", func(t *testing.T) {
-		hasPermission := th.permissions.HasPermissionToTeam("user-id", "team-id", model.PermissionManageBoardCards)
+	t.Run("all users have all permissions on the root team", func(t *testing.T) {
+		hasPermission := th.permissions.HasPermissionToTeam("user-id", model.GlobalTeamID, model.PermissionManageBoardCards)
 		assert.True(t, hasPermission)
 	})
 
-	t.Run("no users have PermissionManageTeam on teams", func(t *testing.T) {
+	t.Run("only system admins have PermissionManageTeam on the root team", func(t *testing.T) {
+		th.store.EXPECT().GetUserByID("user-id").Return(&model.User{ID: "user-id", Roles: model.SystemUserRoleID}, nil)
+		hasPermission := th.permissions.HasPermissionToTeam("user-id", model.GlobalTeamID, model.PermissionManageTeam)
+		assert.False(t, hasPermission)
+
+		th.store.EXPECT().GetUserByID("admin-id").Return(&model.User{ID: "admin-id", Roles: "system_user system_admin"}, nil)
+		hasPermission = th.permissions.HasPermissionToTeam("admin-id", model.GlobalTeamID, model.PermissionManageTeam)
+		assert.True(t, hasPermission)
+	})
+
+	t.Run("team members have permissions on their team", func(t *testing.T) {
+		th.store.EXPECT().GetTeamMember("team-id", "user-id").Return(&model.TeamMember{TeamID: "team-id", UserID: "user-id"}, nil)
+		hasPermission := th.permissions.HasPermissionToTeam("user-id", "team-id", model.PermissionViewTeam)
+		assert.True(t, hasPermission)
+
+		th.store.EXPECT().GetTeamMember("team-id", "user-id").Return(&model.TeamMember{TeamID: "team-id", UserID: "user-id"}, nil)
+		th.store.EXPECT().GetUserByID("user-id").Return(&model.User{ID: "user-id", Roles: model.SystemUserRoleID}, nil)
+		hasPermission = th.permissions.HasPermissionToTeam("user-id", "team-id", model.PermissionManageTeam)
+		assert.False(t, hasPermission)
+	})
+
+	t.Run("team admins have PermissionManageTeam on their team", func(t *testing.T) {
+		th.store.EXPECT().GetTeamMember("team-id", "user-id").Return(&model.TeamMember{TeamID: "team-id", UserID: "user-id", Roles: model.TeamRoleAdmin}, nil)
 		hasPermission := th.permissions.HasPermissionToTeam("user-id", "team-id", model.PermissionManageTeam)
+		assert.True(t, hasPermission)
+	})
+
+	t.Run("users have no permissions on the teams they aren't members of", func(t *testing.T) {
+		th.store.EXPECT().GetTeamMember("team-id", "user-id").Return(nil, model.NewErrNotFound("team member"))
+		th.store.EXPECT().GetUserByID("user-id").Return(&model.User{ID: "user-id", Roles: model.SystemUserRoleID}, nil)
+		hasPermission := th.permissions.HasPermissionToTeam("user-id", "team-id", model.PermissionViewTeam)
 		assert.False(t, hasPermission)
+
+		th.store.EXPECT().GetTeamMember("team-id", "admin-id").Return(nil, model.NewErrNotFound("team member"))
+		th.store.EXPECT().GetUserByID("admin-id").Return(&model.User{ID: "admin-id", Roles: "system_user system_admin"}, nil)
+		hasPermission = th.permissions.HasPermissionToTeam("admin-id", "team-id", model.PermissionViewTeam)
+		assert.True(t, hasPermission)
+	})
+}
+
+func TestHasPermissionTo(t *testing.T) {
+	th := SetupTestHelper(t)
+
+	t.Run("empty input should always unauthorize", func(t *testing.T) {
+		assert.False(t, th.permissions.HasPermissionTo("", model.PermissionManageSystem))
+		assert.False(t, th.permissions.HasPermissionTo("user-id", nil))
+	})
+
+	t.Run("regular users don't have system permissions", func(t *testing.T) {
+		th.store.EXPECT().GetUserByID("user-id").Return(&model.User{ID: "user-id"}, nil)
+		assert.False(t, th.permissions.HasPermissionTo("user-id", model.PermissionManageSystem))
+	})
+
+	t.Run("system admins have system permissions", func(t *testing.T) {
+		th.store.EXPECT().GetUserByID("admin-id").Return(&model.User{ID: "admin-id", Roles: model.SystemAdminRoleID}, nil)
+		assert.True(t, th.permissions.HasPermissionTo("admin-id", model.PermissionManageSystem))
+	})
+
+	t.Run("nonexistent users don't have system permissions", func(t *testing.T) {
+		th.store.EXPECT().GetUserByID("nonexistent-id").Return(nil, model.NewErrNotFound("user"))
+		assert.False(t, th.permissions.HasPermissionTo("nonexistent-id", model.PermissionManageSystem))
+	})
+
+	t.Run("system admins only have the system admin permissions", func(t *testing.T) {
+		assert.False(t, th.permissions.HasPermissionTo("admin-id", mmModel.PermissionGetAnalytics))
+		assert.False(t, th.permissions.HasPermissionTo("admin-id", mmModel.PermissionManageSystemWideOAuth))
 	})
 }
 
@@ -169,4 +232,129 @@ func TestHasPermissionToBoard(t *testing.T) {
 
 		th.checkBoardPermissions("viewer", member, hasPermissionTo, hasNotPermissionTo)
 	})
+
+	t.Run("custom role", func(t *testing.T) {
+		member := &model.BoardMember{
+			UserID:          "user-id",
+			BoardID:         "board-id",
+			SchemeCommenter: true,
+			CustomRoleID:    "role-id",
+		}
+
+		th.store.EXPECT().
+			GetCustomBoardRole("role-id").
+			Return(&model.CustomBoardRole{
+				ID:          "role-id",
+				BoardID:     "board-id",
+				Name:        "Card editor",
+				Permissions: []string{model.PermissionManageBoardCards.Id},
+			}, nil).
+			AnyTimes()
+
+		hasPermissionTo := []*mmModel.Permission{
+			model.PermissionViewBoard,
+			model.PermissionCommentBoardCards,
+			model.PermissionManageBoardCards,
+		}
+
+		hasNotPermissionTo := []*mmModel.Permission{
+			model.PermissionManageBoardType,
+			model.PermissionDeleteBoard,
+			model.PermissionManageBoardRoles,
+			model.PermissionShareBoard,
+			model.PermissionManageBoardProperties,
+		}
+
+		th.checkBoardPermissions("custom role", member, hasPermissionTo, hasNotPermissionTo)
+	})
+
+	t.Run("custom role of another board", func(t *testing.T) {
+		member := &model.BoardMember{
+			UserID:       "user-id",
+			BoardID:      "board-id",
+			CustomRoleID: "other-role-id",
+		}
+
+		th.store.EXPECT().
+			GetCustomBoardRole("other-role-id").
+			Return(&model.CustomBoardRole{
+				ID:          "other-role-id",
+				BoardID:     "other-board-id",
+				Name:        "Card editor",
+				Permissions: []string{model.PermissionManageBoardCards.Id},
+			}, nil).
+			AnyTimes()
+
+		hasNotPermissionTo := []*mmModel.Permission{
+			model.PermissionViewBoard,
+			model.PermissionManageBoardCards,
+		}
+
+		th.checkBoardPermissions("foreign custom role", member, []*mmModel.Permission{}, hasNotPermissionTo)
+	})
+
+	t.Run("guests don't get admin permissions from the minimum role", func(t *testing.T) {
+		member := &model.BoardMember{
+			UserID:      "guest-id",
+			BoardID:     "board-id",
+			MinimumRole: "admin",
+		}
+
+		th.store.EXPECT().
+			GetUserByID("guest-id").
+			Return(&model.User{ID: "guest-id", IsGuest: true}, nil).
+			AnyTimes()
+
+		hasPermissionTo := []*mmModel.Permission{
+			model.PermissionViewBoard,
+			model.PermissionManageBoardCards,
+		}
+
+		hasNotPermissionTo := []*mmModel.Permission{
+			model.PermissionManageBoardRoles,
+			model.PermissionDeleteBoard,
+		}
+
+		th.checkBoardPermissions("guest", member, hasPermissionTo, hasNotPermissionTo)
+	})
+
+	t.Run("guests don't get admin permissions from a custom role", func(t *testing.T) {
+		member := &model.BoardMember{
+			UserID:       "guest-id",
+			BoardID:      "board-id",
+			SchemeViewer: true,
+			CustomRoleID: "admin-role-id",
+		}
+
+		th.store.EXPECT().
+			GetCustomBoardRole("admin-role-id").
+			Return(&model.CustomBoardRole{
+				ID:      "admin-role-id",
+				BoardID: "board-id",
+				Name:    "Almost admin",
+				Permissions: []string{
+					model.PermissionManageBoardCards.Id,
+					model.PermissionManageBoardRoles.Id,
+					model.PermissionDeleteBoard.Id,
+				},
+			}, nil).
+			AnyTimes()
+
+		th.store.EXPECT().
+			GetUserByID("guest-id").
+			Return(&model.User{ID: "guest-id", IsGuest: true}, nil).
+			AnyTimes()
+
+		hasPermissionTo := []*mmModel.Permission{
+			model.PermissionViewBoard,
+			model.PermissionManageBoardCards,
+		}
+
+		hasNotPermissionTo := []*mmModel.Permission{
+			model.PermissionManageBoardRoles,
+			model.PermissionDeleteBoard,
+		}
+
+		th.checkBoardPermissions("guest custom role", member, hasPermissionTo, hasNotPermissionTo)
+	})
 }
@@ -115,14 +115,67 @@ func (s *Service) HasPermissionToBoard(userID, boardID string, permission *mmMod
 
 	switch permission {
 	case model.PermissionManageBoardType, model.PermissionDeleteBoard, model.PermissionManageBoardRoles, model.PermissionShareBoard, model.PermissionDeleteOthersComments:
-		return member.SchemeAdmin
+		if member.SchemeAdmin {
+			return true
+		}
 	case model.PermissionManageBoardCards, model.PermissionManageBoardProperties:
-		return member.SchemeAdmin || member.SchemeEditor
+		if member.SchemeAdmin || member.SchemeEditor {
+			return true
+		}
 	case model.PermissionCommentBoardCards:
-		return member.SchemeAdmin || member.SchemeEditor || member.SchemeCommenter
+		if member.SchemeAdmin || member.SchemeEditor || member.SchemeCommenter {
+			return true
+		}
 	case model.PermissionViewBoard:
-		return member.SchemeAdmin || member.SchemeEditor || member.SchemeCommenter || member.SchemeViewer
+		if member.SchemeAdmin || member.SchemeEditor || member.SchemeCommenter || member.SchemeViewer {
+			return true
+		}
 	default:
 		return false
 	}
+
+	return s.hasCustomRolePermission(member, permission)
+}
+
+func (s *Service) isGuest(userID string) bool {
+	user, err := s.store.GetUserByID(userID)
+	if model.IsErrNotFound(err) {
+		return false
+	}
+	if err != nil {
+		// without the user, the safe answer is the most restrictive one
+		s.logger.Error("error getting user",
+			mlog.String("userID", userID),
+			mlog.Err(err),
+		)
+		return true
+	}
+	return user.IsGuest
+}
+
+// hasCustomRolePermission checks the permissions granted to the member by
+// their custom board role, if they have one. Guests never get the board
+// admin permissions from their custom role.
+func (s *Service) hasCustomRolePermission(member *model.BoardMember, permission *mmModel.Permission) bool {
+	if member.CustomRoleID == "" {
+		return false
+	}
+
+	role, err := s.store.GetCustomBoardRole(member.CustomRoleID)
+	if model.IsErrNotFound(err) {
+		return false
+	}
+	if err != nil {
+		s.logger.Error("error getting custom board role",
+			mlog.String("boardID", member.BoardID),
+			mlog.String("roleID", member.CustomRoleID),
+			mlog.Err(err),
+		)
+		return false
+	}
+
+	if role.BoardID != member.BoardID || !role.HasPermission(permission) {
+		return false
+	}
+	return !model.IsBoardAdminPermission(permission) || !s.isGuest(member.UserID)
 }
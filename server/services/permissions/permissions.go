@@ -20,5 +20,8 @@ type PermissionsService interface {
 type Store interface {
 	GetBoard(boardID string) (*model.Board, error)
 	GetMemberForBoard(boardID, userID string) (*model.BoardMember, error)
+	GetCustomBoardRole(roleID string) (*model.CustomBoardRole, error)
 	GetBoardHistory(boardID string, opts model.QueryBoardHistoryOptions) ([]*model.Board, error)
+	GetUserByID(userID string) (*model.User, error)
+	GetTeamMember(teamID, userID string) (*model.TeamMember, error)
 }
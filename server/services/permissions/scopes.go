@@ -0,0 +1,142 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package permissions
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+)
+
+// scopePermissions lists the permissions that each access token
+// scope grants. Scopes are additive.
+var scopePermissions = map[string][]*mmModel.Permission{
+	model.AccessTokenScopeReadOnly: {
+		model.PermissionViewTeam,
+		model.PermissionReadChannel,
+		model.PermissionViewMembers,
+		model.PermissionViewBoard,
+	},
+	model.AccessTokenScopeCardsWrite: {
+		model.PermissionViewTeam,
+		model.PermissionReadChannel,
+		model.PermissionViewMembers,
+		model.PermissionViewBoard,
+		model.PermissionManageBoardCards,
+		model.PermissionCommentBoardCards,
+	},
+	model.AccessTokenScopeBoardsAdmin: {
+		model.PermissionViewTeam,
+		model.PermissionReadChannel,
+		model.PermissionViewMembers,
+		model.PermissionCreatePost,
+		model.PermissionCreatePublicChannel,
+		model.PermissionCreatePrivateChannel,
+		model.PermissionViewBoard,
+		model.PermissionManageBoardCards,
+		model.PermissionCommentBoardCards,
+		model.PermissionManageBoardType,
+		model.PermissionDeleteBoard,
+		model.PermissionManageBoardRoles,
+		model.PermissionShareBoard,
+		model.PermissionManageBoardProperties,
+		model.PermissionDeleteOthersComments,
+	},
+}
+
+// ScopesAllowPermission returns true if any of the scopes grants the
+// permission. An empty set of scopes doesn't restrict anything.
+func ScopesAllowPermission(scopes []string, permission *mmModel.Permission) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	if permission == nil {
+		return false
+	}
+
+	for _, scope := range scopes {
+		for _, p := range scopePermissions[scope] {
+			if p.Id == permission.Id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ScopesAllowWrite returns true if the scopes allow any kind of
+// modification.
+func ScopesAllowWrite(scopes []string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+
+	for _, scope := range scopes {
+		if scope != model.AccessTokenScopeReadOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopesAllowAny returns true if any of the scopes is one of the
+// allowed ones. An empty set of scopes doesn't restrict anything.
+func ScopesAllowAny(scopes []string, allowed ...string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+
+	for _, scope := range scopes {
+		for _, a := range allowed {
+			if scope == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ScopedService restricts the permissions of an underlying service to
+// the ones granted by a set of access token scopes.
+type ScopedService struct {
+	PermissionsService
+	scopes []string
+}
+
+// NewScopedService wraps a permissions service so only the permissions
+// granted by the scopes are evaluated.
+func NewScopedService(service PermissionsService, scopes []string) *ScopedService {
+	return &ScopedService{
+		PermissionsService: service,
+		scopes:             scopes,
+	}
+}
+
+func (s *ScopedService) HasPermissionTo(userID string, permission *mmModel.Permission) bool {
+	if !ScopesAllowPermission(s.scopes, permission) {
+		return false
+	}
+	return s.PermissionsService.HasPermissionTo(userID, permission)
+}
+
+func (s *ScopedService) HasPermissionToTeam(userID, teamID string, permission *mmModel.Permission) bool {
+	if !ScopesAllowPermission(s.scopes, permission) {
+		return false
+	}
+	return s.PermissionsService.HasPermissionToTeam(userID, teamID, permission)
+}
+
+func (s *ScopedService) HasPermissionToChannel(userID, channelID string, permission *mmModel.Permission) bool {
+	if !ScopesAllowPermission(s.scopes, permission) {
+		return false
+	}
+	return s.PermissionsService.HasPermissionToChannel(userID, channelID, permission)
+}
+
+func (s *ScopedService) HasPermissionToBoard(userID, boardID string, permission *mmModel.Permission) bool {
+	if !ScopesAllowPermission(s.scopes, permission) {
+		return false
+	}
+	return s.PermissionsService.HasPermissionToBoard(userID, boardID, permission)
+}
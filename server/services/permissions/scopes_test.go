@@ -0,0 +1,82 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package permissions
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type allowAllService struct{}
+
+func (allowAllService) HasPermissionTo(string, *mmModel.Permission) bool { return true }
+func (allowAllService) HasPermissionToTeam(string, string, *mmModel.Permission) bool {
+	return true
+}
+func (allowAllService) HasPermissionToChannel(string, string, *mmModel.Permission) bool {
+	return true
+}
+func (allowAllService) HasPermissionToBoard(string, string, *mmModel.Permission) bool {
+	return true
+}
+
+func TestScopedService(t *testing.T) {
+	t.Run("no scopes don't restrict anything", func(t *testing.T) {
+		service := NewScopedService(allowAllService{}, nil)
+		assert.True(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionDeleteBoard))
+		assert.True(t, service.HasPermissionTo("user-id", model.PermissionManageSystem))
+	})
+
+	t.Run("read-only scope", func(t *testing.T) {
+		service := NewScopedService(allowAllService{}, []string{model.AccessTokenScopeReadOnly})
+		assert.True(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionViewBoard))
+		assert.True(t, service.HasPermissionToTeam("user-id", "team-id", model.PermissionViewTeam))
+		assert.False(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionManageBoardCards))
+		assert.False(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionCommentBoardCards))
+		assert.False(t, service.HasPermissionTo("user-id", model.PermissionManageSystem))
+	})
+
+	t.Run("cards:write scope", func(t *testing.T) {
+		service := NewScopedService(allowAllService{}, []string{model.AccessTokenScopeCardsWrite})
+		assert.True(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionViewBoard))
+		assert.True(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionManageBoardCards))
+		assert.False(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionManageBoardProperties))
+		assert.False(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionDeleteBoard))
+		assert.False(t, service.HasPermissionToTeam("user-id", "team-id", model.PermissionCreatePublicChannel))
+	})
+
+	t.Run("boards:admin scope", func(t *testing.T) {
+		service := NewScopedService(allowAllService{}, []string{model.AccessTokenScopeBoardsAdmin})
+		assert.True(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionDeleteBoard))
+		assert.True(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionManageBoardRoles))
+		assert.True(t, service.HasPermissionToTeam("user-id", "team-id", model.PermissionCreatePublicChannel))
+		assert.True(t, service.HasPermissionToTeam("user-id", "team-id", model.PermissionCreatePrivateChannel))
+		assert.False(t, service.HasPermissionTo("user-id", model.PermissionManageSystem))
+		assert.False(t, service.HasPermissionToTeam("user-id", "team-id", model.PermissionManageTeam))
+	})
+
+	t.Run("scopes never grant permissions the user doesn't have", func(t *testing.T) {
+		service := NewScopedService(NewScopedService(allowAllService{}, []string{model.AccessTokenScopeReadOnly}), []string{model.AccessTokenScopeBoardsAdmin})
+		assert.False(t, service.HasPermissionToBoard("user-id", "board-id", model.PermissionDeleteBoard))
+	})
+}
+
+func TestScopesAllowWrite(t *testing.T) {
+	assert.True(t, ScopesAllowWrite(nil))
+	assert.False(t, ScopesAllowWrite([]string{model.AccessTokenScopeReadOnly}))
+	assert.True(t, ScopesAllowWrite([]string{model.AccessTokenScopeReadOnly, model.AccessTokenScopeCardsWrite}))
+}
+
+func TestScopesAllowAny(t *testing.T) {
+	assert.True(t, ScopesAllowAny(nil, model.AccessTokenScopeBoardsAdmin))
+	assert.True(t, ScopesAllowAny(nil))
+	assert.False(t, ScopesAllowAny([]string{model.AccessTokenScopeCardsWrite}, model.AccessTokenScopeBoardsAdmin))
+	assert.True(t, ScopesAllowAny([]string{model.AccessTokenScopeReadOnly, model.AccessTokenScopeBoardsAdmin}, model.AccessTokenScopeBoardsAdmin))
+	assert.False(t, ScopesAllowAny([]string{model.AccessTokenScopeBoardsAdmin}))
+}
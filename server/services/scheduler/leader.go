@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// DefaultLeaseDuration is the time after which the leadership of a node
+// that stopped renewing its lease is taken by another node.
+const DefaultLeaseDuration = 30 * time.Second
+
+// Leader tells whether the node runs the cluster singleton tasks.
+type Leader interface {
+	IsLeader() bool
+}
+
+// LeaseStore keeps the leases shared by the nodes of a cluster.
+type LeaseStore interface {
+	AcquireLease(name, holderID string, expireAt int64) (bool, error)
+	ReleaseLease(name, holderID string) error
+}
+
+// LeaderElector elects a single node of a cluster as the leader, by
+// holding a lease in the database. The leader renews its lease several
+// times before it expires, and the other nodes try to take it.
+type LeaderElector struct {
+	name     string
+	holderID string
+	duration time.Duration
+	store    LeaseStore
+	logger   mlog.LoggerIFace
+
+	mu     sync.Mutex
+	leader bool
+	task   *ScheduledTask
+}
+
+// NewLeaderElector creates an elector for the named lease.
+func NewLeaderElector(store LeaseStore, name string, duration time.Duration, logger mlog.LoggerIFace) *LeaderElector {
+	return &LeaderElector{
+		name:     name,
+		holderID: utils.NewID(utils.IDTypeNone),
+		duration: duration,
+		store:    store,
+		logger:   logger,
+	}
+}
+
+// Start tries to take the lease, and keeps trying or renewing it until
+// the elector is shut down.
+func (le *LeaderElector) Start() {
+	le.renew()
+
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	le.task = CreateRecurringTask("leaderElection", le.renew, le.duration/3)
+}
+
+// IsLeader returns true if the node holds the lease.
+func (le *LeaderElector) IsLeader() bool {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	return le.leader
+}
+
+// Shutdown stops renewing the lease and releases it, so that another
+// node can take the leadership right away.
+func (le *LeaderElector) Shutdown() {
+	le.mu.Lock()
+	task := le.task
+	le.task = nil
+	le.mu.Unlock()
+
+	if task != nil {
+		task.Cancel()
+	}
+
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	if le.leader {
+		if err := le.store.ReleaseLease(le.name, le.holderID); err != nil {
+			le.logger.Warn("Unable to release the leader lease", mlog.String("lease", le.name), mlog.Err(err))
+		}
+		le.leader = false
+	}
+}
+
+func (le *LeaderElector) renew() {
+	acquired, err := le.store.AcquireLease(le.name, le.holderID, utils.GetMillis()+le.duration.Milliseconds())
+	if err != nil {
+		// the lease can't be renewed, so another node may take it
+		le.logger.Error("Unable to acquire the leader lease", mlog.String("lease", le.name), mlog.Err(err))
+		acquired = false
+	}
+
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	if acquired != le.leader {
+		le.logger.Info("Leadership changed",
+			mlog.String("lease", le.name),
+			mlog.String("holder_id", le.holderID),
+			mlog.Bool("leader", acquired),
+		)
+	}
+	le.leader = acquired
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+type lease struct {
+	holderID string
+	expireAt int64
+}
+
+// memoryLeaseStore keeps the leases in memory, and fails while broken.
+type memoryLeaseStore struct {
+	mu     sync.Mutex
+	leases map[string]lease
+	broken bool
+}
+
+func newMemoryLeaseStore() *memoryLeaseStore {
+	return &memoryLeaseStore{leases: map[string]lease{}}
+}
+
+func (s *memoryLeaseStore) AcquireLease(name, holderID string, expireAt int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken {
+		return false, errors.New("broken")
+	}
+	current, ok := s.leases[name]
+	if ok && current.holderID != holderID && current.expireAt >= utils.GetMillis() {
+		return false, nil
+	}
+	s.leases[name] = lease{holderID: holderID, expireAt: expireAt}
+	return true, nil
+}
+
+func (s *memoryLeaseStore) ReleaseLease(name, holderID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.leases[name].holderID == holderID {
+		delete(s.leases, name)
+	}
+	return nil
+}
+
+func (s *memoryLeaseStore) setBroken(broken bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.broken = broken
+}
+
+func TestLeaderElector(t *testing.T) {
+	logger := mlog.CreateConsoleTestLogger(t)
+	store := newMemoryLeaseStore()
+
+	node1 := NewLeaderElector(store, "test", time.Hour, logger)
+	node2 := NewLeaderElector(store, "test", time.Hour, logger)
+
+	node1.Start()
+	node2.Start()
+	require.True(t, node1.IsLeader())
+	require.False(t, node2.IsLeader())
+
+	t.Run("the leader loses the leadership if it can't renew its lease", func(t *testing.T) {
+		store.setBroken(true)
+		node1.renew()
+		require.False(t, node1.IsLeader())
+
+		store.setBroken(false)
+		node1.renew()
+		require.True(t, node1.IsLeader())
+	})
+
+	t.Run("another node takes the leadership once released", func(t *testing.T) {
+		node1.Shutdown()
+		require.False(t, node1.IsLeader())
+
+		node2.renew()
+		require.True(t, node2.IsLeader())
+	})
+
+	node2.Shutdown()
+	require.Empty(t, store.leases)
+}
@@ -115,6 +115,10 @@ func (s *MattermostAuthLayer) GetUserByUsername(username string) (*model.User, e
 	return &user, nil
 }
 
+func (s *MattermostAuthLayer) GetUserByAuthData(authService, authData string) (*model.User, error) {
+	return nil, store.NewNotSupportedError("users are authenticated by mattermost")
+}
+
 func (s *MattermostAuthLayer) CreateUser(user *model.User) (*model.User, error) {
 	return nil, store.NewNotSupportedError("no user creation allowed from focalboard, create it using mattermost")
 }
@@ -131,6 +135,22 @@ func (s *MattermostAuthLayer) UpdateUserPasswordByID(userID, password string) er
 	return store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
 }
 
+func (s *MattermostAuthLayer) QueryUsers(opts model.QueryUsersOptions) ([]*model.User, int, error) {
+	return nil, 0, store.NewNotSupportedError("users are provisioned in mattermost")
+}
+
+func (s *MattermostAuthLayer) GetPasswordHistory(userID string, limit int) ([]string, error) {
+	return nil, store.NewNotSupportedError("passwords are managed by mattermost")
+}
+
+func (s *MattermostAuthLayer) AddPasswordHistory(userID, passwordHash string, keep int) error {
+	return store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
+}
+
+func (s *MattermostAuthLayer) UpdateUserMfa(userID, mfaSecret string, mfaActive bool) error {
+	return store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
+}
+
 func (s *MattermostAuthLayer) PatchUserPreferences(userID string, patch model.UserPreferencesPatch) (mmModel.Preferences, error) {
 	preferences, err := s.GetUserPreferences(userID)
 	if err != nil {
@@ -256,6 +276,14 @@ func (s *MattermostAuthLayer) DeleteSession(sessionID string) error {
 	return store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
 }
 
+func (s *MattermostAuthLayer) GetUserSessions(userID string, expireTime int64) ([]*model.Session, error) {
+	return nil, store.NewNotSupportedError("sessions not used when using mattermost")
+}
+
+func (s *MattermostAuthLayer) DeleteUserSessions(userID, exceptSessionID string) error {
+	return store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
+}
+
 func (s *MattermostAuthLayer) CleanUpSessions(expireTime int64) error {
 	return store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
 }
@@ -289,6 +317,50 @@ func (s *MattermostAuthLayer) GetTeam(id string) (*model.Team, error) {
 	return &model.Team{ID: id, Title: displayName}, nil
 }
 
+func (s *MattermostAuthLayer) CreateTeam(team *model.Team) (*model.Team, error) {
+	return nil, store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
+}
+
+func (s *MattermostAuthLayer) UpdateTeam(team *model.Team) (*model.Team, error) {
+	return nil, store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
+}
+
+func (s *MattermostAuthLayer) DeleteTeam(teamID string) error {
+	return store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
+}
+
+func (s *MattermostAuthLayer) GetTeamMembers(teamID string) ([]*model.TeamMember, error) {
+	return nil, store.NewNotSupportedError("team members are managed by mattermost")
+}
+
+func (s *MattermostAuthLayer) GetTeamMembersForUser(userID string) ([]*model.TeamMember, error) {
+	return nil, store.NewNotSupportedError("team members are managed by mattermost")
+}
+
+func (s *MattermostAuthLayer) GetTeamMember(teamID, userID string) (*model.TeamMember, error) {
+	return nil, store.NewNotSupportedError("team members are managed by mattermost")
+}
+
+func (s *MattermostAuthLayer) SaveTeamMember(member *model.TeamMember) (*model.TeamMember, error) {
+	return nil, store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
+}
+
+func (s *MattermostAuthLayer) DeleteTeamMember(teamID, userID string) error {
+	return store.NewNotSupportedError("no update allowed from focalboard, update it using mattermost")
+}
+
+func (s *MattermostAuthLayer) SaveTeamInvite(invite *model.TeamInvite) error {
+	return store.NewNotSupportedError("team invites are managed by mattermost")
+}
+
+func (s *MattermostAuthLayer) GetTeamInvite(tokenHash string) (*model.TeamInvite, error) {
+	return nil, store.NewNotSupportedError("team invites are managed by mattermost")
+}
+
+func (s *MattermostAuthLayer) UseTeamInvite(tokenHash string) (*model.TeamInvite, error) {
+	return nil, store.NewNotSupportedError("team invites are managed by mattermost")
+}
+
 // GetTeamsForUser retrieves all the teams that the user is a member of.
 func (s *MattermostAuthLayer) GetTeamsForUser(userID string) ([]*model.Team, error) {
 	query := s.getQueryBuilder().
@@ -492,22 +564,24 @@ func mmUserToFbUser(mmUser *mmModel.User) model.User {
 		authData = *mmUser.AuthData
 	}
 	return model.User{
-		ID:          mmUser.Id,
-		Username:    mmUser.Username,
-		Email:       mmUser.Email,
-		Password:    mmUser.Password,
-		Nickname:    mmUser.Nickname,
-		FirstName:   mmUser.FirstName,
-		LastName:    mmUser.LastName,
-		MfaSecret:   mmUser.MfaSecret,
-		AuthService: mmUser.AuthService,
-		AuthData:    authData,
-		CreateAt:    mmUser.CreateAt,
-		UpdateAt:    mmUser.UpdateAt,
-		DeleteAt:    mmUser.DeleteAt,
-		IsBot:       mmUser.IsBot,
-		IsGuest:     mmUser.IsGuest(),
-		Roles:       mmUser.Roles,
+		ID:            mmUser.Id,
+		Username:      mmUser.Username,
+		Email:         mmUser.Email,
+		EmailVerified: mmUser.EmailVerified,
+		Password:      mmUser.Password,
+		Nickname:      mmUser.Nickname,
+		FirstName:     mmUser.FirstName,
+		LastName:      mmUser.LastName,
+		MfaSecret:     mmUser.MfaSecret,
+		MfaActive:     mmUser.MfaActive,
+		AuthService:   mmUser.AuthService,
+		AuthData:      authData,
+		CreateAt:      mmUser.CreateAt,
+		UpdateAt:      mmUser.UpdateAt,
+		DeleteAt:      mmUser.DeleteAt,
+		IsBot:         mmUser.IsBot,
+		IsGuest:       mmUser.IsGuest(),
+		Roles:         mmUser.Roles,
 	}
 }
 
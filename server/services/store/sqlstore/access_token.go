@@ -0,0 +1,173 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func accessTokenFields() []string {
+	return []string{
+		"id",
+		"user_id",
+		"token_hash",
+		"description",
+		"scopes",
+		"expires_at",
+		"last_used_at",
+		"create_at",
+		"delete_at",
+	}
+}
+
+func (s *SQLStore) accessTokensFromRows(rows *sql.Rows) ([]*model.AccessToken, error) {
+	tokens := []*model.AccessToken{}
+
+	for rows.Next() {
+		var token model.AccessToken
+		var scopesBytes []byte
+
+		err := rows.Scan(
+			&token.ID,
+			&token.UserID,
+			&token.TokenHash,
+			&token.Description,
+			&scopesBytes,
+			&token.ExpiresAt,
+			&token.LastUsedAt,
+			&token.CreateAt,
+			&token.DeleteAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		token.Scopes = []string{}
+		if len(scopesBytes) > 0 {
+			if err = json.Unmarshal(scopesBytes, &token.Scopes); err != nil {
+				return nil, err
+			}
+		}
+
+		tokens = append(tokens, &token)
+	}
+
+	return tokens, nil
+}
+
+func (s *SQLStore) getAccessTokensByCondition(db sq.BaseRunner, condition interface{}) ([]*model.AccessToken, error) {
+	query := s.getQueryBuilder(db).
+		Select(accessTokenFields()...).
+		From(s.tablePrefix + "access_tokens").
+		Where(sq.Eq{"delete_at": 0}).
+		Where(condition).
+		OrderBy("create_at")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error(`getAccessTokensByCondition ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.accessTokensFromRows(rows)
+}
+
+func (s *SQLStore) createAccessToken(db sq.BaseRunner, token *model.AccessToken) error {
+	if token.Scopes == nil {
+		token.Scopes = []string{}
+	}
+
+	scopesBytes, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return err
+	}
+
+	token.CreateAt = utils.GetMillis()
+	token.DeleteAt = 0
+
+	query := s.getQueryBuilder(db).Insert(s.tablePrefix+"access_tokens").
+		Columns(accessTokenFields()...).
+		Values(
+			token.ID,
+			token.UserID,
+			token.TokenHash,
+			token.Description,
+			scopesBytes,
+			token.ExpiresAt,
+			token.LastUsedAt,
+			token.CreateAt,
+			token.DeleteAt,
+		)
+
+	_, err = query.Exec()
+	return err
+}
+
+func (s *SQLStore) getAccessToken(db sq.BaseRunner, tokenID string) (*model.AccessToken, error) {
+	tokens, err := s.getAccessTokensByCondition(db, sq.Eq{"id": tokenID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, model.NewErrNotFound("access token ID=" + tokenID)
+	}
+
+	return tokens[0], nil
+}
+
+func (s *SQLStore) getAccessTokenByHash(db sq.BaseRunner, tokenHash string) (*model.AccessToken, error) {
+	tokens, err := s.getAccessTokensByCondition(db, sq.Eq{"token_hash": tokenHash})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, model.NewErrNotFound("access token")
+	}
+
+	return tokens[0], nil
+}
+
+func (s *SQLStore) getAccessTokensForUser(db sq.BaseRunner, userID string) ([]*model.AccessToken, error) {
+	return s.getAccessTokensByCondition(db, sq.Eq{"user_id": userID})
+}
+
+func (s *SQLStore) updateAccessTokenLastUsed(db sq.BaseRunner, tokenID string, lastUsedAt int64) error {
+	query := s.getQueryBuilder(db).Update(s.tablePrefix+"access_tokens").
+		Set("last_used_at", lastUsedAt).
+		Where(sq.Eq{"id": tokenID})
+
+	_, err := query.Exec()
+	return err
+}
+
+func (s *SQLStore) deleteAccessToken(db sq.BaseRunner, tokenID string) error {
+	query := s.getQueryBuilder(db).Update(s.tablePrefix+"access_tokens").
+		Set("delete_at", utils.GetMillis()).
+		Where(sq.Eq{"id": tokenID}).
+		Where(sq.Eq{"delete_at": 0})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowCount < 1 {
+		return model.NewErrNotFound("access token ID=" + tokenID)
+	}
+
+	return nil
+}
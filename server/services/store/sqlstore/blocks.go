@@ -228,6 +228,13 @@ func (s *SQLStore) blocksFromRows(rows *sql.Rows) ([]*model.Block, error) {
 }
 
 func (s *SQLStore) insertBlock(db sq.BaseRunner, block *model.Block, userID string) error {
+	return s.saveBlock(db, block, userID, nil)
+}
+
+// saveBlock inserts or updates a block. If expectedUpdateAt is set, an
+// existing block is only updated if it wasn't modified since, and an
+// ErrVersionConflict is returned otherwise.
+func (s *SQLStore) saveBlock(db sq.BaseRunner, block *model.Block, userID string, expectedUpdateAt *int64) error {
 	if err := block.IsValid(); err != nil {
 		return fmt.Errorf("error validating block %s: %w", block.ID, err)
 	}
@@ -242,7 +249,16 @@ func (s *SQLStore) insertBlock(db sq.BaseRunner, block *model.Block, userID stri
 		return err
 	}
 
+	if existingBlock != nil && expectedUpdateAt != nil && existingBlock.UpdateAt != *expectedUpdateAt {
+		return model.NewErrVersionConflict(block.ID, existingBlock.UpdateAt)
+	}
+
 	block.UpdateAt = utils.GetMillis()
+	if existingBlock != nil && block.UpdateAt <= existingBlock.UpdateAt {
+		// the update time is the version of the block, so it has to
+		// change with each update
+		block.UpdateAt = existingBlock.UpdateAt + 1
+	}
 	block.ModifiedBy = userID
 
 	insertQuery := s.getQueryBuilder(db).Insert("").
@@ -292,11 +308,30 @@ func (s *SQLStore) insertBlock(db sq.BaseRunner, block *model.Block, userID stri
 			Set("update_at", block.UpdateAt).
 			Set("delete_at", block.DeleteAt)
 
-		if _, err := query.Exec(); err != nil {
+		if expectedUpdateAt != nil {
+			query = query.Where(sq.Eq{"update_at": *expectedUpdateAt})
+		}
+
+		var result sql.Result
+		if result, err = query.Exec(); err != nil {
 			s.logger.Error(`InsertBlock error occurred while updating existing block`, mlog.String("blockID", block.ID), mlog.Err(err))
 
 			return err
 		}
+
+		if expectedUpdateAt != nil {
+			// the block was modified after it was read
+			var count int64
+			if count, err = result.RowsAffected(); err != nil {
+				return err
+			}
+			if count == 0 {
+				if existingBlock, err = s.getBlock(db, block.ID); err != nil {
+					return err
+				}
+				return model.NewErrVersionConflict(block.ID, existingBlock.UpdateAt)
+			}
+		}
 	} else {
 		block.CreatedBy = userID
 		query := insertQuery.SetMap(insertQueryValues).Into(s.tablePrefix + "blocks")
@@ -321,7 +356,7 @@ func (s *SQLStore) patchBlock(db sq.BaseRunner, blockID string, blockPatch *mode
 	}
 
 	block := blockPatch.Patch(existingBlock)
-	return s.insertBlock(db, block, userID)
+	return s.saveBlock(db, block, userID, blockPatch.ExpectedUpdateAt)
 }
 
 func (s *SQLStore) patchBlocks(db sq.BaseRunner, blockPatches *model.BlockPatchBatch, userID string) error {
@@ -78,6 +78,7 @@ var boardMemberFields = []string{
 	"BM.scheme_editor",
 	"BM.scheme_commenter",
 	"BM.scheme_viewer",
+	"BM.custom_role_id",
 }
 
 func (s *SQLStore) boardsFromRows(rows *sql.Rows) ([]*model.Board, error) {
@@ -145,6 +146,7 @@ func (s *SQLStore) boardMembersFromRows(rows *sql.Rows) ([]*model.BoardMember, e
 			&boardMember.SchemeEditor,
 			&boardMember.SchemeCommenter,
 			&boardMember.SchemeViewer,
+			&boardMember.CustomRoleID,
 		)
 		if err != nil {
 			return nil, err
@@ -295,6 +297,13 @@ func (s *SQLStore) getBoardsInTeamByIds(db sq.BaseRunner, boardIDs []string, tea
 }
 
 func (s *SQLStore) insertBoard(db sq.BaseRunner, board *model.Board, userID string) (*model.Board, error) {
+	return s.saveBoard(db, board, userID, nil)
+}
+
+// saveBoard inserts or updates a board. If expectedUpdateAt is set, an
+// existing board is only updated if it wasn't modified since, and an
+// ErrVersionConflict is returned otherwise.
+func (s *SQLStore) saveBoard(db sq.BaseRunner, board *model.Board, userID string, expectedUpdateAt *int64) (*model.Board, error) {
 	// Generate tracking IDs for in-built templates
 	if board.IsTemplate && board.TeamID == model.GlobalTeamID {
 		//nolint:gosec
@@ -332,9 +341,18 @@ func (s *SQLStore) insertBoard(db sq.BaseRunner, board *model.Board, userID stri
 	insertQuery := s.getQueryBuilder(db).Insert("").
 		Columns(boardFields("")...)
 
+	if existingBoard != nil && expectedUpdateAt != nil && existingBoard.UpdateAt != *expectedUpdateAt {
+		return nil, model.NewErrVersionConflict(board.ID, existingBoard.UpdateAt)
+	}
+
 	now := utils.GetMillis()
 	board.ModifiedBy = userID
 	board.UpdateAt = now
+	if existingBoard != nil && board.UpdateAt <= existingBoard.UpdateAt {
+		// the update time is the version of the board, so it has to
+		// change with each update
+		board.UpdateAt = existingBoard.UpdateAt + 1
+	}
 
 	insertQueryValues := map[string]interface{}{
 		"id":               board.ID,
@@ -375,10 +393,29 @@ func (s *SQLStore) insertBoard(db sq.BaseRunner, board *model.Board, userID stri
 			Set("update_at", board.UpdateAt).
 			Set("delete_at", board.DeleteAt)
 
-		if _, err := query.Exec(); err != nil {
+		if expectedUpdateAt != nil {
+			query = query.Where(sq.Eq{"update_at": *expectedUpdateAt})
+		}
+
+		var result sql.Result
+		if result, err = query.Exec(); err != nil {
 			s.logger.Error(`InsertBoard error occurred while updating existing board`, mlog.String("boardID", board.ID), mlog.Err(err))
 			return nil, fmt.Errorf("insertBoard error occurred while updating existing board %s: %w", board.ID, err)
 		}
+
+		if expectedUpdateAt != nil {
+			// the board was modified after it was read
+			var count int64
+			if count, err = result.RowsAffected(); err != nil {
+				return nil, err
+			}
+			if count == 0 {
+				if existingBoard, err = s.getBoard(db, board.ID); err != nil {
+					return nil, err
+				}
+				return nil, model.NewErrVersionConflict(board.ID, existingBoard.UpdateAt)
+			}
+		}
 	} else {
 		board.CreatedBy = userID
 		board.CreateAt = now
@@ -408,7 +445,7 @@ func (s *SQLStore) patchBoard(db sq.BaseRunner, boardID string, boardPatch *mode
 	}
 
 	board := boardPatch.Patch(existingBoard)
-	return s.insertBoard(db, board, userID)
+	return s.saveBoard(db, board, userID, boardPatch.ExpectedUpdateAt)
 }
 
 func (s *SQLStore) deleteBoard(db sq.BaseRunner, boardID, userID string) error {
@@ -503,11 +540,12 @@ func (s *SQLStore) saveMember(db sq.BaseRunner, bm *model.BoardMember) (*model.B
 	queryValues := map[string]interface{}{
 		"board_id":         bm.BoardID,
 		"user_id":          bm.UserID,
-		"roles":            "",
+		"roles":            bm.Roles,
 		"scheme_admin":     bm.SchemeAdmin,
 		"scheme_editor":    bm.SchemeEditor,
 		"scheme_commenter": bm.SchemeCommenter,
 		"scheme_viewer":    bm.SchemeViewer,
+		"custom_role_id":   bm.CustomRoleID,
 	}
 
 	oldMember, err := s.getMemberForBoard(db, bm.BoardID, bm.UserID)
@@ -521,13 +559,14 @@ func (s *SQLStore) saveMember(db sq.BaseRunner, bm *model.BoardMember) (*model.B
 
 	if s.dbType == model.MysqlDBType {
 		query = query.Suffix(
-			"ON DUPLICATE KEY UPDATE scheme_admin = ?, scheme_editor = ?, scheme_commenter = ?, scheme_viewer = ?",
-			bm.SchemeAdmin, bm.SchemeEditor, bm.SchemeCommenter, bm.SchemeViewer)
+			"ON DUPLICATE KEY UPDATE roles = ?, scheme_admin = ?, scheme_editor = ?, scheme_commenter = ?, scheme_viewer = ?, custom_role_id = ?",
+			bm.Roles, bm.SchemeAdmin, bm.SchemeEditor, bm.SchemeCommenter, bm.SchemeViewer, bm.CustomRoleID)
 	} else {
 		query = query.Suffix(
 			`ON CONFLICT (board_id, user_id)
-             DO UPDATE SET scheme_admin = EXCLUDED.scheme_admin, scheme_editor = EXCLUDED.scheme_editor,
-			   scheme_commenter = EXCLUDED.scheme_commenter, scheme_viewer = EXCLUDED.scheme_viewer`,
+             DO UPDATE SET roles = EXCLUDED.roles, scheme_admin = EXCLUDED.scheme_admin, scheme_editor = EXCLUDED.scheme_editor,
+			   scheme_commenter = EXCLUDED.scheme_commenter, scheme_viewer = EXCLUDED.scheme_viewer,
+			   custom_role_id = EXCLUDED.custom_role_id`,
 		)
 	}
 
@@ -549,6 +588,17 @@ func (s *SQLStore) saveMember(db sq.BaseRunner, bm *model.BoardMember) (*model.B
 	return bm, nil
 }
 
+// saveMembersAndPatchBlocks saves the board members and patches the
+// blocks together, so that a failure leaves both of them untouched.
+func (s *SQLStore) saveMembersAndPatchBlocks(db sq.BaseRunner, members []*model.BoardMember, blockPatches *model.BlockPatchBatch, userID string) error {
+	for _, member := range members {
+		if _, err := s.saveMember(db, member); err != nil {
+			return err
+		}
+	}
+	return s.patchBlocks(db, blockPatches, userID)
+}
+
 func (s *SQLStore) deleteMember(db sq.BaseRunner, boardID, userID string) error {
 	deleteQuery := s.getQueryBuilder(db).
 		Delete(s.tablePrefix + "board_members").
@@ -0,0 +1,138 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+var customBoardRoleFields = []string{
+	"id",
+	"board_id",
+	"name",
+	"permissions",
+	"created_by",
+	"create_at",
+	"update_at",
+}
+
+func (s *SQLStore) customBoardRolesFromRows(rows *sql.Rows) ([]*model.CustomBoardRole, error) {
+	roles := []*model.CustomBoardRole{}
+
+	for rows.Next() {
+		var role model.CustomBoardRole
+		var permissions string
+
+		err := rows.Scan(
+			&role.ID,
+			&role.BoardID,
+			&role.Name,
+			&permissions,
+			&role.CreatedBy,
+			&role.CreateAt,
+			&role.UpdateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		role.Permissions = strings.Fields(permissions)
+
+		roles = append(roles, &role)
+	}
+
+	return roles, nil
+}
+
+func (s *SQLStore) getCustomBoardRolesByCondition(db sq.BaseRunner, condition sq.Eq) ([]*model.CustomBoardRole, error) {
+	query := s.getQueryBuilder(db).
+		Select(customBoardRoleFields...).
+		From(s.tablePrefix+"board_roles").
+		Where(condition).
+		OrderBy("name", "id")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR getCustomBoardRolesByCondition", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.customBoardRolesFromRows(rows)
+}
+
+func (s *SQLStore) getCustomBoardRoles(db sq.BaseRunner, boardID string) ([]*model.CustomBoardRole, error) {
+	return s.getCustomBoardRolesByCondition(db, sq.Eq{"board_id": boardID})
+}
+
+func (s *SQLStore) getCustomBoardRole(db sq.BaseRunner, roleID string) (*model.CustomBoardRole, error) {
+	roles, err := s.getCustomBoardRolesByCondition(db, sq.Eq{"id": roleID})
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, model.NewErrNotFound("board role ID=" + roleID)
+	}
+	return roles[0], nil
+}
+
+// saveCustomBoardRole creates a role, or updates the name and the
+// permissions of an existing one.
+func (s *SQLStore) saveCustomBoardRole(db sq.BaseRunner, role *model.CustomBoardRole) (*model.CustomBoardRole, error) {
+	now := utils.GetMillis()
+	if role.CreateAt == 0 {
+		role.CreateAt = now
+	}
+	role.UpdateAt = now
+	permissions := strings.Join(role.Permissions, " ")
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"board_roles").
+		Columns(customBoardRoleFields...).
+		Values(role.ID, role.BoardID, role.Name, permissions, role.CreatedBy, role.CreateAt, role.UpdateAt)
+	if s.dbType == model.MysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE name = ?, permissions = ?, update_at = ?", role.Name, permissions, role.UpdateAt)
+	} else {
+		query = query.Suffix("ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, permissions = EXCLUDED.permissions, update_at = EXCLUDED.update_at")
+	}
+
+	if _, err := query.Exec(); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// deleteCustomBoardRole deletes a role and unassigns it from the board
+// members that had it.
+func (s *SQLStore) deleteCustomBoardRole(db sq.BaseRunner, roleID string) error {
+	updateMembers := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"board_members").
+		Set("custom_role_id", "").
+		Where(sq.Eq{"custom_role_id": roleID})
+	if _, err := updateMembers.Exec(); err != nil {
+		return err
+	}
+
+	deleteRole := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "board_roles").
+		Where(sq.Eq{"id": roleID})
+
+	result, err := deleteRole.Exec()
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowCount < 1 {
+		return model.NewErrNotFound("board role ID=" + roleID)
+	}
+	return nil
+}
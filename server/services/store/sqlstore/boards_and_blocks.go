@@ -131,6 +131,9 @@ func (s *SQLStore) deleteBoardsAndBlocks(db sq.BaseRunner, dbab *model.DeleteBoa
 	return nil
 }
 
+// duplicateBoard copies a board and the blocks of it that the user can
+// see. The user becomes the admin of the copy, so the private cards and
+// the property values hidden to them aren't copied.
 func (s *SQLStore) duplicateBoard(db sq.BaseRunner, boardID string, userID string, toTeam string, asTemplate bool) (*model.BoardsAndBlocks, []*model.BoardMember, error) {
 	bab := &model.BoardsAndBlocks{
 		Boards: []*model.Board{},
@@ -142,6 +145,15 @@ func (s *SQLStore) duplicateBoard(db sq.BaseRunner, boardID string, userID strin
 		return nil, nil, err
 	}
 
+	member, err := s.getMemberForBoard(db, boardID, userID)
+	if err != nil && !model.IsErrNotFound(err) {
+		return nil, nil, err
+	}
+	restrictions := model.GetPropertyRestrictions(nil, nil)
+	if userID != model.SystemUserID {
+		restrictions = model.GetPropertyRestrictions(board, member)
+	}
+
 	// todo: server localization
 	if asTemplate == board.IsTemplate {
 		// board -> board or template -> template
@@ -166,11 +178,18 @@ func (s *SQLStore) duplicateBoard(db sq.BaseRunner, boardID string, userID strin
 	if err != nil {
 		return nil, nil, err
 	}
+	hiddenCards := map[string]bool{}
+	for _, b := range blocks {
+		if b.Type == model.TypeCard && !model.CanViewCard(b, userID, member) {
+			hiddenCards[b.ID] = true
+		}
+	}
 	newBlocks := []*model.Block{}
 	for _, b := range blocks {
-		if b.Type != model.TypeComment {
-			newBlocks = append(newBlocks, b)
+		if b.Type == model.TypeComment || hiddenCards[b.ID] || hiddenCards[model.CardParentID(b)] {
+			continue
 		}
+		newBlocks = append(newBlocks, restrictions.FilterBlock(b))
 	}
 	bab.Blocks = newBlocks
 
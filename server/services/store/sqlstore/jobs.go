@@ -0,0 +1,209 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"unicode/utf8"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// maxJobErrorLength is the size of the error column of the jobs.
+const maxJobErrorLength = 1024
+
+func jobFields() []string {
+	return []string{
+		"id",
+		"type",
+		"status",
+		"attempt",
+		"created_by",
+		"error",
+		"run_at",
+		"start_at",
+		"finish_at",
+		"create_at",
+		"update_at",
+	}
+}
+
+func (s *SQLStore) jobsFromRows(rows *sql.Rows) ([]*model.Job, error) {
+	jobs := []*model.Job{}
+	for rows.Next() {
+		var job model.Job
+		err := rows.Scan(
+			&job.ID,
+			&job.Type,
+			&job.Status,
+			&job.Attempt,
+			&job.CreatedBy,
+			&job.Error,
+			&job.RunAt,
+			&job.StartAt,
+			&job.FinishAt,
+			&job.CreateAt,
+			&job.UpdateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// truncateJobError cuts an error to the size of its column, without
+// splitting a multi-byte character.
+func truncateJobError(message string) string {
+	if len(message) <= maxJobErrorLength {
+		return message
+	}
+	n := maxJobErrorLength
+	for n > 0 && !utf8.RuneStart(message[n]) {
+		n--
+	}
+	return message[:n]
+}
+
+func (s *SQLStore) saveJob(db sq.BaseRunner, job *model.Job) error {
+	now := utils.GetMillis()
+	if job.CreateAt == 0 {
+		job.CreateAt = now
+	}
+	job.UpdateAt = now
+	job.Error = truncateJobError(job.Error)
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"jobs").
+		Columns(jobFields()...).
+		Values(
+			job.ID,
+			job.Type,
+			job.Status,
+			job.Attempt,
+			job.CreatedBy,
+			job.Error,
+			job.RunAt,
+			job.StartAt,
+			job.FinishAt,
+			job.CreateAt,
+			job.UpdateAt,
+		)
+
+	_, err := query.Exec()
+	return err
+}
+
+func (s *SQLStore) getJob(db sq.BaseRunner, id string) (*model.Job, error) {
+	query := s.getQueryBuilder(db).
+		Select(jobFields()...).
+		From(s.tablePrefix + "jobs").
+		Where(sq.Eq{"id": id})
+
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	jobs, err := s.jobsFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, model.NewErrNotFound("job ID=" + id)
+	}
+	return jobs[0], nil
+}
+
+// getJobs returns the jobs matching the options, the most recent first.
+func (s *SQLStore) getJobs(db sq.BaseRunner, opts model.QueryJobsOptions) ([]*model.Job, error) {
+	query := s.getQueryBuilder(db).
+		Select(jobFields()...).
+		From(s.tablePrefix+"jobs").
+		OrderBy("run_at DESC", "create_at DESC", "id")
+
+	if opts.Type != "" {
+		query = query.Where(sq.Eq{"type": opts.Type})
+	}
+	if len(opts.Statuses) > 0 {
+		query = query.Where(sq.Eq{"status": opts.Statuses})
+	}
+	if opts.Page != 0 {
+		query = query.Offset(uint64(opts.Page * opts.PerPage))
+	}
+	if opts.PerPage > 0 {
+		query = query.Limit(uint64(opts.PerPage))
+	}
+
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.jobsFromRows(rows)
+}
+
+// getDueJobs returns the pending jobs due before runBefore, the oldest
+// first.
+func (s *SQLStore) getDueJobs(db sq.BaseRunner, runBefore int64) ([]*model.Job, error) {
+	query := s.getQueryBuilder(db).
+		Select(jobFields()...).
+		From(s.tablePrefix+"jobs").
+		Where(sq.Eq{"status": model.JobStatusPending}).
+		Where(sq.LtOrEq{"run_at": runBefore}).
+		OrderBy("run_at", "create_at", "id")
+
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.jobsFromRows(rows)
+}
+
+// updateJobStatus saves the status of a job if it still has the
+// previous status, so that a job can only be claimed or finished once.
+// It returns false if the status of the job changed meanwhile.
+func (s *SQLStore) updateJobStatus(db sq.BaseRunner, job *model.Job, previousStatus string) (bool, error) {
+	job.UpdateAt = utils.GetMillis()
+	job.Error = truncateJobError(job.Error)
+
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"jobs").
+		Set("status", job.Status).
+		Set("error", job.Error).
+		Set("start_at", job.StartAt).
+		Set("finish_at", job.FinishAt).
+		Set("update_at", job.UpdateAt).
+		Where(sq.Eq{"id": job.ID}).
+		Where(sq.Eq{"status": previousStatus})
+
+	result, err := query.Exec()
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// cleanUpJobs deletes the jobs that finished before finishedBefore.
+func (s *SQLStore) cleanUpJobs(db sq.BaseRunner, finishedBefore int64) (int64, error) {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "jobs").
+		Where(sq.Eq{"status": []string{model.JobStatusSucceeded, model.JobStatusFailed, model.JobStatusCancelled}}).
+		Where(sq.Lt{"finish_at": finishedBefore})
+
+	result, err := query.Exec()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
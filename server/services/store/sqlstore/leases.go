@@ -0,0 +1,75 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// acquireLease takes a named lease until expireAt, or renews it if the
+// holder already has it. It returns false if another holder has a lease
+// that didn't expire yet.
+func (s *SQLStore) acquireLease(db sq.BaseRunner, name, holderID string, expireAt int64) (bool, error) {
+	updateQuery := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"leases").
+		Set("holder_id", holderID).
+		Set("expire_at", expireAt).
+		Where(sq.Eq{"name": name}).
+		Where(sq.Or{
+			sq.Eq{"holder_id": holderID},
+			sq.Lt{"expire_at": utils.GetMillis()},
+		})
+
+	result, err := updateQuery.Exec()
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	insertQuery := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"leases").
+		Columns("name", "holder_id", "expire_at").
+		Values(name, holderID, expireAt)
+
+	if _, err := insertQuery.Exec(); err != nil {
+		// the insert fails if the lease exists, in which case another
+		// holder has it
+		exists, existsErr := s.leaseExists(db, name)
+		if existsErr != nil || !exists {
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *SQLStore) leaseExists(db sq.BaseRunner, name string) (bool, error) {
+	query := s.getQueryBuilder(db).
+		Select("COUNT(*)").
+		From(s.tablePrefix + "leases").
+		Where(sq.Eq{"name": name})
+
+	var count int
+	if err := query.QueryRow().Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// releaseLease gives a lease up, if the holder has it, so that another
+// holder can take it without waiting for it to expire.
+func (s *SQLStore) releaseLease(db sq.BaseRunner, name, holderID string) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "leases").
+		Where(sq.Eq{"name": name}).
+		Where(sq.Eq{"holder_id": holderID})
+
+	_, err := query.Exec()
+	return err
+}
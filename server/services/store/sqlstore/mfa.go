@@ -0,0 +1,74 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+func (s *SQLStore) saveMfaRecoveryCodes(db sq.BaseRunner, userID string, codeHashes []string) error {
+	deleteQuery := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "mfa_recovery_codes").
+		Where(sq.Eq{"user_id": userID})
+
+	if _, err := deleteQuery.Exec(); err != nil {
+		return err
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	now := utils.GetMillis()
+	insertQuery := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"mfa_recovery_codes").
+		Columns("user_id", "code_hash", "create_at")
+	for _, codeHash := range codeHashes {
+		insertQuery = insertQuery.Values(userID, codeHash, now)
+	}
+
+	_, err := insertQuery.Exec()
+	return err
+}
+
+func (s *SQLStore) useMfaRecoveryCode(db sq.BaseRunner, userID, codeHash string) (bool, error) {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "mfa_recovery_codes").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"code_hash": codeHash})
+
+	result, err := query.Exec()
+	if err != nil {
+		return false, err
+	}
+
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowCount > 0, nil
+}
+
+// useMfaTotpCounter records the time step of the last TOTP code accepted
+// for a user. It returns false if a code of the same or a later time step
+// was already accepted, so that codes can't be replayed.
+func (s *SQLStore) useMfaTotpCounter(db sq.BaseRunner, userID string, counter int64) (bool, error) {
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"users").
+		Set("mfa_last_counter", counter).
+		Where(sq.Eq{"id": userID}).
+		Where(sq.Lt{"mfa_last_counter": counter})
+
+	result, err := query.Exec()
+	if err != nil {
+		return false, err
+	}
+
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowCount > 0, nil
+}
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}access_tokens (
+	id VARCHAR(36) NOT NULL,
+	user_id VARCHAR(36) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL,
+	description VARCHAR(255),
+	scopes VARCHAR(255),
+	expires_at BIGINT,
+	last_used_at BIGINT,
+	create_at BIGINT,
+	delete_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "access_tokens" "token_hash" }}
+{{ createIndexIfNeeded "access_tokens" "user_id" }}
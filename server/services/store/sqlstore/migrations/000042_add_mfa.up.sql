@@ -0,0 +1,10 @@
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "users" "mfa_active" "boolean" "default false"}}
+{{ addColumnIfNeeded "users" "mfa_last_counter" "BIGINT" "default 0"}}
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}mfa_recovery_codes (
+	user_id VARCHAR(36) NOT NULL,
+	code_hash VARCHAR(64) NOT NULL,
+	create_at BIGINT,
+	PRIMARY KEY (user_id, code_hash)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
@@ -0,0 +1,5 @@
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "users" "roles" "varchar(256)" "NOT NULL DEFAULT ''"}}
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "users" "auth_service, auth_data" }}
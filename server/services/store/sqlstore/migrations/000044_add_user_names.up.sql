@@ -0,0 +1,4 @@
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "users" "nickname" "varchar(64)" "NOT NULL DEFAULT ''"}}
+{{ addColumnIfNeeded "users" "first_name" "varchar(64)" "NOT NULL DEFAULT ''"}}
+{{ addColumnIfNeeded "users" "last_name" "varchar(64)" "NOT NULL DEFAULT ''"}}
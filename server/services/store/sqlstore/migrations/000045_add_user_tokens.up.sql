@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}user_tokens (
+	token_hash VARCHAR(64) NOT NULL,
+	type VARCHAR(32) NOT NULL,
+	user_id VARCHAR(36) NOT NULL,
+	extra VARCHAR(256),
+	create_at BIGINT,
+	expire_at BIGINT,
+	PRIMARY KEY (token_hash)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "user_tokens" "user_id" }}
+
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "users" "email_verified" "boolean" "default false"}}
+
+UPDATE {{.prefix}}users SET email_verified = true;
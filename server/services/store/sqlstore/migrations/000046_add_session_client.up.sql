@@ -0,0 +1,6 @@
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "sessions" "user_agent" "varchar(512)" "NOT NULL DEFAULT ''"}}
+{{ addColumnIfNeeded "sessions" "ip_address" "varchar(64)" "NOT NULL DEFAULT ''"}}
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "sessions" "user_id" }}
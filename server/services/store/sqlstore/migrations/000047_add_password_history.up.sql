@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}password_history (
+	id VARCHAR(36) NOT NULL,
+	user_id VARCHAR(36) NOT NULL,
+	password_hash VARCHAR(128) NOT NULL,
+	create_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "password_history" "user_id" }}
+
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "users" "password_update_at" "BIGINT" "default 0"}}
+
+UPDATE {{.prefix}}users SET password_update_at = create_at;
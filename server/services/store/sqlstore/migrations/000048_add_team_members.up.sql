@@ -0,0 +1,13 @@
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "teams" "title" "VARCHAR(100)" "NOT NULL DEFAULT ''"}}
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}team_members (
+	team_id VARCHAR(36) NOT NULL,
+	user_id VARCHAR(36) NOT NULL,
+	roles VARCHAR(64) NOT NULL DEFAULT '',
+	create_at BIGINT,
+	PRIMARY KEY (team_id, user_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "team_members" "user_id" }}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}team_invites (
+	token_hash VARCHAR(64) NOT NULL,
+	team_id VARCHAR(36) NOT NULL,
+	email VARCHAR(256) NOT NULL,
+	roles VARCHAR(64) NOT NULL DEFAULT '',
+	invited_by VARCHAR(36) NOT NULL,
+	create_at BIGINT,
+	expire_at BIGINT,
+	PRIMARY KEY (token_hash)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "team_invites" "team_id" }}
@@ -0,0 +1,16 @@
+{{- /* addColumnIfNeeded tableName columnName datatype constraint */ -}}
+{{ addColumnIfNeeded "board_members" "custom_role_id" "VARCHAR(36)" "NOT NULL DEFAULT ''"}}
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}board_roles (
+	id VARCHAR(36) NOT NULL,
+	board_id VARCHAR(36) NOT NULL,
+	name VARCHAR(64) NOT NULL,
+	permissions VARCHAR(1024) NOT NULL DEFAULT '',
+	created_by VARCHAR(36) NOT NULL,
+	create_at BIGINT,
+	update_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "board_roles" "board_id" }}
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}leases (
+	name VARCHAR(64) NOT NULL,
+	holder_id VARCHAR(36) NOT NULL,
+	expire_at BIGINT NOT NULL,
+	PRIMARY KEY (name)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
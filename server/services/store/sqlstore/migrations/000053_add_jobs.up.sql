@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}jobs (
+	id VARCHAR(36) NOT NULL,
+	type VARCHAR(64) NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	attempt INT NOT NULL DEFAULT 1,
+	created_by VARCHAR(36) NOT NULL DEFAULT '',
+	error VARCHAR(1024) NOT NULL DEFAULT '',
+	run_at BIGINT NOT NULL,
+	start_at BIGINT NOT NULL DEFAULT 0,
+	finish_at BIGINT NOT NULL DEFAULT 0,
+	create_at BIGINT NOT NULL,
+	update_at BIGINT NOT NULL,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+{{- /* createIndexIfNeeded tableName columns */ -}}
+{{ createIndexIfNeeded "jobs" "type, status" }}
+{{ createIndexIfNeeded "jobs" "status, run_at" }}
@@ -0,0 +1,85 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// getPasswordHistory returns the hashes of the last passwords of a user,
+// the most recent first.
+func (s *SQLStore) getPasswordHistory(db sq.BaseRunner, userID string, limit int) ([]string, error) {
+	if limit <= 0 {
+		return []string{}, nil
+	}
+
+	query := s.getQueryBuilder(db).
+		Select("password_hash").
+		From(s.tablePrefix+"password_history").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("create_at DESC", "id DESC").
+		Limit(uint64(limit))
+
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	hashes := []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// addPasswordHistory records a password of a user, and forgets all but
+// the last keep ones.
+func (s *SQLStore) addPasswordHistory(db sq.BaseRunner, userID, passwordHash string, keep int) error {
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"password_history").
+		Columns("id", "user_id", "password_hash", "create_at").
+		Values(utils.NewID(utils.IDTypeNone), userID, passwordHash, utils.GetMillis())
+
+	if _, err := query.Exec(); err != nil {
+		return err
+	}
+
+	idsQuery := s.getQueryBuilder(db).
+		Select("id").
+		From(s.tablePrefix+"password_history").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("create_at DESC", "id DESC")
+
+	rows, err := idsQuery.Query()
+	if err != nil {
+		return err
+	}
+	defer s.CloseRows(rows)
+
+	staleIDs := []string{}
+	for i := 0; rows.Next(); i++ {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		if i >= keep {
+			staleIDs = append(staleIDs, id)
+		}
+	}
+	if len(staleIDs) == 0 {
+		return nil
+	}
+
+	deleteQuery := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "password_history").
+		Where(sq.Eq{"id": staleIDs})
+
+	_, err = deleteQuery.Exec()
+	return err
+}
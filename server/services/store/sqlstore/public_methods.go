@@ -22,6 +22,35 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 )
 
+func (s *SQLStore) AcquireLease(name string, holderID string, expireAt int64) (bool, error) {
+	return s.acquireLease(s.db, name, holderID, expireAt)
+
+}
+
+func (s *SQLStore) AddPasswordHistory(userID string, passwordHash string, keep int) error {
+	if s.dbType == model.SqliteDBType {
+		return s.addPasswordHistory(s.db, userID, passwordHash, keep)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.addPasswordHistory(tx, userID, passwordHash, keep)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "AddPasswordHistory"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
 func (s *SQLStore) AddUpdateCategoryBoard(userID string, categoryID string, boardIDs []string) error {
 	if s.dbType == model.SqliteDBType {
 		return s.addUpdateCategoryBoard(s.db, userID, categoryID, boardIDs)
@@ -51,11 +80,31 @@ func (s *SQLStore) CanSeeUser(seerID string, seenID string) (bool, error) {
 
 }
 
+func (s *SQLStore) CleanUpJobs(finishedBefore int64) (int64, error) {
+	return s.cleanUpJobs(s.db, finishedBefore)
+
+}
+
 func (s *SQLStore) CleanUpSessions(expireTime int64) error {
 	return s.cleanUpSessions(s.db, expireTime)
 
 }
 
+func (s *SQLStore) CleanUpTeamInvites() error {
+	return s.cleanUpTeamInvites(s.db)
+
+}
+
+func (s *SQLStore) CleanUpUserTokens() error {
+	return s.cleanUpUserTokens(s.db)
+
+}
+
+func (s *SQLStore) CreateAccessToken(token *model.AccessToken) error {
+	return s.createAccessToken(s.db, token)
+
+}
+
 func (s *SQLStore) CreateBoardsAndBlocks(bab *model.BoardsAndBlocks, userID string) (*model.BoardsAndBlocks, error) {
 	if s.dbType == model.SqliteDBType {
 		return s.createBoardsAndBlocks(s.db, bab, userID)
@@ -138,11 +187,21 @@ func (s *SQLStore) CreateSubscription(sub *model.Subscription) (*model.Subscript
 
 }
 
+func (s *SQLStore) CreateTeam(team *model.Team) (*model.Team, error) {
+	return s.createTeam(s.db, team)
+
+}
+
 func (s *SQLStore) CreateUser(user *model.User) (*model.User, error) {
 	return s.createUser(s.db, user)
 
 }
 
+func (s *SQLStore) DeleteAccessToken(tokenID string) error {
+	return s.deleteAccessToken(s.db, tokenID)
+
+}
+
 func (s *SQLStore) DeleteBlock(blockID string, modifiedBy string) error {
 	if s.dbType == model.SqliteDBType {
 		return s.deleteBlock(s.db, blockID, modifiedBy)
@@ -230,6 +289,30 @@ func (s *SQLStore) DeleteCategory(categoryID string, userID string, teamID strin
 
 }
 
+func (s *SQLStore) DeleteCustomBoardRole(roleID string) error {
+	if s.dbType == model.SqliteDBType {
+		return s.deleteCustomBoardRole(s.db, roleID)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.deleteCustomBoardRole(tx, roleID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "DeleteCustomBoardRole"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
 func (s *SQLStore) DeleteMember(boardID string, userID string) error {
 	return s.deleteMember(s.db, boardID, userID)
 
@@ -250,6 +333,45 @@ func (s *SQLStore) DeleteSubscription(blockID string, subscriberID string) error
 
 }
 
+func (s *SQLStore) DeleteTeam(teamID string) error {
+	if s.dbType == model.SqliteDBType {
+		return s.deleteTeam(s.db, teamID)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.deleteTeam(tx, teamID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "DeleteTeam"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (s *SQLStore) DeleteTeamMember(teamID string, userID string) error {
+	return s.deleteTeamMember(s.db, teamID, userID)
+
+}
+
+func (s *SQLStore) DeleteUserSessions(userID string, exceptSessionID string) error {
+	return s.deleteUserSessions(s.db, userID, exceptSessionID)
+
+}
+
+func (s *SQLStore) DeleteUserTokens(userID string, tokenType string) error {
+	return s.deleteUserTokens(s.db, userID, tokenType)
+
+}
+
 func (s *SQLStore) DuplicateBlock(boardID string, blockID string, userID string, asTemplate bool) ([]*model.Block, error) {
 	if s.dbType == model.SqliteDBType {
 		return s.duplicateBlock(s.db, boardID, blockID, userID, asTemplate)
@@ -298,6 +420,21 @@ func (s *SQLStore) DuplicateBoard(boardID string, userID string, toTeam string,
 
 }
 
+func (s *SQLStore) GetAccessToken(tokenID string) (*model.AccessToken, error) {
+	return s.getAccessToken(s.db, tokenID)
+
+}
+
+func (s *SQLStore) GetAccessTokenByHash(tokenHash string) (*model.AccessToken, error) {
+	return s.getAccessTokenByHash(s.db, tokenHash)
+
+}
+
+func (s *SQLStore) GetAccessTokensForUser(userID string) ([]*model.AccessToken, error) {
+	return s.getAccessTokensForUser(s.db, userID)
+
+}
+
 func (s *SQLStore) GetActiveUserCount(updatedSecondsAgo int64) (int, error) {
 	return s.getActiveUserCount(s.db, updatedSecondsAgo)
 
@@ -433,11 +570,36 @@ func (s *SQLStore) GetChannel(teamID string, channelID string) (*mmModel.Channel
 
 }
 
+func (s *SQLStore) GetCustomBoardRole(roleID string) (*model.CustomBoardRole, error) {
+	return s.getCustomBoardRole(s.db, roleID)
+
+}
+
+func (s *SQLStore) GetCustomBoardRoles(boardID string) ([]*model.CustomBoardRole, error) {
+	return s.getCustomBoardRoles(s.db, boardID)
+
+}
+
+func (s *SQLStore) GetDueJobs(runBefore int64) ([]*model.Job, error) {
+	return s.getDueJobs(s.db, runBefore)
+
+}
+
 func (s *SQLStore) GetFileInfo(id string) (*mmModel.FileInfo, error) {
 	return s.getFileInfo(s.db, id)
 
 }
 
+func (s *SQLStore) GetJob(id string) (*model.Job, error) {
+	return s.getJob(s.db, id)
+
+}
+
+func (s *SQLStore) GetJobs(opts model.QueryJobsOptions) ([]*model.Job, error) {
+	return s.getJobs(s.db, opts)
+
+}
+
 func (s *SQLStore) GetLicense() *mmModel.License {
 	return s.getLicense(s.db)
 
@@ -468,6 +630,11 @@ func (s *SQLStore) GetNotificationHint(blockID string) (*model.NotificationHint,
 
 }
 
+func (s *SQLStore) GetPasswordHistory(userID string, limit int) ([]string, error) {
+	return s.getPasswordHistory(s.db, userID, limit)
+
+}
+
 func (s *SQLStore) GetRegisteredUserCount() (int, error) {
 	return s.getRegisteredUserCount(s.db)
 
@@ -528,6 +695,26 @@ func (s *SQLStore) GetTeamCount() (int64, error) {
 
 }
 
+func (s *SQLStore) GetTeamInvite(tokenHash string) (*model.TeamInvite, error) {
+	return s.getTeamInvite(s.db, tokenHash)
+
+}
+
+func (s *SQLStore) GetTeamMember(teamID string, userID string) (*model.TeamMember, error) {
+	return s.getTeamMember(s.db, teamID, userID)
+
+}
+
+func (s *SQLStore) GetTeamMembers(teamID string) ([]*model.TeamMember, error) {
+	return s.getTeamMembers(s.db, teamID)
+
+}
+
+func (s *SQLStore) GetTeamMembersForUser(userID string) ([]*model.TeamMember, error) {
+	return s.getTeamMembersForUser(s.db, userID)
+
+}
+
 func (s *SQLStore) GetTeamsForUser(userID string) ([]*model.Team, error) {
 	return s.getTeamsForUser(s.db, userID)
 
@@ -543,6 +730,11 @@ func (s *SQLStore) GetUsedCardsCount() (int, error) {
 
 }
 
+func (s *SQLStore) GetUserByAuthData(authService string, authData string) (*model.User, error) {
+	return s.getUserByAuthData(s.db, authService, authData)
+
+}
+
 func (s *SQLStore) GetUserByEmail(email string) (*model.User, error) {
 	return s.getUserByEmail(s.db, email)
 
@@ -573,6 +765,11 @@ func (s *SQLStore) GetUserPreferences(userID string) (mmModel.Preferences, error
 
 }
 
+func (s *SQLStore) GetUserSessions(userID string, expireTime int64) ([]*model.Session, error) {
+	return s.getUserSessions(s.db, userID, expireTime)
+
+}
+
 func (s *SQLStore) GetUserTimezone(userID string) (string, error) {
 	return s.getUserTimezone(s.db, userID)
 
@@ -771,11 +968,21 @@ func (s *SQLStore) PostMessage(message string, postType string, channelID string
 
 }
 
+func (s *SQLStore) QueryUsers(opts model.QueryUsersOptions) ([]*model.User, int, error) {
+	return s.queryUsers(s.db, opts)
+
+}
+
 func (s *SQLStore) RefreshSession(session *model.Session) error {
 	return s.refreshSession(s.db, session)
 
 }
 
+func (s *SQLStore) ReleaseLease(name string, holderID string) error {
+	return s.releaseLease(s.db, name, holderID)
+
+}
+
 func (s *SQLStore) RemoveDefaultTemplates(boards []*model.Board) error {
 	return s.removeDefaultTemplates(s.db, boards)
 
@@ -815,16 +1022,89 @@ func (s *SQLStore) RunDataRetention(globalRetentionDate int64, batchSize int64)
 
 }
 
+func (s *SQLStore) SaveCustomBoardRole(role *model.CustomBoardRole) (*model.CustomBoardRole, error) {
+	return s.saveCustomBoardRole(s.db, role)
+
+}
+
 func (s *SQLStore) SaveFileInfo(fileInfo *mmModel.FileInfo) error {
 	return s.saveFileInfo(s.db, fileInfo)
 
 }
 
+func (s *SQLStore) SaveJob(job *model.Job) error {
+	return s.saveJob(s.db, job)
+
+}
+
 func (s *SQLStore) SaveMember(bm *model.BoardMember) (*model.BoardMember, error) {
 	return s.saveMember(s.db, bm)
 
 }
 
+func (s *SQLStore) SaveMembersAndPatchBlocks(members []*model.BoardMember, blockPatches *model.BlockPatchBatch, userID string) error {
+	if s.dbType == model.SqliteDBType {
+		return s.saveMembersAndPatchBlocks(s.db, members, blockPatches, userID)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.saveMembersAndPatchBlocks(tx, members, blockPatches, userID)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "SaveMembersAndPatchBlocks"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (s *SQLStore) SaveMfaRecoveryCodes(userID string, codeHashes []string) error {
+	if s.dbType == model.SqliteDBType {
+		return s.saveMfaRecoveryCodes(s.db, userID, codeHashes)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return txErr
+	}
+	err := s.saveMfaRecoveryCodes(tx, userID, codeHashes)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "SaveMfaRecoveryCodes"))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func (s *SQLStore) SaveTeamInvite(invite *model.TeamInvite) error {
+	return s.saveTeamInvite(s.db, invite)
+
+}
+
+func (s *SQLStore) SaveTeamMember(member *model.TeamMember) (*model.TeamMember, error) {
+	return s.saveTeamMember(s.db, member)
+
+}
+
+func (s *SQLStore) SaveUserToken(token *model.UserToken) error {
+	return s.saveUserToken(s.db, token)
+
+}
+
 func (s *SQLStore) SearchBoardsForUser(term string, searchField model.BoardSearchField, userID string, includePublicBoards bool) ([]*model.Board, error) {
 	return s.searchBoardsForUser(s.db, term, searchField, userID, includePublicBoards)
 
@@ -908,6 +1188,11 @@ func (s *SQLStore) UndeleteBoard(boardID string, modifiedBy string) error {
 
 }
 
+func (s *SQLStore) UpdateAccessTokenLastUsed(tokenID string, lastUsedAt int64) error {
+	return s.updateAccessTokenLastUsed(s.db, tokenID, lastUsedAt)
+
+}
+
 func (s *SQLStore) UpdateCardLimitTimestamp(cardLimit int) (int64, error) {
 	return s.updateCardLimitTimestamp(s.db, cardLimit)
 
@@ -918,6 +1203,11 @@ func (s *SQLStore) UpdateCategory(category model.Category) error {
 
 }
 
+func (s *SQLStore) UpdateJobStatus(job *model.Job, previousStatus string) (bool, error) {
+	return s.updateJobStatus(s.db, job, previousStatus)
+
+}
+
 func (s *SQLStore) UpdateSession(session *model.Session) error {
 	return s.updateSession(s.db, session)
 
@@ -928,11 +1218,21 @@ func (s *SQLStore) UpdateSubscribersNotifiedAt(blockID string, notifiedAt int64)
 
 }
 
+func (s *SQLStore) UpdateTeam(team *model.Team) (*model.Team, error) {
+	return s.updateTeam(s.db, team)
+
+}
+
 func (s *SQLStore) UpdateUser(user *model.User) (*model.User, error) {
 	return s.updateUser(s.db, user)
 
 }
 
+func (s *SQLStore) UpdateUserMfa(userID string, mfaSecret string, mfaActive bool) error {
+	return s.updateUserMfa(s.db, userID, mfaSecret, mfaActive)
+
+}
+
 func (s *SQLStore) UpdateUserPassword(username string, password string) error {
 	return s.updateUserPassword(s.db, username, password)
 
@@ -962,3 +1262,61 @@ func (s *SQLStore) UpsertTeamSignupToken(team model.Team) error {
 	return s.upsertTeamSignupToken(s.db, team)
 
 }
+
+func (s *SQLStore) UseMfaRecoveryCode(userID string, codeHash string) (bool, error) {
+	return s.useMfaRecoveryCode(s.db, userID, codeHash)
+
+}
+
+func (s *SQLStore) UseMfaTotpCounter(userID string, counter int64) (bool, error) {
+	return s.useMfaTotpCounter(s.db, userID, counter)
+
+}
+
+func (s *SQLStore) UseTeamInvite(tokenHash string) (*model.TeamInvite, error) {
+	if s.dbType == model.SqliteDBType {
+		return s.useTeamInvite(s.db, tokenHash)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return nil, txErr
+	}
+	result, err := s.useTeamInvite(tx, tokenHash)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "UseTeamInvite"))
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+
+}
+
+func (s *SQLStore) UseUserToken(tokenHash string, tokenType string) (*model.UserToken, error) {
+	if s.dbType == model.SqliteDBType {
+		return s.useUserToken(s.db, tokenHash, tokenType)
+	}
+	tx, txErr := s.db.BeginTx(context.Background(), nil)
+	if txErr != nil {
+		return nil, txErr
+	}
+	result, err := s.useUserToken(tx, tokenHash, tokenType)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Error("transaction rollback error", mlog.Err(rollbackErr), mlog.String("methodName", "UseUserToken"))
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+
+}
@@ -28,7 +28,7 @@ func (s *SQLStore) getActiveUserCount(db sq.BaseRunner, updatedSecondsAgo int64)
 
 func (s *SQLStore) getSession(db sq.BaseRunner, token string, expireTimeSeconds int64) (*model.Session, error) {
 	query := s.getQueryBuilder(db).
-		Select("id", "token", "user_id", "auth_service", "props").
+		Select("id", "token", "user_id", "auth_service", "props", "user_agent", "ip_address").
 		From(s.tablePrefix + "sessions").
 		Where(sq.Eq{"token": token}).
 		Where(sq.Gt{"update_at": utils.GetMillis() - utils.SecondsToMillis(expireTimeSeconds)})
@@ -37,7 +37,7 @@ func (s *SQLStore) getSession(db sq.BaseRunner, token string, expireTimeSeconds
 	session := model.Session{}
 
 	var propsBytes []byte
-	err := row.Scan(&session.ID, &session.Token, &session.UserID, &session.AuthService, &propsBytes)
+	err := row.Scan(&session.ID, &session.Token, &session.UserID, &session.AuthService, &propsBytes, &session.UserAgent, &session.IPAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +50,44 @@ func (s *SQLStore) getSession(db sq.BaseRunner, token string, expireTimeSeconds
 	return &session, nil
 }
 
+// getUserSessions returns the active sessions of a user, the most recently
+// used first.
+func (s *SQLStore) getUserSessions(db sq.BaseRunner, userID string, expireTimeSeconds int64) ([]*model.Session, error) {
+	query := s.getQueryBuilder(db).
+		Select("id", "token", "user_id", "auth_service", "props", "user_agent", "ip_address", "create_at", "update_at").
+		From(s.tablePrefix+"sessions").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Gt{"update_at": utils.GetMillis() - utils.SecondsToMillis(expireTimeSeconds)}).
+		OrderBy("update_at DESC", "id")
+
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	sessions := []*model.Session{}
+	for rows.Next() {
+		session := model.Session{}
+
+		var propsBytes []byte
+		err := rows.Scan(&session.ID, &session.Token, &session.UserID, &session.AuthService, &propsBytes,
+			&session.UserAgent, &session.IPAddress, &session.CreateAt, &session.UpdateAt)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal(propsBytes, &session.Props)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, nil
+}
+
 func (s *SQLStore) createSession(db sq.BaseRunner, session *model.Session) error {
 	now := utils.GetMillis()
 
@@ -59,8 +97,8 @@ func (s *SQLStore) createSession(db sq.BaseRunner, session *model.Session) error
 	}
 
 	query := s.getQueryBuilder(db).Insert(s.tablePrefix+"sessions").
-		Columns("id", "token", "user_id", "auth_service", "props", "create_at", "update_at").
-		Values(session.ID, session.Token, session.UserID, session.AuthService, propsBytes, now, now)
+		Columns("id", "token", "user_id", "auth_service", "props", "user_agent", "ip_address", "create_at", "update_at").
+		Values(session.ID, session.Token, session.UserID, session.AuthService, propsBytes, session.UserAgent, session.IPAddress, now, now)
 
 	_, err = query.Exec()
 	return err
@@ -102,6 +140,19 @@ func (s *SQLStore) deleteSession(db sq.BaseRunner, sessionID string) error {
 	return err
 }
 
+// deleteUserSessions deletes all the sessions of a user but the excepted
+// one, if any.
+func (s *SQLStore) deleteUserSessions(db sq.BaseRunner, userID, exceptSessionID string) error {
+	query := s.getQueryBuilder(db).Delete(s.tablePrefix + "sessions").
+		Where(sq.Eq{"user_id": userID})
+	if exceptSessionID != "" {
+		query = query.Where(sq.NotEq{"id": exceptSessionID})
+	}
+
+	_, err := query.Exec()
+	return err
+}
+
 func (s *SQLStore) cleanUpSessions(db sq.BaseRunner, expireTimeSeconds int64) error {
 	query := s.getQueryBuilder(db).Delete(s.tablePrefix + "sessions").
 		Where(sq.Lt{"update_at": utils.GetMillis() - utils.SecondsToMillis(expireTimeSeconds)})
@@ -18,6 +18,12 @@ func TestSQLStore(t *testing.T) {
 	t.Run("SystemStore", func(t *testing.T) { storetests.StoreTestSystemStore(t, SetupTests) })
 	t.Run("UserStore", func(t *testing.T) { storetests.StoreTestUserStore(t, SetupTests) })
 	t.Run("SessionStore", func(t *testing.T) { storetests.StoreTestSessionStore(t, SetupTests) })
+	t.Run("PasswordHistoryStore", func(t *testing.T) { storetests.StoreTestPasswordHistoryStore(t, SetupTests) })
+	t.Run("LeaseStore", func(t *testing.T) { storetests.StoreTestLeaseStore(t, SetupTests) })
+	t.Run("JobStore", func(t *testing.T) { storetests.StoreTestJobStore(t, SetupTests) })
+	t.Run("AccessTokenStore", func(t *testing.T) { storetests.StoreTestAccessTokenStore(t, SetupTests) })
+	t.Run("MfaStore", func(t *testing.T) { storetests.StoreTestMfaStore(t, SetupTests) })
+	t.Run("UserTokenStore", func(t *testing.T) { storetests.StoreTestUserTokenStore(t, SetupTests) })
 	t.Run("TeamStore", func(t *testing.T) { storetests.StoreTestTeamStore(t, SetupTests) })
 	t.Run("BoardStore", func(t *testing.T) { storetests.StoreTestBoardStore(t, SetupTests) })
 	t.Run("BoardsAndBlocksStore", func(t *testing.T) { storetests.StoreTestBoardsAndBlocksStore(t, SetupTests) })
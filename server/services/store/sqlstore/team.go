@@ -3,6 +3,7 @@ package sqlstore
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/utils"
@@ -15,6 +16,7 @@ import (
 var (
 	teamFields = []string{
 		"id",
+		"title",
 		"signup_token",
 		"COALESCE(settings, '{}')",
 		"modified_by",
@@ -95,13 +97,7 @@ func (s *SQLStore) getTeam(db sq.BaseRunner, id string) (*model.Team, error) {
 	var settingsJSON string
 
 	query := s.getQueryBuilder(db).
-		Select(
-			"id",
-			"signup_token",
-			"COALESCE(settings, '{}')",
-			"modified_by",
-			"update_at",
-		).
+		Select(teamFields...).
 		From(s.tablePrefix + "teams").
 		Where(sq.Eq{"id": id})
 	row := query.QueryRow()
@@ -109,6 +105,7 @@ func (s *SQLStore) getTeam(db sq.BaseRunner, id string) (*model.Team, error) {
 
 	err := row.Scan(
 		&team.ID,
+		&team.Title,
 		&team.SignupToken,
 		&settingsJSON,
 		&team.ModifiedBy,
@@ -127,8 +124,36 @@ func (s *SQLStore) getTeam(db sq.BaseRunner, id string) (*model.Team, error) {
 	return &team, nil
 }
 
-func (s *SQLStore) getTeamsForUser(db sq.BaseRunner, _ string) ([]*model.Team, error) {
-	return s.getAllTeams(db)
+// getTeamsForUser returns the root team, which all the users belong to,
+// and the teams the user is a member of.
+func (s *SQLStore) getTeamsForUser(db sq.BaseRunner, userID string) ([]*model.Team, error) {
+	// the subquery keeps question mark placeholders until it's joined
+	memberTeams := s.getQueryBuilder(db).PlaceholderFormat(sq.Question).
+		Select("team_id").
+		From(s.tablePrefix + "team_members").
+		Where(sq.Eq{"user_id": userID})
+	memberTeamsSQL, memberTeamsArgs, err := memberTeams.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder(db).
+		Select(teamFields...).
+		From(s.tablePrefix + "teams").
+		Where(sq.Or{
+			sq.Eq{"id": model.GlobalTeamID},
+			sq.Expr("id IN ("+memberTeamsSQL+")", memberTeamsArgs...),
+		}).
+		OrderBy("id")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetTeamsForUser", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.teamsFromRows(rows)
 }
 
 func (s *SQLStore) getTeamCount(db sq.BaseRunner) (int64, error) {
@@ -165,6 +190,7 @@ func (s *SQLStore) teamsFromRows(rows *sql.Rows) ([]*model.Team, error) {
 
 		err := rows.Scan(
 			&team.ID,
+			&team.Title,
 			&team.SignupToken,
 			&settingsBytes,
 			&team.ModifiedBy,
@@ -203,3 +229,253 @@ func (s *SQLStore) getAllTeams(db sq.BaseRunner) ([]*model.Team, error) {
 
 	return teams, nil
 }
+
+func (s *SQLStore) createTeam(db sq.BaseRunner, team *model.Team) (*model.Team, error) {
+	team.UpdateAt = utils.GetMillis()
+	if team.SignupToken == "" {
+		team.SignupToken = utils.NewID(utils.IDTypeToken)
+	}
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"teams").
+		Columns(
+			"id",
+			"title",
+			"signup_token",
+			"modified_by",
+			"update_at",
+		).
+		Values(
+			team.ID,
+			team.Title,
+			team.SignupToken,
+			team.ModifiedBy,
+			team.UpdateAt,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (s *SQLStore) updateTeam(db sq.BaseRunner, team *model.Team) (*model.Team, error) {
+	team.UpdateAt = utils.GetMillis()
+
+	query := s.getQueryBuilder(db).
+		Update(s.tablePrefix+"teams").
+		Set("title", team.Title).
+		Set("modified_by", team.ModifiedBy).
+		Set("update_at", team.UpdateAt).
+		Where(sq.Eq{"id": team.ID})
+
+	result, err := query.Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowCount < 1 {
+		return nil, model.NewErrNotFound("team ID=" + team.ID)
+	}
+
+	return team, nil
+}
+
+// deleteTeam deletes a team, its memberships and its pending invites.
+func (s *SQLStore) deleteTeam(db sq.BaseRunner, teamID string) error {
+	deleteMembers := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "team_members").
+		Where(sq.Eq{"team_id": teamID})
+	if _, err := deleteMembers.Exec(); err != nil {
+		return err
+	}
+
+	deleteInvites := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "team_invites").
+		Where(sq.Eq{"team_id": teamID})
+	if _, err := deleteInvites.Exec(); err != nil {
+		return err
+	}
+
+	deleteTeam := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "teams").
+		Where(sq.Eq{"id": teamID})
+	_, err := deleteTeam.Exec()
+	return err
+}
+
+func (s *SQLStore) teamMembersFromRows(rows *sql.Rows) ([]*model.TeamMember, error) {
+	members := []*model.TeamMember{}
+
+	for rows.Next() {
+		var member model.TeamMember
+
+		err := rows.Scan(
+			&member.TeamID,
+			&member.UserID,
+			&member.Roles,
+			&member.CreateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, &member)
+	}
+
+	return members, nil
+}
+
+func (s *SQLStore) getTeamMembersByCondition(db sq.BaseRunner, condition sq.Eq) ([]*model.TeamMember, error) {
+	query := s.getQueryBuilder(db).
+		Select("team_id", "user_id", "roles", "create_at").
+		From(s.tablePrefix+"team_members").
+		Where(condition).
+		OrderBy("create_at", "user_id")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR getTeamMembersByCondition", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.teamMembersFromRows(rows)
+}
+
+func (s *SQLStore) getTeamMembers(db sq.BaseRunner, teamID string) ([]*model.TeamMember, error) {
+	return s.getTeamMembersByCondition(db, sq.Eq{"team_id": teamID})
+}
+
+func (s *SQLStore) getTeamMembersForUser(db sq.BaseRunner, userID string) ([]*model.TeamMember, error) {
+	return s.getTeamMembersByCondition(db, sq.Eq{"user_id": userID})
+}
+
+func (s *SQLStore) getTeamMember(db sq.BaseRunner, teamID, userID string) (*model.TeamMember, error) {
+	members, err := s.getTeamMembersByCondition(db, sq.Eq{"team_id": teamID, "user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, model.NewErrNotFound("team member")
+	}
+	return members[0], nil
+}
+
+// saveTeamMember adds a user to a team, or updates their roles if they
+// are already a member of it.
+func (s *SQLStore) saveTeamMember(db sq.BaseRunner, member *model.TeamMember) (*model.TeamMember, error) {
+	if member.CreateAt == 0 {
+		member.CreateAt = utils.GetMillis()
+	}
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"team_members").
+		Columns("team_id", "user_id", "roles", "create_at").
+		Values(member.TeamID, member.UserID, member.Roles, member.CreateAt)
+	if s.dbType == model.MysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE roles = ?", member.Roles)
+	} else {
+		query = query.Suffix("ON CONFLICT (team_id, user_id) DO UPDATE SET roles = EXCLUDED.roles")
+	}
+
+	if _, err := query.Exec(); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *SQLStore) deleteTeamMember(db sq.BaseRunner, teamID, userID string) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "team_members").
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Eq{"user_id": userID})
+
+	_, err := query.Exec()
+	return err
+}
+
+func (s *SQLStore) saveTeamInvite(db sq.BaseRunner, invite *model.TeamInvite) error {
+	if invite.CreateAt == 0 {
+		invite.CreateAt = utils.GetMillis()
+	}
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"team_invites").
+		Columns("token_hash", "team_id", "email", "roles", "invited_by", "create_at", "expire_at").
+		Values(invite.TokenHash, invite.TeamID, invite.Email, invite.Roles, invite.InvitedBy, invite.CreateAt, invite.ExpireAt)
+
+	_, err := query.Exec()
+	return err
+}
+
+// getTeamInvite returns a pending invite without using it. Expired
+// invites are not found.
+func (s *SQLStore) getTeamInvite(db sq.BaseRunner, tokenHash string) (*model.TeamInvite, error) {
+	query := s.getQueryBuilder(db).
+		Select("token_hash", "team_id", "email", "roles", "invited_by", "create_at", "expire_at").
+		From(s.tablePrefix + "team_invites").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		Where(sq.GtOrEq{"expire_at": utils.GetMillis()})
+
+	var invite model.TeamInvite
+	err := query.QueryRow().Scan(
+		&invite.TokenHash,
+		&invite.TeamID,
+		&invite.Email,
+		&invite.Roles,
+		&invite.InvitedBy,
+		&invite.CreateAt,
+		&invite.ExpireAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.NewErrNotFound("team invite")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// useTeamInvite returns an invite and deletes it, so it can only be used
+// once. Expired invites are not found.
+func (s *SQLStore) useTeamInvite(db sq.BaseRunner, tokenHash string) (*model.TeamInvite, error) {
+	invite, err := s.getTeamInvite(db, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "team_invites").
+		Where(sq.Eq{"token_hash": tokenHash})
+
+	result, err := query.Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	// the invite was used concurrently
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowCount < 1 {
+		return nil, model.NewErrNotFound("team invite")
+	}
+
+	return invite, nil
+}
+
+func (s *SQLStore) cleanUpTeamInvites(db sq.BaseRunner) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "team_invites").
+		Where(sq.Lt{"expire_at": utils.GetMillis()})
+
+	_, err := query.Exec()
+	return err
+}
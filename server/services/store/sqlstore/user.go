@@ -18,6 +18,27 @@ import (
 
 var (
 	errUnsupportedOperation = errors.New("unsupported operation")
+
+	userFields = []string{
+		"id",
+		"username",
+		"email",
+		"email_verified",
+		"nickname",
+		"first_name",
+		"last_name",
+		"password",
+		"password_update_at",
+		"mfa_secret",
+		"mfa_active",
+		"auth_service",
+		"auth_data",
+		"roles",
+		"is_guest",
+		"create_at",
+		"update_at",
+		"delete_at",
+	}
 )
 
 type UserNotFoundError struct {
@@ -59,18 +80,7 @@ func (s *SQLStore) getUserByCondition(db sq.BaseRunner, condition sq.Eq) (*model
 
 func (s *SQLStore) getUsersByCondition(db sq.BaseRunner, condition interface{}, limit uint64) ([]*model.User, error) {
 	query := s.getQueryBuilder(db).
-		Select(
-			"id",
-			"username",
-			"email",
-			"password",
-			"mfa_secret",
-			"auth_service",
-			"auth_data",
-			"create_at",
-			"update_at",
-			"delete_at",
-		).
+		Select(userFields...).
 		From(s.tablePrefix + "users").
 		Where(sq.Eq{"delete_at": 0}).
 		Where(condition)
@@ -98,6 +108,59 @@ func (s *SQLStore) getUsersByCondition(db sq.BaseRunner, condition interface{},
 	return users, nil
 }
 
+// queryUsers returns a page of the users matching the options, including
+// the deactivated ones, and the total number of matching users.
+func (s *SQLStore) queryUsers(db sq.BaseRunner, opts model.QueryUsersOptions) ([]*model.User, int, error) {
+	condition := sq.Eq{}
+	if opts.UserID != "" {
+		condition["id"] = opts.UserID
+	}
+	if opts.Username != "" {
+		condition["username"] = opts.Username
+	}
+	if opts.Email != "" {
+		condition["email"] = opts.Email
+	}
+
+	countQuery := s.getQueryBuilder(db).
+		Select("COUNT(*)").
+		From(s.tablePrefix + "users").
+		Where(condition)
+
+	var count int
+	if err := countQuery.QueryRow().Scan(&count); err != nil {
+		s.logger.Error(`queryUsers count ERROR`, mlog.Err(err))
+		return nil, 0, err
+	}
+
+	query := s.getQueryBuilder(db).
+		Select(userFields...).
+		From(s.tablePrefix+"users").
+		Where(condition).
+		OrderBy("create_at", "id")
+
+	if opts.Offset > 0 {
+		query = query.Offset(uint64(opts.Offset))
+	}
+	if opts.Limit > 0 {
+		query = query.Limit(uint64(opts.Limit))
+	}
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error(`queryUsers ERROR`, mlog.Err(err))
+		return nil, 0, err
+	}
+	defer s.CloseRows(rows)
+
+	users, err := s.usersFromRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, count, nil
+}
+
 func (s *SQLStore) getUserByID(db sq.BaseRunner, userID string) (*model.User, error) {
 	return s.getUserByCondition(db, sq.Eq{"id": userID})
 }
@@ -119,6 +182,34 @@ func (s *SQLStore) getUserByEmail(db sq.BaseRunner, email string) (*model.User,
 	return s.getUserByCondition(db, sq.Eq{"email": email})
 }
 
+// getUserByAuthData returns the user linked to an account of an external
+// identity provider, including a deactivated one, so that the accounts
+// deactivated by hand aren't provisioned again by the provider.
+func (s *SQLStore) getUserByAuthData(db sq.BaseRunner, authService, authData string) (*model.User, error) {
+	query := s.getQueryBuilder(db).
+		Select(userFields...).
+		From(s.tablePrefix+"users").
+		Where(sq.Eq{"auth_service": authService, "auth_data": authData}).
+		OrderBy("delete_at", "create_at").
+		Limit(1)
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error(`getUserByAuthData ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	users, err := s.usersFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, model.NewErrNotFound("user")
+	}
+	return users[0], nil
+}
+
 func (s *SQLStore) getUserByUsername(db sq.BaseRunner, username string) (*model.User, error) {
 	return s.getUserByCondition(db, sq.Eq{"username": username})
 }
@@ -128,10 +219,13 @@ func (s *SQLStore) createUser(db sq.BaseRunner, user *model.User) (*model.User,
 	user.CreateAt = now
 	user.UpdateAt = now
 	user.DeleteAt = 0
+	if user.Password != "" {
+		user.PasswordUpdateAt = now
+	}
 
 	query := s.getQueryBuilder(db).Insert(s.tablePrefix+"users").
-		Columns("id", "username", "email", "password", "mfa_secret", "auth_service", "auth_data", "create_at", "update_at", "delete_at").
-		Values(user.ID, user.Username, user.Email, user.Password, user.MfaSecret, user.AuthService, user.AuthData, user.CreateAt, user.UpdateAt, user.DeleteAt)
+		Columns("id", "username", "email", "email_verified", "nickname", "first_name", "last_name", "password", "password_update_at", "mfa_secret", "mfa_active", "auth_service", "auth_data", "roles", "is_guest", "create_at", "update_at", "delete_at").
+		Values(user.ID, user.Username, user.Email, user.EmailVerified, user.Nickname, user.FirstName, user.LastName, user.Password, user.PasswordUpdateAt, user.MfaSecret, user.MfaActive, user.AuthService, user.AuthData, user.Roles, user.IsGuest, user.CreateAt, user.UpdateAt, user.DeleteAt)
 
 	_, err := query.Exec()
 	return user, err
@@ -144,7 +238,14 @@ func (s *SQLStore) updateUser(db sq.BaseRunner, user *model.User) (*model.User,
 	query := s.getQueryBuilder(db).Update(s.tablePrefix+"users").
 		Set("username", user.Username).
 		Set("email", user.Email).
+		Set("email_verified", user.EmailVerified).
+		Set("nickname", user.Nickname).
+		Set("first_name", user.FirstName).
+		Set("last_name", user.LastName).
+		Set("roles", user.Roles).
+		Set("is_guest", user.IsGuest).
 		Set("update_at", user.UpdateAt).
+		Set("delete_at", user.DeleteAt).
 		Where(sq.Eq{"id": user.ID})
 
 	result, err := query.Exec()
@@ -169,6 +270,7 @@ func (s *SQLStore) updateUserPassword(db sq.BaseRunner, username, password strin
 
 	query := s.getQueryBuilder(db).Update(s.tablePrefix+"users").
 		Set("password", password).
+		Set("password_update_at", now).
 		Set("update_at", now).
 		Where(sq.Eq{"username": username})
 
@@ -194,6 +296,7 @@ func (s *SQLStore) updateUserPasswordByID(db sq.BaseRunner, userID, password str
 
 	query := s.getQueryBuilder(db).Update(s.tablePrefix+"users").
 		Set("password", password).
+		Set("password_update_at", now).
 		Set("update_at", now).
 		Where(sq.Eq{"id": userID})
 
@@ -214,8 +317,85 @@ func (s *SQLStore) updateUserPasswordByID(db sq.BaseRunner, userID, password str
 	return nil
 }
 
-func (s *SQLStore) getUsersByTeam(db sq.BaseRunner, _ string, _ string, _, _ bool) ([]*model.User, error) {
-	users, err := s.getUsersByCondition(db, nil, 0)
+func (s *SQLStore) updateUserMfa(db sq.BaseRunner, userID, mfaSecret string, mfaActive bool) error {
+	now := utils.GetMillis()
+
+	query := s.getQueryBuilder(db).Update(s.tablePrefix+"users").
+		Set("mfa_secret", mfaSecret).
+		Set("mfa_active", mfaActive).
+		Set("update_at", now).
+		Where(sq.Eq{"id": userID})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowCount < 1 {
+		return UserNotFoundError{userID}
+	}
+
+	return nil
+}
+
+// teamUsersCondition restricts a users query to the members of a team.
+// All the users belong to the root team.
+func (s *SQLStore) teamUsersCondition(db sq.BaseRunner, teamID string) (sq.Sqlizer, error) {
+	if teamID == "" || teamID == model.GlobalTeamID {
+		return sq.And{}, nil
+	}
+
+	// the subquery keeps question mark placeholders until it's joined
+	members := s.getQueryBuilder(db).PlaceholderFormat(sq.Question).
+		Select("user_id").
+		From(s.tablePrefix + "team_members").
+		Where(sq.Eq{"team_id": teamID})
+	membersSQL, membersArgs, err := members.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return sq.Expr("id IN ("+membersSQL+")", membersArgs...), nil
+}
+
+// guestUsersCondition limits the users to the guest and the ones that
+// share a board with them. An empty guest ID doesn't limit anything.
+func (s *SQLStore) guestUsersCondition(db sq.BaseRunner, guestID string) (sq.Sqlizer, error) {
+	if guestID == "" {
+		return sq.And{}, nil
+	}
+
+	// the subquery keeps question mark placeholders until it's joined
+	boardUsers := s.getQueryBuilder(db).PlaceholderFormat(sq.Question).
+		Select("BM2.user_id").
+		From(s.tablePrefix + "board_members AS BM1").
+		Join(s.tablePrefix + "board_members AS BM2 ON BM2.board_id = BM1.board_id").
+		Where(sq.Eq{"BM1.user_id": guestID})
+	boardUsersSQL, boardUsersArgs, err := boardUsers.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return sq.Or{sq.Eq{"id": guestID}, sq.Expr("id IN ("+boardUsersSQL+")", boardUsersArgs...)}, nil
+}
+
+func (s *SQLStore) getUsersByTeam(db sq.BaseRunner, teamID string, asGuestID string, _, _ bool) ([]*model.User, error) {
+	condition, err := s.teamUsersCondition(db, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	guestCondition, err := s.guestUsersCondition(db, asGuestID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.getUsersByCondition(db, sq.And{condition, guestCondition}, 0)
 	if model.IsErrNotFound(err) {
 		return []*model.User{}, nil
 	}
@@ -223,8 +403,18 @@ func (s *SQLStore) getUsersByTeam(db sq.BaseRunner, _ string, _ string, _, _ boo
 	return users, err
 }
 
-func (s *SQLStore) searchUsersByTeam(db sq.BaseRunner, _ string, searchQuery string, _ string, _, _, _ bool) ([]*model.User, error) {
-	users, err := s.getUsersByCondition(db, &sq.Like{"username": "%" + searchQuery + "%"}, 10)
+func (s *SQLStore) searchUsersByTeam(db sq.BaseRunner, teamID string, searchQuery string, asGuestID string, _, _, _ bool) ([]*model.User, error) {
+	condition, err := s.teamUsersCondition(db, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	guestCondition, err := s.guestUsersCondition(db, asGuestID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.getUsersByCondition(db, sq.And{condition, guestCondition, &sq.Like{"username": "%" + searchQuery + "%"}}, 10)
 	if model.IsErrNotFound(err) {
 		return []*model.User{}, nil
 	}
@@ -242,10 +432,18 @@ func (s *SQLStore) usersFromRows(rows *sql.Rows) ([]*model.User, error) {
 			&user.ID,
 			&user.Username,
 			&user.Email,
+			&user.EmailVerified,
+			&user.Nickname,
+			&user.FirstName,
+			&user.LastName,
 			&user.Password,
+			&user.PasswordUpdateAt,
 			&user.MfaSecret,
+			&user.MfaActive,
 			&user.AuthService,
 			&user.AuthData,
+			&user.Roles,
+			&user.IsGuest,
 			&user.CreateAt,
 			&user.UpdateAt,
 			&user.DeleteAt,
@@ -353,8 +551,25 @@ func (s *SQLStore) deleteUserPreference(db sq.BaseRunner, preference mmModel.Pre
 	return nil
 }
 
+// canSeeUser returns true if the users are the same or share a board.
 func (s *SQLStore) canSeeUser(db sq.BaseRunner, seerID string, seenID string) (bool, error) {
-	return true, nil
+	if seerID == seenID {
+		return true, nil
+	}
+
+	query := s.getQueryBuilder(db).
+		Select("count(*)").
+		From(s.tablePrefix + "board_members AS BM1").
+		Join(s.tablePrefix + "board_members AS BM2 ON BM2.board_id = BM1.board_id").
+		Where(sq.Eq{"BM1.user_id": seerID}).
+		Where(sq.Eq{"BM2.user_id": seenID})
+
+	var count int
+	if err := query.QueryRow().Scan(&count); err != nil {
+		s.logger.Error("canSeeUser ERROR", mlog.Err(err))
+		return false, err
+	}
+	return count > 0, nil
 }
 
 func (s *SQLStore) sendMessage(db sq.BaseRunner, message, postType string, receipts []string) error {
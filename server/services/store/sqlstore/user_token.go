@@ -0,0 +1,96 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+func (s *SQLStore) saveUserToken(db sq.BaseRunner, token *model.UserToken) error {
+	if token.CreateAt == 0 {
+		token.CreateAt = utils.GetMillis()
+	}
+
+	query := s.getQueryBuilder(db).
+		Insert(s.tablePrefix+"user_tokens").
+		Columns("token_hash", "type", "user_id", "extra", "create_at", "expire_at").
+		Values(token.TokenHash, token.Type, token.UserID, token.Extra, token.CreateAt, token.ExpireAt)
+
+	_, err := query.Exec()
+	return err
+}
+
+// useUserToken returns a token and deletes it, so it can only be used
+// once. Expired tokens are not found.
+func (s *SQLStore) useUserToken(db sq.BaseRunner, tokenHash, tokenType string) (*model.UserToken, error) {
+	query := s.getQueryBuilder(db).
+		Select("token_hash", "type", "user_id", "extra", "create_at", "expire_at").
+		From(s.tablePrefix + "user_tokens").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		Where(sq.Eq{"type": tokenType})
+
+	var token model.UserToken
+	var extra sql.NullString
+	err := query.QueryRow().Scan(
+		&token.TokenHash,
+		&token.Type,
+		&token.UserID,
+		&extra,
+		&token.CreateAt,
+		&token.ExpireAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.NewErrNotFound("user token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	token.Extra = extra.String
+
+	deleteQuery := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "user_tokens").
+		Where(sq.Eq{"token_hash": tokenHash})
+
+	result, err := deleteQuery.Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	// the token was used concurrently
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowCount < 1 {
+		return nil, model.NewErrNotFound("user token")
+	}
+
+	if token.ExpireAt < utils.GetMillis() {
+		return nil, model.NewErrNotFound("user token")
+	}
+
+	return &token, nil
+}
+
+func (s *SQLStore) deleteUserTokens(db sq.BaseRunner, userID, tokenType string) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "user_tokens").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"type": tokenType})
+
+	_, err := query.Exec()
+	return err
+}
+
+func (s *SQLStore) cleanUpUserTokens(db sq.BaseRunner) error {
+	query := s.getQueryBuilder(db).
+		Delete(s.tablePrefix + "user_tokens").
+		Where(sq.Lt{"expire_at": utils.GetMillis()})
+
+	_, err := query.Exec()
+	return err
+}
@@ -60,10 +60,16 @@ type Store interface {
 	GetUsersList(userIDs []string, showEmail, showName bool) ([]*model.User, error)
 	GetUserByEmail(email string) (*model.User, error)
 	GetUserByUsername(username string) (*model.User, error)
+	GetUserByAuthData(authService, authData string) (*model.User, error)
+	QueryUsers(opts model.QueryUsersOptions) ([]*model.User, int, error)
 	CreateUser(user *model.User) (*model.User, error)
 	UpdateUser(user *model.User) (*model.User, error)
 	UpdateUserPassword(username, password string) error
 	UpdateUserPasswordByID(userID, password string) error
+	GetPasswordHistory(userID string, limit int) ([]string, error)
+	// @withTransaction
+	AddPasswordHistory(userID, passwordHash string, keep int) error
+	UpdateUserMfa(userID, mfaSecret string, mfaActive bool) error
 	GetUsersByTeam(teamID string, asGuestID string, showEmail, showName bool) ([]*model.User, error)
 	SearchUsersByTeam(teamID string, searchQuery string, asGuestID string, excludeBots bool, showEmail, showName bool) ([]*model.User, error)
 	PatchUserPreferences(userID string, patch model.UserPreferencesPatch) (mmModel.Preferences, error)
@@ -75,8 +81,28 @@ type Store interface {
 	RefreshSession(session *model.Session) error
 	UpdateSession(session *model.Session) error
 	DeleteSession(sessionID string) error
+	GetUserSessions(userID string, expireTime int64) ([]*model.Session, error)
+	DeleteUserSessions(userID, exceptSessionID string) error
 	CleanUpSessions(expireTime int64) error
 
+	CreateAccessToken(token *model.AccessToken) error
+	GetAccessToken(tokenID string) (*model.AccessToken, error)
+	GetAccessTokenByHash(tokenHash string) (*model.AccessToken, error)
+	GetAccessTokensForUser(userID string) ([]*model.AccessToken, error)
+	UpdateAccessTokenLastUsed(tokenID string, lastUsedAt int64) error
+	DeleteAccessToken(tokenID string) error
+
+	// @withTransaction
+	SaveMfaRecoveryCodes(userID string, codeHashes []string) error
+	UseMfaRecoveryCode(userID, codeHash string) (bool, error)
+	UseMfaTotpCounter(userID string, counter int64) (bool, error)
+
+	SaveUserToken(token *model.UserToken) error
+	// @withTransaction
+	UseUserToken(tokenHash, tokenType string) (*model.UserToken, error)
+	DeleteUserTokens(userID, tokenType string) error
+	CleanUpUserTokens() error
+
 	UpsertSharing(sharing model.Sharing) error
 	GetSharing(rootID string) (*model.Sharing, error)
 
@@ -86,6 +112,21 @@ type Store interface {
 	GetTeamsForUser(userID string) ([]*model.Team, error)
 	GetAllTeams() ([]*model.Team, error)
 	GetTeamCount() (int64, error)
+	CreateTeam(team *model.Team) (*model.Team, error)
+	UpdateTeam(team *model.Team) (*model.Team, error)
+	// @withTransaction
+	DeleteTeam(teamID string) error
+	GetTeamMembers(teamID string) ([]*model.TeamMember, error)
+	GetTeamMembersForUser(userID string) ([]*model.TeamMember, error)
+	GetTeamMember(teamID, userID string) (*model.TeamMember, error)
+	SaveTeamMember(member *model.TeamMember) (*model.TeamMember, error)
+	DeleteTeamMember(teamID, userID string) error
+
+	SaveTeamInvite(invite *model.TeamInvite) error
+	GetTeamInvite(tokenHash string) (*model.TeamInvite, error)
+	// @withTransaction
+	UseTeamInvite(tokenHash string) (*model.TeamInvite, error)
+	CleanUpTeamInvites() error
 
 	InsertBoard(board *model.Board, userID string) (*model.Board, error)
 	// @withTransaction
@@ -104,6 +145,13 @@ type Store interface {
 	GetBoardMemberHistory(boardID, userID string, limit uint64) ([]*model.BoardMemberHistoryEntry, error)
 	GetMembersForBoard(boardID string) ([]*model.BoardMember, error)
 	GetMembersForUser(userID string) ([]*model.BoardMember, error)
+	GetCustomBoardRoles(boardID string) ([]*model.CustomBoardRole, error)
+	GetCustomBoardRole(roleID string) (*model.CustomBoardRole, error)
+	SaveCustomBoardRole(role *model.CustomBoardRole) (*model.CustomBoardRole, error)
+	// @withTransaction
+	DeleteCustomBoardRole(roleID string) error
+	// @withTransaction
+	SaveMembersAndPatchBlocks(members []*model.BoardMember, blockPatches *model.BlockPatchBatch, userID string) error
 	CanSeeUser(seerID string, seenID string) (bool, error)
 	SearchBoardsForUser(term string, searchField model.BoardSearchField, userID string, includePublicBoards bool) ([]*model.Board, error)
 	SearchBoardsForUserInTeam(teamID, term, userID string) ([]*model.Board, error)
@@ -155,6 +203,16 @@ type Store interface {
 	// @withTransaction
 	RunDataRetention(globalRetentionDate int64, batchSize int64) (int64, error)
 
+	AcquireLease(name, holderID string, expireAt int64) (bool, error)
+	ReleaseLease(name, holderID string) error
+
+	SaveJob(job *model.Job) error
+	GetJob(id string) (*model.Job, error)
+	GetJobs(opts model.QueryJobsOptions) ([]*model.Job, error)
+	GetDueJobs(runBefore int64) ([]*model.Job, error)
+	UpdateJobStatus(job *model.Job, previousStatus string) (bool, error)
+	CleanUpJobs(finishedBefore int64) (int64, error)
+
 	GetUsedCardsCount() (int, error)
 	GetCardLimitTimestamp() (int64, error)
 	UpdateCardLimitTimestamp(cardLimit int) (int64, error)
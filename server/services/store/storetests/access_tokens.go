@@ -0,0 +1,135 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestAccessTokenStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("CreateAndGetAccessToken", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCreateAndGetAccessToken(t, store)
+	})
+
+	t.Run("GetAccessTokensForUser", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetAccessTokensForUser(t, store)
+	})
+
+	t.Run("DeleteAccessToken", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteAccessToken(t, store)
+	})
+}
+
+func testCreateAndGetAccessToken(t *testing.T, store store.Store) {
+	token := &model.AccessToken{
+		ID:          "token-id",
+		UserID:      testUserID,
+		Description: "automation",
+		Scopes:      []string{model.AccessTokenScopeReadOnly},
+		TokenHash:   "token-hash",
+		ExpiresAt:   1234,
+	}
+
+	t.Run("create and get by ID", func(t *testing.T) {
+		require.NoError(t, store.CreateAccessToken(token))
+		require.NotZero(t, token.CreateAt)
+
+		got, err := store.GetAccessToken(token.ID)
+		require.NoError(t, err)
+		require.Equal(t, token, got)
+	})
+
+	t.Run("get by hash", func(t *testing.T) {
+		got, err := store.GetAccessTokenByHash("token-hash")
+		require.NoError(t, err)
+		require.Equal(t, token, got)
+	})
+
+	t.Run("get nonexistent token", func(t *testing.T) {
+		got, err := store.GetAccessTokenByHash("nonexistent-hash")
+		require.True(t, model.IsErrNotFound(err))
+		require.Nil(t, got)
+	})
+
+	t.Run("update last used", func(t *testing.T) {
+		require.NoError(t, store.UpdateAccessTokenLastUsed(token.ID, 5678))
+
+		got, err := store.GetAccessToken(token.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(5678), got.LastUsedAt)
+	})
+
+	t.Run("token without scopes", func(t *testing.T) {
+		noScopes := &model.AccessToken{
+			ID:        "token-id-2",
+			UserID:    testUserID,
+			TokenHash: "token-hash-2",
+		}
+		require.NoError(t, store.CreateAccessToken(noScopes))
+
+		got, err := store.GetAccessToken(noScopes.ID)
+		require.NoError(t, err)
+		require.Empty(t, got.Scopes)
+	})
+}
+
+func testGetAccessTokensForUser(t *testing.T, store store.Store) {
+	t.Run("no tokens", func(t *testing.T) {
+		tokens, err := store.GetAccessTokensForUser(testUserID)
+		require.NoError(t, err)
+		require.Empty(t, tokens)
+	})
+
+	t.Run("tokens of several users", func(t *testing.T) {
+		for _, token := range []*model.AccessToken{
+			{ID: "token-1", UserID: testUserID, TokenHash: "hash-1"},
+			{ID: "token-2", UserID: testUserID, TokenHash: "hash-2"},
+			{ID: "token-3", UserID: "other-user-id", TokenHash: "hash-3"},
+		} {
+			require.NoError(t, store.CreateAccessToken(token))
+		}
+
+		tokens, err := store.GetAccessTokensForUser(testUserID)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		for _, token := range tokens {
+			require.Equal(t, testUserID, token.UserID)
+		}
+	})
+}
+
+func testDeleteAccessToken(t *testing.T, store store.Store) {
+	token := &model.AccessToken{
+		ID:        "token-id",
+		UserID:    testUserID,
+		TokenHash: "token-hash",
+	}
+	require.NoError(t, store.CreateAccessToken(token))
+
+	t.Run("delete token", func(t *testing.T) {
+		require.NoError(t, store.DeleteAccessToken(token.ID))
+
+		_, err := store.GetAccessToken(token.ID)
+		require.True(t, model.IsErrNotFound(err))
+
+		_, err = store.GetAccessTokenByHash(token.TokenHash)
+		require.True(t, model.IsErrNotFound(err))
+
+		tokens, err := store.GetAccessTokensForUser(testUserID)
+		require.NoError(t, err)
+		require.Empty(t, tokens)
+	})
+
+	t.Run("delete an already deleted token", func(t *testing.T) {
+		err := store.DeleteAccessToken(token.ID)
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
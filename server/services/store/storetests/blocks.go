@@ -452,6 +452,38 @@ func testPatchBlock(t *testing.T, store store.Store) {
 		require.Equal(t, "test value 2", retrievedBlock.Fields["test2"])
 		require.Equal(t, nil, retrievedBlock.Fields["test3"])
 	})
+
+	t.Run("patch the expected version", func(t *testing.T) {
+		currentBlock, err := store.GetBlock("id-test")
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		newTitle := "Expected version"
+		blockPatch := &model.BlockPatch{Title: &newTitle, ExpectedUpdateAt: &currentBlock.UpdateAt}
+		require.NoError(t, store.PatchBlock("id-test", blockPatch, "user-id-2"))
+
+		retrievedBlock, err := store.GetBlock("id-test")
+		require.NoError(t, err)
+		require.Equal(t, newTitle, retrievedBlock.Title)
+		require.Greater(t, retrievedBlock.UpdateAt, currentBlock.UpdateAt)
+
+		t.Run("an outdated version conflicts", func(t *testing.T) {
+			time.Sleep(1 * time.Millisecond)
+
+			outdatedTitle := "Outdated version"
+			blockPatch := &model.BlockPatch{Title: &outdatedTitle, ExpectedUpdateAt: &currentBlock.UpdateAt}
+			err := store.PatchBlock("id-test", blockPatch, "user-id-2")
+			var conflict *model.ErrVersionConflict
+			require.ErrorAs(t, err, &conflict)
+			require.True(t, model.IsErrConflict(err))
+			require.Equal(t, retrievedBlock.UpdateAt, conflict.CurrentUpdateAt)
+
+			block, err := store.GetBlock("id-test")
+			require.NoError(t, err)
+			require.Equal(t, newTitle, block.Title)
+		})
+	})
 }
 
 func testPatchBlocks(t *testing.T, store store.Store) {
@@ -77,6 +77,16 @@ func StoreTestBoardStore(t *testing.T, setup func(t *testing.T) (store.Store, fu
 		defer tearDown()
 		testDeleteMember(t, store)
 	})
+	t.Run("CustomBoardRoles", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCustomBoardRoles(t, store)
+	})
+	t.Run("SaveMembersAndPatchBlocks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testSaveMembersAndPatchBlocks(t, store)
+	})
 	t.Run("SearchBoardsForUser", func(t *testing.T) {
 		store, tearDown := setup(t)
 		defer tearDown()
@@ -538,6 +548,43 @@ func testPatchBoard(t *testing.T, store store.Store) {
 		require.NoError(t, err)
 		require.ElementsMatch(t, expectedCardProperties, patchedBoard.CardProperties)
 	})
+
+	t.Run("should only apply a patch to the expected version", func(t *testing.T) {
+		boardID := utils.NewID(utils.IDTypeBoard)
+
+		board := &model.Board{
+			ID:     boardID,
+			TeamID: testTeamID,
+			Type:   model.BoardTypeOpen,
+			Title:  "A simple title",
+		}
+
+		newBoard, err := store.InsertBoard(board, userID)
+		require.NoError(t, err)
+
+		// wait to avoid hitting pk uniqueness constraint in history
+		time.Sleep(10 * time.Millisecond)
+
+		newTitle := "A new title"
+		patch := &model.BoardPatch{Title: &newTitle, ExpectedUpdateAt: &newBoard.UpdateAt}
+		patchedBoard, err := store.PatchBoard(boardID, patch, userID)
+		require.NoError(t, err)
+		require.Equal(t, newTitle, patchedBoard.Title)
+		require.Greater(t, patchedBoard.UpdateAt, newBoard.UpdateAt)
+
+		time.Sleep(10 * time.Millisecond)
+
+		outdatedTitle := "An outdated title"
+		patch = &model.BoardPatch{Title: &outdatedTitle, ExpectedUpdateAt: &newBoard.UpdateAt}
+		_, err = store.PatchBoard(boardID, patch, userID)
+		var conflict *model.ErrVersionConflict
+		require.ErrorAs(t, err, &conflict)
+		require.Equal(t, patchedBoard.UpdateAt, conflict.CurrentUpdateAt)
+
+		rBoard, err := store.GetBoard(boardID)
+		require.NoError(t, err)
+		require.Equal(t, newTitle, rBoard.Title)
+	})
 }
 
 func testDeleteBoard(t *testing.T, store store.Store) {
@@ -653,6 +700,30 @@ func testSaveMember(t *testing.T, store store.Store) {
 		require.Len(t, memberHistory, initialMemberHistory)
 	})
 
+	t.Run("should store the member roles", func(t *testing.T) {
+		bm := &model.BoardMember{
+			UserID:       userID,
+			BoardID:      boardID,
+			Roles:        "ldap",
+			SchemeViewer: true,
+		}
+
+		_, err := store.SaveMember(bm)
+		require.NoError(t, err)
+
+		member, err := store.GetMemberForBoard(boardID, userID)
+		require.NoError(t, err)
+		require.Equal(t, "ldap", member.Roles)
+
+		bm.Roles = ""
+		_, err = store.SaveMember(bm)
+		require.NoError(t, err)
+
+		member, err = store.GetMemberForBoard(boardID, userID)
+		require.NoError(t, err)
+		require.Empty(t, member.Roles)
+	})
+
 	t.Run("should return empty list if no results are found", func(t *testing.T) {
 		memberHistory, err := store.GetBoardMemberHistory(boardID, "nonexistent-user", 0)
 		require.NoError(t, err)
@@ -660,6 +731,53 @@ func testSaveMember(t *testing.T, store store.Store) {
 	})
 }
 
+func testSaveMembersAndPatchBlocks(t *testing.T, store store.Store) {
+	boardID := testBoardID
+	cards := createTestCards(t, store, testUserID, boardID, 1)
+	title := "updated title"
+
+	t.Run("should leave the members untouched if a patch fails", func(t *testing.T) {
+		if store.DBType() == model.SqliteDBType {
+			t.Skip("No transactions support int sqlite")
+		}
+
+		members := []*model.BoardMember{{UserID: testUserID, BoardID: boardID, SchemeAdmin: true}}
+		patches := &model.BlockPatchBatch{
+			BlockIDs:     []string{cards[0].ID, "nonexistent"},
+			BlockPatches: []model.BlockPatch{{Title: &title}, {Title: &title}},
+		}
+
+		err := store.SaveMembersAndPatchBlocks(members, patches, testUserID)
+		require.Error(t, err)
+
+		_, err = store.GetMemberForBoard(boardID, testUserID)
+		require.True(t, model.IsErrNotFound(err))
+
+		card, err := store.GetBlock(cards[0].ID)
+		require.NoError(t, err)
+		require.NotEqual(t, title, card.Title)
+	})
+
+	t.Run("should save the members and patch the blocks", func(t *testing.T) {
+		members := []*model.BoardMember{{UserID: testUserID, BoardID: boardID, SchemeAdmin: true}}
+		patches := &model.BlockPatchBatch{
+			BlockIDs:     []string{cards[0].ID},
+			BlockPatches: []model.BlockPatch{{Title: &title}},
+		}
+
+		err := store.SaveMembersAndPatchBlocks(members, patches, testUserID)
+		require.NoError(t, err)
+
+		member, err := store.GetMemberForBoard(boardID, testUserID)
+		require.NoError(t, err)
+		require.True(t, member.SchemeAdmin)
+
+		card, err := store.GetBlock(cards[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, title, card.Title)
+	})
+}
+
 func testGetMemberForBoard(t *testing.T, store store.Store) {
 	userID := testUserID
 	boardID := testBoardID
@@ -1199,3 +1317,78 @@ func testGetBoardCount(t *testing.T, store store.Store) {
 		require.Equal(t, originalCount+1, newCount)
 	})
 }
+
+func testCustomBoardRoles(t *testing.T, store store.Store) {
+	role := &model.CustomBoardRole{
+		ID:          utils.NewID(utils.IDTypeNone),
+		BoardID:     testBoardID,
+		Name:        "Card editor",
+		Permissions: []string{model.PermissionManageBoardCards.Id, model.PermissionCommentBoardCards.Id},
+		CreatedBy:   testUserID,
+	}
+
+	t.Run("save and get a role", func(t *testing.T) {
+		_, err := store.SaveCustomBoardRole(role)
+		require.NoError(t, err)
+		require.NotZero(t, role.CreateAt)
+
+		_, err = store.SaveCustomBoardRole(&model.CustomBoardRole{
+			ID:          utils.NewID(utils.IDTypeNone),
+			BoardID:     "other-board-id",
+			Name:        "Commenter",
+			Permissions: []string{model.PermissionCommentBoardCards.Id},
+			CreatedBy:   testUserID,
+		})
+		require.NoError(t, err)
+
+		rRole, err := store.GetCustomBoardRole(role.ID)
+		require.NoError(t, err)
+		require.Equal(t, role.Name, rRole.Name)
+		require.Equal(t, role.Permissions, rRole.Permissions)
+
+		roles, err := store.GetCustomBoardRoles(testBoardID)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		require.Equal(t, role.ID, roles[0].ID)
+	})
+
+	t.Run("update a role", func(t *testing.T) {
+		role.Name = "Property editor"
+		role.Permissions = []string{model.PermissionManageBoardProperties.Id}
+		_, err := store.SaveCustomBoardRole(role)
+		require.NoError(t, err)
+
+		rRole, err := store.GetCustomBoardRole(role.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Property editor", rRole.Name)
+		require.Equal(t, []string{model.PermissionManageBoardProperties.Id}, rRole.Permissions)
+	})
+
+	t.Run("assign a role to a member", func(t *testing.T) {
+		_, err := store.SaveMember(&model.BoardMember{
+			UserID:       testUserID,
+			BoardID:      testBoardID,
+			SchemeViewer: true,
+			CustomRoleID: role.ID,
+		})
+		require.NoError(t, err)
+
+		member, err := store.GetMemberForBoard(testBoardID, testUserID)
+		require.NoError(t, err)
+		require.Equal(t, role.ID, member.CustomRoleID)
+	})
+
+	t.Run("delete a role", func(t *testing.T) {
+		require.NoError(t, store.DeleteCustomBoardRole(role.ID))
+
+		_, err := store.GetCustomBoardRole(role.ID)
+		require.True(t, model.IsErrNotFound(err))
+
+		member, err := store.GetMemberForBoard(testBoardID, testUserID)
+		require.NoError(t, err)
+		require.Empty(t, member.CustomRoleID)
+
+		err = store.DeleteCustomBoardRole(role.ID)
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
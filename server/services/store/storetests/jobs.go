@@ -0,0 +1,137 @@
+package storetests
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestJobStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("SaveAndGetJobs", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testSaveAndGetJobs(t, store)
+	})
+	t.Run("UpdateJobStatus", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testUpdateJobStatus(t, store)
+	})
+	t.Run("CleanUpJobs", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCleanUpJobs(t, store)
+	})
+}
+
+func newTestJob(jobType, status string, runAt int64) *model.Job {
+	return &model.Job{
+		ID:      utils.NewID(utils.IDTypeNone),
+		Type:    jobType,
+		Status:  status,
+		Attempt: 1,
+		RunAt:   runAt,
+	}
+}
+
+func testSaveAndGetJobs(t *testing.T, store store.Store) {
+	now := utils.GetMillis()
+	past := newTestJob("type-1", model.JobStatusSucceeded, now-2000)
+	due := newTestJob("type-1", model.JobStatusPending, now-1000)
+	future := newTestJob("type-1", model.JobStatusPending, now+60000)
+	other := newTestJob("type-2", model.JobStatusPending, now-500)
+	for _, job := range []*model.Job{past, due, future, other} {
+		require.NoError(t, store.SaveJob(job))
+	}
+
+	t.Run("get a job", func(t *testing.T) {
+		job, err := store.GetJob(due.ID)
+		require.NoError(t, err)
+		require.Equal(t, due, job)
+
+		_, err = store.GetJob("missing")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("get the jobs, the most recent first", func(t *testing.T) {
+		jobs, err := store.GetJobs(model.QueryJobsOptions{Type: "type-1"})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Job{future, due, past}, jobs)
+
+		jobs, err = store.GetJobs(model.QueryJobsOptions{Statuses: []string{model.JobStatusPending}, Page: 1, PerPage: 2})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Job{due}, jobs)
+	})
+
+	t.Run("get the due jobs, the oldest first", func(t *testing.T) {
+		jobs, err := store.GetDueJobs(now)
+		require.NoError(t, err)
+		require.Equal(t, []*model.Job{due, other}, jobs)
+	})
+}
+
+func testUpdateJobStatus(t *testing.T, store store.Store) {
+	job := newTestJob("type-1", model.JobStatusPending, utils.GetMillis())
+	require.NoError(t, store.SaveJob(job))
+
+	job.Status = model.JobStatusRunning
+	job.StartAt = utils.GetMillis()
+	updated, err := store.UpdateJobStatus(job, model.JobStatusPending)
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	t.Run("a job can't be claimed twice", func(t *testing.T) {
+		claimed := *job
+		updated, err := store.UpdateJobStatus(&claimed, model.JobStatusPending)
+		require.NoError(t, err)
+		require.False(t, updated)
+	})
+
+	job.Status = model.JobStatusFailed
+	job.FinishAt = utils.GetMillis()
+	job.Error = "failure"
+	updated, err = store.UpdateJobStatus(job, model.JobStatusRunning)
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	saved, err := store.GetJob(job.ID)
+	require.NoError(t, err)
+	require.Equal(t, job, saved)
+
+	t.Run("long errors are truncated without splitting characters", func(t *testing.T) {
+		failed := newTestJob("type-1", model.JobStatusFailed, utils.GetMillis())
+		failed.Error = "x" + strings.Repeat("é", 1024)
+		require.NoError(t, store.SaveJob(failed))
+
+		saved, err := store.GetJob(failed.ID)
+		require.NoError(t, err)
+		require.True(t, utf8.ValidString(saved.Error))
+		require.LessOrEqual(t, len(saved.Error), 1024)
+		require.True(t, strings.HasPrefix(failed.Error, saved.Error))
+	})
+}
+
+func testCleanUpJobs(t *testing.T, store store.Store) {
+	now := utils.GetMillis()
+	old := newTestJob("type-1", model.JobStatusSucceeded, now-5000)
+	old.FinishAt = now - 4000
+	recent := newTestJob("type-1", model.JobStatusFailed, now-2000)
+	recent.FinishAt = now - 1000
+	pending := newTestJob("type-1", model.JobStatusPending, now-5000)
+	for _, job := range []*model.Job{old, recent, pending} {
+		require.NoError(t, store.SaveJob(job))
+	}
+
+	deleted, err := store.CleanUpJobs(now - 2000)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	jobs, err := store.GetJobs(model.QueryJobsOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []*model.Job{recent, pending}, jobs)
+}
@@ -0,0 +1,69 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestLeaseStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("AcquireAndReleaseLease", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testAcquireAndReleaseLease(t, store)
+	})
+}
+
+func testAcquireAndReleaseLease(t *testing.T, store store.Store) {
+	expireAt := utils.GetMillis() + 60*1000
+
+	t.Run("the first holder takes the lease", func(t *testing.T) {
+		acquired, err := store.AcquireLease("lease", "holder-1", expireAt)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+
+	t.Run("the holder renews the lease", func(t *testing.T) {
+		acquired, err := store.AcquireLease("lease", "holder-1", expireAt+1000)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+
+	t.Run("another holder can't take a lease that didn't expire", func(t *testing.T) {
+		acquired, err := store.AcquireLease("lease", "holder-2", expireAt)
+		require.NoError(t, err)
+		require.False(t, acquired)
+
+		acquired, err = store.AcquireLease("other-lease", "holder-2", expireAt)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+
+	t.Run("another holder takes an expired lease", func(t *testing.T) {
+		acquired, err := store.AcquireLease("expiring-lease", "holder-1", utils.GetMillis()-1000)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = store.AcquireLease("expiring-lease", "holder-2", expireAt)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = store.AcquireLease("expiring-lease", "holder-1", expireAt)
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+
+	t.Run("a released lease can be taken", func(t *testing.T) {
+		require.NoError(t, store.ReleaseLease("lease", "holder-2"), "releasing a lease of another holder does nothing")
+		acquired, err := store.AcquireLease("lease", "holder-2", expireAt)
+		require.NoError(t, err)
+		require.False(t, acquired)
+
+		require.NoError(t, store.ReleaseLease("lease", "holder-1"))
+		acquired, err = store.AcquireLease("lease", "holder-2", expireAt)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+}
@@ -0,0 +1,88 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestMfaStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("SaveAndUseMfaRecoveryCodes", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testSaveAndUseMfaRecoveryCodes(t, store)
+	})
+	t.Run("UseMfaTotpCounter", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testUseMfaTotpCounter(t, store)
+	})
+}
+
+func testSaveAndUseMfaRecoveryCodes(t *testing.T, store store.Store) {
+	require.NoError(t, store.SaveMfaRecoveryCodes(testUserID, []string{"hash-1", "hash-2"}))
+	require.NoError(t, store.SaveMfaRecoveryCodes("other-user-id", []string{"hash-3"}))
+
+	t.Run("codes are single use", func(t *testing.T) {
+		used, err := store.UseMfaRecoveryCode(testUserID, "hash-1")
+		require.NoError(t, err)
+		require.True(t, used)
+
+		used, err = store.UseMfaRecoveryCode(testUserID, "hash-1")
+		require.NoError(t, err)
+		require.False(t, used)
+	})
+
+	t.Run("codes belong to a user", func(t *testing.T) {
+		used, err := store.UseMfaRecoveryCode(testUserID, "hash-3")
+		require.NoError(t, err)
+		require.False(t, used)
+	})
+
+	t.Run("saving replaces the existing codes", func(t *testing.T) {
+		require.NoError(t, store.SaveMfaRecoveryCodes(testUserID, []string{"hash-4"}))
+
+		used, err := store.UseMfaRecoveryCode(testUserID, "hash-2")
+		require.NoError(t, err)
+		require.False(t, used)
+
+		used, err = store.UseMfaRecoveryCode(testUserID, "hash-4")
+		require.NoError(t, err)
+		require.True(t, used)
+	})
+
+	t.Run("saving no codes removes them", func(t *testing.T) {
+		require.NoError(t, store.SaveMfaRecoveryCodes("other-user-id", nil))
+
+		used, err := store.UseMfaRecoveryCode("other-user-id", "hash-3")
+		require.NoError(t, err)
+		require.False(t, used)
+	})
+}
+
+func testUseMfaTotpCounter(t *testing.T, store store.Store) {
+	user, err := store.CreateUser(&model.User{ID: testUserID, Username: "mfa-user", Email: "mfa-user@example.com"})
+	require.NoError(t, err)
+
+	t.Run("a time step can be used once", func(t *testing.T) {
+		accepted, err := store.UseMfaTotpCounter(user.ID, 100)
+		require.NoError(t, err)
+		require.True(t, accepted)
+
+		accepted, err = store.UseMfaTotpCounter(user.ID, 100)
+		require.NoError(t, err)
+		require.False(t, accepted)
+	})
+
+	t.Run("earlier time steps are refused", func(t *testing.T) {
+		accepted, err := store.UseMfaTotpCounter(user.ID, 99)
+		require.NoError(t, err)
+		require.False(t, accepted)
+
+		accepted, err = store.UseMfaTotpCounter(user.ID, 101)
+		require.NoError(t, err)
+		require.True(t, accepted)
+	})
+}
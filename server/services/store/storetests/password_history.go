@@ -0,0 +1,52 @@
+package storetests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestPasswordHistoryStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("AddAndGetPasswordHistory", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testAddAndGetPasswordHistory(t, store)
+	})
+}
+
+func testAddAndGetPasswordHistory(t *testing.T, store store.Store) {
+	t.Run("no history", func(t *testing.T) {
+		hashes, err := store.GetPasswordHistory("user-id", 5)
+		require.NoError(t, err)
+		require.Empty(t, hashes)
+	})
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, store.AddPasswordHistory("user-id", fmt.Sprintf("hash-%d", i), 3))
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, store.AddPasswordHistory("other-user-id", "other-hash", 3))
+
+	t.Run("keeps the last passwords", func(t *testing.T) {
+		hashes, err := store.GetPasswordHistory("user-id", 5)
+		require.NoError(t, err)
+		require.Equal(t, []string{"hash-3", "hash-2", "hash-1"}, hashes)
+
+		hashes, err = store.GetPasswordHistory("user-id", 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"hash-3", "hash-2"}, hashes)
+
+		hashes, err = store.GetPasswordHistory("user-id", 0)
+		require.NoError(t, err)
+		require.Empty(t, hashes)
+	})
+
+	t.Run("the history of other users is kept", func(t *testing.T) {
+		hashes, err := store.GetPasswordHistory("other-user-id", 5)
+		require.NoError(t, err)
+		require.Equal(t, []string{"other-hash"}, hashes)
+	})
+}
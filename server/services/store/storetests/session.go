@@ -28,12 +28,20 @@ func StoreTestSessionStore(t *testing.T, setup func(t *testing.T) (store.Store,
 		defer tearDown()
 		testUpdateSession(t, store)
 	})
+
+	t.Run("GetAndDeleteUserSessions", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetAndDeleteUserSessions(t, store)
+	})
 }
 
 func testCreateAndGetAndDeleteSession(t *testing.T, store store.Store) {
 	session := &model.Session{
-		ID:    "session-id",
-		Token: "token",
+		ID:        "session-id",
+		Token:     "token",
+		UserAgent: "Mozilla/5.0",
+		IPAddress: "10.0.0.1",
 	}
 
 	t.Run("CreateAndGetSession", func(t *testing.T) {
@@ -105,3 +113,59 @@ func testUpdateSession(t *testing.T, store store.Store) {
 	require.NoError(t, err)
 	require.Equal(t, session, got)
 }
+
+func testGetAndDeleteUserSessions(t *testing.T, store store.Store) {
+	for i := 0; i < 3; i++ {
+		session := &model.Session{
+			ID:        fmt.Sprintf("session-id-%d", i),
+			Token:     fmt.Sprintf("token-%d", i),
+			UserID:    "user-id",
+			UserAgent: "Mozilla/5.0",
+			IPAddress: "10.0.0.1",
+		}
+		require.NoError(t, store.CreateSession(session))
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, store.CreateSession(&model.Session{ID: "other-session-id", Token: "other-token", UserID: "other-user-id"}))
+
+	t.Run("GetUserSessions", func(t *testing.T) {
+		sessions, err := store.GetUserSessions("user-id", 60)
+		require.NoError(t, err)
+		require.Len(t, sessions, 3)
+
+		// the most recently used first
+		require.Equal(t, "session-id-2", sessions[0].ID)
+		require.Equal(t, "token-2", sessions[0].Token)
+		require.Equal(t, "Mozilla/5.0", sessions[0].UserAgent)
+		require.Equal(t, "10.0.0.1", sessions[0].IPAddress)
+		require.NotZero(t, sessions[0].CreateAt)
+		require.NotZero(t, sessions[0].UpdateAt)
+		require.Equal(t, "session-id-0", sessions[2].ID)
+
+		sessions, err = store.GetUserSessions("nonexistent-user-id", 60)
+		require.NoError(t, err)
+		require.Empty(t, sessions)
+	})
+
+	t.Run("DeleteUserSessions except one", func(t *testing.T) {
+		require.NoError(t, store.DeleteUserSessions("user-id", "session-id-1"))
+
+		sessions, err := store.GetUserSessions("user-id", 60)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.Equal(t, "session-id-1", sessions[0].ID)
+	})
+
+	t.Run("DeleteUserSessions", func(t *testing.T) {
+		require.NoError(t, store.DeleteUserSessions("user-id", ""))
+
+		sessions, err := store.GetUserSessions("user-id", 60)
+		require.NoError(t, err)
+		require.Empty(t, sessions)
+
+		// the sessions of the other users are kept
+		sessions, err = store.GetUserSessions("other-user-id", 60)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+	})
+}
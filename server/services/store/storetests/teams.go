@@ -40,6 +40,30 @@ func StoreTestTeamStore(t *testing.T, setup func(t *testing.T) (store.Store, fun
 		defer tearDown()
 		testGetAllTeams(t, store)
 	})
+
+	t.Run("CreateUpdateAndDeleteTeam", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCreateUpdateAndDeleteTeam(t, store)
+	})
+
+	t.Run("TeamMembers", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testTeamMembers(t, store)
+	})
+
+	t.Run("GetTeamsForUser", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetTeamsForUser(t, store)
+	})
+
+	t.Run("TeamInvites", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testTeamInvites(t, store)
+	})
 }
 
 func testGetTeam(t *testing.T, store store.Store) {
@@ -155,3 +179,213 @@ func testGetAllTeams(t *testing.T, store store.Store) {
 		require.Len(t, got, teamCount)
 	})
 }
+
+func testCreateUpdateAndDeleteTeam(t *testing.T, store store.Store) {
+	team, err := store.CreateTeam(&model.Team{
+		ID:         utils.NewID(utils.IDTypeTeam),
+		Title:      "Engineering",
+		ModifiedBy: "user-id",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, team.SignupToken)
+
+	t.Run("GetTeam", func(t *testing.T) {
+		got, err := store.GetTeam(team.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Engineering", got.Title)
+		require.Equal(t, team.SignupToken, got.SignupToken)
+		require.Equal(t, "user-id", got.ModifiedBy)
+	})
+
+	t.Run("UpdateTeam", func(t *testing.T) {
+		team.Title = "Platform"
+		_, err := store.UpdateTeam(team)
+		require.NoError(t, err)
+
+		teams, err := store.GetAllTeams()
+		require.NoError(t, err)
+		require.Len(t, teams, 1)
+		require.Equal(t, "Platform", teams[0].Title)
+	})
+
+	t.Run("UpdateTeam nonexistent", func(t *testing.T) {
+		_, err := store.UpdateTeam(&model.Team{ID: "nonexistent-id"})
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("DeleteTeam", func(t *testing.T) {
+		_, err := store.SaveTeamMember(&model.TeamMember{TeamID: team.ID, UserID: "user-id"})
+		require.NoError(t, err)
+
+		require.NoError(t, store.DeleteTeam(team.ID))
+
+		_, err = store.GetTeam(team.ID)
+		require.True(t, model.IsErrNotFound(err))
+
+		members, err := store.GetTeamMembersForUser("user-id")
+		require.NoError(t, err)
+		require.Empty(t, members)
+	})
+}
+
+func testTeamMembers(t *testing.T, store store.Store) {
+	teamID := utils.NewID(utils.IDTypeTeam)
+	otherTeamID := utils.NewID(utils.IDTypeTeam)
+
+	t.Run("no members", func(t *testing.T) {
+		members, err := store.GetTeamMembers(teamID)
+		require.NoError(t, err)
+		require.Empty(t, members)
+	})
+
+	t.Run("SaveTeamMember", func(t *testing.T) {
+		_, err := store.SaveTeamMember(&model.TeamMember{TeamID: teamID, UserID: "user-1"})
+		require.NoError(t, err)
+		_, err = store.SaveTeamMember(&model.TeamMember{TeamID: teamID, UserID: "user-2"})
+		require.NoError(t, err)
+		_, err = store.SaveTeamMember(&model.TeamMember{TeamID: otherTeamID, UserID: "user-1"})
+		require.NoError(t, err)
+
+		// saving an existing member updates its roles
+		member, err := store.SaveTeamMember(&model.TeamMember{TeamID: teamID, UserID: "user-2", Roles: "admin"})
+		require.NoError(t, err)
+		require.NotZero(t, member.CreateAt)
+
+		members, err := store.GetTeamMembers(teamID)
+		require.NoError(t, err)
+		require.Len(t, members, 2)
+		for _, member := range members {
+			require.Equal(t, teamID, member.TeamID)
+			if member.UserID == "user-2" {
+				require.Equal(t, "admin", member.Roles)
+			}
+		}
+
+		members, err = store.GetTeamMembersForUser("user-1")
+		require.NoError(t, err)
+		require.Len(t, members, 2)
+	})
+
+	t.Run("GetTeamMember", func(t *testing.T) {
+		member, err := store.GetTeamMember(teamID, "user-2")
+		require.NoError(t, err)
+		require.True(t, member.IsAdmin())
+
+		_, err = store.GetTeamMember(otherTeamID, "user-2")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("DeleteTeamMember", func(t *testing.T) {
+		require.NoError(t, store.DeleteTeamMember(teamID, "user-1"))
+
+		members, err := store.GetTeamMembers(teamID)
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		require.Equal(t, "user-2", members[0].UserID)
+
+		members, err = store.GetTeamMembersForUser("user-1")
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		require.Equal(t, otherTeamID, members[0].TeamID)
+	})
+}
+
+func testGetTeamsForUser(t *testing.T, store store.Store) {
+	require.NoError(t, store.UpsertTeamSignupToken(model.Team{ID: model.GlobalTeamID, SignupToken: "token"}))
+	team1, err := store.CreateTeam(&model.Team{ID: utils.NewID(utils.IDTypeTeam), Title: "Team 1"})
+	require.NoError(t, err)
+	team2, err := store.CreateTeam(&model.Team{ID: utils.NewID(utils.IDTypeTeam), Title: "Team 2"})
+	require.NoError(t, err)
+
+	_, err = store.SaveTeamMember(&model.TeamMember{TeamID: team1.ID, UserID: "user-1"})
+	require.NoError(t, err)
+
+	t.Run("the root team and the teams of the user", func(t *testing.T) {
+		teams, err := store.GetTeamsForUser("user-1")
+		require.NoError(t, err)
+		require.Len(t, teams, 2)
+
+		teamIDs := []string{teams[0].ID, teams[1].ID}
+		require.ElementsMatch(t, []string{model.GlobalTeamID, team1.ID}, teamIDs)
+		require.NotContains(t, teamIDs, team2.ID)
+	})
+
+	t.Run("the root team only", func(t *testing.T) {
+		teams, err := store.GetTeamsForUser("user-2")
+		require.NoError(t, err)
+		require.Len(t, teams, 1)
+		require.Equal(t, model.GlobalTeamID, teams[0].ID)
+	})
+}
+
+func testTeamInvites(t *testing.T, store store.Store) {
+	teamID := utils.NewID(utils.IDTypeTeam)
+	now := utils.GetMillis()
+
+	invite := &model.TeamInvite{
+		TokenHash: "invite-hash",
+		TeamID:    teamID,
+		Email:     "invitee@example.com",
+		Roles:     model.TeamRoleAdmin,
+		InvitedBy: "user-1",
+		ExpireAt:  now + 60*1000,
+	}
+	require.NoError(t, store.SaveTeamInvite(invite))
+
+	expired := &model.TeamInvite{
+		TokenHash: "expired-hash",
+		TeamID:    teamID,
+		Email:     "invitee@example.com",
+		InvitedBy: "user-1",
+		CreateAt:  now - 120*1000,
+		ExpireAt:  now - 60*1000,
+	}
+	require.NoError(t, store.SaveTeamInvite(expired))
+
+	t.Run("GetTeamInvite", func(t *testing.T) {
+		got, err := store.GetTeamInvite("invite-hash")
+		require.NoError(t, err)
+		require.Equal(t, teamID, got.TeamID)
+		require.Equal(t, "invitee@example.com", got.Email)
+		require.Equal(t, model.TeamRoleAdmin, got.Roles)
+		require.NotZero(t, got.CreateAt)
+
+		_, err = store.GetTeamInvite("expired-hash")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("UseTeamInvite", func(t *testing.T) {
+		got, err := store.UseTeamInvite("invite-hash")
+		require.NoError(t, err)
+		require.Equal(t, teamID, got.TeamID)
+
+		// an invite can only be used once
+		_, err = store.UseTeamInvite("invite-hash")
+		require.True(t, model.IsErrNotFound(err))
+
+		_, err = store.UseTeamInvite("expired-hash")
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("CleanUpTeamInvites", func(t *testing.T) {
+		require.NoError(t, store.SaveTeamInvite(&model.TeamInvite{
+			TokenHash: "pending-hash",
+			TeamID:    teamID,
+			Email:     "other@example.com",
+			InvitedBy: "user-1",
+			ExpireAt:  now + 60*1000,
+		}))
+
+		require.NoError(t, store.CleanUpTeamInvites())
+
+		_, err := store.GetTeamInvite("pending-hash")
+		require.NoError(t, err)
+	})
+
+	t.Run("deleting the team deletes its invites", func(t *testing.T) {
+		require.NoError(t, store.DeleteTeam(teamID))
+
+		_, err := store.GetTeamInvite("pending-hash")
+		require.True(t, model.IsErrNotFound(err))
+	})
+}
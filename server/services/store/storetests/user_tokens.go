@@ -0,0 +1,100 @@
+package storetests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestUserTokenStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("UseUserToken", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testUseUserToken(t, store)
+	})
+	t.Run("DeleteUserTokens", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteUserTokens(t, store)
+	})
+	t.Run("CleanUpUserTokens", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCleanUpUserTokens(t, store)
+	})
+}
+
+func newUserToken(hash, tokenType, userID string, expiry time.Duration) *model.UserToken {
+	return &model.UserToken{
+		TokenHash: hash,
+		Type:      tokenType,
+		UserID:    userID,
+		Extra:     "user@example.com",
+		ExpireAt:  utils.GetMillis() + expiry.Milliseconds(),
+	}
+}
+
+func testUseUserToken(t *testing.T, store store.Store) {
+	require.NoError(t, store.SaveUserToken(newUserToken("hash-1", model.UserTokenTypePasswordReset, testUserID, time.Hour)))
+	require.NoError(t, store.SaveUserToken(newUserToken("hash-2", model.UserTokenTypePasswordReset, testUserID, -time.Minute)))
+	require.NoError(t, store.SaveUserToken(newUserToken("hash-3", model.UserTokenTypeEmailVerification, testUserID, time.Hour)))
+
+	t.Run("tokens are single use", func(t *testing.T) {
+		token, err := store.UseUserToken("hash-1", model.UserTokenTypePasswordReset)
+		require.NoError(t, err)
+		require.Equal(t, testUserID, token.UserID)
+		require.Equal(t, "user@example.com", token.Extra)
+		require.NotZero(t, token.CreateAt)
+
+		_, err = store.UseUserToken("hash-1", model.UserTokenTypePasswordReset)
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("expired tokens are not found", func(t *testing.T) {
+		_, err := store.UseUserToken("hash-2", model.UserTokenTypePasswordReset)
+		require.True(t, model.IsErrNotFound(err))
+	})
+
+	t.Run("tokens have a type", func(t *testing.T) {
+		_, err := store.UseUserToken("hash-3", model.UserTokenTypePasswordReset)
+		require.True(t, model.IsErrNotFound(err))
+
+		token, err := store.UseUserToken("hash-3", model.UserTokenTypeEmailVerification)
+		require.NoError(t, err)
+		require.Equal(t, model.UserTokenTypeEmailVerification, token.Type)
+	})
+}
+
+func testDeleteUserTokens(t *testing.T, store store.Store) {
+	require.NoError(t, store.SaveUserToken(newUserToken("hash-1", model.UserTokenTypePasswordReset, testUserID, time.Hour)))
+	require.NoError(t, store.SaveUserToken(newUserToken("hash-2", model.UserTokenTypeEmailVerification, testUserID, time.Hour)))
+	require.NoError(t, store.SaveUserToken(newUserToken("hash-3", model.UserTokenTypePasswordReset, "other-user-id", time.Hour)))
+
+	require.NoError(t, store.DeleteUserTokens(testUserID, model.UserTokenTypePasswordReset))
+
+	_, err := store.UseUserToken("hash-1", model.UserTokenTypePasswordReset)
+	require.True(t, model.IsErrNotFound(err))
+
+	_, err = store.UseUserToken("hash-2", model.UserTokenTypeEmailVerification)
+	require.NoError(t, err)
+
+	_, err = store.UseUserToken("hash-3", model.UserTokenTypePasswordReset)
+	require.NoError(t, err)
+}
+
+func testCleanUpUserTokens(t *testing.T, store store.Store) {
+	require.NoError(t, store.SaveUserToken(newUserToken("hash-1", model.UserTokenTypePasswordReset, testUserID, time.Hour)))
+	require.NoError(t, store.SaveUserToken(newUserToken("hash-2", model.UserTokenTypePasswordReset, testUserID, -time.Minute)))
+
+	require.NoError(t, store.CleanUpUserTokens())
+
+	// the expired token is deleted, so it can be saved again
+	require.NoError(t, store.SaveUserToken(newUserToken("hash-2", model.UserTokenTypePasswordReset, testUserID, time.Hour)))
+
+	_, err := store.UseUserToken("hash-1", model.UserTokenTypePasswordReset)
+	require.NoError(t, err)
+}
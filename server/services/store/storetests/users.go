@@ -23,6 +23,12 @@ func StoreTestUserStore(t *testing.T, setup func(t *testing.T) (store.Store, fun
 		testGetUsersByTeam(t, store)
 	})
 
+	t.Run("GuestUsers", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGuestUsers(t, store)
+	})
+
 	t.Run("CreateAndGetUser", func(t *testing.T) {
 		store, tearDown := setup(t)
 		defer tearDown()
@@ -41,6 +47,12 @@ func StoreTestUserStore(t *testing.T, setup func(t *testing.T) (store.Store, fun
 		testCreateAndUpdateUser(t, store)
 	})
 
+	t.Run("QueryUsers", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testQueryUsers(t, store)
+	})
+
 	t.Run("CreateAndGetRegisteredUserCount", func(t *testing.T) {
 		store, tearDown := setup(t)
 		defer tearDown()
@@ -78,10 +90,98 @@ func testGetUsersByTeam(t *testing.T, store store.Store) {
 			})
 		}()
 
+		// all the users belong to the root team
+		users, err = store.GetUsersByTeam(model.GlobalTeamID, "", false, false)
+		require.Equal(t, 1, len(users))
+		require.NoError(t, err)
+
+		users, err = store.GetUsersByTeam("team_1", "", false, false)
+		require.Equal(t, 0, len(users))
+		require.NoError(t, err)
+
+		_, err = store.SaveTeamMember(&model.TeamMember{TeamID: "team_1", UserID: userID})
+		require.NoError(t, err)
+		defer func() {
+			_ = store.DeleteTeamMember("team_1", userID)
+		}()
+
 		users, err = store.GetUsersByTeam("team_1", "", false, false)
 		require.Equal(t, 1, len(users))
 		require.Equal(t, "darth.vader", users[0].Username)
 		require.NoError(t, err)
+
+		users, err = store.SearchUsersByTeam("team_1", "vader", "", false, false, false)
+		require.Equal(t, 1, len(users))
+		require.NoError(t, err)
+
+		users, err = store.SearchUsersByTeam("team_2", "vader", "", false, false, false)
+		require.Equal(t, 0, len(users))
+		require.NoError(t, err)
+	})
+}
+
+func testGuestUsers(t *testing.T, store store.Store) {
+	guest, err := store.CreateUser(&model.User{
+		ID:       utils.NewID(utils.IDTypeUser),
+		Username: "guest",
+		IsGuest:  true,
+	})
+	require.NoError(t, err)
+	colleague, err := store.CreateUser(&model.User{
+		ID:       utils.NewID(utils.IDTypeUser),
+		Username: "colleague",
+	})
+	require.NoError(t, err)
+	stranger, err := store.CreateUser(&model.User{
+		ID:       utils.NewID(utils.IDTypeUser),
+		Username: "stranger",
+	})
+	require.NoError(t, err)
+
+	boardID := utils.NewID(utils.IDTypeBoard)
+	for _, userID := range []string{guest.ID, colleague.ID} {
+		_, err = store.SaveMember(&model.BoardMember{BoardID: boardID, UserID: userID, SchemeViewer: true})
+		require.NoError(t, err)
+	}
+
+	t.Run("the guest flag is stored", func(t *testing.T) {
+		user, err := store.GetUserByID(guest.ID)
+		require.NoError(t, err)
+		require.True(t, user.IsGuest)
+
+		user.IsGuest = false
+		_, err = store.UpdateUser(user)
+		require.NoError(t, err)
+		user, err = store.GetUserByID(guest.ID)
+		require.NoError(t, err)
+		require.False(t, user.IsGuest)
+
+		user.IsGuest = true
+		_, err = store.UpdateUser(user)
+		require.NoError(t, err)
+	})
+
+	t.Run("guests only see the users they share a board with", func(t *testing.T) {
+		users, err := store.GetUsersByTeam(model.GlobalTeamID, guest.ID, false, false)
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		require.ElementsMatch(t, []string{guest.ID, colleague.ID}, []string{users[0].ID, users[1].ID})
+
+		users, err = store.SearchUsersByTeam(model.GlobalTeamID, "stranger", guest.ID, false, false, false)
+		require.NoError(t, err)
+		require.Empty(t, users)
+
+		users, err = store.GetUsersByTeam(model.GlobalTeamID, "", false, false)
+		require.NoError(t, err)
+		require.Len(t, users, 3)
+
+		canSee, err := store.CanSeeUser(guest.ID, colleague.ID)
+		require.NoError(t, err)
+		require.True(t, canSee)
+
+		canSee, err = store.CanSeeUser(guest.ID, stranger.ID)
+		require.NoError(t, err)
+		require.False(t, canSee)
 	})
 }
 
@@ -142,6 +242,39 @@ func testCreateAndGetUser(t *testing.T, store store.Store) {
 		require.ErrorAs(t, err, &nf)
 		require.Nil(t, got)
 	})
+
+	t.Run("GetUserByAuthData", func(t *testing.T) {
+		externalUser := &model.User{
+			ID:          utils.NewID(utils.IDTypeUser),
+			Username:    "external",
+			Email:       "external@email.com",
+			AuthService: "oidc",
+			AuthData:    "subject-id",
+			Roles:       model.SystemAdminRoleID,
+		}
+		_, err := store.CreateUser(externalUser)
+		require.NoError(t, err)
+
+		got, err := store.GetUserByAuthData("oidc", "subject-id")
+		require.NoError(t, err)
+		require.Equal(t, externalUser.ID, got.ID)
+		require.Equal(t, model.SystemAdminRoleID, got.Roles)
+
+		got, err = store.GetUserByAuthData("other-service", "subject-id")
+		require.True(t, model.IsErrNotFound(err))
+		require.Nil(t, got)
+
+		t.Run("deactivated users are found too", func(t *testing.T) {
+			externalUser.DeleteAt = utils.GetMillis()
+			_, err := store.UpdateUser(externalUser)
+			require.NoError(t, err)
+
+			got, err := store.GetUserByAuthData("oidc", "subject-id")
+			require.NoError(t, err)
+			require.Equal(t, externalUser.ID, got.ID)
+			require.NotZero(t, got.DeleteAt)
+		})
+	})
 }
 
 func testGetUsersList(t *testing.T, store store.Store) {
@@ -212,6 +345,11 @@ func testCreateAndUpdateUser(t *testing.T, store store.Store) {
 	t.Run("UpdateUser", func(t *testing.T) {
 		user.Username = "damao"
 		user.Email = "mock@email.com"
+		user.Roles = model.SystemUserRoleID
+		user.Nickname = "Mao"
+		user.FirstName = "Da"
+		user.LastName = "Mao"
+		user.EmailVerified = true
 		uUser, err := store.UpdateUser(user)
 		require.NoError(t, err)
 		require.NotNil(t, uUser)
@@ -223,6 +361,11 @@ func testCreateAndUpdateUser(t *testing.T, store store.Store) {
 		require.Equal(t, user.ID, got.ID)
 		require.Equal(t, user.Username, got.Username)
 		require.Equal(t, user.Email, got.Email)
+		require.Equal(t, user.Roles, got.Roles)
+		require.Equal(t, user.Nickname, got.Nickname)
+		require.Equal(t, user.FirstName, got.FirstName)
+		require.Equal(t, user.LastName, got.LastName)
+		require.True(t, got.EmailVerified)
 	})
 
 	t.Run("UpdateUserPassword", func(t *testing.T) {
@@ -234,17 +377,110 @@ func testCreateAndUpdateUser(t *testing.T, store store.Store) {
 		require.NoError(t, err)
 		require.Equal(t, user.Username, got.Username)
 		require.Equal(t, newPassword, got.Password)
+		require.NotZero(t, got.PasswordUpdateAt)
 	})
 
 	t.Run("UpdateUserPasswordByID", func(t *testing.T) {
+		previous, err := store.GetUserByID(user.ID)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+
 		newPassword := utils.NewID(utils.IDTypeNone)
-		err := store.UpdateUserPasswordByID(user.ID, newPassword)
+		err = store.UpdateUserPasswordByID(user.ID, newPassword)
 		require.NoError(t, err)
 
 		got, err := store.GetUserByID(user.ID)
 		require.NoError(t, err)
 		require.Equal(t, user.ID, got.ID)
 		require.Equal(t, newPassword, got.Password)
+		require.Greater(t, got.PasswordUpdateAt, previous.PasswordUpdateAt)
+	})
+
+	t.Run("UpdateUserMfa", func(t *testing.T) {
+		got, err := store.GetUserByID(user.ID)
+		require.NoError(t, err)
+		require.False(t, got.MfaActive)
+
+		err = store.UpdateUserMfa(user.ID, "mfa-secret", true)
+		require.NoError(t, err)
+
+		got, err = store.GetUserByID(user.ID)
+		require.NoError(t, err)
+		require.Equal(t, "mfa-secret", got.MfaSecret)
+		require.True(t, got.MfaActive)
+
+		err = store.UpdateUserMfa("nonexistent-user-id", "mfa-secret", true)
+		require.Error(t, err)
+	})
+}
+
+func testQueryUsers(t *testing.T, store store.Store) {
+	users := []*model.User{}
+	for i := 0; i < 3; i++ {
+		user, err := store.CreateUser(&model.User{
+			ID:       utils.NewID(utils.IDTypeUser),
+			Username: fmt.Sprintf("query-user-%d", i),
+			Email:    fmt.Sprintf("query-user-%d@example.com", i),
+		})
+		require.NoError(t, err)
+		users = append(users, user)
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	// deactivated users are included
+	users[1].DeleteAt = utils.GetMillis()
+	_, err := store.UpdateUser(users[1])
+	require.NoError(t, err)
+
+	_, err = store.GetUserByID(users[1].ID)
+	require.True(t, model.IsErrNotFound(err))
+
+	t.Run("all users", func(t *testing.T) {
+		got, count, err := store.QueryUsers(model.QueryUsersOptions{})
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		require.Len(t, got, 3)
+		require.Equal(t, users[0].ID, got[0].ID)
+		require.Equal(t, users[1].ID, got[1].ID)
+		require.NotZero(t, got[1].DeleteAt)
+	})
+
+	t.Run("paginated", func(t *testing.T) {
+		got, count, err := store.QueryUsers(model.QueryUsersOptions{Offset: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		require.Len(t, got, 1)
+		require.Equal(t, users[1].ID, got[0].ID)
+	})
+
+	t.Run("by username", func(t *testing.T) {
+		got, count, err := store.QueryUsers(model.QueryUsersOptions{Username: users[2].Username})
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Len(t, got, 1)
+		require.Equal(t, users[2].ID, got[0].ID)
+	})
+
+	t.Run("by email and ID", func(t *testing.T) {
+		got, count, err := store.QueryUsers(model.QueryUsersOptions{UserID: users[1].ID, Email: users[1].Email})
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Equal(t, users[1].ID, got[0].ID)
+
+		got, count, err = store.QueryUsers(model.QueryUsersOptions{UserID: users[0].ID, Email: users[1].Email})
+		require.NoError(t, err)
+		require.Zero(t, count)
+		require.Empty(t, got)
+	})
+
+	t.Run("reactivate", func(t *testing.T) {
+		users[1].DeleteAt = 0
+		_, err := store.UpdateUser(users[1])
+		require.NoError(t, err)
+
+		got, err := store.GetUserByID(users[1].ID)
+		require.NoError(t, err)
+		require.Zero(t, got.DeleteAt)
 	})
 }
 
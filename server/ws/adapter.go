@@ -1,4 +1,5 @@
 //go:generate mockgen -destination=mocks/mockstore.go -package mocks . Store
+//go:generate mockgen -destination=mocks/mockadapter.go -package mocks . Adapter
 package ws
 
 import (
@@ -9,12 +10,29 @@ const (
 	websocketActionAuth                     = "AUTH"
 	websocketActionSubscribeTeam            = "SUBSCRIBE_TEAM"
 	websocketActionUnsubscribeTeam          = "UNSUBSCRIBE_TEAM"
+	websocketActionSubscribeBoard           = "SUBSCRIBE_BOARD"
+	websocketActionUnsubscribeBoard         = "UNSUBSCRIBE_BOARD"
 	websocketActionSubscribeBlocks          = "SUBSCRIBE_BLOCKS"
 	websocketActionUnsubscribeBlocks        = "UNSUBSCRIBE_BLOCKS"
+	websocketActionResume                   = "RESUME"
+	websocketActionResync                   = "RESYNC"
+	websocketActionFocusCard                = "FOCUS_CARD"
+	websocketActionHeartbeat                = "HEARTBEAT"
+	websocketActionBoardPresence            = "BOARD_PRESENCE"
+	websocketActionBoardViewers             = "BOARD_VIEWERS"
+	websocketActionAccessRevoked            = "ACCESS_REVOKED"
+	websocketActionJoinTextBlock            = "JOIN_TEXT_BLOCK"
+	websocketActionLeaveTextBlock           = "LEAVE_TEXT_BLOCK"
+	websocketActionEditTextBlock            = "EDIT_TEXT_BLOCK"
+	websocketActionTextBlockState           = "TEXT_BLOCK_STATE"
+	websocketActionTextBlockOperation       = "TEXT_BLOCK_OPERATION"
+	websocketActionTextBlockAck             = "TEXT_BLOCK_ACK"
+	websocketActionTextBlockClosed          = "TEXT_BLOCK_CLOSED"
 	websocketActionUpdateBoard              = "UPDATE_BOARD"
 	websocketActionUpdateMember             = "UPDATE_MEMBER"
 	websocketActionDeleteMember             = "DELETE_MEMBER"
 	websocketActionUpdateBlock              = "UPDATE_BLOCK"
+	websocketActionUpdateBlocks             = "UPDATE_BLOCKS"
 	websocketActionUpdateConfig             = "UPDATE_CLIENT_CONFIG"
 	websocketActionUpdateCategory           = "UPDATE_CATEGORY"
 	websocketActionUpdateCategoryBoard      = "UPDATE_BOARD_CATEGORY"
@@ -26,7 +44,11 @@ const (
 
 type Store interface {
 	GetBlock(blockID string) (*model.Block, error)
+	GetBoard(boardID string) (*model.Board, error)
+	GetMemberForBoard(boardID, userID string) (*model.BoardMember, error)
 	GetMembersForBoard(boardID string) ([]*model.BoardMember, error)
+	AcquireLease(name, holderID string, expireAt int64) (bool, error)
+	ReleaseLease(name, holderID string) error
 }
 
 type Adapter interface {
@@ -43,4 +65,6 @@ type Adapter interface {
 	BroadcastSubscriptionChange(teamID string, subscription *model.Subscription)
 	BroadcastCategoryReorder(teamID, userID string, categoryOrder []string)
 	BroadcastCategoryBoardsReorder(teamID, userID, categoryID string, boardsOrder []string)
+	CloseSessions(sessionIDs ...string)
+	RevokeTeamAccess(teamID, userID string)
 }
@@ -0,0 +1,235 @@
+package ws
+
+import (
+	"encoding/json"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/cluster"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	clusterEventBlockChange              = "block_change"
+	clusterEventBlockDelete              = "block_delete"
+	clusterEventBoardChange              = "board_change"
+	clusterEventBoardDelete              = "board_delete"
+	clusterEventMemberChange             = "member_change"
+	clusterEventMemberDelete             = "member_delete"
+	clusterEventConfigChange             = "config_change"
+	clusterEventCategoryChange           = "category_change"
+	clusterEventCategoryBoardChange      = "category_board_change"
+	clusterEventCardLimitTimestampChange = "card_limit_timestamp_change"
+	clusterEventSubscriptionChange       = "subscription_change"
+	clusterEventCategoryReorder          = "category_reorder"
+	clusterEventCategoryBoardsReorder    = "category_boards_reorder"
+	clusterEventCloseSessions            = "close_sessions"
+	clusterEventRevokeTeamAccess         = "revoke_team_access"
+)
+
+// clusterEvent is a call to an Adapter method, as relayed to the other
+// nodes of the cluster.
+type clusterEvent struct {
+	Event      string `json:"event"`
+	TeamID     string `json:"teamId,omitempty"`
+	BoardID    string `json:"boardId,omitempty"`
+	BlockID    string `json:"blockId,omitempty"`
+	UserID     string `json:"userId,omitempty"`
+	CategoryID string `json:"categoryId,omitempty"`
+
+	Block              *model.Block                        `json:"block,omitempty"`
+	Board              *model.Board                        `json:"board,omitempty"`
+	Member             *model.BoardMember                  `json:"member,omitempty"`
+	ClientConfig       *model.ClientConfig                 `json:"clientConfig,omitempty"`
+	Category           *model.Category                     `json:"category,omitempty"`
+	BoardCategories    []*model.BoardCategoryWebsocketData `json:"boardCategories,omitempty"`
+	Subscription       *model.Subscription                 `json:"subscription,omitempty"`
+	Order              []string                            `json:"order,omitempty"`
+	CardLimitTimestamp int64                               `json:"cardLimitTimestamp,omitempty"`
+	SessionIDs         []string                            `json:"sessionIds,omitempty"`
+}
+
+// ClusterAdapter is an Adapter that relays its calls to the other nodes
+// of a standalone cluster through a bus, so that the clients connected
+// to any node receive the changes made on every node. The calls received
+// from the other nodes are applied to the wrapped adapter.
+//
+// Event sequences are kept by each node, under an epoch of their own that
+// the clients echo when resuming, so a client resuming on another node
+// than the one it was connected to is asked to resync.
+type ClusterAdapter struct {
+	adapter Adapter
+	bus     cluster.Bus
+	logger  mlog.LoggerIFace
+}
+
+// NewClusterAdapter creates an adapter that relays the calls to the
+// wrapped adapter through the bus.
+func NewClusterAdapter(adapter Adapter, bus cluster.Bus, logger mlog.LoggerIFace) *ClusterAdapter {
+	return &ClusterAdapter{
+		adapter: adapter,
+		bus:     bus,
+		logger:  logger,
+	}
+}
+
+// Start begins applying the events of the other nodes.
+func (ca *ClusterAdapter) Start() error {
+	return ca.bus.Start(ca.handleClusterEvent)
+}
+
+// Shutdown stops applying the events of the other nodes.
+func (ca *ClusterAdapter) Shutdown() error {
+	return ca.bus.Shutdown()
+}
+
+// RegisterRoutes registers the routes of the wrapped adapter, if any.
+func (ca *ClusterAdapter) RegisterRoutes(r *mux.Router) {
+	if routed, ok := ca.adapter.(interface{ RegisterRoutes(*mux.Router) }); ok {
+		routed.RegisterRoutes(r)
+	}
+}
+
+func (ca *ClusterAdapter) publish(event *clusterEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		ca.logger.Error("couldn't get JSON bytes from cluster event",
+			mlog.String("event", event.Event),
+			mlog.Err(err),
+		)
+		return
+	}
+
+	if err := ca.bus.Publish(data); err != nil {
+		ca.logger.Error("error publishing cluster event",
+			mlog.String("event", event.Event),
+			mlog.Err(err),
+		)
+	}
+}
+
+func (ca *ClusterAdapter) handleClusterEvent(data []byte) {
+	var event clusterEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		ca.logger.Error("cannot unmarshal cluster event", mlog.Err(err))
+		return
+	}
+
+	ca.logger.Debug("received cluster event", mlog.String("event", event.Event))
+
+	switch event.Event {
+	case clusterEventBlockChange:
+		ca.adapter.BroadcastBlockChange(event.TeamID, event.Block)
+	case clusterEventBlockDelete:
+		ca.adapter.BroadcastBlockDelete(event.TeamID, event.BlockID, event.BoardID)
+	case clusterEventBoardChange:
+		ca.adapter.BroadcastBoardChange(event.TeamID, event.Board)
+	case clusterEventBoardDelete:
+		ca.adapter.BroadcastBoardDelete(event.TeamID, event.BoardID)
+	case clusterEventMemberChange:
+		ca.adapter.BroadcastMemberChange(event.TeamID, event.BoardID, event.Member)
+	case clusterEventMemberDelete:
+		ca.adapter.BroadcastMemberDelete(event.TeamID, event.BoardID, event.UserID)
+	case clusterEventConfigChange:
+		if event.ClientConfig != nil {
+			ca.adapter.BroadcastConfigChange(*event.ClientConfig)
+		}
+	case clusterEventCategoryChange:
+		if event.Category != nil {
+			ca.adapter.BroadcastCategoryChange(*event.Category)
+		}
+	case clusterEventCategoryBoardChange:
+		ca.adapter.BroadcastCategoryBoardChange(event.TeamID, event.UserID, event.BoardCategories)
+	case clusterEventCardLimitTimestampChange:
+		ca.adapter.BroadcastCardLimitTimestampChange(event.CardLimitTimestamp)
+	case clusterEventSubscriptionChange:
+		ca.adapter.BroadcastSubscriptionChange(event.TeamID, event.Subscription)
+	case clusterEventCategoryReorder:
+		ca.adapter.BroadcastCategoryReorder(event.TeamID, event.UserID, event.Order)
+	case clusterEventCategoryBoardsReorder:
+		ca.adapter.BroadcastCategoryBoardsReorder(event.TeamID, event.UserID, event.CategoryID, event.Order)
+	case clusterEventCloseSessions:
+		ca.adapter.CloseSessions(event.SessionIDs...)
+	case clusterEventRevokeTeamAccess:
+		ca.adapter.RevokeTeamAccess(event.TeamID, event.UserID)
+	default:
+		ca.logger.Warn("unknown cluster event", mlog.String("event", event.Event))
+	}
+}
+
+func (ca *ClusterAdapter) BroadcastBlockChange(teamID string, block *model.Block) {
+	ca.adapter.BroadcastBlockChange(teamID, block)
+	ca.publish(&clusterEvent{Event: clusterEventBlockChange, TeamID: teamID, Block: block})
+}
+
+func (ca *ClusterAdapter) BroadcastBlockDelete(teamID, blockID, boardID string) {
+	ca.adapter.BroadcastBlockDelete(teamID, blockID, boardID)
+	ca.publish(&clusterEvent{Event: clusterEventBlockDelete, TeamID: teamID, BlockID: blockID, BoardID: boardID})
+}
+
+func (ca *ClusterAdapter) BroadcastBoardChange(teamID string, board *model.Board) {
+	ca.adapter.BroadcastBoardChange(teamID, board)
+	ca.publish(&clusterEvent{Event: clusterEventBoardChange, TeamID: teamID, Board: board})
+}
+
+func (ca *ClusterAdapter) BroadcastBoardDelete(teamID, boardID string) {
+	ca.adapter.BroadcastBoardDelete(teamID, boardID)
+	ca.publish(&clusterEvent{Event: clusterEventBoardDelete, TeamID: teamID, BoardID: boardID})
+}
+
+func (ca *ClusterAdapter) BroadcastMemberChange(teamID, boardID string, member *model.BoardMember) {
+	ca.adapter.BroadcastMemberChange(teamID, boardID, member)
+	ca.publish(&clusterEvent{Event: clusterEventMemberChange, TeamID: teamID, BoardID: boardID, Member: member})
+}
+
+func (ca *ClusterAdapter) BroadcastMemberDelete(teamID, boardID, userID string) {
+	ca.adapter.BroadcastMemberDelete(teamID, boardID, userID)
+	ca.publish(&clusterEvent{Event: clusterEventMemberDelete, TeamID: teamID, BoardID: boardID, UserID: userID})
+}
+
+func (ca *ClusterAdapter) BroadcastConfigChange(clientConfig model.ClientConfig) {
+	ca.adapter.BroadcastConfigChange(clientConfig)
+	ca.publish(&clusterEvent{Event: clusterEventConfigChange, ClientConfig: &clientConfig})
+}
+
+func (ca *ClusterAdapter) BroadcastCategoryChange(category model.Category) {
+	ca.adapter.BroadcastCategoryChange(category)
+	ca.publish(&clusterEvent{Event: clusterEventCategoryChange, Category: &category})
+}
+
+func (ca *ClusterAdapter) BroadcastCategoryBoardChange(teamID, userID string, boardCategories []*model.BoardCategoryWebsocketData) {
+	ca.adapter.BroadcastCategoryBoardChange(teamID, userID, boardCategories)
+	ca.publish(&clusterEvent{Event: clusterEventCategoryBoardChange, TeamID: teamID, UserID: userID, BoardCategories: boardCategories})
+}
+
+func (ca *ClusterAdapter) BroadcastCardLimitTimestampChange(cardLimitTimestamp int64) {
+	ca.adapter.BroadcastCardLimitTimestampChange(cardLimitTimestamp)
+	ca.publish(&clusterEvent{Event: clusterEventCardLimitTimestampChange, CardLimitTimestamp: cardLimitTimestamp})
+}
+
+func (ca *ClusterAdapter) BroadcastSubscriptionChange(teamID string, subscription *model.Subscription) {
+	ca.adapter.BroadcastSubscriptionChange(teamID, subscription)
+	ca.publish(&clusterEvent{Event: clusterEventSubscriptionChange, TeamID: teamID, Subscription: subscription})
+}
+
+func (ca *ClusterAdapter) BroadcastCategoryReorder(teamID, userID string, categoryOrder []string) {
+	ca.adapter.BroadcastCategoryReorder(teamID, userID, categoryOrder)
+	ca.publish(&clusterEvent{Event: clusterEventCategoryReorder, TeamID: teamID, UserID: userID, Order: categoryOrder})
+}
+
+func (ca *ClusterAdapter) BroadcastCategoryBoardsReorder(teamID, userID, categoryID string, boardsOrder []string) {
+	ca.adapter.BroadcastCategoryBoardsReorder(teamID, userID, categoryID, boardsOrder)
+	ca.publish(&clusterEvent{Event: clusterEventCategoryBoardsReorder, TeamID: teamID, UserID: userID, CategoryID: categoryID, Order: boardsOrder})
+}
+
+func (ca *ClusterAdapter) CloseSessions(sessionIDs ...string) {
+	ca.adapter.CloseSessions(sessionIDs...)
+	ca.publish(&clusterEvent{Event: clusterEventCloseSessions, SessionIDs: sessionIDs})
+}
+
+func (ca *ClusterAdapter) RevokeTeamAccess(teamID, userID string) {
+	ca.adapter.RevokeTeamAccess(teamID, userID)
+	ca.publish(&clusterEvent{Event: clusterEventRevokeTeamAccess, TeamID: teamID, UserID: userID})
+}
@@ -0,0 +1,207 @@
+package ws
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
+	wsMocks "github.com/mattermost/focalboard/server/ws/mocks"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// localBus delivers the messages synchronously to the other buses of the
+// same cluster.
+type localBus struct {
+	cluster *[]*localBus
+	handler func(data []byte)
+}
+
+func newLocalCluster(size int) []*localBus {
+	buses := make([]*localBus, 0, size)
+	for i := 0; i < size; i++ {
+		buses = append(buses, &localBus{cluster: &buses})
+	}
+	return buses
+}
+
+func (b *localBus) Start(handler func(data []byte)) error {
+	b.handler = handler
+	return nil
+}
+
+func (b *localBus) Publish(data []byte) error {
+	for _, peer := range *b.cluster {
+		if peer != b && peer.handler != nil {
+			peer.handler(data)
+		}
+	}
+	return nil
+}
+
+func (b *localBus) Shutdown() error {
+	b.handler = nil
+	return nil
+}
+
+func TestClusterAdapter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := mlog.CreateConsoleTestLogger(t)
+	buses := newLocalCluster(2)
+	inner1 := wsMocks.NewMockAdapter(ctrl)
+	inner2 := wsMocks.NewMockAdapter(ctrl)
+	node1 := NewClusterAdapter(inner1, buses[0], logger)
+	node2 := NewClusterAdapter(inner2, buses[1], logger)
+	require.NoError(t, node1.Start())
+	require.NoError(t, node2.Start())
+
+	t.Run("block changes are relayed", func(t *testing.T) {
+		block := &model.Block{ID: "block-1", BoardID: "board-1", Title: "title"}
+		inner1.EXPECT().BroadcastBlockChange("team-1", block)
+		inner2.EXPECT().BroadcastBlockChange("team-1", block)
+
+		node1.BroadcastBlockChange("team-1", block)
+	})
+
+	t.Run("config changes are relayed", func(t *testing.T) {
+		clientConfig := model.ClientConfig{Telemetry: true, FeatureFlags: map[string]string{"flag": "on"}}
+		inner2.EXPECT().BroadcastConfigChange(clientConfig)
+		inner1.EXPECT().BroadcastConfigChange(clientConfig)
+
+		node2.BroadcastConfigChange(clientConfig)
+	})
+
+	t.Run("categories and orders are relayed", func(t *testing.T) {
+		category := model.Category{ID: "category-1", TeamID: "team-1", UserID: "user-1"}
+		inner1.EXPECT().BroadcastCategoryChange(category)
+		inner2.EXPECT().BroadcastCategoryChange(category)
+		node1.BroadcastCategoryChange(category)
+
+		order := []string{"board-2", "board-1"}
+		inner1.EXPECT().BroadcastCategoryBoardsReorder("team-1", "user-1", "category-1", order)
+		inner2.EXPECT().BroadcastCategoryBoardsReorder("team-1", "user-1", "category-1", order)
+		node1.BroadcastCategoryBoardsReorder("team-1", "user-1", "category-1", order)
+	})
+
+	t.Run("sessions and access revocations are relayed", func(t *testing.T) {
+		inner1.EXPECT().CloseSessions("session-1", "session-2")
+		inner2.EXPECT().CloseSessions("session-1", "session-2")
+		node1.CloseSessions("session-1", "session-2")
+
+		inner1.EXPECT().RevokeTeamAccess("team-1", "user-1")
+		inner2.EXPECT().RevokeTeamAccess("team-1", "user-1")
+		node2.RevokeTeamAccess("team-1", "user-1")
+	})
+
+	t.Run("unknown and invalid events are ignored", func(t *testing.T) {
+		node1.handleClusterEvent([]byte(`{"event":"unknown"}`))
+		node1.handleClusterEvent([]byte(`not json`))
+	})
+
+	t.Run("events aren't applied once shut down", func(t *testing.T) {
+		require.NoError(t, node2.Shutdown())
+		inner1.EXPECT().BroadcastBoardDelete("team-1", "board-1")
+
+		node1.BroadcastBoardDelete("team-1", "board-1")
+	})
+}
+
+func TestClusterResume(t *testing.T) {
+	singleUserToken := "single-user-token"
+	teamID := "team-id"
+	logger := mlog.CreateConsoleTestLogger(t)
+	buses := newLocalCluster(2)
+
+	type node struct {
+		server  *Server
+		adapter *ClusterAdapter
+		url     string
+	}
+	nodes := make([]node, 0, len(buses))
+	for _, bus := range buses {
+		server := NewServer(&auth.Auth{}, singleUserToken, false, logger, nil, nil)
+		adapter := NewClusterAdapter(server, bus, logger)
+		require.NoError(t, adapter.Start())
+
+		router := mux.NewRouter()
+		server.RegisterRoutes(router)
+		httpServer := httptest.NewServer(router)
+		t.Cleanup(httpServer.Close)
+
+		nodes = append(nodes, node{server: server, adapter: adapter, url: "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"})
+	}
+
+	resume := func(n node, epoch string, seq int64) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(n.url, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: singleUserToken}))
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionResume, TeamID: teamID, Epoch: epoch, Sequence: seq}))
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		require.Eventually(t, func() bool {
+			n.server.mu.RLock()
+			defer n.server.mu.RUnlock()
+			return len(n.server.listenersByTeam[teamID]) > 0
+		}, time.Second, 10*time.Millisecond)
+		return conn
+	}
+
+	broadcast := func(categoryID string) {
+		nodes[0].adapter.BroadcastCategoryChange(model.Category{ID: categoryID, TeamID: teamID, UserID: model.SingleUser})
+	}
+
+	readCategory := func(conn *websocket.Conn) UpdateCategoryMessage {
+		var message UpdateCategoryMessage
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionUpdateCategory, message.Action)
+		return message
+	}
+
+	// both nodes number the events, with overlapping sequences
+	conn1 := resume(nodes[0], "", 0)
+	conn2 := resume(nodes[1], "", 0)
+	broadcast("category-1")
+	broadcast("category-2")
+	first := readCategory(conn1)
+	readCategory(conn1)
+	require.Equal(t, "category-1", readCategory(conn2).Category.ID)
+	other := readCategory(conn2)
+	require.NotEqual(t, first.Epoch, other.Epoch)
+
+	t.Run("a client resuming on another node resyncs", func(t *testing.T) {
+		conn := resume(nodes[1], first.Epoch, first.Sequence)
+
+		var message ResyncMsg
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionResync, message.Action)
+		require.Equal(t, other.Epoch, message.Epoch)
+		require.Equal(t, other.Sequence, message.Sequence)
+
+		t.Run("and resumes on it after the resync", func(t *testing.T) {
+			broadcast("category-3")
+			readCategory(conn)
+
+			conn := resume(nodes[1], message.Epoch, message.Sequence)
+			replayed := readCategory(conn)
+			require.Equal(t, "category-3", replayed.Category.ID)
+			require.Equal(t, other.Epoch, replayed.Epoch)
+		})
+	})
+
+	t.Run("a client resuming on the same node gets the missed events", func(t *testing.T) {
+		conn := resume(nodes[0], first.Epoch, first.Sequence)
+		require.Equal(t, "category-2", readCategory(conn).Category.ID)
+		require.Equal(t, "category-3", readCategory(conn).Category.ID)
+	})
+}
@@ -10,27 +10,46 @@ type UpdateCategoryMessage struct {
 	TeamID          string                              `json:"teamId"`
 	Category        *model.Category                     `json:"category,omitempty"`
 	BoardCategories []*model.BoardCategoryWebsocketData `json:"blockCategories,omitempty"`
+	Sequence        int64                               `json:"sequence,omitempty"`
+	Epoch           string                              `json:"epoch,omitempty"`
 }
 
 // UpdateBlockMsg is sent on block updates.
 type UpdateBlockMsg struct {
-	Action string       `json:"action"`
-	TeamID string       `json:"teamId"`
-	Block  *model.Block `json:"block"`
+	Action   string       `json:"action"`
+	TeamID   string       `json:"teamId"`
+	Block    *model.Block `json:"block"`
+	Sequence int64        `json:"sequence,omitempty"`
+	Epoch    string       `json:"epoch,omitempty"`
+}
+
+// UpdateBlocksMsg is sent instead of several UpdateBlockMsg when block
+// updates are queued in a row for a client. The sequence is the one of
+// the last update.
+type UpdateBlocksMsg struct {
+	Action   string         `json:"action"`
+	TeamID   string         `json:"teamId"`
+	Blocks   []*model.Block `json:"blocks"`
+	Sequence int64          `json:"sequence,omitempty"`
+	Epoch    string         `json:"epoch,omitempty"`
 }
 
 // UpdateBoardMsg is sent on block updates.
 type UpdateBoardMsg struct {
-	Action string       `json:"action"`
-	TeamID string       `json:"teamId"`
-	Board  *model.Board `json:"board"`
+	Action   string       `json:"action"`
+	TeamID   string       `json:"teamId"`
+	Board    *model.Board `json:"board"`
+	Sequence int64        `json:"sequence,omitempty"`
+	Epoch    string       `json:"epoch,omitempty"`
 }
 
 // UpdateMemberMsg is sent on membership updates.
 type UpdateMemberMsg struct {
-	Action string             `json:"action"`
-	TeamID string             `json:"teamId"`
-	Member *model.BoardMember `json:"member"`
+	Action   string             `json:"action"`
+	TeamID   string             `json:"teamId"`
+	Member   *model.BoardMember `json:"member"`
+	Sequence int64              `json:"sequence,omitempty"`
+	Epoch    string             `json:"epoch,omitempty"`
 }
 
 // UpdateSubscription is sent on subscription updates.
@@ -51,19 +70,111 @@ type UpdateCardLimitTimestamp struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// CloseCodeSessionRevoked is the close code of the connections whose
+// session was revoked.
+const CloseCodeSessionRevoked = 4001
+
+// AccessRevokedMsg is sent when a client is unsubscribed from a team or a
+// board it can't access anymore.
+type AccessRevokedMsg struct {
+	Action  string `json:"action"`
+	TeamID  string `json:"teamId,omitempty"`
+	BoardID string `json:"boardId,omitempty"`
+}
+
+// ResyncMsg is sent when a client resumes its connection but the events
+// it missed can't be replayed, so it has to reload the team data.
+type ResyncMsg struct {
+	Action   string `json:"action"`
+	TeamID   string `json:"teamId"`
+	Sequence int64  `json:"sequence"`
+	Epoch    string `json:"epoch"`
+}
+
+// BoardPresenceMsg is sent when a user opens or leaves a board, or
+// focuses one of its cards.
+type BoardPresenceMsg struct {
+	Action  string      `json:"action"`
+	BoardID string      `json:"boardId"`
+	Event   string      `json:"event"`
+	Viewer  BoardViewer `json:"viewer"`
+}
+
+// BoardViewersMsg lists the users viewing a board. It's sent to a client
+// when it opens the board.
+type BoardViewersMsg struct {
+	Action  string        `json:"action"`
+	BoardID string        `json:"boardId"`
+	Viewers []BoardViewer `json:"viewers"`
+}
+
+// TextBlockStateMsg is sent when a client starts editing a text block,
+// or when its edits can't be merged anymore. The client discards its
+// unacknowledged edits and starts over from the text and revision.
+type TextBlockStateMsg struct {
+	Action   string `json:"action"`
+	BoardID  string `json:"boardId"`
+	BlockID  string `json:"blockId"`
+	Revision int64  `json:"revision"`
+	Text     string `json:"text"`
+}
+
+// TextBlockOperationMsg is sent to the editors of a text block when
+// someone else edits it. The operation applies to the previous
+// revision of the text.
+type TextBlockOperationMsg struct {
+	Action       string         `json:"action"`
+	BoardID      string         `json:"boardId"`
+	BlockID      string         `json:"blockId"`
+	Revision     int64          `json:"revision"`
+	UserID       string         `json:"userId"`
+	ConnectionID string         `json:"connectionId,omitempty"`
+	Operation    *TextOperation `json:"operation"`
+}
+
+// TextBlockAckMsg is sent to the editor of a text block once its
+// operation was applied, with the revision it resulted in.
+type TextBlockAckMsg struct {
+	Action   string `json:"action"`
+	BoardID  string `json:"boardId"`
+	BlockID  string `json:"blockId"`
+	Revision int64  `json:"revision"`
+}
+
+// TextBlockClosedMsg is sent when a client can't edit a text block over
+// the websocket, either because it can't see or edit the block anymore,
+// or because the block is being edited through another node of the
+// cluster. The client keeps editing the block through the API.
+type TextBlockClosedMsg struct {
+	Action  string `json:"action"`
+	BoardID string `json:"boardId"`
+	BlockID string `json:"blockId"`
+	Reason  string `json:"reason"`
+}
+
 // WebsocketCommand is an incoming command from the client.
 type WebsocketCommand struct {
 	Action    string   `json:"action"`
 	TeamID    string   `json:"teamId"`
+	BoardID   string   `json:"boardId"`
+	CardID    string   `json:"cardId"`
 	Token     string   `json:"token"`
 	ReadToken string   `json:"readToken"`
 	BlockIDs  []string `json:"blockIds"`
+	Sequence  int64    `json:"sequence"`
+	Epoch     string   `json:"epoch"`
+	// BlockID, Revision and Operation are the text block edits
+	BlockID   string         `json:"blockId"`
+	Revision  int64          `json:"revision"`
+	Operation *TextOperation `json:"operation"`
 }
 
 type CategoryReorderMessage struct {
 	Action        string   `json:"action"`
 	CategoryOrder []string `json:"categoryOrder"`
 	TeamID        string   `json:"teamId"`
+	Sequence      int64    `json:"sequence,omitempty"`
+	Epoch         string   `json:"epoch,omitempty"`
 }
 
 type CategoryBoardReorderMessage struct {
@@ -71,4 +182,6 @@ type CategoryBoardReorderMessage struct {
 	CategoryID string   `json:"CategoryId"`
 	BoardOrder []string `json:"BoardOrder"`
 	TeamID     string   `json:"teamId"`
+	Sequence   int64    `json:"sequence,omitempty"`
+	Epoch      string   `json:"epoch,omitempty"`
 }
@@ -0,0 +1,83 @@
+package ws
+
+import (
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// defaultEventBufferSize is the number of events of each team kept to
+// be replayed to the clients that resume their connection.
+const defaultEventBufferSize = 1000
+
+// eventMessage returns the message of an event for a user, or nil if the
+// event isn't sent to the user. The message carries the epoch of the
+// team events with the sequence, for the client to resume from.
+type eventMessage func(epoch string, seq int64, userID string) interface{}
+
+// teamEvent is a message broadcast to the listeners of a team.
+type teamEvent struct {
+	seq     int64
+	message eventMessage
+}
+
+// teamEvents numbers the events broadcast to the listeners of a team
+// and keeps the last ones, to replay them to the clients that missed
+// them.
+type teamEvents struct {
+	// epoch identifies the sequences of these events. Each node, and
+	// each run of a node, numbers the events on its own, so clients
+	// resuming with a sequence of another epoch are told to resync.
+	epoch string
+	// start is the sequence before the first event of the team. It's
+	// the time the team got its first event, so that the sequences
+	// keep increasing when the server restarts.
+	start  int64
+	seq    int64
+	size   int
+	events []teamEvent
+}
+
+func newTeamEvents(size int) *teamEvents {
+	start := utils.GetMillis()
+	return &teamEvents{
+		epoch: utils.NewID(utils.IDTypeNone),
+		start: start,
+		seq:   start,
+		size:  size,
+	}
+}
+
+// add numbers an event and keeps it, dropping the oldest event if the
+// buffer is full.
+func (te *teamEvents) add(message eventMessage) int64 {
+	te.seq++
+	if len(te.events) >= te.size {
+		te.events = append(te.events[:0], te.events[len(te.events)-te.size+1:]...)
+	}
+	te.events = append(te.events, teamEvent{seq: te.seq, message: message})
+	return te.seq
+}
+
+// since returns the events after a sequence of an epoch. It returns
+// false if some of them aren't kept anymore, or if the sequence wasn't
+// given by these events. A zero sequence stands for a client that didn't
+// receive any event yet, whatever its epoch.
+func (te *teamEvents) since(epoch string, seq int64) ([]teamEvent, bool) {
+	if seq == 0 {
+		seq = te.start
+	} else if epoch != te.epoch {
+		return nil, false
+	}
+	if seq < te.start || seq > te.seq {
+		return nil, false
+	}
+	if seq == te.seq {
+		return nil, true
+	}
+
+	oldest := te.events[0].seq
+	if seq < oldest-1 {
+		return nil, false
+	}
+	events := te.events[seq-oldest+1:]
+	return append([]teamEvent{}, events...), true
+}
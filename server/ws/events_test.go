@@ -0,0 +1,80 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamEvents(t *testing.T) {
+	message := func(_ string, seq int64, _ string) interface{} { return seq }
+	seqs := func(events []teamEvent) []int64 {
+		result := []int64{}
+		for _, event := range events {
+			result = append(result, event.seq)
+		}
+		return result
+	}
+
+	events := newTeamEvents(3)
+	epoch := events.epoch
+	start := events.seq
+
+	t.Run("no events yet", func(t *testing.T) {
+		missed, ok := events.since(epoch, 0)
+		require.True(t, ok)
+		require.Empty(t, missed)
+	})
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, start+int64(i)+1, events.add(message))
+	}
+
+	t.Run("replays the missed events", func(t *testing.T) {
+		missed, ok := events.since(epoch, start+1)
+		require.True(t, ok)
+		require.Equal(t, []int64{start + 2, start + 3}, seqs(missed))
+
+		missed, ok = events.since(epoch, 0)
+		require.True(t, ok)
+		require.Equal(t, []int64{start + 1, start + 2, start + 3}, seqs(missed))
+
+		missed, ok = events.since(epoch, start+3)
+		require.True(t, ok)
+		require.Empty(t, missed)
+	})
+
+	t.Run("the oldest events are dropped", func(t *testing.T) {
+		events.add(message)
+
+		_, ok := events.since(epoch, 0)
+		require.False(t, ok)
+
+		missed, ok := events.since(epoch, start+1)
+		require.True(t, ok)
+		require.Equal(t, []int64{start + 2, start + 3, start + 4}, seqs(missed))
+	})
+
+	t.Run("unknown sequences", func(t *testing.T) {
+		_, ok := events.since(epoch, start-10)
+		require.False(t, ok)
+
+		_, ok = events.since(epoch, start+10)
+		require.False(t, ok)
+	})
+
+	t.Run("sequences of another epoch", func(t *testing.T) {
+		other := newTeamEvents(3)
+		require.NotEqual(t, epoch, other.epoch)
+
+		_, ok := events.since(other.epoch, start+3)
+		require.False(t, ok)
+
+		_, ok = events.since("", start+3)
+		require.False(t, ok)
+
+		missed, ok := other.since(epoch, 0)
+		require.True(t, ok)
+		require.Empty(t, missed)
+	})
+}
@@ -0,0 +1,67 @@
+package ws
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// newBlockFilter returns a function that gives the version of the block
+// each user can see: nil if the block is a private card the user can't
+// see or belongs to one, and cards without the property values hidden to
+// the user. It returns nil if all the users can see the block as is.
+func newBlockFilter(store Store, logger mlog.LoggerIFace, block *model.Block) func(userID string) *model.Block {
+	card := block
+	if parentID := model.CardParentID(block); parentID != "" {
+		parent, err := store.GetBlock(parentID)
+		if err != nil && !model.IsErrNotFound(err) {
+			logger.Error("error getting card for block broadcast",
+				mlog.String("blockID", block.ID),
+				mlog.String("parentID", parentID),
+				mlog.Err(err),
+			)
+		}
+		card = parent
+	}
+	private := model.IsPrivateCard(card)
+
+	var board *model.Board
+	if block.Type == model.TypeCard && block.DeleteAt == 0 {
+		var err error
+		board, err = store.GetBoard(block.BoardID)
+		if err != nil && !model.IsErrNotFound(err) {
+			logger.Error("error getting board for block broadcast",
+				mlog.String("boardID", block.BoardID),
+				mlog.Err(err),
+			)
+		}
+	}
+	restricted := model.HasPropertyAccessRules(board)
+
+	if !private && !restricted {
+		return nil
+	}
+
+	members, err := store.GetMembersForBoard(block.BoardID)
+	if err != nil {
+		logger.Error("error getting members for block broadcast",
+			mlog.String("boardID", block.BoardID),
+			mlog.Err(err),
+		)
+	}
+	membersByUserID := map[string]*model.BoardMember{}
+	for _, member := range members {
+		membersByUserID[member.UserID] = member
+	}
+
+	return func(userID string) *model.Block {
+		member := membersByUserID[userID]
+		if private && !model.CanViewCard(card, userID, member) {
+			return nil
+		}
+		if restricted {
+			return model.GetPropertyRestrictions(board, member).FilterBlock(block)
+		}
+		return block
+	}
+}
@@ -0,0 +1,219 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/focalboard/server/ws (interfaces: Adapter)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/mattermost/focalboard/server/model"
+)
+
+// MockAdapter is a mock of Adapter interface.
+type MockAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdapterMockRecorder
+}
+
+// MockAdapterMockRecorder is the mock recorder for MockAdapter.
+type MockAdapterMockRecorder struct {
+	mock *MockAdapter
+}
+
+// NewMockAdapter creates a new mock instance.
+func NewMockAdapter(ctrl *gomock.Controller) *MockAdapter {
+	mock := &MockAdapter{ctrl: ctrl}
+	mock.recorder = &MockAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdapter) EXPECT() *MockAdapterMockRecorder {
+	return m.recorder
+}
+
+// BroadcastBlockChange mocks base method.
+func (m *MockAdapter) BroadcastBlockChange(arg0 string, arg1 *model.Block) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastBlockChange", arg0, arg1)
+}
+
+// BroadcastBlockChange indicates an expected call of BroadcastBlockChange.
+func (mr *MockAdapterMockRecorder) BroadcastBlockChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastBlockChange", reflect.TypeOf((*MockAdapter)(nil).BroadcastBlockChange), arg0, arg1)
+}
+
+// BroadcastBlockDelete mocks base method.
+func (m *MockAdapter) BroadcastBlockDelete(arg0, arg1, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastBlockDelete", arg0, arg1, arg2)
+}
+
+// BroadcastBlockDelete indicates an expected call of BroadcastBlockDelete.
+func (mr *MockAdapterMockRecorder) BroadcastBlockDelete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastBlockDelete", reflect.TypeOf((*MockAdapter)(nil).BroadcastBlockDelete), arg0, arg1, arg2)
+}
+
+// BroadcastBoardChange mocks base method.
+func (m *MockAdapter) BroadcastBoardChange(arg0 string, arg1 *model.Board) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastBoardChange", arg0, arg1)
+}
+
+// BroadcastBoardChange indicates an expected call of BroadcastBoardChange.
+func (mr *MockAdapterMockRecorder) BroadcastBoardChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastBoardChange", reflect.TypeOf((*MockAdapter)(nil).BroadcastBoardChange), arg0, arg1)
+}
+
+// BroadcastBoardDelete mocks base method.
+func (m *MockAdapter) BroadcastBoardDelete(arg0, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastBoardDelete", arg0, arg1)
+}
+
+// BroadcastBoardDelete indicates an expected call of BroadcastBoardDelete.
+func (mr *MockAdapterMockRecorder) BroadcastBoardDelete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastBoardDelete", reflect.TypeOf((*MockAdapter)(nil).BroadcastBoardDelete), arg0, arg1)
+}
+
+// BroadcastCardLimitTimestampChange mocks base method.
+func (m *MockAdapter) BroadcastCardLimitTimestampChange(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastCardLimitTimestampChange", arg0)
+}
+
+// BroadcastCardLimitTimestampChange indicates an expected call of BroadcastCardLimitTimestampChange.
+func (mr *MockAdapterMockRecorder) BroadcastCardLimitTimestampChange(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastCardLimitTimestampChange", reflect.TypeOf((*MockAdapter)(nil).BroadcastCardLimitTimestampChange), arg0)
+}
+
+// BroadcastCategoryBoardChange mocks base method.
+func (m *MockAdapter) BroadcastCategoryBoardChange(arg0, arg1 string, arg2 []*model.BoardCategoryWebsocketData) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastCategoryBoardChange", arg0, arg1, arg2)
+}
+
+// BroadcastCategoryBoardChange indicates an expected call of BroadcastCategoryBoardChange.
+func (mr *MockAdapterMockRecorder) BroadcastCategoryBoardChange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastCategoryBoardChange", reflect.TypeOf((*MockAdapter)(nil).BroadcastCategoryBoardChange), arg0, arg1, arg2)
+}
+
+// BroadcastCategoryBoardsReorder mocks base method.
+func (m *MockAdapter) BroadcastCategoryBoardsReorder(arg0, arg1, arg2 string, arg3 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastCategoryBoardsReorder", arg0, arg1, arg2, arg3)
+}
+
+// BroadcastCategoryBoardsReorder indicates an expected call of BroadcastCategoryBoardsReorder.
+func (mr *MockAdapterMockRecorder) BroadcastCategoryBoardsReorder(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastCategoryBoardsReorder", reflect.TypeOf((*MockAdapter)(nil).BroadcastCategoryBoardsReorder), arg0, arg1, arg2, arg3)
+}
+
+// BroadcastCategoryChange mocks base method.
+func (m *MockAdapter) BroadcastCategoryChange(arg0 model.Category) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastCategoryChange", arg0)
+}
+
+// BroadcastCategoryChange indicates an expected call of BroadcastCategoryChange.
+func (mr *MockAdapterMockRecorder) BroadcastCategoryChange(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastCategoryChange", reflect.TypeOf((*MockAdapter)(nil).BroadcastCategoryChange), arg0)
+}
+
+// BroadcastCategoryReorder mocks base method.
+func (m *MockAdapter) BroadcastCategoryReorder(arg0, arg1 string, arg2 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastCategoryReorder", arg0, arg1, arg2)
+}
+
+// BroadcastCategoryReorder indicates an expected call of BroadcastCategoryReorder.
+func (mr *MockAdapterMockRecorder) BroadcastCategoryReorder(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastCategoryReorder", reflect.TypeOf((*MockAdapter)(nil).BroadcastCategoryReorder), arg0, arg1, arg2)
+}
+
+// BroadcastConfigChange mocks base method.
+func (m *MockAdapter) BroadcastConfigChange(arg0 model.ClientConfig) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastConfigChange", arg0)
+}
+
+// BroadcastConfigChange indicates an expected call of BroadcastConfigChange.
+func (mr *MockAdapterMockRecorder) BroadcastConfigChange(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastConfigChange", reflect.TypeOf((*MockAdapter)(nil).BroadcastConfigChange), arg0)
+}
+
+// BroadcastMemberChange mocks base method.
+func (m *MockAdapter) BroadcastMemberChange(arg0, arg1 string, arg2 *model.BoardMember) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastMemberChange", arg0, arg1, arg2)
+}
+
+// BroadcastMemberChange indicates an expected call of BroadcastMemberChange.
+func (mr *MockAdapterMockRecorder) BroadcastMemberChange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastMemberChange", reflect.TypeOf((*MockAdapter)(nil).BroadcastMemberChange), arg0, arg1, arg2)
+}
+
+// BroadcastMemberDelete mocks base method.
+func (m *MockAdapter) BroadcastMemberDelete(arg0, arg1, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastMemberDelete", arg0, arg1, arg2)
+}
+
+// BroadcastMemberDelete indicates an expected call of BroadcastMemberDelete.
+func (mr *MockAdapterMockRecorder) BroadcastMemberDelete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastMemberDelete", reflect.TypeOf((*MockAdapter)(nil).BroadcastMemberDelete), arg0, arg1, arg2)
+}
+
+// BroadcastSubscriptionChange mocks base method.
+func (m *MockAdapter) BroadcastSubscriptionChange(arg0 string, arg1 *model.Subscription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "BroadcastSubscriptionChange", arg0, arg1)
+}
+
+// BroadcastSubscriptionChange indicates an expected call of BroadcastSubscriptionChange.
+func (mr *MockAdapterMockRecorder) BroadcastSubscriptionChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastSubscriptionChange", reflect.TypeOf((*MockAdapter)(nil).BroadcastSubscriptionChange), arg0, arg1)
+}
+
+// CloseSessions mocks base method.
+func (m *MockAdapter) CloseSessions(arg0 ...string) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "CloseSessions", varargs...)
+}
+
+// CloseSessions indicates an expected call of CloseSessions.
+func (mr *MockAdapterMockRecorder) CloseSessions(arg0 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSessions", reflect.TypeOf((*MockAdapter)(nil).CloseSessions), arg0...)
+}
+
+// RevokeTeamAccess mocks base method.
+func (m *MockAdapter) RevokeTeamAccess(arg0, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeTeamAccess", arg0, arg1)
+}
+
+// RevokeTeamAccess indicates an expected call of RevokeTeamAccess.
+func (mr *MockAdapterMockRecorder) RevokeTeamAccess(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTeamAccess", reflect.TypeOf((*MockAdapter)(nil).RevokeTeamAccess), arg0, arg1)
+}
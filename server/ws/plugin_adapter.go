@@ -46,7 +46,12 @@ type PluginAdapter struct {
 
 	subscriptionsMU  sync.RWMutex
 	listenersByTeam  map[string][]*PluginAdapterClient
+	listenersByBoard map[string][]*PluginAdapterClient
 	listenersByBlock map[string][]*PluginAdapterClient
+
+	// presence has the viewers of the boards on all the nodes, as
+	// the presence events are propagated through the cluster
+	presence *presence
 }
 
 // servicesAPI is the interface required by the PluginAdapter to interact with
@@ -66,9 +71,11 @@ func NewPluginAdapter(api servicesAPI, auth auth.AuthInterface, store Store, log
 		listeners:         make(map[string]*PluginAdapterClient),
 		listenersByUserID: make(map[string][]*PluginAdapterClient),
 		listenersByTeam:   make(map[string][]*PluginAdapterClient),
+		listenersByBoard:  make(map[string][]*PluginAdapterClient),
 		listenersByBlock:  make(map[string][]*PluginAdapterClient),
 		listenersMU:       sync.RWMutex{},
 		subscriptionsMU:   sync.RWMutex{},
+		presence:          newPresence(defaultPresenceTimeout),
 	}
 }
 
@@ -94,6 +101,13 @@ func (pa *PluginAdapter) GetListenersByTeam(teamID string) []*PluginAdapterClien
 	return pa.listenersByTeam[teamID]
 }
 
+func (pa *PluginAdapter) GetListenersByBoard(boardID string) []*PluginAdapterClient {
+	pa.subscriptionsMU.RLock()
+	defer pa.subscriptionsMU.RUnlock()
+
+	return pa.listenersByBoard[boardID]
+}
+
 func (pa *PluginAdapter) GetListenersByBlock(blockID string) []*PluginAdapterClient {
 	pa.subscriptionsMU.RLock()
 	defer pa.subscriptionsMU.RUnlock()
@@ -111,13 +125,18 @@ func (pa *PluginAdapter) addListener(pac *PluginAdapterClient) {
 
 func (pa *PluginAdapter) removeListener(pac *PluginAdapterClient) {
 	pa.listenersMU.Lock()
-	defer pa.listenersMU.Unlock()
+	boards := pac.getBoards()
 
 	// team subscriptions
 	for _, team := range pac.teams {
 		pa.removeListenerFromTeam(pac, team)
 	}
 
+	// board subscriptions
+	for _, board := range boards {
+		pa.removeListenerFromBoard(pac, board)
+	}
+
 	// block subscriptions
 	for _, block := range pac.blocks {
 		pa.removeListenerFromBlock(pac, block)
@@ -133,6 +152,11 @@ func (pa *PluginAdapter) removeListener(pac *PluginAdapterClient) {
 	pa.listenersByUserID[pac.userID] = newUserListeners
 
 	delete(pa.listeners, pac.webConnID)
+	pa.listenersMU.Unlock()
+
+	for _, boardID := range boards {
+		pa.leaveBoard(pac, boardID)
+	}
 }
 
 func (pa *PluginAdapter) removeExpiredForUserID(userID string) {
@@ -157,6 +181,24 @@ func (pa *PluginAdapter) removeListenerFromTeam(pac *PluginAdapterClient, teamID
 	pac.unsubscribeFromTeam(teamID)
 }
 
+func (pa *PluginAdapter) removeListenerFromBoard(pac *PluginAdapterClient, boardID string) {
+	newBoardListeners := []*PluginAdapterClient{}
+	for _, listener := range pa.GetListenersByBoard(boardID) {
+		if listener.webConnID != pac.webConnID {
+			newBoardListeners = append(newBoardListeners, listener)
+		}
+	}
+	pa.subscriptionsMU.Lock()
+	if len(newBoardListeners) == 0 {
+		delete(pa.listenersByBoard, boardID)
+	} else {
+		pa.listenersByBoard[boardID] = newBoardListeners
+	}
+	pa.subscriptionsMU.Unlock()
+
+	pac.unsubscribeFromBoard(boardID)
+}
+
 func (pa *PluginAdapter) removeListenerFromBlock(pac *PluginAdapterClient, blockID string) {
 	newBlockListeners := []*PluginAdapterClient{}
 	for _, listener := range pa.GetListenersByBlock(blockID) {
@@ -191,6 +233,27 @@ func (pa *PluginAdapter) unsubscribeListenerFromTeam(pac *PluginAdapterClient, t
 	pa.removeListenerFromTeam(pac, teamID)
 }
 
+func (pa *PluginAdapter) subscribeListenerToBoard(pac *PluginAdapterClient, boardID string) {
+	if pac.isSubscribedToBoard(boardID) {
+		return
+	}
+
+	pa.subscriptionsMU.Lock()
+	pa.listenersByBoard[boardID] = append(pa.listenersByBoard[boardID], pac)
+	pa.subscriptionsMU.Unlock()
+
+	pac.subscribeToBoard(boardID)
+}
+
+func (pa *PluginAdapter) unsubscribeListenerFromBoard(pac *PluginAdapterClient, boardID string) {
+	if !pac.isSubscribedToBoard(boardID) {
+		return
+	}
+
+	pa.removeListenerFromBoard(pac, boardID)
+	pa.leaveBoard(pac, boardID)
+}
+
 func (pa *PluginAdapter) getUserIDsForTeam(teamID string) []string {
 	userMap := map[string]bool{}
 	for _, pac := range pa.GetListenersByTeam(teamID) {
@@ -277,6 +340,11 @@ func (pa *PluginAdapter) OnWebSocketConnect(webConnID, userID string) {
 			mlog.String("userID", userID),
 		)
 		atomic.StoreInt64(&existingPAC.inactiveAt, 0)
+
+		// the connection is viewing its boards again
+		for _, boardID := range existingPAC.getBoards() {
+			pa.joinBoard(existingPAC, boardID)
+		}
 		return
 	}
 
@@ -285,6 +353,7 @@ func (pa *PluginAdapter) OnWebSocketConnect(webConnID, userID string) {
 		webConnID:  webConnID,
 		userID:     userID,
 		teams:      []string{},
+		boards:     []string{},
 		blocks:     []string{},
 	}
 
@@ -303,6 +372,12 @@ func (pa *PluginAdapter) OnWebSocketDisconnect(webConnID, userID string) {
 	}
 
 	atomic.StoreInt64(&pac.inactiveAt, mmModel.GetMillis())
+
+	// the subscriptions are kept in case the connection comes back, but
+	// it isn't viewing its boards anymore
+	for _, boardID := range pac.getBoards() {
+		pa.leaveBoard(pac, boardID)
+	}
 }
 
 func commandFromRequest(req *mmModel.WebSocketRequest) (*WebsocketCommand, error) {
@@ -314,6 +389,14 @@ func commandFromRequest(req *mmModel.WebSocketRequest) (*WebsocketCommand, error
 		return nil, errMissingTeamInCommand
 	}
 
+	if boardID, ok := req.Data["boardId"]; ok {
+		c.BoardID = boardID.(string)
+	}
+
+	if cardID, ok := req.Data["cardId"]; ok {
+		c.CardID = cardID.(string)
+	}
+
 	if readToken, ok := req.Data["readToken"]; ok {
 		c.ReadToken = readToken.(string)
 	}
@@ -385,6 +468,39 @@ func (pa *PluginAdapter) WebSocketMessageHasBeenPosted(webConnID, userID string,
 		)
 
 		pa.unsubscribeListenerFromTeam(pac, command.TeamID)
+	case websocketActionSubscribeBoard:
+		pa.logger.Debug(`Command: SUBSCRIBE_BOARD`,
+			mlog.String("webConnID", webConnID),
+			mlog.String("userID", userID),
+			mlog.String("boardID", command.BoardID),
+		)
+
+		if command.BoardID == "" || !pa.auth.DoesUserHaveBoardAccess(userID, command.BoardID) {
+			return
+		}
+
+		pa.subscribeListenerToBoard(pac, command.BoardID)
+		pa.joinBoard(pac, command.BoardID)
+		pa.sendBoardViewers(pac, command.BoardID)
+	case websocketActionUnsubscribeBoard:
+		pa.logger.Debug(`Command: UNSUBSCRIBE_BOARD`,
+			mlog.String("webConnID", webConnID),
+			mlog.String("userID", userID),
+			mlog.String("boardID", command.BoardID),
+		)
+
+		pa.unsubscribeListenerFromBoard(pac, command.BoardID)
+	case websocketActionFocusCard:
+		pa.logger.Debug(`Command: FOCUS_CARD`,
+			mlog.String("webConnID", webConnID),
+			mlog.String("userID", userID),
+			mlog.String("boardID", command.BoardID),
+			mlog.String("cardID", command.CardID),
+		)
+
+		pa.focusCard(pac, command.BoardID, command.CardID)
+	case websocketActionHeartbeat:
+		pa.heartbeat(pac)
 	}
 }
 
@@ -398,6 +514,74 @@ func (pa *PluginAdapter) BroadcastConfigChange(pluginConfig model.ClientConfig)
 	pa.sendMessageToAll(websocketActionUpdateConfig, utils.StructToMap(pluginConfig))
 }
 
+// CloseSessions does nothing, as the sessions are managed by Mattermost
+// in plugin mode.
+func (pa *PluginAdapter) CloseSessions(sessionIDs ...string) {}
+
+// RevokeTeamAccess unsubscribes the connections of a user on this node
+// from a team, and from the boards they can't access anymore. The
+// connections on the other nodes stop getting the team messages too, as
+// the team access is checked on each broadcast.
+func (pa *PluginAdapter) RevokeTeamAccess(teamID, userID string) {
+	for _, pac := range pa.GetListenersByUserID(userID) {
+		if !pac.isSubscribedToTeam(teamID) {
+			continue
+		}
+		pa.unsubscribeListenerFromTeam(pac, teamID)
+
+		message := AccessRevokedMsg{Action: websocketActionAccessRevoked, TeamID: teamID}
+		pa.api.PublishWebSocketEvent(websocketActionAccessRevoked, utils.StructToMap(message), &mmModel.WebsocketBroadcast{
+			UserId:       pac.userID,
+			ConnectionId: pac.webConnID,
+		})
+	}
+
+	for _, boardID := range pa.getBoardsForUserID(userID) {
+		pa.checkBoardSubscriptions(boardID, userID)
+	}
+}
+
+// getBoardsForUserID returns the boards the connections of a user on this
+// node are subscribed to.
+func (pa *PluginAdapter) getBoardsForUserID(userID string) []string {
+	boards := map[string]bool{}
+	for _, pac := range pa.GetListenersByUserID(userID) {
+		for _, boardID := range pac.getBoards() {
+			boards[boardID] = true
+		}
+	}
+
+	boardIDs := make([]string, 0, len(boards))
+	for boardID := range boards {
+		boardIDs = append(boardIDs, boardID)
+	}
+	return boardIDs
+}
+
+// checkBoardSubscriptions unsubscribes the connections of a user on this
+// node from a board if the user can't see it anymore.
+func (pa *PluginAdapter) checkBoardSubscriptions(boardID, userID string) {
+	listeners := []*PluginAdapterClient{}
+	for _, pac := range pa.GetListenersByUserID(userID) {
+		if pac.isSubscribedToBoard(boardID) {
+			listeners = append(listeners, pac)
+		}
+	}
+	if len(listeners) == 0 || pa.auth.DoesUserHaveBoardAccess(userID, boardID) {
+		return
+	}
+
+	message := AccessRevokedMsg{Action: websocketActionAccessRevoked, BoardID: boardID}
+	payload := utils.StructToMap(message)
+	for _, pac := range listeners {
+		pa.unsubscribeListenerFromBoard(pac, boardID)
+		pa.api.PublishWebSocketEvent(websocketActionAccessRevoked, payload, &mmModel.WebsocketBroadcast{
+			UserId:       pac.userID,
+			ConnectionId: pac.webConnID,
+		})
+	}
+}
+
 // sendUserMessageSkipCluster sends the message to specific users.
 func (pa *PluginAdapter) sendUserMessageSkipCluster(event string, payload map[string]interface{}, userIDs ...string) {
 	for _, userID := range userIDs {
@@ -466,7 +650,42 @@ func (pa *PluginAdapter) BroadcastBlockChange(teamID string, block *model.Block)
 		Block:  block,
 	}
 
-	pa.sendBoardMessage(teamID, block.BoardID, utils.StructToMap(message))
+	filter := newBlockFilter(pa.store, pa.logger, block)
+	if filter == nil {
+		pa.sendBoardMessage(teamID, block.BoardID, utils.StructToMap(message))
+		return
+	}
+
+	// the block is a private card or has property values hidden to some of
+	// the members, so each of them gets their own copy of it
+	userIDs := map[string]bool{}
+	for _, userID := range pa.getUserIDsForTeamAndBoard(teamID, block.BoardID) {
+		userIDs[userID] = true
+	}
+	members, err := pa.store.GetMembersForBoard(block.BoardID)
+	if err != nil {
+		pa.logger.Error("error getting members for board",
+			mlog.String("method", "BroadcastBlockChange"),
+			mlog.String("boardID", block.BoardID),
+			mlog.Err(err),
+		)
+		return
+	}
+	for _, member := range members {
+		message.Block = filter(member.UserID)
+		if message.Block == nil {
+			continue
+		}
+		payload := utils.StructToMap(message)
+
+		go func(userID string) {
+			pa.sendMessageToCluster(&ClusterMessage{Payload: payload, UserID: userID})
+		}(member.UserID)
+
+		if userIDs[member.UserID] {
+			pa.sendUserMessageSkipCluster(websocketActionUpdateBoard, payload, member.UserID)
+		}
+	}
 }
 
 func (pa *PluginAdapter) BroadcastCategoryChange(category model.Category) {
@@ -644,6 +863,8 @@ func (pa *PluginAdapter) BroadcastMemberDelete(teamID, boardID, userID string) {
 	// member deletion message, the deleted member will not be one of
 	// them, so we need to ensure they receive the message
 	pa.sendBoardMessage(teamID, boardID, utils.StructToMap(message), userID)
+
+	pa.checkBoardSubscriptions(boardID, userID)
 }
 
 func (pa *PluginAdapter) BroadcastSubscriptionChange(teamID string, subscription *model.Subscription) {
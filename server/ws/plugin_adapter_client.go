@@ -13,6 +13,7 @@ type PluginAdapterClient struct {
 	webConnID  string
 	userID     string
 	teams      []string
+	boards     []string
 	blocks     []string
 	mu         sync.RWMutex
 }
@@ -45,6 +46,33 @@ func (pac *PluginAdapterClient) unsubscribeFromTeam(teamID string) {
 	pac.teams = newClientTeams
 }
 
+func (pac *PluginAdapterClient) subscribeToBoard(boardID string) {
+	pac.mu.Lock()
+	defer pac.mu.Unlock()
+
+	pac.boards = append(pac.boards, boardID)
+}
+
+func (pac *PluginAdapterClient) unsubscribeFromBoard(boardID string) {
+	pac.mu.Lock()
+	defer pac.mu.Unlock()
+
+	newClientBoards := []string{}
+	for _, id := range pac.boards {
+		if id != boardID {
+			newClientBoards = append(newClientBoards, id)
+		}
+	}
+	pac.boards = newClientBoards
+}
+
+func (pac *PluginAdapterClient) getBoards() []string {
+	pac.mu.RLock()
+	defer pac.mu.RUnlock()
+
+	return append([]string{}, pac.boards...)
+}
+
 func (pac *PluginAdapterClient) unsubscribeFromBlock(blockID string) {
 	pac.mu.Lock()
 	defer pac.mu.Unlock()
@@ -71,6 +99,19 @@ func (pac *PluginAdapterClient) isSubscribedToTeam(teamID string) bool {
 	return false
 }
 
+func (pac *PluginAdapterClient) isSubscribedToBoard(boardID string) bool {
+	pac.mu.RLock()
+	defer pac.mu.RUnlock()
+
+	for _, id := range pac.boards {
+		if id == boardID {
+			return true
+		}
+	}
+
+	return false
+}
+
 //nolint:unused
 func (pac *PluginAdapterClient) isSubscribedToBlock(blockID string) bool {
 	pac.mu.RLock()
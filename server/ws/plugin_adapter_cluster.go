@@ -13,6 +13,7 @@ type ClusterMessage struct {
 	UserID      string
 	Payload     map[string]interface{}
 	EnsureUsers []string
+	Presence    *BoardPresenceMsg
 }
 
 func (pa *PluginAdapter) sendMessageToCluster(clusterMessage *ClusterMessage) {
@@ -51,6 +52,11 @@ func (pa *PluginAdapter) HandleClusterEvent(ev mmModel.PluginClusterEvent) {
 		return
 	}
 
+	if clusterMessage.Presence != nil {
+		pa.handleClusterPresence(clusterMessage.Presence)
+		return
+	}
+
 	if clusterMessage.BoardID != "" {
 		pa.sendBoardMessageSkipCluster(clusterMessage.TeamID, clusterMessage.BoardID, clusterMessage.Payload, clusterMessage.EnsureUsers...)
 		return
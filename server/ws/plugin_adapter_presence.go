@@ -0,0 +1,148 @@
+package ws
+
+import (
+	"github.com/mattermost/focalboard/server/utils"
+
+	mmModel "github.com/mattermost/mattermost/server/public/model"
+)
+
+// joinBoard adds a connection to the viewers of a board. The join is
+// propagated to the cluster even if the connection was already viewing
+// the board, so that the other nodes keep it alive.
+func (pa *PluginAdapter) joinBoard(pac *PluginAdapterClient, boardID string) {
+	pa.expirePresence()
+
+	viewer := BoardViewer{UserID: pac.userID, ConnectionID: pac.webConnID}
+	joined := pa.presence.join(boardID, viewer)
+
+	go pa.sendPresenceToCluster(boardID, PresenceEventJoin, viewer)
+	if joined {
+		pa.sendPresenceSkipCluster(boardID, PresenceEventJoin, viewer)
+	}
+}
+
+// leaveBoard removes a connection from the viewers of a board.
+func (pa *PluginAdapter) leaveBoard(pac *PluginAdapterClient, boardID string) {
+	if viewer, ok := pa.presence.leave(boardID, pac.webConnID); ok {
+		pa.sendPresence(boardID, PresenceEventLeave, viewer)
+	}
+}
+
+// focusCard changes the card a connection is viewing on a board. An
+// empty card ID means that the connection isn't viewing any card.
+func (pa *PluginAdapter) focusCard(pac *PluginAdapterClient, boardID, cardID string) {
+	pa.expirePresence()
+
+	if viewer, ok := pa.presence.focus(boardID, pac.webConnID, cardID); ok {
+		pa.sendPresence(boardID, PresenceEventFocus, viewer)
+	}
+}
+
+// heartbeat keeps a connection among the viewers of its boards, on this
+// node and on the rest of the cluster.
+func (pa *PluginAdapter) heartbeat(pac *PluginAdapterClient) {
+	for _, boardID := range pac.getBoards() {
+		pa.joinBoard(pac, boardID)
+	}
+}
+
+// expirePresence removes the viewers that stopped sending heartbeats.
+// Each node expires its own copy of the viewers, so the leave events are
+// only sent to the connections of this node.
+func (pa *PluginAdapter) expirePresence() {
+	for boardID, viewers := range pa.presence.expire() {
+		for _, viewer := range viewers {
+			pa.sendPresenceSkipCluster(boardID, PresenceEventLeave, viewer)
+		}
+	}
+}
+
+// sendBoardViewers sends to a connection the other viewers of a board.
+func (pa *PluginAdapter) sendBoardViewers(pac *PluginAdapterClient, boardID string) {
+	message := BoardViewersMsg{
+		Action:  websocketActionBoardViewers,
+		BoardID: boardID,
+		Viewers: []BoardViewer{},
+	}
+	for _, viewer := range pa.presence.viewers(boardID) {
+		if viewer.ConnectionID != pac.webConnID {
+			message.Viewers = append(message.Viewers, viewer)
+		}
+	}
+
+	pa.api.PublishWebSocketEvent(websocketActionBoardViewers, utils.StructToMap(message), &mmModel.WebsocketBroadcast{
+		UserId:       pac.userID,
+		ConnectionId: pac.webConnID,
+	})
+}
+
+// sendPresenceSkipCluster sends a presence event to the connections of
+// this node subscribed to a board, but the one of the viewer.
+func (pa *PluginAdapter) sendPresenceSkipCluster(boardID, event string, viewer BoardViewer) {
+	message := BoardPresenceMsg{
+		Action:  websocketActionBoardPresence,
+		BoardID: boardID,
+		Event:   event,
+		Viewer:  viewer,
+	}
+	payload := utils.StructToMap(message)
+
+	for _, pac := range pa.GetListenersByBoard(boardID) {
+		if pac.webConnID == viewer.ConnectionID || !pac.isActive() {
+			continue
+		}
+		pa.api.PublishWebSocketEvent(websocketActionBoardPresence, payload, &mmModel.WebsocketBroadcast{
+			UserId:       pac.userID,
+			ConnectionId: pac.webConnID,
+		})
+	}
+}
+
+// sendPresenceToCluster propagates a presence event to the other nodes.
+func (pa *PluginAdapter) sendPresenceToCluster(boardID, event string, viewer BoardViewer) {
+	pa.sendMessageToCluster(&ClusterMessage{
+		BoardID: boardID,
+		Presence: &BoardPresenceMsg{
+			Action:  websocketActionBoardPresence,
+			BoardID: boardID,
+			Event:   event,
+			Viewer:  viewer,
+		},
+	})
+}
+
+// sendPresence sends and propagates a presence event of a board.
+func (pa *PluginAdapter) sendPresence(boardID, event string, viewer BoardViewer) {
+	go pa.sendPresenceToCluster(boardID, event, viewer)
+
+	pa.sendPresenceSkipCluster(boardID, event, viewer)
+}
+
+// handleClusterPresence applies a presence event of another node, and
+// sends it to the connections of this node.
+func (pa *PluginAdapter) handleClusterPresence(message *BoardPresenceMsg) {
+	pa.expirePresence()
+
+	boardID := message.BoardID
+	viewer := message.Viewer
+	switch message.Event {
+	case PresenceEventJoin:
+		if pa.presence.join(boardID, viewer) {
+			pa.sendPresenceSkipCluster(boardID, PresenceEventJoin, viewer)
+		}
+	case PresenceEventLeave:
+		if left, ok := pa.presence.leave(boardID, viewer.ConnectionID); ok {
+			pa.sendPresenceSkipCluster(boardID, PresenceEventLeave, left)
+		}
+	case PresenceEventFocus:
+		// the viewer may have expired on this node if its heartbeats
+		// were lost
+		joined := BoardViewer{UserID: viewer.UserID, ConnectionID: viewer.ConnectionID}
+		if pa.presence.join(boardID, joined) {
+			pa.sendPresenceSkipCluster(boardID, PresenceEventJoin, joined)
+		}
+		if focused, ok := pa.presence.focus(boardID, viewer.ConnectionID, viewer.CardID); ok {
+			pa.sendPresenceSkipCluster(boardID, PresenceEventFocus, focused)
+		}
+	}
+}
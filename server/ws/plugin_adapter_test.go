@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"encoding/json"
 	"sync"
 	"testing"
 
@@ -8,6 +9,7 @@ import (
 
 	mmModel "github.com/mattermost/mattermost/server/public/model"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -551,3 +553,132 @@ func TestParallelSubscriptionsOnMultipleConnections(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestPluginAdapterPresence(t *testing.T) {
+	th := SetupTestHelper(t)
+
+	teamID := mmModel.NewId()
+	boardID := mmModel.NewId()
+	userID := mmModel.NewId()
+	webConnID := mmModel.NewId()
+	remoteUserID := mmModel.NewId()
+	remoteWebConnID := mmModel.NewId()
+
+	th.api.EXPECT().PublishPluginClusterEvent(gomock.Any(), gomock.Any()).AnyTimes()
+
+	th.pa.OnWebSocketConnect(webConnID, userID)
+	pac, ok := th.pa.GetListenerByWebConnID(webConnID)
+	require.True(t, ok)
+
+	toConnection := &mmModel.WebsocketBroadcast{UserId: userID, ConnectionId: webConnID}
+
+	t.Run("subscribing to a board sends its viewers", func(t *testing.T) {
+		th.auth.EXPECT().DoesUserHaveBoardAccess(userID, boardID).Return(true)
+		th.api.EXPECT().PublishWebSocketEvent(websocketActionBoardViewers, gomock.Any(), toConnection).
+			Do(func(_ string, payload map[string]interface{}, _ *mmModel.WebsocketBroadcast) {
+				require.Empty(t, payload["viewers"])
+			})
+
+		msgData := map[string]interface{}{"teamId": teamID, "boardId": boardID}
+		th.ReceiveWebSocketMessage(webConnID, userID, websocketActionSubscribeBoard, msgData)
+		require.True(t, pac.isSubscribedToBoard(boardID))
+		require.Len(t, th.pa.presence.viewers(boardID), 1)
+	})
+
+	remoteEvent := func(event string, viewer BoardViewer) mmModel.PluginClusterEvent {
+		data, err := json.Marshal(&ClusterMessage{
+			BoardID: boardID,
+			Presence: &BoardPresenceMsg{
+				Action:  websocketActionBoardPresence,
+				BoardID: boardID,
+				Event:   event,
+				Viewer:  viewer,
+			},
+		})
+		require.NoError(t, err)
+		return mmModel.PluginClusterEvent{Id: "websocket_message", Data: data}
+	}
+	remoteViewer := BoardViewer{UserID: remoteUserID, ConnectionID: remoteWebConnID}
+
+	t.Run("presence events of other nodes are sent to the board viewers", func(t *testing.T) {
+		th.api.EXPECT().PublishWebSocketEvent(websocketActionBoardPresence, gomock.Any(), toConnection).
+			Do(func(_ string, payload map[string]interface{}, _ *mmModel.WebsocketBroadcast) {
+				require.Equal(t, PresenceEventJoin, payload["event"])
+			})
+		th.pa.HandleClusterEvent(remoteEvent(PresenceEventJoin, remoteViewer))
+		require.Len(t, th.pa.presence.viewers(boardID), 2)
+
+		// joins that only keep the viewer alive aren't sent
+		th.pa.HandleClusterEvent(remoteEvent(PresenceEventJoin, remoteViewer))
+
+		focused := remoteViewer
+		focused.CardID = mmModel.NewId()
+		th.api.EXPECT().PublishWebSocketEvent(websocketActionBoardPresence, gomock.Any(), toConnection).
+			Do(func(_ string, payload map[string]interface{}, _ *mmModel.WebsocketBroadcast) {
+				require.Equal(t, PresenceEventFocus, payload["event"])
+			})
+		th.pa.HandleClusterEvent(remoteEvent(PresenceEventFocus, focused))
+
+		th.api.EXPECT().PublishWebSocketEvent(websocketActionBoardPresence, gomock.Any(), toConnection).
+			Do(func(_ string, payload map[string]interface{}, _ *mmModel.WebsocketBroadcast) {
+				require.Equal(t, PresenceEventLeave, payload["event"])
+			})
+		th.pa.HandleClusterEvent(remoteEvent(PresenceEventLeave, remoteViewer))
+		require.Len(t, th.pa.presence.viewers(boardID), 1)
+	})
+
+	t.Run("disconnected connections leave their boards and join back when reconnecting", func(t *testing.T) {
+		th.pa.OnWebSocketDisconnect(webConnID, userID)
+		require.Empty(t, th.pa.presence.viewers(boardID))
+		require.True(t, pac.isSubscribedToBoard(boardID))
+
+		th.pa.OnWebSocketConnect(webConnID, userID)
+		require.Len(t, th.pa.presence.viewers(boardID), 1)
+	})
+
+	t.Run("unsubscribing from a board leaves it", func(t *testing.T) {
+		msgData := map[string]interface{}{"teamId": teamID, "boardId": boardID}
+		th.ReceiveWebSocketMessage(webConnID, userID, websocketActionUnsubscribeBoard, msgData)
+		require.False(t, pac.isSubscribedToBoard(boardID))
+		require.Empty(t, th.pa.presence.viewers(boardID))
+		require.Empty(t, th.pa.GetListenersByBoard(boardID))
+	})
+}
+
+func TestPluginAdapterRevokeTeamAccess(t *testing.T) {
+	th := SetupTestHelper(t)
+
+	teamID := mmModel.NewId()
+	boardID := mmModel.NewId()
+	userID := mmModel.NewId()
+	webConnID := mmModel.NewId()
+
+	th.api.EXPECT().PublishPluginClusterEvent(gomock.Any(), gomock.Any()).AnyTimes()
+	th.api.EXPECT().PublishWebSocketEvent(websocketActionBoardViewers, gomock.Any(), gomock.Any())
+
+	th.pa.OnWebSocketConnect(webConnID, userID)
+	pac, ok := th.pa.GetListenerByWebConnID(webConnID)
+	require.True(t, ok)
+
+	th.SubscribeWebConnToTeam(webConnID, userID, teamID)
+	th.auth.EXPECT().DoesUserHaveBoardAccess(userID, boardID).Return(true)
+	th.ReceiveWebSocketMessage(webConnID, userID, websocketActionSubscribeBoard, map[string]interface{}{"teamId": teamID, "boardId": boardID})
+	require.True(t, pac.isSubscribedToBoard(boardID))
+
+	toConnection := &mmModel.WebsocketBroadcast{UserId: userID, ConnectionId: webConnID}
+	th.auth.EXPECT().DoesUserHaveBoardAccess(userID, boardID).Return(false)
+	th.api.EXPECT().PublishWebSocketEvent(websocketActionAccessRevoked, gomock.Any(), toConnection).
+		Do(func(_ string, payload map[string]interface{}, _ *mmModel.WebsocketBroadcast) {
+			require.Equal(t, teamID, payload["teamId"])
+		})
+	th.api.EXPECT().PublishWebSocketEvent(websocketActionAccessRevoked, gomock.Any(), toConnection).
+		Do(func(_ string, payload map[string]interface{}, _ *mmModel.WebsocketBroadcast) {
+			require.Equal(t, boardID, payload["boardId"])
+		})
+
+	th.pa.RevokeTeamAccess(teamID, userID)
+
+	require.False(t, pac.isSubscribedToTeam(teamID))
+	require.False(t, pac.isSubscribedToBoard(boardID))
+	require.Empty(t, th.pa.presence.viewers(boardID))
+}
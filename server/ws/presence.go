@@ -0,0 +1,144 @@
+package ws
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// defaultPresenceTimeout is the time after which a viewer that didn't
+// send any heartbeat is considered gone.
+const defaultPresenceTimeout = time.Minute
+
+const (
+	PresenceEventJoin  = "join"
+	PresenceEventLeave = "leave"
+	PresenceEventFocus = "focus"
+)
+
+// BoardViewer is a user viewing a board through a websocket connection.
+type BoardViewer struct {
+	UserID       string `json:"userId"`
+	ConnectionID string `json:"connectionId"`
+	// CardID is the card the user is viewing, if any
+	CardID string `json:"cardId,omitempty"`
+}
+
+type boardViewer struct {
+	BoardViewer
+	lastSeen int64
+}
+
+// presence keeps track of the users viewing each board and of the card
+// they're viewing. Viewers are identified by their connection, as a
+// user may have the same board open in several tabs.
+type presence struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	boards  map[string]map[string]*boardViewer
+}
+
+func newPresence(timeout time.Duration) *presence {
+	return &presence{
+		timeout: timeout,
+		boards:  make(map[string]map[string]*boardViewer),
+	}
+}
+
+// join adds a viewer to a board, or marks it as alive if the connection
+// was already viewing it. It returns true if the viewer was added.
+func (p *presence) join(boardID string, viewer BoardViewer) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	viewers, ok := p.boards[boardID]
+	if !ok {
+		viewers = make(map[string]*boardViewer)
+		p.boards[boardID] = viewers
+	}
+	if existing, ok := viewers[viewer.ConnectionID]; ok {
+		existing.lastSeen = utils.GetMillis()
+		return false
+	}
+	viewers[viewer.ConnectionID] = &boardViewer{BoardViewer: viewer, lastSeen: utils.GetMillis()}
+	return true
+}
+
+// leave removes a connection from the viewers of a board. It returns
+// false if the connection wasn't viewing it.
+func (p *presence) leave(boardID, connectionID string) (BoardViewer, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	viewer, ok := p.boards[boardID][connectionID]
+	if !ok {
+		return BoardViewer{}, false
+	}
+	delete(p.boards[boardID], connectionID)
+	if len(p.boards[boardID]) == 0 {
+		delete(p.boards, boardID)
+	}
+	return viewer.BoardViewer, true
+}
+
+// focus changes the card a connection is viewing on a board. It returns
+// false if the connection isn't viewing the board, or if the card
+// didn't change.
+func (p *presence) focus(boardID, connectionID, cardID string) (BoardViewer, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	viewer, ok := p.boards[boardID][connectionID]
+	if !ok {
+		return BoardViewer{}, false
+	}
+	viewer.lastSeen = utils.GetMillis()
+	if viewer.CardID == cardID {
+		return BoardViewer{}, false
+	}
+	viewer.CardID = cardID
+	return viewer.BoardViewer, true
+}
+
+// viewers returns the viewers of a board, ordered by user.
+func (p *presence) viewers(boardID string) []BoardViewer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	viewers := make([]BoardViewer, 0, len(p.boards[boardID]))
+	for _, viewer := range p.boards[boardID] {
+		viewers = append(viewers, viewer.BoardViewer)
+	}
+	sort.Slice(viewers, func(i, j int) bool {
+		if viewers[i].UserID == viewers[j].UserID {
+			return viewers[i].ConnectionID < viewers[j].ConnectionID
+		}
+		return viewers[i].UserID < viewers[j].UserID
+	})
+	return viewers
+}
+
+// expire removes the viewers that didn't send any heartbeat in time,
+// and returns them by board.
+func (p *presence) expire() map[string][]BoardViewer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	expired := map[string][]BoardViewer{}
+	threshold := utils.GetMillis() - p.timeout.Milliseconds()
+	for boardID, viewers := range p.boards {
+		for connectionID, viewer := range viewers {
+			if viewer.lastSeen >= threshold {
+				continue
+			}
+			expired[boardID] = append(expired[boardID], viewer.BoardViewer)
+			delete(viewers, connectionID)
+		}
+		if len(viewers) == 0 {
+			delete(p.boards, boardID)
+		}
+	}
+	return expired
+}
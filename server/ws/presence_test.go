@@ -0,0 +1,56 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresence(t *testing.T) {
+	p := newPresence(time.Minute)
+	viewer1 := BoardViewer{UserID: "user-1", ConnectionID: "conn-1"}
+	viewer2 := BoardViewer{UserID: "user-2", ConnectionID: "conn-2"}
+
+	t.Run("join", func(t *testing.T) {
+		require.True(t, p.join("board-1", viewer2))
+		require.True(t, p.join("board-1", viewer1))
+		require.False(t, p.join("board-1", viewer1))
+
+		require.Equal(t, []BoardViewer{viewer1, viewer2}, p.viewers("board-1"))
+		require.Empty(t, p.viewers("board-2"))
+	})
+
+	t.Run("focus", func(t *testing.T) {
+		viewer, ok := p.focus("board-1", "conn-1", "card-1")
+		require.True(t, ok)
+		require.Equal(t, "card-1", viewer.CardID)
+
+		_, ok = p.focus("board-1", "conn-1", "card-1")
+		require.False(t, ok, "the card didn't change")
+
+		_, ok = p.focus("board-2", "conn-1", "card-2")
+		require.False(t, ok, "the connection isn't viewing the board")
+
+		require.Equal(t, "card-1", p.viewers("board-1")[0].CardID)
+	})
+
+	t.Run("leave", func(t *testing.T) {
+		viewer, ok := p.leave("board-1", "conn-1")
+		require.True(t, ok)
+		require.Equal(t, "user-1", viewer.UserID)
+
+		_, ok = p.leave("board-1", "conn-1")
+		require.False(t, ok)
+
+		require.Equal(t, []BoardViewer{viewer2}, p.viewers("board-1"))
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		require.Empty(t, p.expire())
+
+		p.boards["board-1"]["conn-2"].lastSeen -= time.Minute.Milliseconds() + 1
+		require.Equal(t, map[string][]BoardViewer{"board-1": {viewer2}}, p.expire())
+		require.Empty(t, p.boards)
+	})
+}
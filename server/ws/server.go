@@ -3,24 +3,21 @@ package ws
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/mattermost/focalboard/server/auth"
 	"github.com/mattermost/focalboard/server/model"
+	authService "github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/metrics"
 	"github.com/mattermost/focalboard/server/utils"
 
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 )
 
-func (wss *websocketSession) WriteJSON(v interface{}) error {
-	wss.mu.Lock()
-	defer wss.mu.Unlock()
-	err := wss.conn.WriteJSON(v)
-	return err
-}
-
 func (wss *websocketSession) isSubscribedToTeam(teamID string) bool {
 	for _, id := range wss.teams {
 		if id == teamID {
@@ -31,6 +28,16 @@ func (wss *websocketSession) isSubscribedToTeam(teamID string) bool {
 	return false
 }
 
+func (wss *websocketSession) isSubscribedToBoard(boardID string) bool {
+	for _, id := range wss.boards {
+		if id == boardID {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (wss *websocketSession) isSubscribedToBlock(blockID string) bool {
 	for _, id := range wss.blocks {
 		if id == blockID {
@@ -46,6 +53,7 @@ type Server struct {
 	upgrader         websocket.Upgrader
 	listeners        map[*websocketSession]bool
 	listenersByTeam  map[string][]*websocketSession
+	listenersByBoard map[string][]*websocketSession
 	listenersByBlock map[string][]*websocketSession
 	mu               sync.RWMutex
 	auth             *auth.Auth
@@ -53,13 +61,44 @@ type Server struct {
 	isMattermostAuth bool
 	logger           mlog.LoggerIFace
 	store            Store
+	// events are the numbered events of each team, kept to be
+	// replayed to the clients that resume their connection
+	events          map[string]*teamEvents
+	eventBufferSize int
+	presence        *presence
+	metrics         *metrics.Metrics
+	sendQueueSize   int
+	// textDocuments are the text blocks being edited, by block
+	textDocuments  map[string]*textDocument
+	textMu         sync.Mutex
+	textBlockSaver TextBlockSaver
+	textSaveDelay  time.Duration
+	// nodeID identifies the node holding the leases of the text blocks
+	// it edits
+	nodeID string
 }
 
 type websocketSession struct {
+	// id identifies the connection among the viewers of the boards
+	id     string
 	conn   *websocket.Conn
 	userID string
-	mu     sync.Mutex
-	teams  []string
+	// sessionID is the ID of the user session authenticating the
+	// connection, if any
+	sessionID string
+	// scopes are the scopes of the access token authenticating the
+	// connection, if any
+	scopes []string
+	// mu serializes the queuing of the messages
+	mu      sync.Mutex
+	send    chan interface{}
+	closed  bool
+	metrics *metrics.Metrics
+	teams   []string
+	// boards are the boards the listener is viewing. Listeners that
+	// aren't subscribed to any board get the block changes of all the
+	// boards of their teams.
+	boards []string
 	blocks []string
 }
 
@@ -68,10 +107,11 @@ func (wss *websocketSession) isAuthenticated() bool {
 }
 
 // NewServer creates a new Server.
-func NewServer(auth *auth.Auth, singleUserToken string, isMattermostAuth bool, logger mlog.LoggerIFace, store Store) *Server {
+func NewServer(auth *auth.Auth, singleUserToken string, isMattermostAuth bool, logger mlog.LoggerIFace, store Store, metrics *metrics.Metrics) *Server {
 	return &Server{
 		listeners:        make(map[*websocketSession]bool),
 		listenersByTeam:  make(map[string][]*websocketSession),
+		listenersByBoard: make(map[string][]*websocketSession),
 		listenersByBlock: make(map[string][]*websocketSession),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
@@ -83,6 +123,14 @@ func NewServer(auth *auth.Auth, singleUserToken string, isMattermostAuth bool, l
 		isMattermostAuth: isMattermostAuth,
 		logger:           logger,
 		store:            store,
+		events:           make(map[string]*teamEvents),
+		eventBufferSize:  defaultEventBufferSize,
+		presence:         newPresence(defaultPresenceTimeout),
+		metrics:          metrics,
+		sendQueueSize:    defaultSendQueueSize,
+		textDocuments:    make(map[string]*textDocument),
+		textSaveDelay:    defaultTextSaveDelay,
+		nodeID:           utils.NewID(utils.IDTypeNone),
 	}
 }
 
@@ -100,16 +148,20 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// create an empty session with websocket client
-	wsSession := &websocketSession{
-		conn:   client,
-		userID: "",
-		mu:     sync.Mutex{},
-		teams:  []string{},
-		blocks: []string{},
-	}
+	wsSession := newWebsocketSession(client, ws.sendQueueSize, ws.metrics, ws.logger)
+	wsSession.id = utils.NewID(utils.IDTypeNone)
 
 	if ws.isMattermostAuth {
 		wsSession.userID = r.Header.Get("Mattermost-User-Id")
+	} else if cookie, cookieErr := r.Cookie(authService.SessionCookieToken); cookieErr == nil && isSameOrigin(r) {
+		// sessions created by an OIDC login are only available to the
+		// browser as a cookie. As any origin can open a websocket, the
+		// cookie is only trusted for same origin connections.
+		if session := ws.getSessionForToken(cookie.Value); session != nil {
+			wsSession.userID = session.UserID
+			wsSession.sessionID = session.ID
+			wsSession.scopes = session.AccessTokenScopes()
+		}
 	}
 
 	ws.addListener(wsSession)
@@ -120,6 +172,7 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 		// Remove session from listeners
 		ws.removeListener(wsSession)
+		wsSession.close()
 		wsSession.conn.Close()
 	}()
 
@@ -213,24 +266,24 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
 			)
 
-			// if single user mode, check that the userID is valid and
-			// assume that the user has permission if so
-			if len(ws.singleUserToken) != 0 {
-				if wsSession.userID != model.SingleUser {
-					continue
-				}
-
-				// if not in single user mode validate that the session
-				// has permissions to the team
-			} else {
-				ws.logger.Debug("Not single user mode")
-				if !ws.auth.DoesUserHaveTeamAccess(wsSession.userID, command.TeamID) {
-					ws.logger.Error("WS user doesn't have team access", mlog.String("teamID", command.TeamID), mlog.String("userID", wsSession.userID))
-					continue
-				}
+			if !ws.hasTeamAccess(wsSession, command.TeamID) {
+				continue
 			}
 
 			ws.subscribeListenerToTeam(wsSession, command.TeamID)
+		case websocketActionResume:
+			ws.logger.Debug(`Command: RESUME`,
+				mlog.String("teamID", command.TeamID),
+				mlog.Int("sequence", command.Sequence),
+				mlog.String("epoch", command.Epoch),
+				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
+			)
+
+			if !ws.hasTeamAccess(wsSession, command.TeamID) {
+				continue
+			}
+
+			ws.resumeListener(wsSession, command.TeamID, command.Epoch, command.Sequence)
 		case websocketActionUnsubscribeTeam:
 			ws.logger.Debug(`Command: UNSUBSCRIBE_TEAM`,
 				mlog.String("teamID", command.TeamID),
@@ -238,12 +291,95 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			)
 
 			ws.unsubscribeListenerFromTeam(wsSession, command.TeamID)
+		case websocketActionSubscribeBoard:
+			ws.logger.Debug(`Command: SUBSCRIBE_BOARD`,
+				mlog.String("boardID", command.BoardID),
+				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
+			)
+
+			if !ws.hasBoardAccess(wsSession, command.BoardID) {
+				continue
+			}
+
+			ws.subscribeListenerToBoard(wsSession, command.BoardID)
+			ws.joinBoard(wsSession, command.BoardID)
+		case websocketActionUnsubscribeBoard:
+			ws.logger.Debug(`Command: UNSUBSCRIBE_BOARD`,
+				mlog.String("boardID", command.BoardID),
+				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
+			)
+
+			ws.unsubscribeListenerFromBoard(wsSession, command.BoardID)
+		case websocketActionFocusCard:
+			ws.logger.Debug(`Command: FOCUS_CARD`,
+				mlog.String("boardID", command.BoardID),
+				mlog.String("cardID", command.CardID),
+				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
+			)
+
+			ws.focusCard(wsSession, command.BoardID, command.CardID)
+		case websocketActionHeartbeat:
+			ws.heartbeat(wsSession)
+		case websocketActionJoinTextBlock:
+			ws.logger.Debug(`Command: JOIN_TEXT_BLOCK`,
+				mlog.String("boardID", command.BoardID),
+				mlog.String("blockID", command.BlockID),
+				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
+			)
+
+			ws.joinTextBlock(wsSession, command.BoardID, command.BlockID)
+		case websocketActionLeaveTextBlock:
+			ws.logger.Debug(`Command: LEAVE_TEXT_BLOCK`,
+				mlog.String("blockID", command.BlockID),
+				mlog.Stringer("client", wsSession.conn.RemoteAddr()),
+			)
+
+			ws.leaveTextBlock(wsSession, command.BlockID)
+		case websocketActionEditTextBlock:
+			ws.editTextBlock(wsSession, command.BlockID, command.Revision, command.Operation)
 		default:
 			ws.logger.Error(`ERROR webSocket command, invalid action`, mlog.String("action", command.Action))
 		}
 	}
 }
 
+// hasTeamAccess returns true if the listener can subscribe to a team
+// changes.
+func (ws *Server) hasTeamAccess(wsSession *websocketSession, teamID string) bool {
+	// if single user mode, check that the userID is valid and
+	// assume that the user has permission if so
+	if len(ws.singleUserToken) != 0 {
+		return wsSession.userID == model.SingleUser
+	}
+
+	// if not in single user mode validate that the session
+	// has permissions to the team
+	ws.logger.Debug("Not single user mode")
+	if !ws.auth.DoesUserHaveTeamAccess(wsSession.userID, teamID) {
+		ws.logger.Error("WS user doesn't have team access", mlog.String("teamID", teamID), mlog.String("userID", wsSession.userID))
+		return false
+	}
+	return true
+}
+
+// hasBoardAccess returns true if the listener can subscribe to a board
+// changes.
+func (ws *Server) hasBoardAccess(wsSession *websocketSession, boardID string) bool {
+	if boardID == "" {
+		return false
+	}
+
+	if len(ws.singleUserToken) != 0 {
+		return wsSession.userID == model.SingleUser
+	}
+
+	if !ws.auth.DoesUserHaveBoardAccess(wsSession.userID, boardID) {
+		ws.logger.Error("WS user doesn't have board access", mlog.String("boardID", boardID), mlog.String("userID", wsSession.userID))
+		return false
+	}
+	return true
+}
+
 // isCommandReadTokenValid ensures that a command contains a read
 // token and a set of block ids that said token is valid for.
 func (ws *Server) isCommandReadTokenValid(command WebsocketCommand) bool {
@@ -296,7 +432,7 @@ func (ws *Server) addListener(listener *websocketSession) {
 // any, from the websockets server.
 func (ws *Server) removeListener(listener *websocketSession) {
 	ws.mu.Lock()
-	defer ws.mu.Unlock()
+	boards := listener.boards
 
 	// remove the listener from its subscriptions, if any
 
@@ -305,12 +441,23 @@ func (ws *Server) removeListener(listener *websocketSession) {
 		ws.removeListenerFromTeam(listener, team)
 	}
 
+	// board subscriptions
+	for _, board := range listener.boards {
+		ws.removeListenerFromBoard(listener, board)
+	}
+
 	// block subscriptions
 	for _, block := range listener.blocks {
 		ws.removeListenerFromBlock(listener, block)
 	}
 
 	delete(ws.listeners, listener)
+	ws.mu.Unlock()
+
+	for _, boardID := range boards {
+		ws.leaveBoard(listener, boardID)
+	}
+	ws.leaveTextBlocks(listener, func(*textDocument) bool { return true })
 }
 
 // subscribeListenerToTeam safely modifies the listener and the
@@ -341,6 +488,36 @@ func (ws *Server) unsubscribeListenerFromTeam(listener *websocketSession, teamID
 	ws.removeListenerFromTeam(listener, teamID)
 }
 
+// subscribeListenerToBoard safely modifies the listener and the
+// server to subscribe the listener to a given board updates.
+func (ws *Server) subscribeListenerToBoard(listener *websocketSession, boardID string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if listener.isSubscribedToBoard(boardID) {
+		return
+	}
+
+	ws.listenersByBoard[boardID] = append(ws.listenersByBoard[boardID], listener)
+	listener.boards = append(listener.boards, boardID)
+}
+
+// unsubscribeListenerFromBoard safely modifies the listener and the
+// server data structures to remove the link between the listener and a
+// given board ID.
+func (ws *Server) unsubscribeListenerFromBoard(listener *websocketSession, boardID string) {
+	ws.mu.Lock()
+	subscribed := listener.isSubscribedToBoard(boardID)
+	if subscribed {
+		ws.removeListenerFromBoard(listener, boardID)
+	}
+	ws.mu.Unlock()
+
+	if subscribed {
+		ws.leaveBoard(listener, boardID)
+	}
+}
+
 // subscribeListenerToBlocks safely modifies the listener and the
 // server to subscribe the listener to a given set of block updates.
 func (ws *Server) subscribeListenerToBlocks(listener *websocketSession, blockIDs []string) {
@@ -393,6 +570,30 @@ func (ws *Server) removeListenerFromTeam(listener *websocketSession, teamID stri
 	listener.teams = newListenerTeams
 }
 
+// removeListenerFromBoard removes the listener from both its own board
+// subscribed list and the server listeners by board map.
+func (ws *Server) removeListenerFromBoard(listener *websocketSession, boardID string) {
+	newBoardListeners := []*websocketSession{}
+	for _, l := range ws.listenersByBoard[boardID] {
+		if l != listener {
+			newBoardListeners = append(newBoardListeners, l)
+		}
+	}
+	if len(newBoardListeners) == 0 {
+		delete(ws.listenersByBoard, boardID)
+	} else {
+		ws.listenersByBoard[boardID] = newBoardListeners
+	}
+
+	newListenerBoards := []string{}
+	for _, id := range listener.boards {
+		if id != boardID {
+			newListenerBoards = append(newListenerBoards, id)
+		}
+	}
+	listener.boards = newListenerBoards
+}
+
 // removeListenerFromBlock removes the listener from both its own
 // block subscribed list and the server listeners by block map.
 func (ws *Server) removeListenerFromBlock(listener *websocketSession, blockID string) {
@@ -416,20 +617,116 @@ func (ws *Server) removeListenerFromBlock(listener *websocketSession, blockID st
 }
 
 func (ws *Server) getUserIDForToken(token string) string {
+	session := ws.getSessionForToken(token)
+	if session == nil {
+		return ""
+	}
+	return session.UserID
+}
+
+// getSessionForToken returns the session of a token, or nil if the token
+// is not valid. The session has no ID in single-user mode.
+func (ws *Server) getSessionForToken(token string) *model.Session {
 	if len(ws.singleUserToken) > 0 {
 		if token == ws.singleUserToken {
-			return model.SingleUser
-		} else {
-			return ""
+			return &model.Session{UserID: model.SingleUser}
 		}
+		return nil
 	}
 
 	session, err := ws.auth.GetSession(token)
 	if session == nil || err != nil {
-		return ""
+		return nil
 	}
+	return session
+}
 
-	return session.UserID
+// CloseSessions closes the connections authenticated by the given user
+// sessions, such as revoked ones.
+func (ws *Server) CloseSessions(sessionIDs ...string) {
+	ids := make(map[string]bool, len(sessionIDs))
+	for _, id := range sessionIDs {
+		ids[id] = true
+	}
+
+	ws.mu.RLock()
+	listeners := []*websocketSession{}
+	for listener := range ws.listeners {
+		if listener.sessionID != "" && ids[listener.sessionID] {
+			listeners = append(listeners, listener)
+		}
+	}
+	ws.mu.RUnlock()
+
+	for _, listener := range listeners {
+		ws.logger.Debug("CloseSessions: closing the connection of a revoked session",
+			mlog.String("userID", listener.userID),
+			mlog.Stringer("client", listener.conn.RemoteAddr()),
+		)
+		ws.closeListener(listener, CloseCodeSessionRevoked, "session revoked")
+	}
+}
+
+// closeListener removes a listener and closes its connection with the
+// given close code. The listener is removed right away instead of when
+// its read loop ends, so that it doesn't get any other message.
+func (ws *Server) closeListener(listener *websocketSession, code int, reason string) {
+	ws.removeListener(listener)
+	listener.close()
+
+	message := websocket.FormatCloseMessage(code, reason)
+	_ = listener.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeTimeout))
+	listener.conn.Close()
+}
+
+// RevokeTeamAccess unsubscribes the listeners of a user from a team, and
+// from the boards they can't access anymore.
+func (ws *Server) RevokeTeamAccess(teamID, userID string) {
+	ws.mu.Lock()
+	listeners := []*websocketSession{}
+	boards := map[string]bool{}
+	for listener := range ws.listeners {
+		if listener.userID != userID {
+			continue
+		}
+		for _, boardID := range listener.boards {
+			boards[boardID] = true
+		}
+		if listener.isSubscribedToTeam(teamID) {
+			listeners = append(listeners, listener)
+		}
+	}
+	for _, listener := range listeners {
+		ws.removeListenerFromTeam(listener, teamID)
+	}
+	ws.mu.Unlock()
+
+	for _, listener := range listeners {
+		ws.logger.Debug("RevokeTeamAccess: unsubscribing a listener from the team",
+			mlog.String("teamID", teamID),
+			mlog.String("userID", userID),
+			mlog.Stringer("client", listener.conn.RemoteAddr()),
+		)
+		message := AccessRevokedMsg{Action: websocketActionAccessRevoked, TeamID: teamID}
+		if err := listener.WriteJSON(message); err != nil {
+			ws.logger.Error("access revoked error", mlog.Err(err))
+			listener.conn.Close()
+		}
+	}
+
+	for boardID := range boards {
+		ws.checkBoardSubscriptions(boardID, userID)
+	}
+}
+
+// isSameOrigin returns true if the request was initiated by a page
+// served by this server.
+func isSameOrigin(r *http.Request) bool {
+	origin, err := url.Parse(r.Header.Get("Origin"))
+	if err != nil || origin.Host == "" {
+		return false
+	}
+	return origin.Host == r.Host
 }
 
 func (ws *Server) authenticateListener(wsSession *websocketSession, token string) {
@@ -448,15 +745,19 @@ func (ws *Server) authenticateListener(wsSession *websocketSession, token string
 	}
 
 	// Authenticate session
-	userID := ws.getUserIDForToken(token)
-	if userID == "" {
+	session := ws.getSessionForToken(token)
+	if session == nil || session.UserID == "" {
 		wsSession.conn.Close()
 		return
 	}
 
 	// Authenticated
-	wsSession.userID = userID
-	ws.logger.Debug("authenticateListener: Authenticated", mlog.String("userID", userID), mlog.Stringer("client", wsSession.conn.RemoteAddr()))
+	ws.mu.Lock()
+	wsSession.userID = session.UserID
+	wsSession.sessionID = session.ID
+	wsSession.scopes = session.AccessTokenScopes()
+	ws.mu.Unlock()
+	ws.logger.Debug("authenticateListener: Authenticated", mlog.String("userID", session.UserID), mlog.Stringer("client", wsSession.conn.RemoteAddr()))
 }
 
 // getListenersForBlock returns the listeners subscribed to a
@@ -476,13 +777,13 @@ func (ws *Server) getListenerForUser(teamID, userID string) *websocketSession {
 	return nil
 }
 
-// getListenersForTeamAndBoard returns the listeners subscribed to a
-// team changes and members of a given board.
-func (ws *Server) getListenersForTeamAndBoard(teamID, boardID string, ensureUsers ...string) []*websocketSession {
+// getBoardMemberIDs returns the IDs of the members of a board, and of
+// the given users.
+func (ws *Server) getBoardMemberIDs(teamID, boardID string, ensureUsers ...string) map[string]bool {
 	members, err := ws.store.GetMembersForBoard(boardID)
 	if err != nil {
 		ws.logger.Error("error getting members for board",
-			mlog.String("method", "getListenersForTeamAndBoard"),
+			mlog.String("method", "getBoardMemberIDs"),
 			mlog.String("teamID", teamID),
 			mlog.String("boardID", boardID),
 		)
@@ -496,165 +797,449 @@ func (ws *Server) getListenersForTeamAndBoard(teamID, boardID string, ensureUser
 	for _, id := range ensureUsers {
 		memberMap[id] = true
 	}
+	return memberMap
+}
 
-	memberIDs := []string{}
-	for id := range memberMap {
-		memberIDs = append(memberIDs, id)
+// lazyBoardMemberIDs returns a function that gives the IDs of the
+// members of a board, only fetching them the first time it's called.
+func (ws *Server) lazyBoardMemberIDs(teamID, boardID string) func() map[string]bool {
+	var once sync.Once
+	var memberIDs map[string]bool
+	return func() map[string]bool {
+		once.Do(func() {
+			memberIDs = ws.getBoardMemberIDs(teamID, boardID)
+		})
+		return memberIDs
 	}
+}
 
-	listeners := []*websocketSession{}
-	for _, memberID := range memberIDs {
-		for _, listener := range ws.listenersByTeam[teamID] {
-			if listener.userID == memberID {
-				listeners = append(listeners, listener)
-			}
+// hasTeamWideListeners returns true if some listeners of a team aren't
+// subscribed to any board, and so get the changes of all its boards.
+func (ws *Server) hasTeamWideListeners(teamID string) bool {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	for _, listener := range ws.listenersByTeam[teamID] {
+		if len(listener.boards) == 0 {
+			return true
 		}
 	}
-	return listeners
+	return false
 }
 
-// BroadcastBlockDelete broadcasts delete messages to clients.
-func (ws *Server) BroadcastBlockDelete(teamID, blockID, boardID string) {
-	now := utils.GetMillis()
-	block := &model.Block{}
-	block.ID = blockID
-	block.BoardID = boardID
-	block.UpdateAt = now
-	block.DeleteAt = now
-
-	ws.BroadcastBlockChange(teamID, block)
+// getListenersForBoard returns the listeners subscribed to a board, and
+// the listeners of its team among the given users. If teamWide is false,
+// only the team listeners that aren't subscribed to any board are
+// returned. The caller must hold the server lock.
+func (ws *Server) getListenersForBoard(teamID, boardID string, userIDs map[string]bool, teamWide bool) []*websocketSession {
+	listeners := append([]*websocketSession{}, ws.listenersByBoard[boardID]...)
+	for _, listener := range ws.listenersByTeam[teamID] {
+		if !userIDs[listener.userID] || listener.isSubscribedToBoard(boardID) {
+			continue
+		}
+		if !teamWide && len(listener.boards) != 0 {
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners
 }
 
-// BroadcastBlockChange broadcasts update messages to clients.
-func (ws *Server) BroadcastBlockChange(teamID string, block *model.Block) {
-	blockIDsToNotify := []string{block.ID, block.ParentID}
+// publish numbers an event of a team, keeps it to be replayed and queues
+// it to the listeners returned by recipients, with the message returned
+// by send. The listeners are selected while holding the lock, so that
+// listeners resuming their connection get either the event or its
+// replay, and they are locked before releasing it, so that the events of
+// a team are queued in the order of their sequence.
+func (ws *Server) publish(teamID, logMessage string, message, send eventMessage, recipients func() []*websocketSession) {
+	ws.mu.Lock()
+	events, ok := ws.events[teamID]
+	if !ok {
+		events = newTeamEvents(ws.eventBufferSize)
+		ws.events[teamID] = events
+	}
+	seq := events.add(message)
 
-	message := UpdateBlockMsg{
-		Action: websocketActionUpdateBlock,
-		TeamID: teamID,
-		Block:  block,
+	listeners := uniqueListeners(recipients())
+	for _, listener := range listeners {
+		listener.mu.Lock()
 	}
+	ws.mu.Unlock()
 
-	listeners := ws.getListenersForTeamAndBoard(teamID, block.BoardID)
 	ws.logger.Trace("listener(s) for teamID",
 		mlog.Int("listener_count", len(listeners)),
 		mlog.String("teamID", teamID),
-		mlog.String("boardID", block.BoardID),
 	)
 
-	for _, blockID := range blockIDsToNotify {
-		listeners = append(listeners, ws.getListenersForBlock(blockID)...)
-		ws.logger.Trace("listener(s) for blockID",
-			mlog.Int("listener_count", len(listeners)),
-			mlog.String("blockID", blockID),
+	failed := []*websocketSession{}
+	for _, listener := range listeners {
+		ws.logger.Debug(logMessage,
+			mlog.String("teamID", teamID),
+			mlog.String("userID", listener.userID),
+			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
 		)
+
+		if m := send(events.epoch, seq, listener.userID); m != nil {
+			if err := listener.queue(m); err != nil {
+				ws.logger.Error("broadcast error", mlog.String("message", logMessage), mlog.Err(err))
+				failed = append(failed, listener)
+			}
+		}
+		listener.mu.Unlock()
 	}
 
+	for _, listener := range failed {
+		listener.conn.Close()
+	}
+}
+
+// uniqueListeners removes the duplicates from a list of listeners,
+// keeping their order.
+func uniqueListeners(listeners []*websocketSession) []*websocketSession {
+	seen := map[*websocketSession]bool{}
+	unique := make([]*websocketSession, 0, len(listeners))
 	for _, listener := range listeners {
-		ws.logger.Debug("Broadcast block change",
+		if !seen[listener] {
+			seen[listener] = true
+			unique = append(unique, listener)
+		}
+	}
+	return unique
+}
+
+// resumeListener subscribes the listener to a team changes and replays
+// the events it missed after the given sequence of an epoch. If they
+// aren't kept anymore, or the sequence was given by another node, the
+// listener is told to resync instead.
+func (ws *Server) resumeListener(listener *websocketSession, teamID, epoch string, seq int64) {
+	ws.mu.Lock()
+	if !listener.isSubscribedToTeam(teamID) {
+		ws.listenersByTeam[teamID] = append(ws.listenersByTeam[teamID], listener)
+		listener.teams = append(listener.teams, teamID)
+	}
+
+	events, ok := ws.events[teamID]
+	if !ok {
+		events = newTeamEvents(ws.eventBufferSize)
+		ws.events[teamID] = events
+	}
+	missed, ok := events.since(epoch, seq)
+	current := events.seq
+
+	// the events broadcast from now on are queued after the replay, so
+	// that the listener gets them in order
+	listener.mu.Lock()
+	ws.mu.Unlock()
+	defer listener.mu.Unlock()
+
+	if !ok {
+		ws.logger.Debug("resumeListener: missed events are gone, resyncing",
 			mlog.String("teamID", teamID),
-			mlog.String("blockID", block.ID),
-			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
+			mlog.Int("sequence", seq),
+			mlog.Stringer("client", listener.conn.RemoteAddr()),
 		)
-
-		err := listener.WriteJSON(message)
-		if err != nil {
-			ws.logger.Error("broadcast error", mlog.Err(err))
+		message := ResyncMsg{
+			Action:   websocketActionResync,
+			TeamID:   teamID,
+			Sequence: current,
+			Epoch:    events.epoch,
+		}
+		if err := listener.queue(message); err != nil {
+			ws.logger.Error("resync error", mlog.Err(err))
 			listener.conn.Close()
 		}
+		return
+	}
+
+	ws.logger.Debug("resumeListener: replaying missed events",
+		mlog.String("teamID", teamID),
+		mlog.Int("sequence", seq),
+		mlog.Int("event_count", len(missed)),
+		mlog.Stringer("client", listener.conn.RemoteAddr()),
+	)
+	replay := messageBatch{}
+	for _, event := range missed {
+		if message := event.message(events.epoch, event.seq, listener.userID); message != nil {
+			replay = append(replay, message)
+		}
+	}
+	if len(replay) == 0 {
+		return
+	}
+	// the replay takes a single place in the queue, as it may be longer
+	// than the queue
+	if err := listener.queue(replay); err != nil {
+		ws.logger.Error("replay error", mlog.Err(err))
+		listener.conn.Close()
 	}
 }
 
-func (ws *Server) BroadcastCategoryChange(category model.Category) {
-	message := UpdateCategoryMessage{
-		Action:   websocketActionUpdateCategory,
-		TeamID:   category.TeamID,
-		Category: &category,
-	}
-
-	listener := ws.getListenerForUser(category.TeamID, category.UserID)
-	if listener != nil {
-		ws.logger.Debug("Broadcast category change",
-			mlog.String("userID", category.UserID),
-			mlog.String("teamID", category.TeamID),
-			mlog.String("categoryID", category.ID),
-			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
-		)
+// joinBoard adds the listener to the viewers of a board, sends it the
+// other viewers and tells them it joined.
+func (ws *Server) joinBoard(listener *websocketSession, boardID string) {
+	ws.mu.RLock()
+	subscribed := listener.isSubscribedToBoard(boardID)
+	ws.mu.RUnlock()
+	if !subscribed {
+		return
+	}
 
-		if err := listener.WriteJSON(message); err != nil {
-			ws.logger.Error("broadcast category change error", mlog.Err(err))
-			listener.conn.Close()
+	ws.expirePresence()
+
+	viewer := BoardViewer{UserID: listener.userID, ConnectionID: listener.id}
+	joined := ws.presence.join(boardID, viewer)
+
+	message := BoardViewersMsg{
+		Action:  websocketActionBoardViewers,
+		BoardID: boardID,
+		Viewers: []BoardViewer{},
+	}
+	for _, v := range ws.presence.viewers(boardID) {
+		if v.ConnectionID != listener.id {
+			message.Viewers = append(message.Viewers, v)
 		}
 	}
+	if err := listener.WriteJSON(message); err != nil {
+		ws.logger.Error("board viewers error", mlog.Err(err))
+		listener.conn.Close()
+		return
+	}
+
+	if joined {
+		ws.broadcastPresence(boardID, PresenceEventJoin, viewer)
+	}
 }
 
-func (ws *Server) BroadcastCategoryReorder(teamID, userID string, categoryOrder []string) {
-	message := CategoryReorderMessage{
-		Action:        websocketActionReorderCategories,
-		CategoryOrder: categoryOrder,
-		TeamID:        teamID,
+// leaveBoard removes the listener from the viewers of a board.
+func (ws *Server) leaveBoard(listener *websocketSession, boardID string) {
+	if viewer, ok := ws.presence.leave(boardID, listener.id); ok {
+		ws.broadcastPresence(boardID, PresenceEventLeave, viewer)
 	}
+}
 
-	listener := ws.getListenerForUser(teamID, userID)
-	if listener != nil {
-		ws.logger.Debug("Broadcast category order change",
-			mlog.String("userID", userID),
-			mlog.String("teamID", teamID),
-			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
-		)
+// focusCard changes the card the listener is viewing on a board. An
+// empty card ID means that the listener isn't viewing any card.
+func (ws *Server) focusCard(listener *websocketSession, boardID, cardID string) {
+	ws.expirePresence()
 
-		if err := listener.WriteJSON(message); err != nil {
-			ws.logger.Error("broadcast category order change error", mlog.Err(err))
-			listener.conn.Close()
+	if viewer, ok := ws.presence.focus(boardID, listener.id, cardID); ok {
+		ws.broadcastPresence(boardID, PresenceEventFocus, viewer)
+	}
+}
+
+// heartbeat keeps the listener among the viewers of its boards, adding
+// it back to the ones it expired from.
+func (ws *Server) heartbeat(listener *websocketSession) {
+	ws.expirePresence()
+
+	ws.mu.RLock()
+	boards := append([]string{}, listener.boards...)
+	ws.mu.RUnlock()
+
+	viewer := BoardViewer{UserID: listener.userID, ConnectionID: listener.id}
+	for _, boardID := range boards {
+		if ws.presence.join(boardID, viewer) {
+			ws.broadcastPresence(boardID, PresenceEventJoin, viewer)
 		}
 	}
 }
 
-func (ws *Server) BroadcastCategoryBoardsReorder(teamID, userID, categoryID string, boardOrder []string) {
-	message := CategoryBoardReorderMessage{
-		Action:     websocketActionReorderCategoryBoards,
-		CategoryID: categoryID,
-		BoardOrder: boardOrder,
-		TeamID:     teamID,
+// expirePresence removes the viewers that stopped sending heartbeats.
+// It runs whenever a client sends a presence command, as the viewers of
+// a board send heartbeats regularly.
+func (ws *Server) expirePresence() {
+	for boardID, viewers := range ws.presence.expire() {
+		for _, viewer := range viewers {
+			ws.broadcastPresence(boardID, PresenceEventLeave, viewer)
+		}
 	}
+}
 
-	listener := ws.getListenerForUser(teamID, userID)
-	if listener != nil {
-		ws.logger.Debug("Broadcast board category order change",
-			mlog.String("userID", userID),
-			mlog.String("teamID", teamID),
-			mlog.String("categoryID", categoryID),
+// broadcastPresence sends a presence event to the listeners subscribed
+// to a board, but the one of the viewer.
+func (ws *Server) broadcastPresence(boardID, event string, viewer BoardViewer) {
+	message := BoardPresenceMsg{
+		Action:  websocketActionBoardPresence,
+		BoardID: boardID,
+		Event:   event,
+		Viewer:  viewer,
+	}
+
+	ws.mu.RLock()
+	listeners := []*websocketSession{}
+	for _, listener := range ws.listenersByBoard[boardID] {
+		if listener.id != viewer.ConnectionID {
+			listeners = append(listeners, listener)
+		}
+	}
+	ws.mu.RUnlock()
+
+	for _, listener := range listeners {
+		ws.logger.Debug("Broadcast board presence",
+			mlog.String("boardID", boardID),
+			mlog.String("event", event),
+			mlog.String("userID", viewer.UserID),
 			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
 		)
 
 		if err := listener.WriteJSON(message); err != nil {
-			ws.logger.Error("broadcast category boards order change error", mlog.Err(err))
+			ws.logger.Error("broadcast error", mlog.Err(err))
 			listener.conn.Close()
 		}
 	}
 }
 
-func (ws *Server) BroadcastCategoryBoardChange(teamID, userID string, boardCategories []*model.BoardCategoryWebsocketData) {
-	message := UpdateCategoryMessage{
-		Action:          websocketActionUpdateCategoryBoard,
-		TeamID:          teamID,
-		BoardCategories: boardCategories,
+// lazyBlockFilter returns a block filter that is only built the first
+// time it's used, as the block may not be sent to anyone.
+func (ws *Server) lazyBlockFilter(block *model.Block) func(userID string) *model.Block {
+	var once sync.Once
+	var filter func(userID string) *model.Block
+	return func(userID string) *model.Block {
+		once.Do(func() {
+			filter = newBlockFilter(ws.store, ws.logger, block)
+		})
+		if filter == nil {
+			return block
+		}
+		return filter(userID)
 	}
+}
 
-	listener := ws.getListenerForUser(teamID, userID)
-	if listener != nil {
-		ws.logger.Debug("Broadcast category board change",
-			mlog.String("userID", userID),
-			mlog.String("teamID", teamID),
-			mlog.Int("numEntries", len(boardCategories)),
-			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
-		)
+// BroadcastBlockDelete broadcasts delete messages to clients.
+func (ws *Server) BroadcastBlockDelete(teamID, blockID, boardID string) {
+	now := utils.GetMillis()
+	block := &model.Block{}
+	block.ID = blockID
+	block.BoardID = boardID
+	block.UpdateAt = now
+	block.DeleteAt = now
 
-		if err := listener.WriteJSON(message); err != nil {
-			ws.logger.Error("broadcast category change error", mlog.Err(err))
-			listener.conn.Close()
+	ws.BroadcastBlockChange(teamID, block)
+}
+
+// BroadcastBlockChange broadcasts update messages to clients.
+func (ws *Server) BroadcastBlockChange(teamID string, block *model.Block) {
+	ws.textBlockChanged(block)
+
+	blockIDsToNotify := []string{block.ID, block.ParentID}
+
+	filter := ws.lazyBlockFilter(block)
+	blockMessage := func(epoch string, seq int64, userID string) interface{} {
+		filtered := filter(userID)
+		if filtered == nil {
+			return nil
+		}
+		return UpdateBlockMsg{
+			Action:   websocketActionUpdateBlock,
+			TeamID:   teamID,
+			Block:    filtered,
+			Sequence: seq,
+			Epoch:    epoch,
 		}
 	}
+
+	// the members of the board are only needed for the listeners that
+	// get the changes of all the boards of the team, and for replays
+	memberIDs := ws.lazyBoardMemberIDs(teamID, block.BoardID)
+	var teamWideMemberIDs map[string]bool
+	if ws.hasTeamWideListeners(teamID) {
+		teamWideMemberIDs = memberIDs()
+	}
+
+	ws.publish(teamID, "Broadcast block change", func(epoch string, seq int64, userID string) interface{} {
+		if !memberIDs()[userID] {
+			return nil
+		}
+		return blockMessage(epoch, seq, userID)
+	}, blockMessage, func() []*websocketSession {
+		listeners := ws.getListenersForBoard(teamID, block.BoardID, teamWideMemberIDs, false)
+		for _, blockID := range blockIDsToNotify {
+			listeners = append(listeners, ws.getListenersForBlock(blockID)...)
+		}
+		return listeners
+	})
+}
+
+// broadcastToUser sends a team event to the listeners of a user.
+func (ws *Server) broadcastToUser(teamID, userID, logMessage string, message eventMessage) {
+	forUser := func(epoch string, seq int64, id string) interface{} {
+		if id != userID {
+			return nil
+		}
+		return message(epoch, seq, id)
+	}
+	ws.publish(teamID, logMessage, forUser, forUser, func() []*websocketSession {
+		listeners := []*websocketSession{}
+		for _, listener := range ws.listenersByTeam[teamID] {
+			if listener.userID == userID {
+				listeners = append(listeners, listener)
+			}
+		}
+		return listeners
+	})
+}
+
+// broadcastToBoardMembers sends a team event to the listeners of the
+// members of a board, and to the listeners subscribed to the board.
+func (ws *Server) broadcastToBoardMembers(teamID, boardID, logMessage string, message eventMessage, ensureUsers ...string) {
+	memberIDs := ws.getBoardMemberIDs(teamID, boardID, ensureUsers...)
+	ws.publish(teamID, logMessage, func(epoch string, seq int64, userID string) interface{} {
+		if !memberIDs[userID] {
+			return nil
+		}
+		return message(epoch, seq, userID)
+	}, message, func() []*websocketSession {
+		return ws.getListenersForBoard(teamID, boardID, memberIDs, true)
+	})
+}
+
+func (ws *Server) BroadcastCategoryChange(category model.Category) {
+	ws.broadcastToUser(category.TeamID, category.UserID, "Broadcast category change", func(epoch string, seq int64, _ string) interface{} {
+		return UpdateCategoryMessage{
+			Action:   websocketActionUpdateCategory,
+			TeamID:   category.TeamID,
+			Category: &category,
+			Sequence: seq,
+			Epoch:    epoch,
+		}
+	})
+}
+
+func (ws *Server) BroadcastCategoryReorder(teamID, userID string, categoryOrder []string) {
+	ws.broadcastToUser(teamID, userID, "Broadcast category order change", func(epoch string, seq int64, _ string) interface{} {
+		return CategoryReorderMessage{
+			Action:        websocketActionReorderCategories,
+			CategoryOrder: categoryOrder,
+			TeamID:        teamID,
+			Sequence:      seq,
+			Epoch:         epoch,
+		}
+	})
+}
+
+func (ws *Server) BroadcastCategoryBoardsReorder(teamID, userID, categoryID string, boardOrder []string) {
+	ws.broadcastToUser(teamID, userID, "Broadcast board category order change", func(epoch string, seq int64, _ string) interface{} {
+		return CategoryBoardReorderMessage{
+			Action:     websocketActionReorderCategoryBoards,
+			CategoryID: categoryID,
+			BoardOrder: boardOrder,
+			TeamID:     teamID,
+			Sequence:   seq,
+			Epoch:      epoch,
+		}
+	})
+}
+
+func (ws *Server) BroadcastCategoryBoardChange(teamID, userID string, boardCategories []*model.BoardCategoryWebsocketData) {
+	ws.broadcastToUser(teamID, userID, "Broadcast category board change", func(epoch string, seq int64, _ string) interface{} {
+		return UpdateCategoryMessage{
+			Action:          websocketActionUpdateCategoryBoard,
+			TeamID:          teamID,
+			BoardCategories: boardCategories,
+			Sequence:        seq,
+			Epoch:           epoch,
+		}
+	})
 }
 
 // BroadcastConfigChange broadcasts update messages to clients.
@@ -682,32 +1267,15 @@ func (ws *Server) BroadcastConfigChange(clientConfig model.ClientConfig) {
 }
 
 func (ws *Server) BroadcastBoardChange(teamID string, board *model.Board) {
-	message := UpdateBoardMsg{
-		Action: websocketActionUpdateBoard,
-		TeamID: teamID,
-		Board:  board,
-	}
-
-	listeners := ws.getListenersForTeamAndBoard(teamID, board.ID)
-	ws.logger.Trace("listener(s) for teamID and boardID",
-		mlog.Int("listener_count", len(listeners)),
-		mlog.String("teamID", teamID),
-		mlog.String("boardID", board.ID),
-	)
-
-	for _, listener := range listeners {
-		ws.logger.Debug("Broadcast board change",
-			mlog.String("teamID", teamID),
-			mlog.String("boardID", board.ID),
-			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
-		)
-
-		err := listener.WriteJSON(message)
-		if err != nil {
-			ws.logger.Error("broadcast error", mlog.Err(err))
-			listener.conn.Close()
+	ws.broadcastToBoardMembers(teamID, board.ID, "Broadcast board change", func(epoch string, seq int64, _ string) interface{} {
+		return UpdateBoardMsg{
+			Action:   websocketActionUpdateBoard,
+			TeamID:   teamID,
+			Board:    board,
+			Sequence: seq,
+			Epoch:    epoch,
 		}
-	}
+	})
 }
 
 func (ws *Server) BroadcastBoardDelete(teamID, boardID string) {
@@ -722,61 +1290,62 @@ func (ws *Server) BroadcastBoardDelete(teamID, boardID string) {
 }
 
 func (ws *Server) BroadcastMemberChange(teamID, boardID string, member *model.BoardMember) {
-	message := UpdateMemberMsg{
-		Action: websocketActionUpdateMember,
-		TeamID: teamID,
-		Member: member,
-	}
-
-	listeners := ws.getListenersForTeamAndBoard(teamID, boardID)
-	ws.logger.Trace("listener(s) for teamID and boardID",
-		mlog.Int("listener_count", len(listeners)),
-		mlog.String("teamID", teamID),
-		mlog.String("boardID", boardID),
-	)
-
-	for _, listener := range listeners {
-		ws.logger.Debug("Broadcast member change",
-			mlog.String("teamID", teamID),
-			mlog.String("boardID", boardID),
-			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
-		)
-
-		err := listener.WriteJSON(message)
-		if err != nil {
-			ws.logger.Error("broadcast error", mlog.Err(err))
-			listener.conn.Close()
+	ws.broadcastToBoardMembers(teamID, boardID, "Broadcast member change", func(epoch string, seq int64, _ string) interface{} {
+		return UpdateMemberMsg{
+			Action:   websocketActionUpdateMember,
+			TeamID:   teamID,
+			Member:   member,
+			Sequence: seq,
+			Epoch:    epoch,
 		}
-	}
+	})
 }
 
 func (ws *Server) BroadcastMemberDelete(teamID, boardID, userID string) {
-	message := UpdateMemberMsg{
-		Action: websocketActionDeleteMember,
-		TeamID: teamID,
-		Member: &model.BoardMember{UserID: userID, BoardID: boardID},
-	}
-
 	// when fetching the members of the board that should receive the
 	// member deletion message, the deleted member will not be one of
 	// them, so we need to ensure they receive the message
-	listeners := ws.getListenersForTeamAndBoard(teamID, boardID, userID)
-	ws.logger.Trace("listener(s) for teamID and boardID",
-		mlog.Int("listener_count", len(listeners)),
-		mlog.String("teamID", teamID),
-		mlog.String("boardID", boardID),
-	)
+	ws.broadcastToBoardMembers(teamID, boardID, "Broadcast member removal", func(epoch string, seq int64, _ string) interface{} {
+		return UpdateMemberMsg{
+			Action:   websocketActionDeleteMember,
+			TeamID:   teamID,
+			Member:   &model.BoardMember{UserID: userID, BoardID: boardID},
+			Sequence: seq,
+			Epoch:    epoch,
+		}
+	}, userID)
+
+	ws.checkBoardSubscriptions(boardID, userID)
+}
+
+// checkBoardSubscriptions unsubscribes the listeners of a user from a
+// board if the user can't see it anymore.
+func (ws *Server) checkBoardSubscriptions(boardID, userID string) {
+	ws.mu.RLock()
+	listeners := []*websocketSession{}
+	for _, listener := range ws.listenersByBoard[boardID] {
+		if listener.userID == userID {
+			listeners = append(listeners, listener)
+		}
+	}
+	ws.mu.RUnlock()
+
+	if len(listeners) == 0 || ws.hasBoardAccess(listeners[0], boardID) {
+		return
+	}
 
 	for _, listener := range listeners {
-		ws.logger.Debug("Broadcast member removal",
-			mlog.String("teamID", teamID),
+		ws.logger.Debug("checkBoardSubscriptions: unsubscribing a listener that lost access to the board",
 			mlog.String("boardID", boardID),
-			mlog.Stringer("remoteAddr", listener.conn.RemoteAddr()),
+			mlog.String("userID", userID),
+			mlog.Stringer("client", listener.conn.RemoteAddr()),
 		)
+		ws.unsubscribeListenerFromBoard(listener, boardID)
+		ws.leaveTextBlocks(listener, func(doc *textDocument) bool { return doc.boardID == boardID })
 
-		err := listener.WriteJSON(message)
-		if err != nil {
-			ws.logger.Error("broadcast error", mlog.Err(err))
+		message := AccessRevokedMsg{Action: websocketActionAccessRevoked, BoardID: boardID}
+		if err := listener.WriteJSON(message); err != nil {
+			ws.logger.Error("access revoked error", mlog.Err(err))
 			listener.conn.Close()
 		}
 	}
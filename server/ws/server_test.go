@@ -1,20 +1,32 @@
 package ws
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/mattermost/focalboard/server/auth"
 	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/store/mockstore"
+	"github.com/mattermost/focalboard/server/utils"
+	wsMocks "github.com/mattermost/focalboard/server/ws/mocks"
 
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTeamSubscription(t *testing.T) {
-	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, nil)
+	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, nil, nil)
 	session := &websocketSession{
 		conn:   &websocket.Conn{},
 		mu:     sync.Mutex{},
@@ -146,7 +158,7 @@ func TestTeamSubscription(t *testing.T) {
 }
 
 func TestBlocksSubscription(t *testing.T) {
-	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, nil)
+	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, nil, nil)
 	session := &websocketSession{
 		conn:   &websocket.Conn{},
 		mu:     sync.Mutex{},
@@ -266,7 +278,7 @@ func TestBlocksSubscription(t *testing.T) {
 
 func TestGetUserIDForTokenInSingleUserMode(t *testing.T) {
 	singleUserToken := "single-user-token"
-	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, nil)
+	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, nil, nil)
 	server.singleUserToken = singleUserToken
 
 	t.Run("Should return nothing if the token is empty", func(t *testing.T) {
@@ -281,3 +293,683 @@ func TestGetUserIDForTokenInSingleUserMode(t *testing.T) {
 		require.Equal(t, model.SingleUser, server.getUserIDForToken(singleUserToken))
 	})
 }
+
+func TestCloseSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mockstore.NewMockStore(ctrl)
+	cfg := &config.Configuration{SessionExpireTime: 60, SessionRefreshTime: 60}
+	server := NewServer(auth.New(cfg, mockStore, nil), "", false, mlog.CreateConsoleTestLogger(t), nil, nil)
+
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	connect := func(token, sessionID string) *websocket.Conn {
+		mockStore.EXPECT().GetSession(token, cfg.SessionExpireTime).
+			Return(&model.Session{ID: sessionID, UserID: "user-id", UpdateAt: utils.GetMillis()}, nil)
+
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: token}))
+		return conn
+	}
+
+	authenticatedSessions := func() []string {
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+		sessionIDs := []string{}
+		for listener := range server.listeners {
+			if listener.sessionID != "" {
+				sessionIDs = append(sessionIDs, listener.sessionID)
+			}
+		}
+		return sessionIDs
+	}
+
+	revoked := connect("token-1", "session-1")
+	kept := connect("token-2", "session-2")
+	require.Eventually(t, func() bool { return len(authenticatedSessions()) == 2 }, time.Second, 10*time.Millisecond)
+
+	server.CloseSessions("session-1")
+
+	require.NoError(t, revoked.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err := revoked.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	require.Equal(t, CloseCodeSessionRevoked, closeErr.Code)
+
+	// the listener is removed right away
+	require.Equal(t, []string{"session-2"}, authenticatedSessions())
+	require.NoError(t, kept.WriteJSON(WebsocketCommand{Action: websocketActionUnsubscribeTeam, TeamID: "team-id"}))
+}
+
+func TestResume(t *testing.T) {
+	singleUserToken := "single-user-token"
+	server := NewServer(&auth.Auth{}, singleUserToken, false, mlog.CreateConsoleTestLogger(t), nil, nil)
+	teamID := "team-id"
+
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	teamListeners := func() int {
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+		return len(server.listenersByTeam[teamID])
+	}
+
+	resume := func(epoch string, seq int64) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: singleUserToken}))
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionResume, TeamID: teamID, Epoch: epoch, Sequence: seq}))
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		return conn
+	}
+
+	broadcast := func(categoryID string) {
+		server.BroadcastCategoryChange(model.Category{ID: categoryID, TeamID: teamID, UserID: model.SingleUser})
+	}
+
+	readCategory := func(conn *websocket.Conn) UpdateCategoryMessage {
+		var message UpdateCategoryMessage
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionUpdateCategory, message.Action)
+		return message
+	}
+
+	conn := resume("", 0)
+	require.Eventually(t, func() bool { return teamListeners() == 1 }, time.Second, 10*time.Millisecond)
+
+	broadcast("category-1")
+	first := readCategory(conn)
+	require.Equal(t, "category-1", first.Category.ID)
+	require.NotZero(t, first.Sequence)
+	require.NotEmpty(t, first.Epoch)
+
+	conn.Close()
+	require.Eventually(t, func() bool { return teamListeners() == 0 }, time.Second, 10*time.Millisecond)
+
+	broadcast("category-2")
+	broadcast("category-3")
+
+	t.Run("the missed events are replayed in order", func(t *testing.T) {
+		conn := resume(first.Epoch, first.Sequence)
+
+		message := readCategory(conn)
+		require.Equal(t, "category-2", message.Category.ID)
+		require.Equal(t, first.Sequence+1, message.Sequence)
+
+		message = readCategory(conn)
+		require.Equal(t, "category-3", message.Category.ID)
+		require.Equal(t, first.Sequence+2, message.Sequence)
+
+		broadcast("category-4")
+		message = readCategory(conn)
+		require.Equal(t, "category-4", message.Category.ID)
+		require.Equal(t, first.Sequence+3, message.Sequence)
+	})
+
+	t.Run("unknown sequences require a resync", func(t *testing.T) {
+		conn := resume(first.Epoch, 1)
+
+		var message ResyncMsg
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionResync, message.Action)
+		require.Equal(t, teamID, message.TeamID)
+		require.Equal(t, first.Sequence+3, message.Sequence)
+		require.Equal(t, first.Epoch, message.Epoch)
+	})
+
+	t.Run("sequences of another epoch require a resync", func(t *testing.T) {
+		for _, epoch := range []string{"", "other-epoch"} {
+			conn := resume(epoch, first.Sequence+1)
+
+			var message ResyncMsg
+			require.NoError(t, conn.ReadJSON(&message))
+			require.Equal(t, websocketActionResync, message.Action)
+			require.Equal(t, first.Epoch, message.Epoch)
+		}
+	})
+}
+
+func TestConcurrentBroadcastOrder(t *testing.T) {
+	singleUserToken := "single-user-token"
+	server := NewServer(&auth.Auth{}, singleUserToken, false, mlog.CreateConsoleTestLogger(t), nil, nil)
+	teamID := "team-id"
+
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: singleUserToken}))
+	require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionResume, TeamID: teamID}))
+	require.Eventually(t, func() bool {
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+		return len(server.listenersByTeam[teamID]) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	const broadcasters = 20
+	const broadcastsEach = 5
+
+	var wg sync.WaitGroup
+	for i := 0; i < broadcasters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < broadcastsEach; j++ {
+				server.BroadcastCategoryChange(model.Category{
+					ID:     fmt.Sprintf("category-%d-%d", i, j),
+					TeamID: teamID,
+					UserID: model.SingleUser,
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// the events are received in the order of their sequence
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	var last int64
+	for i := 0; i < broadcasters*broadcastsEach; i++ {
+		var message UpdateCategoryMessage
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionUpdateCategory, message.Action)
+		if last != 0 {
+			require.Equal(t, last+1, message.Sequence)
+		}
+		last = message.Sequence
+	}
+}
+
+func TestBoardSubscription(t *testing.T) {
+	singleUserToken := "single-user-token"
+	ctrl := gomock.NewController(t)
+	mockStore := wsMocks.NewMockStore(ctrl)
+	server := NewServer(&auth.Auth{}, singleUserToken, false, mlog.CreateConsoleTestLogger(t), mockStore, nil)
+	teamID := "team-id"
+
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	listenerCount := func(listeners map[string][]*websocketSession, id string) func() bool {
+		return func() bool {
+			server.mu.RLock()
+			defer server.mu.RUnlock()
+			return len(listeners[id]) == 1
+		}
+	}
+
+	connect := func(commands ...WebsocketCommand) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: singleUserToken}))
+		for _, command := range commands {
+			require.NoError(t, conn.WriteJSON(command))
+		}
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		return conn
+	}
+
+	readBlockID := func(conn *websocket.Conn) string {
+		var message UpdateBlockMsg
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionUpdateBlock, message.Action)
+		return message.Block.ID
+	}
+
+	board1Conn := connect(
+		WebsocketCommand{Action: websocketActionSubscribeTeam, TeamID: teamID},
+		WebsocketCommand{Action: websocketActionSubscribeBoard, BoardID: "board-1"},
+	)
+	board2Conn := connect(
+		WebsocketCommand{Action: websocketActionSubscribeTeam, TeamID: teamID},
+		WebsocketCommand{Action: websocketActionSubscribeBoard, BoardID: "board-2"},
+	)
+	require.Eventually(t, listenerCount(server.listenersByBoard, "board-1"), time.Second, 10*time.Millisecond)
+	require.Eventually(t, listenerCount(server.listenersByBoard, "board-2"), time.Second, 10*time.Millisecond)
+
+	// subscribing to a board sends its viewers first
+	for _, conn := range []*websocket.Conn{board1Conn, board2Conn} {
+		var message BoardViewersMsg
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionBoardViewers, message.Action)
+	}
+
+	t.Run("block changes only reach the board listeners", func(t *testing.T) {
+		// no board members are fetched, as all the listeners are
+		// subscribed to boards
+		server.BroadcastBlockChange(teamID, &model.Block{ID: "block-1", BoardID: "board-1", Type: model.TypeText})
+		server.BroadcastBlockChange(teamID, &model.Block{ID: "block-2", BoardID: "board-2", Type: model.TypeText})
+
+		require.Equal(t, "block-1", readBlockID(board1Conn))
+		require.Equal(t, "block-2", readBlockID(board2Conn))
+	})
+
+	t.Run("team listeners not viewing any board get all the changes", func(t *testing.T) {
+		teamConn := connect(WebsocketCommand{Action: websocketActionSubscribeTeam, TeamID: teamID})
+		require.Eventually(t, func() bool {
+			server.mu.RLock()
+			defer server.mu.RUnlock()
+			return len(server.listenersByTeam[teamID]) == 3
+		}, time.Second, 10*time.Millisecond)
+
+		mockStore.EXPECT().GetMembersForBoard("board-1").
+			Return([]*model.BoardMember{{BoardID: "board-1", UserID: model.SingleUser}}, nil)
+		server.BroadcastBlockChange(teamID, &model.Block{ID: "block-3", BoardID: "board-1", Type: model.TypeText})
+
+		require.Equal(t, "block-3", readBlockID(board1Conn))
+		require.Equal(t, "block-3", readBlockID(teamConn))
+	})
+
+	t.Run("board subscriptions are removed with the listener", func(t *testing.T) {
+		require.NoError(t, board1Conn.WriteJSON(WebsocketCommand{Action: websocketActionUnsubscribeBoard, BoardID: "board-1"}))
+		require.Eventually(t, func() bool {
+			server.mu.RLock()
+			defer server.mu.RUnlock()
+			return len(server.listenersByBoard["board-1"]) == 0
+		}, time.Second, 10*time.Millisecond)
+
+		board2Conn.Close()
+		require.Eventually(t, func() bool {
+			server.mu.RLock()
+			defer server.mu.RUnlock()
+			return len(server.listenersByBoard) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestBoardPresence(t *testing.T) {
+	singleUserToken := "single-user-token"
+	server := NewServer(&auth.Auth{}, singleUserToken, false, mlog.CreateConsoleTestLogger(t), nil, nil)
+
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	connect := func() *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: singleUserToken}))
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionSubscribeBoard, BoardID: "board-1"}))
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		return conn
+	}
+
+	readPresence := func(conn *websocket.Conn) BoardPresenceMsg {
+		var message BoardPresenceMsg
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionBoardPresence, message.Action)
+		require.Equal(t, "board-1", message.BoardID)
+		return message
+	}
+
+	conn1 := connect()
+	var viewers BoardViewersMsg
+	require.NoError(t, conn1.ReadJSON(&viewers))
+	require.Equal(t, websocketActionBoardViewers, viewers.Action)
+	require.Empty(t, viewers.Viewers)
+
+	conn2 := connect()
+	require.NoError(t, conn2.ReadJSON(&viewers))
+	require.Len(t, viewers.Viewers, 1)
+	viewer1 := viewers.Viewers[0]
+	require.Equal(t, model.SingleUser, viewer1.UserID)
+
+	var viewer2 BoardViewer
+	t.Run("the viewers are told when someone opens the board", func(t *testing.T) {
+		message := readPresence(conn1)
+		require.Equal(t, PresenceEventJoin, message.Event)
+		require.Equal(t, model.SingleUser, message.Viewer.UserID)
+		require.NotEqual(t, viewer1.ConnectionID, message.Viewer.ConnectionID)
+		viewer2 = message.Viewer
+	})
+
+	t.Run("the viewers are told which card someone is viewing", func(t *testing.T) {
+		require.NoError(t, conn2.WriteJSON(WebsocketCommand{Action: websocketActionFocusCard, BoardID: "board-1", CardID: "card-1"}))
+
+		message := readPresence(conn1)
+		require.Equal(t, PresenceEventFocus, message.Event)
+		require.Equal(t, viewer2.ConnectionID, message.Viewer.ConnectionID)
+		require.Equal(t, "card-1", message.Viewer.CardID)
+	})
+
+	t.Run("viewers that stop sending heartbeats expire", func(t *testing.T) {
+		server.presence.mu.Lock()
+		server.presence.boards["board-1"][viewer1.ConnectionID].lastSeen -= defaultPresenceTimeout.Milliseconds() + 1
+		server.presence.mu.Unlock()
+
+		require.NoError(t, conn2.WriteJSON(WebsocketCommand{Action: websocketActionHeartbeat}))
+		message := readPresence(conn2)
+		require.Equal(t, PresenceEventLeave, message.Event)
+		require.Equal(t, viewer1.ConnectionID, message.Viewer.ConnectionID)
+
+		// the expired viewer gets back with its next heartbeat
+		require.NoError(t, conn1.WriteJSON(WebsocketCommand{Action: websocketActionHeartbeat}))
+		message = readPresence(conn2)
+		require.Equal(t, PresenceEventJoin, message.Event)
+		require.Equal(t, viewer1.ConnectionID, message.Viewer.ConnectionID)
+	})
+
+	t.Run("the viewers are told when someone leaves the board", func(t *testing.T) {
+		conn2.Close()
+
+		message := readPresence(conn1)
+		require.Equal(t, PresenceEventLeave, message.Event)
+		require.Equal(t, viewer2.ConnectionID, message.Viewer.ConnectionID)
+		require.Len(t, server.presence.viewers("board-1"), 1)
+	})
+}
+
+func TestRevokeTeamAccess(t *testing.T) {
+	singleUserToken := "single-user-token"
+	server := NewServer(&auth.Auth{}, singleUserToken, false, mlog.CreateConsoleTestLogger(t), nil, nil)
+
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: singleUserToken}))
+	require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionSubscribeTeam, TeamID: "team-1"}))
+	require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionSubscribeTeam, TeamID: "team-2"}))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	teamListeners := func(teamID string) int {
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+		return len(server.listenersByTeam[teamID])
+	}
+	require.Eventually(t, func() bool { return teamListeners("team-2") == 1 }, time.Second, 10*time.Millisecond)
+
+	server.RevokeTeamAccess("team-1", model.SingleUser)
+
+	var message AccessRevokedMsg
+	require.NoError(t, conn.ReadJSON(&message))
+	require.Equal(t, websocketActionAccessRevoked, message.Action)
+	require.Equal(t, "team-1", message.TeamID)
+
+	require.Zero(t, teamListeners("team-1"))
+	require.Equal(t, 1, teamListeners("team-2"))
+
+	t.Run("other users are kept", func(t *testing.T) {
+		server.RevokeTeamAccess("team-2", "other-user")
+		require.Equal(t, 1, teamListeners("team-2"))
+	})
+}
+
+var errTestSave = errors.New("unable to save the block")
+
+type testTextBlockSaver struct {
+	mu       sync.Mutex
+	titles   []string
+	userID   string
+	failures int
+}
+
+func (s *testTextBlockSaver) PatchBlock(blockID string, blockPatch *model.BlockPatch, modifiedByID string) (*model.Block, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return nil, errTestSave
+	}
+	s.titles = append(s.titles, *blockPatch.Title)
+	s.userID = modifiedByID
+	return &model.Block{ID: blockID, Title: *blockPatch.Title}, nil
+}
+
+func (s *testTextBlockSaver) failNext(failures int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = failures
+}
+
+func (s *testTextBlockSaver) lastTitle() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.titles) == 0 {
+		return ""
+	}
+	return s.titles[len(s.titles)-1]
+}
+
+func TestTextBlockEditing(t *testing.T) {
+	singleUserToken := "single-user-token"
+	ctrl := gomock.NewController(t)
+	mockStore := wsMocks.NewMockStore(ctrl)
+	server := NewServer(&auth.Auth{}, singleUserToken, false, mlog.CreateConsoleTestLogger(t), mockStore, nil)
+	server.textSaveDelay = 10 * time.Millisecond
+	saver := &testTextBlockSaver{}
+	server.SetTextBlockSaver(saver)
+
+	block := &model.Block{ID: "block-1", BoardID: "board-1", Type: model.TypeText, Title: "hello"}
+	mockStore.EXPECT().GetBlock("block-1").Return(block, nil).AnyTimes()
+	mockStore.EXPECT().AcquireLease("text_block_block-1", server.nodeID, gomock.Any()).Return(true, nil).AnyTimes()
+	mockStore.EXPECT().ReleaseLease("text_block_block-1", server.nodeID).Return(nil).AnyTimes()
+
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	join := func() *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: singleUserToken}))
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionJoinTextBlock, BoardID: "board-1", BlockID: "block-1"}))
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		return conn
+	}
+
+	edit := func(conn *websocket.Conn, revision int64, operation string) {
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{
+			"action":    websocketActionEditTextBlock,
+			"blockId":   "block-1",
+			"revision":  revision,
+			"operation": json.RawMessage(operation),
+		}))
+	}
+
+	readState := func(conn *websocket.Conn) TextBlockStateMsg {
+		var message TextBlockStateMsg
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionTextBlockState, message.Action)
+		return message
+	}
+
+	readAck := func(conn *websocket.Conn) int64 {
+		var message TextBlockAckMsg
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionTextBlockAck, message.Action)
+		return message.Revision
+	}
+
+	readOperation := func(conn *websocket.Conn) (int64, string) {
+		var message TextBlockOperationMsg
+		require.NoError(t, conn.ReadJSON(&message))
+		require.Equal(t, websocketActionTextBlockOperation, message.Action)
+		data, err := json.Marshal(message.Operation)
+		require.NoError(t, err)
+		return message.Revision, string(data)
+	}
+
+	conn1 := join()
+	state := readState(conn1)
+	require.Equal(t, int64(0), state.Revision)
+	require.Equal(t, "hello", state.Text)
+
+	conn2 := join()
+	require.Equal(t, "hello", readState(conn2).Text)
+
+	t.Run("concurrent edits are merged", func(t *testing.T) {
+		edit(conn1, 0, `[5, " world"]`)
+		require.Equal(t, int64(1), readAck(conn1))
+		revision, operation := readOperation(conn2)
+		require.Equal(t, int64(1), revision)
+		require.JSONEq(t, `[5, " world"]`, operation)
+
+		// the edit was made before the first one was received
+		edit(conn2, 0, `["Oh, ", 5]`)
+		require.Equal(t, int64(2), readAck(conn2))
+		revision, operation = readOperation(conn1)
+		require.Equal(t, int64(2), revision)
+		require.JSONEq(t, `["Oh, ", 11]`, operation)
+	})
+
+	t.Run("the edits are saved as the block title", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			return saver.lastTitle() == "Oh, hello world"
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, model.SingleUser, saver.userID)
+	})
+
+	t.Run("an edit that can't be merged gets the text back", func(t *testing.T) {
+		edit(conn2, 5, `[15, "!"]`)
+		state := readState(conn2)
+		require.Equal(t, int64(2), state.Revision)
+		require.Equal(t, "Oh, hello world", state.Text)
+
+		edit(conn2, 2, `[3, "!"]`)
+		require.Equal(t, "Oh, hello world", readState(conn2).Text)
+	})
+
+	t.Run("the changes made elsewhere replace the text", func(t *testing.T) {
+		// the broadcast of a saved snapshot isn't a change
+		server.BroadcastBlockChange("team-1", &model.Block{ID: "block-1", BoardID: "board-1", Type: model.TypeText, Title: "Oh, hello world"})
+		server.BroadcastBlockChange("team-1", &model.Block{ID: "block-1", BoardID: "board-1", Type: model.TypeText, Title: "bye", ModifiedBy: "user-2"})
+
+		for _, conn := range []*websocket.Conn{conn1, conn2} {
+			revision, operation := readOperation(conn)
+			require.Equal(t, int64(3), revision)
+			require.JSONEq(t, `["bye", -15]`, operation)
+		}
+	})
+
+	t.Run("the document is discarded once the editors leave and the edits are saved", func(t *testing.T) {
+		saver.failNext(2)
+		edit(conn1, 3, `[3, "!"]`)
+		require.Equal(t, int64(4), readAck(conn1))
+
+		require.NoError(t, conn1.WriteJSON(WebsocketCommand{Action: websocketActionLeaveTextBlock, BlockID: "block-1"}))
+		conn2.Close()
+		require.Eventually(t, func() bool {
+			server.textMu.Lock()
+			defer server.textMu.Unlock()
+			return len(server.textDocuments) == 0
+		}, time.Second, 10*time.Millisecond)
+		require.Eventually(t, func() bool {
+			return saver.lastTitle() == "bye!"
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestTextBlockEditedOnOtherNode(t *testing.T) {
+	singleUserToken := "single-user-token"
+	ctrl := gomock.NewController(t)
+	mockStore := wsMocks.NewMockStore(ctrl)
+	server := NewServer(&auth.Auth{}, singleUserToken, false, mlog.CreateConsoleTestLogger(t), mockStore, nil)
+
+	block := &model.Block{ID: "block-1", BoardID: "board-1", Type: model.TypeText, Title: "hello"}
+	mockStore.EXPECT().GetBlock("block-1").Return(block, nil)
+	mockStore.EXPECT().AcquireLease("text_block_block-1", server.nodeID, gomock.Any()).Return(false, nil)
+
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: singleUserToken}))
+	require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionJoinTextBlock, BoardID: "board-1", BlockID: "block-1"}))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	var message TextBlockClosedMsg
+	require.NoError(t, conn.ReadJSON(&message))
+	require.Equal(t, websocketActionTextBlockClosed, message.Action)
+	require.Equal(t, textBlockClosedOtherNode, message.Reason)
+
+	server.textMu.Lock()
+	defer server.textMu.Unlock()
+	require.Empty(t, server.textDocuments)
+}
+
+func TestTextBlockEditingAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := wsMocks.NewMockStore(ctrl)
+	server := NewServer(&auth.Auth{}, "", false, mlog.CreateConsoleTestLogger(t), mockStore, nil)
+
+	t.Run("read-only access tokens can't edit the cards", func(t *testing.T) {
+		session := &websocketSession{userID: "user-1", scopes: []string{model.AccessTokenScopeReadOnly}}
+		require.False(t, server.canEditBoardCards(session, "board-1"))
+	})
+
+	card := &model.Card{ID: "card-1", BoardID: "board-1", CreatedBy: "user-1", VisibleTo: []string{"user-1"}}
+	card.Populate()
+	mockStore.EXPECT().GetBlock("card-1").Return(model.Card2Block(card), nil).AnyTimes()
+	mockStore.EXPECT().GetMemberForBoard("board-1", gomock.Any()).DoAndReturn(func(boardID, userID string) (*model.BoardMember, error) {
+		return &model.BoardMember{BoardID: boardID, UserID: userID, SchemeEditor: true}, nil
+	}).AnyTimes()
+
+	t.Run("only the users that can see a private card edit its text blocks", func(t *testing.T) {
+		require.True(t, server.canViewCard(&websocketSession{userID: "user-1"}, "board-1", "card-1"))
+		require.False(t, server.canViewCard(&websocketSession{userID: "user-2"}, "board-1", "card-1"))
+		require.True(t, server.canViewCard(&websocketSession{userID: "user-2"}, "board-1", ""))
+	})
+
+	t.Run("the editors that can't see a card anymore stop editing its text blocks", func(t *testing.T) {
+		mockStore.EXPECT().GetMembersForBoard("board-1").Return([]*model.BoardMember{
+			{BoardID: "board-1", UserID: "user-1", SchemeEditor: true},
+			{BoardID: "board-1", UserID: "user-2", SchemeEditor: true},
+		}, nil)
+		mockStore.EXPECT().ReleaseLease("text_block_block-1", server.nodeID).Return(nil)
+
+		doc := newTextDocument(&model.Block{ID: "block-1", BoardID: "board-1", ParentID: "card-1", Type: model.TypeText})
+		editor := &websocketSession{id: "connection-1", userID: "user-2", send: make(chan interface{}, 1)}
+		doc.editors[editor.id] = editor
+		server.textMu.Lock()
+		server.textDocuments[doc.blockID] = doc
+		server.textMu.Unlock()
+
+		server.BroadcastBlockChange("team-1", model.Card2Block(card))
+
+		message, ok := (<-editor.send).(TextBlockClosedMsg)
+		require.True(t, ok)
+		require.Equal(t, textBlockClosedNoAccess, message.Reason)
+
+		server.textMu.Lock()
+		defer server.textMu.Unlock()
+		require.Empty(t, server.textDocuments)
+	})
+}
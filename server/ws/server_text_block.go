@@ -0,0 +1,469 @@
+package ws
+
+import (
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/permissions"
+	"github.com/mattermost/focalboard/server/services/scheduler"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// SetTextBlockSaver sets the service that saves the text blocks edited
+// over the websocket. The edits aren't saved until it's set.
+func (ws *Server) SetTextBlockSaver(saver TextBlockSaver) {
+	ws.textMu.Lock()
+	defer ws.textMu.Unlock()
+
+	ws.textBlockSaver = saver
+}
+
+// canEditBoardCards returns true if the listener can edit the cards of
+// a board. The listeners authenticated by an access token also need a
+// scope allowing it.
+func (ws *Server) canEditBoardCards(wsSession *websocketSession, boardID string) bool {
+	if !permissions.ScopesAllowPermission(wsSession.scopes, model.PermissionManageBoardCards) {
+		ws.logger.Error("WS access token scopes don't allow editing the board cards", mlog.String("boardID", boardID), mlog.String("userID", wsSession.userID))
+		return false
+	}
+
+	if len(ws.singleUserToken) != 0 {
+		return wsSession.userID == model.SingleUser
+	}
+
+	if !ws.auth.CanUserEditBoardCards(wsSession.userID, boardID) {
+		ws.logger.Error("WS user can't edit the board cards", mlog.String("boardID", boardID), mlog.String("userID", wsSession.userID))
+		return false
+	}
+	return true
+}
+
+// canViewCard returns true if the listener can see a card, which may be
+// private. Blocks that don't belong to a card have no card ID.
+func (ws *Server) canViewCard(wsSession *websocketSession, boardID, cardID string) bool {
+	if cardID == "" || len(ws.singleUserToken) != 0 {
+		return true
+	}
+
+	card, err := ws.store.GetBlock(cardID)
+	if err != nil {
+		ws.logger.Error("WS unable to get the card", mlog.String("cardID", cardID), mlog.Err(err))
+		return false
+	}
+	if !model.IsPrivateCard(card) {
+		return true
+	}
+
+	member, err := ws.store.GetMemberForBoard(boardID, wsSession.userID)
+	if err != nil && !model.IsErrNotFound(err) {
+		ws.logger.Error("WS unable to get the board member", mlog.String("boardID", boardID), mlog.Err(err))
+		return false
+	}
+	return model.CanViewCard(card, wsSession.userID, member)
+}
+
+// joinTextBlock adds the listener to the editors of a text block, and
+// sends it the text to start editing from. The documents are kept in
+// memory, so a node holds a lease on the blocks it edits: the listeners
+// connected to another node are told to edit the block through the API.
+func (ws *Server) joinTextBlock(listener *websocketSession, boardID, blockID string) {
+	if !ws.hasBoardAccess(listener, boardID) || !ws.canEditBoardCards(listener, boardID) {
+		return
+	}
+
+	block, err := ws.store.GetBlock(blockID)
+	if err != nil {
+		ws.logger.Error("joinTextBlock: unable to get the block", mlog.String("blockID", blockID), mlog.Err(err))
+		return
+	}
+	if block.BoardID != boardID || block.Type != model.TypeText || block.DeleteAt != 0 {
+		ws.logger.Error("joinTextBlock: not a text block of the board",
+			mlog.String("boardID", boardID),
+			mlog.String("blockID", blockID),
+		)
+		return
+	}
+	if !ws.canViewCard(listener, boardID, model.CardParentID(block)) {
+		ws.sendTextBlockClosed(listener, boardID, blockID, textBlockClosedNoAccess)
+		return
+	}
+
+	doc := ws.getTextDocument(block)
+	if doc == nil {
+		ws.sendTextBlockClosed(listener, boardID, blockID, textBlockClosedOtherNode)
+		return
+	}
+	defer doc.mu.Unlock()
+
+	doc.editors[listener.id] = listener
+	ws.sendTextBlockMessage(listener, doc.state())
+}
+
+// getTextDocument returns the locked document of a text block, creating
+// it if the node can take the lease of the block. It returns nil if
+// another node is editing the block.
+func (ws *Server) getTextDocument(block *model.Block) *textDocument {
+	ws.textMu.Lock()
+	if doc, ok := ws.textDocuments[block.ID]; ok {
+		doc.mu.Lock()
+		ws.textMu.Unlock()
+		return doc
+	}
+	ws.textMu.Unlock()
+
+	acquired, err := ws.store.AcquireLease(textBlockLeaseName(block.ID), ws.nodeID, utils.GetMillis()+textBlockLeaseDuration.Milliseconds())
+	if err != nil {
+		ws.logger.Error("getTextDocument: unable to acquire the lease", mlog.String("blockID", block.ID), mlog.Err(err))
+		return nil
+	}
+	if !acquired {
+		ws.logger.Debug("getTextDocument: the block is edited through another node", mlog.String("blockID", block.ID))
+		return nil
+	}
+
+	ws.textMu.Lock()
+	doc, ok := ws.textDocuments[block.ID]
+	if !ok {
+		doc = newTextDocument(block)
+		doc.leaseTask = scheduler.CreateRecurringTask("textBlockLease", func() { ws.renewTextBlockLease(doc) }, textBlockLeaseDuration/3)
+		ws.textDocuments[block.ID] = doc
+	}
+	doc.mu.Lock()
+	ws.textMu.Unlock()
+	return doc
+}
+
+// renewTextBlockLease renews the lease of a document. If another node
+// took it, the document is saved and discarded, and its editors are told
+// to edit the block through the API.
+func (ws *Server) renewTextBlockLease(doc *textDocument) {
+	acquired, err := ws.store.AcquireLease(textBlockLeaseName(doc.blockID), ws.nodeID, utils.GetMillis()+textBlockLeaseDuration.Milliseconds())
+	if err != nil {
+		// the lease is renewed again before it expires
+		ws.logger.Error("renewTextBlockLease: unable to renew the lease", mlog.String("blockID", doc.blockID), mlog.Err(err))
+		return
+	}
+	if acquired {
+		return
+	}
+
+	ws.logger.Warn("renewTextBlockLease: the block is edited through another node", mlog.String("blockID", doc.blockID))
+	ws.textMu.Lock()
+	if ws.textDocuments[doc.blockID] == doc {
+		delete(ws.textDocuments, doc.blockID)
+	}
+	doc.mu.Lock()
+	editors := doc.editors
+	doc.editors = make(map[string]*websocketSession)
+	leaseTask := doc.leaseTask
+	doc.leaseTask = nil
+	doc.mu.Unlock()
+	ws.textMu.Unlock()
+
+	// the task waits for this function to return before being cancelled
+	go leaseTask.Cancel()
+
+	for _, editor := range editors {
+		ws.sendTextBlockClosed(editor, doc.boardID, doc.blockID, textBlockClosedOtherNode)
+	}
+	ws.saveTextBlock(doc)
+}
+
+// leaveTextBlock removes the listener from the editors of a text block.
+func (ws *Server) leaveTextBlock(listener *websocketSession, blockID string) {
+	ws.leaveTextBlocks(listener, func(doc *textDocument) bool {
+		return doc.blockID == blockID
+	})
+}
+
+// leaveTextBlocks removes the listener from the editors of the text
+// blocks matching the filter. The documents left without editors are
+// discarded once saved.
+func (ws *Server) leaveTextBlocks(listener *websocketSession, match func(doc *textDocument) bool) {
+	ws.textMu.Lock()
+	unused := []*textDocument{}
+	for _, doc := range ws.textDocuments {
+		if !match(doc) {
+			continue
+		}
+		doc.mu.Lock()
+		if _, ok := doc.editors[listener.id]; ok {
+			delete(doc.editors, listener.id)
+			if len(doc.editors) == 0 {
+				doc.stopSaveTimer()
+				unused = append(unused, doc)
+			}
+		}
+		doc.mu.Unlock()
+	}
+	ws.textMu.Unlock()
+
+	for _, doc := range unused {
+		ws.saveTextBlock(doc)
+	}
+}
+
+// discardTextDocument stops editing a text block and releases its lease,
+// unless the document got new editors or edits to save.
+func (ws *Server) discardTextDocument(doc *textDocument, force bool) {
+	ws.textMu.Lock()
+	doc.mu.Lock()
+	if doc.closed || (!force && (len(doc.editors) != 0 || doc.dirty)) {
+		doc.mu.Unlock()
+		ws.textMu.Unlock()
+		return
+	}
+	if ws.textDocuments[doc.blockID] == doc {
+		delete(ws.textDocuments, doc.blockID)
+	}
+	doc.closed = true
+	doc.dirty = false
+	doc.stopSaveTimer()
+	leaseTask := doc.leaseTask
+	doc.leaseTask = nil
+	doc.mu.Unlock()
+	ws.textMu.Unlock()
+
+	if leaseTask != nil {
+		leaseTask.Cancel()
+	}
+	if err := ws.store.ReleaseLease(textBlockLeaseName(doc.blockID), ws.nodeID); err != nil {
+		ws.logger.Warn("discardTextDocument: unable to release the lease", mlog.String("blockID", doc.blockID), mlog.Err(err))
+	}
+}
+
+// editTextBlock applies an operation of the listener to a text block.
+// The operation is acknowledged to the listener and sent to the other
+// editors. If it can't be merged, the listener gets the current text
+// and starts over from it. Listeners that can't edit or see the block
+// anymore stop editing it.
+func (ws *Server) editTextBlock(listener *websocketSession, blockID string, revision int64, operation *TextOperation) {
+	ws.textMu.Lock()
+	doc, ok := ws.textDocuments[blockID]
+	ws.textMu.Unlock()
+	if !ok {
+		ws.logger.Debug("editTextBlock: the block isn't being edited", mlog.String("blockID", blockID))
+		return
+	}
+
+	if !ws.canEditBoardCards(listener, doc.boardID) || !ws.canViewCard(listener, doc.boardID, doc.cardID) {
+		ws.leaveTextBlock(listener, blockID)
+		ws.sendTextBlockClosed(listener, doc.boardID, blockID, textBlockClosedNoAccess)
+		return
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+
+	if _, ok := doc.editors[listener.id]; !ok {
+		ws.logger.Error("editTextBlock: the listener isn't editing the block", mlog.String("blockID", blockID))
+		return
+	}
+
+	if operation == nil {
+		ws.sendTextBlockMessage(listener, doc.state())
+		return
+	}
+
+	applied, err := doc.apply(revision, operation)
+	if err != nil {
+		ws.logger.Debug("editTextBlock: unable to merge the operation, sending the text back",
+			mlog.String("blockID", blockID),
+			mlog.Int("revision", revision),
+			mlog.Int("currentRevision", doc.revision),
+			mlog.Err(err),
+		)
+		ws.sendTextBlockMessage(listener, doc.state())
+		return
+	}
+
+	doc.lastEditorID = listener.userID
+	doc.dirty = true
+	ws.scheduleTextBlockSave(doc)
+
+	ws.sendTextBlockMessage(listener, TextBlockAckMsg{
+		Action:   websocketActionTextBlockAck,
+		BoardID:  doc.boardID,
+		BlockID:  blockID,
+		Revision: doc.revision,
+	})
+	ws.broadcastTextOperation(doc, applied, listener.userID, listener.id)
+}
+
+// scheduleTextBlockSave saves the document after the save delay, unless
+// a save is already scheduled. The caller must hold the document lock.
+func (ws *Server) scheduleTextBlockSave(doc *textDocument) {
+	if doc.saveTimer == nil && !doc.closed {
+		doc.saveTimer = time.AfterFunc(ws.textSaveDelay, func() { ws.saveTextBlock(doc) })
+	}
+}
+
+// broadcastTextOperation sends an operation applied to a text block to
+// its editors, but the one that made it. The caller must hold the
+// document lock, so that the editors get the operations in order.
+func (ws *Server) broadcastTextOperation(doc *textDocument, operation *TextOperation, userID, connectionID string) {
+	message := TextBlockOperationMsg{
+		Action:       websocketActionTextBlockOperation,
+		BoardID:      doc.boardID,
+		BlockID:      doc.blockID,
+		Revision:     doc.revision,
+		UserID:       userID,
+		ConnectionID: connectionID,
+		Operation:    operation,
+	}
+	for id, editor := range doc.editors {
+		if id != connectionID {
+			ws.sendTextBlockMessage(editor, message)
+		}
+	}
+}
+
+// saveTextBlock saves the text of a document as the title of its block,
+// if it changed since the last snapshot. A failed save is retried after
+// the save delay, and the document is kept until its text is saved. Once
+// saved, the documents without editors are discarded.
+func (ws *Server) saveTextBlock(doc *textDocument) {
+	doc.saveMu.Lock()
+	defer doc.saveMu.Unlock()
+
+	doc.mu.Lock()
+	doc.saveTimer = nil
+	text, editorID, ok := doc.snapshot()
+	doc.mu.Unlock()
+	if !ok {
+		ws.discardTextDocument(doc, false)
+		return
+	}
+
+	ws.textMu.Lock()
+	saver := ws.textBlockSaver
+	ws.textMu.Unlock()
+	if saver == nil {
+		doc.mu.Lock()
+		doc.forgetPendingSave(text)
+		doc.mu.Unlock()
+		ws.discardTextDocument(doc, false)
+		return
+	}
+
+	_, err := saver.PatchBlock(doc.blockID, &model.BlockPatch{Title: &text}, editorID)
+	if model.IsErrNotFound(err) {
+		// the block is gone, so the edits can't be saved anymore
+		ws.logger.Warn("saveTextBlock: the text block doesn't exist anymore", mlog.String("blockID", doc.blockID))
+		ws.discardTextDocument(doc, true)
+		return
+	}
+	if err != nil {
+		ws.logger.Error("saveTextBlock: unable to save the text block, retrying", mlog.String("blockID", doc.blockID), mlog.Err(err))
+
+		doc.mu.Lock()
+		doc.forgetPendingSave(text)
+		doc.dirty = true
+		ws.scheduleTextBlockSave(doc)
+		doc.mu.Unlock()
+		return
+	}
+	ws.discardTextDocument(doc, false)
+}
+
+// textBlockChanged merges a change of a text block made outside of the
+// websocket edits, such as a patch through the API, replacing the text
+// being edited. Deleted blocks stop being edited. When a card changes,
+// the editors of its text blocks that can't see it anymore stop editing
+// them.
+func (ws *Server) textBlockChanged(block *model.Block) {
+	if block.Type == model.TypeCard {
+		ws.cardChanged(block)
+		return
+	}
+
+	ws.textMu.Lock()
+	doc, ok := ws.textDocuments[block.ID]
+	ws.textMu.Unlock()
+	if !ok {
+		return
+	}
+	if block.DeleteAt != 0 {
+		ws.discardTextDocument(doc, true)
+		return
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+
+	if doc.closed || doc.takePendingSave(block.Title) || block.Title == decodeText(doc.text) {
+		return
+	}
+
+	operation, err := doc.apply(doc.revision, newReplaceTextOperation(doc.text, encodeText(block.Title)))
+	if err != nil {
+		ws.logger.Error("textBlockChanged: unable to apply the change", mlog.String("blockID", block.ID), mlog.Err(err))
+		return
+	}
+	ws.broadcastTextOperation(doc, operation, block.ModifiedBy, "")
+}
+
+// cardChanged removes the editors of the text blocks of a card that
+// can't see it anymore.
+func (ws *Server) cardChanged(card *model.Block) {
+	if len(ws.singleUserToken) != 0 {
+		return
+	}
+
+	ws.textMu.Lock()
+	docs := []*textDocument{}
+	for _, doc := range ws.textDocuments {
+		if doc.cardID == card.ID {
+			docs = append(docs, doc)
+		}
+	}
+	ws.textMu.Unlock()
+	if len(docs) == 0 || !model.IsPrivateCard(card) {
+		return
+	}
+
+	members, err := ws.store.GetMembersForBoard(card.BoardID)
+	if err != nil {
+		ws.logger.Error("cardChanged: unable to get the board members", mlog.String("boardID", card.BoardID), mlog.Err(err))
+		return
+	}
+	membersByUserID := map[string]*model.BoardMember{}
+	for _, member := range members {
+		membersByUserID[member.UserID] = member
+	}
+
+	for _, doc := range docs {
+		doc.mu.Lock()
+		editors := []*websocketSession{}
+		for _, editor := range doc.editors {
+			editors = append(editors, editor)
+		}
+		doc.mu.Unlock()
+
+		for _, editor := range editors {
+			if card.DeleteAt == 0 && model.CanViewCard(card, editor.userID, membersByUserID[editor.userID]) {
+				continue
+			}
+			ws.leaveTextBlock(editor, doc.blockID)
+			ws.sendTextBlockClosed(editor, doc.boardID, doc.blockID, textBlockClosedNoAccess)
+		}
+	}
+}
+
+func (ws *Server) sendTextBlockClosed(listener *websocketSession, boardID, blockID, reason string) {
+	ws.sendTextBlockMessage(listener, TextBlockClosedMsg{
+		Action:  websocketActionTextBlockClosed,
+		BoardID: boardID,
+		BlockID: blockID,
+		Reason:  reason,
+	})
+}
+
+func (ws *Server) sendTextBlockMessage(listener *websocketSession, message interface{}) {
+	if err := listener.WriteJSON(message); err != nil {
+		ws.logger.Error("text block message error", mlog.Err(err))
+		listener.conn.Close()
+	}
+}
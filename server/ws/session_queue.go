@@ -0,0 +1,195 @@
+package ws
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/metrics"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// defaultSendQueueSize is the number of messages that can wait to be
+	// written to a client. Clients whose queue overflows are too slow to
+	// keep up, and are disconnected.
+	defaultSendQueueSize = 256
+
+	// maxBlocksPerMessage is the maximum number of block updates
+	// coalesced into a single message.
+	maxBlocksPerMessage = 100
+
+	// writeTimeout is the time after which a client that doesn't read
+	// its messages is disconnected.
+	writeTimeout = 10 * time.Second
+)
+
+var errSlowClient = errors.New("the websocket client is too slow, its queue is full")
+
+// messageBatch is a set of messages queued at once, such as a replay,
+// that takes a single place in the queue.
+type messageBatch []interface{}
+
+// newWebsocketSession creates a session and starts writing its queued
+// messages to the connection.
+func newWebsocketSession(conn *websocket.Conn, queueSize int, m *metrics.Metrics, logger mlog.LoggerIFace) *websocketSession {
+	wss := &websocketSession{
+		conn:    conn,
+		teams:   []string{},
+		boards:  []string{},
+		blocks:  []string{},
+		send:    make(chan interface{}, queueSize),
+		metrics: m,
+	}
+	go wss.writeMessages(logger)
+	return wss
+}
+
+// WriteJSON queues a message to be written to the connection. It
+// returns an error if the queue is full, in which case the connection
+// should be closed.
+func (wss *websocketSession) WriteJSON(v interface{}) error {
+	wss.mu.Lock()
+	defer wss.mu.Unlock()
+
+	return wss.queue(v)
+}
+
+// queue adds a message to the queue. The caller must hold the session
+// lock.
+func (wss *websocketSession) queue(v interface{}) error {
+	if wss.closed {
+		wss.metrics.IncrementWebsocketDroppedMessages(1)
+		return nil
+	}
+
+	select {
+	case wss.send <- v:
+		wss.metrics.AddWebsocketQueuedMessages(1)
+		return nil
+	default:
+		wss.metrics.IncrementWebsocketDroppedMessages(1)
+		wss.metrics.IncrementWebsocketSlowClients()
+		wss.closeQueue()
+		return errSlowClient
+	}
+}
+
+// close stops queuing messages. The writer drops the messages left in
+// the queue once the connection is closed.
+func (wss *websocketSession) close() {
+	wss.mu.Lock()
+	defer wss.mu.Unlock()
+
+	wss.closeQueue()
+}
+
+// closeQueue closes the queue if it isn't closed yet. The caller must
+// hold the session lock.
+func (wss *websocketSession) closeQueue() {
+	if !wss.closed {
+		wss.closed = true
+		close(wss.send)
+	}
+}
+
+// writeMessages writes the queued messages to the connection until the
+// queue is closed. The block updates queued in a row are coalesced, so
+// that a burst of changes is sent as a few messages.
+func (wss *websocketSession) writeMessages(logger mlog.LoggerIFace) {
+	for message := range wss.send {
+		wss.metrics.AddWebsocketQueuedMessages(-1)
+		messages := appendMessage(nil, message)
+
+	drain:
+		for len(messages) < maxBlocksPerMessage {
+			select {
+			case next, ok := <-wss.send:
+				if !ok {
+					break drain
+				}
+				wss.metrics.AddWebsocketQueuedMessages(-1)
+				messages = appendMessage(messages, next)
+			default:
+				break drain
+			}
+		}
+
+		coalesced := coalesceBlockUpdates(messages)
+		for i, m := range coalesced {
+			_ = wss.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := wss.conn.WriteJSON(m); err != nil {
+				// the connection is usually closed by the client
+				logger.Debug("websocket write error",
+					mlog.Stringer("client", wss.conn.RemoteAddr()),
+					mlog.Err(err),
+				)
+				wss.conn.Close()
+				wss.metrics.IncrementWebsocketDroppedMessages(len(coalesced) - i)
+				wss.discardMessages()
+				return
+			}
+		}
+	}
+}
+
+// discardMessages drops the queued messages until the queue is closed.
+func (wss *websocketSession) discardMessages() {
+	for message := range wss.send {
+		wss.metrics.AddWebsocketQueuedMessages(-1)
+		wss.metrics.IncrementWebsocketDroppedMessages(len(appendMessage(nil, message)))
+	}
+}
+
+func appendMessage(messages []interface{}, message interface{}) []interface{} {
+	if batch, ok := message.(messageBatch); ok {
+		return append(messages, batch...)
+	}
+	return append(messages, message)
+}
+
+// coalesceBlockUpdates merges the block updates of a team that follow
+// each other into a single message.
+func coalesceBlockUpdates(messages []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(messages))
+	var batch *UpdateBlocksMsg
+	var first UpdateBlockMsg
+
+	flush := func() {
+		if batch != nil && len(batch.Blocks) > 1 {
+			result = append(result, *batch)
+		} else if batch != nil {
+			result = append(result, first)
+		}
+		batch = nil
+	}
+
+	for _, message := range messages {
+		update, ok := message.(UpdateBlockMsg)
+		if !ok {
+			flush()
+			result = append(result, message)
+			continue
+		}
+
+		if batch != nil && (batch.TeamID != update.TeamID || len(batch.Blocks) >= maxBlocksPerMessage) {
+			flush()
+		}
+		if batch == nil {
+			first = update
+			batch = &UpdateBlocksMsg{
+				Action: websocketActionUpdateBlocks,
+				TeamID: update.TeamID,
+				Blocks: []*model.Block{},
+			}
+		}
+		batch.Blocks = append(batch.Blocks, update.Block)
+		batch.Sequence = update.Sequence
+		batch.Epoch = update.Epoch
+	}
+	flush()
+
+	return result
+}
@@ -0,0 +1,85 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoalesceBlockUpdates(t *testing.T) {
+	update := func(teamID, blockID string, seq int64) UpdateBlockMsg {
+		return UpdateBlockMsg{
+			Action:   websocketActionUpdateBlock,
+			TeamID:   teamID,
+			Block:    &model.Block{ID: blockID},
+			Sequence: seq,
+		}
+	}
+	category := UpdateCategoryMessage{Action: websocketActionUpdateCategory, TeamID: "team-1"}
+
+	t.Run("a single update is kept as is", func(t *testing.T) {
+		messages := []interface{}{update("team-1", "block-1", 1)}
+		require.Equal(t, messages, coalesceBlockUpdates(messages))
+	})
+
+	t.Run("updates in a row are merged", func(t *testing.T) {
+		result := coalesceBlockUpdates([]interface{}{
+			update("team-1", "block-1", 1),
+			update("team-1", "block-2", 2),
+			category,
+			update("team-1", "block-3", 4),
+			update("team-2", "block-4", 1),
+			update("team-2", "block-5", 2),
+		})
+
+		require.Len(t, result, 4)
+		batch, ok := result[0].(UpdateBlocksMsg)
+		require.True(t, ok)
+		require.Equal(t, websocketActionUpdateBlocks, batch.Action)
+		require.Equal(t, "team-1", batch.TeamID)
+		require.Len(t, batch.Blocks, 2)
+		require.Equal(t, int64(2), batch.Sequence)
+
+		require.Equal(t, category, result[1])
+		require.Equal(t, update("team-1", "block-3", 4), result[2])
+
+		batch, ok = result[3].(UpdateBlocksMsg)
+		require.True(t, ok)
+		require.Equal(t, "team-2", batch.TeamID)
+		require.Equal(t, "block-5", batch.Blocks[1].ID)
+	})
+
+	t.Run("batches are limited in size", func(t *testing.T) {
+		messages := []interface{}{}
+		for i := 0; i < maxBlocksPerMessage+1; i++ {
+			messages = append(messages, update("team-1", "block", int64(i)))
+		}
+
+		result := coalesceBlockUpdates(messages)
+		require.Len(t, result, 2)
+		require.Len(t, result[0].(UpdateBlocksMsg).Blocks, maxBlocksPerMessage)
+		require.IsType(t, UpdateBlockMsg{}, result[1])
+	})
+}
+
+func TestSessionQueue(t *testing.T) {
+	// the writer isn't started, so that the queue fills up
+	session := &websocketSession{send: make(chan interface{}, 2)}
+
+	require.NoError(t, session.WriteJSON("message-1"))
+	require.NoError(t, session.WriteJSON(messageBatch{"message-2", "message-3"}))
+	require.ErrorIs(t, session.WriteJSON("message-4"), errSlowClient)
+	require.True(t, session.closed)
+
+	// the messages are dropped once the queue is closed
+	require.NoError(t, session.WriteJSON("message-5"))
+
+	messages := []interface{}{}
+	for message := range session.send {
+		messages = appendMessage(messages, message)
+	}
+	require.Equal(t, []interface{}{"message-1", "message-2", "message-3"}, messages)
+
+	session.close()
+}
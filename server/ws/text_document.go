@@ -0,0 +1,161 @@
+package ws
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/scheduler"
+)
+
+const (
+	// defaultTextSaveDelay is the time after which the edits of a
+	// text block are saved as its title.
+	defaultTextSaveDelay = 5 * time.Second
+
+	// maxTextHistory is the number of operations kept to merge the
+	// edits of the clients that are behind.
+	maxTextHistory = 500
+
+	// textBlockLeaseDuration is the time after which the lease of a node
+	// editing a text block expires if it isn't renewed.
+	textBlockLeaseDuration = 30 * time.Second
+
+	// the reasons of the TextBlockClosedMsg
+	textBlockClosedNoAccess  = "no_access"
+	textBlockClosedOtherNode = "other_node"
+)
+
+var errTextRevision = errors.New("the revision of the operation is unknown")
+
+// TextBlockSaver saves the text blocks edited over the websocket.
+type TextBlockSaver interface {
+	PatchBlock(blockID string, blockPatch *model.BlockPatch, modifiedByID string) (*model.Block, error)
+}
+
+// textDocument is the text of a block being edited by several
+// connections at once. The edits are merged with operational
+// transformation: an operation made on an older revision is
+// transformed against the operations applied since.
+type textDocument struct {
+	mu sync.Mutex
+	// saveMu serializes the snapshots, so that they're saved in order
+	saveMu  sync.Mutex
+	boardID string
+	blockID string
+	// cardID is the card the block belongs to, if any
+	cardID   string
+	text     []uint16
+	revision int64
+	// history holds the operations that led to the last revisions
+	history []*TextOperation
+	editors map[string]*websocketSession
+	// lastEditorID is the user saved as the author of the snapshots
+	lastEditorID string
+	dirty        bool
+	saveTimer    *time.Timer
+	// pendingSaves are the snapshots saved but not yet broadcast, so
+	// that their broadcast isn't taken for an edit made elsewhere
+	pendingSaves []string
+	// leaseTask renews the lease that keeps the block edited by this
+	// node only
+	leaseTask *scheduler.ScheduledTask
+	// closed is set once the document is discarded
+	closed bool
+}
+
+func newTextDocument(block *model.Block) *textDocument {
+	return &textDocument{
+		boardID: block.BoardID,
+		blockID: block.ID,
+		cardID:  model.CardParentID(block),
+		text:    encodeText(block.Title),
+		editors: make(map[string]*websocketSession),
+	}
+}
+
+// apply merges an operation made on a revision of the text, and returns
+// the operation as applied to the current revision.
+func (d *textDocument) apply(revision int64, operation *TextOperation) (*TextOperation, error) {
+	historyStart := d.revision - int64(len(d.history))
+	if revision < historyStart || revision > d.revision {
+		return nil, errTextRevision
+	}
+
+	for _, concurrent := range d.history[revision-historyStart:] {
+		var err error
+		if operation, _, err = transformTextOperations(operation, concurrent); err != nil {
+			return nil, err
+		}
+	}
+
+	text, err := operation.apply(d.text)
+	if err != nil {
+		return nil, err
+	}
+
+	d.text = text
+	d.revision++
+	d.history = append(d.history, operation)
+	if len(d.history) > maxTextHistory {
+		d.history = d.history[len(d.history)-maxTextHistory:]
+	}
+	return operation, nil
+}
+
+// snapshot returns the text to save, if it changed since the last
+// snapshot.
+func (d *textDocument) snapshot() (string, string, bool) {
+	if !d.dirty {
+		return "", "", false
+	}
+	d.dirty = false
+	text := decodeText(d.text)
+	d.pendingSaves = append(d.pendingSaves, text)
+	return text, d.lastEditorID, true
+}
+
+func (d *textDocument) state() TextBlockStateMsg {
+	return TextBlockStateMsg{
+		Action:   websocketActionTextBlockState,
+		BoardID:  d.boardID,
+		BlockID:  d.blockID,
+		Revision: d.revision,
+		Text:     decodeText(d.text),
+	}
+}
+
+// takePendingSave returns true if the text is a snapshot saved by the
+// document, and forgets it and the ones saved before.
+func (d *textDocument) takePendingSave(text string) bool {
+	for i, saved := range d.pendingSaves {
+		if saved == text {
+			d.pendingSaves = d.pendingSaves[i+1:]
+			return true
+		}
+	}
+	return false
+}
+
+// forgetPendingSave removes a snapshot that couldn't be saved.
+func (d *textDocument) forgetPendingSave(text string) {
+	for i := len(d.pendingSaves) - 1; i >= 0; i-- {
+		if d.pendingSaves[i] == text {
+			d.pendingSaves = append(d.pendingSaves[:i], d.pendingSaves[i+1:]...)
+			return
+		}
+	}
+}
+
+// textBlockLeaseName returns the name of the lease of a text block.
+func textBlockLeaseName(blockID string) string {
+	return "text_block_" + blockID
+}
+
+func (d *textDocument) stopSaveTimer() {
+	if d.saveTimer != nil {
+		d.saveTimer.Stop()
+		d.saveTimer = nil
+	}
+}
@@ -0,0 +1,278 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf16"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// maxTextLength is the maximum length of the texts edited over the
+// websocket, and of the components of their operations. It's the maximum
+// size of a block title, which is larger than its length in UTF-16 code
+// units.
+const maxTextLength = model.BlockTitleMaxBytes
+
+var (
+	errTextOperationLength  = errors.New("the operation doesn't match the length of the text")
+	errTextOperationEmpty   = errors.New("empty operation component")
+	errTextOperationTooLong = errors.New("the operation is longer than the maximum length of a text")
+)
+
+type textComponentKind int
+
+const (
+	textRetain textComponentKind = iota
+	textInsert
+	textDelete
+)
+
+// textComponent is a step of a text operation. Lengths are counted in
+// UTF-16 code units, as in the clients.
+type textComponent struct {
+	kind textComponentKind
+	n    int
+	text []uint16
+}
+
+func (c textComponent) length() int {
+	if c.kind == textInsert {
+		return len(c.text)
+	}
+	return c.n
+}
+
+// TextOperation is an edit of a text, made of components that retain,
+// insert or delete characters from the start to the end of the text.
+// It's serialized as a list where a positive number retains characters,
+// a negative number deletes them and a string inserts it.
+type TextOperation struct {
+	components []textComponent
+	// baseLength is the length of the texts the operation applies to
+	baseLength int
+	// targetLength is the length of the text once the operation applied
+	targetLength int
+}
+
+func (o *TextOperation) retain(n int) *TextOperation {
+	if n == 0 {
+		return o
+	}
+	o.baseLength += n
+	o.targetLength += n
+	if last := len(o.components) - 1; last >= 0 && o.components[last].kind == textRetain {
+		o.components[last].n += n
+		return o
+	}
+	o.components = append(o.components, textComponent{kind: textRetain, n: n})
+	return o
+}
+
+// insert adds an insertion. An insertion that follows a deletion is
+// moved before it, so that equivalent operations have the same
+// components.
+func (o *TextOperation) insert(text []uint16) *TextOperation {
+	if len(text) == 0 {
+		return o
+	}
+	o.targetLength += len(text)
+
+	last := len(o.components) - 1
+	if last >= 0 && o.components[last].kind == textInsert {
+		o.components[last].text = append(o.components[last].text, text...)
+		return o
+	}
+	if last >= 0 && o.components[last].kind == textDelete {
+		if last >= 1 && o.components[last-1].kind == textInsert {
+			o.components[last-1].text = append(o.components[last-1].text, text...)
+			return o
+		}
+		deletion := o.components[last]
+		o.components[last] = textComponent{kind: textInsert, text: append([]uint16{}, text...)}
+		o.components = append(o.components, deletion)
+		return o
+	}
+	o.components = append(o.components, textComponent{kind: textInsert, text: append([]uint16{}, text...)})
+	return o
+}
+
+func (o *TextOperation) delete(n int) *TextOperation {
+	if n == 0 {
+		return o
+	}
+	o.baseLength += n
+	if last := len(o.components) - 1; last >= 0 && o.components[last].kind == textDelete {
+		o.components[last].n += n
+		return o
+	}
+	o.components = append(o.components, textComponent{kind: textDelete, n: n})
+	return o
+}
+
+// apply returns the text edited by the operation.
+func (o *TextOperation) apply(text []uint16) ([]uint16, error) {
+	if len(text) != o.baseLength {
+		return nil, errTextOperationLength
+	}
+
+	result := make([]uint16, 0, o.targetLength)
+	index := 0
+	for _, c := range o.components {
+		if c.kind != textInsert && (c.n <= 0 || c.n > len(text)-index) {
+			return nil, errTextOperationLength
+		}
+		switch c.kind {
+		case textRetain:
+			result = append(result, text[index:index+c.n]...)
+			index += c.n
+		case textInsert:
+			result = append(result, c.text...)
+		case textDelete:
+			index += c.n
+		}
+	}
+	if index != len(text) {
+		return nil, errTextOperationLength
+	}
+	return result, nil
+}
+
+// transformTextOperations transforms two concurrent operations a and b
+// that apply to the same text into a' and b', so that applying a then b'
+// gives the same text as applying b then a'. When both insert at the
+// same position, the insertion of a goes first.
+func transformTextOperations(a, b *TextOperation) (*TextOperation, *TextOperation, error) {
+	if a.baseLength != b.baseLength {
+		return nil, nil, errTextOperationLength
+	}
+
+	aPrime, bPrime := &TextOperation{}, &TextOperation{}
+	as, bs := a.components, b.components
+	var ac, bc *textComponent
+	next := func(components *[]textComponent) *textComponent {
+		if len(*components) == 0 {
+			return nil
+		}
+		c := (*components)[0]
+		*components = (*components)[1:]
+		return &c
+	}
+	ac, bc = next(&as), next(&bs)
+
+	for ac != nil || bc != nil {
+		if ac != nil && ac.kind == textInsert {
+			aPrime.insert(ac.text)
+			bPrime.retain(len(ac.text))
+			ac = next(&as)
+			continue
+		}
+		if bc != nil && bc.kind == textInsert {
+			aPrime.retain(len(bc.text))
+			bPrime.insert(bc.text)
+			bc = next(&bs)
+			continue
+		}
+		if ac == nil || bc == nil || ac.n <= 0 || bc.n <= 0 {
+			return nil, nil, errTextOperationLength
+		}
+
+		n := min(ac.n, bc.n)
+		switch {
+		case ac.kind == textRetain && bc.kind == textRetain:
+			aPrime.retain(n)
+			bPrime.retain(n)
+		case ac.kind == textDelete && bc.kind == textRetain:
+			aPrime.delete(n)
+		case ac.kind == textRetain && bc.kind == textDelete:
+			bPrime.delete(n)
+		}
+		// when both delete the same characters, there's nothing left to
+		// delete for either of them
+
+		if ac.n -= n; ac.n == 0 {
+			ac = next(&as)
+		}
+		if bc.n -= n; bc.n == 0 {
+			bc = next(&bs)
+		}
+	}
+	return aPrime, bPrime, nil
+}
+
+// newReplaceTextOperation returns an operation that replaces a whole
+// text with another one.
+func newReplaceTextOperation(from, to []uint16) *TextOperation {
+	return (&TextOperation{}).delete(len(from)).insert(to)
+}
+
+func encodeText(text string) []uint16 {
+	return utf16.Encode([]rune(text))
+}
+
+func decodeText(text []uint16) string {
+	return string(utf16.Decode(text))
+}
+
+func (o TextOperation) MarshalJSON() ([]byte, error) {
+	components := make([]interface{}, 0, len(o.components))
+	for _, c := range o.components {
+		switch c.kind {
+		case textRetain:
+			components = append(components, c.n)
+		case textInsert:
+			components = append(components, decodeText(c.text))
+		case textDelete:
+			components = append(components, -c.n)
+		}
+	}
+	return json.Marshal(components)
+}
+
+func (o *TextOperation) UnmarshalJSON(data []byte) error {
+	var components []interface{}
+	if err := json.Unmarshal(data, &components); err != nil {
+		return err
+	}
+
+	// the lengths are bounded before being added, so they can't overflow
+	operation := TextOperation{}
+	for _, c := range components {
+		switch value := c.(type) {
+		case float64:
+			if value > maxTextLength || value < -maxTextLength || value != math.Trunc(value) {
+				return fmt.Errorf("invalid operation component %v", value)
+			}
+			n := int(value)
+			switch {
+			case n > 0:
+				if operation.baseLength+n > maxTextLength || operation.targetLength+n > maxTextLength {
+					return errTextOperationTooLong
+				}
+				operation.retain(n)
+			case n < 0:
+				if operation.baseLength-n > maxTextLength {
+					return errTextOperationTooLong
+				}
+				operation.delete(-n)
+			default:
+				return errTextOperationEmpty
+			}
+		case string:
+			if value == "" {
+				return errTextOperationEmpty
+			}
+			text := encodeText(value)
+			if operation.targetLength+len(text) > maxTextLength {
+				return errTextOperationTooLong
+			}
+			operation.insert(text)
+		default:
+			return fmt.Errorf("invalid operation component %v", value)
+		}
+	}
+	*o = operation
+	return nil
+}
@@ -0,0 +1,169 @@
+package ws
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseTextOperation(t *testing.T, data string) *TextOperation {
+	var operation TextOperation
+	require.NoError(t, json.Unmarshal([]byte(data), &operation))
+	return &operation
+}
+
+func TestTextOperation(t *testing.T) {
+	t.Run("operations are serialized as lists", func(t *testing.T) {
+		operation := parseTextOperation(t, `[2, "añ😀", -3, 1]`)
+		require.Equal(t, 6, operation.baseLength)
+		require.Equal(t, 7, operation.targetLength)
+
+		data, err := json.Marshal(operation)
+		require.NoError(t, err)
+		require.JSONEq(t, `[2, "añ😀", -3, 1]`, string(data))
+
+		for _, invalid := range []string{`[0]`, `[""]`, `[1.5]`, `[true]`, `{}`} {
+			var operation TextOperation
+			require.Error(t, json.Unmarshal([]byte(invalid), &operation), invalid)
+		}
+	})
+
+	t.Run("malicious operations are rejected", func(t *testing.T) {
+		for _, invalid := range []string{
+			// the lengths would overflow to match an empty text
+			`[4611686018427387904, "a", 4611686018427387904, "a", 4611686018427387904, "a", 4611686018427387904]`,
+			`[9223372036854775807, 9223372036854775807]`,
+			`[-1e300]`,
+			`[65535, 1]`,
+			`[-65535, -1]`,
+		} {
+			var operation TextOperation
+			require.Error(t, json.Unmarshal([]byte(invalid), &operation), invalid)
+		}
+	})
+
+	t.Run("operations going past the end of the text aren't applied", func(t *testing.T) {
+		operation := &TextOperation{
+			components: []textComponent{{kind: textRetain, n: 5}, {kind: textDelete, n: -5}},
+			baseLength: 0,
+		}
+		_, err := operation.apply(encodeText(""))
+		require.ErrorIs(t, err, errTextOperationLength)
+
+		_, _, err = transformTextOperations(operation, &TextOperation{})
+		require.ErrorIs(t, err, errTextOperationLength)
+	})
+
+	t.Run("insertions go before deletions", func(t *testing.T) {
+		operation := (&TextOperation{}).retain(1).delete(2).insert(encodeText("a")).delete(1).insert(encodeText("b"))
+		data, err := json.Marshal(operation)
+		require.NoError(t, err)
+		require.JSONEq(t, `[1, "ab", -3]`, string(data))
+	})
+
+	t.Run("apply an operation", func(t *testing.T) {
+		text, err := parseTextOperation(t, `[6, "big ", 5, -5, "😀"]`).apply(encodeText("a new text block"))
+		require.NoError(t, err)
+		require.Equal(t, "a new big text 😀", decodeText(text))
+
+		_, err = parseTextOperation(t, `[3]`).apply(encodeText("text"))
+		require.ErrorIs(t, err, errTextOperationLength)
+	})
+
+	t.Run("concurrent insertions at the same position", func(t *testing.T) {
+		text := encodeText("ac")
+		a := parseTextOperation(t, `[1, "b", 1]`)
+		b := parseTextOperation(t, `[1, "x", 1]`)
+
+		aPrime, bPrime, err := transformTextOperations(a, b)
+		require.NoError(t, err)
+
+		afterA, err := a.apply(text)
+		require.NoError(t, err)
+		afterAB, err := bPrime.apply(afterA)
+		require.NoError(t, err)
+		afterB, err := b.apply(text)
+		require.NoError(t, err)
+		afterBA, err := aPrime.apply(afterB)
+		require.NoError(t, err)
+
+		require.Equal(t, "abxc", decodeText(afterAB))
+		require.Equal(t, "abxc", decodeText(afterBA))
+	})
+
+	t.Run("concurrent deletions of the same text", func(t *testing.T) {
+		a := parseTextOperation(t, `[1, -3, 1]`)
+		b := parseTextOperation(t, `[2, -3]`)
+
+		aPrime, bPrime, err := transformTextOperations(a, b)
+		require.NoError(t, err)
+
+		afterA, err := a.apply(encodeText("abcde"))
+		require.NoError(t, err)
+		afterAB, err := bPrime.apply(afterA)
+		require.NoError(t, err)
+		require.Equal(t, "a", decodeText(afterAB))
+
+		afterB, err := b.apply(encodeText("abcde"))
+		require.NoError(t, err)
+		afterBA, err := aPrime.apply(afterB)
+		require.NoError(t, err)
+		require.Equal(t, "a", decodeText(afterBA))
+	})
+
+	t.Run("transformed operations converge", func(t *testing.T) {
+		random := rand.New(rand.NewSource(1))
+		for i := 0; i < 500; i++ {
+			text := randomText(random, random.Intn(20))
+			a := randomTextOperation(random, text)
+			b := randomTextOperation(random, text)
+
+			aPrime, bPrime, err := transformTextOperations(a, b)
+			require.NoError(t, err)
+
+			afterA, err := a.apply(text)
+			require.NoError(t, err)
+			afterAB, err := bPrime.apply(afterA)
+			require.NoError(t, err)
+			afterB, err := b.apply(text)
+			require.NoError(t, err)
+			afterBA, err := aPrime.apply(afterB)
+			require.NoError(t, err)
+			require.Equal(t, decodeText(afterAB), decodeText(afterBA))
+		}
+
+		_, _, err := transformTextOperations(parseTextOperation(t, `[1]`), parseTextOperation(t, `[2]`))
+		require.ErrorIs(t, err, errTextOperationLength)
+	})
+}
+
+func randomText(random *rand.Rand, length int) []uint16 {
+	text := make([]uint16, length)
+	for i := range text {
+		text[i] = uint16('a' + random.Intn(26))
+	}
+	return text
+}
+
+func randomTextOperation(random *rand.Rand, text []uint16) *TextOperation {
+	operation := &TextOperation{}
+	for left := len(text); left > 0; {
+		n := 1 + random.Intn(left)
+		switch random.Intn(3) {
+		case 0:
+			operation.retain(n)
+		case 1:
+			operation.delete(n)
+		default:
+			operation.insert(randomText(random, 1+random.Intn(3)))
+			continue
+		}
+		left -= n
+	}
+	if random.Intn(2) == 0 {
+		operation.insert(randomText(random, 1+random.Intn(3)))
+	}
+	return operation
+}